@@ -5,6 +5,7 @@ go 1.24.7
 require (
 	github.com/appetiteclub/apt v0.1.0
 	github.com/google/uuid v1.6.0
+	github.com/nats-io/nats.go v1.37.0
 	go.mongodb.org/mongo-driver v1.17.6
 )
 
@@ -26,6 +27,8 @@ require (
 	github.com/mitchellh/copystructure v1.2.0 // indirect
 	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Dead letters as the services keep them, see pkg.DeadLetterStreamName.
+const (
+	deadLetterStream        = "DEAD_LETTERS"
+	deadLetterSubjectPrefix = "dlq."
+	deadLetterHeaderPrefix  = "Dlq-"
+
+	headerDeadLetterSubject    = "Dlq-Subject"
+	headerDeadLetterStream     = "Dlq-Stream"
+	headerDeadLetterConsumer   = "Dlq-Consumer"
+	headerDeadLetterReason     = "Dlq-Reason"
+	headerDeadLetterDeliveries = "Dlq-Deliveries"
+	headerDeadLetterFailedAt   = "Dlq-Failed-At"
+
+	defaultDeadLetterLimit = 50
+	deadLetterReasonWidth  = 60
+)
+
+// DLQList lists the dead-lettered messages, oldest first. dlq.subject keeps
+// the ones from one stream or subject, e.g. KITCHEN_EVENTS or orders.items.
+func DLQList(ctx context.Context, config *apt.Config, logger apt.Logger) error {
+	conn, stream, err := connectDeadLetters(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	limit := defaultDeadLetterLimit
+	if value, _ := config.GetString("dlq.limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid dlq.limit %q", value)
+		}
+	}
+
+	messages, err := findDeadLetters(ctx, stream, deadLetterFilter(config), limit)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		logger.Info("No dead-lettered messages")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEQ\tSUBJECT\tCONSUMER\tDELIVERIES\tFAILED AT\tREASON")
+	for _, msg := range messages {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			msg.Sequence,
+			msg.Header.Get(headerDeadLetterSubject),
+			msg.Header.Get(headerDeadLetterConsumer),
+			msg.Header.Get(headerDeadLetterDeliveries),
+			msg.Header.Get(headerDeadLetterFailedAt),
+			truncate(msg.Header.Get(headerDeadLetterReason), deadLetterReasonWidth))
+	}
+	return tw.Flush()
+}
+
+// DLQInspect prints the headers and payload of the dead letter dlq.seq.
+func DLQInspect(ctx context.Context, config *apt.Config, logger apt.Logger) error {
+	seq, err := deadLetterSeq(config)
+	if err != nil {
+		return err
+	}
+	if seq == 0 {
+		return errors.New("dlq.seq is required")
+	}
+
+	conn, stream, err := connectDeadLetters(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	msg, err := stream.GetMsg(ctx, seq)
+	if err != nil {
+		return fmt.Errorf("get dead letter %d: %w", seq, err)
+	}
+
+	fmt.Printf("Sequence:  %d\n", msg.Sequence)
+	fmt.Printf("Subject:   %s\n", msg.Subject)
+	fmt.Printf("Stored at: %s\n\n", msg.Time.UTC().Format(time.RFC3339))
+
+	keys := make([]string, 0, len(msg.Header))
+	for key := range msg.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Println("Headers:")
+	for _, key := range keys {
+		fmt.Printf("  %s: %s\n", key, strings.Join(msg.Header.Values(key), ", "))
+	}
+
+	fmt.Println("\nPayload:")
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, msg.Data, "  ", "  "); err == nil {
+		fmt.Printf("  %s\n", pretty.String())
+	} else {
+		fmt.Printf("  %s\n", string(msg.Data))
+	}
+	return nil
+}
+
+// DLQReplay publishes dead letters back on the subject they failed on and
+// removes them from the dead letter stream. It replays dlq.seq, or all the
+// dead letters dlq.subject keeps. Every consumer of the subject gets the
+// message again; those that already handled it skip it by its event ID.
+func DLQReplay(ctx context.Context, config *apt.Config, logger apt.Logger) error {
+	seq, err := deadLetterSeq(config)
+	if err != nil {
+		return err
+	}
+
+	conn, stream, err := connectDeadLetters(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return fmt.Errorf("create jetstream context: %w", err)
+	}
+
+	var messages []*jetstream.RawStreamMsg
+	if seq != 0 {
+		msg, err := stream.GetMsg(ctx, seq)
+		if err != nil {
+			return fmt.Errorf("get dead letter %d: %w", seq, err)
+		}
+		messages = append(messages, msg)
+	} else {
+		messages, err = findDeadLetters(ctx, stream, deadLetterFilter(config), 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	replayed := 0
+	for _, msg := range messages {
+		if err := replayDeadLetter(ctx, conn, js, msg); err != nil {
+			return fmt.Errorf("replay dead letter %d: %w", msg.Sequence, err)
+		}
+		if err := stream.DeleteMsg(ctx, msg.Sequence); err != nil {
+			return fmt.Errorf("remove replayed dead letter %d: %w", msg.Sequence, err)
+		}
+		logger.Info("Replayed dead letter", "seq", msg.Sequence, "subject", msg.Header.Get(headerDeadLetterSubject))
+		replayed++
+	}
+
+	logger.Info("Dead letters replayed", "count", replayed)
+	return nil
+}
+
+// replayDeadLetter publishes the message with its original headers. Messages
+// that came from a stream go through JetStream so the publish is confirmed.
+func replayDeadLetter(ctx context.Context, conn *nats.Conn, js jetstream.JetStream, dl *jetstream.RawStreamMsg) error {
+	subject := dl.Header.Get(headerDeadLetterSubject)
+	if subject == "" {
+		return errors.New("dead letter has no original subject")
+	}
+
+	header := nats.Header{}
+	for key, values := range dl.Header {
+		if strings.HasPrefix(key, deadLetterHeaderPrefix) {
+			continue
+		}
+		header[key] = values
+	}
+	msg := &nats.Msg{Subject: subject, Header: header, Data: dl.Data}
+
+	if dl.Header.Get(headerDeadLetterStream) != "" {
+		_, err := js.PublishMsg(ctx, msg)
+		return err
+	}
+	if err := conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return conn.FlushWithContext(ctx)
+}
+
+// findDeadLetters walks the dead letter stream from the oldest message and
+// returns the ones on subject, up to limit. A zero limit returns them all.
+func findDeadLetters(ctx context.Context, stream jetstream.Stream, subject string, limit int) ([]*jetstream.RawStreamMsg, error) {
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get %s info: %w", deadLetterStream, err)
+	}
+	if info.State.Msgs == 0 {
+		return nil, nil
+	}
+
+	var messages []*jetstream.RawStreamMsg
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq; seq++ {
+		msg, err := stream.GetMsg(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get dead letter %d: %w", seq, err)
+		}
+		if subject != "" && msg.Subject != subject && !strings.HasPrefix(msg.Subject, subject+".") {
+			continue
+		}
+		messages = append(messages, msg)
+		if limit > 0 && len(messages) == limit {
+			break
+		}
+	}
+	return messages, nil
+}
+
+// deadLetterFilter returns the dead letter subject dlq.subject names, which
+// can be given with or without the dlq. prefix.
+func deadLetterFilter(config *apt.Config) string {
+	value, _ := config.GetString("dlq.subject")
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, deadLetterSubjectPrefix) {
+		return value
+	}
+	return deadLetterSubjectPrefix + value
+}
+
+func deadLetterSeq(config *apt.Config) (uint64, error) {
+	value, _ := config.GetString("dlq.seq")
+	if value == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || seq == 0 {
+		return 0, fmt.Errorf("invalid dlq.seq %q", value)
+	}
+	return seq, nil
+}
+
+func connectDeadLetters(ctx context.Context, config *apt.Config, logger apt.Logger) (*nats.Conn, jetstream.Stream, error) {
+	natsURL, _ := config.GetString("nats.url")
+	if natsURL == "" {
+		natsURL = nats.DefaultURL
+	}
+
+	conn, err := nats.Connect(natsURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("create jetstream context: %w", err)
+	}
+
+	stream, err := js.Stream(ctx, deadLetterStream)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("open %s stream: %w", deadLetterStream, err)
+	}
+
+	logger.Info("Connected to NATS")
+	return conn, stream, nil
+}
+
+func truncate(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:width-3] + "..."
+}
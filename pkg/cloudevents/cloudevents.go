@@ -0,0 +1,233 @@
+// Package cloudevents maps the events published on NATS to CloudEvents 1.0,
+// in the HTTP structured and binary content modes, so they can be handed to
+// systems outside the platform.
+package cloudevents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/event"
+)
+
+const (
+	// SpecVersion is the CloudEvents version events are mapped to.
+	SpecVersion = "1.0"
+
+	// ContentTypeStructured is the content type of a structured mode event.
+	ContentTypeStructured = "application/cloudevents+json"
+
+	// ContentTypeJSON is the content type of the data of every event.
+	ContentTypeJSON = "application/json"
+
+	// SourcePrefix starts the source of every event, followed by the
+	// service that published it.
+	SourcePrefix = "/appetite/"
+
+	headerPrefix = "ce-"
+)
+
+// Topics maps the NATS topics whose events are exported to the service that
+// publishes on them. The service names the source of events that do not
+// carry one, which is the case for messages published before envelopes.
+var Topics = map[string]string{
+	event.OrderItemsTopic:     "order-service",
+	event.KitchenTicketsTopic: "kitchen-service",
+	pkg.TableStatusTopic:      "table-service",
+	pkg.OrderTableTopic:       "order-service",
+}
+
+// ErrInvalid is returned for events that cannot be read or miss a required
+// attribute.
+var ErrInvalid = errors.New("invalid cloudevent")
+
+// Mode is the HTTP content mode of an event.
+type Mode string
+
+const (
+	// Structured sends the whole event as a JSON document.
+	Structured Mode = "structured"
+	// Binary sends the attributes as ce-* headers and the data as the body.
+	Binary Mode = "binary"
+)
+
+// ParseMode returns the mode named s, structured when s is empty.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", Structured:
+		return Structured, nil
+	case Binary:
+		return Binary, nil
+	}
+	return "", fmt.Errorf("unknown cloudevents mode %q", s)
+}
+
+// Event is a CloudEvent. Besides the required and optional context
+// attributes it has the envelope's schema version, correlation and causation
+// IDs as extensions.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time,omitzero"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	SchemaVersion   int             `json:"schemaversion,omitempty"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	CausationID     string          `json:"causationid,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// FromEnvelope maps an envelope published on topic to a CloudEvent.
+func FromEnvelope(topic string, env *event.Envelope) *Event {
+	e := &Event{
+		SpecVersion:     SpecVersion,
+		ID:              env.ID,
+		Source:          env.Source,
+		Type:            env.Type,
+		Time:            env.OccurredAt,
+		DataContentType: ContentTypeJSON,
+		SchemaVersion:   env.SchemaVersion,
+		CorrelationID:   env.CorrelationID,
+		CausationID:     env.CausationID,
+		Data:            env.Payload,
+	}
+	if e.Source == "" {
+		e.Source = Topics[topic]
+	}
+	if e.Source != "" && !strings.HasPrefix(e.Source, "/") {
+		e.Source = SourcePrefix + e.Source
+	}
+	return e
+}
+
+// FromMessage maps a message published on topic to a CloudEvent. The payload
+// is not upcast: the event says the schema version it was published with.
+// Messages without an ID or type are not events and return event.ErrNotEvent.
+func FromMessage(topic string, msg []byte) (*Event, error) {
+	env, err := event.Parse(msg)
+	if err != nil {
+		return nil, err
+	}
+	if env.ID == "" || env.Type == "" {
+		return nil, fmt.Errorf("%w: no id or type", event.ErrNotEvent)
+	}
+	return FromEnvelope(topic, env), nil
+}
+
+// Validate checks the required attributes are set.
+func (e *Event) Validate() error {
+	switch {
+	case e.SpecVersion != SpecVersion:
+		return fmt.Errorf("%w: specversion %q", ErrInvalid, e.SpecVersion)
+	case e.ID == "":
+		return fmt.Errorf("%w: no id", ErrInvalid)
+	case e.Source == "":
+		return fmt.Errorf("%w: no source", ErrInvalid)
+	case e.Type == "":
+		return fmt.Errorf("%w: no type", ErrInvalid)
+	}
+	return nil
+}
+
+// Encode returns the headers and body of e in mode.
+func (e *Event) Encode(mode Mode) (http.Header, []byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	header := http.Header{}
+	if mode == Binary {
+		contentType := e.DataContentType
+		if contentType == "" {
+			contentType = ContentTypeJSON
+		}
+		header.Set("Content-Type", contentType)
+		for name, value := range e.attributes() {
+			header.Set(headerPrefix+name, value)
+		}
+		return header, e.Data, nil
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encode cloudevent %s: %w", e.ID, err)
+	}
+	header.Set("Content-Type", ContentTypeStructured)
+	return header, body, nil
+}
+
+// attributes returns the attributes sent as headers in binary mode.
+func (e *Event) attributes() map[string]string {
+	attrs := map[string]string{
+		"specversion": e.SpecVersion,
+		"id":          e.ID,
+		"source":      e.Source,
+		"type":        e.Type,
+	}
+	if !e.Time.IsZero() {
+		attrs["time"] = e.Time.UTC().Format(time.RFC3339Nano)
+	}
+	if e.SchemaVersion > 0 {
+		attrs["schemaversion"] = strconv.Itoa(e.SchemaVersion)
+	}
+	if e.CorrelationID != "" {
+		attrs["correlationid"] = e.CorrelationID
+	}
+	if e.CausationID != "" {
+		attrs["causationid"] = e.CausationID
+	}
+	return attrs
+}
+
+// Decode reads an event sent over HTTP in either mode, telling them apart by
+// the content type.
+func Decode(header http.Header, body []byte) (*Event, error) {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == ContentTypeStructured {
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+
+	e := Event{
+		SpecVersion:     header.Get(headerPrefix + "specversion"),
+		ID:              header.Get(headerPrefix + "id"),
+		Source:          header.Get(headerPrefix + "source"),
+		Type:            header.Get(headerPrefix + "type"),
+		DataContentType: header.Get("Content-Type"),
+		CorrelationID:   header.Get(headerPrefix + "correlationid"),
+		CausationID:     header.Get(headerPrefix + "causationid"),
+		Data:            body,
+	}
+	if value := header.Get(headerPrefix + "time"); value != "" {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time %q", ErrInvalid, value)
+		}
+		e.Time = t
+	}
+	if value := header.Get(headerPrefix + "schemaversion"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: schemaversion %q", ErrInvalid, value)
+		}
+		e.SchemaVersion = n
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
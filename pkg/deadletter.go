@@ -0,0 +1,190 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// DeadLetterStreamName is the JetStream stream that keeps the messages
+	// no consumer could handle, under DeadLetterSubjectPrefix.
+	DeadLetterStreamName = "DEAD_LETTERS"
+
+	// DeadLetterSubjectPrefix starts the subject a message is dead-lettered
+	// on, followed by the stream or subject it came from.
+	DeadLetterSubjectPrefix = "dlq."
+
+	// DeadLetterMaxAge is how long dead-lettered messages are kept.
+	DeadLetterMaxAge = 7 * 24 * time.Hour
+)
+
+// Headers set on a dead-lettered message, next to the original headers.
+const (
+	HeaderDeadLetterSubject    = "Dlq-Subject"
+	HeaderDeadLetterStream     = "Dlq-Stream"
+	HeaderDeadLetterConsumer   = "Dlq-Consumer"
+	HeaderDeadLetterReason     = "Dlq-Reason"
+	HeaderDeadLetterDeliveries = "Dlq-Deliveries"
+	HeaderDeadLetterFailedAt   = "Dlq-Failed-At"
+)
+
+const (
+	DefaultMaxDeliver = 5
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+// DeliveryPolicy says how often a consumer retries a message its handler
+// fails on, and how long it waits in between, before dead-lettering it.
+type DeliveryPolicy struct {
+	// Consumer names who gave up on a dead-lettered message.
+	Consumer   string
+	MaxDeliver int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultDeliveryPolicy returns the delivery defaults for consumer.
+func DefaultDeliveryPolicy(consumer string) DeliveryPolicy {
+	return DeliveryPolicy{
+		Consumer:   consumer,
+		MaxDeliver: DefaultMaxDeliver,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// LoadDeliveryPolicy reads nats.max_deliver, nats.min_backoff and
+// nats.max_backoff from the service config.
+func LoadDeliveryPolicy(config *apt.Config, consumer string) (DeliveryPolicy, error) {
+	policy := DefaultDeliveryPolicy(consumer)
+	if config == nil {
+		return policy, nil
+	}
+
+	if value, _ := config.GetString("nats.max_deliver"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return policy, fmt.Errorf("invalid nats.max_deliver %q", value)
+		}
+		policy.MaxDeliver = n
+	}
+
+	if value, _ := config.GetString("nats.min_backoff"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return policy, fmt.Errorf("invalid nats.min_backoff %q", value)
+		}
+		policy.MinBackoff = d
+	}
+
+	if value, _ := config.GetString("nats.max_backoff"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return policy, fmt.Errorf("invalid nats.max_backoff %q", value)
+		}
+		policy.MaxBackoff = d
+	}
+
+	if policy.MaxBackoff < policy.MinBackoff {
+		return policy, fmt.Errorf("nats.max_backoff %s is shorter than nats.min_backoff %s", policy.MaxBackoff, policy.MinBackoff)
+	}
+	return policy, nil
+}
+
+// withDefaults fills the zero fields of the policy.
+func (p DeliveryPolicy) withDefaults() DeliveryPolicy {
+	if p.MaxDeliver <= 0 {
+		p.MaxDeliver = DefaultMaxDeliver
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = DefaultMinBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+	return p
+}
+
+// Backoff returns the wait before redelivering a message delivered
+// deliveries times, doubling from MinBackoff up to MaxBackoff.
+func (p DeliveryPolicy) Backoff(deliveries int) time.Duration {
+	p = p.withDefaults()
+	wait := p.MinBackoff
+	for i := 1; i < deliveries && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// DeadLetterSubject returns the subject messages from source are
+// dead-lettered on.
+func DeadLetterSubject(source string) string {
+	return DeadLetterSubjectPrefix + source
+}
+
+// ensureDeadLetterStream creates the dead letter stream if it is missing.
+func ensureDeadLetterStream(ctx context.Context, js jetstream.JetStream) error {
+	_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     DeadLetterStreamName,
+		Subjects: []string{DeadLetterSubjectPrefix + ">"},
+		MaxAge:   DeadLetterMaxAge,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create/update stream %s: %w", DeadLetterStreamName, err)
+	}
+	return nil
+}
+
+// deadLetter is a message that ran out of deliveries.
+type deadLetter struct {
+	source     string // stream or subject the message came from
+	stream     string
+	subject    string
+	header     nats.Header
+	data       []byte
+	deliveries int
+	reason     error
+}
+
+// publishDeadLetter stores the message on its dead letter subject with why
+// it failed in the headers.
+func publishDeadLetter(ctx context.Context, js jetstream.JetStream, policy DeliveryPolicy, dl deadLetter) error {
+	header := nats.Header{}
+	for key, values := range dl.header {
+		header[key] = append([]string(nil), values...)
+	}
+	// The original message id would make JetStream drop the dead letter as
+	// a duplicate of the message itself
+	header.Del(nats.MsgIdHdr)
+
+	header.Set(HeaderDeadLetterSubject, dl.subject)
+	if dl.stream != "" {
+		header.Set(HeaderDeadLetterStream, dl.stream)
+	}
+	if policy.Consumer != "" {
+		header.Set(HeaderDeadLetterConsumer, policy.Consumer)
+	}
+	header.Set(HeaderDeadLetterReason, dl.reason.Error())
+	header.Set(HeaderDeadLetterDeliveries, strconv.Itoa(dl.deliveries))
+	header.Set(HeaderDeadLetterFailedAt, time.Now().UTC().Format(time.RFC3339))
+
+	msg := &nats.Msg{
+		Subject: DeadLetterSubject(dl.source),
+		Header:  header,
+		Data:    dl.data,
+	}
+	if _, err := js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to dead-letter message from %s: %w", dl.subject, err)
+	}
+	return nil
+}
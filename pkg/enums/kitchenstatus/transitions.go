@@ -0,0 +1,39 @@
+package kitchenstatus
+
+// transitions lists the statuses a ticket can move to from each status. The
+// main line is created, accepted, started, ready and delivered; standby and
+// block pause a ticket, reject refuses it before work starts and cancelled
+// drops it. A held course waits in standby and goes back to created when it
+// fires. Delivered, rejected and cancelled tickets are final.
+var transitions = map[string][]Status{
+	Statuses.Created.Name:  {Statuses.Accepted, Statuses.Started, Statuses.Standby, Statuses.Block, Statuses.Reject, Statuses.Cancelled},
+	Statuses.Accepted.Name: {Statuses.Started, Statuses.Standby, Statuses.Block, Statuses.Reject, Statuses.Cancelled},
+	Statuses.Started.Name:  {Statuses.Ready, Statuses.Standby, Statuses.Block, Statuses.Cancelled},
+	Statuses.Ready.Name:    {Statuses.Delivered, Statuses.Started, Statuses.Cancelled},
+	Statuses.Standby.Name:  {Statuses.Created, Statuses.Accepted, Statuses.Started, Statuses.Block, Statuses.Cancelled},
+	Statuses.Block.Name:    {Statuses.Accepted, Statuses.Started, Statuses.Standby, Statuses.Reject, Statuses.Cancelled},
+}
+
+// Next returns the statuses a ticket in s can move to, in workflow order.
+func (s Status) Next() []Status {
+	next := transitions[s.Name]
+	result := make([]Status, len(next))
+	copy(result, next)
+	return result
+}
+
+// IsFinal reports whether a ticket in s can no longer change status.
+func (s Status) IsFinal() bool {
+	return len(transitions[s.Name]) == 0
+}
+
+// CanTransition reports whether the graph allows moving from one status to
+// another. Unknown statuses allow nothing.
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s.Name == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appetiteclub/apt"
+)
+
+const (
+	// SetName is the dictionary set that lists a venue's stations.
+	SetName = "station"
+
+	// Default is where items go when the menu does not name a station.
+	Default = "kitchen"
+
+	// Fallback takes the tickets of stations the venue does not have or
+	// that are closed.
+	Fallback = "other"
+
+	// DefaultRefreshInterval is how often a started registry reloads the
+	// station set.
+	DefaultRefreshInterval = 5 * time.Minute
+)
+
+// Registry holds the stations of the venue as kept in the dictionary
+// service, so a venue can model its own line (a grill, a fryer, two bars)
+// instead of the built-in stations. Until the dictionary answers, or when it
+// has no station set, the built-in stations are used.
+//
+// A nil Registry behaves as one holding the built-in stations.
+type Registry struct {
+	client   *apt.ServiceClient
+	interval time.Duration
+	logger   apt.Logger
+
+	mu       sync.RWMutex
+	stations []Station
+	location *time.Location
+	cancel   context.CancelFunc
+}
+
+// NewRegistry returns a registry that loads stations from the dictionary
+// service behind client. A nil client keeps the built-in stations.
+func NewRegistry(client *apt.ServiceClient, interval time.Duration, logger apt.Logger) *Registry {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	return &Registry{
+		client:   client,
+		interval: interval,
+		logger:   logger,
+		stations: append([]Station(nil), All...),
+	}
+}
+
+// Start loads the stations and keeps reloading them in the background, so
+// stations added in the dictionary show up without a restart. A failed load
+// is logged and the current stations are kept.
+func (r *Registry) Start(ctx context.Context) error {
+	if r.client == nil {
+		return nil
+	}
+	if err := r.Refresh(ctx); err != nil {
+		r.logger.Errorf("cannot load stations, using built-in ones: %v", err)
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Refresh(loopCtx); err != nil {
+					r.logger.Errorf("cannot reload stations: %v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ends the background reload.
+func (r *Registry) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	return nil
+}
+
+// dictionaryOption mirrors the option JSON returned by the dictionary
+// service.
+type dictionaryOption struct {
+	Key        string            `json:"key"`
+	Value      string            `json:"value"`
+	Label      string            `json:"label"`
+	Order      int               `json:"order"`
+	Active     bool              `json:"active"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Refresh reloads the stations from the dictionary. An empty station set
+// leaves the current stations in place.
+func (r *Registry) Refresh(ctx context.Context) error {
+	if r == nil || r.client == nil {
+		return nil
+	}
+
+	resp, err := r.client.Request(ctx, http.MethodGet, "/dictionary/options/set/"+SetName, nil)
+	if err != nil {
+		return fmt.Errorf("list station options: %w", err)
+	}
+	if resp == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(resp.Data)
+	if err != nil {
+		return err
+	}
+	var options []dictionaryOption
+	if err := json.Unmarshal(raw, &options); err != nil {
+		return fmt.Errorf("decode station options: %w", err)
+	}
+
+	stations, err := stationsFromOptions(options)
+	if err != nil {
+		return err
+	}
+	if len(stations) == 0 {
+		return nil
+	}
+	r.Set(stations)
+	return nil
+}
+
+// stationsFromOptions turns the active dictionary options into stations,
+// in the order the dictionary gives them.
+func stationsFromOptions(options []dictionaryOption) ([]Station, error) {
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].Order < options[j].Order
+	})
+
+	stations := make([]Station, 0, len(options))
+	for _, option := range options {
+		if !option.Active {
+			continue
+		}
+		name := option.Value
+		if name == "" {
+			name = option.Key
+		}
+		name = normalize(name)
+		if name == "" {
+			continue
+		}
+
+		st := Station{
+			Name:        name,
+			DisplayName: option.Label,
+			Colour:      option.Attributes["colour"],
+			Printer:     option.Attributes["printer"],
+			Hours:       option.Attributes["hours"],
+		}
+		if capacity := option.Attributes["capacity"]; capacity != "" {
+			n, err := strconv.Atoi(capacity)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("station %s: invalid capacity %q", name, capacity)
+			}
+			st.Capacity = n
+		}
+		if _, err := parseHours(st.Hours); err != nil {
+			return nil, fmt.Errorf("station %s: %w", name, err)
+		}
+		stations = append(stations, st)
+	}
+	return stations, nil
+}
+
+// Set replaces the stations, e.g. with ones read from config.
+func (r *Registry) Set(stations []Station) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stations = append([]Station(nil), stations...)
+}
+
+// All returns the stations in display order.
+func (r *Registry) All() []Station {
+	if r == nil {
+		return append([]Station(nil), All...)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Station(nil), r.stations...)
+}
+
+// Get returns the station with the given code.
+func (r *Registry) Get(code string) (Station, bool) {
+	code = normalize(code)
+	for _, st := range r.All() {
+		if st.Name == code {
+			return st, true
+		}
+	}
+	return Station{}, false
+}
+
+// Resolve returns the code of the station that takes items routed to code:
+// the station itself when the venue has it, the fallback otherwise. An
+// empty code means the default station.
+func (r *Registry) Resolve(code string) string {
+	code = normalize(code)
+	if code == "" {
+		code = Default
+	}
+	if st, ok := r.Get(code); ok {
+		return st.Name
+	}
+	return r.fallback()
+}
+
+// SetLocation sets the venue time zone station hours are kept in. Without
+// one, Route reads them on the wall clock of the time it is given.
+func (r *Registry) SetLocation(location *time.Location) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.location = location
+}
+
+// Route is Resolve for a ticket created at the given time: a station
+// outside its active hours, on the venue's clock, hands its tickets to the
+// fallback.
+func (r *Registry) Route(code string, at time.Time) string {
+	r.mu.RLock()
+	location := r.location
+	r.mu.RUnlock()
+	if location != nil {
+		at = at.In(location)
+	}
+
+	resolved := r.Resolve(code)
+	if st, ok := r.Get(resolved); ok && !st.OpenAt(at) {
+		return r.fallback()
+	}
+	return resolved
+}
+
+// fallback returns the fallback station, or the first station when the
+// venue has no fallback station.
+func (r *Registry) fallback() string {
+	stations := r.All()
+	for _, st := range stations {
+		if st.Name == Fallback {
+			return Fallback
+		}
+	}
+	if len(stations) > 0 {
+		return stations[0].Name
+	}
+	return Fallback
+}
+
+// OpenAt reports whether the station works at the given time, read on its
+// own wall clock. Ranges that end before they start run past midnight, e.g.
+// "18:00-02:00".
+func (s Station) OpenAt(at time.Time) bool {
+	ranges, err := parseHours(s.Hours)
+	if err != nil || len(ranges) == 0 {
+		return true
+	}
+	minute := at.Hour()*60 + at.Minute()
+	for _, rg := range ranges {
+		if rg.from <= rg.to {
+			if minute >= rg.from && minute < rg.to {
+				return true
+			}
+		} else if minute >= rg.from || minute < rg.to {
+			return true
+		}
+	}
+	return false
+}
+
+// hoursRange is an active range in minutes since midnight.
+type hoursRange struct {
+	from, to int
+}
+
+func parseHours(hours string) ([]hoursRange, error) {
+	var ranges []hoursRange
+	for _, part := range strings.Split(hours, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid hours %q: want HH:MM-HH:MM", part)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, hoursRange{from: start, to: end})
+	}
+	return ranges, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func normalize(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
@@ -2,8 +2,15 @@ package station
 
 import "strings"
 
+// Station is a place on the line that prepares tickets. The built-in ones
+// below are used until the dictionary has a station set, see Registry.
 type Station struct {
-	Name string
+	Name        string
+	DisplayName string // Label shown to staff; the capitalised name when empty
+	Colour      string // CSS colour for the station's tab and cards
+	Printer     string // Printer target tickets for this station go to
+	Capacity    int    // Tickets the station can work on at once, 0 for no limit
+	Hours       string // Active hours as "HH:MM-HH:MM" ranges separated by commas, empty for always
 }
 
 func (s Station) Code() string {
@@ -11,6 +18,9 @@ func (s Station) Code() string {
 }
 
 func (s Station) Label() string {
+	if s.DisplayName != "" {
+		return s.DisplayName
+	}
 	// Capitalize first letter
 	if len(s.Name) == 0 {
 		return ""
@@ -0,0 +1,136 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/appetiteclub/apt"
+)
+
+// Envelope wraps every event published to NATS. The payload is the event
+// struct of its type and schema version; the envelope says what it is, who
+// sent it and what caused it, so consumers can pick the type, upcast old
+// payloads and follow a chain of events across services.
+//
+// Payloads still carry event_id, event_type and occurred_at, matching the
+// envelope, so a version 1 payload reads the same wrapped or bare.
+type Envelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	Source        string          `json:"source"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	CausationID   string          `json:"causation_id,omitempty"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Meta is what an event says about itself. Marshal copies it to the envelope.
+type Meta struct {
+	ID         string
+	Type       string
+	OccurredAt time.Time
+}
+
+// Event is a payload that can be published in an envelope.
+type Event interface {
+	EventMeta() Meta
+}
+
+// EventMeta implements Event.
+func (e OrderItemEvent) EventMeta() Meta {
+	return Meta{ID: e.EventID, Type: e.EventType, OccurredAt: e.OccurredAt}
+}
+
+// EventMeta implements Event for all the kitchen ticket events.
+func (m KitchenTicketEventMetadata) EventMeta() Meta {
+	return Meta{ID: m.EventID, Type: m.EventType, OccurredAt: m.OccurredAt}
+}
+
+// Unmarshal decodes the payload into v.
+func (e *Envelope) Unmarshal(v any) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("cannot decode %s v%d payload: %w", e.Type, e.SchemaVersion, err)
+	}
+	return nil
+}
+
+// Marshal wraps evt in an envelope from source and encodes it, stamped with
+// the schema version the default registry has for its type. See
+// Registry.Marshal.
+func Marshal(ctx context.Context, source string, evt Event) ([]byte, error) {
+	return DefaultRegistry.Marshal(ctx, source, evt)
+}
+
+// Open decodes a message, enveloped or not, and upcasts its payload to the
+// latest version the default registry knows. See Registry.Open.
+func Open(msg []byte) (*Envelope, error) {
+	return DefaultRegistry.Open(msg)
+}
+
+// ErrNotEvent is returned for messages that are not JSON objects. Consumers
+// drop them: no retry will make them readable.
+var ErrNotEvent = errors.New("message is not an event")
+
+// Parse decodes a message without upcasting it, for code that forwards
+// events as they were published. Messages published before envelopes existed
+// are bare version 1 payloads and are wrapped as such.
+func Parse(msg []byte) (*Envelope, error) {
+	var probe struct {
+		Envelope
+		EventID   string `json:"event_id"`
+		EventType string `json:"event_type"`
+	}
+	if err := json.Unmarshal(msg, &probe); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotEvent, err)
+	}
+
+	if probe.SchemaVersion > 0 && len(probe.Payload) > 0 {
+		env := probe.Envelope
+		return &env, nil
+	}
+	return &Envelope{
+		ID:            probe.EventID,
+		Type:          probe.EventType,
+		SchemaVersion: 1,
+		Source:        probe.Source,
+		OccurredAt:    probe.OccurredAt,
+		Payload:       json.RawMessage(msg),
+	}, nil
+}
+
+type causeKey struct{}
+
+type cause struct {
+	id          string
+	correlation string
+}
+
+// WithCause returns a context for handling env: the events marshaled with it
+// name env as their cause and share its correlation ID.
+func WithCause(ctx context.Context, env *Envelope) context.Context {
+	if env == nil || env.ID == "" {
+		return ctx
+	}
+	correlation := env.CorrelationID
+	if correlation == "" {
+		correlation = env.ID
+	}
+	return context.WithValue(ctx, causeKey{}, cause{id: env.ID, correlation: correlation})
+}
+
+// correlate returns the correlation and causation IDs for an event marshaled
+// with ctx. Events raised by a request are correlated by its request ID;
+// events nothing led to start their own chain.
+func correlate(ctx context.Context, id string) (correlationID, causationID string) {
+	if c, ok := ctx.Value(causeKey{}).(cause); ok {
+		return c.correlation, c.id
+	}
+	if requestID := apt.RequestIDFrom(ctx); requestID != "" {
+		return requestID, ""
+	}
+	return id, ""
+}
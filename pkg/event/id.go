@@ -0,0 +1,31 @@
+package event
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// NewID returns a new event ID. Producers set it once when they build an
+// event, so a republished or redelivered event keeps its ID and consumers can
+// tell it apart from a new one.
+func NewID() string {
+	return uuid.NewString()
+}
+
+// IDOf returns the ID of an encoded event, enveloped or bare, or "" when it
+// has none, e.g. events published before IDs were added.
+func IDOf(msg []byte) string {
+	var probe struct {
+		ID            string `json:"id"`
+		SchemaVersion int    `json:"schema_version"`
+		EventID       string `json:"event_id"`
+	}
+	if err := json.Unmarshal(msg, &probe); err != nil {
+		return ""
+	}
+	if probe.SchemaVersion > 0 && probe.ID != "" {
+		return probe.ID
+	}
+	return probe.EventID
+}
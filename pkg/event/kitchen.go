@@ -6,9 +6,21 @@ const (
 	KitchenTicketsTopic            = "kitchen.tickets"
 	EventKitchenTicketCreated      = "kitchen.ticket.created"
 	EventKitchenTicketStatusChange = "kitchen.ticket.status_changed"
+	EventKitchenTicketLate         = "kitchen.ticket.late"
+	EventKitchenDecisionRequested  = "kitchen.ticket.decision_requested"
+	EventKitchenDecisionEscalated  = "kitchen.ticket.decision_escalated"
+	EventKitchenDecisionResolved   = "kitchen.ticket.decision_resolved"
+)
+
+// Outcomes the floor can pick when the kitchen asks for a decision.
+const (
+	DecisionOutcomeSubstitute = "substitute" // Make another menu item instead
+	DecisionOutcomeCancel     = "cancel"     // Drop the item
+	DecisionOutcomeProceed    = "proceed"    // Make it anyway, with a note
 )
 
 type KitchenTicketEventMetadata struct {
+	EventID     string    `json:"event_id"`
 	EventType   string    `json:"event_type"`
 	OccurredAt  time.Time `json:"occurred_at"`
 	TicketID    string    `json:"ticket_id"`
@@ -18,9 +30,17 @@ type KitchenTicketEventMetadata struct {
 	Station     string    `json:"station"`
 
 	// Denormalized data for display (Kanban UI)
-	MenuItemName string `json:"menu_item_name,omitempty"`
-	StationName  string `json:"station_name,omitempty"`
-	TableNumber  string `json:"table_number,omitempty"`
+	MenuItemName string   `json:"menu_item_name,omitempty"`
+	StationName  string   `json:"station_name,omitempty"`
+	TableNumber  string   `json:"table_number,omitempty"`
+	Modifiers    []string `json:"modifiers,omitempty"`    // Modifier labels, e.g. "Extras: Cheese"
+	PortionName  string   `json:"portion_name,omitempty"` // e.g. "Half"
+	PrepTime     int      `json:"prep_time,omitempty"`    // Expected minutes of work
+	Course       int      `json:"course,omitempty"`       // Course the item is served in
+
+	// ModelVersion is the ticket's version once the change was saved, so
+	// readers rebuilding tickets from events can still check edits against it
+	ModelVersion int `json:"model_version,omitempty"`
 }
 
 type KitchenTicketCreatedEvent struct {
@@ -40,3 +60,46 @@ type KitchenTicketStatusChangedEvent struct {
 	FinishedAt     *time.Time `json:"finished_at,omitempty"`
 	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
 }
+
+// KitchenTicketLateEvent is published once a ticket goes past its expected
+// ready time by more than the grace allowed for its station.
+type KitchenTicketLateEvent struct {
+	KitchenTicketEventMetadata
+	Status          string    `json:"status"`
+	Quantity        int       `json:"quantity"`
+	ExpectedReadyAt time.Time `json:"expected_ready_at"`
+	LateSeconds     int       `json:"late_seconds"` // How far past the expected ready time
+}
+
+// KitchenDecisionOption is one answer the kitchen offers the floor, e.g.
+// "Substitute with trout" or "Cancel the item".
+type KitchenDecisionOption struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Outcome string `json:"outcome"`
+
+	// Set on substitutes: the menu item made instead and, when it costs
+	// something else, its unit price
+	MenuItemID   string   `json:"menu_item_id,omitempty"`
+	MenuItemName string   `json:"menu_item_name,omitempty"`
+	Price        *float64 `json:"price,omitempty"`
+
+	// Added to the item notes when the option is picked
+	Note string `json:"note,omitempty"`
+}
+
+// KitchenTicketDecisionEvent is published when the kitchen asks the floor
+// about a ticket, when nobody answered in time and the question goes to a
+// manager, and when the floor answers.
+type KitchenTicketDecisionEvent struct {
+	KitchenTicketEventMetadata
+	Question  string                  `json:"question"`
+	Options   []KitchenDecisionOption `json:"options"`
+	RaisedBy  string                  `json:"raised_by,omitempty"`
+	ExpiresAt time.Time               `json:"expires_at"`
+	Escalated bool                    `json:"escalated,omitempty"`
+
+	// Set on decision_resolved
+	Chosen     *KitchenDecisionOption `json:"chosen,omitempty"`
+	AnsweredBy string                 `json:"answered_by,omitempty"`
+}
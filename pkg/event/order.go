@@ -3,15 +3,18 @@ package event
 import "time"
 
 const (
-	OrderItemsTopic          = "orders.items"
-	EventOrderItemCreated    = "order.item.created"
-	EventOrderItemUpdated    = "order.item.updated"
-	EventOrderItemCancelled  = "order.item.cancelled"
+	OrderItemsTopic             = "orders.items"
+	EventOrderItemCreated       = "order.item.created"
+	EventOrderItemUpdated       = "order.item.updated"
+	EventOrderItemCancelled     = "order.item.cancelled"
+	EventOrderItemFired         = "order.item.fired"
+	EventOrderItemStatusChanged = "order.item.status_changed"
 )
 
 // OrderItemEvent represents an order item event published to NATS.
 // This event is consumed by the Kitchen service to create tickets.
 type OrderItemEvent struct {
+	EventID            string    `json:"event_id"`
 	EventType          string    `json:"event_type"`
 	OccurredAt         time.Time `json:"occurred_at"`
 	OrderID            string    `json:"order_id"`
@@ -31,4 +34,46 @@ type OrderItemEvent struct {
 	StationName  string `json:"station_name,omitempty"`
 	TableNumber  string `json:"table_number,omitempty"`
 	TableID      string `json:"table_id,omitempty"`
+
+	// Modifier options picked for the item
+	Modifiers []OrderItemModifier `json:"modifiers,omitempty"`
+
+	// Portion picked for the item and its expected prep time
+	PortionID       string `json:"portion_id,omitempty"`
+	PortionName     string `json:"portion_name,omitempty"`
+	PrepTimeMinutes int    `json:"prep_time_minutes,omitempty"`
+
+	// Course the item is served in; held items wait in standby until the
+	// course fires
+	Course int  `json:"course,omitempty"`
+	Held   bool `json:"held,omitempty"`
+}
+
+// OrderItemModifier is a modifier option picked for an order item, such as
+// "Cooking point: Medium rare" or "Extras: Cheese".
+type OrderItemModifier struct {
+	GroupID    string `json:"group_id,omitempty"`
+	GroupName  string `json:"group_name,omitempty"`
+	OptionID   string `json:"option_id,omitempty"`
+	OptionName string `json:"option_name"`
+}
+
+// Label renders the modifier for tickets and screens.
+func (m OrderItemModifier) Label() string {
+	if m.GroupName == "" {
+		return m.OptionName
+	}
+	return m.GroupName + ": " + m.OptionName
+}
+
+// ModifierLabels renders each modifier with Label.
+func ModifierLabels(modifiers []OrderItemModifier) []string {
+	if len(modifiers) == 0 {
+		return nil
+	}
+	labels := make([]string, len(modifiers))
+	for i, m := range modifiers {
+		labels[i] = m.Label()
+	}
+	return labels
 }
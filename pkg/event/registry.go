@@ -0,0 +1,230 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrUnknownVersion is returned for payloads newer than the registry knows.
+// The consumer fails the message, so it is retried and dead-lettered until
+// the consumer is upgraded and the dead letter replayed.
+var ErrUnknownVersion = errors.New("unknown event schema version")
+
+// Upcaster turns a payload of one schema version into the next one.
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+type schemaKey struct {
+	eventType string
+	version   int
+}
+
+// Registry maps event types and schema versions to the Go types of their
+// payloads, and keeps the upcasters between versions.
+//
+// To change a payload, register its new version with the new type and an
+// upcaster from the previous one. Producers move to the new version right
+// away; consumers upcast what older producers still send.
+type Registry struct {
+	mu        sync.RWMutex
+	types     map[schemaKey]reflect.Type
+	latest    map[string]int
+	upcasters map[schemaKey]Upcaster
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		types:     make(map[schemaKey]reflect.Type),
+		latest:    make(map[string]int),
+		upcasters: make(map[schemaKey]Upcaster),
+	}
+}
+
+// DefaultRegistry holds the events of this package. Events declared in other
+// packages register themselves in it.
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, eventType := range []string{
+		EventOrderItemCreated,
+		EventOrderItemUpdated,
+		EventOrderItemCancelled,
+		EventOrderItemFired,
+		EventOrderItemStatusChanged,
+	} {
+		r.Register(eventType, 1, OrderItemEvent{})
+	}
+
+	r.Register(EventKitchenTicketCreated, 1, KitchenTicketCreatedEvent{})
+	r.Register(EventKitchenTicketStatusChange, 1, KitchenTicketStatusChangedEvent{})
+	r.Register(EventKitchenTicketLate, 1, KitchenTicketLateEvent{})
+	r.Register(EventKitchenDecisionRequested, 1, KitchenTicketDecisionEvent{})
+	r.Register(EventKitchenDecisionEscalated, 1, KitchenTicketDecisionEvent{})
+	r.Register(EventKitchenDecisionResolved, 1, KitchenTicketDecisionEvent{})
+	return r
+}
+
+// Register maps version of eventType to the type of payload. It panics on a
+// version registered twice, which is a programming error.
+func (r *Registry) Register(eventType string, version int, payload any) {
+	if eventType == "" || version <= 0 {
+		panic(fmt.Sprintf("event: invalid registration %q v%d", eventType, version))
+	}
+	t := reflect.TypeOf(payload)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		panic(fmt.Sprintf("event: nil payload for %q v%d", eventType, version))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := schemaKey{eventType, version}
+	if _, ok := r.types[key]; ok {
+		panic(fmt.Sprintf("event: %q v%d registered twice", eventType, version))
+	}
+	r.types[key] = t
+	if version > r.latest[eventType] {
+		r.latest[eventType] = version
+	}
+}
+
+// RegisterUpcaster sets the upcaster from version from of eventType to
+// version from+1.
+func (r *Registry) RegisterUpcaster(eventType string, from int, up Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.upcasters[schemaKey{eventType, from}] = up
+}
+
+// Version returns the latest registered version of eventType, 0 when it is
+// not registered.
+func (r *Registry) Version(eventType string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.latest[eventType]
+}
+
+// New returns a pointer to a new payload of version of eventType.
+func (r *Registry) New(eventType string, version int) (any, bool) {
+	r.mu.RLock()
+	t, ok := r.types[schemaKey{eventType, version}]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return reflect.New(t).Interface(), true
+}
+
+// Marshal wraps evt in an envelope from source and encodes it. The event
+// type must be registered and evt must be the type of its latest version.
+// The correlation and causation IDs come from ctx, see WithCause.
+func (r *Registry) Marshal(ctx context.Context, source string, evt Event) ([]byte, error) {
+	meta := evt.EventMeta()
+	version := r.Version(meta.Type)
+	if version == 0 {
+		return nil, fmt.Errorf("event type %q is not registered", meta.Type)
+	}
+
+	r.mu.RLock()
+	want := r.types[schemaKey{meta.Type, version}]
+	r.mu.RUnlock()
+	got := reflect.TypeOf(evt)
+	for got.Kind() == reflect.Pointer {
+		got = got.Elem()
+	}
+	if got != want {
+		return nil, fmt.Errorf("event %s v%d is a %s, not a %s", meta.Type, version, want, got)
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %s payload: %w", meta.Type, err)
+	}
+
+	env := Envelope{
+		ID:            meta.ID,
+		Type:          meta.Type,
+		SchemaVersion: version,
+		Source:        source,
+		OccurredAt:    meta.OccurredAt,
+		Payload:       payload,
+	}
+	if env.ID == "" {
+		env.ID = NewID()
+	}
+	if env.OccurredAt.IsZero() {
+		env.OccurredAt = time.Now().UTC()
+	}
+	env.CorrelationID, env.CausationID = correlate(ctx, env.ID)
+
+	msg, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %s envelope: %w", meta.Type, err)
+	}
+	return msg, nil
+}
+
+// Open decodes a message, enveloped or not, and upcasts its payload to the
+// latest registered version of its type. Types the registry does not know
+// are returned as they came, for the consumer to skip.
+func (r *Registry) Open(msg []byte) (*Envelope, error) {
+	env, err := Parse(msg)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Upcast(env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Upcast brings the payload of env up to the latest registered version of
+// its type, one version at a time.
+func (r *Registry) Upcast(env *Envelope) error {
+	latest := r.Version(env.Type)
+	if latest == 0 {
+		return nil
+	}
+	if env.SchemaVersion > latest {
+		return fmt.Errorf("%w: %s v%d, latest known is v%d", ErrUnknownVersion, env.Type, env.SchemaVersion, latest)
+	}
+
+	for env.SchemaVersion < latest {
+		r.mu.RLock()
+		up, ok := r.upcasters[schemaKey{env.Type, env.SchemaVersion}]
+		r.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("no upcaster for %s v%d", env.Type, env.SchemaVersion)
+		}
+
+		payload, err := up(env.Payload)
+		if err != nil {
+			return fmt.Errorf("cannot upcast %s v%d: %w", env.Type, env.SchemaVersion, err)
+		}
+		env.Payload = payload
+		env.SchemaVersion++
+	}
+	return nil
+}
+
+// Decode returns the payload of env as a pointer to the Go type registered
+// for its type and version.
+func (r *Registry) Decode(env *Envelope) (any, error) {
+	payload, ok := r.New(env.Type, env.SchemaVersion)
+	if !ok {
+		return nil, fmt.Errorf("event type %q v%d is not registered", env.Type, env.SchemaVersion)
+	}
+	if err := env.Unmarshal(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,177 @@
+// Package idempotency lets event consumers skip events they already handled.
+// A redelivered message, a replayed dead letter or an event republished by
+// the outbox relay carries the same event ID as the first copy; the store
+// remembers the IDs a consumer has processed.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+)
+
+// DefaultTTL is how long a processed event ID is remembered. It only needs to
+// outlive the redeliveries and republishes of an event.
+const DefaultTTL = 7 * 24 * time.Hour
+
+// LoadTTL reads idempotency.ttl from the service config.
+func LoadTTL(config *apt.Config) (time.Duration, error) {
+	if config == nil {
+		return DefaultTTL, nil
+	}
+	value, _ := config.GetString("idempotency.ttl")
+	if value == "" {
+		return DefaultTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return DefaultTTL, fmt.Errorf("invalid idempotency.ttl %q", value)
+	}
+	return ttl, nil
+}
+
+// ClaimTimeout is how long a claim on an event holds. A consumer that stops
+// while handling an event leaves its claim behind; once it times out another
+// delivery of the event takes it over.
+const ClaimTimeout = 5 * time.Minute
+
+// ErrInProgress is returned while another delivery of the event holds its
+// claim. The event is not acknowledged, so it comes back once that delivery
+// finished or gave up.
+var ErrInProgress = errors.New("event is being processed")
+
+// Store records the events a consumer is processing and has processed.
+type Store interface {
+	// Claim atomically records that the event is being processed. It
+	// returns false when the event was already processed and ErrInProgress
+	// while another delivery holds the claim.
+	Claim(ctx context.Context, id string) (bool, error)
+	// Complete records a claimed event as processed.
+	Complete(ctx context.Context, id string) error
+	// Release drops the claim of an event that failed, so a redelivery can
+	// process it.
+	Release(ctx context.Context, id string) error
+}
+
+// Handler wraps next so that it runs once per event ID. Events without an ID
+// always run. The ID is claimed before next runs, so two deliveries of an
+// event cannot both apply it; a failed event releases its claim and is
+// retried. An event that fails to be completed is still acknowledged, since
+// next has already applied it.
+func Handler(store Store, logger apt.Logger, next events.HandlerFunc) events.HandlerFunc {
+	if store == nil {
+		return next
+	}
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+
+	return func(ctx context.Context, msg []byte) error {
+		id := event.IDOf(msg)
+		if id == "" {
+			return next(ctx, msg)
+		}
+
+		claimed, err := store.Claim(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			logger.Debug("skipping already processed event", "event_id", id)
+			return nil
+		}
+
+		if err := next(ctx, msg); err != nil {
+			if releaseErr := store.Release(context.WithoutCancel(ctx), id); releaseErr != nil {
+				logger.Error("cannot release event claim", "event_id", id, "error", releaseErr)
+			}
+			return err
+		}
+
+		if err := store.Complete(context.WithoutCancel(ctx), id); err != nil {
+			logger.Error("cannot mark event as processed", "event_id", id, "error", err)
+		}
+		return nil
+	}
+}
+
+// memoryEntry is an event ID a MemoryStore holds, claimed or processed
+// since at.
+type memoryEntry struct {
+	processed bool
+	at        time.Time
+}
+
+// MemoryStore keeps processed event IDs in memory. It does not survive a
+// restart, so it suits tests and single-instance development setups.
+type MemoryStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu  sync.Mutex
+	ids map[string]memoryEntry
+}
+
+// NewMemoryStore returns a store that forgets IDs after ttl, or DefaultTTL
+// when ttl is not positive.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &MemoryStore{
+		ttl: ttl,
+		now: time.Now,
+		ids: make(map[string]memoryEntry),
+	}
+}
+
+// Claim records id as being processed unless it was processed and has not
+// expired, or another claim on it has not timed out.
+func (s *MemoryStore) Claim(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.ids[id]; ok {
+		if entry.processed && now.Before(entry.at.Add(s.ttl)) {
+			return false, nil
+		}
+		if !entry.processed && now.Before(entry.at.Add(ClaimTimeout)) {
+			return false, ErrInProgress
+		}
+	}
+	s.ids[id] = memoryEntry{at: now}
+	return true, nil
+}
+
+// Complete records id as processed and drops the expired IDs.
+func (s *MemoryStore) Complete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, entry := range s.ids {
+		if entry.processed && !now.Before(entry.at.Add(s.ttl)) {
+			delete(s.ids, key)
+		}
+	}
+	s.ids[id] = memoryEntry{processed: true, at: now}
+	return nil
+}
+
+// Release drops the claim on id.
+func (s *MemoryStore) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.ids[id]; ok && !entry.processed {
+		delete(s.ids, id)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/event"
+)
+
+func testEvent(t *testing.T) []byte {
+	t.Helper()
+	return []byte(`{"event_id":"` + event.NewID() + `","event_type":"test.happened"}`)
+}
+
+func TestHandlerRunsConcurrentDeliveriesOnce(t *testing.T) {
+	msg := testEvent(t)
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	handler := Handler(NewMemoryStore(time.Hour), nil, func(ctx context.Context, msg []byte) error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+
+	first := make(chan error, 1)
+	go func() { first <- handler(context.Background(), msg) }()
+	for runs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A second delivery while the first is still running is not applied
+	// and comes back later
+	if err := handler(context.Background(), msg); !errors.Is(err, ErrInProgress) {
+		t.Fatalf("concurrent delivery error = %v, want %v", err, ErrInProgress)
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("first delivery error = %v", err)
+	}
+	if err := handler(context.Background(), msg); err != nil {
+		t.Fatalf("redelivery error = %v", err)
+	}
+	if got := runs.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
+
+func TestHandlerReleasesFailedEvent(t *testing.T) {
+	msg := testEvent(t)
+
+	var mu sync.Mutex
+	fail := true
+	runs := 0
+	handler := Handler(NewMemoryStore(time.Hour), nil, func(ctx context.Context, msg []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		runs++
+		if fail {
+			fail = false
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	if err := handler(context.Background(), msg); err == nil {
+		t.Fatal("failed delivery returned no error")
+	}
+	if err := handler(context.Background(), msg); err != nil {
+		t.Fatalf("redelivery error = %v", err)
+	}
+	if err := handler(context.Background(), msg); err != nil {
+		t.Fatalf("duplicate delivery error = %v", err)
+	}
+	if runs != 2 {
+		t.Errorf("handler ran %d times, want 2", runs)
+	}
+}
+
+func TestMemoryStoreTakesOverTimedOutClaim(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore(time.Hour)
+	store.now = func() time.Time { return now }
+
+	if claimed, err := store.Claim(context.Background(), "evt-1"); !claimed || err != nil {
+		t.Fatalf("Claim() = %v, %v, want true", claimed, err)
+	}
+
+	// The consumer that claimed it stopped without releasing it
+	now = now.Add(ClaimTimeout)
+	if claimed, err := store.Claim(context.Background(), "evt-1"); !claimed || err != nil {
+		t.Errorf("Claim() after timeout = %v, %v, want true", claimed, err)
+	}
+}
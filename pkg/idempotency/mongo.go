@@ -0,0 +1,189 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection is where a service keeps the event IDs its consumers processed.
+const Collection = "processed_events"
+
+// ErrNoDatabase is returned while the service database is not connected.
+var ErrNoDatabase = errors.New("idempotency database not available")
+
+// Event states. IDs recorded before claims existed have no state and count
+// as processed.
+const (
+	stateProcessing = "processing"
+	stateProcessed  = "processed"
+)
+
+type processedEvent struct {
+	ID          string    `bson:"_id"`
+	Consumer    string    `bson:"consumer"`
+	EventID     string    `bson:"event_id"`
+	State       string    `bson:"state"`
+	ProcessedAt time.Time `bson:"processed_at"`
+}
+
+// MongoStore keeps processed event IDs in the service database, so they
+// survive restarts and are shared by the instances of a service. IDs are
+// kept per consumer: two consumers of the same event each process it once.
+type MongoStore struct {
+	db       func() *mongo.Database
+	consumer string
+	ttl      time.Duration
+	logger   apt.Logger
+}
+
+// NewMongoStore returns a store for consumer in the database db returns. It
+// takes a func because some repositories only connect when their lifecycle
+// starts. IDs expire after ttl, or DefaultTTL when ttl is not positive.
+func NewMongoStore(db func() *mongo.Database, consumer string, ttl time.Duration, logger apt.Logger) *MongoStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &MongoStore{
+		db:       db,
+		consumer: consumer,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// Start creates the index that expires processed IDs.
+func (s *MongoStore) Start(ctx context.Context) error {
+	if err := s.EnsureIndexes(ctx); err != nil {
+		s.logger.Errorf("cannot prepare idempotency store: %v", err)
+	}
+	return nil
+}
+
+// Stop does nothing; the database is closed by its repository.
+func (s *MongoStore) Stop(ctx context.Context) error {
+	return nil
+}
+
+// EnsureIndexes creates the TTL index on processed_at.
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	coll, err := s.collection()
+	if err != nil {
+		return err
+	}
+
+	model := mongo.IndexModel{
+		Keys:    bson.D{{Key: "processed_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(s.ttl.Seconds())),
+	}
+	if _, err := coll.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("cannot create idempotency indexes: %w", err)
+	}
+	return nil
+}
+
+// Claim inserts id as being processed by the consumer. The insert fails on
+// the unique _id when another delivery got there first; the claim is then
+// taken over only when the ID expired or the other claim timed out.
+func (s *MongoStore) Claim(ctx context.Context, id string) (bool, error) {
+	coll, err := s.collection()
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	doc := processedEvent{
+		ID:          s.key(id),
+		Consumer:    s.consumer,
+		EventID:     id,
+		State:       stateProcessing,
+		ProcessedAt: now,
+	}
+	_, err = coll.InsertOne(ctx, doc)
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, fmt.Errorf("cannot claim event %s: %w", id, err)
+	}
+
+	// Mongo removes expired IDs in the background, so one may still be there
+	stale := bson.M{
+		"_id": doc.ID,
+		"$or": bson.A{
+			bson.M{"state": bson.M{"$ne": stateProcessing}, "processed_at": bson.M{"$lte": now.Add(-s.ttl)}},
+			bson.M{"state": stateProcessing, "processed_at": bson.M{"$lte": now.Add(-ClaimTimeout)}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"state": stateProcessing, "processed_at": now}}
+	res, err := coll.UpdateOne(ctx, stale, update)
+	if err != nil {
+		return false, fmt.Errorf("cannot claim event %s: %w", id, err)
+	}
+	if res.ModifiedCount > 0 {
+		return true, nil
+	}
+
+	var current processedEvent
+	err = coll.FindOne(ctx, bson.M{"_id": doc.ID}).Decode(&current)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Expired between our writes; the redelivery claims it
+		return false, ErrInProgress
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot check processed event %s: %w", id, err)
+	}
+	if current.State == stateProcessing {
+		return false, ErrInProgress
+	}
+	return false, nil
+}
+
+// Complete records the claimed id as processed by the consumer.
+func (s *MongoStore) Complete(ctx context.Context, id string) error {
+	coll, err := s.collection()
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"state": stateProcessed, "processed_at": time.Now().UTC()}}
+	if _, err := coll.UpdateOne(ctx, bson.M{"_id": s.key(id)}, update); err != nil {
+		return fmt.Errorf("cannot mark event %s as processed: %w", id, err)
+	}
+	return nil
+}
+
+// Release removes the consumer's claim on id.
+func (s *MongoStore) Release(ctx context.Context, id string) error {
+	coll, err := s.collection()
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": s.key(id), "state": stateProcessing}
+	if _, err := coll.DeleteOne(ctx, filter); err != nil {
+		return fmt.Errorf("cannot release event %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *MongoStore) key(id string) string {
+	return s.consumer + "/" + id
+}
+
+func (s *MongoStore) collection() (*mongo.Collection, error) {
+	db := s.db()
+	if db == nil {
+		return nil, ErrNoDatabase
+	}
+	return db.Collection(Collection), nil
+}
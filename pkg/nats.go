@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/appetiteclub/apt/events"
+	"github.com/appetiteclub/appetite/pkg/event"
 	"github.com/nats-io/nats.go"
 )
 
@@ -20,36 +20,21 @@ func NewNATSPublisher(url string) (*NATSPublisher, error) {
 	return &NATSPublisher{conn: conn}, nil
 }
 
+// Publish sends msg on topic. The event ID, when the payload has one, goes in
+// the Nats-Msg-Id header so streams on the subject drop a republished event.
 func (p *NATSPublisher) Publish(ctx context.Context, topic string, msg []byte) error {
-	return p.conn.Publish(topic, msg)
-}
-
-func (p *NATSPublisher) Close() error {
-	p.conn.Close()
-	return nil
-}
-
-type NATSSubscriber struct {
-	conn *nats.Conn
-}
-
-func NewNATSSubscriber(url string) (*NATSSubscriber, error) {
-	conn, err := nats.Connect(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	id := event.IDOf(msg)
+	if id == "" {
+		return p.conn.Publish(topic, msg)
 	}
-	return &NATSSubscriber{conn: conn}, nil
-}
-
-func (s *NATSSubscriber) Subscribe(ctx context.Context, topic string, handler events.HandlerFunc) error {
-	_, err := s.conn.Subscribe(topic, func(msg *nats.Msg) {
-		if err := handler(ctx, msg.Data); err != nil {
-		}
+	return p.conn.PublishMsg(&nats.Msg{
+		Subject: topic,
+		Header:  nats.Header{nats.MsgIdHdr: []string{id}},
+		Data:    msg,
 	})
-	return err
 }
 
-func (s *NATSSubscriber) Close() error {
-	s.conn.Close()
+func (p *NATSPublisher) Close() error {
+	p.conn.Close()
 	return nil
 }
@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSConsumerConfig configures a NATSConsumer.
+type NATSConsumerConfig struct {
+	URL string // NATS server URL
+	// Stream is created, or updated, with this config. It keeps the topics
+	// the consumer subscribes to. Without a name, each topic is read from
+	// its EventStream.
+	Stream jetstream.StreamConfig
+	// Consumer names the durable consumers, one per topic, as
+	// <Consumer>_<topic>.
+	Consumer string
+	// AckWait is how long a message may be handled before the server
+	// delivers it again. It should outlast the handler; zero keeps the
+	// server default of 30 seconds.
+	AckWait time.Duration
+
+	// Delivery sets the retries before a message is dead-lettered on
+	// dlq.<topic>; the consumer name defaults to Consumer.
+	Delivery DeliveryPolicy
+	Logger   apt.Logger
+}
+
+// NATSConsumer implements events.Subscriber with a durable JetStream consumer
+// per topic. The server keeps where each consumer is and the messages it has
+// not acknowledged, so messages published while the service is down, and
+// the retries pending when it stops, are delivered once it is back.
+type NATSConsumer struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	stream   jetstream.Stream
+	consumer string
+	ackWait  time.Duration
+	policy   DeliveryPolicy
+	logger   apt.Logger
+
+	mu       sync.Mutex
+	streams  map[string]jetstream.Stream
+	consumes []jetstream.ConsumeContext
+}
+
+// NewNATSConsumer connects to NATS and creates or updates the stream, when
+// the config names one.
+func NewNATSConsumer(ctx context.Context, cfg NATSConsumerConfig) (*NATSConsumer, error) {
+	conn, err := nats.Connect(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	var stream jetstream.Stream
+	if cfg.Stream.Name != "" {
+		stream, err = js.CreateOrUpdateStream(ctx, cfg.Stream)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to create/update stream %s: %w", cfg.Stream.Name, err)
+		}
+	}
+
+	if err := ensureDeadLetterStream(ctx, js); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	policy := cfg.Delivery.withDefaults()
+	if policy.Consumer == "" {
+		policy.Consumer = cfg.Consumer
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+
+	return &NATSConsumer{
+		conn:     conn,
+		js:       js,
+		stream:   stream,
+		consumer: cfg.Consumer,
+		ackWait:  cfg.AckWait,
+		policy:   policy,
+		logger:   logger,
+		streams:  make(map[string]jetstream.Stream),
+	}, nil
+}
+
+// streamFor returns the stream keeping topic: the configured one, or else
+// the topic's EventStream, created or updated the first time it is used.
+func (c *NATSConsumer) streamFor(ctx context.Context, topic string) (jetstream.Stream, error) {
+	if c.stream != nil {
+		return c.stream, nil
+	}
+	cfg, ok := EventStream(topic)
+	if !ok {
+		return nil, fmt.Errorf("no stream keeps %s", topic)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stream, ok := c.streams[cfg.Name]; ok {
+		return stream, nil
+	}
+	stream, err := c.js.CreateOrUpdateStream(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create/update stream %s: %w", cfg.Name, err)
+	}
+	c.streams[cfg.Name] = stream
+	return stream, nil
+}
+
+// durableNames replaces the characters NATS does not allow in consumer names.
+var durableNames = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_")
+
+// Subscribe creates or resumes the durable consumer of topic and hands its
+// messages to handler. A new consumer starts with the messages published
+// from then on.
+func (c *NATSConsumer) Subscribe(ctx context.Context, topic string, handler events.HandlerFunc) error {
+	stream, err := c.streamFor(ctx, topic)
+	if err != nil {
+		return err
+	}
+
+	name := durableNames.Replace(c.consumer + "_" + topic)
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Name:          name,
+		Durable:       name,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       c.ackWait,
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		FilterSubject: topic,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create/update consumer %s: %w", name, err)
+	}
+
+	h := streamHandler{js: c.js, stream: stream.CachedInfo().Config.Name, policy: c.policy, logger: c.logger, bySubject: true}
+	consume, err := consumer.Consume(func(msg jetstream.Msg) {
+		h.handle(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", topic, err)
+	}
+
+	c.mu.Lock()
+	c.consumes = append(c.consumes, consume)
+	c.mu.Unlock()
+	return nil
+}
+
+// Close stops consuming and closes the NATS connection. Messages that were
+// not acknowledged stay on the stream for the next start.
+func (c *NATSConsumer) Close() error {
+	c.mu.Lock()
+	consumes := c.consumes
+	c.consumes = nil
+	c.mu.Unlock()
+
+	for _, consume := range consumes {
+		consume.Stop()
+	}
+	c.conn.Close()
+	return nil
+}
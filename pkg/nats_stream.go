@@ -5,11 +5,54 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+// The kitchen keeps kitchen.tickets in a stream, so the events can be read
+// back by services that were not listening when they were published.
+const (
+	KitchenStreamName   = "KITCHEN_EVENTS"
+	KitchenStreamMaxAge = 24 * time.Hour
+)
+
+// The topics the order and table services publish on plain NATS are kept in
+// these streams, so the services consuming them do it with durable consumers.
+const (
+	OrderStreamName   = "ORDER_EVENTS"
+	TableStreamName   = "TABLE_EVENTS"
+	EventStreamMaxAge = 24 * time.Hour
+)
+
+// EventStream returns the stream that keeps topic. Every service creating
+// the stream uses this config, so their updates do not undo each other.
+func EventStream(topic string) (jetstream.StreamConfig, bool) {
+	switch topic {
+	case event.KitchenTicketsTopic:
+		return jetstream.StreamConfig{
+			Name:     KitchenStreamName,
+			Subjects: []string{event.KitchenTicketsTopic},
+			MaxAge:   KitchenStreamMaxAge,
+		}, true
+	case event.OrderItemsTopic, OrderTableTopic:
+		return jetstream.StreamConfig{
+			Name:     OrderStreamName,
+			Subjects: []string{event.OrderItemsTopic, OrderTableTopic},
+			MaxAge:   EventStreamMaxAge,
+		}, true
+	case TableStatusTopic:
+		return jetstream.StreamConfig{
+			Name:     TableStreamName,
+			Subjects: []string{TableStatusTopic},
+			MaxAge:   EventStreamMaxAge,
+		}, true
+	}
+	return jetstream.StreamConfig{}, false
+}
+
 // NATSStream implements events.Stream using NATS JetStream for persistent event streaming.
 type NATSStream struct {
 	conn     *nats.Conn
@@ -17,6 +60,9 @@ type NATSStream struct {
 	stream   jetstream.Stream
 	consumer jetstream.Consumer
 	topic    string
+	name     string
+	policy   DeliveryPolicy
+	logger   apt.Logger
 }
 
 // NATSStreamConfig configures a NATSStream instance.
@@ -27,6 +73,11 @@ type NATSStreamConfig struct {
 	ConsumerName string        // Durable consumer name for this service
 	MaxAge       time.Duration // How long to retain events (e.g., 24 hours)
 	MaxMsgs      int64         // Maximum number of messages to retain (0 = unlimited)
+
+	// Delivery sets the retries before a message is dead-lettered on
+	// dlq.<StreamName>; the consumer name defaults to ConsumerName.
+	Delivery DeliveryPolicy
+	Logger   apt.Logger
 }
 
 // NewNATSStream creates a new NATSStream and ensures the stream and consumer exist.
@@ -75,18 +126,41 @@ func NewNATSStream(cfg NATSStreamConfig) (*NATSStream, error) {
 		return nil, fmt.Errorf("failed to create/update consumer %s: %w", cfg.ConsumerName, err)
 	}
 
+	if err := ensureDeadLetterStream(context.Background(), js); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	policy := cfg.Delivery.withDefaults()
+	if policy.Consumer == "" {
+		policy.Consumer = cfg.ConsumerName
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+
 	return &NATSStream{
 		conn:     conn,
 		js:       js,
 		stream:   stream,
 		consumer: consumer,
 		topic:    cfg.Topic,
+		name:     cfg.StreamName,
+		policy:   policy,
+		logger:   logger,
 	}, nil
 }
 
 // Publish publishes a message to the stream.
 func (s *NATSStream) Publish(ctx context.Context, topic string, msg []byte) error {
-	_, err := s.js.Publish(ctx, topic, msg)
+	var opts []jetstream.PublishOpt
+	// JetStream drops a message whose ID it has seen within the stream's
+	// duplicate window, so a retried publish does not store the event twice
+	if id := event.IDOf(msg); id != "" {
+		opts = append(opts, jetstream.WithMsgID(id))
+	}
+	_, err := s.js.Publish(ctx, topic, msg, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to publish to stream: %w", err)
 	}
@@ -128,18 +202,76 @@ func (s *NATSStream) Fetch(ctx context.Context, limit int) ([]events.StreamMessa
 }
 
 // SubscribeStream subscribes to new messages arriving on the stream (real-time).
+// A message the handler fails on is redelivered with a growing delay, and
+// dead-lettered once it has been delivered Delivery.MaxDeliver times.
 func (s *NATSStream) SubscribeStream(ctx context.Context, handler events.HandlerFunc) error {
 	_, err := s.consumer.Consume(func(msg jetstream.Msg) {
-		if err := handler(ctx, msg.Data()); err != nil {
-			// TODO: Add logging for handler errors
-			msg.Nak() // Negative acknowledge - redelivery
-		} else {
-			msg.Ack()
-		}
+		s.handle(ctx, msg, handler)
 	})
 	return err
 }
 
+func (s *NATSStream) handle(ctx context.Context, msg jetstream.Msg, handler events.HandlerFunc) {
+	h := streamHandler{js: s.js, stream: s.name, policy: s.policy, logger: s.logger}
+	h.handle(ctx, msg, handler)
+}
+
+// streamHandler acks the messages of a JetStream consumer its handler took.
+// A message the handler fails on is redelivered with a growing delay, and
+// dead-lettered once it has been delivered policy.MaxDeliver times.
+type streamHandler struct {
+	js     jetstream.JetStream
+	stream string
+	policy DeliveryPolicy
+	logger apt.Logger
+	// bySubject dead-letters on dlq.<subject> rather than dlq.<stream>.
+	bySubject bool
+}
+
+func (h streamHandler) handle(ctx context.Context, msg jetstream.Msg, handler events.HandlerFunc) {
+	err := handler(ctx, msg.Data())
+	if err == nil {
+		msg.Ack()
+		return
+	}
+
+	delivery := 1
+	if metadata, metaErr := msg.Metadata(); metaErr == nil {
+		delivery = int(metadata.NumDelivered)
+	}
+	log := h.logger.With("stream", h.stream, "subject", msg.Subject(), "consumer", h.policy.Consumer,
+		"delivery", delivery, "max_deliver", h.policy.MaxDeliver)
+
+	if delivery < h.policy.MaxDeliver {
+		wait := h.policy.Backoff(delivery)
+		log.Info("message failed, redelivering", "error", err, "retry_in", wait)
+		msg.NakWithDelay(wait)
+		return
+	}
+
+	log.Error("message failed on its last delivery, dead-lettering it", "error", err)
+	source := h.stream
+	if h.bySubject {
+		source = msg.Subject()
+	}
+	dl := deadLetter{
+		source:     source,
+		stream:     h.stream,
+		subject:    msg.Subject(),
+		header:     msg.Headers(),
+		data:       msg.Data(),
+		deliveries: delivery,
+		reason:     err,
+	}
+	if dlErr := publishDeadLetter(ctx, h.js, h.policy, dl); dlErr != nil {
+		// Keep the message rather than lose it, and try again later
+		log.Error("cannot dead-letter message, redelivering", "error", dlErr)
+		msg.NakWithDelay(h.policy.MaxBackoff)
+		return
+	}
+	msg.Term()
+}
+
 // Subscribe implements events.Subscriber interface.
 // For streams, topic is ignored (already configured in consumer).
 func (s *NATSStream) Subscribe(ctx context.Context, topic string, handler events.HandlerFunc) error {
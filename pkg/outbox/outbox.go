@@ -0,0 +1,221 @@
+// Package outbox keeps the events of a service in its own Mongo database
+// before they reach NATS, so an event is saved together with the change that
+// caused it and a NATS outage delays events instead of losing them.
+//
+// Handlers publish to an Outbox as they would to NATS; a Relay reads the
+// pending entries and publishes them with retries.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection is where a service keeps its outbox entries.
+const Collection = "outbox"
+
+// Entry statuses.
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+// Entry is an event waiting to be published, or one that was.
+type Entry struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Topic         string             `bson:"topic"`
+	Payload       string             `bson:"payload"`
+	Status        string             `bson:"status"`
+	Attempts      int                `bson:"attempts"`
+	LastError     string             `bson:"last_error,omitempty"`
+	BackingOff    bool               `bson:"backing_off,omitempty"` // Failed and waiting for its next try, not leased
+	CreatedAt     time.Time          `bson:"created_at"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"`
+	SentAt        *time.Time         `bson:"sent_at,omitempty"`
+}
+
+// ErrNoDatabase is returned while the service database is not connected.
+var ErrNoDatabase = errors.New("outbox database not available")
+
+// Transactor is a publisher that can save a change and the events it
+// publishes atomically.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// WithTransaction runs fn so that the writes it makes and the events it
+// publishes to publisher are kept together. Publishers other than an outbox
+// have nothing to join, so fn simply runs.
+func WithTransaction(ctx context.Context, publisher events.Publisher, fn func(ctx context.Context) error) error {
+	if tx, ok := publisher.(Transactor); ok {
+		return tx.WithTransaction(ctx, fn)
+	}
+	return fn(ctx)
+}
+
+// Outbox is an events.Publisher that stores events in the service database.
+type Outbox struct {
+	db     func() *mongo.Database
+	logger apt.Logger
+	notify chan struct{}
+
+	mu          sync.Mutex
+	checked     bool
+	transaction bool
+}
+
+// New returns an outbox in the database db returns. It takes a func because
+// some repositories only connect when their lifecycle starts.
+func New(db func() *mongo.Database, logger apt.Logger) *Outbox {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &Outbox{
+		db:     db,
+		logger: logger,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Publish stores the event. Inside WithTransaction it is written in the
+// transaction, so it is only kept if the change is.
+func (o *Outbox) Publish(ctx context.Context, topic string, msg []byte) error {
+	coll, err := o.collection()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	entry := Entry{
+		ID:            primitive.NewObjectID(),
+		Topic:         topic,
+		Payload:       string(msg),
+		Status:        StatusPending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+	if _, err := coll.InsertOne(ctx, entry); err != nil {
+		return fmt.Errorf("cannot store %s event in outbox: %w", topic, err)
+	}
+
+	if mongo.SessionFromContext(ctx) == nil {
+		o.wake()
+	}
+	return nil
+}
+
+// WithTransaction runs fn in a Mongo transaction, passing it a context that
+// the repositories and Publish use to join it. fn runs once: a failed
+// commit is returned, not retried, since a change may not be safe to apply
+// twice.
+//
+// Transactions need a replica set. On a standalone server fn runs without
+// one: the change and its events are written one after the other, which
+// still survives a NATS outage but not a crash between the two writes.
+func (o *Outbox) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	db := o.db()
+	if db == nil || !o.supportsTransactions(ctx, db) {
+		return fn(ctx)
+	}
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("cannot start outbox session: %w", err)
+	}
+	defer session.EndSession(context.Background())
+
+	err = mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
+		if err := session.StartTransaction(); err != nil {
+			return err
+		}
+		if err := fn(sc); err != nil {
+			_ = session.AbortTransaction(context.Background())
+			return err
+		}
+		return session.CommitTransaction(sc)
+	})
+	if err != nil {
+		return err
+	}
+
+	o.wake()
+	return nil
+}
+
+// supportsTransactions asks the server once whether it is part of a replica
+// set or a sharded cluster. When it cannot tell it answers no and asks again
+// next time.
+func (o *Outbox) supportsTransactions(ctx context.Context, db *mongo.Database) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.checked {
+		return o.transaction
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		o.logger.Errorf("cannot check outbox transaction support: %v", err)
+		return false
+	}
+
+	o.checked = true
+	o.transaction = hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !o.transaction {
+		o.logger.Info("mongo is standalone, outbox events are written without transactions")
+	}
+	return o.transaction
+}
+
+// EnsureIndexes creates the indexes the relay reads by, and expires sent
+// entries after retention. A zero retention keeps them.
+func (o *Outbox) EnsureIndexes(ctx context.Context, retention time.Duration) error {
+	coll, err := o.collection()
+	if err != nil {
+		return err
+	}
+
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "topic", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+	}
+	if retention > 0 {
+		models = append(models, mongo.IndexModel{
+			Keys:    bson.D{{Key: "sent_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(retention.Seconds())),
+		})
+	}
+
+	if _, err := coll.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("cannot create outbox indexes: %w", err)
+	}
+	return nil
+}
+
+// wake tells the relay there are new entries, without waiting for it.
+func (o *Outbox) wake() {
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (o *Outbox) collection() (*mongo.Collection, error) {
+	db := o.db()
+	if db == nil {
+		return nil, ErrNoDatabase
+	}
+	return db.Collection(Collection), nil
+}
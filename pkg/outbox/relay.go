@@ -0,0 +1,464 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// DefaultInterval is how often the relay looks for due entries when it
+	// is not woken by a new one.
+	DefaultInterval = time.Second
+
+	// DefaultMaxAttempts is how many times an entry is tried before it is
+	// marked failed and left for an operator to re-drive. At the capped
+	// backoff that is over an hour of NATS being down.
+	DefaultMaxAttempts = 80
+
+	// DefaultMinBackoff and DefaultMaxBackoff bound the wait between tries,
+	// which doubles on every failure. The cap is short because a backing
+	// off entry holds up its whole topic.
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+
+	// DefaultLease is how long a claimed entry is hidden from other relays
+	// while it is published.
+	DefaultLease = 30 * time.Second
+
+	// DefaultRetention is how long sent entries are kept.
+	DefaultRetention = 24 * time.Hour
+
+	// batchSize caps the entries published per pass, so a long backlog does
+	// not hold off the shutdown.
+	batchSize = 100
+
+	// claimCandidates is how many due entries a claim tries, in case other
+	// relays lease the first ones.
+	claimCandidates = 10
+)
+
+// RelayConfig tunes how the relay publishes entries.
+type RelayConfig struct {
+	Interval    time.Duration
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	Lease       time.Duration
+	Retention   time.Duration
+}
+
+// DefaultRelayConfig returns the relay defaults.
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		Interval:    DefaultInterval,
+		MaxAttempts: DefaultMaxAttempts,
+		MinBackoff:  DefaultMinBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+		Lease:       DefaultLease,
+		Retention:   DefaultRetention,
+	}
+}
+
+// LoadRelayConfig reads the outbox section of the service config.
+func LoadRelayConfig(config *apt.Config) (RelayConfig, error) {
+	cfg := DefaultRelayConfig()
+	if config == nil {
+		return cfg, nil
+	}
+
+	durations := []struct {
+		key  string
+		dst  *time.Duration
+		zero bool
+	}{
+		{"outbox.interval", &cfg.Interval, false},
+		{"outbox.min_backoff", &cfg.MinBackoff, false},
+		{"outbox.max_backoff", &cfg.MaxBackoff, false},
+		{"outbox.lease", &cfg.Lease, false},
+		{"outbox.retention", &cfg.Retention, true},
+	}
+	for _, d := range durations {
+		value, _ := config.GetString(d.key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 || (parsed == 0 && !d.zero) {
+			return cfg, fmt.Errorf("invalid %s %q", d.key, value)
+		}
+		*d.dst = parsed
+	}
+
+	if value, _ := config.GetString("outbox.max_attempts"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts <= 0 {
+			return cfg, fmt.Errorf("invalid outbox.max_attempts %q", value)
+		}
+		cfg.MaxAttempts = attempts
+	}
+
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		return cfg, fmt.Errorf("outbox.max_backoff %s is shorter than outbox.min_backoff %s", cfg.MaxBackoff, cfg.MinBackoff)
+	}
+	return cfg, nil
+}
+
+// Relay publishes outbox entries to NATS and marks them sent. Entries that
+// cannot be published are retried with a growing backoff until they run out
+// of attempts.
+//
+// Entries on a topic are published in the order they were stored: an entry
+// waits while an earlier one on its topic is pending, also while that one
+// backs off, so subscribers never see an aggregate's events out of order.
+// An entry that runs out of attempts is marked failed and no longer holds
+// back the ones after it.
+//
+// Once a publish succeeds after a failure, the entries backing off are due
+// again, so topics held up by a NATS outage move as soon as it ends. An
+// entry that fails while NATS works gets one more try per pass, and runs out
+// of attempts, releasing its topic, sooner.
+type Relay struct {
+	outbox    *Outbox
+	store     entryStore
+	publisher events.Publisher
+	config    RelayConfig
+	logger    apt.Logger
+
+	// backingOff is set when a publish fails and cleared when the entries
+	// backing off are made due again.
+	backingOff atomic.Bool
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay returns a relay from the outbox to publisher.
+func NewRelay(outbox *Outbox, publisher events.Publisher, config RelayConfig, logger apt.Logger) *Relay {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	defaults := DefaultRelayConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = defaults.MinBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	return &Relay{
+		outbox:    outbox,
+		store:     outbox,
+		publisher: publisher,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Start creates the outbox indexes and starts publishing in the background.
+func (r *Relay) Start(ctx context.Context) error {
+	if err := r.outbox.EnsureIndexes(ctx, r.config.Retention); err != nil {
+		r.logger.Errorf("cannot prepare outbox: %v", err)
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	r.mu.Lock()
+	r.cancel = cancel
+	r.done = done
+	r.mu.Unlock()
+
+	go r.run(loopCtx, done)
+
+	r.logger.Info("outbox relay started", "interval", r.config.Interval)
+	return nil
+}
+
+// Stop ends the relay after the entry it is publishing, if any.
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (r *Relay) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.Flush(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.outbox.notify:
+		}
+	}
+}
+
+// Flush publishes the entries that are due and next in line on their topic,
+// oldest first, and returns how many were sent.
+func (r *Relay) Flush(ctx context.Context) int {
+	sent := 0
+	retried := false
+	for i := 0; i < batchSize; i++ {
+		if ctx.Err() != nil {
+			return sent
+		}
+
+		entry, err := r.claim(ctx)
+		if err != nil {
+			if !errors.Is(err, ErrNoDatabase) && !errors.Is(err, context.Canceled) {
+				r.logger.Errorf("cannot read outbox: %v", err)
+			}
+			return sent
+		}
+		if entry == nil {
+			return sent
+		}
+
+		if !r.deliver(ctx, entry) {
+			continue
+		}
+		sent++
+
+		// NATS takes events again: retry what failed meanwhile, once per
+		// pass so an entry that keeps failing does not take the batch
+		if !retried && r.backingOff.Swap(false) {
+			retried = true
+			r.retryBackingOff(ctx)
+		}
+	}
+	return sent
+}
+
+// retryBackingOff makes the entries backing off due now.
+func (r *Relay) retryBackingOff(ctx context.Context) {
+	retried, err := r.store.retryNow(ctx, time.Now().UTC())
+	if err != nil {
+		r.logger.Errorf("cannot retry outbox entries: %v", err)
+		return
+	}
+	if retried > 0 {
+		r.logger.Info("publishing works again, retrying outbox entries", "entries", retried)
+	}
+}
+
+// claim takes the oldest due entry that is next in line on its topic and
+// pushes its next attempt past the lease, so a second relay neither
+// publishes it at the same time nor moves on to the entries behind it.
+func (r *Relay) claim(ctx context.Context) (*Entry, error) {
+	now := time.Now().UTC()
+	until := now.Add(r.config.Lease)
+
+	heads, err := r.store.dueHeads(ctx, now, claimCandidates)
+	if err != nil {
+		return nil, err
+	}
+	for i := range heads {
+		leased, err := r.store.lease(ctx, heads[i].ID, now, until)
+		if err != nil {
+			return nil, err
+		}
+		if leased {
+			entry := heads[i]
+			entry.NextAttemptAt = until
+			return &entry, nil
+		}
+	}
+	return nil, nil
+}
+
+// deliver publishes the entry and records the outcome. It reports whether
+// the entry was sent.
+func (r *Relay) deliver(ctx context.Context, entry *Entry) bool {
+	now := time.Now().UTC()
+	publishErr := r.publisher.Publish(ctx, entry.Topic, []byte(entry.Payload))
+
+	// Record the outcome even when the relay is stopping, or a sent entry
+	// would be published again after its lease
+	recordCtx := context.WithoutCancel(ctx)
+
+	var err error
+	if publishErr == nil {
+		err = r.store.markSent(recordCtx, entry.ID, now)
+	} else {
+		attempts := entry.Attempts + 1
+		status := StatusPending
+		if attempts >= r.config.MaxAttempts {
+			status = StatusFailed
+			r.logger.Errorf("outbox entry %s to %s failed after %d attempts: %v", entry.ID.Hex(), entry.Topic, attempts, publishErr)
+		} else {
+			r.logger.Debugf("outbox entry %s to %s failed, retrying: %v", entry.ID.Hex(), entry.Topic, publishErr)
+		}
+		err = r.store.markFailed(recordCtx, entry.ID, status, publishErr.Error(), now.Add(r.backoff(attempts)))
+		r.backingOff.Store(true)
+	}
+	if err != nil {
+		r.logger.Errorf("cannot update outbox entry %s: %v", entry.ID.Hex(), err)
+	}
+	return publishErr == nil
+}
+
+// backoff returns the wait before the next try of an entry tried attempts
+// times.
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := r.config.MinBackoff
+	for i := 1; i < attempts && wait < r.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > r.config.MaxBackoff {
+		wait = r.config.MaxBackoff
+	}
+	return wait
+}
+
+// entryStore is where the relay reads entries and records what became of
+// them. The Outbox keeps them in Mongo.
+type entryStore interface {
+	// dueHeads returns, oldest first and up to limit, the pending entries
+	// that are due by now and have no earlier pending entry on their topic.
+	dueHeads(ctx context.Context, now time.Time, limit int) ([]Entry, error)
+	// lease moves the next attempt of the entry to until if it is still
+	// pending and due by now, and reports whether it did.
+	lease(ctx context.Context, id primitive.ObjectID, now, until time.Time) (bool, error)
+	markSent(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// markFailed records a failed try; a pending entry backs off until next.
+	markFailed(ctx context.Context, id primitive.ObjectID, status, lastError string, next time.Time) error
+	// retryNow makes the pending entries that back off due by now, leaving
+	// out the leased ones, and returns how many there were.
+	retryNow(ctx context.Context, now time.Time) (int64, error)
+}
+
+func (o *Outbox) dueHeads(ctx context.Context, now time.Time, limit int) ([]Entry, error) {
+	coll, err := o.collection()
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": StatusPending}}},
+		{{Key: "$sort", Value: bson.D{{Key: "topic", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$topic", "head": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$head"}}},
+		{{Key: "$match", Value: bson.M{"next_attempt_at": bson.M{"$lte": now}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var heads []Entry
+	if err := cursor.All(ctx, &heads); err != nil {
+		return nil, err
+	}
+	return heads, nil
+}
+
+func (o *Outbox) lease(ctx context.Context, id primitive.ObjectID, now, until time.Time) (bool, error) {
+	coll, err := o.collection()
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.M{
+		"_id":             id,
+		"status":          StatusPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set":   bson.M{"next_attempt_at": until},
+		"$unset": bson.M{"backing_off": ""},
+	}
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (o *Outbox) markSent(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	coll, err := o.collection()
+	if err != nil {
+		return err
+	}
+
+	_, err = coll.UpdateByID(ctx, id, bson.M{
+		"$set":   bson.M{"status": StatusSent, "sent_at": at},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"last_error": ""},
+	})
+	return err
+}
+
+func (o *Outbox) markFailed(ctx context.Context, id primitive.ObjectID, status, lastError string, next time.Time) error {
+	coll, err := o.collection()
+	if err != nil {
+		return err
+	}
+
+	_, err = coll.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{
+			"status":          status,
+			"last_error":      lastError,
+			"next_attempt_at": next,
+			"backing_off":     status == StatusPending,
+		},
+		"$inc": bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (o *Outbox) retryNow(ctx context.Context, now time.Time) (int64, error) {
+	coll, err := o.collection()
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.M{"status": StatusPending, "backing_off": true}
+	update := bson.M{
+		"$set":   bson.M{"next_attempt_at": now},
+		"$unset": bson.M{"backing_off": ""},
+	}
+	result, err := coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
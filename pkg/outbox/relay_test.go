@@ -0,0 +1,240 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore keeps entries the way the Mongo collection does, for the relay
+// to run against without a database.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries []*Entry
+}
+
+func (s *memoryStore) add(topic, payload string, createdAt time.Time) *Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &Entry{
+		ID:            primitive.NewObjectID(),
+		Topic:         topic,
+		Payload:       payload,
+		Status:        StatusPending,
+		CreatedAt:     createdAt,
+		NextAttemptAt: createdAt,
+	}
+	s.entries = append(s.entries, entry)
+	return entry
+}
+
+func (s *memoryStore) get(id primitive.ObjectID) *Entry {
+	for _, entry := range s.entries {
+		if entry.ID == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// expire makes every pending entry due, as if their backoff had passed.
+func (s *memoryStore) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		if entry.Status == StatusPending {
+			entry.NextAttemptAt = entry.CreatedAt
+		}
+	}
+}
+
+func (s *memoryStore) dueHeads(ctx context.Context, now time.Time, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]*Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if entry.Status == StatusPending {
+			pending = append(pending, entry)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+
+	seen := map[string]bool{}
+	var heads []Entry
+	for _, entry := range pending {
+		if seen[entry.Topic] {
+			continue
+		}
+		seen[entry.Topic] = true
+		if !entry.NextAttemptAt.After(now) && len(heads) < limit {
+			heads = append(heads, *entry)
+		}
+	}
+	return heads, nil
+}
+
+func (s *memoryStore) lease(ctx context.Context, id primitive.ObjectID, now, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.get(id)
+	if entry == nil || entry.Status != StatusPending || entry.NextAttemptAt.After(now) {
+		return false, nil
+	}
+	entry.NextAttemptAt = until
+	entry.BackingOff = false
+	return true, nil
+}
+
+func (s *memoryStore) markSent(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.get(id)
+	entry.Status = StatusSent
+	entry.SentAt = &at
+	entry.Attempts++
+	entry.LastError = ""
+	return nil
+}
+
+func (s *memoryStore) markFailed(ctx context.Context, id primitive.ObjectID, status, lastError string, next time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.get(id)
+	entry.Status = status
+	entry.LastError = lastError
+	entry.NextAttemptAt = next
+	entry.BackingOff = status == StatusPending
+	entry.Attempts++
+	return nil
+}
+
+func (s *memoryStore) retryNow(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var retried int64
+	for _, entry := range s.entries {
+		if entry.Status == StatusPending && entry.BackingOff {
+			entry.NextAttemptAt = now
+			entry.BackingOff = false
+			retried++
+		}
+	}
+	return retried, nil
+}
+
+// flakyPublisher records what it publishes and fails the payloads in fail
+// as many times as given.
+type flakyPublisher struct {
+	fail      map[string]int
+	published []string
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, topic string, msg []byte) error {
+	if p.fail[string(msg)] > 0 {
+		p.fail[string(msg)]--
+		return errors.New("nats unavailable")
+	}
+	p.published = append(p.published, string(msg))
+	return nil
+}
+
+func newTestRelay(store *memoryStore, publisher *flakyPublisher, maxAttempts int) *Relay {
+	r := NewRelay(New(nil, nil), publisher, RelayConfig{MaxAttempts: maxAttempts, MinBackoff: time.Minute}, nil)
+	r.store = store
+	return r
+}
+
+func TestRelayKeepsTopicOrderWhileAnEntryBacksOff(t *testing.T) {
+	store := &memoryStore{}
+	start := time.Now().UTC().Add(-time.Minute)
+	store.add("orders.items", "item-1", start)
+	store.add("orders.items", "item-2", start.Add(time.Second))
+	store.add("tables.status", "table-1", start.Add(2*time.Second))
+	store.add("orders.items", "item-3", start.Add(3*time.Second))
+
+	publisher := &flakyPublisher{fail: map[string]int{"item-1": 2}}
+	relay := newTestRelay(store, publisher, 5)
+
+	// item-1 fails and backs off; the other order items wait behind it while
+	// the table topic goes on. The table event going out retries item-1
+	// once, which fails again
+	if sent := relay.Flush(context.Background()); sent != 1 {
+		t.Fatalf("first Flush() sent %d, want 1", sent)
+	}
+	if got := publisher.published; len(got) != 1 || got[0] != "table-1" {
+		t.Fatalf("published %v, want [table-1]", got)
+	}
+
+	store.expire()
+	if sent := relay.Flush(context.Background()); sent != 3 {
+		t.Fatalf("second Flush() sent %d, want 3", sent)
+	}
+	want := []string{"table-1", "item-1", "item-2", "item-3"}
+	if got := publisher.published; len(got) != len(want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+	for i := range want {
+		if publisher.published[i] != want[i] {
+			t.Fatalf("published %v, want %v", publisher.published, want)
+		}
+	}
+}
+
+func TestRelayFailedEntryReleasesItsTopic(t *testing.T) {
+	store := &memoryStore{}
+	start := time.Now().UTC().Add(-time.Minute)
+	poison := store.add("orders.items", "item-1", start)
+	store.add("orders.items", "item-2", start.Add(time.Second))
+
+	publisher := &flakyPublisher{fail: map[string]int{"item-1": 1}}
+	relay := newTestRelay(store, publisher, 1)
+
+	if sent := relay.Flush(context.Background()); sent != 1 {
+		t.Fatalf("Flush() sent %d, want 1", sent)
+	}
+	if got := publisher.published; len(got) != 1 || got[0] != "item-2" {
+		t.Errorf("published %v, want [item-2]", got)
+	}
+	if poison.Status != StatusFailed || poison.Attempts != 1 {
+		t.Errorf("failed entry = %s after %d attempts, want %s after 1", poison.Status, poison.Attempts, StatusFailed)
+	}
+}
+
+func TestRelayRetriesBackedOffEntriesOncePublishingWorks(t *testing.T) {
+	store := &memoryStore{}
+	start := time.Now().UTC().Add(-time.Minute)
+	first := store.add("orders.items", "item-1", start)
+	store.add("orders.items", "item-2", start.Add(time.Second))
+
+	publisher := &flakyPublisher{fail: map[string]int{"item-1": 1}}
+	relay := newTestRelay(store, publisher, 5)
+
+	// NATS is down: item-1 backs off a minute and holds up its topic
+	if sent := relay.Flush(context.Background()); sent != 0 {
+		t.Fatalf("first Flush() sent %d, want 0", sent)
+	}
+	if !first.BackingOff {
+		t.Fatal("failed entry is not backing off")
+	}
+
+	// NATS is back: a new table event goes out and item-1 does not wait
+	// out its backoff
+	store.add("tables.status", "table-1", time.Now().UTC())
+	if sent := relay.Flush(context.Background()); sent != 3 {
+		t.Fatalf("second Flush() sent %d, want 3", sent)
+	}
+	want := []string{"table-1", "item-1", "item-2"}
+	for i := range want {
+		if i >= len(publisher.published) || publisher.published[i] != want[i] {
+			t.Fatalf("published %v, want %v", publisher.published, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// DefaultReplaySize is how many events a stream keeps for subscribers that
+// reconnect after a short drop.
+const DefaultReplaySize = 1024
+
+// ReplayBuffer numbers the events a stream sends and keeps the most recent
+// ones, so a subscriber that lost its connection can pick up after the last
+// sequence it saw instead of reloading everything. Sequences start over when
+// the process restarts, so each buffer also has an epoch that subscribers
+// send back along with the sequence.
+//
+// It is not safe for concurrent use. Streams guard it with the same lock
+// they hold while fanning events out, so registering a subscriber and
+// reading what it missed happen at once.
+type ReplayBuffer[T any] struct {
+	events []T
+	size   int
+	last   uint64
+	epoch  string
+}
+
+// NewReplayBuffer returns a buffer holding up to size events.
+func NewReplayBuffer[T any](size int) *ReplayBuffer[T] {
+	if size <= 0 {
+		size = DefaultReplaySize
+	}
+	return &ReplayBuffer[T]{
+		events: make([]T, 0, size),
+		size:   size,
+		epoch:  newEpoch(),
+	}
+}
+
+// newEpoch returns a random id for a new buffer, falling back to the time
+// when no randomness is available.
+func newEpoch() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Epoch identifies this buffer's numbering. A sequence only means something
+// together with the epoch it was given in.
+func (b *ReplayBuffer[T]) Epoch() string {
+	return b.epoch
+}
+
+// Append stores evt and returns the sequence number assigned to it.
+// Sequences start at 1.
+func (b *ReplayBuffer[T]) Append(evt T) uint64 {
+	b.last++
+	if len(b.events) < b.size {
+		b.events = append(b.events, evt)
+	} else {
+		b.events[int((b.last-1)%uint64(b.size))] = evt
+	}
+	return b.last
+}
+
+// Last returns the sequence of the most recent event, 0 when none was sent.
+func (b *ReplayBuffer[T]) Last() uint64 {
+	return b.last
+}
+
+// Since returns the events sent after the given sequence of epoch, oldest
+// first. ok is false when the buffer cannot cover the gap: the sequence is
+// from another epoch, because the stream restarted since the subscriber last
+// saw it, or the events were already dropped.
+func (b *ReplayBuffer[T]) Since(epoch string, after uint64) (events []T, ok bool) {
+	if epoch != b.epoch || after > b.last {
+		return nil, false
+	}
+	missed := b.last - after
+	if missed > uint64(len(b.events)) {
+		return nil, false
+	}
+
+	events = make([]T, 0, missed)
+	for seq := after + 1; seq <= b.last; seq++ {
+		events = append(events, b.events[int((seq-1)%uint64(b.size))])
+	}
+	return events, true
+}
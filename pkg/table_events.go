@@ -1,6 +1,10 @@
 package pkg
 
-import "time"
+import (
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/event"
+)
 
 const (
 	// TableStatusTopic delivers authoritative status changes for tables.
@@ -16,11 +20,21 @@ const (
 	EventTableIntentQueued = "table.intent.queued"
 	// EventOrderTableRejected identifies a rejection emitted by the order service.
 	EventOrderTableRejected = "order.table.rejected"
+	// EventOrderBillSettled identifies a fully paid order bill.
+	EventOrderBillSettled = "order.bill.settled"
 )
 
+func init() {
+	event.DefaultRegistry.Register(EventTableStatusChanged, 1, TableStatusEvent{})
+	event.DefaultRegistry.Register(EventTableIntentQueued, 1, TableIntentEvent{})
+	event.DefaultRegistry.Register(EventOrderTableRejected, 1, OrderTableRejectionEvent{})
+	event.DefaultRegistry.Register(EventOrderBillSettled, 1, OrderBillSettledEvent{})
+}
+
 // TableStatusEvent captures the minimal information the order service needs to
 // reason about a table's availability.
 type TableStatusEvent struct {
+	EventID        string    `json:"event_id"`
 	EventType      string    `json:"event_type"`
 	TableID        string    `json:"table_id"`
 	Status         string    `json:"status"`
@@ -28,10 +42,16 @@ type TableStatusEvent struct {
 	Reason         string    `json:"reason,omitempty"`
 	Source         string    `json:"source,omitempty"`
 	OccurredAt     time.Time `json:"occurred_at"`
+
+	// Number shown for the table and the waiter serving it, empty when
+	// nobody is assigned
+	Number     string `json:"number,omitempty"`
+	AssignedTo string `json:"assigned_to,omitempty"`
 }
 
 // TableIntentEvent communicates that a requested transition was deferred.
 type TableIntentEvent struct {
+	EventID        string    `json:"event_id"`
 	EventType      string    `json:"event_type"`
 	TableID        string    `json:"table_id"`
 	RequestedState string    `json:"requested_state"`
@@ -44,6 +64,7 @@ type TableIntentEvent struct {
 // OrderTableRejectionEvent captures rejections performed by the order service
 // whenever a table transition blocks an operation.
 type OrderTableRejectionEvent struct {
+	EventID    string    `json:"event_id"`
 	EventType  string    `json:"event_type"`
 	TableID    string    `json:"table_id"`
 	OrderID    string    `json:"order_id,omitempty"`
@@ -52,3 +73,39 @@ type OrderTableRejectionEvent struct {
 	Status     string    `json:"status"`
 	OccurredAt time.Time `json:"occurred_at"`
 }
+
+// OrderBillSettledEvent is emitted by the order service once the payments
+// recorded against an order cover its bill. Amounts are in the venue currency.
+type OrderBillSettledEvent struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	TableID       string    `json:"table_id"`
+	OrderID       string    `json:"order_id"`
+	Subtotal      float64   `json:"subtotal"`
+	ServiceCharge float64   `json:"service_charge"`
+	Tax           float64   `json:"tax"`
+	Tip           float64   `json:"tip"`
+	Total         float64   `json:"total"`
+	Paid          float64   `json:"paid"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// EventMeta implements event.Event.
+func (e TableStatusEvent) EventMeta() event.Meta {
+	return event.Meta{ID: e.EventID, Type: e.EventType, OccurredAt: e.OccurredAt}
+}
+
+// EventMeta implements event.Event.
+func (e TableIntentEvent) EventMeta() event.Meta {
+	return event.Meta{ID: e.EventID, Type: e.EventType, OccurredAt: e.OccurredAt}
+}
+
+// EventMeta implements event.Event.
+func (e OrderTableRejectionEvent) EventMeta() event.Meta {
+	return event.Meta{ID: e.EventID, Type: e.EventType, OccurredAt: e.OccurredAt}
+}
+
+// EventMeta implements event.Event.
+func (e OrderBillSettledEvent) EventMeta() event.Meta {
+	return event.Meta{ID: e.EventID, Type: e.EventType, OccurredAt: e.OccurredAt}
+}
@@ -0,0 +1,130 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/cloudevents"
+	"github.com/appetiteclub/apt"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// StreamName is the JetStream stream the bridge keeps the exported topics
+// in, so the events published while a webhook or the bridge itself is down
+// are forwarded once it is back. The topics are copied from the streams the
+// services keep them in.
+const StreamName = "BRIDGE_EVENTS"
+
+// DefaultRetention is how long the stream keeps events for a webhook.
+const DefaultRetention = 24 * time.Hour
+
+// ackMargin is added to the webhook timeout for how long a delivery may take
+// before the server hands the event out again.
+const ackMargin = 30 * time.Second
+
+// Bridge subscribes every webhook to the exported topics. Each webhook has
+// its own durable consumers, so one that is down or slow only delays and
+// retries its own events.
+type Bridge struct {
+	natsURL    string
+	retention  time.Duration
+	forwarders []*Forwarder
+	logger     apt.Logger
+
+	consumers []*pkg.NATSConsumer
+}
+
+// New returns a bridge from NATS at natsURL to hooks. The stream keeps
+// events for retention, or DefaultRetention when it is not positive.
+func New(natsURL string, retention time.Duration, hooks []Webhook, logger apt.Logger) *Bridge {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	b := &Bridge{natsURL: natsURL, retention: retention, logger: logger}
+	for _, hook := range hooks {
+		b.forwarders = append(b.forwarders, NewForwarder(hook, logger))
+	}
+	return b
+}
+
+// Forwarders returns the forwarders of the bridge, one per webhook.
+func (b *Bridge) Forwarders() []*Forwarder {
+	return b.forwarders
+}
+
+// Start subscribes the webhooks.
+func (b *Bridge) Start(ctx context.Context) error {
+	if len(b.forwarders) == 0 {
+		b.logger.Info("no webhooks configured, nothing to forward")
+		return nil
+	}
+
+	topics := make([]string, 0, len(cloudevents.Topics))
+	for topic := range cloudevents.Topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	for _, f := range b.forwarders {
+		hook := f.Webhook()
+		consumer, err := pkg.NewNATSConsumer(ctx, pkg.NATSConsumerConfig{
+			URL:      b.natsURL,
+			Stream:   b.streamConfig(topics),
+			Consumer: hook.Delivery.Consumer,
+			AckWait:  hook.Timeout + ackMargin,
+			Delivery: hook.Delivery,
+			Logger:   b.logger,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot connect webhook %s to NATS: %w", hook.Name, err)
+		}
+		b.consumers = append(b.consumers, consumer)
+
+		for _, topic := range topics {
+			if err := consumer.Subscribe(ctx, topic, f.Handler(topic)); err != nil {
+				return fmt.Errorf("cannot subscribe webhook %s to %s: %w", hook.Name, topic, err)
+			}
+		}
+		b.logger.Info("forwarding events to webhook", "webhook", hook.Name, "url", redactURL(hook.URL), "types", hook.Types, "mode", hook.Mode)
+	}
+	return nil
+}
+
+// streamConfig returns the stream for topics. A subject can only be in one
+// stream, so the topics the services already keep in theirs are sourced
+// from there.
+func (b *Bridge) streamConfig(topics []string) jetstream.StreamConfig {
+	cfg := jetstream.StreamConfig{
+		Name:   StreamName,
+		MaxAge: b.retention,
+	}
+	for _, topic := range topics {
+		if source, ok := pkg.EventStream(topic); ok {
+			cfg.Sources = append(cfg.Sources, &jetstream.StreamSource{
+				Name:          source.Name,
+				FilterSubject: topic,
+			})
+			continue
+		}
+		cfg.Subjects = append(cfg.Subjects, topic)
+	}
+	return cfg
+}
+
+// Stop closes the consumers. Events being forwarded or waiting for a retry
+// stay on the stream and are delivered after the next start.
+func (b *Bridge) Stop(ctx context.Context) error {
+	for _, consumer := range b.consumers {
+		if err := consumer.Close(); err != nil {
+			b.logger.Error("cannot close consumer", "error", err)
+		}
+	}
+	b.consumers = nil
+	return nil
+}
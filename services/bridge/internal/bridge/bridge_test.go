@@ -0,0 +1,34 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/event"
+)
+
+func TestStreamConfigSourcesServiceStreams(t *testing.T) {
+	b := New("nats://localhost:4222", 0, nil, nil)
+	cfg := b.streamConfig([]string{event.KitchenTicketsTopic, event.OrderItemsTopic, pkg.TableStatusTopic, "menu.items"})
+
+	if cfg.Name != StreamName || cfg.MaxAge != DefaultRetention {
+		t.Errorf("streamConfig() = %s kept %s, want %s kept %s", cfg.Name, cfg.MaxAge, StreamName, DefaultRetention)
+	}
+	if len(cfg.Subjects) != 1 || cfg.Subjects[0] != "menu.items" {
+		t.Errorf("streamConfig() subjects = %v, want [menu.items]", cfg.Subjects)
+	}
+
+	want := map[string]string{
+		event.KitchenTicketsTopic: pkg.KitchenStreamName,
+		event.OrderItemsTopic:     pkg.OrderStreamName,
+		pkg.TableStatusTopic:      pkg.TableStreamName,
+	}
+	if len(cfg.Sources) != len(want) {
+		t.Fatalf("streamConfig() sources = %+v, want %d", cfg.Sources, len(want))
+	}
+	for _, source := range cfg.Sources {
+		if want[source.FilterSubject] != source.Name {
+			t.Errorf("streamConfig() sources %s from %s, want from %s", source.FilterSubject, source.Name, want[source.FilterSubject])
+		}
+	}
+}
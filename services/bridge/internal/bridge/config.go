@@ -0,0 +1,140 @@
+package bridge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/cloudevents"
+	"github.com/appetiteclub/apt"
+)
+
+// DefaultTimeout bounds a single webhook call.
+const DefaultTimeout = 10 * time.Second
+
+// Webhook is an HTTP endpoint events are forwarded to.
+type Webhook struct {
+	Name string
+	URL  string
+	// Secret signs every request, see SignatureHeader. Requests are not
+	// signed without one.
+	Secret string
+	// Types are the event types forwarded: exact types, prefixes ending in
+	// ".*", or "*" for all of them. No types means all of them.
+	Types   []string
+	Mode    cloudevents.Mode
+	Timeout time.Duration
+	// Delivery says how often an event the webhook fails on is retried
+	// before it is dead-lettered.
+	Delivery pkg.DeliveryPolicy
+}
+
+// Accepts tells whether events of eventType are forwarded to the webhook.
+func (w Webhook) Accepts(eventType string) bool {
+	if len(w.Types) == 0 {
+		return true
+	}
+	for _, pattern := range w.Types {
+		switch {
+		case pattern == "*", pattern == eventType:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// LoadWebhooks reads the webhooks named in bridge.webhooks, a comma
+// separated list, each from webhooks.<name>.
+func LoadWebhooks(config *apt.Config, consumer string) ([]Webhook, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	value, _ := config.GetString("bridge.webhooks")
+	var hooks []Webhook
+	for _, name := range splitList(value) {
+		hook, err := loadWebhook(config, consumer, name)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+func loadWebhook(config *apt.Config, consumer, name string) (Webhook, error) {
+	key := func(field string) string { return "webhooks." + name + "." + field }
+	get := func(field string) string {
+		value, _ := config.GetString(key(field))
+		return strings.TrimSpace(value)
+	}
+
+	hook := Webhook{
+		Name:     name,
+		URL:      get("url"),
+		Secret:   get("secret"),
+		Types:    splitList(get("types")),
+		Timeout:  DefaultTimeout,
+		Delivery: pkg.DefaultDeliveryPolicy(consumer + "." + name),
+	}
+	if hook.URL == "" {
+		return hook, fmt.Errorf("missing %s", key("url"))
+	}
+
+	mode, err := cloudevents.ParseMode(get("mode"))
+	if err != nil {
+		return hook, fmt.Errorf("invalid %s: %w", key("mode"), err)
+	}
+	hook.Mode = mode
+
+	if value := get("timeout"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return hook, fmt.Errorf("invalid %s %q", key("timeout"), value)
+		}
+		hook.Timeout = d
+	}
+
+	if value := get("max_attempts"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return hook, fmt.Errorf("invalid %s %q", key("max_attempts"), value)
+		}
+		hook.Delivery.MaxDeliver = n
+	}
+
+	if value := get("min_backoff"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return hook, fmt.Errorf("invalid %s %q", key("min_backoff"), value)
+		}
+		hook.Delivery.MinBackoff = d
+	}
+
+	if value := get("max_backoff"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return hook, fmt.Errorf("invalid %s %q", key("max_backoff"), value)
+		}
+		hook.Delivery.MaxBackoff = d
+	}
+
+	if hook.Delivery.MaxBackoff < hook.Delivery.MinBackoff {
+		return hook, fmt.Errorf("%s %s is shorter than %s %s", key("max_backoff"), hook.Delivery.MaxBackoff, key("min_backoff"), hook.Delivery.MinBackoff)
+	}
+	return hook, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
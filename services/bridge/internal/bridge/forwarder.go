@@ -0,0 +1,164 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/cloudevents"
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+)
+
+// SignatureHeader carries the signature of a request to a webhook with a
+// secret: "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Receivers
+// recompute it with the shared secret and reject stale timestamps.
+const SignatureHeader = "X-Appetite-Signature"
+
+// Stats counts what a forwarder did with the events it was handed.
+type Stats struct {
+	Delivered int64 `json:"delivered"`
+	Skipped   int64 `json:"skipped"`
+	Failed    int64 `json:"failed"`
+	Rejected  int64 `json:"rejected"`
+}
+
+// Forwarder posts the events a webhook subscribes to as CloudEvents.
+//
+// A call that fails on the network, times out or gets a 429 or 5xx returns
+// an error, so the subscriber retries the event with backoff and
+// dead-letters it after the last attempt. Any other response is final: 2xx
+// delivers the event and 4xx rejects it for good. Receivers may get an event
+// more than once and should deduplicate on its id.
+type Forwarder struct {
+	hook   Webhook
+	client *http.Client
+	logger apt.Logger
+	now    func() time.Time
+
+	delivered atomic.Int64
+	skipped   atomic.Int64
+	failed    atomic.Int64
+	rejected  atomic.Int64
+}
+
+// NewForwarder returns a forwarder to hook.
+func NewForwarder(hook Webhook, logger apt.Logger) *Forwarder {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &Forwarder{
+		hook:   hook,
+		client: &http.Client{Timeout: hook.Timeout},
+		logger: logger.With("webhook", hook.Name),
+		now:    time.Now,
+	}
+}
+
+// Webhook returns the webhook events are forwarded to.
+func (f *Forwarder) Webhook() Webhook {
+	return f.hook
+}
+
+// Stats returns the counts so far.
+func (f *Forwarder) Stats() Stats {
+	return Stats{
+		Delivered: f.delivered.Load(),
+		Skipped:   f.skipped.Load(),
+		Failed:    f.failed.Load(),
+		Rejected:  f.rejected.Load(),
+	}
+}
+
+// Handler returns the handler for messages published on topic.
+func (f *Forwarder) Handler(topic string) events.HandlerFunc {
+	return func(ctx context.Context, msg []byte) error {
+		return f.Forward(ctx, topic, msg)
+	}
+}
+
+// Forward posts msg, published on topic, to the webhook if it subscribes to
+// its type.
+func (f *Forwarder) Forward(ctx context.Context, topic string, msg []byte) error {
+	ce, err := cloudevents.FromMessage(topic, msg)
+	if errors.Is(err, event.ErrNotEvent) {
+		f.logger.Info("dropping message that is not an event", "topic", topic, "error", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !f.hook.Accepts(ce.Type) {
+		f.skipped.Add(1)
+		return nil
+	}
+
+	log := f.logger.With("topic", topic, "event_id", ce.ID, "event_type", ce.Type)
+
+	header, body, err := ce.Encode(f.hook.Mode)
+	if err != nil {
+		log.Error("cannot encode event, dropping it", "error", err)
+		f.rejected.Add(1)
+		return nil
+	}
+
+	status, err := f.post(ctx, header, body)
+	switch {
+	case err != nil:
+		f.failed.Add(1)
+		return fmt.Errorf("cannot post %s to webhook %s: %w", ce.ID, f.hook.Name, err)
+	case status >= 200 && status < 300:
+		f.delivered.Add(1)
+		log.Debug("event delivered", "status", status)
+		return nil
+	case status == http.StatusTooManyRequests || status >= 500:
+		f.failed.Add(1)
+		return fmt.Errorf("webhook %s answered %d to %s", f.hook.Name, status, ce.ID)
+	default:
+		f.rejected.Add(1)
+		log.Error("webhook rejected event, dropping it", "status", status)
+		return nil
+	}
+}
+
+func (f *Forwarder) post(ctx context.Context, header http.Header, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if f.hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(f.hook.Secret, f.now(), body))
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	return resp.StatusCode, nil
+}
+
+// Sign returns the SignatureHeader value for body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,228 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/cloudevents"
+	"github.com/appetiteclub/appetite/pkg/event"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+type webhookRecorder struct {
+	mu       sync.Mutex
+	received []received
+}
+
+func (r *webhookRecorder) calls() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received(nil), r.received...)
+}
+
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, *webhookRecorder) {
+	t.Helper()
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		rec.mu.Lock()
+		rec.received = append(rec.received, received{header: r.Header.Clone(), body: body})
+		rec.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func orderItemMessage(t *testing.T, eventType string) []byte {
+	t.Helper()
+	msg, err := event.Marshal(context.Background(), "order-service", event.OrderItemEvent{
+		EventID:     "evt-1",
+		EventType:   eventType,
+		OccurredAt:  time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		OrderID:     "order-1",
+		OrderItemID: "item-1",
+	})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return msg
+}
+
+func TestWebhookAccepts(t *testing.T) {
+	tests := []struct {
+		name      string
+		types     []string
+		eventType string
+		want      bool
+	}{
+		{name: "noTypes", eventType: "order.item.created", want: true},
+		{name: "wildcard", types: []string{"*"}, eventType: "table.status.changed", want: true},
+		{name: "exact", types: []string{"order.item.created"}, eventType: "order.item.created", want: true},
+		{name: "otherExact", types: []string{"order.item.created"}, eventType: "order.item.fired", want: false},
+		{name: "prefix", types: []string{"kitchen.*"}, eventType: "kitchen.ticket.created", want: true},
+		{name: "prefixIsNotType", types: []string{"kitchen.*"}, eventType: "kitchen", want: false},
+		{name: "otherPrefix", types: []string{"kitchen.*", "table.*"}, eventType: "order.item.created", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := Webhook{Types: tt.types}
+			if got := hook.Accepts(tt.eventType); got != tt.want {
+				t.Errorf("Accepts(%q) = %v, want %v", tt.eventType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwarderPostsStructuredSignedEvent(t *testing.T) {
+	srv, rec := newWebhookServer(t, http.StatusAccepted)
+	now := time.Unix(1790000000, 0)
+	f := NewForwarder(Webhook{Name: "test", URL: srv.URL, Secret: "s3cret", Mode: cloudevents.Structured}, nil)
+	f.now = func() time.Time { return now }
+
+	if err := f.Forward(context.Background(), event.OrderItemsTopic, orderItemMessage(t, event.EventOrderItemCreated)); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	calls := rec.calls()
+	if len(calls) != 1 {
+		t.Fatalf("webhook called %d times, want 1", len(calls))
+	}
+
+	call := calls[0]
+	if got := call.header.Get("Content-Type"); got != cloudevents.ContentTypeStructured {
+		t.Errorf("Content-Type = %q, want %q", got, cloudevents.ContentTypeStructured)
+	}
+	if got, want := call.header.Get(SignatureHeader), Sign("s3cret", now, call.body); got != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+	}
+
+	ce, err := cloudevents.Decode(call.header, call.body)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if ce.ID != "evt-1" || ce.Type != event.EventOrderItemCreated || ce.Source != "/appetite/order-service" || ce.SchemaVersion != 1 {
+		t.Errorf("event = %+v", ce)
+	}
+	var payload event.OrderItemEvent
+	if err := json.Unmarshal(ce.Data, &payload); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if payload.OrderItemID != "item-1" {
+		t.Errorf("data order_item_id = %q, want item-1", payload.OrderItemID)
+	}
+	if got := f.Stats().Delivered; got != 1 {
+		t.Errorf("Delivered = %d, want 1", got)
+	}
+}
+
+func TestForwarderPostsBinaryEvent(t *testing.T) {
+	srv, rec := newWebhookServer(t, http.StatusOK)
+	f := NewForwarder(Webhook{Name: "test", URL: srv.URL, Mode: cloudevents.Binary}, nil)
+
+	if err := f.Forward(context.Background(), event.OrderItemsTopic, orderItemMessage(t, event.EventOrderItemFired)); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	calls := rec.calls()
+	if len(calls) != 1 {
+		t.Fatalf("webhook called %d times, want 1", len(calls))
+	}
+
+	call := calls[0]
+	if got := call.header.Get("Ce-Type"); got != event.EventOrderItemFired {
+		t.Errorf("ce-type = %q, want %q", got, event.EventOrderItemFired)
+	}
+	if got := call.header.Get("Ce-Specversion"); got != cloudevents.SpecVersion {
+		t.Errorf("ce-specversion = %q, want %q", got, cloudevents.SpecVersion)
+	}
+	if got := call.header.Get(SignatureHeader); got != "" {
+		t.Errorf("%s = %q on a webhook without secret", SignatureHeader, got)
+	}
+
+	var payload event.OrderItemEvent
+	if err := json.Unmarshal(call.body, &payload); err != nil {
+		t.Fatalf("body is not the payload: %v", err)
+	}
+	if payload.EventID != "evt-1" {
+		t.Errorf("body event_id = %q, want evt-1", payload.EventID)
+	}
+}
+
+func TestForwarderSkipsUnsubscribedTypes(t *testing.T) {
+	srv, rec := newWebhookServer(t, http.StatusOK)
+	f := NewForwarder(Webhook{Name: "test", URL: srv.URL, Types: []string{"kitchen.*"}}, nil)
+
+	if err := f.Forward(context.Background(), event.OrderItemsTopic, orderItemMessage(t, event.EventOrderItemCreated)); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if calls := rec.calls(); len(calls) != 0 {
+		t.Errorf("webhook called %d times, want 0", len(calls))
+	}
+	if got := f.Stats().Skipped; got != 1 {
+		t.Errorf("Skipped = %d, want 1", got)
+	}
+}
+
+func TestForwarderRetriesOnlyTransientFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantRetry bool
+	}{
+		{name: "serverError", status: http.StatusBadGateway, wantRetry: true},
+		{name: "tooManyRequests", status: http.StatusTooManyRequests, wantRetry: true},
+		{name: "badRequest", status: http.StatusBadRequest, wantRetry: false},
+		{name: "gone", status: http.StatusGone, wantRetry: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newWebhookServer(t, tt.status)
+			f := NewForwarder(Webhook{Name: "test", URL: srv.URL}, nil)
+
+			err := f.Forward(context.Background(), event.OrderItemsTopic, orderItemMessage(t, event.EventOrderItemCreated))
+			if (err != nil) != tt.wantRetry {
+				t.Fatalf("Forward() error = %v, want retry %v", err, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestForwarderRetriesUnreachableWebhook(t *testing.T) {
+	srv, _ := newWebhookServer(t, http.StatusOK)
+	url := srv.URL
+	srv.Close()
+
+	f := NewForwarder(Webhook{Name: "test", URL: url, Timeout: time.Second}, nil)
+	if err := f.Forward(context.Background(), event.OrderItemsTopic, orderItemMessage(t, event.EventOrderItemCreated)); err == nil {
+		t.Fatal("Forward() error = nil, want an error to retry on")
+	}
+	if got := f.Stats().Failed; got != 1 {
+		t.Errorf("Failed = %d, want 1", got)
+	}
+}
+
+func TestForwarderDropsMessagesThatAreNotEvents(t *testing.T) {
+	srv, rec := newWebhookServer(t, http.StatusOK)
+	f := NewForwarder(Webhook{Name: "test", URL: srv.URL}, nil)
+
+	if err := f.Forward(context.Background(), event.OrderItemsTopic, []byte("not json")); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if calls := rec.calls(); len(calls) != 0 {
+		t.Errorf("webhook called %d times, want 0", len(calls))
+	}
+}
@@ -0,0 +1,66 @@
+package bridge
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler exposes the webhooks of a bridge and what was forwarded to them.
+type Handler struct {
+	bridge *Bridge
+	logger apt.Logger
+}
+
+// NewHandler creates a new Handler for the bridge.
+func NewHandler(bridge *Bridge, logger apt.Logger) *Handler {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &Handler{bridge: bridge, logger: logger}
+}
+
+// RegisterRoutes registers the routes of the bridge service.
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/webhooks", h.ListWebhooks)
+}
+
+type webhookStatus struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Types       []string `json:"types"`
+	Mode        string   `json:"mode"`
+	Signed      bool     `json:"signed"`
+	MaxAttempts int      `json:"max_attempts"`
+	Stats       Stats    `json:"stats"`
+}
+
+// ListWebhooks handles GET /webhooks
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]webhookStatus, 0, len(h.bridge.Forwarders()))
+	for _, f := range h.bridge.Forwarders() {
+		hook := f.Webhook()
+		statuses = append(statuses, webhookStatus{
+			Name:        hook.Name,
+			URL:         redactURL(hook.URL),
+			Types:       hook.Types,
+			Mode:        string(hook.Mode),
+			Signed:      hook.Secret != "",
+			MaxAttempts: hook.Delivery.MaxDeliver,
+			Stats:       f.Stats(),
+		})
+	}
+	apt.RespondCollection(w, statuses, "webhook")
+}
+
+// redactURL keeps the scheme and host of a webhook URL. Paths and queries
+// often carry tokens, so they are not shown.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
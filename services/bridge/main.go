@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/appetiteclub/appetite/services/bridge/internal/bridge"
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/middleware"
+)
+
+const (
+	appNamespace = "BRIDGE"
+	appName      = "bridge"
+	appVersion   = "0.1.0"
+)
+
+func main() {
+	config, err := apt.LoadConfig(appNamespace, os.Args[1:])
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup: %v", appName, appVersion, err)
+	}
+
+	logLevel, _ := config.GetString("log.level")
+	logger := apt.NewLogger(logLevel)
+
+	ctx, stop := signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGQUIT,
+	)
+	defer stop()
+
+	hooks, err := bridge.LoadWebhooks(config, appName)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup webhooks: %v", appName, appVersion, err)
+	}
+
+	// Events are kept in a stream while a webhook or the bridge is down
+	retention := bridge.DefaultRetention
+	if value, _ := config.GetString("bridge.retention"); value != "" {
+		retention, err = time.ParseDuration(value)
+		if err != nil || retention <= 0 {
+			log.Fatalf("%s(%s) invalid bridge.retention %q", appName, appVersion, value)
+		}
+	}
+
+	natsURL := config.GetStringOrDef("nats.url", "nats://localhost:4222")
+	eventBridge := bridge.New(natsURL, retention, hooks, logger)
+	handler := bridge.NewHandler(eventBridge, logger)
+
+	stack := middleware.DefaultStack(middleware.StackOptions{
+		Logger:      logger,
+		DisableCORS: true, // Internal API service
+	})
+	stack = append(stack, middleware.InternalOnly())
+
+	options := []apt.Option{
+		apt.WithConfig(config),
+		apt.WithLogger(logger),
+		apt.WithHTTPMiddleware(stack...),
+		apt.WithHTTPServerModules("web.port", handler),
+		apt.WithLifecycle(eventBridge),
+		apt.WithHealthChecks(appName),
+	}
+
+	ms := apt.NewMicro(options...)
+	logger.Infof("Starting %s(%s)", appName, appVersion)
+
+	if err := ms.Run(ctx); err != nil {
+		log.Fatalf("%s(%s) stopped: %v", appName, appVersion, err)
+	}
+
+	logger.Infof("%s(%s) stopped", appName, appVersion)
+}
@@ -13,21 +13,22 @@ import (
 // An option represents a single entry within a set (e.g., "Residential", "House", "Bungalow").
 // Options can have hierarchical relationships via ParentID.
 type Option struct {
-	ID          uuid.UUID  `json:"id" bson:"_id"`
-	Set         uuid.UUID  `json:"set_id" bson:"set_id"`                               // Reference to the Set this option belongs to
-	ParentID    *uuid.UUID `json:"parent_id,omitempty" bson:"parent_id,omitempty"`     // Optional parent option for hierarchy
-	Locale      string     `json:"locale" bson:"locale"`                               // Language/locale code
-	ShortCode   string     `json:"short_code" bson:"short_code"`                       // Short code for the option
-	Key         string     `json:"key" bson:"key"`                                     // Unique key within the set per locale
-	Label       string     `json:"label" bson:"label"`                                 // Human-readable label
-	Description string     `json:"description,omitempty" bson:"description,omitempty"` // Optional description
-	Value       string     `json:"value" bson:"value"`                                 // The actual value
-	Order       int        `json:"order" bson:"order"`                                 // Display order
-	Active      bool       `json:"active" bson:"active"`
-	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
-	CreatedBy   string     `json:"created_by" bson:"created_by"`
-	UpdatedAt   time.Time  `json:"updated_at" bson:"updated_at"`
-	UpdatedBy   string     `json:"updated_by" bson:"updated_by"`
+	ID          uuid.UUID         `json:"id" bson:"_id"`
+	Set         uuid.UUID         `json:"set_id" bson:"set_id"`                               // Reference to the Set this option belongs to
+	ParentID    *uuid.UUID        `json:"parent_id,omitempty" bson:"parent_id,omitempty"`     // Optional parent option for hierarchy
+	Locale      string            `json:"locale" bson:"locale"`                               // Language/locale code
+	ShortCode   string            `json:"short_code" bson:"short_code"`                       // Short code for the option
+	Key         string            `json:"key" bson:"key"`                                     // Unique key within the set per locale
+	Label       string            `json:"label" bson:"label"`                                 // Human-readable label
+	Description string            `json:"description,omitempty" bson:"description,omitempty"` // Optional description
+	Value       string            `json:"value" bson:"value"`                                 // The actual value
+	Order       int               `json:"order" bson:"order"`                                 // Display order
+	Attributes  map[string]string `json:"attributes,omitempty" bson:"attributes,omitempty"`   // Extra settings, e.g. a station's colour or printer
+	Active      bool              `json:"active" bson:"active"`
+	CreatedAt   time.Time         `json:"created_at" bson:"created_at"`
+	CreatedBy   string            `json:"created_by" bson:"created_by"`
+	UpdatedAt   time.Time         `json:"updated_at" bson:"updated_at"`
+	UpdatedBy   string            `json:"updated_by" bson:"updated_by"`
 }
 
 // GetID returns the ID of the Option (implements Identifiable interface).
@@ -100,6 +101,10 @@ func (o *Option) MarshalBSON() ([]byte, error) {
 		doc["parent_id"] = o.ParentID.String()
 	}
 
+	if len(o.Attributes) > 0 {
+		doc["attributes"] = o.Attributes
+	}
+
 	return bson.Marshal(doc)
 }
 
@@ -162,6 +167,22 @@ func (o *Option) UnmarshalBSON(data []byte) error {
 	} else if v, ok := doc["order"].(int); ok {
 		o.Order = v
 	}
+	switch v := doc["attributes"].(type) {
+	case bson.M:
+		o.Attributes = make(map[string]string, len(v))
+		for key, value := range v {
+			if str, ok := value.(string); ok {
+				o.Attributes[key] = str
+			}
+		}
+	case bson.D:
+		o.Attributes = make(map[string]string, len(v))
+		for _, elem := range v {
+			if str, ok := elem.Value.(string); ok {
+				o.Attributes[elem.Key] = str
+			}
+		}
+	}
 	if v, ok := doc["active"].(bool); ok {
 		o.Active = v
 	}
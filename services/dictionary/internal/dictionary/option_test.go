@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestOptionEnsureID(t *testing.T) {
@@ -89,3 +90,26 @@ func TestOptionResourceType(t *testing.T) {
 		t.Errorf("ResourceType() = %s, want %s", option.ResourceType(), "dictionary/option")
 	}
 }
+
+func TestOptionAttributesBSON(t *testing.T) {
+	option := &Option{
+		ID:         uuid.New(),
+		Set:        uuid.New(),
+		Key:        "grill",
+		Attributes: map[string]string{"colour": "#c0392b", "capacity": "6"},
+	}
+
+	data, err := bson.Marshal(option)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded Option
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if decoded.Attributes["colour"] != "#c0392b" || decoded.Attributes["capacity"] != "6" {
+		t.Errorf("Attributes = %v, want colour and capacity back", decoded.Attributes)
+	}
+}
@@ -23,9 +23,77 @@ func Seeds(db *mongo.Database) []seed.Seed {
 				return seedRestaurantDictionary(ctx, db)
 			},
 		},
+		{
+			ID:          "2026-10-16_kitchen_stations",
+			Description: "Load the default kitchen stations",
+			Run: func(ctx context.Context) error {
+				return seedStations(ctx, db)
+			},
+		},
 	}
 }
 
+// seedStations creates the station set with the stations the line used to
+// have built in. Venues edit, add or deactivate stations from there.
+func seedStations(ctx context.Context, db *mongo.Database) error {
+	setsCollection := db.Collection("sets")
+	optionsCollection := db.Collection("options")
+
+	_, err := setsCollection.UpdateOne(ctx, bson.M{"name": "station", "locale": "en"}, bson.M{"$setOnInsert": bson.M{
+		"_id":         uuid.New().String(),
+		"name":        "station",
+		"locale":      "en",
+		"label":       "Station",
+		"description": "Stations on the line that prepare tickets",
+		"active":      true,
+		"created_at":  time.Now(),
+		"updated_at":  time.Now(),
+		"created_by":  "system",
+		"updated_by":  "system",
+	}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("seed station set: %w", err)
+	}
+
+	var set Set
+	if err := setsCollection.FindOne(ctx, bson.M{"name": "station", "locale": "en"}).Decode(&set); err != nil {
+		return fmt.Errorf("load station set: %w", err)
+	}
+
+	stations := []struct {
+		value, label, colour string
+	}{
+		{"kitchen", "Kitchen", "#e67e22"},
+		{"dessert", "Dessert", "#d35db3"},
+		{"bar", "Bar", "#2e86c1"},
+		{"coffee", "Coffee", "#8d6e63"},
+		{"other", "Other", "#7f8c8d"},
+	}
+
+	for i, st := range stations {
+		_, err := optionsCollection.UpdateOne(ctx, bson.M{"set_id": set.ID.String(), "locale": "en", "value": st.value}, bson.M{"$setOnInsert": bson.M{
+			"_id":        uuid.New().String(),
+			"set_id":     set.ID.String(),
+			"locale":     "en",
+			"key":        st.value,
+			"label":      st.label,
+			"value":      st.value,
+			"order":      i + 1,
+			"attributes": bson.M{"colour": st.colour, "printer": st.value},
+			"active":     true,
+			"created_at": time.Now(),
+			"updated_at": time.Now(),
+			"created_by": "system",
+			"updated_by": "system",
+		}}, options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("seed station %s: %w", st.value, err)
+		}
+	}
+
+	return nil
+}
+
 // seedRestaurantDictionary creates all sets and options for restaurant operations.
 func seedRestaurantDictionary(ctx context.Context, db *mongo.Database) error {
 	setsCollection := db.Collection("sets")
@@ -47,7 +47,7 @@ func (a *App) Initialize(ctx context.Context) error {
 
 	// Initialize NATS Stream or Publisher
 	var kitchenStream *pkg.NATSStream
-	var orderSubscriber *pkg.NATSSubscriber
+	var orderSubscriber *pkg.NATSConsumer
 	var eventPublisher aqmevents.Publisher
 
 	streamEnabled, _ := a.config.GetString("nats.stream.enabled")
@@ -69,8 +69,8 @@ func (a *App) Initialize(ctx context.Context) error {
 		a.logger.Info("NATS stream initialized for persistent events")
 		eventPublisher = kitchenStream
 
-		// Use separate subscriber for orders.items
-		orderSubscriber, err = pkg.NewNATSSubscriber(natsURL)
+		// Use a durable consumer for orders.items
+		orderSubscriber, err = pkg.NewNATSConsumer(ctx, pkg.NATSConsumerConfig{URL: natsURL, Consumer: "kitchen", Logger: a.logger})
 		if err != nil {
 			return err
 		}
@@ -82,7 +82,7 @@ func (a *App) Initialize(ctx context.Context) error {
 		}
 		eventPublisher = publisher
 
-		orderSubscriber, err = pkg.NewNATSSubscriber(natsURL)
+		orderSubscriber, err = pkg.NewNATSConsumer(ctx, pkg.NATSConsumerConfig{URL: natsURL, Consumer: "kitchen", Logger: a.logger})
 		if err != nil {
 			return err
 		}
@@ -2,12 +2,15 @@ package events
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
+	"github.com/appetiteclub/appetite/pkg/enums/station"
 	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/idempotency"
+	"github.com/appetiteclub/appetite/pkg/outbox"
 	"github.com/appetiteclub/appetite/services/kitchen/internal/kitchen"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
@@ -20,6 +23,8 @@ type OrderItemSubscriber struct {
 	repo       kitchen.TicketRepository
 	cache      *kitchen.TicketStateCache
 	publisher  events.Publisher
+	stations   *station.Registry
+	processed  idempotency.Store
 	logger     apt.Logger
 }
 
@@ -39,10 +44,23 @@ func NewOrderItemSubscriber(
 	}
 }
 
+// SetStations sets the station registry new tickets are routed with. Without
+// one tickets go to the station the order item names.
+func (s *OrderItemSubscriber) SetStations(stations *station.Registry) {
+	s.stations = stations
+}
+
+// SetIdempotencyStore sets the store of processed event IDs, so a redelivered
+// or republished event does not create a second ticket.
+func (s *OrderItemSubscriber) SetIdempotencyStore(store idempotency.Store) {
+	s.processed = store
+}
+
 func (s *OrderItemSubscriber) Start(ctx context.Context) error {
 	s.logger.Info("Starting OrderItemSubscriber for topic: orders.items")
 
-	if err := s.subscriber.Subscribe(ctx, "orders.items", s.handleEvent); err != nil {
+	handler := idempotency.Handler(s.processed, s.logger, s.handleEvent)
+	if err := s.subscriber.Subscribe(ctx, "orders.items", handler); err != nil {
 		return fmt.Errorf("failed to subscribe to orders.items: %w", err)
 	}
 
@@ -51,8 +69,17 @@ func (s *OrderItemSubscriber) Start(ctx context.Context) error {
 }
 
 func (s *OrderItemSubscriber) handleEvent(ctx context.Context, msg []byte) error {
+	env, err := event.Open(msg)
+	if errors.Is(err, event.ErrNotEvent) {
+		s.logger.Errorf("Failed to unmarshal event: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	var evt event.OrderItemEvent
-	if err := json.Unmarshal(msg, &evt); err != nil {
+	if err := env.Unmarshal(&evt); err != nil {
 		s.logger.Errorf("Failed to unmarshal event: %v", err)
 		return nil
 	}
@@ -61,17 +88,22 @@ func (s *OrderItemSubscriber) handleEvent(ctx context.Context, msg []byte) error
 		return nil
 	}
 
-	switch evt.EventType {
+	// Tickets and the events they raise are traced back to this event
+	ctx = event.WithCause(ctx, env)
+
+	switch env.Type {
 	case event.EventOrderItemCreated:
 		return s.handleCreated(ctx, &evt)
 	case event.EventOrderItemUpdated:
 		return s.handleUpdated(ctx, &evt)
 	case event.EventOrderItemCancelled:
 		return s.handleCancelled(ctx, &evt)
-	case "order.item.status_changed":
+	case event.EventOrderItemFired:
+		return s.handleFired(ctx, &evt)
+	case event.EventOrderItemStatusChanged:
 		return s.handleStatusChanged(ctx, &evt)
 	default:
-		s.logger.Infof("Unknown event type: %s", evt.EventType)
+		s.logger.Infof("Unknown event type: %s", env.Type)
 	}
 
 	return nil
@@ -106,21 +138,53 @@ func (s *OrderItemSubscriber) handleCreated(ctx context.Context, evt *event.Orde
 		return nil
 	}
 
+	// Held courses wait in standby until the order fires them
+	status := kitchenstatus.Statuses.Created.Code()
+	if evt.Held {
+		status = kitchenstatus.Statuses.Standby.Code()
+	}
+
+	// Items for a station the venue does not have, or one that was closed
+	// when the item was ordered, go to the fallback station
+	stationCode, stationName := evt.ProductionStation, evt.StationName
+	if s.stations != nil {
+		orderedAt := evt.OccurredAt
+		if orderedAt.IsZero() {
+			orderedAt = time.Now()
+		}
+		stationCode = s.stations.Route(evt.ProductionStation, orderedAt)
+		if st, ok := s.stations.Get(stationCode); ok {
+			stationName = st.Label()
+		}
+	}
+
 	ticket := &kitchen.Ticket{
 		ID:           uuid.New(),
 		OrderID:      orderID,
 		OrderItemID:  orderItemID,
 		MenuItemID:   menuItemID,
-		Station:      evt.ProductionStation,
+		Station:      stationCode,
 		Quantity:     evt.Quantity,
-		Status:       kitchenstatus.Statuses.Created.Code(),
+		Status:       status,
 		Notes:        evt.Notes,
 		MenuItemName: evt.MenuItemName,
-		StationName:  evt.StationName,
+		StationName:  stationName,
 		TableNumber:  evt.TableNumber,
-	}
-
-	if err := s.repo.Create(ctx, ticket); err != nil {
+		Modifiers:    event.ModifierLabels(evt.Modifiers),
+		PortionName:  evt.PortionName,
+		PrepTime:     evt.PrepTimeMinutes,
+		Course:       evt.Course,
+	}
+
+	// The ticket and its created event are saved together, so the floor
+	// hears about every ticket the kitchen takes
+	err = outbox.WithTransaction(ctx, s.publisher, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, ticket); err != nil {
+			return err
+		}
+		return s.publishCreated(ctx, ticket, evt)
+	})
+	if err != nil {
 		s.logger.Errorf("Failed to create ticket: %v", err)
 		return err
 	}
@@ -131,9 +195,13 @@ func (s *OrderItemSubscriber) handleCreated(ctx context.Context, evt *event.Orde
 	}
 
 	s.logger.Infof("Created ticket %s for order item %s", ticket.ID, evt.OrderItemID)
+	return nil
+}
 
+func (s *OrderItemSubscriber) publishCreated(ctx context.Context, ticket *kitchen.Ticket, evt *event.OrderItemEvent) error {
 	eventPayload := event.KitchenTicketCreatedEvent{
 		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
+			EventID:      event.NewID(),
 			EventType:    event.EventKitchenTicketCreated,
 			OccurredAt:   time.Now().UTC(),
 			TicketID:     ticket.ID.String(),
@@ -142,19 +210,26 @@ func (s *OrderItemSubscriber) handleCreated(ctx context.Context, evt *event.Orde
 			MenuItemID:   ticket.MenuItemID.String(),
 			Station:      ticket.Station,
 			MenuItemName: evt.MenuItemName,
-			StationName:  evt.StationName,
+			StationName:  ticket.StationName,
 			TableNumber:  evt.TableNumber,
+			Modifiers:    ticket.Modifiers,
+			PortionName:  ticket.PortionName,
+			PrepTime:     ticket.PrepTime,
+			Course:       ticket.Course,
+			ModelVersion: ticket.ModelVersion,
 		},
 		Status:   ticket.Status,
 		Quantity: ticket.Quantity,
 		Notes:    ticket.Notes,
 	}
 
-	eventBytes, _ := json.Marshal(eventPayload)
+	eventBytes, err := event.Marshal(ctx, kitchen.EventSource, eventPayload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal ticket.created event: %w", err)
+	}
 	if err := s.publisher.Publish(ctx, event.KitchenTicketsTopic, eventBytes); err != nil {
-		s.logger.Errorf("Failed to publish ticket.created event: %v", err)
+		return fmt.Errorf("cannot publish ticket.created event: %w", err)
 	}
-
 	return nil
 }
 
@@ -164,18 +239,23 @@ func (s *OrderItemSubscriber) handleUpdated(ctx context.Context, evt *event.Orde
 		return nil
 	}
 
-	ticket, err := s.repo.FindByOrderItemID(ctx, orderItemID)
-	if err != nil || ticket == nil {
-		return err
-	}
-
-	ticket.Quantity = evt.Quantity
-	ticket.Notes = evt.Notes
-
-	if err := s.repo.Update(ctx, ticket); err != nil {
+	ticket, err := s.updateTicket(ctx, orderItemID, func(ticket *kitchen.Ticket) bool {
+		ticket.Quantity = evt.Quantity
+		ticket.Notes = evt.Notes
+		ticket.Modifiers = event.ModifierLabels(evt.Modifiers)
+		if evt.PortionName != "" {
+			ticket.PortionName = evt.PortionName
+			ticket.PrepTime = evt.PrepTimeMinutes
+		}
+		return true
+	}, nil)
+	if err != nil {
 		s.logger.Errorf("Failed to update ticket: %v", err)
 		return err
 	}
+	if ticket == nil {
+		return nil
+	}
 
 	// Update cache
 	if s.cache != nil {
@@ -192,18 +272,20 @@ func (s *OrderItemSubscriber) handleCancelled(ctx context.Context, evt *event.Or
 		return nil
 	}
 
-	ticket, err := s.repo.FindByOrderItemID(ctx, orderItemID)
-	if err != nil || ticket == nil {
-		return err
-	}
-
-	previousStatus := ticket.Status
-	ticket.Status = kitchenstatus.Statuses.Cancelled.Code()
-
-	if err := s.repo.Update(ctx, ticket); err != nil {
+	ticket, err := s.updateTicket(ctx, orderItemID, func(ticket *kitchen.Ticket) bool {
+		if err := ticket.Transition(kitchenstatus.Statuses.Cancelled.Code(), time.Now().UTC()); err != nil {
+			s.logger.Infof("Cannot cancel ticket %s: %v", ticket.ID, err)
+			return false
+		}
+		return true
+	}, s.publishStatusChange)
+	if err != nil {
 		s.logger.Errorf("Failed to cancel ticket: %v", err)
 		return err
 	}
+	if ticket == nil {
+		return nil
+	}
 
 	// Update cache (or remove if filtering out cancelled)
 	if s.cache != nil {
@@ -211,27 +293,42 @@ func (s *OrderItemSubscriber) handleCancelled(ctx context.Context, evt *event.Or
 	}
 
 	s.logger.Infof("Cancelled ticket %s for order item %s", ticket.ID, evt.OrderItemID)
+	return nil
+}
 
-	eventPayload := event.KitchenTicketStatusChangedEvent{
-		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
-			EventType:   event.EventKitchenTicketStatusChange,
-			OccurredAt:  time.Now().UTC(),
-			TicketID:    ticket.ID.String(),
-			OrderID:     ticket.OrderID.String(),
-			OrderItemID: ticket.OrderItemID.String(),
-			MenuItemID:  ticket.MenuItemID.String(),
-			Station:     ticket.Station,
-		},
-		NewStatus:      ticket.Status,
-		PreviousStatus: previousStatus,
-		Notes:          ticket.Notes,
+// handleFired releases a held course ticket from standby to the line.
+func (s *OrderItemSubscriber) handleFired(ctx context.Context, evt *event.OrderItemEvent) error {
+	orderItemID, err := uuid.Parse(evt.OrderItemID)
+	if err != nil {
+		return nil
 	}
 
-	eventBytes, _ := json.Marshal(eventPayload)
-	if err := s.publisher.Publish(ctx, event.KitchenTicketsTopic, eventBytes); err != nil {
-		s.logger.Errorf("Failed to publish ticket.status_changed event: %v", err)
+	ticket, err := s.updateTicket(ctx, orderItemID, func(ticket *kitchen.Ticket) bool {
+		if ticket.Status != kitchenstatus.Statuses.Standby.Code() {
+			// The line already picked it up
+			return false
+		}
+		now := time.Now().UTC()
+		if err := ticket.Transition(kitchenstatus.Statuses.Created.Code(), now); err != nil {
+			s.logger.Infof("Cannot fire ticket %s: %v", ticket.ID, err)
+			return false
+		}
+		ticket.FiredAt = &now
+		return true
+	}, s.publishStatusChange)
+	if err != nil {
+		s.logger.Errorf("Failed to fire ticket: %v", err)
+		return err
+	}
+	if ticket == nil {
+		return nil
+	}
+
+	if s.cache != nil {
+		s.cache.Set(ticket)
 	}
 
+	s.logger.Infof("Fired course %d ticket %s for order item %s", ticket.Course, ticket.ID, evt.OrderItemID)
 	return nil
 }
 
@@ -241,11 +338,6 @@ func (s *OrderItemSubscriber) handleStatusChanged(ctx context.Context, evt *even
 		return nil
 	}
 
-	ticket, err := s.repo.FindByOrderItemID(ctx, orderItemID)
-	if err != nil || ticket == nil {
-		return err
-	}
-
 	// Map order item status to kitchen ticket status
 	var newStatus string
 	switch evt.Status {
@@ -258,13 +350,22 @@ func (s *OrderItemSubscriber) handleStatusChanged(ctx context.Context, evt *even
 		return nil
 	}
 
-	previousStatus := ticket.Status
-	ticket.Status = newStatus
-
-	if err := s.repo.Update(ctx, ticket); err != nil {
+	var previousStatus string
+	ticket, err := s.updateTicket(ctx, orderItemID, func(ticket *kitchen.Ticket) bool {
+		previousStatus = ticket.Status
+		if err := ticket.Transition(newStatus, time.Now().UTC()); err != nil {
+			s.logger.Infof("Cannot move ticket %s to %s: %v", ticket.ID, newStatus, err)
+			return false
+		}
+		return true
+	}, s.publishStatusChange)
+	if err != nil {
 		s.logger.Errorf("Failed to update ticket status: %v", err)
 		return err
 	}
+	if ticket == nil {
+		return nil
+	}
 
 	// Update cache
 	if s.cache != nil {
@@ -272,27 +373,86 @@ func (s *OrderItemSubscriber) handleStatusChanged(ctx context.Context, evt *even
 	}
 
 	s.logger.Infof("Updated ticket %s status from %s to %s", ticket.ID, previousStatus, newStatus)
+	return nil
+}
+
+// maxUpdateAttempts bounds how many times an order change is reapplied when
+// the kitchen moved the ticket between our read and our write.
+const maxUpdateAttempts = 3
+
+// updateTicket loads the ticket of the order item, applies change and saves
+// it. When someone else saved the ticket first, it reloads it and applies
+// change again on top. change returns false to leave the ticket alone, in
+// which case no ticket is returned.
+//
+// When publish is set, the event it raises is saved with the ticket. Each
+// attempt runs in its own transaction, so a retry reads the ticket the
+// kitchen saved.
+func (s *OrderItemSubscriber) updateTicket(
+	ctx context.Context,
+	orderItemID uuid.UUID,
+	change func(ticket *kitchen.Ticket) bool,
+	publish func(ctx context.Context, ticket *kitchen.Ticket, previousStatus string) error,
+) (*kitchen.Ticket, error) {
+	for attempt := 1; ; attempt++ {
+		var updated *kitchen.Ticket
+		err := outbox.WithTransaction(ctx, s.publisher, func(ctx context.Context) error {
+			ticket, err := s.repo.FindByOrderItemID(ctx, orderItemID)
+			if err != nil || ticket == nil {
+				return err
+			}
+			previousStatus := ticket.Status
+			if !change(ticket) {
+				return nil
+			}
+			if err := s.repo.Update(ctx, ticket); err != nil {
+				return err
+			}
+			if publish != nil {
+				if err := publish(ctx, ticket, previousStatus); err != nil {
+					return err
+				}
+			}
+			updated = ticket
+			return nil
+		})
+		if err == nil {
+			return updated, nil
+		}
+		if !errors.Is(err, kitchen.ErrVersionConflict) || attempt == maxUpdateAttempts {
+			return nil, err
+		}
+		s.logger.Infof("Ticket for order item %s changed while applying order update, retrying: %v", orderItemID, err)
+	}
+}
 
-	// Publish kitchen ticket status changed event
+// publishStatusChange publishes the status_changed event of a ticket the
+// order moved.
+func (s *OrderItemSubscriber) publishStatusChange(ctx context.Context, ticket *kitchen.Ticket, previousStatus string) error {
 	eventPayload := event.KitchenTicketStatusChangedEvent{
 		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
-			EventType:   event.EventKitchenTicketStatusChange,
-			OccurredAt:  time.Now().UTC(),
-			TicketID:    ticket.ID.String(),
-			OrderID:     ticket.OrderID.String(),
-			OrderItemID: ticket.OrderItemID.String(),
-			MenuItemID:  ticket.MenuItemID.String(),
-			Station:     ticket.Station,
+			EventID:      event.NewID(),
+			EventType:    event.EventKitchenTicketStatusChange,
+			OccurredAt:   time.Now().UTC(),
+			TicketID:     ticket.ID.String(),
+			OrderID:      ticket.OrderID.String(),
+			OrderItemID:  ticket.OrderItemID.String(),
+			MenuItemID:   ticket.MenuItemID.String(),
+			Station:      ticket.Station,
+			Course:       ticket.Course,
+			ModelVersion: ticket.ModelVersion,
 		},
 		NewStatus:      ticket.Status,
 		PreviousStatus: previousStatus,
 		Notes:          ticket.Notes,
 	}
 
-	eventBytes, _ := json.Marshal(eventPayload)
+	eventBytes, err := event.Marshal(ctx, kitchen.EventSource, eventPayload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal ticket.status_changed event: %w", err)
+	}
 	if err := s.publisher.Publish(ctx, event.KitchenTicketsTopic, eventBytes); err != nil {
-		s.logger.Errorf("Failed to publish ticket.status_changed event: %v", err)
+		return fmt.Errorf("cannot publish ticket.status_changed event: %w", err)
 	}
-
 	return nil
 }
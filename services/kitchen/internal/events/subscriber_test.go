@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
+	"github.com/appetiteclub/appetite/pkg/enums/station"
 	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/idempotency"
 	"github.com/appetiteclub/appetite/services/kitchen/internal/kitchen"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
@@ -58,9 +61,14 @@ func (m *MockTicketRepo) Update(ctx context.Context, t *kitchen.Ticket) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, t)
 	}
-	if _, exists := m.tickets[t.ID]; !exists {
-		return errors.New("ticket not found")
+	stored, exists := m.tickets[t.ID]
+	if !exists {
+		return kitchen.ErrTicketNotFound
+	}
+	if stored.ModelVersion != t.ModelVersion {
+		return kitchen.ErrVersionConflict
 	}
+	t.ModelVersion++
 	m.tickets[t.ID] = t
 	return nil
 }
@@ -123,6 +131,41 @@ func (m *MockPublisher) Publish(ctx context.Context, topic string, data []byte)
 	return nil
 }
 
+// MockTransactionalPublisher implements an events.Publisher that saves a
+// change and its events together, like the outbox
+type MockTransactionalPublisher struct {
+	*MockPublisher
+	Transactions       int
+	OutsideTransaction int
+}
+
+type mockTransactionKey struct{}
+
+func NewMockTransactionalPublisher() *MockTransactionalPublisher {
+	return &MockTransactionalPublisher{MockPublisher: NewMockPublisher()}
+}
+
+func (m *MockTransactionalPublisher) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Transactions++
+	return fn(context.WithValue(ctx, mockTransactionKey{}, true))
+}
+
+func (m *MockTransactionalPublisher) Publish(ctx context.Context, topic string, data []byte) error {
+	if ctx.Value(mockTransactionKey{}) == nil {
+		m.OutsideTransaction++
+	}
+	return m.MockPublisher.Publish(ctx, topic, data)
+}
+
+// unmarshalEvent decodes the payload of a published event envelope into v.
+func unmarshalEvent(msg []byte, v any) error {
+	env, err := event.Open(msg)
+	if err != nil {
+		return err
+	}
+	return env.Unmarshal(v)
+}
+
 func TestNewOrderItemSubscriber(t *testing.T) {
 	subscriber := &MockSubscriber{}
 	repo := NewMockTicketRepo()
@@ -476,6 +519,111 @@ func TestOrderItemSubscriberHandleCancelled(t *testing.T) {
 	}
 }
 
+func TestOrderItemSubscriberSavesEventsWithTicket(t *testing.T) {
+	orderItemID := uuid.New()
+
+	repo := NewMockTicketRepo()
+	cache := kitchen.NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	publisher := NewMockTransactionalPublisher()
+	s := NewOrderItemSubscriber(&MockSubscriber{}, repo, cache, publisher, apt.NewNoopLogger())
+
+	for _, evt := range []event.OrderItemEvent{
+		{
+			EventType:          event.EventOrderItemCreated,
+			OrderItemID:        orderItemID.String(),
+			OrderID:            uuid.New().String(),
+			MenuItemID:         uuid.New().String(),
+			RequiresProduction: true,
+		},
+		{
+			EventType:          event.EventOrderItemCancelled,
+			OrderItemID:        orderItemID.String(),
+			RequiresProduction: true,
+		},
+	} {
+		eventBytes, _ := json.Marshal(evt)
+		if err := s.handleEvent(context.Background(), eventBytes); err != nil {
+			t.Fatalf("handleEvent(%s) error = %v", evt.EventType, err)
+		}
+	}
+
+	if len(publisher.PublishedEvents) != 2 {
+		t.Fatalf("published %d events, want 2", len(publisher.PublishedEvents))
+	}
+	if publisher.OutsideTransaction != 0 {
+		t.Errorf("%d events published outside the ticket transaction", publisher.OutsideTransaction)
+	}
+}
+
+func TestOrderItemSubscriberRetriesOnVersionConflict(t *testing.T) {
+	orderItemID := uuid.New()
+
+	tests := []struct {
+		name        string
+		conflicts   int
+		wantErr     bool
+		wantStatus  string
+		wantPublish bool
+	}{
+		{
+			name:        "appliesOnNewerTicket",
+			conflicts:   1,
+			wantStatus:  "cancelled",
+			wantPublish: true,
+		},
+		{
+			name:       "givesUp",
+			conflicts:  maxUpdateAttempts,
+			wantErr:    true,
+			wantStatus: "started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockTicketRepo()
+			stored := &kitchen.Ticket{ID: uuid.New(), OrderItemID: orderItemID, Status: "started", ModelVersion: 2}
+			repo.AddTicket(stored)
+
+			conflicts := 0
+			repo.FindByOrderItemIDFunc = func(ctx context.Context, id kitchen.OrderItemID) (*kitchen.Ticket, error) {
+				read := *stored
+				return &read, nil
+			}
+			repo.UpdateFunc = func(ctx context.Context, ticket *kitchen.Ticket) error {
+				if conflicts < tt.conflicts {
+					// A cook saved the ticket between our read and write
+					conflicts++
+					stored.ModelVersion++
+					return kitchen.ErrVersionConflict
+				}
+				stored.Status = ticket.Status
+				return nil
+			}
+
+			publisher := NewMockPublisher()
+			s := NewOrderItemSubscriber(&MockSubscriber{}, repo, nil, publisher, apt.NewNoopLogger())
+
+			eventBytes, _ := json.Marshal(event.OrderItemEvent{
+				EventType:          event.EventOrderItemCancelled,
+				OrderItemID:        orderItemID.String(),
+				RequiresProduction: true,
+			})
+			err := s.handleEvent(context.Background(), eventBytes)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handleEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", stored.Status, tt.wantStatus)
+			}
+			if got := len(publisher.PublishedEvents) > 0; got != tt.wantPublish {
+				t.Errorf("published = %v, want %v", got, tt.wantPublish)
+			}
+		})
+	}
+}
+
 func TestOrderItemSubscriberHandleStatusChanged(t *testing.T) {
 	ticketID := uuid.New()
 	orderItemID := uuid.New()
@@ -674,10 +822,11 @@ func TestOrderItemSubscriberPublishError(t *testing.T) {
 	}
 	eventBytes, _ := json.Marshal(evt)
 
-	// Should not return error - publish error is logged but not propagated
+	// The ticket event is saved with the ticket, so failing to store it
+	// fails the order event and it is delivered again
 	err := s.handleEvent(context.Background(), eventBytes)
-	if err != nil {
-		t.Errorf("handleEvent() should not return error for publish failure: %v", err)
+	if err == nil {
+		t.Error("handleEvent() should return error for publish failure")
 	}
 }
 
@@ -751,6 +900,13 @@ func TestOrderItemSubscriberCreatedPublishesEvent(t *testing.T) {
 		TableNumber:        "T5",
 		Quantity:           2,
 		Notes:              "Extra cheese",
+		Modifiers: []event.OrderItemModifier{
+			{GroupName: "Crust", OptionName: "Thin"},
+			{OptionName: "No olives"},
+		},
+		PortionID:       uuid.New().String(),
+		PortionName:     "Half",
+		PrepTimeMinutes: 12,
 	}
 	eventBytes, _ := json.Marshal(evt)
 
@@ -762,7 +918,7 @@ func TestOrderItemSubscriberCreatedPublishesEvent(t *testing.T) {
 
 	// Verify published event content
 	var publishedEvt event.KitchenTicketCreatedEvent
-	json.Unmarshal(publisher.PublishedEvents[0].Data, &publishedEvt)
+	unmarshalEvent(publisher.PublishedEvents[0].Data, &publishedEvt)
 
 	if publishedEvt.EventType != event.EventKitchenTicketCreated {
 		t.Errorf("published event type = %v, want %v", publishedEvt.EventType, event.EventKitchenTicketCreated)
@@ -773,6 +929,22 @@ func TestOrderItemSubscriberCreatedPublishesEvent(t *testing.T) {
 	if publishedEvt.Quantity != 2 {
 		t.Errorf("published event Quantity = %d, want 2", publishedEvt.Quantity)
 	}
+	wantModifiers := []string{"Crust: Thin", "No olives"}
+	if !reflect.DeepEqual(publishedEvt.Modifiers, wantModifiers) {
+		t.Errorf("published event Modifiers = %v, want %v", publishedEvt.Modifiers, wantModifiers)
+	}
+
+	if publishedEvt.PortionName != "Half" || publishedEvt.PrepTime != 12 {
+		t.Errorf("published event portion = %q/%d, want Half/12", publishedEvt.PortionName, publishedEvt.PrepTime)
+	}
+
+	ticket, _ := repo.FindByOrderItemID(context.Background(), uuid.MustParse(evt.OrderItemID))
+	if ticket == nil || !reflect.DeepEqual(ticket.Modifiers, wantModifiers) {
+		t.Errorf("ticket Modifiers = %v, want %v", ticket, wantModifiers)
+	}
+	if ticket != nil && (ticket.PortionName != "Half" || ticket.PrepTime != 12) {
+		t.Errorf("ticket portion = %q/%d, want Half/12", ticket.PortionName, ticket.PrepTime)
+	}
 }
 
 func TestOrderItemSubscriberOccurredAtTimestamp(t *testing.T) {
@@ -798,9 +970,334 @@ func TestOrderItemSubscriberOccurredAtTimestamp(t *testing.T) {
 	after := time.Now()
 
 	var publishedEvt event.KitchenTicketCreatedEvent
-	json.Unmarshal(publisher.PublishedEvents[0].Data, &publishedEvt)
+	unmarshalEvent(publisher.PublishedEvents[0].Data, &publishedEvt)
 
 	if publishedEvt.OccurredAt.Before(before) || publishedEvt.OccurredAt.After(after) {
 		t.Error("OccurredAt timestamp is outside expected range")
 	}
 }
+
+func TestOrderItemSubscriberHoldsLaterCourse(t *testing.T) {
+	tests := []struct {
+		name       string
+		held       bool
+		wantStatus string
+	}{
+		{name: "heldCourse", held: true, wantStatus: "standby"},
+		{name: "firedCourse", held: false, wantStatus: "created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockTicketRepo()
+			publisher := NewMockPublisher()
+			s := NewOrderItemSubscriber(&MockSubscriber{}, repo, nil, publisher, apt.NewNoopLogger())
+
+			orderItemID := uuid.New()
+			evt := event.OrderItemEvent{
+				EventType:          event.EventOrderItemCreated,
+				OrderItemID:        orderItemID.String(),
+				OrderID:            uuid.New().String(),
+				MenuItemID:         uuid.New().String(),
+				RequiresProduction: true,
+				ProductionStation:  "kitchen",
+				Quantity:           1,
+				Course:             2,
+				Held:               tt.held,
+			}
+			eventBytes, _ := json.Marshal(evt)
+			if err := s.handleEvent(context.Background(), eventBytes); err != nil {
+				t.Fatalf("handleEvent() error = %v", err)
+			}
+
+			ticket := repo.byOrderItemID[orderItemID]
+			if ticket == nil {
+				t.Fatal("expected a ticket to be created")
+			}
+			if ticket.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", ticket.Status, tt.wantStatus)
+			}
+			if ticket.Course != 2 {
+				t.Errorf("Course = %d, want 2", ticket.Course)
+			}
+		})
+	}
+}
+
+func TestOrderItemSubscriberRoutesThroughStations(t *testing.T) {
+	stations := station.NewRegistry(nil, 0, nil)
+	stations.Set([]station.Station{
+		{Name: "grill", DisplayName: "Grill"},
+		{Name: "sushi", DisplayName: "Sushi Bar", Hours: "00:00-00:00"},
+		{Name: "other", DisplayName: "Expo"},
+	})
+
+	tests := []struct {
+		name        string
+		station     string
+		wantStation string
+		wantName    string
+	}{
+		{name: "knownStation", station: "Grill", wantStation: "grill", wantName: "Grill"},
+		{name: "unknownStation", station: "fryer", wantStation: "other", wantName: "Expo"},
+		{name: "closedStation", station: "sushi", wantStation: "other", wantName: "Expo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockTicketRepo()
+			s := NewOrderItemSubscriber(&MockSubscriber{}, repo, nil, NewMockPublisher(), apt.NewNoopLogger())
+			s.SetStations(stations)
+
+			orderItemID := uuid.New()
+			evt := event.OrderItemEvent{
+				EventType:          event.EventOrderItemCreated,
+				OrderItemID:        orderItemID.String(),
+				OrderID:            uuid.New().String(),
+				MenuItemID:         uuid.New().String(),
+				RequiresProduction: true,
+				ProductionStation:  tt.station,
+				Quantity:           1,
+			}
+			eventBytes, _ := json.Marshal(evt)
+			if err := s.handleEvent(context.Background(), eventBytes); err != nil {
+				t.Fatalf("handleEvent() error = %v", err)
+			}
+
+			ticket := repo.byOrderItemID[orderItemID]
+			if ticket == nil {
+				t.Fatal("expected a ticket to be created")
+			}
+			if ticket.Station != tt.wantStation || ticket.StationName != tt.wantName {
+				t.Errorf("ticket at %s (%s), want %s (%s)", ticket.Station, ticket.StationName, tt.wantStation, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestOrderItemSubscriberRoutesOnVenueClock(t *testing.T) {
+	// Lunch service runs 12:00-15:00 at a venue two hours ahead of UTC
+	venue := time.FixedZone("venue", 2*60*60)
+
+	tests := []struct {
+		name        string
+		orderedAt   time.Time
+		wantStation string
+	}{
+		{name: "openOnVenueClock", orderedAt: time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC), wantStation: "lunch"},
+		{name: "closedOnVenueClock", orderedAt: time.Date(2026, 10, 16, 13, 30, 0, 0, time.UTC), wantStation: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stations := station.NewRegistry(nil, 0, nil)
+			stations.Set([]station.Station{
+				{Name: "lunch", DisplayName: "Lunch Line", Hours: "12:00-15:00"},
+				{Name: "other", DisplayName: "Expo"},
+			})
+			stations.SetLocation(venue)
+
+			repo := NewMockTicketRepo()
+			s := NewOrderItemSubscriber(&MockSubscriber{}, repo, nil, NewMockPublisher(), apt.NewNoopLogger())
+			s.SetStations(stations)
+
+			orderItemID := uuid.New()
+			evt := event.OrderItemEvent{
+				EventType:          event.EventOrderItemCreated,
+				OccurredAt:         tt.orderedAt,
+				OrderItemID:        orderItemID.String(),
+				OrderID:            uuid.New().String(),
+				MenuItemID:         uuid.New().String(),
+				RequiresProduction: true,
+				ProductionStation:  "lunch",
+				Quantity:           1,
+			}
+			eventBytes, _ := json.Marshal(evt)
+			if err := s.handleEvent(context.Background(), eventBytes); err != nil {
+				t.Fatalf("handleEvent() error = %v", err)
+			}
+
+			ticket := repo.byOrderItemID[orderItemID]
+			if ticket == nil {
+				t.Fatal("expected a ticket to be created")
+			}
+			if ticket.Station != tt.wantStation {
+				t.Errorf("ticket at %s, want %s", ticket.Station, tt.wantStation)
+			}
+		})
+	}
+}
+
+func TestOrderItemSubscriberHandleFired(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		wantStatus  string
+		wantPublish bool
+	}{
+		{name: "releasesHeldTicket", status: "standby", wantStatus: "created", wantPublish: true},
+		{name: "leavesTicketInProgress", status: "started", wantStatus: "started", wantPublish: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderItemID := uuid.New()
+			repo := NewMockTicketRepo()
+			repo.AddTicket(&kitchen.Ticket{
+				ID:          uuid.New(),
+				OrderItemID: orderItemID,
+				OrderID:     uuid.New(),
+				MenuItemID:  uuid.New(),
+				Station:     "kitchen",
+				Status:      tt.status,
+				Course:      2,
+			})
+			cache := kitchen.NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+			publisher := NewMockPublisher()
+			s := NewOrderItemSubscriber(&MockSubscriber{}, repo, cache, publisher, apt.NewNoopLogger())
+
+			evt := event.OrderItemEvent{
+				EventType:          event.EventOrderItemFired,
+				OrderItemID:        orderItemID.String(),
+				RequiresProduction: true,
+				Course:             2,
+			}
+			eventBytes, _ := json.Marshal(evt)
+			if err := s.handleEvent(context.Background(), eventBytes); err != nil {
+				t.Fatalf("handleEvent() error = %v", err)
+			}
+
+			if got := repo.byOrderItemID[orderItemID].Status; got != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", got, tt.wantStatus)
+			}
+			if fired := repo.byOrderItemID[orderItemID].FiredAt != nil; fired != tt.wantPublish {
+				t.Errorf("FiredAt set = %v, want %v", fired, tt.wantPublish)
+			}
+			if published := len(publisher.PublishedEvents) > 0; published != tt.wantPublish {
+				t.Errorf("published = %v, want %v", published, tt.wantPublish)
+			}
+			if !tt.wantPublish {
+				return
+			}
+
+			var changed event.KitchenTicketStatusChangedEvent
+			if err := unmarshalEvent(publisher.PublishedEvents[0].Data, &changed); err != nil {
+				t.Fatalf("cannot decode published event: %v", err)
+			}
+			if changed.PreviousStatus != "standby" || changed.NewStatus != "created" || changed.Course != 2 {
+				t.Errorf("published %s -> %s (course %d), want standby -> created (course 2)", changed.PreviousStatus, changed.NewStatus, changed.Course)
+			}
+		})
+	}
+}
+
+func TestOrderItemSubscriberSkipsRedeliveredEvent(t *testing.T) {
+	var handler events.HandlerFunc
+	subscriber := &MockSubscriber{
+		SubscribeFunc: func(ctx context.Context, topic string, h events.HandlerFunc) error {
+			handler = h
+			return nil
+		},
+	}
+
+	created := 0
+	repo := NewMockTicketRepo()
+	// The ticket lookup races with the first delivery, so only the event ID
+	// tells the copies apart
+	repo.FindByOrderItemIDFunc = func(ctx context.Context, id kitchen.OrderItemID) (*kitchen.Ticket, error) {
+		return nil, nil
+	}
+	repo.CreateFunc = func(ctx context.Context, t *kitchen.Ticket) error {
+		created++
+		return nil
+	}
+	cache := kitchen.NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	publisher := NewMockPublisher()
+
+	s := NewOrderItemSubscriber(subscriber, repo, cache, publisher, apt.NewNoopLogger())
+	s.SetIdempotencyStore(idempotency.NewMemoryStore(time.Hour))
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	evt := event.OrderItemEvent{
+		EventID:            event.NewID(),
+		EventType:          event.EventOrderItemCreated,
+		OrderID:            uuid.New().String(),
+		OrderItemID:        uuid.New().String(),
+		MenuItemID:         uuid.New().String(),
+		ProductionStation:  "kitchen",
+		Quantity:           1,
+		RequiresProduction: true,
+	}
+	eventBytes, _ := json.Marshal(evt)
+
+	for i := 0; i < 2; i++ {
+		if err := handler(context.Background(), eventBytes); err != nil {
+			t.Fatalf("delivery %d error = %v", i+1, err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("tickets created = %d, want 1", created)
+	}
+
+	// A new event for the same item is still handled
+	evt.EventID = event.NewID()
+	eventBytes, _ = json.Marshal(evt)
+	if err := handler(context.Background(), eventBytes); err != nil {
+		t.Fatalf("new event error = %v", err)
+	}
+	if created != 2 {
+		t.Errorf("tickets created = %d, want 2", created)
+	}
+
+	if len(publisher.PublishedEvents) == 0 {
+		t.Fatal("no ticket created event published")
+	}
+	if id := event.IDOf(publisher.PublishedEvents[0].Data); id == "" {
+		t.Error("ticket created event has no event_id")
+	}
+}
+
+func TestOrderItemSubscriberTracesTicketToOrderItemEvent(t *testing.T) {
+	repo := NewMockTicketRepo()
+	cache := kitchen.NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	publisher := NewMockPublisher()
+	s := NewOrderItemSubscriber(&MockSubscriber{}, repo, cache, publisher, apt.NewNoopLogger())
+
+	evt := event.OrderItemEvent{
+		EventID:            event.NewID(),
+		EventType:          event.EventOrderItemCreated,
+		OccurredAt:         time.Now().UTC(),
+		OrderID:            uuid.New().String(),
+		OrderItemID:        uuid.New().String(),
+		MenuItemID:         uuid.New().String(),
+		Quantity:           1,
+		RequiresProduction: true,
+	}
+	msg, err := event.Marshal(context.Background(), "order-service", evt)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if err := s.handleEvent(context.Background(), msg); err != nil {
+		t.Fatalf("handleEvent() error = %v", err)
+	}
+
+	if len(publisher.PublishedEvents) != 1 {
+		t.Fatalf("published %d events, want 1", len(publisher.PublishedEvents))
+	}
+	env, err := event.Open(publisher.PublishedEvents[0].Data)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if env.Type != event.EventKitchenTicketCreated || env.Source != kitchen.EventSource || env.SchemaVersion != 1 {
+		t.Errorf("envelope = %s v%d from %s, want %s v1 from %s", env.Type, env.SchemaVersion, env.Source, event.EventKitchenTicketCreated, kitchen.EventSource)
+	}
+	if env.CausationID != evt.EventID {
+		t.Errorf("CausationID = %q, want the order item event %q", env.CausationID, evt.EventID)
+	}
+	// The order item event started the chain, so it is the correlation ID
+	if env.CorrelationID != evt.EventID {
+		t.Errorf("CorrelationID = %q, want %q", env.CorrelationID, evt.EventID)
+	}
+}
@@ -0,0 +1,318 @@
+package kitchen
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
+)
+
+// Ways to group the analytics report.
+const (
+	GroupByStation = "station"
+	GroupByItem    = "item"
+	GroupByHour    = "hour"
+	GroupByCook    = "cook"
+)
+
+var analyticsGroups = map[string]bool{
+	GroupByStation: true,
+	GroupByItem:    true,
+	GroupByHour:    true,
+	GroupByCook:    true,
+}
+
+// DurationStats summarises how long one phase of the tickets took, in
+// seconds.
+type DurationStats struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_seconds"`
+	P90   float64 `json:"p90_seconds"`
+	Max   float64 `json:"max_seconds"`
+}
+
+// AnalyticsRow holds the timings of one group of tickets. Queue runs from
+// ordered (or fired) to started, cook from started to ready and pass from
+// ready to delivered.
+type AnalyticsRow struct {
+	Key     string        `json:"key"`
+	Label   string        `json:"label"`
+	Tickets int           `json:"tickets"`
+	Queue   DurationStats `json:"queue"`
+	Cook    DurationStats `json:"cook"`
+	Pass    DurationStats `json:"pass"`
+}
+
+// AnalyticsReport is the line performance over the tickets created in
+// [From, To).
+type AnalyticsReport struct {
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	GroupBy string         `json:"group_by"`
+	Overall AnalyticsRow   `json:"overall"`
+	Rows    []AnalyticsRow `json:"rows"`
+}
+
+// AnalyticsQuery selects the tickets and grouping of a report.
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  string
+	Station  string
+	Location *time.Location // Used for hours of the day
+}
+
+// ParseAnalyticsQuery reads from, to, group_by, station and tz. Dates are
+// YYYY-MM-DD in tz (UTC by default) and both ends are inclusive; RFC 3339
+// times are taken as they are. Without dates the report covers the last
+// seven days up to today.
+func ParseAnalyticsQuery(get func(string) string, now time.Time) (AnalyticsQuery, error) {
+	query := AnalyticsQuery{
+		GroupBy:  strings.ToLower(strings.TrimSpace(get("group_by"))),
+		Station:  strings.TrimSpace(get("station")),
+		Location: time.UTC,
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = GroupByStation
+	}
+	if !analyticsGroups[query.GroupBy] {
+		return query, fmt.Errorf("unknown group_by %q: use station, item, hour or cook", query.GroupBy)
+	}
+
+	if tz := strings.TrimSpace(get("tz")); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return query, fmt.Errorf("unknown time zone %q", tz)
+		}
+		query.Location = loc
+	}
+
+	today := startOfDay(now.In(query.Location))
+	query.From = today.AddDate(0, 0, -6)
+	query.To = today.AddDate(0, 0, 1)
+
+	if value := strings.TrimSpace(get("from")); value != "" {
+		from, _, err := parseAnalyticsTime(value, query.Location)
+		if err != nil {
+			return query, fmt.Errorf("invalid from: %w", err)
+		}
+		query.From = from
+	}
+	if value := strings.TrimSpace(get("to")); value != "" {
+		to, isDate, err := parseAnalyticsTime(value, query.Location)
+		if err != nil {
+			return query, fmt.Errorf("invalid to: %w", err)
+		}
+		if isDate {
+			// The whole day is included
+			to = to.AddDate(0, 0, 1)
+		}
+		query.To = to
+	}
+
+	if !query.From.Before(query.To) {
+		return query, fmt.Errorf("from must be before to")
+	}
+	return query, nil
+}
+
+func parseAnalyticsTime(value string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("use YYYY-MM-DD or RFC 3339, got %q", value)
+	}
+	return t, false, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// ticketPhases returns how long the ticket spent queued, cooking and on the
+// pass. A phase the ticket has not finished, or whose times are out of
+// order, is left out.
+func ticketPhases(t *Ticket) (queue, cook, pass *time.Duration) {
+	span := func(from, to *time.Time) *time.Duration {
+		if from == nil || to == nil || to.Before(*from) {
+			return nil
+		}
+		d := to.Sub(*from)
+		return &d
+	}
+
+	ordered := t.CreatedAt
+	if t.FiredAt != nil {
+		ordered = *t.FiredAt
+	}
+	return span(&ordered, t.StartedAt), span(t.StartedAt, t.FinishedAt), span(t.FinishedAt, t.DeliveredAt)
+}
+
+// analyticsGroup returns the key and label of the ticket's group.
+func analyticsGroup(t *Ticket, groupBy string, loc *time.Location) (string, string) {
+	switch groupBy {
+	case GroupByItem:
+		label := t.MenuItemName
+		if label == "" {
+			label = t.MenuItemID.String()
+		}
+		return t.MenuItemID.String(), label
+	case GroupByHour:
+		hour := t.CreatedAt.In(loc).Hour()
+		return fmt.Sprintf("%02d", hour), fmt.Sprintf("%02d:00", hour)
+	case GroupByCook:
+		if t.Cook == "" {
+			return "", "Unassigned"
+		}
+		return t.Cook, t.Cook
+	default:
+		label := t.StationName
+		if label == "" {
+			label = t.Station
+		}
+		return t.Station, label
+	}
+}
+
+type phaseSamples struct {
+	tickets           int
+	queue, cook, pass []time.Duration
+}
+
+func (s *phaseSamples) add(t *Ticket) {
+	s.tickets++
+	queue, cook, pass := ticketPhases(t)
+	if queue != nil {
+		s.queue = append(s.queue, *queue)
+	}
+	if cook != nil {
+		s.cook = append(s.cook, *cook)
+	}
+	if pass != nil {
+		s.pass = append(s.pass, *pass)
+	}
+}
+
+func (s *phaseSamples) row(key, label string) AnalyticsRow {
+	return AnalyticsRow{
+		Key:     key,
+		Label:   label,
+		Tickets: s.tickets,
+		Queue:   durationStats(s.queue),
+		Cook:    durationStats(s.cook),
+		Pass:    durationStats(s.pass),
+	}
+}
+
+// durationStats computes nearest-rank percentiles over samples.
+func durationStats(samples []time.Duration) DurationStats {
+	if len(samples) == 0 {
+		return DurationStats{}
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := func(p float64) float64 {
+		idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return sorted[idx].Seconds()
+	}
+
+	return DurationStats{
+		Count: len(sorted),
+		P50:   rank(0.5),
+		P90:   rank(0.9),
+		Max:   sorted[len(sorted)-1].Seconds(),
+	}
+}
+
+// BuildAnalyticsReport aggregates the tickets by the query's grouping.
+// Rejected and cancelled tickets never went through the line and are left
+// out.
+func BuildAnalyticsReport(tickets []Ticket, query AnalyticsQuery) AnalyticsReport {
+	loc := query.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	overall := &phaseSamples{}
+	groups := map[string]*phaseSamples{}
+	labels := map[string]string{}
+	for i := range tickets {
+		t := &tickets[i]
+		if t.Status == kitchenstatus.Statuses.Reject.Code() || t.Status == kitchenstatus.Statuses.Cancelled.Code() {
+			continue
+		}
+
+		key, label := analyticsGroup(t, query.GroupBy, loc)
+		if groups[key] == nil {
+			groups[key] = &phaseSamples{}
+			labels[key] = label
+		}
+		groups[key].add(t)
+		overall.add(t)
+	}
+
+	report := AnalyticsReport{
+		From:    query.From,
+		To:      query.To,
+		GroupBy: query.GroupBy,
+		Overall: overall.row("", "All tickets"),
+		Rows:    make([]AnalyticsRow, 0, len(groups)),
+	}
+	for key, samples := range groups {
+		report.Rows = append(report.Rows, samples.row(key, labels[key]))
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if query.GroupBy == GroupByHour {
+			return report.Rows[i].Key < report.Rows[j].Key
+		}
+		return strings.ToLower(report.Rows[i].Label) < strings.ToLower(report.Rows[j].Label)
+	})
+	return report
+}
+
+// WriteCSV writes one line per group plus a final line for all tickets.
+// Times are in seconds.
+func (r AnalyticsReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{r.GroupBy, "tickets"}
+	for _, phase := range []string{"queue", "cook", "pass"} {
+		header = append(header, phase+"_count", phase+"_p50_s", phase+"_p90_s", phase+"_max_s")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	rows := append(append([]AnalyticsRow{}, r.Rows...), r.Overall)
+	for _, row := range rows {
+		record := []string{row.Label, strconv.Itoa(row.Tickets)}
+		for _, stats := range []DurationStats{row.Queue, row.Cook, row.Pass} {
+			record = append(record,
+				strconv.Itoa(stats.Count),
+				strconv.FormatFloat(stats.P50, 'f', 0, 64),
+				strconv.FormatFloat(stats.P90, 'f', 0, 64),
+				strconv.FormatFloat(stats.Max, 'f', 0, 64),
+			)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,272 @@
+package kitchen
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestParseAnalyticsQuery(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		values   url.Values
+		wantFrom time.Time
+		wantTo   time.Time
+		wantBy   string
+		wantErr  bool
+	}{
+		{
+			name:     "defaultsToLastWeek",
+			values:   url.Values{},
+			wantFrom: day(2024, 5, 4),
+			wantTo:   day(2024, 5, 11),
+			wantBy:   GroupByStation,
+		},
+		{
+			name:     "datesAreInclusive",
+			values:   url.Values{"from": {"2024-05-01"}, "to": {"2024-05-01"}, "group_by": {"Cook"}},
+			wantFrom: day(2024, 5, 1),
+			wantTo:   day(2024, 5, 2),
+			wantBy:   GroupByCook,
+		},
+		{
+			name:     "rfc3339",
+			values:   url.Values{"from": {"2024-05-01T18:00:00Z"}, "to": {"2024-05-01T23:00:00Z"}, "group_by": {"hour"}},
+			wantFrom: time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC),
+			wantBy:   GroupByHour,
+		},
+		{name: "unknownGroup", values: url.Values{"group_by": {"waiter"}}, wantErr: true},
+		{name: "badDate", values: url.Values{"from": {"yesterday"}}, wantErr: true},
+		{name: "reversedRange", values: url.Values{"from": {"2024-05-03"}, "to": {"2024-05-01"}}, wantErr: true},
+		{name: "unknownZone", values: url.Values{"tz": {"Mars/Olympus"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAnalyticsQuery(tt.values.Get, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAnalyticsQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.From.Equal(tt.wantFrom) || !got.To.Equal(tt.wantTo) || got.GroupBy != tt.wantBy {
+				t.Errorf("ParseAnalyticsQuery() = %v to %v by %s, want %v to %v by %s", got.From, got.To, got.GroupBy, tt.wantFrom, tt.wantTo, tt.wantBy)
+			}
+		})
+	}
+}
+
+func TestDurationStats(t *testing.T) {
+	samples := []time.Duration{}
+	for i := 10; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Minute)
+	}
+
+	got := durationStats(samples)
+	if got.Count != 10 || got.P50 != 300 || got.P90 != 540 || got.Max != 600 {
+		t.Errorf("durationStats() = %+v, want 10 samples, p50 300s, p90 540s, max 600s", got)
+	}
+	if samples[0] != 10*time.Minute {
+		t.Error("durationStats() should not reorder the samples")
+	}
+	if empty := durationStats(nil); empty != (DurationStats{}) {
+		t.Errorf("durationStats(nil) = %+v, want zero", empty)
+	}
+}
+
+// analyticsTicket is created at created and spends queue, cooking and pass
+// minutes in each phase; a negative phase was not reached.
+func analyticsTicket(station, cook string, created time.Time, queue, cooking, pass int) Ticket {
+	t := Ticket{
+		ID:          uuid.New(),
+		Station:     station,
+		StationName: strings.ToUpper(station[:1]) + station[1:],
+		Cook:        cook,
+		Status:      "created",
+		CreatedAt:   created,
+	}
+	at := created
+	if queue >= 0 {
+		at = at.Add(time.Duration(queue) * time.Minute)
+		started := at
+		t.StartedAt, t.Status = &started, "started"
+	}
+	if queue >= 0 && cooking >= 0 {
+		at = at.Add(time.Duration(cooking) * time.Minute)
+		finished := at
+		t.FinishedAt, t.Status = &finished, "ready"
+	}
+	if queue >= 0 && cooking >= 0 && pass >= 0 {
+		at = at.Add(time.Duration(pass) * time.Minute)
+		delivered := at
+		t.DeliveredAt, t.Status = &delivered, "delivered"
+	}
+	return t
+}
+
+func TestBuildAnalyticsReport(t *testing.T) {
+	evening := time.Date(2024, 5, 10, 20, 15, 0, 0, time.UTC)
+	lunch := time.Date(2024, 5, 10, 13, 5, 0, 0, time.UTC)
+
+	cancelled := analyticsTicket("kitchen", "Ana", lunch, 50, 50, 50)
+	cancelled.Status = "cancelled"
+
+	tickets := []Ticket{
+		analyticsTicket("kitchen", "Ana", evening, 2, 10, 1),
+		analyticsTicket("kitchen", "Ana", evening, 4, 20, 3),
+		analyticsTicket("kitchen", "", lunch, 6, 12, -1),
+		analyticsTicket("bar", "Luis", lunch, 1, 3, 2),
+		analyticsTicket("bar", "Luis", lunch, 5, -1, -1),
+		cancelled,
+	}
+
+	report := BuildAnalyticsReport(tickets, AnalyticsQuery{GroupBy: GroupByStation})
+	if report.Overall.Tickets != 5 || report.Overall.Queue.Count != 5 || report.Overall.Cook.Count != 4 || report.Overall.Pass.Count != 3 {
+		t.Errorf("overall = %+v, want 5 tickets with 5 queued, 4 cooked, 3 passed", report.Overall)
+	}
+	if len(report.Rows) != 2 || report.Rows[0].Label != "Bar" || report.Rows[1].Label != "Kitchen" {
+		t.Fatalf("rows = %+v, want Bar and Kitchen", report.Rows)
+	}
+	kitchen := report.Rows[1]
+	if kitchen.Tickets != 3 || kitchen.Cook.P50 != 720 || kitchen.Cook.Max != 1200 || kitchen.Pass.Count != 2 {
+		t.Errorf("kitchen = %+v, want 3 tickets, cook p50 720s max 1200s, 2 passed", kitchen)
+	}
+
+	byHour := BuildAnalyticsReport(tickets, AnalyticsQuery{GroupBy: GroupByHour})
+	if len(byHour.Rows) != 2 || byHour.Rows[0].Label != "13:00" || byHour.Rows[1].Label != "20:00" {
+		t.Errorf("hour rows = %+v, want 13:00 then 20:00", byHour.Rows)
+	}
+
+	madrid, _ := time.LoadLocation("Europe/Madrid")
+	local := BuildAnalyticsReport(tickets, AnalyticsQuery{GroupBy: GroupByHour, Location: madrid})
+	if len(local.Rows) != 2 || local.Rows[0].Label != "15:00" {
+		t.Errorf("local hour rows = %+v, want 15:00 first", local.Rows)
+	}
+
+	byCook := BuildAnalyticsReport(tickets, AnalyticsQuery{GroupBy: GroupByCook})
+	labels := []string{}
+	for _, row := range byCook.Rows {
+		labels = append(labels, row.Label)
+	}
+	if strings.Join(labels, ",") != "Ana,Luis,Unassigned" {
+		t.Errorf("cook rows = %v, want Ana, Luis, Unassigned", labels)
+	}
+}
+
+func TestAnalyticsReportWriteCSV(t *testing.T) {
+	tickets := []Ticket{analyticsTicket("bar", "Luis", time.Date(2024, 5, 10, 13, 0, 0, 0, time.UTC), 1, 3, 2)}
+	report := BuildAnalyticsReport(tickets, AnalyticsQuery{GroupBy: GroupByStation})
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("cannot read csv: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("csv has %d records, want header, one group and the total", len(records))
+	}
+	if strings.Join(records[0][:6], ",") != "station,tickets,queue_count,queue_p50_s,queue_p90_s,queue_max_s" {
+		t.Errorf("header = %v", records[0])
+	}
+	if strings.Join(records[1], ",") != "Bar,1,1,60,60,60,1,180,180,180,1,120,120,120" {
+		t.Errorf("bar record = %v", records[1])
+	}
+	if records[2][0] != "All tickets" {
+		t.Errorf("last record = %v, want the total", records[2])
+	}
+}
+
+func TestHandlerTicketAnalytics(t *testing.T) {
+	repo := NewMockTicketRepository()
+	inRange := analyticsTicket("kitchen", "Ana", time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC), 2, 10, 1)
+	outOfRange := analyticsTicket("kitchen", "Ana", time.Date(2024, 4, 1, 20, 0, 0, 0, time.UTC), 2, 10, 1)
+	repo.AddTicket(&inRange)
+	repo.AddTicket(&outOfRange)
+
+	h := NewHandler(HandlerDeps{Repo: repo}, apt.NewConfig(), apt.NewNoopLogger())
+	r := chi.NewRouter()
+	r.Get("/analytics/tickets", h.TicketAnalytics)
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/tickets?from=2024-05-01&to=2024-05-31&group_by=cook", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+
+		var resp struct {
+			Data AnalyticsReport `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("cannot decode response: %v", err)
+		}
+		if resp.Data.Overall.Tickets != 1 || len(resp.Data.Rows) != 1 || resp.Data.Rows[0].Label != "Ana" {
+			t.Errorf("report = %+v, want the one ticket in range, by Ana", resp.Data)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/tickets?from=2024-05-01&to=2024-05-31&format=csv", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("Content-Type"); got != "text/csv" {
+			t.Errorf("Content-Type = %q, want text/csv", got)
+		}
+		if got := w.Header().Get("Content-Disposition"); !strings.Contains(got, "kitchen-station-20240501-20240531.csv") {
+			t.Errorf("Content-Disposition = %q", got)
+		}
+		if !strings.HasPrefix(w.Body.String(), "station,tickets,") {
+			t.Errorf("body = %q, want csv", w.Body.String())
+		}
+	})
+
+	t.Run("badQuery", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/tickets?group_by=waiter", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestHandlerUpdateTicketStatusRecordsCook(t *testing.T) {
+	ticket := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "accepted"}
+	repo := NewMockTicketRepository()
+	repo.AddTicket(ticket)
+
+	h := NewHandler(HandlerDeps{Repo: repo, Publisher: NewMockPublisher()}, apt.NewConfig(), apt.NewNoopLogger())
+	r := chi.NewRouter()
+	r.Patch("/tickets/{id}/status", h.UpdateTicketStatus)
+
+	req := httptest.NewRequest(http.MethodPatch, "/tickets/"+ticket.ID.String()+"/status", strings.NewReader(`{"status":"started","cook":"Ana"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if got, _ := repo.FindByID(req.Context(), ticket.ID); got.Cook != "Ana" {
+		t.Errorf("Cook = %q, want Ana", got.Cook)
+	}
+}
@@ -0,0 +1,467 @@
+package kitchen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDecisionPending is returned when the ticket already waits for an
+	// answer from the floor
+	ErrDecisionPending = errors.New("ticket is already waiting for a decision")
+	// ErrNoPendingDecision is returned when answering a ticket nobody asked
+	// about, or one already answered
+	ErrNoPendingDecision = errors.New("ticket has no pending decision")
+	// ErrInvalidDecision is returned for questions or answers that cannot be
+	// applied to the ticket
+	ErrInvalidDecision = errors.New("invalid decision")
+)
+
+// Defaults for decisions when the configuration leaves them out.
+const (
+	DefaultDecisionTimeout  = 5 * time.Minute
+	DefaultDecisionInterval = 15 * time.Second
+)
+
+// DecisionConfig says how long the floor has to answer the kitchen before
+// the question goes to a manager, and how often that is checked.
+type DecisionConfig struct {
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// LoadDecisionConfig reads decisions.timeout and decisions.interval.
+func LoadDecisionConfig(config *apt.Config) (DecisionConfig, error) {
+	cfg := DecisionConfig{
+		Timeout:  DefaultDecisionTimeout,
+		Interval: DefaultDecisionInterval,
+	}
+	if config == nil {
+		return cfg, nil
+	}
+
+	if value, _ := config.GetString("decisions.timeout"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return cfg, fmt.Errorf("invalid decisions.timeout %q", value)
+		}
+		cfg.Timeout = timeout
+	}
+
+	if value, _ := config.GetString("decisions.interval"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return cfg, fmt.Errorf("invalid decisions.interval %q", value)
+		}
+		cfg.Interval = interval
+	}
+
+	return cfg, nil
+}
+
+// Decision is a question the kitchen puts to the floor about a ticket, such
+// as what to do with an item that ran out. It is kept in the ticket's
+// DecisionPayload, answered or not.
+type Decision struct {
+	Question    string                        `json:"question"`
+	Options     []event.KitchenDecisionOption `json:"options"`
+	RaisedBy    string                        `json:"raised_by,omitempty"`
+	RaisedAt    time.Time                     `json:"raised_at"`
+	ExpiresAt   time.Time                     `json:"expires_at"` // Escalated to a manager after this
+	EscalatedAt *time.Time                    `json:"escalated_at,omitempty"`
+	ChosenID    string                        `json:"chosen_id,omitempty"`
+	AnsweredBy  string                        `json:"answered_by,omitempty"`
+	AnsweredAt  *time.Time                    `json:"answered_at,omitempty"`
+}
+
+// Option returns the option with id, or nil.
+func (d *Decision) Option(id string) *event.KitchenDecisionOption {
+	for i := range d.Options {
+		if d.Options[i].ID == id {
+			return &d.Options[i]
+		}
+	}
+	return nil
+}
+
+// normalize checks the question and its options, numbering the options
+// that come without an ID.
+func (d *Decision) normalize() error {
+	d.Question = strings.TrimSpace(d.Question)
+	if d.Question == "" {
+		return fmt.Errorf("%w: question is required", ErrInvalidDecision)
+	}
+	if len(d.Options) == 0 {
+		return fmt.Errorf("%w: at least one option is required", ErrInvalidDecision)
+	}
+
+	seen := map[string]bool{}
+	for i := range d.Options {
+		option := &d.Options[i]
+		option.ID = strings.TrimSpace(option.ID)
+		if option.ID == "" {
+			option.ID = strconv.Itoa(i + 1)
+		}
+		if seen[option.ID] {
+			return fmt.Errorf("%w: option %q is listed twice", ErrInvalidDecision, option.ID)
+		}
+		seen[option.ID] = true
+
+		switch option.Outcome {
+		case event.DecisionOutcomeSubstitute:
+			if _, err := uuid.Parse(option.MenuItemID); err != nil {
+				return fmt.Errorf("%w: substitute %q needs a menu item", ErrInvalidDecision, option.ID)
+			}
+			if option.Label == "" {
+				option.Label = "Substitute with " + option.MenuItemName
+			}
+		case event.DecisionOutcomeCancel:
+			if option.Label == "" {
+				option.Label = "Cancel the item"
+			}
+		case event.DecisionOutcomeProceed:
+			if option.Label == "" {
+				option.Label = "Go ahead"
+			}
+		default:
+			return fmt.Errorf("%w: option %q has unknown outcome %q", ErrInvalidDecision, option.ID, option.Outcome)
+		}
+	}
+	return nil
+}
+
+// Decision returns the last decision asked about the ticket, pending or
+// answered, or nil when the kitchen never asked.
+func (t *Ticket) Decision() (*Decision, error) {
+	if len(t.DecisionPayload) == 0 {
+		return nil, nil
+	}
+	var d Decision
+	if err := json.Unmarshal(t.DecisionPayload, &d); err != nil {
+		return nil, fmt.Errorf("cannot decode decision of ticket %s: %w", t.ID, err)
+	}
+	return &d, nil
+}
+
+// RaiseDecision asks the floor to pick one of the decision's options. The
+// ticket cannot be accepted, started or finished until someone answers, and
+// the question goes to a manager when nobody does within timeout.
+func (t *Ticket) RaiseDecision(d Decision, now time.Time, timeout time.Duration) (*Decision, error) {
+	if t.DecisionRequired {
+		return nil, ErrDecisionPending
+	}
+	if status := kitchenstatus.ByName(t.Status); status == nil || status.IsFinal() {
+		return nil, fmt.Errorf("%w: ticket is already %s", ErrInvalidDecision, t.Status)
+	}
+	if err := d.normalize(); err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = DefaultDecisionTimeout
+	}
+
+	d.RaisedAt = now
+	d.ExpiresAt = now.Add(timeout)
+	d.EscalatedAt, d.ChosenID, d.AnsweredBy, d.AnsweredAt = nil, "", "", nil
+
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode decision: %w", err)
+	}
+	t.DecisionRequired = true
+	t.DecisionPayload = payload
+	return &d, nil
+}
+
+// ResolveDecision records the floor's answer and applies it to the ticket: a
+// substitute changes the menu item the ticket makes, cancel cancels the
+// ticket and proceed lets it go on. The chosen option's note is added to the
+// ticket notes.
+func (t *Ticket) ResolveDecision(optionID, by string, now time.Time) (*Decision, *event.KitchenDecisionOption, error) {
+	d, err := t.Decision()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !t.DecisionRequired || d == nil {
+		return nil, nil, ErrNoPendingDecision
+	}
+
+	option := d.Option(optionID)
+	if option == nil {
+		return nil, nil, fmt.Errorf("%w: unknown option %q", ErrInvalidDecision, optionID)
+	}
+	cancel := option.Outcome == event.DecisionOutcomeCancel
+	if cancel {
+		if err := t.CanTransition(kitchenstatus.Statuses.Cancelled.Code()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	d.ChosenID = option.ID
+	d.AnsweredBy = by
+	d.AnsweredAt = &now
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encode decision: %w", err)
+	}
+	t.DecisionRequired = false
+	t.DecisionPayload = payload
+
+	switch option.Outcome {
+	case event.DecisionOutcomeSubstitute:
+		menuItemID, _ := uuid.Parse(option.MenuItemID)
+		t.Notes = appendNote(t.Notes, "Instead of "+t.MenuItemName)
+		t.MenuItemID = menuItemID
+		t.MenuItemName = option.MenuItemName
+	case event.DecisionOutcomeCancel:
+		if err := t.Transition(kitchenstatus.Statuses.Cancelled.Code(), now); err != nil {
+			return nil, nil, err
+		}
+	}
+	t.Notes = appendNote(t.Notes, option.Note)
+
+	return d, option, nil
+}
+
+// EscalateDecision marks the pending decision as handed to a manager once it
+// expired unanswered. It reports whether the decision was escalated now.
+func (t *Ticket) EscalateDecision(now time.Time) (*Decision, bool, error) {
+	if !t.DecisionRequired {
+		return nil, false, nil
+	}
+	d, err := t.Decision()
+	if err != nil || d == nil {
+		return nil, false, err
+	}
+	if d.EscalatedAt != nil || now.Before(d.ExpiresAt) {
+		return d, false, nil
+	}
+
+	d.EscalatedAt = &now
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot encode decision: %w", err)
+	}
+	t.DecisionPayload = payload
+	return d, true, nil
+}
+
+func appendNote(notes, note string) string {
+	note = strings.TrimSpace(note)
+	switch {
+	case note == "":
+		return notes
+	case notes == "":
+		return note
+	default:
+		return notes + "; " + note
+	}
+}
+
+// decisionEvent describes the ticket's decision for NATS and the stream.
+func decisionEvent(eventType string, ticket *Ticket, d *Decision, at time.Time) *event.KitchenTicketDecisionEvent {
+	evt := &event.KitchenTicketDecisionEvent{
+		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
+			EventID:      event.NewID(),
+			EventType:    eventType,
+			OccurredAt:   at,
+			TicketID:     ticket.ID.String(),
+			OrderID:      ticket.OrderID.String(),
+			OrderItemID:  ticket.OrderItemID.String(),
+			MenuItemID:   ticket.MenuItemID.String(),
+			Station:      ticket.Station,
+			MenuItemName: ticket.MenuItemName,
+			StationName:  ticket.StationName,
+			TableNumber:  ticket.TableNumber,
+			Modifiers:    ticket.Modifiers,
+			PortionName:  ticket.PortionName,
+			PrepTime:     ticket.PrepTime,
+			Course:       ticket.Course,
+			ModelVersion: ticket.ModelVersion,
+		},
+		Question:   d.Question,
+		Options:    d.Options,
+		RaisedBy:   d.RaisedBy,
+		ExpiresAt:  d.ExpiresAt,
+		Escalated:  d.EscalatedAt != nil,
+		AnsweredBy: d.AnsweredBy,
+	}
+	if d.ChosenID != "" {
+		evt.Chosen = d.Option(d.ChosenID)
+	}
+	return evt
+}
+
+// decisionFromEvent rebuilds a decision from its event, keeping what only
+// the previous state of the decision knows, such as when it was raised.
+func decisionFromEvent(evt *event.KitchenTicketDecisionEvent, previous *Decision) Decision {
+	d := Decision{RaisedAt: evt.OccurredAt}
+	if previous != nil && evt.EventType != event.EventKitchenDecisionRequested {
+		d = *previous
+	}
+
+	d.Question = evt.Question
+	d.Options = evt.Options
+	d.RaisedBy = evt.RaisedBy
+	d.ExpiresAt = evt.ExpiresAt
+	at := evt.OccurredAt
+	if evt.Escalated && d.EscalatedAt == nil {
+		d.EscalatedAt = &at
+	}
+	if evt.EventType == event.EventKitchenDecisionResolved {
+		d.AnsweredAt = &at
+		d.AnsweredBy = evt.AnsweredBy
+		if evt.Chosen != nil {
+			d.ChosenID = evt.Chosen.ID
+		}
+	}
+	return d
+}
+
+// announceDecision publishes the decision event on NATS and to the gRPC
+// stream subscribers.
+func announceDecision(ctx context.Context, publisher events.Publisher, stream *EventStreamServer, logger apt.Logger, evt *event.KitchenTicketDecisionEvent) {
+	if stream != nil {
+		stream.BroadcastDecision(evt)
+	}
+	if err := publishDecision(ctx, publisher, evt); err != nil {
+		logger.Errorf("Failed to publish %s event: %v", evt.EventType, err)
+	}
+}
+
+// publishDecision publishes a decision event on the kitchen tickets topic.
+func publishDecision(ctx context.Context, publisher events.Publisher, evt *event.KitchenTicketDecisionEvent) error {
+	if publisher == nil {
+		return nil
+	}
+
+	payload, err := event.Marshal(ctx, EventSource, evt)
+	if err != nil {
+		return fmt.Errorf("cannot marshal %s event: %w", evt.EventType, err)
+	}
+	return publisher.Publish(ctx, event.KitchenTicketsTopic, payload)
+}
+
+// DecisionMonitor hands decisions nobody answered in time to a manager.
+type DecisionMonitor struct {
+	cache     *TicketStateCache
+	repo      TicketRepository
+	publisher events.Publisher
+	stream    *EventStreamServer
+	config    DecisionConfig
+	logger    apt.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDecisionMonitor creates a monitor over the tickets in cache.
+func NewDecisionMonitor(cache *TicketStateCache, repo TicketRepository, publisher events.Publisher, stream *EventStreamServer, config DecisionConfig, logger apt.Logger) *DecisionMonitor {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultDecisionInterval
+	}
+	return &DecisionMonitor{
+		cache:     cache,
+		repo:      repo,
+		publisher: publisher,
+		stream:    stream,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Start runs the checks in the background until Stop is called.
+func (m *DecisionMonitor) Start(ctx context.Context) error {
+	if m.cache == nil || m.repo == nil {
+		m.logger.Info("ticket cache not configured, decision escalation disabled")
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(runCtx)
+
+	m.logger.Info("decision monitor started", "interval", m.config.Interval.String())
+	return nil
+}
+
+// Stop ends the background checks.
+func (m *DecisionMonitor) Stop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *DecisionMonitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Check(ctx, now.UTC())
+		}
+	}
+}
+
+// Check escalates the decisions that expired unanswered at now and returns
+// their tickets. A ticket someone changed meanwhile is left for the next
+// check.
+func (m *DecisionMonitor) Check(ctx context.Context, now time.Time) []*Ticket {
+	var escalated []*Ticket
+	for _, cached := range m.cache.GetAll() {
+		if !cached.DecisionRequired {
+			continue
+		}
+		if d, err := cached.Decision(); err != nil || d == nil || d.EscalatedAt != nil || now.Before(d.ExpiresAt) {
+			continue
+		}
+
+		ticket, err := m.repo.FindByID(ctx, cached.ID)
+		if err != nil {
+			m.logger.Errorf("Cannot load ticket %s to escalate its decision: %v", cached.ID, err)
+			continue
+		}
+		d, ok, err := ticket.EscalateDecision(now)
+		if err != nil || !ok {
+			continue
+		}
+		if err := m.repo.Update(ctx, ticket); err != nil {
+			m.logger.Info("cannot escalate decision", "ticket_id", ticket.ID.String(), "error", err)
+			continue
+		}
+		m.cache.Set(ticket)
+
+		m.logger.Info("decision escalated to a manager", "ticket_id", ticket.ID.String(), "question", d.Question)
+		announceDecision(ctx, m.publisher, m.stream, m.logger, decisionEvent(event.EventKitchenDecisionEscalated, ticket, d, now))
+		escalated = append(escalated, ticket)
+	}
+	return escalated
+}
@@ -0,0 +1,353 @@
+package kitchen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/event"
+	proto "github.com/appetiteclub/appetite/services/kitchen/internal/kitchen/proto"
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func troutDecision() Decision {
+	return Decision{
+		Question: "Out of salmon, what now?",
+		Options: []event.KitchenDecisionOption{
+			{Outcome: event.DecisionOutcomeSubstitute, MenuItemID: "550e8400-e29b-41d4-a716-446655440739", MenuItemName: "Trout"},
+			{Outcome: event.DecisionOutcomeCancel},
+			{Outcome: event.DecisionOutcomeProceed, Label: "Smaller portion", Note: "Half portion of salmon"},
+		},
+		RaisedBy: "grill",
+	}
+}
+
+func TestTicketRaiseDecision(t *testing.T) {
+	now := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+
+	ticket := &Ticket{ID: uuid.New(), Status: "created", MenuItemName: "Salmon"}
+	d, err := ticket.RaiseDecision(troutDecision(), now, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("RaiseDecision() error = %v", err)
+	}
+	if !ticket.DecisionRequired {
+		t.Error("ticket should wait for the decision")
+	}
+	if !d.ExpiresAt.Equal(now.Add(5 * time.Minute)) {
+		t.Errorf("ExpiresAt = %v, want %v", d.ExpiresAt, now.Add(5*time.Minute))
+	}
+	if d.Options[0].ID != "1" || d.Options[2].ID != "3" {
+		t.Errorf("option IDs = %q, %q, want numbered options", d.Options[0].ID, d.Options[2].ID)
+	}
+	if d.Options[0].Label != "Substitute with Trout" || d.Options[1].Label != "Cancel the item" {
+		t.Errorf("labels = %q, %q, want default labels", d.Options[0].Label, d.Options[1].Label)
+	}
+
+	stored, err := ticket.Decision()
+	if err != nil || stored == nil || stored.Question != d.Question {
+		t.Errorf("Decision() = %+v, %v, want the raised decision", stored, err)
+	}
+
+	if _, err := ticket.RaiseDecision(troutDecision(), now, time.Minute); !errors.Is(err, ErrDecisionPending) {
+		t.Errorf("second RaiseDecision() error = %v, want ErrDecisionPending", err)
+	}
+	if err := ticket.Transition("accepted", now); err == nil {
+		t.Error("ticket waiting for a decision should not be accepted")
+	}
+}
+
+func TestTicketRaiseDecisionInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		change func(d *Decision)
+	}{
+		{name: "noQuestion", status: "created", change: func(d *Decision) { d.Question = " " }},
+		{name: "noOptions", status: "created", change: func(d *Decision) { d.Options = nil }},
+		{name: "unknownOutcome", status: "created", change: func(d *Decision) { d.Options[1].Outcome = "refund" }},
+		{name: "substituteWithoutItem", status: "created", change: func(d *Decision) { d.Options[0].MenuItemID = "" }},
+		{name: "duplicateID", status: "created", change: func(d *Decision) { d.Options[0].ID, d.Options[1].ID = "a", "a" }},
+		{name: "finalTicket", status: "delivered", change: func(d *Decision) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := troutDecision()
+			tt.change(&d)
+			ticket := &Ticket{ID: uuid.New(), Status: tt.status}
+			if _, err := ticket.RaiseDecision(d, time.Now(), time.Minute); !errors.Is(err, ErrInvalidDecision) {
+				t.Errorf("RaiseDecision() error = %v, want ErrInvalidDecision", err)
+			}
+			if ticket.DecisionRequired {
+				t.Error("ticket should not wait for an invalid decision")
+			}
+		})
+	}
+}
+
+func TestTicketResolveDecision(t *testing.T) {
+	now := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+	salmonID := uuid.New()
+
+	tests := []struct {
+		name       string
+		optionID   string
+		wantStatus string
+		wantItem   string
+		wantNotes  string
+	}{
+		{name: "substitute", optionID: "1", wantStatus: "created", wantItem: "Trout", wantNotes: "No ice; Instead of Salmon"},
+		{name: "cancel", optionID: "2", wantStatus: "cancelled", wantItem: "Salmon", wantNotes: "No ice"},
+		{name: "proceed", optionID: "3", wantStatus: "created", wantItem: "Salmon", wantNotes: "No ice; Half portion of salmon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := &Ticket{ID: uuid.New(), Status: "created", MenuItemID: salmonID, MenuItemName: "Salmon", Notes: "No ice"}
+			if _, err := ticket.RaiseDecision(troutDecision(), now, time.Minute); err != nil {
+				t.Fatalf("RaiseDecision() error = %v", err)
+			}
+
+			d, option, err := ticket.ResolveDecision(tt.optionID, "ana", now.Add(time.Minute))
+			if err != nil {
+				t.Fatalf("ResolveDecision() error = %v", err)
+			}
+			if option.ID != tt.optionID || d.ChosenID != tt.optionID || d.AnsweredBy != "ana" {
+				t.Errorf("resolved %+v with %+v, want option %s answered by ana", d, option, tt.optionID)
+			}
+			if ticket.DecisionRequired {
+				t.Error("ticket should not wait after the answer")
+			}
+			if ticket.Status != tt.wantStatus || ticket.MenuItemName != tt.wantItem || ticket.Notes != tt.wantNotes {
+				t.Errorf("ticket = %s/%s/%q, want %s/%s/%q", ticket.Status, ticket.MenuItemName, ticket.Notes, tt.wantStatus, tt.wantItem, tt.wantNotes)
+			}
+		})
+	}
+
+	ticket := &Ticket{ID: uuid.New(), Status: "created"}
+	if _, _, err := ticket.ResolveDecision("1", "ana", now); !errors.Is(err, ErrNoPendingDecision) {
+		t.Errorf("ResolveDecision() without question error = %v, want ErrNoPendingDecision", err)
+	}
+	if _, err := ticket.RaiseDecision(troutDecision(), now, time.Minute); err != nil {
+		t.Fatalf("RaiseDecision() error = %v", err)
+	}
+	if _, _, err := ticket.ResolveDecision("9", "ana", now); !errors.Is(err, ErrInvalidDecision) {
+		t.Errorf("ResolveDecision() with unknown option error = %v, want ErrInvalidDecision", err)
+	}
+	if !ticket.DecisionRequired {
+		t.Error("a rejected answer should leave the decision pending")
+	}
+}
+
+func TestDecisionMonitorCheck(t *testing.T) {
+	now := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+
+	waiting := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created", TableNumber: "7"}
+	if _, err := waiting.RaiseDecision(troutDecision(), now, 5*time.Minute); err != nil {
+		t.Fatalf("RaiseDecision() error = %v", err)
+	}
+	fresh := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created"}
+	if _, err := fresh.RaiseDecision(troutDecision(), now.Add(4*time.Minute), 5*time.Minute); err != nil {
+		t.Fatalf("RaiseDecision() error = %v", err)
+	}
+
+	repo := NewMockTicketRepository()
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	for _, ticket := range []*Ticket{waiting, fresh} {
+		copied := *ticket
+		repo.AddTicket(&copied)
+		cache.Set(ticket)
+	}
+
+	stream := NewEventStreamServer(cache, apt.NewNoopLogger())
+	received := make(chan *proto.KitchenTicketEvent, 10)
+	stream.subscribers["test-subscriber"] = received
+
+	publisher := NewMockPublisher()
+	monitor := NewDecisionMonitor(cache, repo, publisher, stream, DecisionConfig{}, apt.NewNoopLogger())
+
+	at := now.Add(6 * time.Minute)
+	escalated := monitor.Check(context.Background(), at)
+	if len(escalated) != 1 || escalated[0].ID != waiting.ID {
+		t.Fatalf("Check() escalated %v, want only the expired decision", escalated)
+	}
+	if d, _ := cache.Get(waiting.ID).Decision(); d.EscalatedAt == nil || !d.EscalatedAt.Equal(at) {
+		t.Errorf("cached decision = %+v, want escalated at %v", d, at)
+	}
+	if stored, _ := repo.FindByID(context.Background(), waiting.ID); stored.ModelVersion != 1 {
+		t.Errorf("stored version = %d, want the escalation persisted", stored.ModelVersion)
+	}
+
+	if len(publisher.PublishedEvents) != 1 {
+		t.Fatalf("published %d events, want 1", len(publisher.PublishedEvents))
+	}
+	var evt event.KitchenTicketDecisionEvent
+	if err := unmarshalEvent(publisher.PublishedEvents[0].Data, &evt); err != nil {
+		t.Fatalf("cannot decode event: %v", err)
+	}
+	if evt.EventType != event.EventKitchenDecisionEscalated || !evt.Escalated || evt.TableNumber != "7" || len(evt.Options) != 3 {
+		t.Errorf("event = %+v, want an escalation for table 7", evt)
+	}
+
+	gotEscalation := false
+	for len(received) > 0 {
+		if got := <-received; got.EventType == event.EventKitchenDecisionEscalated && got.TicketId == waiting.ID.String() {
+			gotEscalation = true
+		}
+	}
+	if !gotEscalation {
+		t.Error("escalation was not broadcast to stream subscribers")
+	}
+
+	// Escalated decisions are announced once
+	if again := monitor.Check(context.Background(), at.Add(time.Minute)); len(again) != 0 {
+		t.Errorf("second Check() escalated %d tickets, want 0", len(again))
+	}
+}
+
+func TestTicketStateCacheReplaysDecisions(t *testing.T) {
+	now := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+	ticket := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created", MenuItemName: "Salmon"}
+
+	raised := *ticket
+	d, err := raised.RaiseDecision(troutDecision(), now, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("RaiseDecision() error = %v", err)
+	}
+
+	created, _ := json.Marshal(event.KitchenTicketCreatedEvent{
+		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
+			EventType: event.EventKitchenTicketCreated,
+			TicketID:  ticket.ID.String(),
+			OrderID:   ticket.OrderID.String(),
+			Station:   "kitchen",
+		},
+		Status: "created",
+	})
+	requested, _ := json.Marshal(decisionEvent(event.EventKitchenDecisionRequested, &raised, d, now))
+
+	consumer := NewMockStreamConsumer()
+	consumer.AddMessage(created)
+	consumer.AddMessage(requested)
+	cache := NewTicketStateCache(consumer, nil, apt.NewNoopLogger())
+	if err := cache.Warm(context.Background()); err != nil {
+		t.Fatalf("Warm() error = %v", err)
+	}
+
+	cached := cache.Get(ticket.ID)
+	if cached == nil || !cached.DecisionRequired {
+		t.Fatalf("cached ticket = %+v, want it waiting for a decision", cached)
+	}
+	if got, _ := cached.Decision(); got == nil || got.Question != d.Question || !got.RaisedAt.Equal(now) || len(got.Options) != 3 {
+		t.Errorf("cached decision = %+v, want the raised question", got)
+	}
+
+	answered := raised
+	answered.DecisionPayload = append([]byte(nil), raised.DecisionPayload...)
+	resolvedDecision, _, err := answered.ResolveDecision("1", "ana", now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("ResolveDecision() error = %v", err)
+	}
+	resolved, _ := json.Marshal(decisionEvent(event.EventKitchenDecisionResolved, &answered, resolvedDecision, now.Add(time.Minute)))
+	cache.mu.Lock()
+	cache.applyEventLocked(context.Background(), resolved)
+	cache.mu.Unlock()
+
+	cached = cache.Get(ticket.ID)
+	if cached.DecisionRequired || cached.MenuItemName != "Trout" {
+		t.Errorf("cached ticket = %v/%s, want the substitute applied", cached.DecisionRequired, cached.MenuItemName)
+	}
+}
+
+func TestHandlerDecisionFlow(t *testing.T) {
+	ticket := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "grill", Status: "created", MenuItemName: "Salmon"}
+
+	repo := NewMockTicketRepository()
+	copied := *ticket
+	repo.AddTicket(&copied)
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	cache.Set(ticket)
+	publisher := NewMockPublisher()
+
+	h := NewHandler(HandlerDeps{Repo: repo, Cache: cache, Publisher: publisher, DecisionTimeout: time.Minute}, apt.NewConfig(), apt.NewNoopLogger())
+	r := chi.NewRouter()
+	h.RegisterRoutes(r)
+
+	d := troutDecision()
+	body, _ := json.Marshal(map[string]interface{}{"question": d.Question, "options": d.Options, "raised_by": d.RaisedBy})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tickets/"+ticket.ID.String()+"/decision", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("RaiseDecision() status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tickets/"+ticket.ID.String()+"/decision", bytes.NewReader(body)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("second RaiseDecision() status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/decisions?order_id="+ticket.OrderID.String(), nil))
+	var listed struct {
+		Data struct {
+			Decisions []PendingDecision `json:"decisions"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("cannot decode response: %v", err)
+	}
+	if len(listed.Data.Decisions) != 1 || listed.Data.Decisions[0].Decision.Question != d.Question {
+		t.Fatalf("decisions = %+v, want the raised question", listed.Data.Decisions)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tickets/"+ticket.ID.String()+"/decision/answer", bytes.NewReader([]byte(`{"option_id":"2","answered_by":"ana"}`))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("AnswerDecision() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if stored, _ := repo.FindByID(context.Background(), ticket.ID); stored.Status != "cancelled" || stored.DecisionRequired {
+		t.Errorf("stored ticket = %s (waiting %v), want cancelled", stored.Status, stored.DecisionRequired)
+	}
+
+	var types []string
+	for _, published := range publisher.PublishedEvents {
+		var base struct {
+			EventType string `json:"event_type"`
+		}
+		unmarshalEvent(published.Data, &base)
+		types = append(types, base.EventType)
+	}
+	want := []string{event.EventKitchenDecisionRequested, event.EventKitchenDecisionResolved, event.EventKitchenTicketStatusChange}
+	if len(types) != len(want) {
+		t.Fatalf("published %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("published[%d] = %s, want %s", i, types[i], want[i])
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tickets/"+ticket.ID.String()+"/decision/answer", bytes.NewReader([]byte(`{"option_id":"2"}`))))
+	if w.Code != http.StatusConflict {
+		t.Errorf("answering twice status = %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func TestLoadDecisionConfigDefaults(t *testing.T) {
+	cfg, err := LoadDecisionConfig(apt.NewConfig())
+	if err != nil {
+		t.Fatalf("LoadDecisionConfig() error = %v", err)
+	}
+	if cfg.Timeout != DefaultDecisionTimeout || cfg.Interval != DefaultDecisionInterval {
+		t.Errorf("LoadDecisionConfig() = %v/%v, want the defaults", cfg.Timeout, cfg.Interval)
+	}
+}
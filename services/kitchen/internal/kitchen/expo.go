@@ -0,0 +1,124 @@
+package kitchen
+
+import (
+	"sort"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
+)
+
+// ExpoStation is how far one station is with a course of an order.
+type ExpoStation struct {
+	Station     string `json:"station"`
+	StationName string `json:"station_name"`
+	Ready       int    `json:"ready"`
+	Total       int    `json:"total"`
+}
+
+// ExpoCourse gathers the tickets of one course of an order across stations.
+// A course lags when some of its tickets are ready and others are not, so
+// the plates on the pass are getting cold.
+type ExpoCourse struct {
+	Course   int           `json:"course"`
+	Held     bool          `json:"held"`
+	Ready    int           `json:"ready"`
+	Total    int           `json:"total"`
+	Lagging  bool          `json:"lagging"`
+	Stations []ExpoStation `json:"stations"`
+	Tickets  []*Ticket     `json:"tickets"`
+}
+
+// ExpoOrder is one order as the pass sees it: every open ticket of the order,
+// grouped by course.
+type ExpoOrder struct {
+	OrderID     OrderID      `json:"order_id"`
+	TableNumber string       `json:"table_number"`
+	Ready       int          `json:"ready"`
+	Total       int          `json:"total"`
+	Lagging     bool         `json:"lagging"`
+	FirstAt     time.Time    `json:"first_at"`
+	Courses     []ExpoCourse `json:"courses"`
+}
+
+// onPass reports whether the ticket still belongs on the expo view.
+func onPass(t *Ticket) bool {
+	status := kitchenstatus.ByName(t.Status)
+	return status != nil && !status.IsFinal()
+}
+
+// newExpoOrder groups the open tickets of one order by course and station.
+func newExpoOrder(orderID OrderID, tickets []*Ticket) ExpoOrder {
+	order := ExpoOrder{OrderID: orderID}
+	byCourse := map[int]*ExpoCourse{}
+
+	for _, t := range tickets {
+		if !onPass(t) {
+			continue
+		}
+		if order.TableNumber == "" {
+			order.TableNumber = t.TableNumber
+		}
+		if order.FirstAt.IsZero() || t.CreatedAt.Before(order.FirstAt) {
+			order.FirstAt = t.CreatedAt
+		}
+
+		course := byCourse[t.Course]
+		if course == nil {
+			course = &ExpoCourse{Course: t.Course, Held: true}
+			byCourse[t.Course] = course
+		}
+		course.Tickets = append(course.Tickets, t)
+		course.Total++
+		if t.Status == kitchenstatus.Statuses.Ready.Code() {
+			course.Ready++
+		}
+		if t.Status != kitchenstatus.Statuses.Standby.Code() {
+			course.Held = false
+		}
+	}
+
+	for _, course := range byCourse {
+		course.Stations = expoStations(course.Tickets)
+		course.Lagging = course.Ready > 0 && course.Ready < course.Total
+		sort.Slice(course.Tickets, func(i, j int) bool {
+			return course.Tickets[i].CreatedAt.Before(course.Tickets[j].CreatedAt)
+		})
+
+		order.Ready += course.Ready
+		order.Total += course.Total
+		order.Lagging = order.Lagging || course.Lagging
+		order.Courses = append(order.Courses, *course)
+	}
+	sort.Slice(order.Courses, func(i, j int) bool {
+		return order.Courses[i].Course < order.Courses[j].Course
+	})
+
+	return order
+}
+
+func expoStations(tickets []*Ticket) []ExpoStation {
+	byStation := map[string]*ExpoStation{}
+	for _, t := range tickets {
+		st := byStation[t.Station]
+		if st == nil {
+			st = &ExpoStation{Station: t.Station, StationName: t.StationName}
+			if st.StationName == "" {
+				st.StationName = t.Station
+			}
+			byStation[t.Station] = st
+		}
+		st.Total++
+		if t.Status == kitchenstatus.Statuses.Ready.Code() {
+			st.Ready++
+		}
+	}
+
+	stations := make([]ExpoStation, 0, len(byStation))
+	for _, st := range byStation {
+		stations = append(stations, *st)
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].StationName < stations[j].StationName
+	})
+	return stations
+}
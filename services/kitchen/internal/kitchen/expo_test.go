@@ -0,0 +1,182 @@
+package kitchen
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestTicketStateCacheExpoOrders(t *testing.T) {
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	now := time.Date(2024, 5, 10, 20, 30, 0, 0, time.UTC)
+	early := uuid.New()
+	late := uuid.New()
+	done := uuid.New()
+
+	add := func(orderID uuid.UUID, station, status string, course int, createdAt time.Time) {
+		cache.Set(&Ticket{
+			ID:          uuid.New(),
+			OrderID:     orderID,
+			Station:     station,
+			Status:      status,
+			Course:      course,
+			TableNumber: "7",
+			CreatedAt:   createdAt,
+		})
+	}
+
+	// Mains of the early order: the grill is done, the kitchen is not
+	add(early, "kitchen", "started", 2, now)
+	add(early, "bar", "ready", 2, now.Add(time.Minute))
+	add(early, "kitchen", "standby", 3, now.Add(2*time.Minute))
+	// Everything of the late order is ready together
+	add(late, "kitchen", "ready", 0, now.Add(5*time.Minute))
+	add(late, "bar", "ready", 0, now.Add(6*time.Minute))
+	// Finished orders leave the pass
+	add(done, "kitchen", "delivered", 0, now.Add(-time.Hour))
+
+	orders := cache.ExpoOrders()
+	if len(orders) != 2 {
+		t.Fatalf("ExpoOrders() returned %d orders, want 2", len(orders))
+	}
+	if orders[0].OrderID != early || orders[1].OrderID != late {
+		t.Fatalf("ExpoOrders() order = %v, %v, want oldest first", orders[0].OrderID, orders[1].OrderID)
+	}
+
+	first := orders[0]
+	if !first.Lagging || first.Ready != 1 || first.Total != 3 || first.TableNumber != "7" {
+		t.Errorf("early order = lagging %v, %d/%d ready, table %q", first.Lagging, first.Ready, first.Total, first.TableNumber)
+	}
+	if len(first.Courses) != 2 || first.Courses[0].Course != 2 || first.Courses[1].Course != 3 {
+		t.Fatalf("early order courses = %+v, want courses 2 and 3", first.Courses)
+	}
+	mains := first.Courses[0]
+	if !mains.Lagging || mains.Held || len(mains.Stations) != 2 {
+		t.Errorf("mains = lagging %v, held %v, %d stations", mains.Lagging, mains.Held, len(mains.Stations))
+	}
+	if st := mains.Stations[0]; st.Station != "bar" || st.Ready != 1 || st.Total != 1 {
+		t.Errorf("bar readiness = %+v, want 1/1", st)
+	}
+	if !first.Courses[1].Held {
+		t.Error("dessert course in standby should be held")
+	}
+
+	second := orders[1]
+	if second.Lagging || second.Ready != 2 || second.Total != 2 {
+		t.Errorf("late order = lagging %v, %d/%d ready, want complete", second.Lagging, second.Ready, second.Total)
+	}
+}
+
+func TestTicketStateCacheGetByOrderFollowsMoves(t *testing.T) {
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	orderID := uuid.New()
+	ticket := &Ticket{ID: uuid.New(), OrderID: orderID, Station: "kitchen", Status: "created"}
+
+	cache.Set(ticket)
+	cache.Set(&Ticket{ID: ticket.ID, OrderID: orderID, Station: "kitchen", Status: "ready"})
+	if got := cache.GetByOrder(orderID); len(got) != 1 || got[0].Status != "ready" {
+		t.Fatalf("GetByOrder() = %v, want the ready ticket once", got)
+	}
+
+	cache.Remove(ticket.ID)
+	if got := cache.GetByOrder(orderID); len(got) != 0 {
+		t.Errorf("GetByOrder() after Remove = %d tickets, want 0", len(got))
+	}
+}
+
+func TestHandlerBumpOrder(t *testing.T) {
+	orderID := uuid.New()
+	ready := &Ticket{ID: uuid.New(), OrderID: orderID, Station: "kitchen", Status: "ready", ModelVersion: 2}
+	cooking := &Ticket{ID: uuid.New(), OrderID: orderID, Station: "bar", Status: "started"}
+	stale := &Ticket{ID: uuid.New(), OrderID: orderID, Station: "bar", Status: "ready"}
+	other := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "ready"}
+
+	repo := NewMockTicketRepository()
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	for _, ticket := range []*Ticket{ready, cooking, other} {
+		copied := *ticket
+		repo.AddTicket(&copied)
+		cache.Set(ticket)
+	}
+	// The cache still shows this one ready, but the line took it back
+	repo.AddTicket(&Ticket{ID: stale.ID, OrderID: orderID, Station: "bar", Status: "started"})
+	cache.Set(stale)
+
+	publisher := NewMockPublisher()
+	h := NewHandler(HandlerDeps{Repo: repo, Cache: cache, Publisher: publisher}, apt.NewConfig(), apt.NewNoopLogger())
+
+	r := chi.NewRouter()
+	r.Post("/expo/orders/{orderID}/bump", h.BumpOrder)
+
+	req := httptest.NewRequest(http.MethodPost, "/expo/orders/"+orderID.String()+"/bump", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("BumpOrder() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp struct {
+		Data struct {
+			Delivered []Ticket    `json:"delivered"`
+			Failed    []uuid.UUID `json:"failed"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("cannot decode response: %v", err)
+	}
+	if len(resp.Data.Delivered) != 1 || resp.Data.Delivered[0].ID != ready.ID {
+		t.Errorf("delivered = %v, want only the ready ticket", resp.Data.Delivered)
+	}
+	if len(resp.Data.Failed) != 1 || resp.Data.Failed[0] != stale.ID {
+		t.Errorf("failed = %v, want the stale ticket", resp.Data.Failed)
+	}
+
+	if got, _ := repo.FindByID(req.Context(), ready.ID); got.Status != "delivered" || got.DeliveredAt == nil {
+		t.Errorf("stored ticket status = %q, want delivered", got.Status)
+	}
+	if got, _ := repo.FindByID(req.Context(), other.ID); got.Status != "ready" {
+		t.Errorf("ticket of another order status = %q, want ready", got.Status)
+	}
+	if cache.Get(ready.ID).Status != "delivered" {
+		t.Error("cache should hold the delivered ticket")
+	}
+	if len(publisher.PublishedEvents) != 1 {
+		t.Errorf("published %d events, want 1", len(publisher.PublishedEvents))
+	}
+}
+
+func TestHandlerExpoWithoutCache(t *testing.T) {
+	h := NewHandler(HandlerDeps{Repo: NewMockTicketRepository()}, apt.NewConfig(), apt.NewNoopLogger())
+
+	r := chi.NewRouter()
+	r.Get("/expo", h.ListExpoOrders)
+	r.Post("/expo/orders/{orderID}/bump", h.BumpOrder)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "list", method: http.MethodGet, path: "/expo", want: http.StatusServiceUnavailable},
+		{name: "bump", method: http.MethodPost, path: "/expo/orders/" + uuid.NewString() + "/bump", want: http.StatusServiceUnavailable},
+		{name: "invalidOrderID", method: http.MethodPost, path: "/expo/orders/not-a-uuid/bump", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -4,22 +4,29 @@ import (
 	"sync"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/enums/station"
 	"github.com/appetiteclub/appetite/pkg/event"
 	proto "github.com/appetiteclub/appetite/services/kitchen/internal/kitchen/proto"
 	"github.com/appetiteclub/apt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // EventStreamServer implements the gRPC EventStream service
 type EventStreamServer struct {
 	proto.UnimplementedEventStreamServer
-	cache  *TicketStateCache
-	logger apt.Logger
+	cache    *TicketStateCache
+	stations *station.Registry
+	logger   apt.Logger
 
-	// Manage active stream subscribers
+	// Manage active stream subscribers and the recent events they can
+	// resume from
 	mu          sync.RWMutex
 	subscribers map[string]chan *proto.KitchenTicketEvent
+	replay      *pkg.ReplayBuffer[*proto.KitchenTicketEvent]
 }
 
 // RegisterGRPCService registers this service with the gRPC server (apt.GRPCServiceRegistrar interface)
@@ -33,21 +40,45 @@ func NewEventStreamServer(cache *TicketStateCache, logger apt.Logger) *EventStre
 		cache:       cache,
 		logger:      logger,
 		subscribers: make(map[string]chan *proto.KitchenTicketEvent),
+		replay:      pkg.NewReplayBuffer[*proto.KitchenTicketEvent](pkg.DefaultReplaySize),
 	}
 }
 
+// SetStations sets the station registry the station filter is checked
+// against.
+func (s *EventStreamServer) SetStations(stations *station.Registry) {
+	s.stations = stations
+}
+
 // StreamKitchenEvents implements the gRPC streaming endpoint
 func (s *EventStreamServer) StreamKitchenEvents(req *proto.SubscribeKitchenEventsRequest, stream proto.EventStream_StreamKitchenEventsServer) error {
 	ctx := stream.Context()
 	subscriberID := generateSubscriberID()
 
-	s.logger.Info("new kitchen events subscriber", "subscriber_id", subscriberID, "station_filter", req.StationId)
+	stationID := req.StationId
+	if stationID != "" && s.stations != nil {
+		st, ok := s.stations.Get(stationID)
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "unknown station %q", stationID)
+		}
+		stationID = st.Name
+	}
+
+	s.logger.Info("new kitchen events subscriber", "subscriber_id", subscriberID, "station_filter", stationID, "after_sequence", req.AfterSequence)
 
 	// Create channel for this subscriber
 	eventChan := make(chan *proto.KitchenTicketEvent, 100)
 
+	// Register and read what the subscriber missed under one lock, so no
+	// event falls between the replay and the live feed
+	var missed []*proto.KitchenTicketEvent
+	resumed := false
 	s.mu.Lock()
 	s.subscribers[subscriberID] = eventChan
+	if req.AfterSequence > 0 {
+		missed, resumed = s.replay.Since(req.Instance, req.AfterSequence)
+	}
+	current := s.replay.Last()
 	s.mu.Unlock()
 
 	// Cleanup on disconnect
@@ -59,28 +90,70 @@ func (s *EventStreamServer) StreamKitchenEvents(req *proto.SubscribeKitchenEvent
 		s.logger.Info("kitchen events subscriber disconnected", "subscriber_id", subscriberID)
 	}()
 
-	// Send initial state (all current tickets)
-	initialTickets := s.cache.GetAll()
-	for _, ticket := range initialTickets {
+	if resumed {
+		for _, evt := range missed {
+			if stationID != "" && evt.StationId != stationID {
+				continue
+			}
+			if err := stream.Send(evt); err != nil {
+				s.logger.Errorf("failed to replay event: %v", err)
+				return err
+			}
+		}
+	} else if err := s.sendCurrentTickets(stream, stationID, current); err != nil {
+		return err
+	}
+
+	// Stream real-time updates
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case evt := <-eventChan:
+			// Apply station filter
+			if stationID != "" && evt.StationId != stationID {
+				continue
+			}
+
+			if err := stream.Send(evt); err != nil {
+				s.logger.Errorf("failed to send event: %v", err)
+				return err
+			}
+		}
+	}
+}
+
+// sendCurrentTickets sends every ticket in the cache as a created event. They
+// carry the latest sequence so far, so a subscriber resuming from any of them
+// gets what happened after the snapshot.
+func (s *EventStreamServer) sendCurrentTickets(stream proto.EventStream_StreamKitchenEventsServer, stationID string, sequence uint64) error {
+	for _, ticket := range s.cache.GetAll() {
 		// Apply station filter if provided
-		if req.StationId != "" && ticket.Station != req.StationId {
+		if stationID != "" && ticket.Station != stationID {
 			continue
 		}
 
 		evt := &proto.KitchenTicketEvent{
-			EventType:      "kitchen.ticket.created",
-			OccurredAt:     timestamppb.New(ticket.CreatedAt),
-			TicketId:       ticket.ID.String(),
-			OrderId:        ticket.OrderID.String(),
-			OrderItemId:    ticket.OrderItemID.String(),
-			MenuItemId:     ticket.MenuItemID.String(),
-			StationId:      ticket.Station,
-			MenuItemName:   ticket.MenuItemName,
-			StationName:    ticket.StationName,
-			TableNumber:    ticket.TableNumber,
-			NewStatusId:    ticket.Status,
-			Quantity:       int32(ticket.Quantity),
-			Notes:          ticket.Notes,
+			EventType:       "kitchen.ticket.created",
+			OccurredAt:      timestamppb.New(ticket.CreatedAt),
+			TicketId:        ticket.ID.String(),
+			OrderId:         ticket.OrderID.String(),
+			OrderItemId:     ticket.OrderItemID.String(),
+			MenuItemId:      ticket.MenuItemID.String(),
+			StationId:       ticket.Station,
+			MenuItemName:    ticket.MenuItemName,
+			StationName:     ticket.StationName,
+			TableNumber:     ticket.TableNumber,
+			NewStatusId:     ticket.Status,
+			Quantity:        int32(ticket.Quantity),
+			Notes:           ticket.Notes,
+			Modifiers:       ticket.Modifiers,
+			PortionName:     ticket.PortionName,
+			PrepTimeMinutes: int32(ticket.PrepTime),
+			Course:          int32(ticket.Course),
+			ModelVersion:    int32(ticket.ModelVersion),
+			Sequence:        sequence,
+			Instance:        s.replay.Epoch(),
 		}
 
 		if ticket.StartedAt != nil {
@@ -98,24 +171,7 @@ func (s *EventStreamServer) StreamKitchenEvents(req *proto.SubscribeKitchenEvent
 			return err
 		}
 	}
-
-	// Stream real-time updates
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case evt := <-eventChan:
-			// Apply station filter
-			if req.StationId != "" && evt.StationId != req.StationId {
-				continue
-			}
-
-			if err := stream.Send(evt); err != nil {
-				s.logger.Errorf("failed to send event: %v", err)
-				return err
-			}
-		}
-	}
+	return nil
 }
 
 // BroadcastTicketEvent sends an event to all connected subscribers
@@ -135,6 +191,11 @@ func (s *EventStreamServer) BroadcastTicketEvent(evt *event.KitchenTicketStatusC
 		NewStatusId:      evt.NewStatus,
 		PreviousStatusId: evt.PreviousStatus,
 		Notes:            evt.Notes,
+		Modifiers:        evt.Modifiers,
+		PortionName:      evt.PortionName,
+		PrepTimeMinutes:  int32(evt.PrepTime),
+		Course:           int32(evt.Course),
+		ModelVersion:     int32(evt.ModelVersion),
 	}
 
 	if evt.StartedAt != nil {
@@ -147,8 +208,65 @@ func (s *EventStreamServer) BroadcastTicketEvent(evt *event.KitchenTicketStatusC
 		protoEvt.DeliveredAt = timestamppb.New(*evt.DeliveredAt)
 	}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.broadcast(protoEvt)
+}
+
+// BroadcastLateTicket tells connected subscribers that a ticket went past
+// its expected ready time, so boards can flag it.
+func (s *EventStreamServer) BroadcastLateTicket(evt *event.KitchenTicketLateEvent) {
+	s.broadcast(&proto.KitchenTicketEvent{
+		EventType:       evt.EventType,
+		OccurredAt:      timestamppb.New(evt.OccurredAt),
+		TicketId:        evt.TicketID,
+		OrderId:         evt.OrderID,
+		OrderItemId:     evt.OrderItemID,
+		MenuItemId:      evt.MenuItemID,
+		StationId:       evt.Station,
+		MenuItemName:    evt.MenuItemName,
+		StationName:     evt.StationName,
+		TableNumber:     evt.TableNumber,
+		NewStatusId:     evt.Status,
+		Quantity:        int32(evt.Quantity),
+		Modifiers:       evt.Modifiers,
+		PortionName:     evt.PortionName,
+		PrepTimeMinutes: int32(evt.PrepTime),
+		Course:          int32(evt.Course),
+		ModelVersion:    int32(evt.ModelVersion),
+		ExpectedReadyAt: timestamppb.New(evt.ExpectedReadyAt),
+		LateSeconds:     int32(evt.LateSeconds),
+	})
+}
+
+// BroadcastDecision tells connected subscribers that the kitchen asked the
+// floor about a ticket, escalated the question or got an answer. The
+// question travels as the event notes.
+func (s *EventStreamServer) BroadcastDecision(evt *event.KitchenTicketDecisionEvent) {
+	s.broadcast(&proto.KitchenTicketEvent{
+		EventType:       evt.EventType,
+		OccurredAt:      timestamppb.New(evt.OccurredAt),
+		TicketId:        evt.TicketID,
+		OrderId:         evt.OrderID,
+		OrderItemId:     evt.OrderItemID,
+		MenuItemId:      evt.MenuItemID,
+		StationId:       evt.Station,
+		MenuItemName:    evt.MenuItemName,
+		StationName:     evt.StationName,
+		TableNumber:     evt.TableNumber,
+		Notes:           evt.Question,
+		Modifiers:       evt.Modifiers,
+		PortionName:     evt.PortionName,
+		PrepTimeMinutes: int32(evt.PrepTime),
+		Course:          int32(evt.Course),
+		ModelVersion:    int32(evt.ModelVersion),
+	})
+}
+
+func (s *EventStreamServer) broadcast(protoEvt *proto.KitchenTicketEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	protoEvt.Sequence = s.replay.Append(protoEvt)
+	protoEvt.Instance = s.replay.Epoch()
 
 	for subscriberID, ch := range s.subscribers {
 		select {
@@ -1,13 +1,17 @@
 package kitchen
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg/enums/station"
 	"github.com/appetiteclub/appetite/pkg/event"
 	proto "github.com/appetiteclub/appetite/services/kitchen/internal/kitchen/proto"
 	"github.com/appetiteclub/apt"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewEventStreamServer(t *testing.T) {
@@ -273,3 +277,143 @@ func TestEventStreamServerSubscriberManagement(t *testing.T) {
 		t.Errorf("subscriber count after delete = %d, want 1", count)
 	}
 }
+
+// recordingKitchenStream collects what StreamKitchenEvents sends and ends the
+// stream once it got the expected number of events.
+type recordingKitchenStream struct {
+	proto.EventStream_StreamKitchenEventsServer
+	ctx    context.Context
+	cancel context.CancelFunc
+	want   int
+	sent   []*proto.KitchenTicketEvent
+}
+
+func (s *recordingKitchenStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recordingKitchenStream) Send(evt *proto.KitchenTicketEvent) error {
+	s.sent = append(s.sent, evt)
+	if len(s.sent) >= s.want {
+		s.cancel()
+	}
+	return nil
+}
+
+func newRecordingKitchenStream(want int) *recordingKitchenStream {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	return &recordingKitchenStream{ctx: ctx, cancel: cancel, want: want}
+}
+
+func TestEventStreamServerNumbersEvents(t *testing.T) {
+	server := NewEventStreamServer(NewTicketStateCache(nil, nil, apt.NewNoopLogger()), apt.NewNoopLogger())
+
+	testChan := make(chan *proto.KitchenTicketEvent, 10)
+	server.mu.Lock()
+	server.subscribers["test"] = testChan
+	server.mu.Unlock()
+
+	for _, status := range []string{"started", "ready"} {
+		server.BroadcastTicketEvent(&event.KitchenTicketStatusChangedEvent{
+			KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{EventType: "kitchen.ticket.status_changed", TicketID: uuid.New().String()},
+			NewStatus:                  status,
+		})
+	}
+
+	for want := uint64(1); want <= 2; want++ {
+		if got := (<-testChan).Sequence; got != want {
+			t.Errorf("Sequence = %d, want %d", got, want)
+		}
+	}
+}
+
+func TestEventStreamServerResumesFromSequence(t *testing.T) {
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	cache.Set(&Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created"})
+	server := NewEventStreamServer(cache, apt.NewNoopLogger())
+
+	for _, station := range []string{"kitchen", "bar", "kitchen"} {
+		server.BroadcastTicketEvent(&event.KitchenTicketStatusChangedEvent{
+			KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{EventType: "kitchen.ticket.status_changed", TicketID: uuid.New().String(), Station: station},
+			NewStatus:                  "started",
+		})
+	}
+
+	stream := newRecordingKitchenStream(1)
+	server.StreamKitchenEvents(&proto.SubscribeKitchenEventsRequest{StationId: "kitchen", AfterSequence: 1, Instance: server.replay.Epoch()}, stream)
+
+	if len(stream.sent) != 1 || stream.sent[0].Sequence != 3 {
+		t.Fatalf("resumed stream sent %d events, want only sequence 3", len(stream.sent))
+	}
+	if stream.sent[0].EventType != "kitchen.ticket.status_changed" {
+		t.Errorf("resumed stream sent %q, want replayed status change", stream.sent[0].EventType)
+	}
+}
+
+func TestEventStreamServerSnapshotWhenResumeIsTooOld(t *testing.T) {
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	cache.Set(&Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created"})
+	server := NewEventStreamServer(cache, apt.NewNoopLogger())
+
+	server.BroadcastTicketEvent(&event.KitchenTicketStatusChangedEvent{
+		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{EventType: "kitchen.ticket.status_changed", TicketID: uuid.New().String()},
+		NewStatus:                  "started",
+	})
+
+	// A sequence ahead of the server means it restarted since
+	stream := newRecordingKitchenStream(1)
+	server.StreamKitchenEvents(&proto.SubscribeKitchenEventsRequest{AfterSequence: 40, Instance: server.replay.Epoch()}, stream)
+
+	if len(stream.sent) != 1 {
+		t.Fatalf("stream sent %d events, want the current ticket", len(stream.sent))
+	}
+	if stream.sent[0].EventType != "kitchen.ticket.created" || stream.sent[0].Sequence != 1 {
+		t.Errorf("snapshot event = %q at %d, want kitchen.ticket.created at 1", stream.sent[0].EventType, stream.sent[0].Sequence)
+	}
+}
+
+func TestEventStreamServerSnapshotAfterRestart(t *testing.T) {
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	cache.Set(&Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created"})
+	server := NewEventStreamServer(cache, apt.NewNoopLogger())
+
+	for i := 0; i < 3; i++ {
+		server.BroadcastTicketEvent(&event.KitchenTicketStatusChangedEvent{
+			KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{EventType: "kitchen.ticket.status_changed", TicketID: uuid.New().String()},
+			NewStatus:                  "started",
+		})
+	}
+
+	// The sequence is one this server also gave, but a previous run gave it
+	stream := newRecordingKitchenStream(1)
+	server.StreamKitchenEvents(&proto.SubscribeKitchenEventsRequest{AfterSequence: 1, Instance: "previous"}, stream)
+
+	if len(stream.sent) != 1 {
+		t.Fatalf("stream sent %d events, want the current ticket", len(stream.sent))
+	}
+	if got := stream.sent[0]; got.EventType != "kitchen.ticket.created" || got.Instance != server.replay.Epoch() {
+		t.Errorf("snapshot event = %q in %q, want kitchen.ticket.created in %q", got.EventType, got.Instance, server.replay.Epoch())
+	}
+}
+
+func TestEventStreamServerChecksStationFilter(t *testing.T) {
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	cache.Set(&Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "grill", Status: "created"})
+	cache.Set(&Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "bar", Status: "created"})
+	server := NewEventStreamServer(cache, apt.NewNoopLogger())
+
+	stations := station.NewRegistry(nil, 0, nil)
+	stations.Set([]station.Station{{Name: "grill"}, {Name: "bar"}})
+	server.SetStations(stations)
+
+	err := server.StreamKitchenEvents(&proto.SubscribeKitchenEventsRequest{StationId: "fryer"}, newRecordingKitchenStream(1))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown station error = %v, want InvalidArgument", err)
+	}
+
+	stream := newRecordingKitchenStream(1)
+	server.StreamKitchenEvents(&proto.SubscribeKitchenEventsRequest{StationId: "Grill"}, stream)
+	if len(stream.sent) != 1 || stream.sent[0].StationId != "grill" {
+		t.Errorf("stream sent %d tickets, want the grill ticket", len(stream.sent))
+	}
+}
@@ -3,13 +3,16 @@ package kitchen
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
 	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/outbox"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
 	"github.com/appetiteclub/apt/telemetry"
@@ -19,19 +22,26 @@ import (
 
 const MaxBodyBytes = 1 << 20
 
+// EventSource names the kitchen service on the events it publishes.
+const EventSource = "kitchen-service"
+
 type Handler struct {
-	config    *apt.Config
-	logger    apt.Logger
-	tlm       *telemetry.HTTP
-	repo      TicketRepository
-	cache     *TicketStateCache
-	publisher events.Publisher
+	config          *apt.Config
+	logger          apt.Logger
+	tlm             *telemetry.HTTP
+	repo            TicketRepository
+	cache           *TicketStateCache
+	publisher       events.Publisher
+	stream          *EventStreamServer
+	decisionTimeout time.Duration
 }
 
 type HandlerDeps struct {
-	Repo      TicketRepository
-	Cache     *TicketStateCache
-	Publisher events.Publisher
+	Repo            TicketRepository
+	Cache           *TicketStateCache
+	Publisher       events.Publisher
+	Stream          *EventStreamServer
+	DecisionTimeout time.Duration // How long the floor has to answer before a manager is asked
 }
 
 func NewHandler(hd HandlerDeps, config *apt.Config, logger apt.Logger) *Handler {
@@ -39,12 +49,14 @@ func NewHandler(hd HandlerDeps, config *apt.Config, logger apt.Logger) *Handler
 		logger = apt.NewNoopLogger()
 	}
 	return &Handler{
-		config:    config,
-		logger:    logger,
-		tlm:       telemetry.NewHTTP(),
-		repo:      hd.Repo,
-		cache:     hd.Cache,
-		publisher: hd.Publisher,
+		config:          config,
+		logger:          logger,
+		tlm:             telemetry.NewHTTP(),
+		repo:            hd.Repo,
+		cache:           hd.Cache,
+		publisher:       hd.Publisher,
+		stream:          hd.Stream,
+		decisionTimeout: hd.DecisionTimeout,
 	}
 }
 
@@ -52,6 +64,7 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/tickets", func(r chi.Router) {
 		r.Get("/", h.ListTickets)
 		r.Get("/{id}", h.GetTicket)
+		r.Get("/{id}/transitions", h.ListTicketTransitions)
 		r.Patch("/{id}/status", h.UpdateTicketStatus)
 		r.Patch("/{id}/accept", h.AcceptTicket)
 		r.Patch("/{id}/start", h.StartTicket)
@@ -61,6 +74,19 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Patch("/{id}/block", h.BlockTicket)
 		r.Patch("/{id}/reject", h.RejectTicket)
 		r.Patch("/{id}/cancel", h.CancelTicket)
+		r.Post("/{id}/decision", h.RaiseDecision)
+		r.Post("/{id}/decision/answer", h.AnswerDecision)
+	})
+
+	r.Get("/decisions", h.ListDecisions)
+
+	r.Route("/expo", func(r chi.Router) {
+		r.Get("/", h.ListExpoOrders)
+		r.Post("/orders/{orderID}/bump", h.BumpOrder)
+	})
+
+	r.Route("/analytics", func(r chi.Router) {
+		r.Get("/tickets", h.TicketAnalytics)
 	})
 
 	// Internal endpoints for operations/debugging
@@ -140,6 +166,7 @@ func (h *Handler) ListTickets(w http.ResponseWriter, r *http.Request) {
 		for i := range repoTickets {
 			tickets[i] = &repoTickets[i]
 		}
+		h.copyLateFlags(tickets)
 	}
 
 	apt.Respond(w, http.StatusOK, map[string]interface{}{
@@ -147,6 +174,20 @@ func (h *Handler) ListTickets(w http.ResponseWriter, r *http.Request) {
 	}, nil)
 }
 
+// copyLateFlags carries the late flags, which only live in the cache, over
+// to tickets read from the repository.
+func (h *Handler) copyLateFlags(tickets []*Ticket) {
+	if h.cache == nil {
+		return
+	}
+	for _, ticket := range tickets {
+		cached := h.cache.Get(ticket.ID)
+		if cached != nil && cached.LateAt != nil && sameDeadline(cached, ticket) {
+			ticket.LateAt = cached.LateAt
+		}
+	}
+}
+
 func (h *Handler) GetTicket(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.GetTicket")
 	defer finish()
@@ -167,15 +208,14 @@ func (h *Handler) GetTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apt.Respond(w, http.StatusOK, ticket, nil)
+	respondTicket(w, http.StatusOK, ticket)
 }
 
-func (h *Handler) AcceptTicket(w http.ResponseWriter, r *http.Request) {
-	h.updateStatus(w, r, "accept", kitchenstatus.Statuses.Accepted.Code())
-}
-
-func (h *Handler) StartTicket(w http.ResponseWriter, r *http.Request) {
-	w, r, finish := h.tlm.Start(w, r, "Handler.StartTicket")
+// ListTicketTransitions handles GET /tickets/{id}/transitions
+// It returns the statuses the ticket can move to now, so boards only offer
+// valid moves.
+func (h *Handler) ListTicketTransitions(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListTicketTransitions")
 	defer finish()
 	log := h.log(r)
 	ctx := r.Context()
@@ -194,39 +234,170 @@ func (h *Handler) StartTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	previousStatus := ticket.Status
-	ticket.Status = kitchenstatus.Statuses.Started.Code()
-	now := time.Now()
-	ticket.StartedAt = &now
+	transitions := []map[string]string{}
+	for _, status := range ticket.AvailableTransitions() {
+		transitions = append(transitions, map[string]string{
+			"status": status.Code(),
+			"label":  status.Label(),
+		})
+	}
 
-	if err := h.repo.Update(ctx, ticket); err != nil {
-		log.Errorf("cannot update ticket: %v", err)
-		apt.RespondError(w, http.StatusInternalServerError, "Could not update ticket")
+	apt.Respond(w, http.StatusOK, map[string]interface{}{
+		"ticket_id":   ticket.ID,
+		"status":      ticket.Status,
+		"transitions": transitions,
+	}, nil)
+}
+
+// ListExpoOrders returns the open tickets grouped by order and course, so the
+// pass can see which orders are complete and which are waiting on a station.
+func (h *Handler) ListExpoOrders(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListExpoOrders")
+	defer finish()
+
+	if h.cache == nil {
+		apt.RespondError(w, http.StatusServiceUnavailable, "Ticket cache not available")
 		return
 	}
 
-	// Update cache after successful DB write
-	if h.cache != nil {
-		h.cache.Set(ticket)
+	apt.Respond(w, http.StatusOK, map[string]interface{}{
+		"orders": h.cache.ExpoOrders(),
+	}, nil)
+}
+
+// BumpOrder delivers every ready ticket of an order at once. Tickets that
+// moved on since the pass last looked are reported back instead of failing
+// the whole bump.
+func (h *Handler) BumpOrder(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.BumpOrder")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "orderID"))
+	if err != nil {
+		apt.RespondError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if h.cache == nil {
+		apt.RespondError(w, http.StatusServiceUnavailable, "Ticket cache not available")
+		return
+	}
+
+	delivered := []*Ticket{}
+	failed := []TicketID{}
+	for _, cached := range h.cache.GetByOrder(orderID) {
+		if cached.Status != kitchenstatus.Statuses.Ready.Code() {
+			continue
+		}
+		ticket, err := h.deliverTicket(ctx, cached.ID)
+		if err != nil {
+			log.Infof("cannot bump ticket %s: %v", cached.ID, err)
+			failed = append(failed, cached.ID)
+			continue
+		}
+		delivered = append(delivered, ticket)
+	}
+
+	log.Infof("bumped order %s: %d delivered, %d failed", orderID, len(delivered), len(failed))
+	apt.Respond(w, http.StatusOK, map[string]interface{}{
+		"order_id":  orderID,
+		"delivered": delivered,
+		"failed":    failed,
+	}, nil)
+}
+
+// PendingDecision is a ticket waiting for the floor with the question asked.
+type PendingDecision struct {
+	Ticket   *Ticket   `json:"ticket"`
+	Decision *Decision `json:"decision"`
+}
+
+// ListDecisions handles GET /decisions
+// It returns the tickets waiting for an answer from the floor, oldest
+// question first, optionally for a single order.
+func (h *Handler) ListDecisions(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListDecisions")
+	defer finish()
+	log := h.log(r)
+
+	var orderID *OrderID
+	if value := r.URL.Query().Get("order_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			apt.RespondError(w, http.StatusBadRequest, "Invalid order ID")
+			return
+		}
+		orderID = &id
 	}
 
-	h.publishStatusChange(ctx, ticket, previousStatus)
-	apt.Respond(w, http.StatusOK, ticket, nil)
+	var tickets []*Ticket
+	switch {
+	case h.cache != nil && orderID != nil:
+		tickets = h.cache.GetByOrder(*orderID)
+	case h.cache != nil:
+		tickets = h.cache.GetAll()
+	default:
+		listed, err := h.repo.List(r.Context(), TicketFilter{OrderID: orderID})
+		if err != nil {
+			log.Errorf("cannot list tickets: %v", err)
+			apt.RespondError(w, http.StatusInternalServerError, "Could not list decisions")
+			return
+		}
+		for i := range listed {
+			tickets = append(tickets, &listed[i])
+		}
+	}
+
+	pending := []PendingDecision{}
+	for _, ticket := range tickets {
+		if !ticket.DecisionRequired {
+			continue
+		}
+		d, err := ticket.Decision()
+		if err != nil || d == nil {
+			log.Infof("skipping decision of ticket %s: %v", ticket.ID, err)
+			continue
+		}
+		pending = append(pending, PendingDecision{Ticket: ticket, Decision: d})
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Decision.RaisedAt.Before(pending[j].Decision.RaisedAt)
+	})
+
+	apt.Respond(w, http.StatusOK, map[string]interface{}{
+		"decisions": pending,
+	}, nil)
 }
 
-func (h *Handler) ReadyTicket(w http.ResponseWriter, r *http.Request) {
-	w, r, finish := h.tlm.Start(w, r, "Handler.ReadyTicket")
+// RaiseDecision handles POST /tickets/{id}/decision
+// The kitchen asks the floor what to do with a ticket, offering options to
+// substitute another menu item, cancel the item or proceed with a note. The
+// ticket is held until someone answers.
+func (h *Handler) RaiseDecision(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.RaiseDecision")
 	defer finish()
 	log := h.log(r)
 	ctx := r.Context()
 
-	idStr := chi.URLParam(r, "id")
-	id, err := uuid.Parse(idStr)
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
 		apt.RespondError(w, http.StatusBadRequest, "Invalid ticket ID")
 		return
 	}
 
+	var req struct {
+		Question     string                        `json:"question"`
+		Options      []event.KitchenDecisionOption `json:"options"`
+		RaisedBy     string                        `json:"raised_by"`
+		ModelVersion *int                          `json:"model_version"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, MaxBodyBytes)).Decode(&req); err != nil {
+		apt.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
 	ticket, err := h.repo.FindByID(ctx, id)
 	if err != nil {
 		log.Errorf("cannot find ticket: %v", err)
@@ -234,39 +405,68 @@ func (h *Handler) ReadyTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	previousStatus := ticket.Status
-	ticket.Status = kitchenstatus.Statuses.Ready.Code()
-	now := time.Now()
-	ticket.FinishedAt = &now
+	if !h.checkVersion(w, r, ticket, req.ModelVersion) {
+		return
+	}
 
-	if err := h.repo.Update(ctx, ticket); err != nil {
-		log.Errorf("cannot update ticket: %v", err)
-		apt.RespondError(w, http.StatusInternalServerError, "Could not update ticket")
+	now := time.Now().UTC()
+	d, err := ticket.RaiseDecision(Decision{
+		Question: req.Question,
+		Options:  req.Options,
+		RaisedBy: req.RaisedBy,
+	}, now, h.decisionTimeout)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrDecisionPending) {
+			status = http.StatusConflict
+		}
+		apt.RespondError(w, status, err.Error())
 		return
 	}
 
-	// Update cache after successful DB write
+	if err := h.repo.Update(ctx, ticket); err != nil {
+		h.respondUpdateError(w, r, id, err)
+		return
+	}
 	if h.cache != nil {
 		h.cache.Set(ticket)
 	}
 
-	h.publishStatusChange(ctx, ticket, previousStatus)
-	apt.Respond(w, http.StatusOK, ticket, nil)
+	log.Info("decision raised", "ticket_id", ticket.ID.String(), "question", d.Question)
+	announceDecision(ctx, h.publisher, h.stream, h.logger, decisionEvent(event.EventKitchenDecisionRequested, ticket, d, now))
+	respondTicket(w, http.StatusCreated, ticket)
 }
 
-func (h *Handler) DeliverTicket(w http.ResponseWriter, r *http.Request) {
-	w, r, finish := h.tlm.Start(w, r, "Handler.DeliverTicket")
+// AnswerDecision handles POST /tickets/{id}/decision/answer
+// Accepts {"option_id": "2", "answered_by": "..."} and applies the chosen
+// option to the ticket. Who may answer an escalated decision is up to the
+// caller.
+func (h *Handler) AnswerDecision(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.AnswerDecision")
 	defer finish()
 	log := h.log(r)
 	ctx := r.Context()
 
-	idStr := chi.URLParam(r, "id")
-	id, err := uuid.Parse(idStr)
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
 		apt.RespondError(w, http.StatusBadRequest, "Invalid ticket ID")
 		return
 	}
 
+	var req struct {
+		OptionID     string `json:"option_id"`
+		AnsweredBy   string `json:"answered_by"`
+		ModelVersion *int   `json:"model_version"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, MaxBodyBytes)).Decode(&req); err != nil {
+		apt.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.OptionID == "" {
+		apt.RespondError(w, http.StatusBadRequest, "Option is required")
+		return
+	}
+
 	ticket, err := h.repo.FindByID(ctx, id)
 	if err != nil {
 		log.Errorf("cannot find ticket: %v", err)
@@ -274,24 +474,130 @@ func (h *Handler) DeliverTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkVersion(w, r, ticket, req.ModelVersion) {
+		return
+	}
+
+	now := time.Now().UTC()
 	previousStatus := ticket.Status
-	ticket.Status = kitchenstatus.Statuses.Delivered.Code()
-	now := time.Now()
-	ticket.DeliveredAt = &now
+	d, option, err := ticket.ResolveDecision(req.OptionID, req.AnsweredBy, now)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrNoPendingDecision) {
+			status = http.StatusConflict
+		}
+		apt.RespondError(w, status, err.Error())
+		return
+	}
 
-	if err := h.repo.Update(ctx, ticket); err != nil {
-		log.Errorf("cannot update ticket: %v", err)
-		apt.RespondError(w, http.StatusInternalServerError, "Could not update ticket")
+	// The answer and the status it leads to are saved with the ticket
+	var resolved *event.KitchenTicketDecisionEvent
+	err = outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.repo.Update(ctx, ticket); err != nil {
+			return err
+		}
+		resolved = decisionEvent(event.EventKitchenDecisionResolved, ticket, d, now)
+		if err := publishDecision(ctx, h.publisher, resolved); err != nil {
+			return err
+		}
+		if ticket.Status == previousStatus {
+			return nil
+		}
+		return h.publishStatusChange(ctx, ticket, previousStatus)
+	})
+	if err != nil {
+		h.respondUpdateError(w, r, id, err)
 		return
 	}
+	if h.cache != nil {
+		h.cache.Set(ticket)
+	}
+
+	log.Info("decision answered", "ticket_id", ticket.ID.String(), "outcome", option.Outcome, "answered_by", req.AnsweredBy)
+	if h.stream != nil {
+		h.stream.BroadcastDecision(resolved)
+	}
+	respondTicket(w, http.StatusOK, ticket)
+}
+
+// TicketAnalytics handles GET /analytics/tickets
+// It reports queue, cook and pass times of the tickets created in a date
+// range, grouped by station, item, hour or cook. format=csv returns the
+// report as a CSV file.
+func (h *Handler) TicketAnalytics(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.TicketAnalytics")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	query, err := ParseAnalyticsQuery(r.URL.Query().Get, time.Now())
+	if err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := TicketFilter{CreatedFrom: &query.From, CreatedTo: &query.To}
+	if query.Station != "" {
+		filter.Station = &query.Station
+	}
+
+	tickets, err := h.repo.List(ctx, filter)
+	if err != nil {
+		log.Errorf("cannot list tickets for analytics: %v", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not compute analytics")
+		return
+	}
+
+	report := BuildAnalyticsReport(tickets, query)
+
+	if r.URL.Query().Get("format") == "csv" {
+		filename := fmt.Sprintf("kitchen-%s-%s-%s.csv", query.GroupBy, query.From.Format("20060102"), query.To.Add(-time.Nanosecond).Format("20060102"))
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		if err := report.WriteCSV(w); err != nil {
+			log.Errorf("cannot write analytics csv: %v", err)
+		}
+		return
+	}
+
+	apt.Respond(w, http.StatusOK, report, nil)
+}
+
+// deliverTicket moves a single ticket to delivered from its stored version.
+func (h *Handler) deliverTicket(ctx context.Context, id TicketID) (*Ticket, error) {
+	ticket, err := h.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := ticket.Status
+	if err := ticket.Transition(kitchenstatus.Statuses.Delivered.Code(), time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := h.saveStatusChange(ctx, ticket, previousStatus); err != nil {
+		return nil, err
+	}
 
-	// Update cache after successful DB write
 	if h.cache != nil {
 		h.cache.Set(ticket)
 	}
+	return ticket, nil
+}
 
-	h.publishStatusChange(ctx, ticket, previousStatus)
-	apt.Respond(w, http.StatusOK, ticket, nil)
+func (h *Handler) AcceptTicket(w http.ResponseWriter, r *http.Request) {
+	h.updateStatus(w, r, "accept", kitchenstatus.Statuses.Accepted.Code())
+}
+
+func (h *Handler) StartTicket(w http.ResponseWriter, r *http.Request) {
+	h.updateStatus(w, r, "start", kitchenstatus.Statuses.Started.Code())
+}
+
+func (h *Handler) ReadyTicket(w http.ResponseWriter, r *http.Request) {
+	h.updateStatus(w, r, "ready", kitchenstatus.Statuses.Ready.Code())
+}
+
+func (h *Handler) DeliverTicket(w http.ResponseWriter, r *http.Request) {
+	h.updateStatus(w, r, "deliver", kitchenstatus.Statuses.Delivered.Code())
 }
 
 func (h *Handler) StandbyTicket(w http.ResponseWriter, r *http.Request) {
@@ -337,8 +643,15 @@ func (h *Handler) BlockTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkVersion(w, r, ticket, nil) {
+		return
+	}
+
 	previousStatus := ticket.Status
-	ticket.Status = kitchenstatus.Statuses.Block.Code()
+	if err := ticket.Transition(kitchenstatus.Statuses.Block.Code(), time.Now().UTC()); err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if payload.ReasonCodeID != "" {
 		reasonID, err := uuid.Parse(payload.ReasonCodeID)
@@ -351,9 +664,8 @@ func (h *Handler) BlockTicket(w http.ResponseWriter, r *http.Request) {
 		ticket.Notes = payload.Notes
 	}
 
-	if err := h.repo.Update(ctx, ticket); err != nil {
-		log.Errorf("cannot update ticket: %v", err)
-		apt.RespondError(w, http.StatusInternalServerError, "Could not update ticket")
+	if err := h.saveStatusChange(ctx, ticket, previousStatus); err != nil {
+		h.respondUpdateError(w, r, id, err)
 		return
 	}
 
@@ -362,8 +674,7 @@ func (h *Handler) BlockTicket(w http.ResponseWriter, r *http.Request) {
 		h.cache.Set(ticket)
 	}
 
-	h.publishStatusChange(ctx, ticket, previousStatus)
-	apt.Respond(w, http.StatusOK, ticket, nil)
+	respondTicket(w, http.StatusOK, ticket)
 }
 
 func (h *Handler) RejectTicket(w http.ResponseWriter, r *http.Request) {
@@ -375,7 +686,8 @@ func (h *Handler) CancelTicket(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateTicketStatus handles generic status updates via PATCH /tickets/:id/status
-// Accepts {"status": "status-code"} in request body
+// Accepts {"status": "status-code"} in request body; the move must follow
+// the ticket transition graph
 func (h *Handler) UpdateTicketStatus(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.UpdateTicketStatus")
 	defer finish()
@@ -390,7 +702,9 @@ func (h *Handler) UpdateTicketStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Status string `json:"status"`
+		Status       string `json:"status"`
+		ModelVersion *int   `json:"model_version"`
+		Cook         string `json:"cook"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		apt.RespondError(w, http.StatusBadRequest, "Invalid request body")
@@ -409,43 +723,27 @@ func (h *Handler) UpdateTicketStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Prevent moving tickets that are already delivered or cancelled
-	terminalStatuses := []string{kitchenstatus.Statuses.Delivered.Code(), kitchenstatus.Statuses.Cancelled.Code()}
-	if apt.IsInList(ticket.Status, terminalStatuses) {
-		apt.RespondError(w, http.StatusBadRequest, "Cannot modify delivered or cancelled tickets")
+	// Only waitstaff mark tickets as delivered, from the order
+	if req.Status == kitchenstatus.Statuses.Delivered.Code() {
+		apt.RespondError(w, http.StatusBadRequest, "Cannot mark tickets as delivered from the kitchen. This must be done from the order by waitstaff.")
 		return
 	}
 
-	// Prevent chef from marking tickets as delivered (only waiters can do this from orders)
-	forbiddenFromReady := []string{kitchenstatus.Statuses.Delivered.Code()}
-	if ticket.Status == kitchenstatus.Statuses.Ready.Code() && apt.IsInList(req.Status, forbiddenFromReady) {
-		apt.RespondError(w, http.StatusBadRequest, "Cannot transition from ready to delivered. This must be done from the order by waitstaff.")
+	if !h.checkVersion(w, r, ticket, req.ModelVersion) {
 		return
 	}
 
 	previousStatus := ticket.Status
-	ticket.Status = req.Status
-
-	// Update timestamps based on status
-	now := time.Now().UTC()
-	switch req.Status {
-	case kitchenstatus.Statuses.Started.Code():
-		if ticket.StartedAt == nil {
-			ticket.StartedAt = &now
-		}
-	case kitchenstatus.Statuses.Ready.Code():
-		if ticket.FinishedAt == nil {
-			ticket.FinishedAt = &now
-		}
-	case kitchenstatus.Statuses.Delivered.Code():
-		if ticket.DeliveredAt == nil {
-			ticket.DeliveredAt = &now
-		}
+	if err := ticket.Transition(req.Status, time.Now().UTC()); err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Status == kitchenstatus.Statuses.Started.Code() && req.Cook != "" {
+		ticket.Cook = req.Cook
 	}
 
-	if err := h.repo.Update(ctx, ticket); err != nil {
-		log.Errorf("cannot update ticket: %v", err)
-		apt.RespondError(w, http.StatusInternalServerError, "Could not update ticket")
+	if err := h.saveStatusChange(ctx, ticket, previousStatus); err != nil {
+		h.respondUpdateError(w, r, id, err)
 		return
 	}
 
@@ -454,8 +752,7 @@ func (h *Handler) UpdateTicketStatus(w http.ResponseWriter, r *http.Request) {
 		h.cache.Set(ticket)
 	}
 
-	h.publishStatusChange(ctx, ticket, previousStatus)
-	apt.Respond(w, http.StatusOK, ticket, nil)
+	respondTicket(w, http.StatusOK, ticket)
 }
 
 func (h *Handler) updateStatus(w http.ResponseWriter, r *http.Request, action string, newStatus string) {
@@ -478,12 +775,18 @@ func (h *Handler) updateStatus(w http.ResponseWriter, r *http.Request, action st
 		return
 	}
 
+	if !h.checkVersion(w, r, ticket, nil) {
+		return
+	}
+
 	previousStatus := ticket.Status
-	ticket.Status = newStatus
+	if err := ticket.Transition(newStatus, time.Now().UTC()); err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if err := h.repo.Update(ctx, ticket); err != nil {
-		log.Errorf("cannot update ticket: %v", err)
-		apt.RespondError(w, http.StatusInternalServerError, "Could not update ticket")
+	if err := h.saveStatusChange(ctx, ticket, previousStatus); err != nil {
+		h.respondUpdateError(w, r, id, err)
 		return
 	}
 
@@ -492,13 +795,24 @@ func (h *Handler) updateStatus(w http.ResponseWriter, r *http.Request, action st
 		h.cache.Set(ticket)
 	}
 
-	h.publishStatusChange(ctx, ticket, previousStatus)
-	apt.Respond(w, http.StatusOK, ticket, nil)
+	respondTicket(w, http.StatusOK, ticket)
 }
 
-func (h *Handler) publishStatusChange(ctx context.Context, ticket *Ticket, previousStatus string) {
+// saveStatusChange stores the ticket together with its status_changed event,
+// so a status the kitchen saved always reaches the floor.
+func (h *Handler) saveStatusChange(ctx context.Context, ticket *Ticket, previousStatus string) error {
+	return outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.repo.Update(ctx, ticket); err != nil {
+			return err
+		}
+		return h.publishStatusChange(ctx, ticket, previousStatus)
+	})
+}
+
+func (h *Handler) publishStatusChange(ctx context.Context, ticket *Ticket, previousStatus string) error {
 	eventPayload := event.KitchenTicketStatusChangedEvent{
 		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
+			EventID:      event.NewID(),
 			EventType:    event.EventKitchenTicketStatusChange,
 			OccurredAt:   time.Now().UTC(),
 			TicketID:     ticket.ID.String(),
@@ -509,6 +823,11 @@ func (h *Handler) publishStatusChange(ctx context.Context, ticket *Ticket, previ
 			MenuItemName: ticket.MenuItemName,
 			StationName:  ticket.StationName,
 			TableNumber:  ticket.TableNumber,
+			Modifiers:    ticket.Modifiers,
+			PortionName:  ticket.PortionName,
+			PrepTime:     ticket.PrepTime,
+			Course:       ticket.Course,
+			ModelVersion: ticket.ModelVersion,
 		},
 		NewStatus:      ticket.Status,
 		PreviousStatus: previousStatus,
@@ -521,10 +840,14 @@ func (h *Handler) publishStatusChange(ctx context.Context, ticket *Ticket, previ
 		eventPayload.ReasonCodeID = ticket.ReasonCodeID.String()
 	}
 
-	eventBytes, _ := json.Marshal(eventPayload)
+	eventBytes, err := event.Marshal(ctx, EventSource, eventPayload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal status_changed event: %w", err)
+	}
 	if err := h.publisher.Publish(ctx, event.KitchenTicketsTopic, eventBytes); err != nil {
-		h.logger.Errorf("Failed to publish status_changed event: %v", err)
+		return fmt.Errorf("cannot publish status_changed event: %w", err)
 	}
+	return nil
 }
 
 // ReloadCache reloads the ticket cache from the database
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -838,6 +839,24 @@ func TestHandlerUpdateTicketStatus(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:     "cannotSkipToReady",
+			ticketID: ticketID.String(),
+			body:     map[string]string{"status": kitchenstatus.Statuses.Ready.Code()},
+			setupRepo: func(r *MockTicketRepository) {
+				r.AddTicket(&Ticket{ID: ticketID, Station: "kitchen", Status: kitchenstatus.Statuses.Created.Code()})
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "unknownStatus",
+			ticketID: ticketID.String(),
+			body:     map[string]string{"status": "plated"},
+			setupRepo: func(r *MockTicketRepository) {
+				r.AddTicket(&Ticket{ID: ticketID, Station: "kitchen", Status: kitchenstatus.Statuses.Created.Code()})
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:     "updateError",
 			ticketID: ticketID.String(),
@@ -887,25 +906,128 @@ func TestHandlerUpdateTicketStatus(t *testing.T) {
 	}
 }
 
+func TestHandlerListTicketTransitions(t *testing.T) {
+	ticketID := uuid.New()
+
+	tests := []struct {
+		name           string
+		ticketID       string
+		ticket         *Ticket
+		expectedStatus int
+		want           []string
+	}{
+		{
+			name:           "started",
+			ticketID:       ticketID.String(),
+			ticket:         &Ticket{ID: ticketID, Station: "kitchen", Status: kitchenstatus.Statuses.Started.Code()},
+			expectedStatus: http.StatusOK,
+			want:           []string{"ready", "standby", "block", "cancelled"},
+		},
+		{
+			name:           "pendingDecision",
+			ticketID:       ticketID.String(),
+			ticket:         &Ticket{ID: ticketID, Station: "kitchen", Status: kitchenstatus.Statuses.Block.Code(), DecisionRequired: true},
+			expectedStatus: http.StatusOK,
+			want:           []string{"standby", "reject", "cancelled"},
+		},
+		{
+			name:           "delivered",
+			ticketID:       ticketID.String(),
+			ticket:         &Ticket{ID: ticketID, Station: "kitchen", Status: kitchenstatus.Statuses.Delivered.Code()},
+			expectedStatus: http.StatusOK,
+			want:           []string{},
+		},
+		{
+			name:           "invalidID",
+			ticketID:       "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "notFound",
+			ticketID:       uuid.New().String(),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockTicketRepository()
+			if tt.ticket != nil {
+				repo.AddTicket(tt.ticket)
+			}
+
+			deps := HandlerDeps{Repo: repo, Cache: NewTicketStateCache(nil, nil, apt.NewNoopLogger()), Publisher: NewMockPublisher()}
+			h := NewHandler(deps, apt.NewConfig(), apt.NewNoopLogger())
+
+			r := chi.NewRouter()
+			r.Get("/tickets/{id}/transitions", h.ListTicketTransitions)
+
+			req := httptest.NewRequest(http.MethodGet, "/tickets/"+tt.ticketID+"/transitions", nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("ListTicketTransitions() status = %d, want %d", w.Code, tt.expectedStatus)
+			}
+			if tt.want == nil {
+				return
+			}
+
+			var resp struct {
+				Data struct {
+					Transitions []struct {
+						Status string `json:"status"`
+					} `json:"transitions"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("cannot decode response: %v", err)
+			}
+
+			got := []string{}
+			for _, tr := range resp.Data.Transitions {
+				got = append(got, tr.Status)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("transitions = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("transitions = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
 func TestHandlerUpdateTicketStatusSetsTimestamps(t *testing.T) {
 	tests := []struct {
 		name           string
-		newStatus      string
+		from           string
+		action         string
+		body           map[string]string
 		checkTimestamp string
 	}{
 		{
 			name:           "setsStartedAt",
-			newStatus:      kitchenstatus.Statuses.Started.Code(),
+			from:           kitchenstatus.Statuses.Created.Code(),
+			action:         "status",
+			body:           map[string]string{"status": kitchenstatus.Statuses.Started.Code()},
 			checkTimestamp: "started_at",
 		},
 		{
 			name:           "setsFinishedAt",
-			newStatus:      kitchenstatus.Statuses.Ready.Code(),
+			from:           kitchenstatus.Statuses.Started.Code(),
+			action:         "status",
+			body:           map[string]string{"status": kitchenstatus.Statuses.Ready.Code()},
 			checkTimestamp: "finished_at",
 		},
 		{
 			name:           "setsDeliveredAt",
-			newStatus:      kitchenstatus.Statuses.Delivered.Code(),
+			from:           kitchenstatus.Statuses.Ready.Code(),
+			action:         "deliver",
 			checkTimestamp: "delivered_at",
 		},
 	}
@@ -914,7 +1036,7 @@ func TestHandlerUpdateTicketStatusSetsTimestamps(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ticketID := uuid.New()
 			repo := NewMockTicketRepository()
-			repo.AddTicket(&Ticket{ID: ticketID, Station: "kitchen", Status: "created"})
+			repo.AddTicket(&Ticket{ID: ticketID, Station: "kitchen", Status: tt.from})
 
 			cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
 			publisher := NewMockPublisher()
@@ -924,15 +1046,20 @@ func TestHandlerUpdateTicketStatusSetsTimestamps(t *testing.T) {
 
 			r := chi.NewRouter()
 			r.Patch("/tickets/{id}/status", h.UpdateTicketStatus)
+			r.Patch("/tickets/{id}/deliver", h.DeliverTicket)
 
-			body, _ := json.Marshal(map[string]string{"status": tt.newStatus})
-			req := httptest.NewRequest(http.MethodPatch, "/tickets/"+ticketID.String()+"/status", bytes.NewReader(body))
+			var body io.Reader = http.NoBody
+			if tt.body != nil {
+				raw, _ := json.Marshal(tt.body)
+				body = bytes.NewReader(raw)
+			}
+			req := httptest.NewRequest(http.MethodPatch, "/tickets/"+ticketID.String()+"/"+tt.action, body)
 			w := httptest.NewRecorder()
 
 			r.ServeHTTP(w, req)
 
 			if w.Code != http.StatusOK {
-				t.Errorf("UpdateTicketStatus() status = %d, want %d", w.Code, http.StatusOK)
+				t.Errorf("%s status = %d, want %d", tt.action, w.Code, http.StatusOK)
 			}
 
 			// Verify timestamp was set
@@ -1021,7 +1148,7 @@ func TestHandlerPublishStatusChange(t *testing.T) {
 		OrderItemID: uuid.New(),
 		MenuItemID:  uuid.New(),
 		Station:     "kitchen",
-		Status:      "started",
+		Status:      "accepted",
 	}
 	repo.AddTicket(ticket)
 
@@ -1061,11 +1188,56 @@ func TestHandlerPublishStatusChangeError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPatch, "/tickets/"+ticketID.String()+"/start", nil)
 	w := httptest.NewRecorder()
 
-	// Should still succeed - publish error is logged but not returned
+	// The event is saved with the ticket, a ticket whose event cannot be
+	// stored is not reported as started
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("StartTicket() with publish error should fail, got status %d", w.Code)
+	}
+	if cache.Get(ticketID) != nil {
+		t.Error("StartTicket() with publish error should not cache the ticket")
+	}
+}
+
+func TestHandlerSavesStatusChangeWithItsEvent(t *testing.T) {
+	repo := NewMockTicketRepository()
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	publisher := NewMockTransactionalPublisher()
+
+	ticketID := uuid.New()
+	stored := &Ticket{ID: ticketID, Station: "kitchen", Status: "created"}
+	repo.AddTicket(stored)
+
+	updatedInTransaction := false
+	repo.UpdateFunc = func(ctx context.Context, t *Ticket) error {
+		updatedInTransaction = inMockTransaction(ctx)
+		t.ModelVersion++
+		return nil
+	}
+
+	deps := HandlerDeps{Repo: repo, Cache: cache, Publisher: publisher}
+	h := NewHandler(deps, apt.NewConfig(), apt.NewNoopLogger())
+
+	r := chi.NewRouter()
+	r.Patch("/tickets/{id}/start", h.StartTicket)
+
+	req := httptest.NewRequest(http.MethodPatch, "/tickets/"+ticketID.String()+"/start", nil)
+	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
-		t.Errorf("StartTicket() should succeed even with publish error, got status %d", w.Code)
+		t.Fatalf("StartTicket() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if publisher.Transactions != 1 {
+		t.Errorf("StartTicket() ran %d transactions, want 1", publisher.Transactions)
+	}
+	if !updatedInTransaction {
+		t.Error("StartTicket() should save the ticket in the transaction")
+	}
+	if len(publisher.PublishedEvents) != 1 || publisher.OutsideTransaction != 0 {
+		t.Errorf("StartTicket() published %d events, %d outside the transaction; want 1 inside",
+			len(publisher.PublishedEvents), publisher.OutsideTransaction)
 	}
 }
 
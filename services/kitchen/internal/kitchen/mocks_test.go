@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/appetiteclub/appetite/pkg/event"
 	"github.com/appetiteclub/apt/events"
 	"github.com/google/uuid"
 )
@@ -39,9 +40,14 @@ func (m *MockTicketRepository) Update(ctx context.Context, t *Ticket) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, t)
 	}
-	if _, exists := m.tickets[t.ID]; !exists {
-		return errors.New("ticket not found")
+	stored, exists := m.tickets[t.ID]
+	if !exists {
+		return ErrTicketNotFound
+	}
+	if stored.ModelVersion != t.ModelVersion {
+		return ErrVersionConflict
 	}
+	t.ModelVersion++
 	m.tickets[t.ID] = t
 	return nil
 }
@@ -82,6 +88,12 @@ func (m *MockTicketRepository) List(ctx context.Context, filter TicketFilter) ([
 		if filter.OrderItemID != nil && t.OrderItemID != *filter.OrderItemID {
 			continue
 		}
+		if filter.CreatedFrom != nil && t.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedTo != nil && !t.CreatedAt.Before(*filter.CreatedTo) {
+			continue
+		}
 		result = append(result, *t)
 	}
 	return result, nil
@@ -118,6 +130,36 @@ func (m *MockPublisher) Publish(ctx context.Context, topic string, data []byte)
 	return nil
 }
 
+// MockTransactionalPublisher is a test mock for a publisher that saves a
+// change and its events together, like the outbox
+type MockTransactionalPublisher struct {
+	*MockPublisher
+	Transactions       int
+	OutsideTransaction int
+}
+
+type mockTransactionKey struct{}
+
+func NewMockTransactionalPublisher() *MockTransactionalPublisher {
+	return &MockTransactionalPublisher{MockPublisher: NewMockPublisher()}
+}
+
+func (m *MockTransactionalPublisher) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Transactions++
+	return fn(context.WithValue(ctx, mockTransactionKey{}, true))
+}
+
+func (m *MockTransactionalPublisher) Publish(ctx context.Context, topic string, data []byte) error {
+	if !inMockTransaction(ctx) {
+		m.OutsideTransaction++
+	}
+	return m.MockPublisher.Publish(ctx, topic, data)
+}
+
+func inMockTransaction(ctx context.Context) bool {
+	return ctx.Value(mockTransactionKey{}) != nil
+}
+
 // MockStreamConsumer is a test mock for events.StreamConsumer
 type MockStreamConsumer struct {
 	messages           []events.StreamMessage
@@ -148,3 +190,12 @@ func (m *MockStreamConsumer) SubscribeStream(ctx context.Context, handler events
 func (m *MockStreamConsumer) AddMessage(data []byte) {
 	m.messages = append(m.messages, events.StreamMessage{Data: data})
 }
+
+// unmarshalEvent decodes the payload of a published event envelope into v.
+func unmarshalEvent(msg []byte, v any) error {
+	env, err := event.Open(msg)
+	if err != nil {
+		return err
+	}
+	return env.Unmarshal(v)
+}
@@ -26,7 +26,14 @@ const (
 type SubscribeKitchenEventsRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Optional filter by station_id
-	StationId     string `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	StationId string `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	// Resume after this sequence: the server replays the events sent since
+	// instead of the current tickets. 0, or a sequence the server no longer
+	// holds, starts over with the current tickets.
+	AfterSequence uint64 `protobuf:"varint,2,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	// Epoch the after_sequence was given in, from the events' instance. When it
+	// is not the server's, the server restarted and starts over.
+	Instance      string `protobuf:"bytes,3,opt,name=instance,proto3" json:"instance,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -68,6 +75,20 @@ func (x *SubscribeKitchenEventsRequest) GetStationId() string {
 	return ""
 }
 
+func (x *SubscribeKitchenEventsRequest) GetAfterSequence() uint64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+func (x *SubscribeKitchenEventsRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // Kitchen ticket event streamed to clients
 type KitchenTicketEvent struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -91,9 +112,28 @@ type KitchenTicketEvent struct {
 	Quantity         int32  `protobuf:"varint,13,opt,name=quantity,proto3" json:"quantity,omitempty"`
 	Notes            string `protobuf:"bytes,14,opt,name=notes,proto3" json:"notes,omitempty"`
 	// Timestamps
-	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
-	DeliveredAt   *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	StartedAt   *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt  *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	DeliveredAt *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	// Modifier labels, e.g. "Cooking point: Medium rare"
+	Modifiers []string `protobuf:"bytes,18,rep,name=modifiers,proto3" json:"modifiers,omitempty"`
+	// Portion picked for the item and its expected prep time in minutes
+	PortionName     string `protobuf:"bytes,19,opt,name=portion_name,json=portionName,proto3" json:"portion_name,omitempty"`
+	PrepTimeMinutes int32  `protobuf:"varint,20,opt,name=prep_time_minutes,json=prepTimeMinutes,proto3" json:"prep_time_minutes,omitempty"`
+	// Course the item is served in, 0 when not coursed
+	Course int32 `protobuf:"varint,21,opt,name=course,proto3" json:"course,omitempty"`
+	// Set on "kitchen.ticket.late": when the ticket was expected to be ready
+	// and how many seconds past that it was when flagged
+	ExpectedReadyAt *timestamppb.Timestamp `protobuf:"bytes,22,opt,name=expected_ready_at,json=expectedReadyAt,proto3" json:"expected_ready_at,omitempty"`
+	LateSeconds     int32                  `protobuf:"varint,23,opt,name=late_seconds,json=lateSeconds,proto3" json:"late_seconds,omitempty"`
+	// Position of the event in the stream, increasing by one per event. The
+	// current tickets sent on subscribe carry the latest sequence so far.
+	Sequence uint64 `protobuf:"varint,24,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Version of the ticket once the change was saved, for edits checked
+	// against it. 0 when unknown.
+	ModelVersion int32 `protobuf:"varint,25,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	// Epoch of the stream's sequences, new each time the server starts
+	Instance      string `protobuf:"bytes,26,opt,name=instance,proto3" json:"instance,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -247,6 +287,69 @@ func (x *KitchenTicketEvent) GetDeliveredAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *KitchenTicketEvent) GetModifiers() []string {
+	if x != nil {
+		return x.Modifiers
+	}
+	return nil
+}
+
+func (x *KitchenTicketEvent) GetPortionName() string {
+	if x != nil {
+		return x.PortionName
+	}
+	return ""
+}
+
+func (x *KitchenTicketEvent) GetPrepTimeMinutes() int32 {
+	if x != nil {
+		return x.PrepTimeMinutes
+	}
+	return 0
+}
+
+func (x *KitchenTicketEvent) GetCourse() int32 {
+	if x != nil {
+		return x.Course
+	}
+	return 0
+}
+
+func (x *KitchenTicketEvent) GetExpectedReadyAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpectedReadyAt
+	}
+	return nil
+}
+
+func (x *KitchenTicketEvent) GetLateSeconds() int32 {
+	if x != nil {
+		return x.LateSeconds
+	}
+	return 0
+}
+
+func (x *KitchenTicketEvent) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *KitchenTicketEvent) GetModelVersion() int32 {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return 0
+}
+
+func (x *KitchenTicketEvent) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // Request to subscribe to order events
 type SubscribeOrderEventsRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -435,10 +538,12 @@ var File_events_proto protoreflect.FileDescriptor
 
 const file_events_proto_rawDesc = "" +
 	"\n" +
-	"\fevents.proto\x12\x13appetite.kitchen.v1\x1a\x1fgoogle/protobuf/timestamp.proto\">\n" +
+	"\fevents.proto\x12\x13appetite.kitchen.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x81\x01\n" +
 	"\x1dSubscribeKitchenEventsRequest\x12\x1d\n" +
 	"\n" +
-	"station_id\x18\x01 \x01(\tR\tstationId\"\xb4\x05\n" +
+	"station_id\x18\x01 \x01(\tR\tstationId\x12%\n" +
+	"\x0eafter_sequence\x18\x02 \x01(\x04R\rafterSequence\x12\x1a\n" +
+	"\binstance\x18\x03 \x01(\tR\binstance\"\x81\b\n" +
 	"\x12KitchenTicketEvent\x12\x1d\n" +
 	"\n" +
 	"event_type\x18\x01 \x01(\tR\teventType\x12;\n" +
@@ -463,7 +568,16 @@ const file_events_proto_rawDesc = "" +
 	"started_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
 	"\vfinished_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
 	"finishedAt\x12=\n" +
-	"\fdelivered_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt\"S\n" +
+	"\fdelivered_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt\x12\x1c\n" +
+	"\tmodifiers\x18\x12 \x03(\tR\tmodifiers\x12!\n" +
+	"\fportion_name\x18\x13 \x01(\tR\vportionName\x12*\n" +
+	"\x11prep_time_minutes\x18\x14 \x01(\x05R\x0fprepTimeMinutes\x12\x16\n" +
+	"\x06course\x18\x15 \x01(\x05R\x06course\x12F\n" +
+	"\x11expected_ready_at\x18\x16 \x01(\v2\x1a.google.protobuf.TimestampR\x0fexpectedReadyAt\x12!\n" +
+	"\flate_seconds\x18\x17 \x01(\x05R\vlateSeconds\x12\x1a\n" +
+	"\bsequence\x18\x18 \x01(\x04R\bsequence\x12#\n" +
+	"\rmodel_version\x18\x19 \x01(\x05R\fmodelVersion\x12\x1a\n" +
+	"\binstance\x18\x1a \x01(\tR\binstance\"S\n" +
 	"\x1bSubscribeOrderEventsRequest\x12\x19\n" +
 	"\btable_id\x18\x01 \x01(\tR\atableId\x12\x19\n" +
 	"\border_id\x18\x02 \x01(\tR\aorderId\"\x99\x03\n" +
@@ -512,16 +626,17 @@ var file_events_proto_depIdxs = []int32{
 	4, // 1: appetite.kitchen.v1.KitchenTicketEvent.started_at:type_name -> google.protobuf.Timestamp
 	4, // 2: appetite.kitchen.v1.KitchenTicketEvent.finished_at:type_name -> google.protobuf.Timestamp
 	4, // 3: appetite.kitchen.v1.KitchenTicketEvent.delivered_at:type_name -> google.protobuf.Timestamp
-	4, // 4: appetite.kitchen.v1.OrderItemEvent.occurred_at:type_name -> google.protobuf.Timestamp
-	0, // 5: appetite.kitchen.v1.EventStream.StreamKitchenEvents:input_type -> appetite.kitchen.v1.SubscribeKitchenEventsRequest
-	2, // 6: appetite.kitchen.v1.EventStream.StreamOrderEvents:input_type -> appetite.kitchen.v1.SubscribeOrderEventsRequest
-	1, // 7: appetite.kitchen.v1.EventStream.StreamKitchenEvents:output_type -> appetite.kitchen.v1.KitchenTicketEvent
-	3, // 8: appetite.kitchen.v1.EventStream.StreamOrderEvents:output_type -> appetite.kitchen.v1.OrderItemEvent
-	7, // [7:9] is the sub-list for method output_type
-	5, // [5:7] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	4, // 4: appetite.kitchen.v1.KitchenTicketEvent.expected_ready_at:type_name -> google.protobuf.Timestamp
+	4, // 5: appetite.kitchen.v1.OrderItemEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	0, // 6: appetite.kitchen.v1.EventStream.StreamKitchenEvents:input_type -> appetite.kitchen.v1.SubscribeKitchenEventsRequest
+	2, // 7: appetite.kitchen.v1.EventStream.StreamOrderEvents:input_type -> appetite.kitchen.v1.SubscribeOrderEventsRequest
+	1, // 8: appetite.kitchen.v1.EventStream.StreamKitchenEvents:output_type -> appetite.kitchen.v1.KitchenTicketEvent
+	3, // 9: appetite.kitchen.v1.EventStream.StreamOrderEvents:output_type -> appetite.kitchen.v1.OrderItemEvent
+	8, // [8:10] is the sub-list for method output_type
+	6, // [6:8] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_events_proto_init() }
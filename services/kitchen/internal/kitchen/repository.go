@@ -1,18 +1,35 @@
 package kitchen
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTicketNotFound is returned when no ticket has the requested ID
+	ErrTicketNotFound = errors.New("ticket not found")
+	// ErrVersionConflict is returned by Update when the stored ticket has
+	// moved past the version the caller read
+	ErrVersionConflict = errors.New("ticket was modified concurrently")
+)
 
 type TicketFilter struct {
 	Station     *string
 	Status      *string
 	OrderID     *OrderID
 	OrderItemID *OrderItemID
+	CreatedFrom *time.Time // Inclusive
+	CreatedTo   *time.Time // Exclusive
 	Limit       int
 	Offset      int
 }
 
 type TicketRepository interface {
 	Create(ctx context.Context, t *Ticket) error
+	// Update saves t only if the stored ticket is still at t.ModelVersion,
+	// and bumps t.ModelVersion on success. Otherwise it returns
+	// ErrVersionConflict and leaves t untouched.
 	Update(ctx context.Context, t *Ticket) error
 	FindByID(ctx context.Context, id TicketID) (*Ticket, error)
 	FindByOrderItemID(ctx context.Context, id OrderItemID) (*Ticket, error)
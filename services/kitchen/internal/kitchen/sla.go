@@ -0,0 +1,271 @@
+package kitchen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+)
+
+// Defaults for the SLA monitor when the configuration leaves them out.
+const (
+	DefaultSLAInterval = 30 * time.Second
+	DefaultSLAGrace    = 2 * time.Minute
+)
+
+// SLAConfig says how often tickets are checked against their expected ready
+// time and how much slack a station gets before a ticket counts as late.
+type SLAConfig struct {
+	Interval time.Duration
+	Grace    time.Duration
+	Stations map[string]time.Duration // Grace by station code, overrides Grace
+}
+
+// LoadSLAConfig reads sla.interval, sla.grace and sla.stations. Stations are
+// listed as code=duration pairs, e.g. "bar=1m,grill=5m".
+func LoadSLAConfig(config *apt.Config) (SLAConfig, error) {
+	cfg := SLAConfig{
+		Interval: DefaultSLAInterval,
+		Grace:    DefaultSLAGrace,
+	}
+	if config == nil {
+		return cfg, nil
+	}
+
+	if value, _ := config.GetString("sla.interval"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return cfg, fmt.Errorf("invalid sla.interval %q", value)
+		}
+		cfg.Interval = interval
+	}
+
+	if value, _ := config.GetString("sla.grace"); value != "" {
+		grace, err := time.ParseDuration(value)
+		if err != nil || grace < 0 {
+			return cfg, fmt.Errorf("invalid sla.grace %q", value)
+		}
+		cfg.Grace = grace
+	}
+
+	stations, _ := config.GetString("sla.stations")
+	grace, err := ParseStationGrace(stations)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Stations = grace
+
+	return cfg, nil
+}
+
+// ParseStationGrace reads per-station grace periods from "bar=1m,grill=5m".
+func ParseStationGrace(s string) (map[string]time.Duration, error) {
+	grace := map[string]time.Duration{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		station, value, ok := strings.Cut(pair, "=")
+		station = strings.TrimSpace(station)
+		if !ok || station == "" {
+			return nil, fmt.Errorf("invalid station grace %q: use station=duration", pair)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid grace for station %s: %q", station, value)
+		}
+		grace[station] = d
+	}
+	return grace, nil
+}
+
+// GraceFor returns how long a ticket of station may run past its expected
+// ready time before it is late.
+func (c SLAConfig) GraceFor(station string) time.Duration {
+	if grace, ok := c.Stations[station]; ok {
+		return grace
+	}
+	return c.Grace
+}
+
+// LateBy reports whether the ticket is late at now and how far past its
+// expected ready time it is. Tickets waiting in standby, held courses
+// included, are not timed.
+func (c SLAConfig) LateBy(t *Ticket, now time.Time) (time.Duration, bool) {
+	status := kitchenstatus.ByName(t.Status)
+	if status == nil || status.IsFinal() || t.Status == kitchenstatus.Statuses.Standby.Code() {
+		return 0, false
+	}
+
+	readyAt := t.ExpectedReadyAt()
+	if readyAt == nil {
+		return 0, false
+	}
+
+	over := now.Sub(*readyAt)
+	if over <= c.GraceFor(t.Station) {
+		return 0, false
+	}
+	return over, true
+}
+
+// sameDeadline reports whether both tickets are timed against the same
+// expected ready time.
+func sameDeadline(a, b *Ticket) bool {
+	ra, rb := a.ExpectedReadyAt(), b.ExpectedReadyAt()
+	return ra != nil && rb != nil && ra.Equal(*rb)
+}
+
+// SLAMonitor periodically looks for tickets running late and announces each
+// of them once, on NATS and to the gRPC stream subscribers.
+type SLAMonitor struct {
+	cache     *TicketStateCache
+	publisher events.Publisher
+	stream    *EventStreamServer
+	config    SLAConfig
+	logger    apt.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSLAMonitor creates a monitor over the tickets in cache.
+func NewSLAMonitor(cache *TicketStateCache, publisher events.Publisher, stream *EventStreamServer, config SLAConfig, logger apt.Logger) *SLAMonitor {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultSLAInterval
+	}
+	return &SLAMonitor{
+		cache:     cache,
+		publisher: publisher,
+		stream:    stream,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Start runs the checks in the background until Stop is called.
+func (m *SLAMonitor) Start(ctx context.Context) error {
+	if m.cache == nil {
+		m.logger.Info("ticket cache not configured, SLA monitor disabled")
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(runCtx)
+
+	m.logger.Info("SLA monitor started", "interval", m.config.Interval.String(), "grace", m.config.Grace.String())
+	return nil
+}
+
+// Stop ends the background checks.
+func (m *SLAMonitor) Stop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *SLAMonitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Check(ctx, now.UTC())
+		}
+	}
+}
+
+// Check flags the tickets that are late at now and announces them. Tickets
+// already flagged are left alone until their expected ready time moves. It
+// returns the tickets it flagged.
+func (m *SLAMonitor) Check(ctx context.Context, now time.Time) []*Ticket {
+	var flagged []*Ticket
+	for _, ticket := range m.cache.GetAll() {
+		if ticket.LateAt != nil {
+			continue
+		}
+		lateBy, late := m.config.LateBy(ticket, now)
+		if !late {
+			continue
+		}
+
+		marked := m.cache.MarkLate(ticket.ID, now)
+		if marked == nil {
+			continue
+		}
+		m.announce(ctx, marked, lateBy)
+		flagged = append(flagged, marked)
+	}
+	return flagged
+}
+
+func (m *SLAMonitor) announce(ctx context.Context, ticket *Ticket, lateBy time.Duration) {
+	m.logger.Info("ticket is late", "ticket_id", ticket.ID.String(), "station", ticket.Station, "late_by", lateBy.Round(time.Second).String())
+
+	evt := event.KitchenTicketLateEvent{
+		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
+			EventID:      event.NewID(),
+			EventType:    event.EventKitchenTicketLate,
+			OccurredAt:   *ticket.LateAt,
+			TicketID:     ticket.ID.String(),
+			OrderID:      ticket.OrderID.String(),
+			OrderItemID:  ticket.OrderItemID.String(),
+			MenuItemID:   ticket.MenuItemID.String(),
+			Station:      ticket.Station,
+			MenuItemName: ticket.MenuItemName,
+			StationName:  ticket.StationName,
+			TableNumber:  ticket.TableNumber,
+			Modifiers:    ticket.Modifiers,
+			PortionName:  ticket.PortionName,
+			PrepTime:     ticket.PrepTime,
+			Course:       ticket.Course,
+			ModelVersion: ticket.ModelVersion,
+		},
+		Status:          ticket.Status,
+		Quantity:        ticket.Quantity,
+		ExpectedReadyAt: *ticket.ExpectedReadyAt(),
+		LateSeconds:     int(lateBy / time.Second),
+	}
+
+	if m.stream != nil {
+		m.stream.BroadcastLateTicket(&evt)
+	}
+	if m.publisher == nil {
+		return
+	}
+
+	payload, err := event.Marshal(ctx, EventSource, evt)
+	if err != nil {
+		m.logger.Errorf("Failed to marshal ticket.late event: %v", err)
+		return
+	}
+	if err := m.publisher.Publish(ctx, event.KitchenTicketsTopic, payload); err != nil {
+		m.logger.Errorf("Failed to publish ticket.late event: %v", err)
+	}
+}
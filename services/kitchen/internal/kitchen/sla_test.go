@@ -0,0 +1,262 @@
+package kitchen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/event"
+	proto "github.com/appetiteclub/appetite/services/kitchen/internal/kitchen/proto"
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestParseStationGrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: map[string]time.Duration{}},
+		{name: "pairs", input: "bar=1m, grill = 5m", want: map[string]time.Duration{"bar": time.Minute, "grill": 5 * time.Minute}},
+		{name: "trailingComma", input: "bar=30s,", want: map[string]time.Duration{"bar": 30 * time.Second}},
+		{name: "missingDuration", input: "bar", wantErr: true},
+		{name: "missingStation", input: "=1m", wantErr: true},
+		{name: "badDuration", input: "bar=soon", wantErr: true},
+		{name: "negative", input: "bar=-1m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStationGrace(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStationGrace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseStationGrace() = %v, want %v", got, tt.want)
+			}
+			for station, grace := range tt.want {
+				if got[station] != grace {
+					t.Errorf("grace[%s] = %v, want %v", station, got[station], grace)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadSLAConfigDefaults(t *testing.T) {
+	cfg, err := LoadSLAConfig(apt.NewConfig())
+	if err != nil {
+		t.Fatalf("LoadSLAConfig() error = %v", err)
+	}
+	if cfg.Interval != DefaultSLAInterval || cfg.Grace != DefaultSLAGrace {
+		t.Errorf("LoadSLAConfig() = %v/%v, want the defaults", cfg.Interval, cfg.Grace)
+	}
+}
+
+func TestSLAConfigLateBy(t *testing.T) {
+	created := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+	started := created.Add(5 * time.Minute)
+	finished := started.Add(30 * time.Minute)
+	cfg := SLAConfig{Grace: 2 * time.Minute, Stations: map[string]time.Duration{"bar": 0}}
+
+	tests := []struct {
+		name     string
+		ticket   Ticket
+		now      time.Time
+		wantLate bool
+		wantBy   time.Duration
+	}{
+		{
+			name:   "onTime",
+			ticket: Ticket{Station: "kitchen", Status: "created", CreatedAt: created, PrepTime: 10},
+			now:    created.Add(9 * time.Minute),
+		},
+		{
+			name:   "withinGrace",
+			ticket: Ticket{Station: "kitchen", Status: "created", CreatedAt: created, PrepTime: 10},
+			now:    created.Add(12 * time.Minute),
+		},
+		{
+			name:     "pastGrace",
+			ticket:   Ticket{Station: "kitchen", Status: "accepted", CreatedAt: created, PrepTime: 10},
+			now:      created.Add(13 * time.Minute),
+			wantLate: true,
+			wantBy:   3 * time.Minute,
+		},
+		{
+			name:     "stationGrace",
+			ticket:   Ticket{Station: "bar", Status: "created", CreatedAt: created, PrepTime: 10},
+			now:      created.Add(11 * time.Minute),
+			wantLate: true,
+			wantBy:   time.Minute,
+		},
+		{
+			name:   "countsFromStarted",
+			ticket: Ticket{Station: "kitchen", Status: "started", CreatedAt: created, StartedAt: &started, PrepTime: 10},
+			now:    created.Add(13 * time.Minute),
+		},
+		{
+			name:   "heldInStandby",
+			ticket: Ticket{Station: "kitchen", Status: "standby", CreatedAt: created, PrepTime: 10, Course: 2},
+			now:    created.Add(time.Hour),
+		},
+		{
+			name:   "finished",
+			ticket: Ticket{Station: "kitchen", Status: "ready", CreatedAt: created, StartedAt: &started, FinishedAt: &finished, PrepTime: 10},
+			now:    created.Add(time.Hour),
+		},
+		{
+			name:   "noPrepTime",
+			ticket: Ticket{Station: "kitchen", Status: "created", CreatedAt: created},
+			now:    created.Add(time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			by, late := cfg.LateBy(&tt.ticket, tt.now)
+			if late != tt.wantLate || by != tt.wantBy {
+				t.Errorf("LateBy() = %v, %v, want %v, %v", by, late, tt.wantBy, tt.wantLate)
+			}
+		})
+	}
+}
+
+func TestSLAMonitorCheck(t *testing.T) {
+	created := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+	now := created.Add(20 * time.Minute)
+
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	late := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created", CreatedAt: created, PrepTime: 10, TableNumber: "4"}
+	onTime := &Ticket{ID: uuid.New(), OrderID: uuid.New(), Station: "kitchen", Status: "created", CreatedAt: created, PrepTime: 30}
+	cache.Set(late)
+	cache.Set(onTime)
+
+	stream := NewEventStreamServer(cache, apt.NewNoopLogger())
+	received := make(chan *proto.KitchenTicketEvent, 10)
+	stream.subscribers["test-subscriber"] = received
+
+	publisher := NewMockPublisher()
+	monitor := NewSLAMonitor(cache, publisher, stream, SLAConfig{Grace: 2 * time.Minute}, apt.NewNoopLogger())
+
+	flagged := monitor.Check(context.Background(), now)
+	if len(flagged) != 1 || flagged[0].ID != late.ID {
+		t.Fatalf("Check() flagged %v, want only the late ticket", flagged)
+	}
+	if cached := cache.Get(late.ID); cached.LateAt == nil || !cached.LateAt.Equal(now) {
+		t.Errorf("cached LateAt = %v, want %v", cached.LateAt, now)
+	}
+	if cache.Get(onTime.ID).LateAt != nil {
+		t.Error("on-time ticket should not be flagged")
+	}
+
+	if len(publisher.PublishedEvents) != 1 || publisher.PublishedEvents[0].Topic != event.KitchenTicketsTopic {
+		t.Fatalf("published %v, want one event on %s", publisher.PublishedEvents, event.KitchenTicketsTopic)
+	}
+	var evt event.KitchenTicketLateEvent
+	if err := unmarshalEvent(publisher.PublishedEvents[0].Data, &evt); err != nil {
+		t.Fatalf("cannot decode event: %v", err)
+	}
+	if evt.EventType != event.EventKitchenTicketLate || evt.TicketID != late.ID.String() || evt.LateSeconds != 600 || evt.TableNumber != "4" {
+		t.Errorf("event = %+v, want ticket.late 600s late for table 4", evt)
+	}
+
+	select {
+	case got := <-received:
+		if got.EventType != event.EventKitchenTicketLate || got.TicketId != late.ID.String() || got.LateSeconds != 600 {
+			t.Errorf("streamed %s for %s (%ds), want the late ticket", got.EventType, got.TicketId, got.LateSeconds)
+		}
+	default:
+		t.Error("late ticket was not broadcast to stream subscribers")
+	}
+
+	// Flagged tickets are announced once
+	if again := monitor.Check(context.Background(), now.Add(time.Minute)); len(again) != 0 {
+		t.Errorf("second Check() flagged %d tickets, want 0", len(again))
+	}
+
+	// Accepting keeps the same deadline, so the ticket stays late
+	cache.Set(&Ticket{ID: late.ID, OrderID: late.OrderID, Station: "kitchen", Status: "accepted", CreatedAt: created, PrepTime: 10})
+	if cache.Get(late.ID).LateAt == nil {
+		t.Error("late flag should survive a move that keeps the deadline")
+	}
+
+	// Starting resets the clock
+	started := now.Add(2 * time.Minute)
+	cache.Set(&Ticket{ID: late.ID, OrderID: late.OrderID, Station: "kitchen", Status: "started", CreatedAt: created, StartedAt: &started, PrepTime: 10})
+	if cache.Get(late.ID).LateAt != nil {
+		t.Error("late flag should clear when the deadline moves")
+	}
+	if got := monitor.Check(context.Background(), started.Add(5*time.Minute)); len(got) != 0 {
+		t.Errorf("Check() after start flagged %d tickets, want 0", len(got))
+	}
+	if len(publisher.PublishedEvents) != 1 {
+		t.Errorf("published %d events, want 1", len(publisher.PublishedEvents))
+	}
+}
+
+func TestSLAMonitorStartStop(t *testing.T) {
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	monitor := NewSLAMonitor(cache, nil, nil, SLAConfig{Interval: time.Millisecond}, nil)
+
+	if err := monitor.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := monitor.Stop(ctx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+
+	idle := NewSLAMonitor(nil, nil, nil, SLAConfig{}, nil)
+	if err := idle.Start(context.Background()); err != nil {
+		t.Errorf("Start() without cache error = %v", err)
+	}
+	if err := idle.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() without start error = %v", err)
+	}
+}
+
+func TestHandlerListTicketsCarriesLateFlags(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	orderID := uuid.New()
+	ticket := &Ticket{ID: uuid.New(), OrderID: orderID, Station: "kitchen", Status: "created", CreatedAt: created, PrepTime: 10}
+
+	repo := NewMockTicketRepository()
+	stored := *ticket
+	repo.AddTicket(&stored)
+	cache := NewTicketStateCache(nil, nil, apt.NewNoopLogger())
+	cache.Set(ticket)
+	cache.MarkLate(ticket.ID, time.Now())
+
+	h := NewHandler(HandlerDeps{Repo: repo, Cache: cache}, apt.NewConfig(), apt.NewNoopLogger())
+	r := chi.NewRouter()
+	r.Get("/tickets", h.ListTickets)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tickets?order_id="+orderID.String(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ListTickets() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Data struct {
+			Tickets []Ticket `json:"tickets"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("cannot decode response: %v", err)
+	}
+	if len(resp.Data.Tickets) != 1 || resp.Data.Tickets[0].LateAt == nil {
+		t.Errorf("tickets = %+v, want the ticket flagged late", resp.Data.Tickets)
+	}
+}
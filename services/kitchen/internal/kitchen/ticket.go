@@ -26,15 +26,43 @@ type Ticket struct {
 	DecisionPayload  []byte        `bson:"decision_payload,omitempty" json:"decision_payload,omitempty"`
 
 	// Denormalized data for display purposes
-	MenuItemName string `bson:"menu_item_name,omitempty" json:"menu_item_name,omitempty"`
-	StationName  string `bson:"station_name,omitempty" json:"station_name,omitempty"`
-	TableNumber  string `bson:"table_number,omitempty" json:"table_number,omitempty"`
+	MenuItemName string   `bson:"menu_item_name,omitempty" json:"menu_item_name,omitempty"`
+	StationName  string   `bson:"station_name,omitempty" json:"station_name,omitempty"`
+	TableNumber  string   `bson:"table_number,omitempty" json:"table_number,omitempty"`
+	Modifiers    []string `bson:"modifiers,omitempty" json:"modifiers,omitempty"`       // e.g. "Cooking point: Medium rare"
+	PortionName  string   `bson:"portion_name,omitempty" json:"portion_name,omitempty"` // e.g. "Half"
+	PrepTime     int      `bson:"prep_time,omitempty" json:"prep_time,omitempty"`       // Expected minutes of work
+	Course       int      `bson:"course,omitempty" json:"course,omitempty"`             // Held in standby until fired
+	Cook         string   `bson:"cook,omitempty" json:"cook,omitempty"`                 // Who started the ticket
 
 	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
 	StartedAt   *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
 	FinishedAt  *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
 	DeliveredAt *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	FiredAt     *time.Time `bson:"fired_at,omitempty" json:"fired_at,omitempty"` // When a held course was sent to the line
+
+	// LateAt is set by the SLA monitor on cached tickets only
+	LateAt *time.Time `bson:"-" json:"late_at,omitempty"`
 
 	ModelVersion int `bson:"model_version" json:"model_version"`
 }
+
+// ExpectedReadyAt returns when the ticket should be done, counting the prep
+// time from when work started or, until then, from when it was ordered or its
+// course was fired. It returns nil when the prep time is unknown or the
+// ticket is already finished.
+func (t *Ticket) ExpectedReadyAt() *time.Time {
+	if t.PrepTime <= 0 || t.FinishedAt != nil {
+		return nil
+	}
+	start := t.CreatedAt
+	if t.FiredAt != nil {
+		start = *t.FiredAt
+	}
+	if t.StartedAt != nil {
+		start = *t.StartedAt
+	}
+	readyAt := start.Add(time.Duration(t.PrepTime) * time.Minute)
+	return &readyAt
+}
@@ -3,7 +3,9 @@ package kitchen
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/appetiteclub/appetite/pkg/event"
 	"github.com/appetiteclub/apt"
@@ -22,6 +24,8 @@ type TicketStateCache struct {
 	byStation map[string][]uuid.UUID
 	// index by status (string code) -> ticket_id
 	byStatus map[string][]uuid.UUID
+	// index by order (order_id string) -> ticket_id, for the expo view
+	byOrder map[string][]uuid.UUID
 
 	stream events.StreamConsumer // For event replay on startup
 	repo   TicketRepository       // Fallback to MongoDB if stream unavailable
@@ -47,6 +51,7 @@ func NewTicketStateCache(stream events.StreamConsumer, repo TicketRepository, lo
 		tickets:   make(map[uuid.UUID]*Ticket),
 		byStation: make(map[string][]uuid.UUID),
 		byStatus:  make(map[string][]uuid.UUID),
+		byOrder:   make(map[string][]uuid.UUID),
 		stream:    stream,
 		repo:      repo,
 		logger:    logger,
@@ -150,20 +155,19 @@ func (c *TicketStateCache) warmFromRepo(ctx context.Context) (err error) {
 // applyEventLocked processes a single event and updates the cache.
 // Must be called with c.mu locked.
 func (c *TicketStateCache) applyEventLocked(ctx context.Context, data []byte) {
-	var baseEvent struct {
-		EventType string `json:"event_type"`
-	}
-
-	if err := json.Unmarshal(data, &baseEvent); err != nil {
+	env, err := event.Open(data)
+	if err != nil {
 		c.logger.Error("failed to unmarshal event type", "error", err)
 		return
 	}
 
-	switch baseEvent.EventType {
+	switch env.Type {
 	case event.EventKitchenTicketCreated:
-		c.handleTicketCreatedLocked(data)
+		c.handleTicketCreatedLocked(env.Payload)
 	case event.EventKitchenTicketStatusChange:
-		c.handleTicketStatusChangedLocked(data)
+		c.handleTicketStatusChangedLocked(env.Payload)
+	case event.EventKitchenDecisionRequested, event.EventKitchenDecisionEscalated, event.EventKitchenDecisionResolved:
+		c.handleDecisionLocked(env.Payload)
 	default:
 		// Silently ignore unknown event types (forward compatibility)
 		return
@@ -196,8 +200,13 @@ func (c *TicketStateCache) handleTicketCreatedLocked(data []byte) {
 		MenuItemName: evt.MenuItemName,
 		StationName:  evt.StationName,
 		TableNumber:  evt.TableNumber,
+		Modifiers:    evt.Modifiers,
+		PortionName:  evt.PortionName,
+		PrepTime:     evt.PrepTime,
+		Course:       evt.Course,
 		CreatedAt:    evt.OccurredAt,
 		UpdatedAt:    evt.OccurredAt,
+		ModelVersion: evt.ModelVersion,
 	}
 
 	c.setLocked(ticket)
@@ -234,6 +243,20 @@ func (c *TicketStateCache) handleTicketStatusChangedLocked(data []byte) {
 	// Update status and timestamps
 	ticket.Status = evt.NewStatus
 	ticket.Notes = evt.Notes
+	if len(evt.Modifiers) > 0 {
+		ticket.Modifiers = evt.Modifiers
+	}
+	if evt.PortionName != "" {
+		ticket.PortionName = evt.PortionName
+		ticket.PrepTime = evt.PrepTime
+	}
+	if evt.Course > 0 {
+		ticket.Course = evt.Course
+	}
+	// Events from before tickets had versions carry none
+	if evt.ModelVersion > 0 {
+		ticket.ModelVersion = evt.ModelVersion
+	}
 	ticket.UpdatedAt = evt.OccurredAt
 	ticket.StartedAt = evt.StartedAt
 	ticket.FinishedAt = evt.FinishedAt
@@ -252,6 +275,36 @@ func (c *TicketStateCache) handleTicketStatusChangedLocked(data []byte) {
 	}
 }
 
+// handleDecisionLocked processes the decision events of a ticket, so a
+// question the floor has not answered yet survives a restart.
+func (c *TicketStateCache) handleDecisionLocked(data []byte) {
+	var evt event.KitchenTicketDecisionEvent
+	if err := json.Unmarshal(data, &evt); err != nil {
+		c.logger.Error("failed to unmarshal decision event", "error", err)
+		return
+	}
+
+	ticketID, _ := uuid.Parse(evt.TicketID)
+	ticket := c.tickets[ticketID]
+	if ticket == nil {
+		return
+	}
+
+	previous, _ := ticket.Decision()
+	payload, err := json.Marshal(decisionFromEvent(&evt, previous))
+	if err != nil {
+		c.logger.Error("failed to encode decision", "error", err)
+		return
+	}
+	ticket.DecisionRequired = evt.EventType != event.EventKitchenDecisionResolved
+	ticket.DecisionPayload = payload
+
+	if evt.Chosen != nil && evt.Chosen.Outcome == event.DecisionOutcomeSubstitute {
+		ticket.MenuItemID, _ = uuid.Parse(evt.MenuItemID)
+		ticket.MenuItemName = evt.MenuItemName
+	}
+}
+
 // removeCompletedTickets filters out delivered and cancelled tickets from the cache.
 // This should be called after warming from stream to show only active tickets.
 func (c *TicketStateCache) removeCompletedTickets() {
@@ -263,6 +316,7 @@ func (c *TicketStateCache) removeCompletedTickets() {
 		if ticket.Status == "delivered" || ticket.Status == "cancelled" {
 			c.removeFromIndexStr(c.byStation, ticket.Station, id)
 			c.removeFromIndexStr(c.byStatus, ticket.Status, id)
+			c.removeFromIndexStr(c.byOrder, ticket.OrderID.String(), id)
 			delete(c.tickets, id)
 			removed++
 		}
@@ -290,8 +344,13 @@ func (c *TicketStateCache) setLocked(ticket *Ticket) {
 	var previousStatus string
 	if old, exists := c.tickets[ticketID]; exists {
 		previousStatus = old.Status
+		if ticket.LateAt == nil && sameDeadline(old, ticket) {
+			// Still running against the same clock, so still late
+			ticket.LateAt = old.LateAt
+		}
 		c.removeFromIndexStr(c.byStation, old.Station, ticketID)
 		c.removeFromIndexStr(c.byStatus, old.Status, ticketID)
+		c.removeFromIndexStr(c.byOrder, old.OrderID.String(), ticketID)
 	}
 
 	// Update ticket
@@ -300,6 +359,7 @@ func (c *TicketStateCache) setLocked(ticket *Ticket) {
 	// Update indexes
 	c.addToIndexStr(c.byStation, ticket.Station, ticketID)
 	c.addToIndexStr(c.byStatus, ticket.Status, ticketID)
+	c.addToIndexStr(c.byOrder, ticket.OrderID.String(), ticketID)
 
 	// Broadcast to gRPC stream subscribers
 	if c.streamServer != nil {
@@ -315,6 +375,11 @@ func (c *TicketStateCache) setLocked(ticket *Ticket) {
 				MenuItemName: ticket.MenuItemName,
 				StationName:  ticket.StationName,
 				TableNumber:  ticket.TableNumber,
+				Modifiers:    ticket.Modifiers,
+				PortionName:  ticket.PortionName,
+				PrepTime:     ticket.PrepTime,
+				Course:       ticket.Course,
+				ModelVersion: ticket.ModelVersion,
 			},
 			NewStatus:      ticket.Status,
 			PreviousStatus: previousStatus,
@@ -379,6 +444,67 @@ func (c *TicketStateCache) GetByStationAndStatusCode(station string, status stri
 	return result
 }
 
+// GetByOrder returns all tickets for a given order.
+func (c *TicketStateCache) GetByOrder(orderID OrderID) []*Ticket {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ticketIDs := c.byOrder[orderID.String()]
+	result := make([]*Ticket, 0, len(ticketIDs))
+	for _, id := range ticketIDs {
+		if ticket := c.tickets[id]; ticket != nil {
+			result = append(result, ticket)
+		}
+	}
+	return result
+}
+
+// MarkLate flags a cached ticket as late as of at and returns the flagged
+// copy. It returns nil when the ticket is not cached or already flagged.
+func (c *TicketStateCache) MarkLate(ticketID uuid.UUID, at time.Time) *Ticket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ticket := c.tickets[ticketID]
+	if ticket == nil || ticket.LateAt != nil {
+		return nil
+	}
+
+	// Station, status and order do not change, so the indexes stay valid
+	late := *ticket
+	late.LateAt = &at
+	c.tickets[ticketID] = &late
+	return &late
+}
+
+// ExpoOrders groups the open tickets by order for the expo view, oldest
+// order first. Orders with nothing left on the pass are left out.
+func (c *TicketStateCache) ExpoOrders() []ExpoOrder {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	orders := make([]ExpoOrder, 0, len(c.byOrder))
+	for _, ticketIDs := range c.byOrder {
+		tickets := make([]*Ticket, 0, len(ticketIDs))
+		for _, id := range ticketIDs {
+			if ticket := c.tickets[id]; ticket != nil {
+				tickets = append(tickets, ticket)
+			}
+		}
+		if len(tickets) == 0 {
+			continue
+		}
+		if order := newExpoOrder(tickets[0].OrderID, tickets); order.Total > 0 {
+			orders = append(orders, order)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].FirstAt.Before(orders[j].FirstAt)
+	})
+	return orders
+}
+
 // GetAll returns all cached tickets.
 func (c *TicketStateCache) GetAll() []*Ticket {
 	c.mu.RLock()
@@ -403,6 +529,7 @@ func (c *TicketStateCache) Remove(ticketID uuid.UUID) {
 
 	c.removeFromIndexStr(c.byStation, ticket.Station, ticketID)
 	c.removeFromIndexStr(c.byStatus, ticket.Status, ticketID)
+	c.removeFromIndexStr(c.byOrder, ticket.OrderID.String(), ticketID)
 	delete(c.tickets, ticketID)
 }
 
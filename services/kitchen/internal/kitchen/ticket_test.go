@@ -0,0 +1,64 @@
+package kitchen
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicketExpectedReadyAt(t *testing.T) {
+	created := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+	started := created.Add(5 * time.Minute)
+	fired := created.Add(20 * time.Minute)
+	finished := started.Add(10 * time.Minute)
+
+	tests := []struct {
+		name   string
+		ticket Ticket
+		want   *time.Time
+	}{
+		{
+			name:   "noPrepTime",
+			ticket: Ticket{CreatedAt: created},
+			want:   nil,
+		},
+		{
+			name:   "countsFromOrderedUntilStarted",
+			ticket: Ticket{CreatedAt: created, PrepTime: 15},
+			want:   timePtr(created.Add(15 * time.Minute)),
+		},
+		{
+			name:   "countsFromStarted",
+			ticket: Ticket{CreatedAt: created, StartedAt: &started, PrepTime: 15},
+			want:   timePtr(started.Add(15 * time.Minute)),
+		},
+		{
+			name:   "countsFromFiredCourse",
+			ticket: Ticket{CreatedAt: created, FiredAt: &fired, PrepTime: 15},
+			want:   timePtr(fired.Add(15 * time.Minute)),
+		},
+		{
+			name:   "finished",
+			ticket: Ticket{CreatedAt: created, StartedAt: &started, FinishedAt: &finished, PrepTime: 15},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ticket.ExpectedReadyAt()
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("ExpectedReadyAt() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || !got.Equal(*tt.want) {
+				t.Errorf("ExpectedReadyAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -363,13 +363,14 @@ func TestTicketStateCacheWarmFromStreamWithStatusChange(t *testing.T) {
 	// First event: create
 	createdEvent := event.KitchenTicketCreatedEvent{
 		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
-			EventType:   event.EventKitchenTicketCreated,
-			OccurredAt:  time.Now(),
-			TicketID:    ticketID.String(),
-			OrderID:     orderID.String(),
-			OrderItemID: orderItemID.String(),
-			MenuItemID:  menuItemID.String(),
-			Station:     "kitchen",
+			EventType:    event.EventKitchenTicketCreated,
+			OccurredAt:   time.Now(),
+			TicketID:     ticketID.String(),
+			OrderID:      orderID.String(),
+			OrderItemID:  orderItemID.String(),
+			MenuItemID:   menuItemID.String(),
+			Station:      "kitchen",
+			ModelVersion: 1,
 		},
 		Status: "created",
 	}
@@ -380,13 +381,14 @@ func TestTicketStateCacheWarmFromStreamWithStatusChange(t *testing.T) {
 	now := time.Now()
 	statusEvent := event.KitchenTicketStatusChangedEvent{
 		KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
-			EventType:   event.EventKitchenTicketStatusChange,
-			OccurredAt:  now,
-			TicketID:    ticketID.String(),
-			OrderID:     orderID.String(),
-			OrderItemID: orderItemID.String(),
-			MenuItemID:  menuItemID.String(),
-			Station:     "kitchen",
+			EventType:    event.EventKitchenTicketStatusChange,
+			OccurredAt:   now,
+			TicketID:     ticketID.String(),
+			OrderID:      orderID.String(),
+			OrderItemID:  orderItemID.String(),
+			MenuItemID:   menuItemID.String(),
+			Station:      "kitchen",
+			ModelVersion: 2,
 		},
 		NewStatus:      "started",
 		PreviousStatus: "created",
@@ -408,6 +410,9 @@ func TestTicketStateCacheWarmFromStreamWithStatusChange(t *testing.T) {
 	if ticket.StartedAt == nil {
 		t.Error("Ticket StartedAt is nil after status change")
 	}
+	if ticket.ModelVersion != 2 {
+		t.Errorf("Ticket ModelVersion = %d, want 2", ticket.ModelVersion)
+	}
 }
 
 func TestTicketStateCacheWarmFromStreamRemovesDelivered(t *testing.T) {
@@ -0,0 +1,99 @@
+package kitchen
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/enums/kitchenstatus"
+)
+
+var (
+	// ErrUnknownStatus is returned for statuses outside kitchenstatus.All
+	ErrUnknownStatus = errors.New("unknown ticket status")
+	// ErrInvalidTransition is returned when the ticket cannot move to the
+	// requested status, either by the transition graph or by a guard
+	ErrInvalidTransition = errors.New("invalid ticket transition")
+)
+
+// transitionGuard vetoes a move the graph allows, given the ticket as it is
+// before the move.
+type transitionGuard func(t *Ticket) error
+
+// transitionGuards are checked by target status.
+var transitionGuards = map[string][]transitionGuard{
+	kitchenstatus.Statuses.Accepted.Code(): {requireNoPendingDecision},
+	kitchenstatus.Statuses.Started.Code():  {requireNoPendingDecision},
+	kitchenstatus.Statuses.Ready.Code():    {requireNoPendingDecision},
+}
+
+func requireNoPendingDecision(t *Ticket) error {
+	if t.DecisionRequired {
+		return errors.New("ticket is waiting for a decision from the floor")
+	}
+	return nil
+}
+
+// CanTransition checks whether the ticket can move to status now.
+func (t *Ticket) CanTransition(status string) error {
+	if kitchenstatus.ByName(status) == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownStatus, status)
+	}
+	if !kitchenstatus.CanTransition(t.Status, status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, t.Status, status)
+	}
+	for _, guard := range transitionGuards[status] {
+		if err := guard(t); err != nil {
+			return fmt.Errorf("%w: %s to %s: %v", ErrInvalidTransition, t.Status, status, err)
+		}
+	}
+	return nil
+}
+
+// Transition moves the ticket to status and stamps the times the move
+// implies: started, finished and delivered are set the first time the ticket
+// reaches them, and sending a ready ticket back to the line clears its finish.
+func (t *Ticket) Transition(status string, now time.Time) error {
+	if err := t.CanTransition(status); err != nil {
+		return err
+	}
+
+	previous := t.Status
+	t.Status = status
+
+	switch status {
+	case kitchenstatus.Statuses.Started.Code():
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+		if previous == kitchenstatus.Statuses.Ready.Code() {
+			t.FinishedAt = nil
+		}
+	case kitchenstatus.Statuses.Ready.Code():
+		t.FinishedAt = &now
+	case kitchenstatus.Statuses.Delivered.Code():
+		t.DeliveredAt = &now
+	}
+
+	if previous == kitchenstatus.Statuses.Block.Code() {
+		t.ReasonCodeID = nil
+	}
+
+	return nil
+}
+
+// AvailableTransitions returns the statuses the ticket can move to now.
+func (t *Ticket) AvailableTransitions() []kitchenstatus.Status {
+	current := kitchenstatus.ByName(t.Status)
+	if current == nil {
+		return []kitchenstatus.Status{}
+	}
+
+	available := []kitchenstatus.Status{}
+	for _, next := range current.Next() {
+		if t.CanTransition(next.Code()) == nil {
+			available = append(available, next)
+		}
+	}
+	return available
+}
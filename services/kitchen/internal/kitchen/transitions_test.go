@@ -0,0 +1,157 @@
+package kitchen
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTicketTransition(t *testing.T) {
+	now := time.Date(2024, 5, 10, 20, 30, 0, 0, time.UTC)
+	earlier := now.Add(-10 * time.Minute)
+	reason := uuid.New()
+
+	tests := []struct {
+		name    string
+		ticket  Ticket
+		to      string
+		wantErr error
+		check   func(t *testing.T, ticket Ticket)
+	}{
+		{
+			name:   "acceptCreated",
+			ticket: Ticket{Status: "created"},
+			to:     "accepted",
+		},
+		{
+			name:   "fireHeldCourse",
+			ticket: Ticket{Status: "standby", Course: 2},
+			to:     "created",
+		},
+		{
+			name:   "startSetsStartedAt",
+			ticket: Ticket{Status: "accepted"},
+			to:     "started",
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.StartedAt == nil || !ticket.StartedAt.Equal(now) {
+					t.Errorf("StartedAt = %v, want %v", ticket.StartedAt, now)
+				}
+			},
+		},
+		{
+			name:   "restartKeepsStartedAt",
+			ticket: Ticket{Status: "standby", StartedAt: &earlier},
+			to:     "started",
+			check: func(t *testing.T, ticket Ticket) {
+				if !ticket.StartedAt.Equal(earlier) {
+					t.Errorf("StartedAt = %v, want %v", ticket.StartedAt, earlier)
+				}
+			},
+		},
+		{
+			name:   "readySetsFinishedAt",
+			ticket: Ticket{Status: "started", StartedAt: &earlier},
+			to:     "ready",
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.FinishedAt == nil || !ticket.FinishedAt.Equal(now) {
+					t.Errorf("FinishedAt = %v, want %v", ticket.FinishedAt, now)
+				}
+			},
+		},
+		{
+			name:   "sendBackClearsFinishedAt",
+			ticket: Ticket{Status: "ready", StartedAt: &earlier, FinishedAt: &earlier},
+			to:     "started",
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.FinishedAt != nil {
+					t.Errorf("FinishedAt = %v, want nil", ticket.FinishedAt)
+				}
+			},
+		},
+		{
+			name:   "deliverSetsDeliveredAt",
+			ticket: Ticket{Status: "ready"},
+			to:     "delivered",
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.DeliveredAt == nil || !ticket.DeliveredAt.Equal(now) {
+					t.Errorf("DeliveredAt = %v, want %v", ticket.DeliveredAt, now)
+				}
+			},
+		},
+		{
+			name:   "unblockClearsReason",
+			ticket: Ticket{Status: "block", ReasonCodeID: &reason},
+			to:     "started",
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.ReasonCodeID != nil {
+					t.Errorf("ReasonCodeID = %v, want nil", ticket.ReasonCodeID)
+				}
+			},
+		},
+		{
+			name:    "cannotSkipToReady",
+			ticket:  Ticket{Status: "created"},
+			to:      "ready",
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "cannotDeliverUnfinished",
+			ticket:  Ticket{Status: "started"},
+			to:      "delivered",
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "cannotRejectStarted",
+			ticket:  Ticket{Status: "started"},
+			to:      "reject",
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "finalTicket",
+			ticket:  Ticket{Status: "cancelled"},
+			to:      "started",
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "pendingDecision",
+			ticket:  Ticket{Status: "block", DecisionRequired: true},
+			to:      "started",
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "unknownStatus",
+			ticket:  Ticket{Status: "created"},
+			to:      "plated",
+			wantErr: ErrUnknownStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := tt.ticket
+			from := ticket.Status
+
+			err := ticket.Transition(tt.to, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Transition() error = %v, want %v", err, tt.wantErr)
+				}
+				if ticket.Status != from {
+					t.Errorf("Status = %q after refused transition, want %q", ticket.Status, from)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transition() unexpected error: %v", err)
+			}
+			if ticket.Status != tt.to {
+				t.Errorf("Status = %q, want %q", ticket.Status, tt.to)
+			}
+			if tt.check != nil {
+				tt.check(t, ticket)
+			}
+		})
+	}
+}
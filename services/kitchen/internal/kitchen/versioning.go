@@ -0,0 +1,95 @@
+package kitchen
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/appetiteclub/apt"
+)
+
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+// ticketETag is the entity tag of the ticket's current version.
+func ticketETag(t *Ticket) string {
+	return strconv.Quote(strconv.Itoa(t.ModelVersion))
+}
+
+// ifMatchVersion reads the ticket version the client last saw from If-Match.
+// ok is false when the header is missing or "*", which skips the check.
+func ifMatchVersion(r *http.Request) (version int, ok bool, err error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, false, nil
+	}
+
+	// Tickets only have one representation, so weak tags compare the same
+	tag := strings.TrimPrefix(header, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, false, errInvalidIfMatch
+	}
+	version, err = strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, false, errInvalidIfMatch
+	}
+	return version, true, nil
+}
+
+// checkVersion refuses the request when the client edited an older version
+// of the ticket than the stored one. The version comes from If-Match or,
+// for clients that cannot set headers, from bodyVersion. It reports whether
+// the request can go on. Versions start at 1, so a version of 0 means the
+// client never knew it and skips the check like a missing one.
+func (h *Handler) checkVersion(w http.ResponseWriter, r *http.Request, ticket *Ticket, bodyVersion *int) bool {
+	version, ok, err := ifMatchVersion(r)
+	if err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if !ok && bodyVersion != nil {
+		version, ok = *bodyVersion, true
+	}
+	if ok && version > 0 && version != ticket.ModelVersion {
+		respondConflict(w, ticket)
+		return false
+	}
+	return true
+}
+
+// respondUpdateError answers a failed repo.Update. A version conflict gets
+// the ticket as it is now, so the client can retry on top of it.
+func (h *Handler) respondUpdateError(w http.ResponseWriter, r *http.Request, id TicketID, err error) {
+	log := h.log(r)
+
+	switch {
+	case errors.Is(err, ErrVersionConflict):
+		current, findErr := h.repo.FindByID(r.Context(), id)
+		if findErr != nil {
+			log.Errorf("cannot reload ticket after conflict: %v", findErr)
+			apt.RespondError(w, http.StatusConflict, "Ticket was changed by someone else")
+			return
+		}
+		respondConflict(w, current)
+	case errors.Is(err, ErrTicketNotFound):
+		apt.RespondError(w, http.StatusNotFound, "Ticket not found")
+	default:
+		log.Errorf("cannot update ticket: %v", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not update ticket")
+	}
+}
+
+// respondConflict answers 409 with the current ticket and its ETag.
+func respondConflict(w http.ResponseWriter, current *Ticket) {
+	w.Header().Set("ETag", ticketETag(current))
+	apt.Respond(w, http.StatusConflict, current, map[string]interface{}{
+		"error": "Ticket was changed by someone else",
+	})
+}
+
+// respondTicket writes the ticket with its ETag.
+func respondTicket(w http.ResponseWriter, status int, ticket *Ticket) {
+	w.Header().Set("ETag", ticketETag(ticket))
+	apt.Respond(w, status, ticket, nil)
+}
@@ -0,0 +1,166 @@
+package kitchen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestHandlerTicketVersioning(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		ifMatch        string
+		setupRepo      func(r *MockTicketRepository)
+		expectedStatus int
+		expectedETag   string
+		expectedTicket string
+	}{
+		{
+			name:           "noPrecondition",
+			path:           "start",
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:           "matchingIfMatch",
+			path:           "start",
+			ifMatch:        `"3"`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:           "weakIfMatch",
+			path:           "start",
+			ifMatch:        `W/"3"`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:           "staleIfMatch",
+			path:           "start",
+			ifMatch:        `"2"`,
+			expectedStatus: http.StatusConflict,
+			expectedETag:   `"3"`,
+			expectedTicket: "accepted",
+		},
+		{
+			name:           "invalidIfMatch",
+			path:           "start",
+			ifMatch:        "three",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "staleBodyVersion",
+			path:           "status",
+			body:           `{"status": "started", "model_version": 1}`,
+			expectedStatus: http.StatusConflict,
+			expectedETag:   `"3"`,
+			expectedTicket: "accepted",
+		},
+		{
+			name:           "unknownBodyVersion",
+			path:           "status",
+			body:           `{"status": "started", "model_version": 0}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:           "staleBlock",
+			path:           "block",
+			ifMatch:        `"1"`,
+			expectedStatus: http.StatusConflict,
+			expectedETag:   `"3"`,
+			expectedTicket: "accepted",
+		},
+		{
+			name: "lostRace",
+			path: "start",
+			setupRepo: func(r *MockTicketRepository) {
+				r.UpdateFunc = func(ctx context.Context, t *Ticket) error {
+					// Another cook started and finished the ticket first
+					r.tickets[t.ID] = &Ticket{ID: t.ID, Station: t.Station, Status: "ready", ModelVersion: 5}
+					return ErrVersionConflict
+				}
+			},
+			expectedStatus: http.StatusConflict,
+			expectedETag:   `"5"`,
+			expectedTicket: "ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := &Ticket{ID: uuid.New(), Station: "kitchen", Status: "accepted", ModelVersion: 3}
+			repo := NewMockTicketRepository()
+			repo.AddTicket(ticket)
+			if tt.setupRepo != nil {
+				tt.setupRepo(repo)
+			}
+
+			deps := HandlerDeps{Repo: repo, Cache: NewTicketStateCache(nil, nil, apt.NewNoopLogger()), Publisher: NewMockPublisher()}
+			h := NewHandler(deps, apt.NewConfig(), apt.NewNoopLogger())
+
+			r := chi.NewRouter()
+			r.Patch("/tickets/{id}/start", h.StartTicket)
+			r.Patch("/tickets/{id}/block", h.BlockTicket)
+			r.Patch("/tickets/{id}/status", h.UpdateTicketStatus)
+
+			req := httptest.NewRequest(http.MethodPatch, "/tickets/"+ticket.ID.String()+"/"+tt.path, bytes.NewBufferString(tt.body))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if got := w.Header().Get("ETag"); got != tt.expectedETag {
+				t.Errorf("ETag = %q, want %q", got, tt.expectedETag)
+			}
+			if tt.expectedTicket == "" {
+				return
+			}
+
+			var resp struct {
+				Data Ticket `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("cannot decode response: %v", err)
+			}
+			if resp.Data.Status != tt.expectedTicket {
+				t.Errorf("conflict ticket status = %q, want %q", resp.Data.Status, tt.expectedTicket)
+			}
+		})
+	}
+}
+
+func TestHandlerGetTicketETag(t *testing.T) {
+	ticket := &Ticket{ID: uuid.New(), Station: "kitchen", Status: "created", ModelVersion: 7}
+	repo := NewMockTicketRepository()
+	repo.AddTicket(ticket)
+
+	h := NewHandler(HandlerDeps{Repo: repo, Publisher: NewMockPublisher()}, apt.NewConfig(), apt.NewNoopLogger())
+
+	r := chi.NewRouter()
+	r.Get("/tickets/{id}", h.GetTicket)
+
+	req := httptest.NewRequest(http.MethodGet, "/tickets/"+ticket.ID.String(), nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("ETag"); got != `"7"` {
+		t.Errorf("ETag = %q, want %q", got, `"7"`)
+	}
+}
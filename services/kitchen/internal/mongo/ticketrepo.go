@@ -77,6 +77,14 @@ func (r *TicketRepo) Start(ctx context.Context) error {
 		return fmt.Errorf("cannot create status index: %w", err)
 	}
 
+	// Analytics reads tickets by date range
+	createdAtIndexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: 1}},
+	}
+	if _, err := r.collection.Indexes().CreateOne(ctx, createdAtIndexModel); err != nil {
+		return fmt.Errorf("cannot create created_at index: %w", err)
+	}
+
 	r.logger.Infof("Connected to MongoDB: %s, database: %s, collection: tickets", mongoURL, dbName)
 	return nil
 }
@@ -108,29 +116,50 @@ func (r *TicketRepo) Create(ctx context.Context, t *kitchen.Ticket) error {
 }
 
 func (r *TicketRepo) Update(ctx context.Context, t *kitchen.Ticket) error {
+	version := t.ModelVersion
+	updatedAt := t.UpdatedAt
+
 	t.UpdatedAt = time.Now()
+	t.ModelVersion = version + 1
 
-	filter := bson.M{"_id": t.ID}
+	// Compare-and-swap: only the writer that read the current version wins
+	filter := bson.M{"_id": t.ID, "model_version": version}
 	update := bson.M{"$set": t}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return fmt.Errorf("cannot update ticket: %w", err)
+		err = fmt.Errorf("cannot update ticket: %w", err)
+	} else if result.MatchedCount == 0 {
+		err = r.missOrConflict(ctx, t.ID, version)
 	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("ticket not found")
+	if err != nil {
+		t.UpdatedAt = updatedAt
+		t.ModelVersion = version
+		return err
 	}
 
 	return nil
 }
 
+// missOrConflict tells apart a ticket that does not exist from one another
+// writer already moved past version.
+func (r *TicketRepo) missOrConflict(ctx context.Context, id kitchen.TicketID, version int) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("cannot update ticket: %w", err)
+	}
+	if count == 0 {
+		return kitchen.ErrTicketNotFound
+	}
+	return fmt.Errorf("%w: ticket %s is no longer at version %d", kitchen.ErrVersionConflict, id, version)
+}
+
 func (r *TicketRepo) FindByID(ctx context.Context, id kitchen.TicketID) (*kitchen.Ticket, error) {
 	var ticket kitchen.Ticket
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&ticket)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("ticket not found")
+			return nil, kitchen.ErrTicketNotFound
 		}
 		return nil, fmt.Errorf("cannot find ticket: %w", err)
 	}
@@ -168,6 +197,17 @@ func (r *TicketRepo) List(ctx context.Context, filter kitchen.TicketFilter) ([]k
 		query["order_item_id"] = *filter.OrderItemID
 	}
 
+	if filter.CreatedFrom != nil || filter.CreatedTo != nil {
+		created := bson.M{}
+		if filter.CreatedFrom != nil {
+			created["$gte"] = *filter.CreatedFrom
+		}
+		if filter.CreatedTo != nil {
+			created["$lt"] = *filter.CreatedTo
+		}
+		query["created_at"] = created
+	}
+
 	opts := options.Find().
 		SetSort(bson.D{{Key: "created_at", Value: -1}})
 
@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/enums/station"
+	"github.com/appetiteclub/appetite/pkg/idempotency"
+	"github.com/appetiteclub/appetite/pkg/outbox"
 	"github.com/appetiteclub/appetite/services/kitchen/internal/events"
 	"github.com/appetiteclub/appetite/services/kitchen/internal/kitchen"
 	"github.com/appetiteclub/appetite/services/kitchen/internal/mongo"
@@ -48,19 +51,27 @@ func main() {
 
 	// Initialize NATS Stream (JetStream) for persistent event publishing
 	var kitchenStream *pkg.NATSStream
-	var orderSubscriber *pkg.NATSSubscriber
+	var orderSubscriber *pkg.NATSConsumer
 	streamEnabled := config.GetBoolOrFalse("nats.stream.enabled")
 	if !streamEnabled {
 		log.Fatalf("%s(%s) NATS stream should be enabled: %v", appName, appVersion, errors.New("nats stream disabled"))
 	}
 
+	// Failed messages are retried with backoff, then dead-lettered
+	deliveryPolicy, err := pkg.LoadDeliveryPolicy(config, appName)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup NATS delivery: %v", appName, appVersion, err)
+	}
+
 	streamCfg := pkg.NATSStreamConfig{
 		URL:          natsURL,
-		StreamName:   "KITCHEN_EVENTS",
+		StreamName:   pkg.KitchenStreamName,
 		Topic:        "kitchen.tickets",
 		ConsumerName: "kitchen-publisher",
-		MaxAge:       24 * time.Hour,
+		MaxAge:       pkg.KitchenStreamMaxAge,
 		MaxMsgs:      0,
+		Delivery:     deliveryPolicy,
+		Logger:       logger,
 	}
 
 	kitchenStream, err = pkg.NewNATSStream(streamCfg)
@@ -69,16 +80,23 @@ func main() {
 	}
 	logger.Info("NATS stream initialized for persistent events")
 
-	// Use separate subscriber for orders.items (no persistence needed)
-	orderSubscriber, err = pkg.NewNATSSubscriber(natsURL)
+	// orders.items is read with a durable consumer, so the order item
+	// events published while the kitchen is down, and their retries, are
+	// handled once it is back
+	orderSubscriber, err = pkg.NewNATSConsumer(ctx, pkg.NATSConsumerConfig{
+		URL:      natsURL,
+		Consumer: appName,
+		Delivery: deliveryPolicy,
+		Logger:   logger,
+	})
 	if err != nil {
 		log.Fatalf("Cannot connect to NATS subscriber: %v", err)
 	}
 
 	// Event subscriber consumes orders.items and publishes to kitchen.tickets
-	var eventPublisher aqmevents.Publisher
+	var streamPublisher aqmevents.Publisher
 	if kitchenStream != nil {
-		eventPublisher = kitchenStream
+		streamPublisher = kitchenStream
 	} else {
 		// Fallback: create basic publisher
 		pub, err := pkg.NewNATSPublisher(natsURL)
@@ -86,27 +104,82 @@ func main() {
 			log.Fatalf("Cannot create fallback publisher: %v", err)
 		}
 
-		eventPublisher = pub
+		streamPublisher = pub
 	}
 
+	// Ticket events are stored with the tickets and relayed to the stream
+	// from there, so a NATS outage delays them instead of losing them
+	relayConfig, err := outbox.LoadRelayConfig(config)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup outbox: %v", appName, appVersion, err)
+	}
+	eventOutbox := outbox.New(ticketRepo.GetDatabase, logger)
+	relay := outbox.NewRelay(eventOutbox, streamPublisher, relayConfig, logger)
+
 	// Initialize ticket cache with Stream (required)
 	ticketCache := kitchen.NewTicketStateCache(kitchenStream, ticketRepo, logger)
 
-	eventSubscriber := events.NewOrderItemSubscriber(orderSubscriber, ticketRepo, ticketCache, eventPublisher, logger)
+	// Stations come from the dictionary so venues can model their own line
+	dictionaryURL := config.GetStringOrDef("services.dictionary.url", "http://localhost:8085")
+	stationRefresh := station.DefaultRefreshInterval
+	if value, _ := config.GetString("stations.refresh_interval"); value != "" {
+		stationRefresh, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("%s(%s) invalid stations.refresh_interval %q: %v", appName, appVersion, value, err)
+		}
+	}
+	stations := station.NewRegistry(apt.NewServiceClient(dictionaryURL), stationRefresh, logger)
 
-	hd := kitchen.HandlerDeps{
-		Repo:      ticketRepo,
-		Cache:     ticketCache,
-		Publisher: eventPublisher,
+	// Station hours are kept on the venue's clock, the same as menu visibility
+	timezone := config.GetStringOrDef("venue.timezone", "UTC")
+	venueLocation, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Fatalf("%s(%s) invalid venue.timezone %q: %v", appName, appVersion, timezone, err)
 	}
+	stations.SetLocation(venueLocation)
 
-	// Handler uses Stream for publishing ticket events and cache for reads
-	handler := kitchen.NewHandler(hd, config, logger)
+	eventSubscriber := events.NewOrderItemSubscriber(orderSubscriber, ticketRepo, ticketCache, eventOutbox, logger)
+	eventSubscriber.SetStations(stations)
+
+	// Redelivered and republished order item events are skipped by their
+	// event ID, so they do not create a second ticket
+	processedTTL, err := idempotency.LoadTTL(config)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup idempotency store: %v", appName, appVersion, err)
+	}
+	processedEvents := idempotency.NewMongoStore(ticketRepo.GetDatabase, appName+".order_items", processedTTL, logger)
+	eventSubscriber.SetIdempotencyStore(processedEvents)
 
 	// Initialize gRPC streaming server for real-time events
 	grpcStreamServer := kitchen.NewEventStreamServer(ticketCache, logger)
+	grpcStreamServer.SetStations(stations)
 	ticketCache.SetStreamServer(grpcStreamServer)
 
+	// Decisions the floor leaves unanswered go to a manager
+	decisionConfig, err := kitchen.LoadDecisionConfig(config)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup decisions: %v", appName, appVersion, err)
+	}
+	decisionMonitor := kitchen.NewDecisionMonitor(ticketCache, ticketRepo, eventOutbox, grpcStreamServer, decisionConfig, logger)
+
+	hd := kitchen.HandlerDeps{
+		Repo:            ticketRepo,
+		Cache:           ticketCache,
+		Publisher:       eventOutbox,
+		Stream:          grpcStreamServer,
+		DecisionTimeout: decisionConfig.Timeout,
+	}
+
+	// Handler uses Stream for publishing ticket events and cache for reads
+	handler := kitchen.NewHandler(hd, config, logger)
+
+	// SLA monitor flags tickets running past their expected ready time
+	slaConfig, err := kitchen.LoadSLAConfig(config)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup SLA monitor: %v", appName, appVersion, err)
+	}
+	slaMonitor := kitchen.NewSLAMonitor(ticketCache, eventOutbox, grpcStreamServer, slaConfig, logger)
+
 	stack := middleware.DefaultStack(middleware.StackOptions{
 		Logger:      logger,
 		DisableCORS: true,
@@ -114,7 +187,7 @@ func main() {
 	stack = append(stack, middleware.InternalOnly())
 
 	// Setup lifecycle hooks
-	lifecycles := []interface{}{ticketRepo, eventSubscriber}
+	lifecycles := []interface{}{ticketRepo, relay, stations, processedEvents, eventSubscriber}
 
 	// Warm cache after repo is started
 	cacheLifecycle := apt.LifecycleHooks{
@@ -126,7 +199,7 @@ func main() {
 			return nil
 		},
 	}
-	lifecycles = append(lifecycles, cacheLifecycle)
+	lifecycles = append(lifecycles, cacheLifecycle, slaMonitor, decisionMonitor)
 
 	// Setup demo seeding if enabled
 	demoEnabled, _ := config.GetString("seeding.demo")
@@ -1,10 +1,15 @@
 package menu
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/appetiteclub/appetite/services/menu/internal/dictionary"
 	"github.com/appetiteclub/apt"
@@ -15,20 +20,27 @@ import (
 
 const MaxBodyBytes = 2 << 20 // 2 MB (larger for menu items with images)
 
+// availableAtLayout is the venue wall-clock format accepted by ?available_at=
+// besides RFC3339.
+const availableAtLayout = "2006-01-02T15:04"
+
 // Handler handles HTTP requests for the Menu service
 type Handler struct {
-	config     *apt.Config
-	logger     apt.Logger
-	tlm        *telemetry.HTTP
-	itemRepo   MenuItemRepo
-	menuRepo   MenuRepo
-	dictClient dictionary.Client
+	config      *apt.Config
+	logger      apt.Logger
+	tlm         *telemetry.HTTP
+	itemRepo    MenuItemRepo
+	menuRepo    MenuRepo
+	versionRepo MenuVersionRepo
+	dictClient  dictionary.Client
+	location    *time.Location // Venue time zone for visibility rules
 }
 
 type HandlerDeps struct {
-	ItemRepo   MenuItemRepo
-	MenuRepo   MenuRepo
-	DictClient dictionary.Client
+	ItemRepo    MenuItemRepo
+	MenuRepo    MenuRepo
+	VersionRepo MenuVersionRepo
+	DictClient  dictionary.Client
 }
 
 // NewHandler creates a new Handler for Menu operations
@@ -42,13 +54,21 @@ func NewHandler(hd HandlerDeps, config *apt.Config, logger apt.Logger) (*Handler
 		return nil, fmt.Errorf("dictionary service unavailable")
 	}
 
+	timezone := config.GetStringOrDef("venue.timezone", "UTC")
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid venue timezone %q: %w", timezone, err)
+	}
+
 	return &Handler{
-		config:     config,
-		logger:     logger,
-		tlm:        telemetry.NewHTTP(),
-		itemRepo:   hd.ItemRepo,
-		menuRepo:   hd.MenuRepo,
-		dictClient: hd.DictClient,
+		config:      config,
+		logger:      logger,
+		tlm:         telemetry.NewHTTP(),
+		itemRepo:    hd.ItemRepo,
+		menuRepo:    hd.MenuRepo,
+		versionRepo: hd.VersionRepo,
+		dictClient:  hd.DictClient,
+		location:    location,
 	}, nil
 }
 
@@ -60,6 +80,9 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 			r.Post("/", h.CreateMenuItem)
 			r.Get("/", h.ListMenuItems)
 			r.Get("/{id}", h.GetMenuItem)
+			r.Get("/{id}/availability", h.GetMenuItemAvailability)
+			r.Post("/{id}/modifiers/resolve", h.ResolveMenuItemModifiers)
+			r.Get("/{id}/portions/{portionID}", h.GetMenuItemPortion)
 			r.Put("/{id}", h.UpdateMenuItem)
 			r.Delete("/{id}", h.DeleteMenuItem)
 			r.Get("/code/{shortCode}", h.GetMenuItemByCode)
@@ -73,6 +96,11 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 			r.Get("/{id}", h.GetMenu)
 			r.Put("/{id}", h.UpdateMenu)
 			r.Delete("/{id}", h.DeleteMenu)
+			r.Post("/{id}/publish", h.PublishMenu)
+			r.Post("/{id}/archive", h.ArchiveMenu)
+			r.Get("/{id}/versions", h.ListMenuVersions)
+			r.Get("/{id}/versions/diff", h.DiffMenuVersions)
+			r.Get("/{id}/versions/{version}", h.GetMenuVersion)
 		})
 	})
 }
@@ -171,7 +199,166 @@ func (h *Handler) GetMenuItemByCode(w http.ResponseWriter, r *http.Request) {
 	apt.RespondSuccess(w, item, links...)
 }
 
+// GetMenuItemAvailability handles GET /menu/items/{id}/availability
+// It tells whether the item can be ordered now, or at ?at= when given.
+func (h *Handler) GetMenuItemAvailability(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.GetMenuItemAvailability")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	at := time.Now()
+	if raw := r.URL.Query().Get("at"); raw != "" {
+		parsed, err := h.parseVenueTime(raw)
+		if err != nil {
+			log.Debug("invalid at parameter", "at", raw, "error", err)
+			apt.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		at = parsed
+	}
+
+	item, err := h.itemRepo.Get(ctx, id)
+	if err != nil || item == nil {
+		log.Debug("menu item not found for availability", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Menu item not found")
+		return
+	}
+
+	menus, err := h.publishedMenus(ctx)
+	if err != nil {
+		log.Error("cannot list published menus", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not check menu item availability")
+		return
+	}
+
+	visible := ItemVisibleAt(item, menus, at, h.location)
+	availability := map[string]interface{}{
+		"menu_item_id":       item.ID,
+		"short_code":         item.ShortCode,
+		"active":             item.Active,
+		"visible":            visible,
+		"available":          item.Active && visible,
+		"requires_modifiers": requiresModifiers(item),
+		"at":                 at.In(h.location).Format(time.RFC3339),
+		"timezone":           h.location.String(),
+	}
+	// The published menu version the item would be ordered from
+	if m := VisibleMenuFor(item, menus, at, h.location); m != nil && m.PublishedVersion > 0 {
+		availability["menu_id"] = m.ID
+		availability["menu_version"] = m.PublishedVersion
+	}
+
+	apt.RespondSuccess(w, availability)
+}
+
+// ResolveMenuItemModifiers handles POST /menu/items/{id}/modifiers/resolve
+// It checks the picked options against the item's modifier groups and
+// returns them with names, price deltas and station routing.
+func (h *Handler) ResolveMenuItemModifiers(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ResolveMenuItemModifiers")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		OptionIDs    []uuid.UUID `json:"option_ids"`
+		CurrencyCode string      `json:"currency_code,omitempty"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer func() { _ = r.Body.Close() }()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	item, err := h.itemRepo.Get(ctx, id)
+	if err != nil || item == nil {
+		log.Debug("menu item not found for modifiers", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Menu item not found")
+		return
+	}
+
+	selected, errs := item.ResolveModifiers(req.OptionIDs, req.CurrencyCode)
+	if len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, e := range errs {
+			messages[i] = e.Message
+		}
+		apt.RespondError(w, http.StatusUnprocessableEntity, strings.Join(messages, "; "))
+		return
+	}
+
+	priceDelta := 0.0
+	for _, m := range selected {
+		priceDelta += m.PriceDelta
+	}
+
+	apt.RespondSuccess(w, map[string]interface{}{
+		"menu_item_id": item.ID,
+		"modifiers":    selected,
+		"price_delta":  priceDelta,
+	})
+}
+
+// GetMenuItemPortion handles GET /menu/items/{id}/portions/{portionID}
+// It returns the portion name, prep time and unit price in ?currency_code=,
+// taken from the portion override or else from the item base price.
+func (h *Handler) GetMenuItemPortion(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.GetMenuItemPortion")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	portionIDStr := chi.URLParam(r, "portionID")
+	portionID, err := uuid.Parse(portionIDStr)
+	if err != nil {
+		log.Debug("invalid portion ID", "portionID", portionIDStr)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid portion ID")
+		return
+	}
+
+	item, err := h.itemRepo.Get(ctx, id)
+	if err != nil || item == nil {
+		log.Debug("menu item not found for portion", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Menu item not found")
+		return
+	}
+
+	portion, ok := item.FindPortion(portionID)
+	if !ok {
+		apt.RespondError(w, http.StatusNotFound, "Portion not found")
+		return
+	}
+
+	selected, errs := item.ResolvePortion(portion, r.URL.Query().Get("currency_code"))
+	if len(errs) > 0 {
+		apt.RespondError(w, http.StatusUnprocessableEntity, errs[0].Message)
+		return
+	}
+
+	apt.RespondSuccess(w, selected)
+}
+
 // ListMenuItems handles GET /menu/items
+// Supports ?active=true, and ?available_at= or ?available_now=true to keep
+// only the items whose visibility rules allow them at that time.
 func (h *Handler) ListMenuItems(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.ListMenuItems")
 	defer finish()
@@ -181,8 +368,14 @@ func (h *Handler) ListMenuItems(w http.ResponseWriter, r *http.Request) {
 	// Check for active query parameter
 	activeOnly := r.URL.Query().Get("active") == "true"
 
+	availableAt, filterVisible, err := h.parseAvailabilityFilter(r)
+	if err != nil {
+		log.Debug("invalid availability filter", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var items []*MenuItem
-	var err error
 
 	if activeOnly {
 		items, err = h.itemRepo.ListActive(ctx)
@@ -196,6 +389,23 @@ func (h *Handler) ListMenuItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if filterVisible {
+		menus, err := h.publishedMenus(ctx)
+		if err != nil {
+			log.Error("cannot list published menus", "error", err)
+			apt.RespondError(w, http.StatusInternalServerError, "Could not list menu items")
+			return
+		}
+
+		visible := make([]*MenuItem, 0, len(items))
+		for _, item := range items {
+			if ItemVisibleAt(item, menus, availableAt, h.location) {
+				visible = append(visible, item)
+			}
+		}
+		items = visible
+	}
+
 	apt.RespondCollection(w, items, "menu/items")
 }
 
@@ -303,6 +513,10 @@ func (h *Handler) CreateMenu(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Menus start as drafts; publishing goes through POST /menu/menus/{id}/publish
+	menu.VersionState = MenuVersionDraft
+	menu.PublishedVersion = 0
+
 	menu.EnsureID()
 	menu.BeforeCreate()
 
@@ -354,6 +568,8 @@ func (h *Handler) GetMenu(w http.ResponseWriter, r *http.Request) {
 }
 
 // ListMenus handles GET /menu/menus
+// Supports ?published=true, and ?available_at= or ?available_now=true to
+// keep only the menus whose visibility rules allow them at that time.
 func (h *Handler) ListMenus(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.ListMenus")
 	defer finish()
@@ -363,8 +579,14 @@ func (h *Handler) ListMenus(w http.ResponseWriter, r *http.Request) {
 	// Check for published query parameter
 	publishedOnly := r.URL.Query().Get("published") == "true"
 
+	availableAt, filterVisible, err := h.parseAvailabilityFilter(r)
+	if err != nil {
+		log.Debug("invalid availability filter", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var menus []*Menu
-	var err error
 
 	if publishedOnly {
 		menus, err = h.menuRepo.ListPublished(ctx)
@@ -378,6 +600,16 @@ func (h *Handler) ListMenus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if filterVisible {
+		visible := make([]*Menu, 0, len(menus))
+		for _, m := range menus {
+			if m.VisibilityRules.VisibleAt(availableAt, h.location) {
+				visible = append(visible, m)
+			}
+		}
+		menus = visible
+	}
+
 	apt.RespondCollection(w, menus, "menu/menus")
 }
 
@@ -398,7 +630,18 @@ func (h *Handler) UpdateMenu(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	existing, err := h.menuRepo.Get(ctx, id)
+	if err != nil || existing == nil {
+		log.Debug("menu not found for update", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Menu not found")
+		return
+	}
+
+	// Edits change the working copy only; the state moves through publish and
+	// archive, and published versions stay as they were.
 	menu.ID = id
+	menu.VersionState = existing.VersionState
+	menu.PublishedVersion = existing.PublishedVersion
 	menu.BeforeUpdate()
 
 	// Validation
@@ -440,12 +683,312 @@ func (h *Handler) DeleteMenu(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Menu Version Handlers
+
+// PublishMenu handles POST /menu/menus/{id}/publish
+// It snapshots the menu and its items as the next immutable version.
+func (h *Handler) PublishMenu(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.PublishMenu")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	menu, err := h.menuRepo.Get(ctx, id)
+	if err != nil || menu == nil {
+		log.Debug("menu not found for publish", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Menu not found")
+		return
+	}
+
+	items, validationErrors := h.loadMenuItems(ctx, menu)
+	if len(validationErrors) > 0 {
+		log.Debug("menu references missing items", "errors", validationErrors)
+		h.respondValidationErrors(w, validationErrors)
+		return
+	}
+
+	versions, err := h.versionRepo.ListByMenu(ctx, id)
+	if err != nil {
+		log.Error("cannot list menu versions", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not publish menu")
+		return
+	}
+
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1].Version + 1
+	}
+
+	version := NewMenuVersion(menu, items, next)
+	if err := h.versionRepo.Create(ctx, version); err != nil {
+		log.Error("cannot create menu version", "error", err, "id", id.String(), "version", next)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not publish menu")
+		return
+	}
+
+	menu.VersionState = MenuVersionPublished
+	menu.PublishedVersion = next
+	if err := h.menuRepo.Save(ctx, menu); err != nil {
+		log.Error("cannot mark menu published", "error", err, "id", id.String(), "version", next)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not publish menu")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(version)
+	w.WriteHeader(http.StatusCreated)
+	apt.RespondSuccess(w, version, links...)
+}
+
+// ArchiveMenu handles POST /menu/menus/{id}/archive
+// Archived menus keep their published versions but are no longer offered.
+func (h *Handler) ArchiveMenu(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ArchiveMenu")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	menu, err := h.menuRepo.Get(ctx, id)
+	if err != nil || menu == nil {
+		log.Debug("menu not found for archive", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Menu not found")
+		return
+	}
+
+	if menu.VersionState == MenuVersionArchived {
+		apt.RespondError(w, http.StatusConflict, "Menu is already archived")
+		return
+	}
+
+	menu.VersionState = MenuVersionArchived
+	if err := h.menuRepo.Save(ctx, menu); err != nil {
+		log.Error("cannot archive menu", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not archive menu")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(menu)
+	apt.RespondSuccess(w, menu, links...)
+}
+
+// ListMenuVersions handles GET /menu/menus/{id}/versions
+func (h *Handler) ListMenuVersions(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListMenuVersions")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	versions, err := h.versionRepo.ListByMenu(ctx, id)
+	if err != nil {
+		log.Error("cannot list menu versions", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not list menu versions")
+		return
+	}
+
+	apt.RespondCollection(w, versions, "menu/menu-versions")
+}
+
+// GetMenuVersion handles GET /menu/menus/{id}/versions/{version}
+func (h *Handler) GetMenuVersion(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.GetMenuVersion")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	number, err := parseVersionNumber(chi.URLParam(r, "version"))
+	if err != nil {
+		log.Debug("invalid version parameter", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := h.versionRepo.Get(ctx, id, number)
+	if err != nil || version == nil {
+		log.Debug("menu version not found", "error", err, "id", id.String(), "version", number)
+		apt.RespondError(w, http.StatusNotFound, "Menu version not found")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(version)
+	apt.RespondSuccess(w, version, links...)
+}
+
+// DiffMenuVersions handles GET /menu/menus/{id}/versions/diff?from=&to=
+// to defaults to the latest version and from to the one before it.
+func (h *Handler) DiffMenuVersions(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.DiffMenuVersions")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	versions, err := h.versionRepo.ListByMenu(ctx, id)
+	if err != nil {
+		log.Error("cannot list menu versions", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not diff menu versions")
+		return
+	}
+	if len(versions) == 0 {
+		apt.RespondError(w, http.StatusNotFound, "Menu has no published versions")
+		return
+	}
+
+	to := versions[len(versions)-1].Version
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		if to, err = parseVersionNumber(raw); err != nil {
+			apt.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	from := to - 1
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		if from, err = parseVersionNumber(raw); err != nil {
+			apt.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	fromVersion, toVersion := findVersion(versions, from), findVersion(versions, to)
+	if fromVersion == nil || toVersion == nil {
+		apt.RespondError(w, http.StatusNotFound, fmt.Sprintf("Menu versions %d and %d are not both published", from, to))
+		return
+	}
+
+	apt.RespondSuccess(w, DiffMenuVersions(fromVersion, toVersion))
+}
+
 // Helper methods
 
+// publishedMenus returns the published menus as of their latest published
+// version, ordered by display order. Unpublished edits are left out.
+func (h *Handler) publishedMenus(ctx context.Context) ([]*Menu, error) {
+	menus, err := h.menuRepo.ListPublished(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	published := make([]*Menu, 0, len(menus))
+	for _, m := range menus {
+		if h.versionRepo != nil && m.PublishedVersion > 0 {
+			version, err := h.versionRepo.Get(ctx, m.ID, m.PublishedVersion)
+			if err != nil {
+				return nil, err
+			}
+			m = version.Menu
+		}
+		published = append(published, m)
+	}
+
+	sort.SliceStable(published, func(i, j int) bool {
+		return published[i].DisplayOrder < published[j].DisplayOrder
+	})
+	return published, nil
+}
+
+// loadMenuItems loads every item the menu references, in section order.
+func (h *Handler) loadMenuItems(ctx context.Context, m *Menu) ([]*MenuItem, []ValidationError) {
+	var items []*MenuItem
+	var errors []ValidationError
+	seen := make(map[uuid.UUID]bool)
+
+	for i, section := range m.Sections {
+		for j, itemID := range section.MenuItems {
+			if seen[itemID] {
+				continue
+			}
+			seen[itemID] = true
+
+			item, err := h.itemRepo.Get(ctx, itemID)
+			if err != nil || item == nil {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("sections[%d].menu_items[%d]", i, j),
+					Message: fmt.Sprintf("menu item %s not found", itemID.String()),
+				})
+				continue
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, errors
+}
+
+func findVersion(versions []*MenuVersion, number int) *MenuVersion {
+	for _, v := range versions {
+		if v.Version == number {
+			return v
+		}
+	}
+	return nil
+}
+
+func parseVersionNumber(raw string) (int, error) {
+	number, err := strconv.Atoi(raw)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid version %q", raw)
+	}
+	return number, nil
+}
+
 func (h *Handler) log(r *http.Request) apt.Logger {
 	return h.logger.With("request_id", r.Context().Value("request_id"))
 }
 
+// parseAvailabilityFilter reads ?available_at= and ?available_now=true. It
+// reports false when neither is set.
+func (h *Handler) parseAvailabilityFilter(r *http.Request) (time.Time, bool, error) {
+	query := r.URL.Query()
+
+	if raw := strings.TrimSpace(query.Get("available_at")); raw != "" {
+		at, err := h.parseVenueTime(raw)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return at, true, nil
+	}
+
+	if query.Get("available_now") == "true" {
+		return time.Now(), true, nil
+	}
+
+	return time.Time{}, false, nil
+}
+
+// parseVenueTime accepts RFC3339, or a wall-clock time without offset that
+// is read in the venue time zone.
+func (h *Handler) parseVenueTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(availableAtLayout, raw, h.location); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC3339 or YYYY-MM-DDTHH:MM", raw)
+}
+
 func (h *Handler) parseIDParam(w http.ResponseWriter, r *http.Request, log apt.Logger) (uuid.UUID, bool) {
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
@@ -21,18 +21,19 @@ const (
 
 // Menu is a container of items and combos presented to end users
 type Menu struct {
-	ID              uuid.UUID         `json:"id" bson:"_id"`
-	Name            map[string]string `json:"name" bson:"name"`                       // Localized names
-	Description     map[string]string `json:"description" bson:"description"`         // Localized descriptions
-	Sections        []MenuSection     `json:"sections" bson:"sections"`               // Organized by categories
-	VersionState    MenuVersionState  `json:"version_state" bson:"version_state"`     // draft/published/archived
-	VisibilityRules VisibilityRules   `json:"visibility_rules" bson:"visibility_rules"` // Optional visibility windows
-	DisplayOrder    int               `json:"display_order" bson:"display_order"`     // Ordering for multiple menus
-	SchemaVersion   int               `json:"schema_version" bson:"schema_version"`   // Model versioning
-	CreatedAt       time.Time         `json:"created_at" bson:"created_at"`
-	CreatedBy       string            `json:"created_by" bson:"created_by"`
-	UpdatedAt       time.Time         `json:"updated_at" bson:"updated_at"`
-	UpdatedBy       string            `json:"updated_by" bson:"updated_by"`
+	ID               uuid.UUID         `json:"id" bson:"_id"`
+	Name             map[string]string `json:"name" bson:"name"`                                               // Localized names
+	Description      map[string]string `json:"description" bson:"description"`                                 // Localized descriptions
+	Sections         []MenuSection     `json:"sections" bson:"sections"`                                       // Organized by categories
+	VersionState     MenuVersionState  `json:"version_state" bson:"version_state"`                             // draft/published/archived
+	PublishedVersion int               `json:"published_version,omitempty" bson:"published_version,omitempty"` // Latest published snapshot
+	VisibilityRules  VisibilityRules   `json:"visibility_rules" bson:"visibility_rules"`                       // Optional visibility windows
+	DisplayOrder     int               `json:"display_order" bson:"display_order"`                             // Ordering for multiple menus
+	SchemaVersion    int               `json:"schema_version" bson:"schema_version"`                           // Model versioning
+	CreatedAt        time.Time         `json:"created_at" bson:"created_at"`
+	CreatedBy        string            `json:"created_by" bson:"created_by"`
+	UpdatedAt        time.Time         `json:"updated_at" bson:"updated_at"`
+	UpdatedBy        string            `json:"updated_by" bson:"updated_by"`
 }
 
 // MenuSection represents a section within a menu organized by category
@@ -112,18 +113,19 @@ func (m *Menu) MarshalBSON() ([]byte, error) {
 	}
 
 	return bson.Marshal(bson.M{
-		"_id":              m.ID.String(),
-		"name":             m.Name,
-		"description":      m.Description,
-		"sections":         sections,
-		"version_state":    string(m.VersionState),
-		"visibility_rules": m.VisibilityRules,
-		"display_order":    m.DisplayOrder,
-		"schema_version":   m.SchemaVersion,
-		"created_at":       m.CreatedAt,
-		"created_by":       m.CreatedBy,
-		"updated_at":       m.UpdatedAt,
-		"updated_by":       m.UpdatedBy,
+		"_id":               m.ID.String(),
+		"name":              m.Name,
+		"description":       m.Description,
+		"sections":          sections,
+		"version_state":     string(m.VersionState),
+		"published_version": m.PublishedVersion,
+		"visibility_rules":  m.VisibilityRules,
+		"display_order":     m.DisplayOrder,
+		"schema_version":    m.SchemaVersion,
+		"created_at":        m.CreatedAt,
+		"created_by":        m.CreatedBy,
+		"updated_at":        m.UpdatedAt,
+		"updated_by":        m.UpdatedBy,
 	})
 }
 
@@ -203,6 +205,12 @@ func (m *Menu) UnmarshalBSON(data []byte) error {
 		m.VersionState = MenuVersionState(v)
 	}
 
+	if v, ok := doc["published_version"].(int32); ok {
+		m.PublishedVersion = int(v)
+	} else if v, ok := doc["published_version"].(int64); ok {
+		m.PublishedVersion = int(v)
+	}
+
 	// Parse visibility rules
 	if visMap, ok := doc["visibility_rules"].(bson.M); ok {
 		// Parse time of day
@@ -0,0 +1,150 @@
+package menu
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// MenuVersionDiff lists what changed between two published versions of a menu
+type MenuVersionDiff struct {
+	MenuID       uuid.UUID     `json:"menu_id"`
+	From         int           `json:"from"`
+	To           int           `json:"to"`
+	Menu         []FieldChange `json:"menu"`          // Changes on the menu itself
+	AddedItems   []ItemRef     `json:"added_items"`   // Items only in To
+	RemovedItems []ItemRef     `json:"removed_items"` // Items only in From
+	ChangedItems []ItemChange  `json:"changed_items"` // Items in both that differ
+}
+
+// FieldChange is a field whose value differs between two versions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// ItemRef identifies a menu item inside a diff
+type ItemRef struct {
+	ID        uuid.UUID `json:"id"`
+	ShortCode string    `json:"short_code"`
+}
+
+// ItemChange lists the field changes of one menu item
+type ItemChange struct {
+	ItemRef
+	Changes []FieldChange `json:"changes"`
+}
+
+// Empty reports whether both versions are equivalent.
+func (d *MenuVersionDiff) Empty() bool {
+	return len(d.Menu) == 0 && len(d.AddedItems) == 0 && len(d.RemovedItems) == 0 && len(d.ChangedItems) == 0
+}
+
+// DiffMenuVersions compares two versions of the same menu. Bookkeeping fields
+// such as timestamps are ignored.
+func DiffMenuVersions(from, to *MenuVersion) *MenuVersionDiff {
+	diff := &MenuVersionDiff{
+		MenuID:       to.MenuID,
+		From:         from.Version,
+		To:           to.Version,
+		Menu:         diffFields(menuDiffFields(from.Menu), menuDiffFields(to.Menu)),
+		AddedItems:   []ItemRef{},
+		RemovedItems: []ItemRef{},
+		ChangedItems: []ItemChange{},
+	}
+
+	for _, item := range to.Items {
+		old := from.Item(item.ID)
+		if old == nil {
+			diff.AddedItems = append(diff.AddedItems, itemRef(item))
+			continue
+		}
+		if changes := diffFields(itemDiffFields(old), itemDiffFields(item)); len(changes) > 0 {
+			diff.ChangedItems = append(diff.ChangedItems, ItemChange{ItemRef: itemRef(item), Changes: changes})
+		}
+	}
+
+	for _, item := range from.Items {
+		if to.Item(item.ID) == nil {
+			diff.RemovedItems = append(diff.RemovedItems, itemRef(item))
+		}
+	}
+
+	return diff
+}
+
+func itemRef(item *MenuItem) ItemRef {
+	return ItemRef{ID: item.ID, ShortCode: item.ShortCode}
+}
+
+func menuDiffFields(m *Menu) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"name":             m.Name,
+		"description":      m.Description,
+		"sections":         m.Sections,
+		"visibility_rules": m.VisibilityRules,
+		"display_order":    m.DisplayOrder,
+	}
+}
+
+func itemDiffFields(item *MenuItem) map[string]interface{} {
+	return map[string]interface{}{
+		"short_code":       item.ShortCode,
+		"name":             item.Name,
+		"description":      item.Description,
+		"prices":           item.Prices,
+		"active":           item.Active,
+		"portions":         item.Portions,
+		"modifier_groups":  item.ModifierGroups,
+		"allergens":        item.Allergens,
+		"dietary_options":  item.DietaryOptions,
+		"cuisine_types":    item.CuisineTypes,
+		"categories":       item.Categories,
+		"tags":             item.Tags,
+		"ingredients":      item.Ingredients,
+		"images":           item.Images,
+		"visibility_rules": item.VisibilityRules,
+		"display_order":    item.DisplayOrder,
+	}
+}
+
+// diffFields compares values by their JSON form, so nil and empty
+// collections that render the same are not reported.
+func diffFields(from, to map[string]interface{}) []FieldChange {
+	fields := make([]string, 0, len(to))
+	for field := range to {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []FieldChange{}
+	for _, field := range fields {
+		if !sameJSON(from[field], to[field]) {
+			changes = append(changes, FieldChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+	return changes
+}
+
+func sameJSON(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return bytes.Equal(normalizeEmptyJSON(ja), normalizeEmptyJSON(jb))
+}
+
+func normalizeEmptyJSON(raw []byte) []byte {
+	switch string(raw) {
+	case "[]", "{}":
+		return []byte("null")
+	}
+	return raw
+}
@@ -19,6 +19,7 @@ type MenuItem struct {
 	Prices          []Price           `json:"prices" bson:"prices"`                       // Multi-currency support
 	Active          bool              `json:"active" bson:"active"`                       // Available for ordering
 	Portions        []Portion         `json:"portions" bson:"portions"`                   // Multiple portion options
+	ModifierGroups  []ModifierGroup   `json:"modifier_groups" bson:"modifier_groups"`     // Structured choices like extras or cooking point
 	Allergens       []uuid.UUID       `json:"allergens" bson:"allergens"`                 // Ref: Dictionary allergens
 	DietaryOptions  []uuid.UUID       `json:"dietary_options" bson:"dietary_options"`     // Ref: Dictionary dietary
 	CuisineTypes    []uuid.UUID       `json:"cuisine_types" bson:"cuisine_types"`         // Ref: Dictionary cuisine_type
@@ -98,6 +99,17 @@ func (m *MenuItem) EnsureID() {
 			m.Portions[i].ID = uuid.New()
 		}
 	}
+	// Ensure modifier groups and their options have IDs
+	for i := range m.ModifierGroups {
+		if m.ModifierGroups[i].ID == uuid.Nil {
+			m.ModifierGroups[i].ID = uuid.New()
+		}
+		for j := range m.ModifierGroups[i].Options {
+			if m.ModifierGroups[i].Options[j].ID == uuid.Nil {
+				m.ModifierGroups[i].Options[j].ID = uuid.New()
+			}
+		}
+	}
 }
 
 // GetID returns the menu item ID
@@ -197,6 +209,7 @@ func (m *MenuItem) MarshalBSON() ([]byte, error) {
 		"prices":           m.Prices,
 		"active":           m.Active,
 		"portions":         portions,
+		"modifier_groups":  modifierGroupsToBSON(m.ModifierGroups),
 		"allergens":        allergens,
 		"dietary_options":  dietary,
 		"cuisine_types":    cuisines,
@@ -332,6 +345,11 @@ func (m *MenuItem) UnmarshalBSON(data []byte) error {
 		}
 	}
 
+	// Parse modifier groups
+	if groupsArr, ok := doc["modifier_groups"].(bson.A); ok {
+		m.ModifierGroups = parseModifierGroups(groupsArr)
+	}
+
 	// Parse allergens (UUID array)
 	if allergensArr, ok := doc["allergens"].(bson.A); ok {
 		m.Allergens = make([]uuid.UUID, 0, len(allergensArr))
@@ -0,0 +1,113 @@
+package menu
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const CurrentMenuVersionSchemaVersion = 1
+
+// MenuVersion is an immutable snapshot of a menu and the items it referenced
+// when it was published. Orders point at it so past prices can be replayed.
+type MenuVersion struct {
+	ID            uuid.UUID   `json:"id" bson:"_id"`
+	MenuID        uuid.UUID   `json:"menu_id" bson:"menu_id"`
+	Version       int         `json:"version" bson:"version"` // 1-based, per menu
+	Menu          *Menu       `json:"menu" bson:"menu"`
+	Items         []*MenuItem `json:"items" bson:"items"`
+	SchemaVersion int         `json:"schema_version" bson:"schema_version"`
+	PublishedAt   time.Time   `json:"published_at" bson:"published_at"`
+	PublishedBy   string      `json:"published_by" bson:"published_by"`
+}
+
+// NewMenuVersion snapshots the menu and its items as the given version.
+func NewMenuVersion(m *Menu, items []*MenuItem, version int) *MenuVersion {
+	menuCopy := *m
+	menuCopy.VersionState = MenuVersionPublished
+	menuCopy.PublishedVersion = version
+
+	return &MenuVersion{
+		ID:            uuid.New(),
+		MenuID:        m.ID,
+		Version:       version,
+		Menu:          &menuCopy,
+		Items:         items,
+		SchemaVersion: CurrentMenuVersionSchemaVersion,
+		PublishedAt:   time.Now(),
+		PublishedBy:   m.UpdatedBy,
+	}
+}
+
+// GetID returns the menu version ID
+func (v *MenuVersion) GetID() uuid.UUID {
+	return v.ID
+}
+
+// ResourceType returns the resource type for URL generation
+func (v *MenuVersion) ResourceType() string {
+	return "menu/menu-version"
+}
+
+// Item returns the snapshot of the given item, or nil if the version does not
+// include it.
+func (v *MenuVersion) Item(id uuid.UUID) *MenuItem {
+	for _, item := range v.Items {
+		if item.ID == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// MarshalBSON custom BSON marshaling for UUID handling
+func (v *MenuVersion) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(bson.M{
+		"_id":            v.ID.String(),
+		"menu_id":        v.MenuID.String(),
+		"version":        v.Version,
+		"menu":           v.Menu,
+		"items":          v.Items,
+		"schema_version": v.SchemaVersion,
+		"published_at":   v.PublishedAt,
+		"published_by":   v.PublishedBy,
+	})
+}
+
+// UnmarshalBSON custom BSON unmarshaling for UUID handling
+func (v *MenuVersion) UnmarshalBSON(data []byte) error {
+	var doc struct {
+		ID            string      `bson:"_id"`
+		MenuID        string      `bson:"menu_id"`
+		Version       int         `bson:"version"`
+		Menu          *Menu       `bson:"menu"`
+		Items         []*MenuItem `bson:"items"`
+		SchemaVersion int         `bson:"schema_version"`
+		PublishedAt   time.Time   `bson:"published_at"`
+		PublishedBy   string      `bson:"published_by"`
+	}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format for _id: %w", err)
+	}
+	menuID, err := uuid.Parse(doc.MenuID)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format for menu_id: %w", err)
+	}
+
+	v.ID = id
+	v.MenuID = menuID
+	v.Version = doc.Version
+	v.Menu = doc.Menu
+	v.Items = doc.Items
+	v.SchemaVersion = doc.SchemaVersion
+	v.PublishedAt = doc.PublishedAt
+	v.PublishedBy = doc.PublishedBy
+	return nil
+}
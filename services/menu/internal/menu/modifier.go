@@ -0,0 +1,362 @@
+package menu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ModifierGroup is a set of options picked when ordering an item, such as
+// "Cooking point" or "Extras"
+type ModifierGroup struct {
+	ID            uuid.UUID         `json:"id" bson:"id"`
+	Name          map[string]string `json:"name" bson:"name"`                     // Localized group name
+	MinSelections int               `json:"min_selections" bson:"min_selections"` // Fewest options to pick
+	MaxSelections int               `json:"max_selections" bson:"max_selections"` // Most options to pick, 0 for no limit
+	Required      bool              `json:"required" bson:"required"`             // At least one pick even if MinSelections is 0
+	Options       []ModifierOption  `json:"options" bson:"options"`
+}
+
+// ModifierOption is a single choice within a modifier group
+type ModifierOption struct {
+	ID                uuid.UUID         `json:"id" bson:"id"`
+	Name              map[string]string `json:"name" bson:"name"`                                                 // Localized option name
+	PriceDeltas       []Price           `json:"price_deltas,omitempty" bson:"price_deltas,omitempty"`             // Added to the item price, per currency
+	ProductionStation string            `json:"production_station,omitempty" bson:"production_station,omitempty"` // Routes the item to another station
+	Active            bool              `json:"active" bson:"active"`
+}
+
+// SelectedModifier is an option chosen for an order, resolved against the
+// item so orders keep the names and price they were taken with
+type SelectedModifier struct {
+	GroupID           uuid.UUID `json:"group_id"`
+	GroupName         string    `json:"group_name"`
+	OptionID          uuid.UUID `json:"option_id"`
+	OptionName        string    `json:"option_name"`
+	PriceDelta        float64   `json:"price_delta"`
+	CurrencyCode      string    `json:"currency_code,omitempty"`
+	ProductionStation string    `json:"production_station,omitempty"`
+}
+
+// MinRequired returns how many options must be picked from the group.
+func (g ModifierGroup) MinRequired() int {
+	if g.Required && g.MinSelections < 1 {
+		return 1
+	}
+	return g.MinSelections
+}
+
+// requiresModifiers reports whether the item cannot be ordered without
+// picking some options.
+func requiresModifiers(item *MenuItem) bool {
+	for _, group := range item.ModifierGroups {
+		if group.MinRequired() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PriceDelta returns the option delta in the given currency. Options without
+// deltas are free in every currency.
+func (o ModifierOption) PriceDelta(currency string) (float64, bool) {
+	if len(o.PriceDeltas) == 0 {
+		return 0, true
+	}
+	for _, p := range o.PriceDeltas {
+		if strings.EqualFold(p.CurrencyCode, currency) {
+			return p.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// ResolveModifiers checks the picked options against the item's modifier
+// groups and returns them in group order. Currency defaults to the item's
+// first price currency.
+func (m *MenuItem) ResolveModifiers(optionIDs []uuid.UUID, currency string) ([]SelectedModifier, []ValidationError) {
+	var errors []ValidationError
+
+	if currency == "" && len(m.Prices) > 0 {
+		currency = m.Prices[0].CurrencyCode
+	}
+
+	picked := make(map[uuid.UUID]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if picked[id] {
+			errors = append(errors, ValidationError{
+				Field:   "modifiers",
+				Message: fmt.Sprintf("option %s selected more than once", id),
+			})
+		}
+		picked[id] = true
+	}
+
+	selected := []SelectedModifier{}
+	found := make(map[uuid.UUID]bool, len(optionIDs))
+	for i, group := range m.ModifierGroups {
+		count := 0
+		for _, option := range group.Options {
+			if !picked[option.ID] {
+				continue
+			}
+			found[option.ID] = true
+			count++
+
+			if !option.Active {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("modifier_groups[%d]", i),
+					Message: fmt.Sprintf("%s is not available", localizedName(option.Name)),
+				})
+				continue
+			}
+			delta, ok := option.PriceDelta(currency)
+			if !ok {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("modifier_groups[%d]", i),
+					Message: fmt.Sprintf("%s has no price in %s", localizedName(option.Name), currency),
+				})
+				continue
+			}
+			selected = append(selected, SelectedModifier{
+				GroupID:           group.ID,
+				GroupName:         localizedName(group.Name),
+				OptionID:          option.ID,
+				OptionName:        localizedName(option.Name),
+				PriceDelta:        delta,
+				CurrencyCode:      currency,
+				ProductionStation: option.ProductionStation,
+			})
+		}
+
+		if min := group.MinRequired(); count < min {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("modifier_groups[%d]", i),
+				Message: fmt.Sprintf("pick at least %d for %s", min, localizedName(group.Name)),
+			})
+		}
+		if group.MaxSelections > 0 && count > group.MaxSelections {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("modifier_groups[%d]", i),
+				Message: fmt.Sprintf("pick at most %d for %s", group.MaxSelections, localizedName(group.Name)),
+			})
+		}
+	}
+
+	for _, id := range optionIDs {
+		if !found[id] {
+			errors = append(errors, ValidationError{
+				Field:   "modifiers",
+				Message: fmt.Sprintf("option %s does not belong to this item", id),
+			})
+			found[id] = true
+		}
+	}
+
+	return selected, errors
+}
+
+// validateModifierGroups checks the modifier group definitions of an item
+func validateModifierGroups(groups []ModifierGroup) []ValidationError {
+	var errors []ValidationError
+
+	for i, group := range groups {
+		field := fmt.Sprintf("modifier_groups[%d]", i)
+		if len(group.Name) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   field + ".name",
+				Message: "modifier group name is required",
+			})
+		}
+		if len(group.Options) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   field + ".options",
+				Message: "modifier group needs at least one option",
+			})
+		}
+		if group.MinSelections < 0 {
+			errors = append(errors, ValidationError{
+				Field:   field + ".min_selections",
+				Message: "min_selections cannot be negative",
+			})
+		}
+		if group.MaxSelections < 0 {
+			errors = append(errors, ValidationError{
+				Field:   field + ".max_selections",
+				Message: "max_selections cannot be negative",
+			})
+		} else if group.MaxSelections > 0 && group.MaxSelections < group.MinRequired() {
+			errors = append(errors, ValidationError{
+				Field:   field + ".max_selections",
+				Message: "max_selections cannot be lower than min_selections",
+			})
+		}
+		if group.MinRequired() > len(group.Options) {
+			errors = append(errors, ValidationError{
+				Field:   field + ".min_selections",
+				Message: "min_selections cannot exceed the number of options",
+			})
+		}
+
+		for j, option := range group.Options {
+			optionField := fmt.Sprintf("%s.options[%d]", field, j)
+			if len(option.Name) == 0 {
+				errors = append(errors, ValidationError{
+					Field:   optionField + ".name",
+					Message: "option name is required",
+				})
+			}
+			for k, price := range option.PriceDeltas {
+				if price.CurrencyCode == "" {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.price_deltas[%d].currency_code", optionField, k),
+						Message: "currency code is required",
+					})
+				} else if len(price.CurrencyCode) != 3 {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.price_deltas[%d].currency_code", optionField, k),
+						Message: "currency code must be 3 characters (ISO 4217)",
+					})
+				}
+			}
+		}
+	}
+
+	return errors
+}
+
+// localizedName picks the English name, falling back to the first language
+// in alphabetical order so the choice is stable.
+func localizedName(names map[string]string) string {
+	if name := names["en"]; name != "" {
+		return name
+	}
+	langs := make([]string, 0, len(names))
+	for lang := range names {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		if names[lang] != "" {
+			return names[lang]
+		}
+	}
+	return ""
+}
+
+func modifierGroupsToBSON(groups []ModifierGroup) []bson.M {
+	docs := make([]bson.M, len(groups))
+	for i, g := range groups {
+		options := make([]bson.M, len(g.Options))
+		for j, o := range g.Options {
+			options[j] = bson.M{
+				"id":                 o.ID.String(),
+				"name":               o.Name,
+				"price_deltas":       o.PriceDeltas,
+				"production_station": o.ProductionStation,
+				"active":             o.Active,
+			}
+		}
+		docs[i] = bson.M{
+			"id":             g.ID.String(),
+			"name":           g.Name,
+			"min_selections": g.MinSelections,
+			"max_selections": g.MaxSelections,
+			"required":       g.Required,
+			"options":        options,
+		}
+	}
+	return docs
+}
+
+func parseModifierGroups(arr bson.A) []ModifierGroup {
+	groups := make([]ModifierGroup, 0, len(arr))
+	for _, g := range arr {
+		groupMap, ok := g.(bson.M)
+		if !ok {
+			continue
+		}
+
+		var group ModifierGroup
+		if idStr, ok := groupMap["id"].(string); ok {
+			group.ID, _ = uuid.Parse(idStr)
+		}
+		group.Name = parseStringMap(groupMap["name"])
+		group.MinSelections = parseInt(groupMap["min_selections"])
+		group.MaxSelections = parseInt(groupMap["max_selections"])
+		if v, ok := groupMap["required"].(bool); ok {
+			group.Required = v
+		}
+
+		if optionsArr, ok := groupMap["options"].(bson.A); ok {
+			group.Options = make([]ModifierOption, 0, len(optionsArr))
+			for _, o := range optionsArr {
+				optionMap, ok := o.(bson.M)
+				if !ok {
+					continue
+				}
+
+				var option ModifierOption
+				if idStr, ok := optionMap["id"].(string); ok {
+					option.ID, _ = uuid.Parse(idStr)
+				}
+				option.Name = parseStringMap(optionMap["name"])
+				if pricesArr, ok := optionMap["price_deltas"].(bson.A); ok {
+					option.PriceDeltas = parsePrices(pricesArr)
+				}
+				if v, ok := optionMap["production_station"].(string); ok {
+					option.ProductionStation = v
+				}
+				if v, ok := optionMap["active"].(bool); ok {
+					option.Active = v
+				}
+				group.Options = append(group.Options, option)
+			}
+		}
+
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func parsePrices(arr bson.A) []Price {
+	prices := make([]Price, len(arr))
+	for i, p := range arr {
+		if priceMap, ok := p.(bson.M); ok {
+			if amount, ok := priceMap["amount"].(float64); ok {
+				prices[i].Amount = amount
+			}
+			if currency, ok := priceMap["currency_code"].(string); ok {
+				prices[i].CurrencyCode = currency
+			}
+		}
+	}
+	return prices
+}
+
+func parseStringMap(v interface{}) map[string]string {
+	raw, ok := v.(bson.M)
+	if !ok {
+		return nil
+	}
+	result := make(map[string]string, len(raw))
+	for k, value := range raw {
+		if str, ok := value.(string); ok {
+			result[k] = str
+		}
+	}
+	return result
+}
+
+func parseInt(v interface{}) int {
+	switch n := v.(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	}
+	return 0
+}
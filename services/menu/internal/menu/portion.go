@@ -0,0 +1,77 @@
+package menu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// SelectedPortion is a portion chosen for an order, resolved against the item
+// so orders keep the name, unit price and prep time they were taken with
+type SelectedPortion struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Price        float64   `json:"price"`
+	CurrencyCode string    `json:"currency_code"`
+	PrepTime     int       `json:"prep_time"` // Minutes
+}
+
+// FindPortion returns the item portion with the given ID.
+func (m *MenuItem) FindPortion(id uuid.UUID) (*Portion, bool) {
+	for i := range m.Portions {
+		if m.Portions[i].ID == id {
+			return &m.Portions[i], true
+		}
+	}
+	return nil, false
+}
+
+// PriceIn returns the item base price in the given currency.
+func (m *MenuItem) PriceIn(currency string) (float64, bool) {
+	return priceIn(m.Prices, currency)
+}
+
+// ResolvePortion prices the portion in the given currency, using its override
+// when it has one and the item base price otherwise. Currency defaults to the
+// item's first price currency.
+func (m *MenuItem) ResolvePortion(portion *Portion, currency string) (*SelectedPortion, []ValidationError) {
+	if currency == "" && len(m.Prices) > 0 {
+		currency = m.Prices[0].CurrencyCode
+	}
+
+	if !portion.Active {
+		return nil, []ValidationError{{
+			Field:   "portion_id",
+			Message: fmt.Sprintf("%s is not available", localizedName(portion.Name)),
+		}}
+	}
+
+	price, ok := priceIn(portion.PriceOverride, currency)
+	if !ok {
+		price, ok = m.PriceIn(currency)
+	}
+	if !ok {
+		return nil, []ValidationError{{
+			Field:   "portion_id",
+			Message: fmt.Sprintf("%s has no price in %s", localizedName(portion.Name), currency),
+		}}
+	}
+
+	return &SelectedPortion{
+		ID:           portion.ID,
+		Name:         localizedName(portion.Name),
+		Price:        price,
+		CurrencyCode: currency,
+		PrepTime:     portion.PrepTime,
+	}, nil
+}
+
+func priceIn(prices []Price, currency string) (float64, bool) {
+	for _, p := range prices {
+		if strings.EqualFold(p.CurrencyCode, currency) {
+			return p.Amount, true
+		}
+	}
+	return 0, false
+}
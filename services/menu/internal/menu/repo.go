@@ -27,3 +27,10 @@ type MenuRepo interface {
 	Save(ctx context.Context, menu *Menu) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
+
+// MenuVersionRepo stores the immutable snapshots taken when menus are published
+type MenuVersionRepo interface {
+	Create(ctx context.Context, version *MenuVersion) error
+	Get(ctx context.Context, menuID uuid.UUID, version int) (*MenuVersion, error)
+	ListByMenu(ctx context.Context, menuID uuid.UUID) ([]*MenuVersion, error)
+}
@@ -107,6 +107,9 @@ func ValidateCreateMenuItem(ctx context.Context, item *MenuItem, dictClient dict
 		}
 	}
 
+	// Validate modifier groups
+	errors = append(errors, validateModifierGroups(item.ModifierGroups)...)
+
 	// Validate ingredients
 	for i, ing := range item.Ingredients {
 		if strings.TrimSpace(ing.Name) == "" {
@@ -117,6 +120,9 @@ func ValidateCreateMenuItem(ctx context.Context, item *MenuItem, dictClient dict
 		}
 	}
 
+	// Validate visibility rules
+	errors = append(errors, validateVisibilityRules(item.VisibilityRules)...)
+
 	// Validate dictionary references if client is provided
 	if dictClient != nil {
 		// Validate allergens
@@ -211,6 +217,9 @@ func ValidateCreateMenu(ctx context.Context, m *Menu, dictClient dictionary.Clie
 		}
 	}
 
+	// Validate visibility rules
+	errors = append(errors, validateVisibilityRules(m.VisibilityRules)...)
+
 	// Validate sections
 	for i, section := range m.Sections {
 		// Validate category reference
@@ -241,3 +250,44 @@ func ValidateUpdateMenu(ctx context.Context, m *Menu, dictClient dictionary.Clie
 
 	return errors
 }
+
+// validateVisibilityRules checks the time windows, weekdays and date ranges
+// shared by menus and menu items
+func validateVisibilityRules(rules VisibilityRules) []ValidationError {
+	var errors []ValidationError
+
+	for i, tw := range rules.TimeOfDay {
+		if _, err := parseTimeOfDay(tw.Start); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("visibility_rules.time_of_day[%d].start", i),
+				Message: err.Error(),
+			})
+		}
+		if _, err := parseTimeOfDay(tw.End); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("visibility_rules.time_of_day[%d].end", i),
+				Message: err.Error(),
+			})
+		}
+	}
+
+	for i, day := range rules.DaysOfWeek {
+		if day < 0 || day > 6 {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("visibility_rules.days_of_week[%d]", i),
+				Message: "day must be between 0 (Sunday) and 6 (Saturday)",
+			})
+		}
+	}
+
+	for i, dr := range rules.DateRanges {
+		if !dr.Start.IsZero() && !dr.End.IsZero() && dr.End.Before(dr.Start) {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("visibility_rules.date_ranges[%d].end", i),
+				Message: "end cannot be before start",
+			})
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,150 @@
+package menu
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const timeOfDayLayout = "15:04"
+
+// IsEmpty reports whether the rules place no restriction at all.
+func (v VisibilityRules) IsEmpty() bool {
+	return len(v.TimeOfDay) == 0 && len(v.DaysOfWeek) == 0 && len(v.DateRanges) == 0
+}
+
+// VisibleAt reports whether the rules allow showing the owner at t, evaluated
+// on the venue's wall clock. Each kind of rule is optional; when present, at
+// least one of its entries must match.
+func (v VisibilityRules) VisibleAt(t time.Time, loc *time.Location) bool {
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+
+	if len(v.DaysOfWeek) > 0 && !containsDay(v.DaysOfWeek, int(local.Weekday())) {
+		return false
+	}
+
+	if len(v.DateRanges) > 0 {
+		inRange := false
+		for _, dr := range v.DateRanges {
+			if dr.Contains(local) {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return false
+		}
+	}
+
+	if len(v.TimeOfDay) > 0 {
+		minute := local.Hour()*60 + local.Minute()
+		for _, tw := range v.TimeOfDay {
+			if tw.Contains(minute) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// Contains reports whether the minute of the day falls in the window. The
+// end is exclusive; a window ending before it starts wraps past midnight.
+func (tw TimeWindow) Contains(minute int) bool {
+	start, err := parseTimeOfDay(tw.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(tw.End)
+	if err != nil {
+		return false
+	}
+
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// Contains reports whether the calendar day of local falls in the range.
+// Range bounds are calendar dates, so both days are inclusive whatever the
+// time zone they were stored in.
+func (dr DateRange) Contains(local time.Time) bool {
+	day := calendarDay(local)
+	if !dr.Start.IsZero() && day < calendarDay(dr.Start) {
+		return false
+	}
+	if !dr.End.IsZero() && day > calendarDay(dr.End) {
+		return false
+	}
+	return true
+}
+
+// ItemVisibleAt reports whether an item can be shown and ordered at t. Its
+// own rules must allow it and, when it belongs to any of the given published
+// menus, at least one of those menus must be visible too.
+func ItemVisibleAt(item *MenuItem, menus []*Menu, t time.Time, loc *time.Location) bool {
+	if !item.VisibilityRules.VisibleAt(t, loc) {
+		return false
+	}
+	if VisibleMenuFor(item, menus, t, loc) != nil {
+		return true
+	}
+
+	for _, m := range menus {
+		if m.Contains(item.ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// VisibleMenuFor returns the first of the given menus that lists the item
+// and is visible at t, or nil when there is none.
+func VisibleMenuFor(item *MenuItem, menus []*Menu, t time.Time, loc *time.Location) *Menu {
+	for _, m := range menus {
+		if m.Contains(item.ID) && m.VisibilityRules.VisibleAt(t, loc) {
+			return m
+		}
+	}
+	return nil
+}
+
+// Contains reports whether the menu lists the item in any section.
+func (m *Menu) Contains(itemID uuid.UUID) bool {
+	for _, section := range m.Sections {
+		for _, id := range section.MenuItems {
+			if id == itemID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse(timeOfDayLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func calendarDay(t time.Time) int {
+	y, m, d := t.Date()
+	return y*10000 + int(m)*100 + d
+}
+
+func containsDay(days []int, day int) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/appetiteclub/appetite/services/menu/internal/menu"
+	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MenuVersionRepo implements the menu.MenuVersionRepo interface using MongoDB.
+// Versions are insert-only: there is no update or delete.
+type MenuVersionRepo struct {
+	itemRepo   *MenuItemRepo
+	collection *mongo.Collection
+	logger     apt.Logger
+}
+
+// NewMenuVersionRepo creates a new MongoDB menu version repository
+func NewMenuVersionRepo(itemRepo *MenuItemRepo, logger apt.Logger) *MenuVersionRepo {
+	return &MenuVersionRepo{
+		itemRepo: itemRepo,
+		logger:   logger,
+	}
+}
+
+// Start initializes the menu version repository (uses same DB as MenuItemRepo)
+func (r *MenuVersionRepo) Start(ctx context.Context) error {
+	if r.itemRepo == nil || r.itemRepo.db == nil {
+		return fmt.Errorf("menu item repository must be started first")
+	}
+
+	r.collection = r.itemRepo.db.Collection("menu_versions")
+
+	// One document per menu and version number
+	versionIndexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "menu_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := r.collection.Indexes().CreateOne(ctx, versionIndexModel); err != nil {
+		return fmt.Errorf("cannot create menu_id/version index: %w", err)
+	}
+
+	r.logger.Info("Menu version repository initialized with collection: menu_versions")
+	return nil
+}
+
+// Stop is a no-op for MenuVersionRepo since connection is managed by MenuItemRepo
+func (r *MenuVersionRepo) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Create inserts a new menu version. A concurrent publish of the same version
+// number fails on the unique index.
+func (r *MenuVersionRepo) Create(ctx context.Context, v *menu.MenuVersion) error {
+	if v == nil {
+		return fmt.Errorf("menu version cannot be nil")
+	}
+
+	_, err := r.collection.InsertOne(ctx, v)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("menu %s version %d already exists", v.MenuID.String(), v.Version)
+		}
+		return fmt.Errorf("could not create menu version: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a version of a menu
+func (r *MenuVersionRepo) Get(ctx context.Context, menuID uuid.UUID, version int) (*menu.MenuVersion, error) {
+	var v menu.MenuVersion
+
+	filter := bson.M{"menu_id": menuID.String(), "version": version}
+	err := r.collection.FindOne(ctx, filter).Decode(&v)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("menu %s version %d not found", menuID.String(), version)
+		}
+		return nil, fmt.Errorf("could not get menu version: %w", err)
+	}
+	return &v, nil
+}
+
+// ListByMenu retrieves all versions of a menu, oldest first
+func (r *MenuVersionRepo) ListByMenu(ctx context.Context, menuID uuid.UUID) ([]*menu.MenuVersion, error) {
+	filter := bson.M{"menu_id": menuID.String()}
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list menu versions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	versions := []*menu.MenuVersion{}
+	for cursor.Next(ctx) {
+		var v menu.MenuVersion
+		if err := cursor.Decode(&v); err != nil {
+			return nil, fmt.Errorf("could not decode menu version: %w", err)
+		}
+		versions = append(versions, &v)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+	return versions, nil
+}
@@ -41,15 +41,17 @@ func main() {
 	// Initialize repositories
 	itemRepo := mongo.NewMenuItemRepo(config, logger)
 	menuRepo := mongo.NewMenuRepo(itemRepo, logger)
+	versionRepo := mongo.NewMenuVersionRepo(itemRepo, logger)
 
 	// Initialize dictionary client
 	dictURL := config.GetStringOrDef("services.dictionary.url", "http://localhost:8084")
 	dictClient := dictionary.NewHTTPClient(dictURL)
 
 	hd := menu.HandlerDeps{
-		ItemRepo:   itemRepo,
-		MenuRepo:   menuRepo,
-		DictClient: dictClient,
+		ItemRepo:    itemRepo,
+		MenuRepo:    menuRepo,
+		VersionRepo: versionRepo,
+		DictClient:  dictClient,
 	}
 
 	// Initialize handler
@@ -78,7 +80,7 @@ func main() {
 		apt.WithLogger(logger),
 		apt.WithHTTPMiddleware(stack...),
 		apt.WithHTTPServerModules("web.port", handler),
-		apt.WithLifecycle(itemRepo, menuRepo, seedHooks),
+		apt.WithLifecycle(itemRepo, menuRepo, versionRepo, seedHooks),
 		apt.WithHealthChecks(appName),
 	}
 
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg"
 	proto "github.com/appetiteclub/appetite/services/operations/internal/kitchenstream/proto"
 	"github.com/appetiteclub/apt"
 	"google.golang.org/grpc"
@@ -19,10 +20,16 @@ type Client struct {
 
 	mu          sync.RWMutex
 	subscribers map[string]chan *proto.KitchenTicketEvent
+	replay      *pkg.ReplayBuffer[*proto.KitchenTicketEvent]
 	conn        *grpc.ClientConn
 	stream      proto.EventStream_StreamKitchenEventsClient
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// Epoch and sequence of the last event received from Kitchen, to resume
+	// from after a reconnect. Only touched by the connection goroutine.
+	upstreamInstance string
+	upstreamSequence uint64
 }
 
 // NewClient creates a new Kitchen stream client
@@ -32,6 +39,7 @@ func NewClient(addr string, logger apt.Logger) *Client {
 		addr:        addr,
 		logger:      logger,
 		subscribers: make(map[string]chan *proto.KitchenTicketEvent),
+		replay:      pkg.NewReplayBuffer[*proto.KitchenTicketEvent](pkg.DefaultReplaySize),
 		ctx:         ctx,
 		cancel:      cancel,
 	}
@@ -76,9 +84,12 @@ func (c *Client) connectWithRetry() {
 		c.conn = conn
 		client := proto.NewEventStreamClient(conn)
 
-		// Subscribe to all events (no station filter)
+		// Subscribe to all events (no station filter), resuming after the
+		// last event we got so a dropped connection does not lose any
 		req := &proto.SubscribeKitchenEventsRequest{
-			StationId: "",
+			StationId:     "",
+			AfterSequence: c.upstreamSequence,
+			Instance:      c.upstreamInstance,
 		}
 
 		stream, err := client.StreamKitchenEvents(c.ctx, req)
@@ -117,15 +128,24 @@ func (c *Client) receiveEvents() {
 			return
 		}
 
+		c.upstreamInstance = evt.Instance
+		c.upstreamSequence = evt.Sequence
+
 		// Broadcast to all SSE subscribers
 		c.broadcastToSubscribers(evt)
 	}
 }
 
-// broadcastToSubscribers sends event to all SSE subscribers
+// broadcastToSubscribers sends event to all SSE subscribers. Events are
+// renumbered in this client's own sequence and epoch, which SSE clients
+// resume from: it keeps increasing when Kitchen restarts and starts its
+// numbering over.
 func (c *Client) broadcastToSubscribers(evt *proto.KitchenTicketEvent) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evt.Sequence = c.replay.Append(evt)
+	evt.Instance = c.replay.Epoch()
 
 	for subscriberID, ch := range c.subscribers {
 		select {
@@ -138,8 +158,12 @@ func (c *Client) broadcastToSubscribers(evt *proto.KitchenTicketEvent) {
 	}
 }
 
-// Subscribe adds a new SSE subscriber and returns event channel
-func (c *Client) Subscribe(subscriberID string) <-chan *proto.KitchenTicketEvent {
+// Subscribe adds a new SSE subscriber and returns its event channel. missed
+// holds the buffered events after the given sequence of instance, oldest
+// first, for a subscriber resuming after a dropped connection; ok is false
+// when they are no longer buffered or instance is from before a restart.
+// last is the sequence of the newest event sent before the subscriber joined.
+func (c *Client) Subscribe(subscriberID string, instance string, after uint64) (events <-chan *proto.KitchenTicketEvent, missed []*proto.KitchenTicketEvent, last uint64, ok bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -148,7 +172,14 @@ func (c *Client) Subscribe(subscriberID string) <-chan *proto.KitchenTicketEvent
 
 	c.logger.Info("new SSE subscriber", "subscriber_id", subscriberID, "total_subscribers", len(c.subscribers))
 
-	return ch
+	missed, ok = c.replay.Since(instance, after)
+	return ch, missed, c.replay.Last(), ok
+}
+
+// Instance returns the epoch of the sequences this client hands out. It
+// changes when operations restarts.
+func (c *Client) Instance() string {
+	return c.replay.Epoch()
 }
 
 // Unsubscribe removes an SSE subscriber
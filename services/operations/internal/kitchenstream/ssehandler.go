@@ -3,7 +3,9 @@ package kitchenstream
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,13 +34,18 @@ type OrderItemData struct {
 	Category           string
 	GroupName          string
 	Notes              string
+	Modifiers          []string
+	PortionName        string
+	Course             int
+	Held               bool
 	CreatedAt          string
 	RequiresProduction bool
 }
 
 // OrderStreamClient interface for Order stream subscription
 type OrderStreamClient interface {
-	Subscribe(subscriberID string) <-chan *orderproto.OrderItemEvent
+	Subscribe(subscriberID string, instance string, after uint64) (events <-chan *orderproto.OrderItemEvent, missed []*orderproto.OrderItemEvent, last uint64, ok bool)
+	Instance() string
 	Unsubscribe(subscriberID string)
 }
 
@@ -66,7 +73,12 @@ func (h *SSEHandler) SetOrderClient(client OrderStreamClient) {
 	h.orderClient = client
 }
 
-// ServeHTTP implements http.Handler for SSE endpoint
+// ServeHTTP implements http.Handler for SSE endpoint. Every event carries an
+// id with the position reached in the Kitchen and Order streams; a browser
+// that reconnects with Last-Event-ID gets the events it missed first. When
+// they are no longer buffered, or the position is from before operations
+// restarted, it gets a stream-resync event so the page reloads instead of
+// showing stale tickets.
 func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -75,17 +87,24 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Accel-Buffering", "no")
 
 	subscriberID := uuid.New().String()
-	h.logger.Info("new SSE connection", "subscriber_id", subscriberID)
+	resumeFrom, resuming := parseStreamPosition(lastEventID(r))
+	h.logger.Info("new SSE connection", "subscriber_id", subscriberID, "last_event_id", lastEventID(r))
 
 	// Subscribe to Kitchen events
-	kitchenEventChan := h.kitchenClient.Subscribe(subscriberID)
+	kitchenEventChan, kitchenMissed, kitchenLast, kitchenOK := h.kitchenClient.Subscribe(subscriberID, resumeFrom.KitchenInstance, resumeFrom.Kitchen)
 	defer h.kitchenClient.Unsubscribe(subscriberID)
+	pos := streamPosition{KitchenInstance: h.kitchenClient.Instance(), Kitchen: kitchenLast}
+	complete := kitchenOK
 
 	// Subscribe to Order events if client is available
 	var orderEventChan <-chan *orderproto.OrderItemEvent
+	var orderMissed []*orderproto.OrderItemEvent
 	if h.orderClient != nil {
-		orderEventChan = h.orderClient.Subscribe(subscriberID)
+		var orderOK bool
+		orderEventChan, orderMissed, pos.Order, orderOK = h.orderClient.Subscribe(subscriberID, resumeFrom.OrderInstance, resumeFrom.Order)
 		defer h.orderClient.Unsubscribe(subscriberID)
+		pos.OrderInstance = h.orderClient.Instance()
+		complete = complete && orderOK
 	}
 
 	// Send initial comment to establish connection
@@ -98,6 +117,27 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		f.Flush()
 	}
 
+	switch {
+	case resuming && !complete:
+		h.logger.Info("SSE client missed more events than are buffered, asking it to reload", "subscriber_id", subscriberID)
+		sendSSEEvent(w, pos.String(), "stream-resync", `<script>window.location.reload();</script>`)
+	case resuming:
+		h.logger.Info("replaying missed events to SSE client", "subscriber_id", subscriberID, "kitchen_events", len(kitchenMissed), "order_events", len(orderMissed))
+		replayed := resumeFrom
+		for _, evt := range kitchenMissed {
+			replayed.Kitchen = evt.Sequence
+			h.sendKitchenEvent(w, replayed.String(), evt)
+		}
+		for _, evt := range orderMissed {
+			replayed.Order = evt.Sequence
+			h.sendOrderEvent(w, replayed.String(), evt)
+		}
+	}
+
+	// Record where the stream stands, so a reconnect before the next event
+	// still resumes from here
+	writeStreamPosition(w, pos)
+
 	// Send keepalive every 30 seconds
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -121,31 +161,8 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			// Send two types of SSE events:
-			// ticket-update for Kitchen Kanban (always)
-			// order-item-update for Order Modal (only for status changes)
-
-			// Render ticket card for Kanban dashboard
-			ticketHTML, err := h.renderTicketCard(evt)
-			if err != nil {
-				h.logger.Error("failed to render ticket card", "error", err)
-			} else {
-				fmt.Fprintf(w, "event: ticket-update\n")
-				fmt.Fprintf(w, "data: %s\n\n", ticketHTML)
-				if f, ok := w.(http.Flusher); ok {
-					f.Flush()
-				}
-			}
-
-			// If this is a status change and has order_item_id, send order-item-update
-			if evt.EventType == "kitchen.ticket.status_changed" && evt.OrderItemId != "" {
-				itemHTML, err := h.renderOrderItemRowFromKitchen(evt)
-				if err != nil {
-					h.logger.Error("failed to render order item row", "error", err)
-				} else {
-					sendSSEEvent(w, "order-item-update", itemHTML)
-				}
-			}
+			pos.Kitchen = evt.Sequence
+			h.sendKitchenEvent(w, pos.String(), evt)
 
 		case evt, ok := <-orderEventChan:
 			if !ok {
@@ -160,22 +177,133 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				"new_status", evt.NewStatus,
 			)
 
-			// Order item status changed - send order-item-update
-			itemHTML, err := h.renderOrderItemRowFromOrder(evt)
-			if err != nil {
-				h.logger.Error("failed to render order item row from order event", "error", err)
-			} else {
-				sendSSEEvent(w, "order-item-update", itemHTML)
-			}
+			pos.Order = evt.Sequence
+			h.sendOrderEvent(w, pos.String(), evt)
 		}
 	}
 }
 
-// sendSSEEvent sends an SSE event with properly formatted multi-line data
-func sendSSEEvent(w http.ResponseWriter, eventType string, data string) {
+// sendKitchenEvent sends the SSE events pages need for a Kitchen ticket event
+func (h *SSEHandler) sendKitchenEvent(w http.ResponseWriter, id string, evt *kitchenproto.KitchenTicketEvent) {
+	// Send two types of SSE events:
+	// ticket-update for Kitchen Kanban (always)
+	// order-item-update for Order Modal (only for status changes)
+
+	// Render ticket card for Kanban dashboard
+	ticketHTML, err := h.renderTicketCard(evt)
+	if err != nil {
+		h.logger.Error("failed to render ticket card", "error", err)
+	} else {
+		sendSSEEvent(w, id, "ticket-update", ticketHTML)
+	}
+
+	// If this is a status change and has order_item_id, send order-item-update
+	if evt.EventType == "kitchen.ticket.status_changed" && evt.OrderItemId != "" {
+		itemHTML, err := h.renderOrderItemRowFromKitchen(evt)
+		if err != nil {
+			h.logger.Error("failed to render order item row", "error", err)
+		} else {
+			sendSSEEvent(w, id, "order-item-update", itemHTML)
+		}
+	}
+
+	// Late tickets are also flashed on the orders board and order modal
+	if evt.EventType == "kitchen.ticket.late" {
+		sendSSEEvent(w, id, "ticket-late", renderLateTicket(evt))
+	}
+
+	// Questions from the kitchen, their escalation and answer show up
+	// on the orders board and order modal
+	if strings.HasPrefix(evt.EventType, "kitchen.ticket.decision_") {
+		sendSSEEvent(w, id, "ticket-decision", renderTicketDecision(evt))
+	}
+}
+
+// sendOrderEvent sends the order-item-update for an Order item event
+func (h *SSEHandler) sendOrderEvent(w http.ResponseWriter, id string, evt *orderproto.OrderItemEvent) {
+	// Order item status changed - send order-item-update
+	itemHTML, err := h.renderOrderItemRowFromOrder(evt)
+	if err != nil {
+		h.logger.Error("failed to render order item row from order event", "error", err)
+		return
+	}
+	sendSSEEvent(w, id, "order-item-update", itemHTML)
+}
+
+// streamPosition is how far an SSE client got in the Kitchen and Order
+// streams, each sequence with the epoch it was given in. It travels as the
+// SSE event id, e.g. "3f9a1c2b.120-a1b2c3d4.48".
+type streamPosition struct {
+	KitchenInstance string
+	Kitchen         uint64
+	OrderInstance   string
+	Order           uint64
+}
+
+func (p streamPosition) String() string {
+	return fmt.Sprintf("%s.%d-%s.%d", p.KitchenInstance, p.Kitchen, p.OrderInstance, p.Order)
+}
+
+// parseStreamPosition reads an SSE event id written by streamPosition. ok is
+// false when there is none, or it has no epochs, so the client starts fresh.
+func parseStreamPosition(id string) (streamPosition, bool) {
+	kitchen, order, found := strings.Cut(strings.TrimSpace(id), "-")
+	if !found {
+		return streamPosition{}, false
+	}
+	kInstance, k, ok := parseStreamCursor(kitchen)
+	if !ok {
+		return streamPosition{}, false
+	}
+	oInstance, o, ok := parseStreamCursor(order)
+	if !ok {
+		return streamPosition{}, false
+	}
+	return streamPosition{KitchenInstance: kInstance, Kitchen: k, OrderInstance: oInstance, Order: o}, true
+}
+
+// parseStreamCursor reads one "<epoch>.<sequence>" half of a position.
+func parseStreamCursor(cursor string) (instance string, sequence uint64, ok bool) {
+	instance, seq, found := strings.Cut(cursor, ".")
+	if !found {
+		return "", 0, false
+	}
+	sequence, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return instance, sequence, true
+}
+
+// lastEventID returns the id the browser last saw. EventSource sends it as a
+// header when it reconnects by itself; clients that open a new connection can
+// pass it in the query string instead.
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("last_event_id")
+}
+
+// writeStreamPosition sets the browser's last event id without dispatching
+// an event.
+func writeStreamPosition(w http.ResponseWriter, pos streamPosition) {
+	fmt.Fprintf(w, "id: %s\n\n", pos)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// sendSSEEvent sends an SSE event with properly formatted multi-line data.
+// id, when set, becomes the browser's Last-Event-ID.
+func sendSSEEvent(w http.ResponseWriter, id string, eventType string, data string) {
 	// Remove any trailing/leading whitespace
 	data = strings.TrimSpace(data)
 
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+
 	// SSE format: each line of data must be prefixed with "data: "
 	fmt.Fprintf(w, "event: %s\n", eventType)
 
@@ -200,6 +328,29 @@ func (h *SSEHandler) renderTicketCard(evt *kitchenproto.KitchenTicketEvent) (str
 	return `<script>window.location.reload();</script>`, nil
 }
 
+// renderLateTicket renders the marker pages use to find and flash a late
+// ticket's order and item.
+func renderLateTicket(evt *kitchenproto.KitchenTicketEvent) string {
+	return fmt.Sprintf(`<div class="ticket-late" data-ticket-id="%s" data-order-id="%s" data-order-item-id="%s" data-late-seconds="%d"></div>`,
+		html.EscapeString(evt.TicketId),
+		html.EscapeString(evt.OrderId),
+		html.EscapeString(evt.OrderItemId),
+		evt.LateSeconds,
+	)
+}
+
+// renderTicketDecision renders the marker pages use to find the order and
+// item a kitchen question is about. data-stage is requested, escalated or
+// resolved.
+func renderTicketDecision(evt *kitchenproto.KitchenTicketEvent) string {
+	return fmt.Sprintf(`<div class="ticket-decision" data-ticket-id="%s" data-order-id="%s" data-order-item-id="%s" data-stage="%s"></div>`,
+		html.EscapeString(evt.TicketId),
+		html.EscapeString(evt.OrderId),
+		html.EscapeString(evt.OrderItemId),
+		html.EscapeString(strings.TrimPrefix(evt.EventType, "kitchen.ticket.decision_")),
+	)
+}
+
 // renderOrderItemRowFromKitchen renders an order item row from a Kitchen ticket event
 func (h *SSEHandler) renderOrderItemRowFromKitchen(evt *kitchenproto.KitchenTicketEvent) (string, error) {
 	// Fetch the current order item data
@@ -0,0 +1,221 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/appetiteclub/appetite/services/operations/internal/operations"
+)
+
+// AuditStore persists the audit trail so managers can query it for comps,
+// voids and disputes.
+type AuditStore struct {
+	client     *mongo.Client
+	db         *mongo.Database
+	collection *mongo.Collection
+	logger     apt.Logger
+	config     *apt.Config
+}
+
+// auditDocument represents the MongoDB document structure. Payload and
+// snapshots are kept as the JSON they were recorded as.
+type auditDocument struct {
+	ID        string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	Action    string    `bson:"action"`
+	Target    string    `bson:"target"`
+	Payload   string    `bson:"payload,omitempty"`
+	Before    string    `bson:"before,omitempty"`
+	After     string    `bson:"after,omitempty"`
+	Timestamp time.Time `bson:"timestamp"`
+	Success   bool      `bson:"success"`
+	Error     string    `bson:"error,omitempty"`
+}
+
+func NewAuditStore(config *apt.Config, logger apt.Logger) *AuditStore {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &AuditStore{
+		logger: logger,
+		config: config,
+	}
+}
+
+func (s *AuditStore) Start(ctx context.Context) error {
+	mongoURL, _ := s.config.GetString("db.mongo.url")
+	connString := mongoURL
+	if connString == "" {
+		connString = "mongodb://localhost:27017"
+	}
+
+	dbName, _ := s.config.GetString("db.mongo.name")
+	if dbName == "" {
+		dbName = "appetite_operations"
+	}
+
+	clientOptions := options.Client().ApplyURI(connString).
+		SetConnectTimeout(10 * time.Second).
+		SetServerSelectionTimeout(10 * time.Second)
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return fmt.Errorf("cannot connect to MongoDB: %w", err)
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("cannot ping MongoDB: %w", err)
+	}
+
+	s.client = client
+	s.db = client.Database(dbName)
+	s.collection = s.db.Collection("audit_log")
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "target", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "timestamp", Value: -1}}},
+	}
+	if _, err := s.collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+
+	s.logger.Infof("Connected to MongoDB: %s, database: %s, collection: audit_log", connString, dbName)
+	return nil
+}
+
+func (s *AuditStore) Stop(ctx context.Context) error {
+	if s.client != nil {
+		if err := s.client.Disconnect(ctx); err != nil {
+			return fmt.Errorf("cannot disconnect from MongoDB: %w", err)
+		}
+		s.logger.Info("Disconnected from MongoDB")
+	}
+	return nil
+}
+
+func (s *AuditStore) Save(ctx context.Context, entry *operations.AuditEntry) error {
+	if entry == nil {
+		return fmt.Errorf("audit entry is nil")
+	}
+
+	if _, err := s.collection.InsertOne(ctx, toAuditDocument(entry)); err != nil {
+		return fmt.Errorf("cannot save audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuditStore) Query(ctx context.Context, query operations.AuditQuery) ([]*operations.AuditEntry, error) {
+	filter := bson.M{}
+	if query.UserID != uuid.Nil {
+		filter["user_id"] = query.UserID.String()
+	}
+	if query.Action != "" {
+		filter["action"] = caseInsensitive(query.Action)
+	}
+	if query.Target != "" {
+		filter["target"] = caseInsensitive(query.Target)
+	}
+	if !query.From.IsZero() || !query.To.IsZero() {
+		timestamp := bson.M{}
+		if !query.From.IsZero() {
+			timestamp["$gte"] = query.From
+		}
+		if !query.To.IsZero() {
+			timestamp["$lte"] = query.To
+		}
+		filter["timestamp"] = timestamp
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetLimit(int64(query.EffectiveLimit()))
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query audit entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []auditDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("cannot decode audit entries: %w", err)
+	}
+
+	result := make([]*operations.AuditEntry, 0, len(docs))
+	for _, doc := range docs {
+		entry, err := fromAuditDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+// caseInsensitive matches value exactly, ignoring case, as the in-memory
+// store does.
+func caseInsensitive(value string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}
+}
+
+// toAuditDocument converts an audit entry to MongoDB document.
+func toAuditDocument(entry *operations.AuditEntry) *auditDocument {
+	return &auditDocument{
+		ID:        entry.ID.String(),
+		UserID:    entry.UserID.String(),
+		Action:    entry.Action,
+		Target:    entry.Target,
+		Payload:   string(entry.Payload),
+		Before:    string(entry.Before),
+		After:     string(entry.After),
+		Timestamp: entry.Timestamp,
+		Success:   entry.Success,
+		Error:     entry.Error,
+	}
+}
+
+// fromAuditDocument converts a MongoDB document to audit entry.
+func fromAuditDocument(doc *auditDocument) (*operations.AuditEntry, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit entry ID format: %w", err)
+	}
+
+	userID, err := uuid.Parse(doc.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit user ID format: %w", err)
+	}
+
+	return &operations.AuditEntry{
+		ID:        id,
+		UserID:    userID,
+		Action:    doc.Action,
+		Target:    doc.Target,
+		Payload:   rawJSON(doc.Payload),
+		Before:    rawJSON(doc.Before),
+		After:     rawJSON(doc.After),
+		Timestamp: doc.Timestamp,
+		Success:   doc.Success,
+		Error:     doc.Error,
+	}, nil
+}
+
+func rawJSON(value string) json.RawMessage {
+	if value == "" {
+		return nil
+	}
+	return json.RawMessage(value)
+}
@@ -0,0 +1,216 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/appetiteclub/appetite/services/operations/internal/operations"
+)
+
+// JournalStore persists the chat command journal so history and undo survive
+// restarts.
+type JournalStore struct {
+	client     *mongo.Client
+	db         *mongo.Database
+	collection *mongo.Collection
+	logger     apt.Logger
+	config     *apt.Config
+}
+
+// journalDocument represents the MongoDB document structure.
+type journalDocument struct {
+	ID        string             `bson:"_id"`
+	UserID    string             `bson:"user_id"`
+	Command   string             `bson:"command"`
+	Params    []string           `bson:"params"`
+	Success   bool               `bson:"success"`
+	Message   string             `bson:"message,omitempty"`
+	Inverse   *journalCommandDoc `bson:"inverse,omitempty"`
+	UndoneAt  *time.Time         `bson:"undone_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+type journalCommandDoc struct {
+	Command string   `bson:"command"`
+	Params  []string `bson:"params"`
+}
+
+func NewJournalStore(config *apt.Config, logger apt.Logger) *JournalStore {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &JournalStore{
+		logger: logger,
+		config: config,
+	}
+}
+
+func (s *JournalStore) Start(ctx context.Context) error {
+	mongoURL, _ := s.config.GetString("db.mongo.url")
+	connString := mongoURL
+	if connString == "" {
+		connString = "mongodb://localhost:27017"
+	}
+
+	dbName, _ := s.config.GetString("db.mongo.name")
+	if dbName == "" {
+		dbName = "appetite_operations"
+	}
+
+	clientOptions := options.Client().ApplyURI(connString).
+		SetConnectTimeout(10 * time.Second).
+		SetServerSelectionTimeout(10 * time.Second)
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return fmt.Errorf("cannot connect to MongoDB: %w", err)
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("cannot ping MongoDB: %w", err)
+	}
+
+	s.client = client
+	s.db = client.Database(dbName)
+	s.collection = s.db.Collection("command_journal")
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	}
+	if _, err := s.collection.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return fmt.Errorf("cannot create index: %w", err)
+	}
+
+	s.logger.Infof("Connected to MongoDB: %s, database: %s, collection: command_journal", connString, dbName)
+	return nil
+}
+
+func (s *JournalStore) Stop(ctx context.Context) error {
+	if s.client != nil {
+		if err := s.client.Disconnect(ctx); err != nil {
+			return fmt.Errorf("cannot disconnect from MongoDB: %w", err)
+		}
+		s.logger.Info("Disconnected from MongoDB")
+	}
+	return nil
+}
+
+func (s *JournalStore) Append(ctx context.Context, entry *operations.JournalEntry) error {
+	if entry == nil {
+		return fmt.Errorf("journal entry is nil")
+	}
+
+	if _, err := s.collection.InsertOne(ctx, toJournalDocument(entry)); err != nil {
+		return fmt.Errorf("cannot append journal entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s *JournalStore) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*operations.JournalEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{"user_id": userID.String()}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list journal entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []journalDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("cannot decode journal entries: %w", err)
+	}
+
+	result := make([]*operations.JournalEntry, 0, len(docs))
+	for _, doc := range docs {
+		entry, err := fromJournalDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+func (s *JournalStore) MarkUndone(ctx context.Context, id uuid.UUID, at time.Time) error {
+	filter := bson.M{"_id": id.String()}
+	update := bson.M{"$set": bson.M{"undone_at": at}}
+
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("cannot mark journal entry undone: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("journal entry not found")
+	}
+
+	return nil
+}
+
+// toJournalDocument converts a journal entry to MongoDB document.
+func toJournalDocument(entry *operations.JournalEntry) *journalDocument {
+	doc := &journalDocument{
+		ID:        entry.ID.String(),
+		UserID:    entry.UserID.String(),
+		Command:   entry.Command,
+		Params:    entry.Params,
+		Success:   entry.Success,
+		Message:   entry.Message,
+		UndoneAt:  entry.UndoneAt,
+		CreatedAt: entry.CreatedAt,
+	}
+
+	if entry.Inverse != nil {
+		doc.Inverse = &journalCommandDoc{
+			Command: entry.Inverse.Command,
+			Params:  entry.Inverse.Params,
+		}
+	}
+
+	return doc
+}
+
+// fromJournalDocument converts a MongoDB document to journal entry.
+func fromJournalDocument(doc *journalDocument) (*operations.JournalEntry, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid journal entry ID format: %w", err)
+	}
+
+	userID, err := uuid.Parse(doc.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid journal user ID format: %w", err)
+	}
+
+	entry := &operations.JournalEntry{
+		ID:        id,
+		UserID:    userID,
+		Command:   doc.Command,
+		Params:    doc.Params,
+		Success:   doc.Success,
+		Message:   doc.Message,
+		UndoneAt:  doc.UndoneAt,
+		CreatedAt: doc.CreatedAt,
+	}
+
+	if doc.Inverse != nil {
+		entry.Inverse = &operations.JournalCommand{
+			Command: doc.Inverse.Command,
+			Params:  doc.Inverse.Params,
+		}
+	}
+
+	return entry, nil
+}
@@ -3,38 +3,114 @@ package operations
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/appetiteclub/apt"
 	"github.com/google/uuid"
 )
 
+const (
+	defaultAuditQueryLimit = 100
+	maxAuditQueryLimit     = 1000
+)
+
 // AuditEntry represents a single audit log entry for a user action.
+// Before and After hold snapshots of the target around state changes.
 type AuditEntry struct {
+	ID        uuid.UUID       `json:"id"`
 	UserID    uuid.UUID       `json:"user_id"`
 	Action    string          `json:"action"`
 	Target    string          `json:"target"`
-	Payload   json.RawMessage `json:"payload"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
 	Timestamp time.Time       `json:"timestamp"`
 	Success   bool            `json:"success"`
 	Error     string          `json:"error,omitempty"`
 }
 
+// AuditQuery filters audit entries. Zero values match everything.
+type AuditQuery struct {
+	UserID uuid.UUID
+	Action string
+	Target string
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// Matches reports whether entry passes the query filters.
+func (q AuditQuery) Matches(entry *AuditEntry) bool {
+	if q.UserID != uuid.Nil && entry.UserID != q.UserID {
+		return false
+	}
+	if q.Action != "" && !strings.EqualFold(entry.Action, q.Action) {
+		return false
+	}
+	if q.Target != "" && !strings.EqualFold(entry.Target, q.Target) {
+		return false
+	}
+	if !q.From.IsZero() && entry.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && entry.Timestamp.After(q.To) {
+		return false
+	}
+	return true
+}
+
+// EffectiveLimit returns the limit to apply, defaulting and capping the
+// requested one.
+func (q AuditQuery) EffectiveLimit() int {
+	if q.Limit <= 0 {
+		return defaultAuditQueryLimit
+	}
+	return min(q.Limit, maxAuditQueryLimit)
+}
+
+// AuditStore persists audit entries.
+type AuditStore interface {
+	Save(ctx context.Context, entry *AuditEntry) error
+	// Query returns the entries matching query, newest first.
+	Query(ctx context.Context, query AuditQuery) ([]*AuditEntry, error)
+}
+
 // AuditLogger handles logging of user actions for operational transparency.
+// Entries go to the logger and to the audit store.
 type AuditLogger struct {
 	logger apt.Logger
+	store  AuditStore
 }
 
-// NewAuditLogger creates a new audit logger.
+// NewAuditLogger creates a new audit logger backed by an in-memory store.
 func NewAuditLogger(logger apt.Logger) *AuditLogger {
 	if logger == nil {
 		logger = apt.NewNoopLogger()
 	}
-	return &AuditLogger{logger: logger}
+	return &AuditLogger{logger: logger, store: NewMemoryAuditStore()}
+}
+
+// SetStore replaces the audit store, e.g. with a persistent one.
+func (a *AuditLogger) SetStore(store AuditStore) {
+	a.store = store
+}
+
+// Query returns the stored entries matching query, newest first.
+func (a *AuditLogger) Query(ctx context.Context, query AuditQuery) ([]*AuditEntry, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("audit store not configured")
+	}
+	return a.store.Query(ctx, query)
 }
 
 // Log records an audit entry.
 func (a *AuditLogger) Log(ctx context.Context, entry AuditEntry) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
 	if entry.Timestamp.IsZero() {
 		entry.Timestamp = time.Now()
 	}
@@ -47,6 +123,12 @@ func (a *AuditLogger) Log(ctx context.Context, entry AuditEntry) {
 		"timestamp", entry.Timestamp.Format(time.RFC3339),
 		"error", entry.Error,
 	)
+
+	if a.store != nil {
+		if err := a.store.Save(ctx, &entry); err != nil {
+			a.logger.Error("cannot store audit entry", "action", entry.Action, "target", entry.Target, "error", err)
+		}
+	}
 }
 
 // LogCommand logs a command execution with its result.
@@ -94,3 +176,75 @@ func (a *AuditLogger) LogLogout(ctx context.Context, userID uuid.UUID) {
 
 	a.Log(ctx, entry)
 }
+
+// LogChange logs a state-changing action on target with snapshots of the
+// target before and after it. A nil snapshot is left out; err marks the action
+// as failed.
+func (a *AuditLogger) LogChange(ctx context.Context, userID uuid.UUID, action, target string, before, after interface{}, err error) {
+	entry := AuditEntry{
+		UserID:    userID,
+		Action:    action,
+		Target:    target,
+		Before:    auditSnapshot(before),
+		After:     auditSnapshot(after),
+		Timestamp: time.Now(),
+		Success:   err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	a.Log(ctx, entry)
+}
+
+func auditSnapshot(value interface{}) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil || string(raw) == "null" {
+		return nil
+	}
+	return raw
+}
+
+// MemoryAuditStore keeps audit entries in memory. It loses the trail on
+// restart and is meant for development and tests.
+type MemoryAuditStore struct {
+	entries []*AuditEntry
+	mutex   sync.RWMutex
+}
+
+// NewMemoryAuditStore creates an empty in-memory audit store.
+func NewMemoryAuditStore() *MemoryAuditStore {
+	return &MemoryAuditStore{}
+}
+
+func (s *MemoryAuditStore) Save(ctx context.Context, entry *AuditEntry) error {
+	if entry == nil {
+		return fmt.Errorf("audit entry is required")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entryCopy := *entry
+	s.entries = append(s.entries, &entryCopy)
+	return nil
+}
+
+func (s *MemoryAuditStore) Query(ctx context.Context, query AuditQuery) ([]*AuditEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	limit := query.EffectiveLimit()
+	result := make([]*AuditEntry, 0)
+	for i := len(s.entries) - 1; i >= 0 && len(result) < limit; i-- {
+		if query.Matches(s.entries[i]) {
+			entryCopy := *s.entries[i]
+			result = append(result, &entryCopy)
+		}
+	}
+
+	return result, nil
+}
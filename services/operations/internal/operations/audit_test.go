@@ -2,6 +2,8 @@ package operations
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"testing"
 	"time"
 
@@ -175,3 +177,85 @@ func TestAuditEntryFields(t *testing.T) {
 		t.Errorf("Timestamp = %v, want %v", entry.Timestamp, now)
 	}
 }
+
+func TestAuditLoggerStoresEntries(t *testing.T) {
+	ctx := context.Background()
+	logger := NewAuditLogger(nil)
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440710")
+	itemID := "550e8400-e29b-41d4-a716-446655440711"
+
+	logger.LogLogin(ctx, userID)
+	logger.LogChange(ctx, userID, "order-item.cancel", itemID,
+		map[string]string{"status": "pending"}, map[string]string{"status": "cancelled"}, nil)
+	logger.LogChange(ctx, userID, "order-item.cancel", itemID, nil, nil, errors.New("order service down"))
+
+	entries, err := logger.Query(ctx, AuditQuery{Target: itemID})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Query() returned %d entries, want 2", len(entries))
+	}
+
+	failed, voided := entries[0], entries[1]
+	if failed.Success || failed.Error != "order service down" || failed.Before != nil {
+		t.Errorf("failed entry = %+v, want unsuccessful without snapshots", failed)
+	}
+	if !voided.Success || voided.ID == uuid.Nil {
+		t.Errorf("voided entry = %+v, want successful with an ID", voided)
+	}
+	if string(voided.Before) != `{"status":"pending"}` || string(voided.After) != `{"status":"cancelled"}` {
+		t.Errorf("snapshots = %s / %s", voided.Before, voided.After)
+	}
+}
+
+func TestAuditQueryMatches(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440712")
+	at := time.Date(2025, 3, 1, 22, 14, 0, 0, time.UTC)
+	entry := &AuditEntry{UserID: userID, Action: "order-item.cancel", Target: "item-1", Timestamp: at}
+
+	tests := []struct {
+		name  string
+		query AuditQuery
+		want  bool
+	}{
+		{name: "empty", query: AuditQuery{}, want: true},
+		{name: "user", query: AuditQuery{UserID: userID}, want: true},
+		{name: "otherUser", query: AuditQuery{UserID: uuid.New()}, want: false},
+		{name: "actionIgnoresCase", query: AuditQuery{Action: "Order-Item.Cancel"}, want: true},
+		{name: "otherTarget", query: AuditQuery{Target: "item-2"}, want: false},
+		{name: "insideRange", query: AuditQuery{From: at.Add(-time.Minute), To: at.Add(time.Minute)}, want: true},
+		{name: "beforeRange", query: AuditQuery{From: at.Add(time.Minute)}, want: false},
+		{name: "afterRange", query: AuditQuery{To: at.Add(-time.Minute)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Matches(entry); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryAuditStoreQueryLimit(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryAuditStore()
+	for i := 0; i < 5; i++ {
+		if err := store.Save(ctx, &AuditEntry{ID: uuid.New(), Action: "login", Target: strconv.Itoa(i)}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	entries, err := store.Query(ctx, AuditQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Target != "4" || entries[1].Target != "3" {
+		t.Errorf("Query() = %v, want the two newest entries", entries)
+	}
+
+	if got := (AuditQuery{Limit: 5000}).EffectiveLimit(); got != maxAuditQueryLimit {
+		t.Errorf("EffectiveLimit() = %d, want %d", got, maxAuditQueryLimit)
+	}
+}
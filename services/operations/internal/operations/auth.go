@@ -115,6 +115,10 @@ func (h *Handler) HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if parsedID, err := uuid.Parse(userID); err == nil && h.auditLogger != nil {
+		h.auditLogger.LogLogin(r.Context(), parsedID)
+	}
+
 	// Set session cookie
 	sessionName, _ := h.config.GetString("auth.session.name")
 	http.SetCookie(w, &http.Cookie{
@@ -140,6 +144,11 @@ func (h *Handler) HandleSignOut(w http.ResponseWriter, r *http.Request) {
 	sessionName, _ := h.config.GetString("auth.session.name")
 	cookie, err := r.Cookie(sessionName)
 	if err == nil && cookie.Value != "" {
+		if session, err := h.sessionStore.Get(cookie.Value); err == nil {
+			if parsedID, err := uuid.Parse(session.UserID); err == nil && h.auditLogger != nil {
+				h.auditLogger.LogLogout(r.Context(), parsedID)
+			}
+		}
 		h.sessionStore.Delete(cookie.Value)
 	}
 
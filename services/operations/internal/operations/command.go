@@ -18,6 +18,9 @@ type CommandResponse struct {
 	HTML    string
 	Success bool
 	Message string
+	// Effects records what a command changed (IDs, previous values) so its
+	// inverse can be built for the journal. It is never sent to the client.
+	Effects map[string]string
 }
 
 // DeterministicParser implements CommandProcessor using pattern matching
@@ -65,21 +68,55 @@ func (p *DeterministicParser) Process(ctx context.Context, input string) (*Comma
 	// Execute command handler
 	response, err := cmd.Handler(ctx, params)
 
-	// Log command execution (for authenticated commands only)
-	userID := getUserIDFromContext(ctx)
-	if p.handler != nil && p.handler.auditLogger != nil && userID != uuid.Nil {
-		errorMsg := ""
-		if err != nil {
-			errorMsg = err.Error()
-		} else if !response.Success {
-			errorMsg = response.Message
-		}
-		p.handler.auditLogger.LogCommand(ctx, userID, cmd.Canonical, params, response.Success, errorMsg)
+	p.audit(ctx, cmd, params, response, err)
+	if err == nil {
+		p.journal(ctx, cmd, params, response)
 	}
 
 	return response, err
 }
 
+// audit logs a command execution (for authenticated commands only).
+func (p *DeterministicParser) audit(ctx context.Context, cmd *CommandDefinition, params []string, response *CommandResponse, err error) {
+	userID := getUserIDFromContext(ctx)
+	if p.handler == nil || p.handler.auditLogger == nil || userID == uuid.Nil {
+		return
+	}
+
+	success := err == nil && response != nil && response.Success
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	} else if !success && response != nil {
+		errorMsg = response.Message
+	}
+	p.handler.auditLogger.LogCommand(ctx, userID, cmd.Canonical, params, success, errorMsg)
+}
+
+// journal records a command run in the user's command journal, together with
+// its inverse when the command is reversible and succeeded.
+func (p *DeterministicParser) journal(ctx context.Context, cmd *CommandDefinition, params []string, response *CommandResponse) {
+	userID := getUserIDFromContext(ctx)
+	if p.handler == nil || p.handler.commandJournal == nil || userID == uuid.Nil || response == nil {
+		return
+	}
+
+	entry := &JournalEntry{
+		UserID:  userID,
+		Command: cmd.Canonical,
+		Params:  params,
+		Success: response.Success,
+		Message: response.Message,
+	}
+	if response.Success && cmd.Inverse != nil {
+		entry.Inverse = cmd.Inverse(params, response)
+	}
+
+	if err := p.handler.commandJournal.Record(ctx, entry); err != nil && p.handler.logger != nil {
+		p.handler.logger.Error("cannot record command in journal", "command", cmd.Canonical, "error", err)
+	}
+}
+
 func (p *DeterministicParser) formatUnknownCommand(input string) string {
 	return fmt.Sprintf(`
 		<p>⚠️ Command not recognized: <code>%s</code></p>
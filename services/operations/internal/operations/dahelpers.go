@@ -3,6 +3,7 @@ package operations
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/appetiteclub/apt"
 )
@@ -24,3 +25,32 @@ func decodeSuccessResponse(resp *apt.SuccessResponse, dest interface{}) error {
 
 	return nil
 }
+
+// backendErrorMessage extracts the message a backend service put in its error
+// response body, so validation failures can be shown as they were written.
+func backendErrorMessage(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	errStr := err.Error()
+	const marker = `"message":"`
+	start := strings.Index(errStr, marker)
+	if start < 0 {
+		return "", false
+	}
+	start += len(marker)
+	end := strings.Index(errStr[start:], `"`)
+	if end <= 0 {
+		return "", false
+	}
+	return errStr[start : start+end], true
+}
+
+// serviceErrorMessage returns the backend message for err, or err itself
+// when the response carried none.
+func serviceErrorMessage(err error) string {
+	if msg, ok := backendErrorMessage(err); ok {
+		return msg
+	}
+	return err.Error()
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg/enums/station"
 	"github.com/appetiteclub/apt"
 	authpkg "github.com/appetiteclub/apt/auth"
 	"github.com/appetiteclub/apt/telemetry"
@@ -20,6 +21,7 @@ type Handler struct {
 	tableData        *TableDataAccess
 	orderData        *OrderDataAccess
 	kitchenData      *KitchenDataAccess
+	stations         *station.Registry
 	roleRepo         RoleRepo
 	grantRepo        GrantRepo
 	authzHelper      *authpkg.AuthzHelper
@@ -29,6 +31,7 @@ type Handler struct {
 	sessionStore     *SessionStore
 	tokenStore       *TokenStore
 	auditLogger      *AuditLogger
+	commandJournal   *CommandJournal
 	commandProcessor CommandProcessor
 	sseHandler       http.Handler
 }
@@ -61,6 +64,13 @@ func NewHandler(
 	}
 	menuClient := apt.NewServiceClient(menuURL)
 
+	// Stations come from the dictionary so venues can model their own line
+	var dictionaryClient *apt.ServiceClient
+	if dictionaryURL, _ := config.GetString("services.dictionary.url"); dictionaryURL != "" {
+		dictionaryClient = apt.NewServiceClient(dictionaryURL)
+	}
+	stations := station.NewRegistry(dictionaryClient, station.DefaultRefreshInterval, logger)
+
 	authzHelper := newAuthzHelper(config, logger)
 
 	// Initialize session store
@@ -79,24 +89,31 @@ func NewHandler(
 	// Initialize audit logger
 	auditLogger := NewAuditLogger(logger)
 
+	// Initialize command journal backing chat history and undo
+	undoWindowStr, _ := config.GetString("journal.undo_window")
+	undoWindow, _ := time.ParseDuration(undoWindowStr)
+	commandJournal := NewCommandJournal(NewMemoryJournalStore(), undoWindow)
+
 	handler := &Handler{
-		tmplMgr:      tmplMgr,
-		authnClient:  authnClient,
-		tableClient:  tableClient,
-		orderClient:  orderClient,
-		menuClient:   menuClient,
-		tableData:    NewTableDataAccess(tableClient),
-		orderData:    NewOrderDataAccess(orderClient),
-		kitchenData:  kitchenDA,
-		roleRepo:     roleRepo,
-		grantRepo:    grantRepo,
-		authzHelper:  authzHelper,
-		logger:       logger,
-		config:       config,
-		http:         telemetry.NewHTTP(),
-		sessionStore: sessionStore,
-		tokenStore:   tokenStore,
-		auditLogger:  auditLogger,
+		tmplMgr:        tmplMgr,
+		authnClient:    authnClient,
+		tableClient:    tableClient,
+		orderClient:    orderClient,
+		menuClient:     menuClient,
+		tableData:      NewTableDataAccess(tableClient),
+		orderData:      NewOrderDataAccess(orderClient),
+		kitchenData:    kitchenDA,
+		stations:       stations,
+		roleRepo:       roleRepo,
+		grantRepo:      grantRepo,
+		authzHelper:    authzHelper,
+		logger:         logger,
+		config:         config,
+		http:           telemetry.NewHTTP(),
+		sessionStore:   sessionStore,
+		tokenStore:     tokenStore,
+		auditLogger:    auditLogger,
+		commandJournal: commandJournal,
 	}
 
 	// Initialize command processor with handler reference for auth commands
@@ -117,6 +134,23 @@ func (h *Handler) SetSSEHandler(handler http.Handler) {
 	h.sseHandler = handler
 }
 
+// SetJournalStore replaces the in-memory command journal store, e.g. with a
+// persistent one so history and undo survive restarts.
+func (h *Handler) SetJournalStore(store JournalStore) {
+	h.commandJournal = NewCommandJournal(store, h.commandJournal.Window())
+}
+
+// SetAuditStore replaces the in-memory audit store, e.g. with a persistent
+// one managers can query later.
+func (h *Handler) SetAuditStore(store AuditStore) {
+	h.auditLogger.SetStore(store)
+}
+
+// Stations returns the station registry, to be started with the service.
+func (h *Handler) Stations() *station.Registry {
+	return h.stations
+}
+
 // GetOrderDataAccess returns the order data access instance
 func (h *Handler) GetOrderDataAccess() *OrderDataAccess {
 	return h.orderData
@@ -176,6 +210,11 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Get("/orders/menu/match", h.OrderMenuMatch)
 		r.Get("/menu", h.Menu)
 		r.Get("/kitchen", h.KitchenKanban)
+		r.Get("/kitchen/expo", h.KitchenExpo)
+		r.Get("/kitchen/analytics", h.KitchenAnalytics)
+		r.Get("/kitchen/analytics.csv", h.KitchenAnalyticsCSV)
+		r.Get("/audit", h.AuditLog)
+		r.Get("/api/audit", h.ListAuditEntries)
 
 		// SSE endpoint for Kitchen events
 		if h.sseHandler != nil {
@@ -185,7 +224,11 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		// API proxy routes to Kitchen service
 		if h.kitchenData != nil {
 			r.Route("/api/kitchen", func(r chi.Router) {
+				r.Get("/tickets/{id}/transitions", h.ProxyKitchenTicketTransitions)
 				r.Patch("/tickets/{id}/status", h.ProxyKitchenTicketStatus)
+				r.Post("/tickets/{id}/decision", h.ProxyKitchenRaiseDecision)
+				r.Post("/tickets/{id}/decision/answer", h.ProxyKitchenAnswerDecision)
+				r.Post("/orders/{id}/bump", h.ProxyKitchenBumpOrder)
 			})
 		}
 
@@ -36,7 +36,9 @@ func (h *Handler) MarkOrderItemDelivered(w http.ResponseWriter, r *http.Request)
 	client := apt.NewServiceClient(orderServiceURL)
 	path := fmt.Sprintf("/items/%s/deliver", itemID)
 
-	_, err := client.Request(ctx, "PATCH", path, nil)
+	before, _ := NewOrderDataAccess(client).GetOrderItem(ctx, itemID)
+	resp, err := client.Request(ctx, "PATCH", path, nil)
+	h.auditChange(r, "order-item.deliver", itemID, before, responseData(resp), err)
 	if err != nil {
 		log.Errorf("Failed to mark item as delivered: %v", err)
 		http.Error(w, "Failed to mark item as delivered", http.StatusInternalServerError)
@@ -71,7 +73,9 @@ func (h *Handler) CancelOrderItem(w http.ResponseWriter, r *http.Request) {
 	client := apt.NewServiceClient(orderServiceURL)
 	path := fmt.Sprintf("/items/%s/cancel", itemID)
 
-	_, err := client.Request(ctx, "PATCH", path, nil)
+	before, _ := NewOrderDataAccess(client).GetOrderItem(ctx, itemID)
+	resp, err := client.Request(ctx, "PATCH", path, nil)
+	h.auditChange(r, "order-item.cancel", itemID, before, responseData(resp), err)
 	if err != nil {
 		log.Errorf("Failed to cancel item: %v", err)
 		http.Error(w, "Failed to cancel item", http.StatusInternalServerError)
@@ -124,22 +128,19 @@ func (h *Handler) CloseOrder(w http.ResponseWriter, r *http.Request) {
 		path += "?" + strings.Join(params, "&")
 	}
 
+	before, _ := NewOrderDataAccess(client).GetOrder(ctx, orderID)
 	resp, err := client.Request(ctx, "POST", path, nil)
+	h.auditChange(r, "order.close", orderID, before, responseData(resp), err)
 	if err != nil {
 		errStr := err.Error()
 		log.Errorf("Failed to close order: %v", errStr)
 
 		// Try to extract the error message from the backend response
-		if strings.Contains(errStr, `"message":"`) {
-			start := strings.Index(errStr, `"message":"`) + len(`"message":"`)
-			end := strings.Index(errStr[start:], `"`)
-			if end > 0 {
-				errorMsg := errStr[start : start+end]
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusBadRequest)
-				json.NewEncoder(w).Encode(map[string]string{"error": errorMsg})
-				return
-			}
+		if errorMsg, ok := backendErrorMessage(err); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": errorMsg})
+			return
 		}
 
 		http.Error(w, "Failed to close order", http.StatusInternalServerError)
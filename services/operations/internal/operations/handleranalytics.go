@@ -0,0 +1,224 @@
+package operations
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// analyticsQueryParams are the filters the kitchen analytics page forwards
+// to the kitchen service.
+var analyticsQueryParams = []string{"from", "to", "group_by", "station", "tz"}
+
+// analyticsGroupOptions are the groupings offered on the analytics page.
+var analyticsGroupOptions = []struct {
+	Value string
+	Label string
+}{
+	{"station", "Station"},
+	{"item", "Menu item"},
+	{"hour", "Hour of day"},
+	{"cook", "Cook"},
+}
+
+// AnalyticsPhaseView shows the timings of one phase, formatted for display.
+type AnalyticsPhaseView struct {
+	Count int
+	P50   string
+	P90   string
+	Max   string
+}
+
+// AnalyticsRowView is a line of the kitchen analytics table.
+type AnalyticsRowView struct {
+	Label   string
+	Tickets int
+	Queue   AnalyticsPhaseView
+	Cook    AnalyticsPhaseView
+	Pass    AnalyticsPhaseView
+}
+
+// KitchenAnalytics shows queue, cook and pass times of the kitchen tickets
+// over a date range, grouped by station, menu item, hour of day or cook.
+func (h *Handler) KitchenAnalytics(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.KitchenAnalytics")
+	defer finish()
+
+	query := analyticsQuery(r.URL.Query())
+	filters := map[string]string{
+		"From":    query.Get("from"),
+		"To":      query.Get("to"),
+		"GroupBy": query.Get("group_by"),
+		"Station": query.Get("station"),
+	}
+	data := map[string]interface{}{
+		"Title":    "Kitchen Analytics",
+		"Template": "analytics",
+		"User":     h.getUserFromSession(r),
+		"Filters":  filters,
+		"Groups":   analyticsGroupOptions,
+		"Stations": h.stations.All(),
+	}
+
+	report, problem := h.kitchenAnalytics(r, query)
+	if report == nil {
+		data["Error"] = problem
+		h.renderTemplate(w, "kitchen_analytics.html", "base.html", data)
+		return
+	}
+
+	// Show the range the kitchen settled on when the form left it out
+	filters["From"] = report.From.Format("2006-01-02")
+	filters["To"] = report.To.Add(-time.Nanosecond).Format("2006-01-02")
+	filters["GroupBy"] = report.GroupBy
+
+	data["GroupLabel"] = analyticsGroupLabel(report.GroupBy)
+	data["Rows"] = analyticsRowViews(report.Rows)
+	data["Overall"] = analyticsRowView(report.Overall)
+	data["CSVURL"] = template.URL("/kitchen/analytics.csv?" + query.Encode())
+
+	h.renderTemplate(w, "kitchen_analytics.html", "base.html", data)
+}
+
+// KitchenAnalyticsCSV exports the kitchen analytics report as CSV, with
+// times in seconds.
+func (h *Handler) KitchenAnalyticsCSV(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.KitchenAnalyticsCSV")
+	defer finish()
+
+	report, problem := h.kitchenAnalytics(r, analyticsQuery(r.URL.Query()))
+	if report == nil {
+		http.Error(w, problem, http.StatusBadGateway)
+		return
+	}
+
+	filename := fmt.Sprintf("kitchen-%s-%s-%s.csv", report.GroupBy,
+		report.From.Format("20060102"), report.To.Add(-time.Nanosecond).Format("20060102"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := writeAnalyticsCSV(w, report); err != nil {
+		h.log().Errorf("cannot write analytics csv: %v", err)
+	}
+}
+
+// kitchenAnalytics fetches the report. When there is none it returns why,
+// worded for the user: a filter the kitchen refused or an outage.
+func (h *Handler) kitchenAnalytics(r *http.Request, query url.Values) (*kitchenAnalyticsResource, string) {
+	if h.kitchenData == nil {
+		return nil, "Kitchen service not configured."
+	}
+
+	report, err := h.kitchenData.TicketAnalytics(r.Context(), query)
+	if err != nil {
+		if msg, ok := kitchenRejectionMessage(err); ok {
+			return nil, msg
+		}
+		h.log().Errorf("cannot fetch kitchen analytics: %v", err)
+		return nil, "Could not load kitchen analytics right now."
+	}
+	return report, ""
+}
+
+// analyticsQuery keeps the filters the kitchen understands, dropping the
+// empty ones.
+func analyticsQuery(values url.Values) url.Values {
+	query := url.Values{}
+	for _, key := range analyticsQueryParams {
+		if value := strings.TrimSpace(values.Get(key)); value != "" {
+			query.Set(key, value)
+		}
+	}
+	return query
+}
+
+func analyticsGroupLabel(groupBy string) string {
+	for _, option := range analyticsGroupOptions {
+		if option.Value == groupBy {
+			return option.Label
+		}
+	}
+	return groupBy
+}
+
+func analyticsRowViews(rows []kitchenAnalyticsRowResource) []AnalyticsRowView {
+	views := make([]AnalyticsRowView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, analyticsRowView(row))
+	}
+	return views
+}
+
+func analyticsRowView(row kitchenAnalyticsRowResource) AnalyticsRowView {
+	return AnalyticsRowView{
+		Label:   row.Label,
+		Tickets: row.Tickets,
+		Queue:   analyticsPhaseView(row.Queue),
+		Cook:    analyticsPhaseView(row.Cook),
+		Pass:    analyticsPhaseView(row.Pass),
+	}
+}
+
+func analyticsPhaseView(stats kitchenDurationStatsResource) AnalyticsPhaseView {
+	if stats.Count == 0 {
+		return AnalyticsPhaseView{P50: "-", P90: "-", Max: "-"}
+	}
+	return AnalyticsPhaseView{
+		Count: stats.Count,
+		P50:   formatAnalyticsSeconds(stats.P50),
+		P90:   formatAnalyticsSeconds(stats.P90),
+		Max:   formatAnalyticsSeconds(stats.Max),
+	}
+}
+
+// formatAnalyticsSeconds renders a duration as "45s", "12m 05s" or "1h 20m".
+func formatAnalyticsSeconds(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second)).Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// writeAnalyticsCSV writes one line per group plus a final line for all
+// tickets, matching the kitchen service export.
+func writeAnalyticsCSV(w io.Writer, report *kitchenAnalyticsResource) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{report.GroupBy, "tickets"}
+	for _, phase := range []string{"queue", "cook", "pass"} {
+		header = append(header, phase+"_count", phase+"_p50_s", phase+"_p90_s", phase+"_max_s")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	rows := append(append([]kitchenAnalyticsRowResource{}, report.Rows...), report.Overall)
+	for _, row := range rows {
+		record := []string{row.Label, strconv.Itoa(row.Tickets)}
+		for _, stats := range []kitchenDurationStatsResource{row.Queue, row.Cook, row.Pass} {
+			record = append(record,
+				strconv.Itoa(stats.Count),
+				strconv.FormatFloat(stats.P50, 'f', 0, 64),
+				strconv.FormatFloat(stats.P90, 'f', 0, 64),
+				strconv.FormatFloat(stats.Max, 'f', 0, 64),
+			)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,90 @@
+package operations
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFormatAnalyticsSeconds(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0, "0s"},
+		{44.6, "45s"},
+		{725, "12m 05s"},
+		{3600, "1h 00m"},
+		{4830, "1h 20m"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAnalyticsSeconds(tt.seconds); got != tt.want {
+			t.Errorf("formatAnalyticsSeconds(%v) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyticsQuery(t *testing.T) {
+	values := url.Values{
+		"from":     {"2025-03-01"},
+		"to":       {" "},
+		"group_by": {"cook"},
+		"station":  {""},
+		"page":     {"2"},
+	}
+
+	got := analyticsQuery(values)
+	if got.Encode() != "from=2025-03-01&group_by=cook" {
+		t.Errorf("analyticsQuery() = %q, want only the filled kitchen filters", got.Encode())
+	}
+}
+
+func TestAnalyticsRowView(t *testing.T) {
+	row := kitchenAnalyticsRowResource{
+		Label:   "Grill",
+		Tickets: 3,
+		Queue:   kitchenDurationStatsResource{Count: 3, P50: 90, P90: 300, Max: 300},
+	}
+
+	view := analyticsRowView(row)
+	if view.Label != "Grill" || view.Tickets != 3 {
+		t.Errorf("view = %+v, want Grill with 3 tickets", view)
+	}
+	if view.Queue.P50 != "1m 30s" || view.Queue.P90 != "5m 00s" {
+		t.Errorf("queue = %+v, want 1m 30s and 5m 00s", view.Queue)
+	}
+	if view.Cook.P50 != "-" || view.Cook.Max != "-" {
+		t.Errorf("cook = %+v, want dashes without samples", view.Cook)
+	}
+}
+
+func TestWriteAnalyticsCSV(t *testing.T) {
+	report := &kitchenAnalyticsResource{
+		GroupBy: "station",
+		Rows: []kitchenAnalyticsRowResource{
+			{Label: "Bar", Tickets: 1, Queue: kitchenDurationStatsResource{Count: 1, P50: 60, P90: 60, Max: 60}},
+		},
+		Overall: kitchenAnalyticsRowResource{Label: "All tickets", Tickets: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := writeAnalyticsCSV(&buf, report); err != nil {
+		t.Fatalf("writeAnalyticsCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("csv has %d lines, want header, one group and the total", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "station,tickets,queue_count,") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if lines[1] != "Bar,1,1,60,60,60,0,0,0,0,0,0,0,0" {
+		t.Errorf("bar line = %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "All tickets,") {
+		t.Errorf("last line = %q, want the total", lines[2])
+	}
+}
@@ -0,0 +1,236 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
+)
+
+const auditReadPermission = "operations:audit:read"
+
+// auditTimeLayouts are the accepted formats for the from/to filters: RFC3339
+// for API clients, datetime-local and plain dates for the admin page.
+var auditTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+type auditEntryView struct {
+	When    string
+	UserID  string
+	Action  string
+	Target  string
+	Success bool
+	Error   string
+	Payload string
+	Before  string
+	After   string
+}
+
+// AuditLog renders the audit trail page with its filter form.
+func (h *Handler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.AuditLog")
+	defer finish()
+
+	if !h.requirePermission(w, r, auditReadPermission) {
+		return
+	}
+
+	query := r.URL.Query()
+	data := map[string]interface{}{
+		"Title":    "Audit Trail",
+		"Template": "audit",
+		"User":     h.getUserFromSession(r),
+		"Filters": map[string]string{
+			"UserID": query.Get("user_id"),
+			"Action": query.Get("action"),
+			"Target": query.Get("target"),
+			"From":   query.Get("from"),
+			"To":     query.Get("to"),
+			"Limit":  query.Get("limit"),
+		},
+	}
+
+	filter, err := parseAuditQuery(query)
+	if err != nil {
+		data["Error"] = err.Error()
+		h.renderTemplate(w, "audit.html", "base.html", data)
+		return
+	}
+
+	entries, err := h.auditLogger.Query(r.Context(), filter)
+	if err != nil {
+		h.log().Error("cannot query audit trail", "error", err)
+		data["Error"] = "Could not load the audit trail right now."
+		h.renderTemplate(w, "audit.html", "base.html", data)
+		return
+	}
+
+	views := make([]auditEntryView, 0, len(entries))
+	for _, entry := range entries {
+		views = append(views, buildAuditEntryView(entry))
+	}
+	data["Entries"] = views
+
+	h.renderTemplate(w, "audit.html", "base.html", data)
+}
+
+// ListAuditEntries handles GET /api/audit with the user_id, action, target,
+// from, to and limit filters.
+func (h *Handler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.ListAuditEntries")
+	defer finish()
+
+	if !h.requirePermission(w, r, auditReadPermission) {
+		return
+	}
+
+	filter, err := parseAuditQuery(r.URL.Query())
+	if err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.auditLogger.Query(r.Context(), filter)
+	if err != nil {
+		h.log().Error("cannot query audit trail", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not query audit trail")
+		return
+	}
+
+	apt.RespondCollection(w, entries, "audit-entry")
+}
+
+// auditChange records a state-changing HTTP action of the signed-in user.
+func (h *Handler) auditChange(r *http.Request, action, target string, before, after interface{}, err error) {
+	if h.auditLogger == nil {
+		return
+	}
+	h.auditLogger.LogChange(r.Context(), sessionUserID(r), action, target, before, after, err)
+}
+
+// sessionUserID returns the user of the request session, or uuid.Nil.
+func sessionUserID(r *http.Request) uuid.UUID {
+	session, ok := r.Context().Value("session").(*Session)
+	if !ok || session == nil {
+		return uuid.Nil
+	}
+	userID, err := uuid.Parse(session.UserID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return userID
+}
+
+func parseAuditQuery(values url.Values) (AuditQuery, error) {
+	query := AuditQuery{
+		Action: strings.TrimSpace(values.Get("action")),
+		Target: strings.TrimSpace(values.Get("target")),
+	}
+
+	if raw := strings.TrimSpace(values.Get("user_id")); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			return AuditQuery{}, fmt.Errorf("invalid user_id %q", raw)
+		}
+		query.UserID = userID
+	}
+
+	from, err := parseAuditTime(values.Get("from"), false)
+	if err != nil {
+		return AuditQuery{}, err
+	}
+	to, err := parseAuditTime(values.Get("to"), true)
+	if err != nil {
+		return AuditQuery{}, err
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return AuditQuery{}, fmt.Errorf("to must not be before from")
+	}
+	query.From, query.To = from, to
+
+	if raw := strings.TrimSpace(values.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return AuditQuery{}, fmt.Errorf("invalid limit %q", raw)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+// parseAuditTime parses a time filter. A plain date used as the end of a
+// range covers that whole day.
+func parseAuditTime(raw string, endOfRange bool) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range auditTimeLayouts {
+		ts, err := time.ParseInLocation(layout, raw, time.Local)
+		if err != nil {
+			continue
+		}
+		if endOfRange && layout == "2006-01-02" {
+			ts = ts.Add(24*time.Hour - time.Nanosecond)
+		}
+		return ts, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC3339 or YYYY-MM-DD", raw)
+}
+
+func buildAuditEntryView(entry *AuditEntry) auditEntryView {
+	view := auditEntryView{
+		When:    entry.Timestamp.Local().Format("2006-01-02 15:04:05"),
+		UserID:  "-",
+		Action:  entry.Action,
+		Target:  orDash(entry.Target),
+		Success: entry.Success,
+		Error:   entry.Error,
+		Payload: prettyAuditJSON(entry.Payload),
+		Before:  prettyAuditJSON(entry.Before),
+		After:   prettyAuditJSON(entry.After),
+	}
+	if entry.UserID != uuid.Nil {
+		view.UserID = entry.UserID.String()
+	}
+	return view
+}
+
+func prettyAuditJSON(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return out.String()
+}
+
+// responseData returns the payload of a service response, or nil.
+func responseData(resp *apt.SuccessResponse) interface{} {
+	if resp == nil {
+		return nil
+	}
+	return resp.Data
+}
+
+// auditTargetID returns the ID of the resource a service response carries,
+// or fallback when it has none.
+func auditTargetID(resp *apt.SuccessResponse, fallback string) string {
+	if data, ok := responseData(resp).(map[string]interface{}); ok {
+		if id, ok := data["id"].(string); ok && id != "" {
+			return id
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,91 @@
+package operations
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseAuditQuery(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440713"
+
+	tests := []struct {
+		name    string
+		values  url.Values
+		check   func(t *testing.T, q AuditQuery)
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			values: url.Values{},
+			check: func(t *testing.T, q AuditQuery) {
+				if q.UserID != uuid.Nil || !q.From.IsZero() || q.Limit != 0 {
+					t.Errorf("query = %+v, want zero filters", q)
+				}
+			},
+		},
+		{
+			name: "allFilters",
+			values: url.Values{
+				"user_id": {userID},
+				"action":  {" order.close "},
+				"target":  {"o-1"},
+				"from":    {"2025-03-01T22:00:00Z"},
+				"to":      {"2025-03-01T23:00:00Z"},
+				"limit":   {"20"},
+			},
+			check: func(t *testing.T, q AuditQuery) {
+				if q.UserID.String() != userID || q.Action != "order.close" || q.Target != "o-1" || q.Limit != 20 {
+					t.Errorf("query = %+v", q)
+				}
+				if !q.From.Equal(time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC)) {
+					t.Errorf("From = %v", q.From)
+				}
+			},
+		},
+		{
+			name:   "dateOnlyToCoversDay",
+			values: url.Values{"from": {"2025-03-01"}, "to": {"2025-03-01"}},
+			check: func(t *testing.T, q AuditQuery) {
+				if q.To.Sub(q.From) < 23*time.Hour {
+					t.Errorf("range %v - %v should cover the whole day", q.From, q.To)
+				}
+			},
+		},
+		{name: "badUser", values: url.Values{"user_id": {"maria"}}, wantErr: true},
+		{name: "badTime", values: url.Values{"from": {"yesterday"}}, wantErr: true},
+		{name: "inverted", values: url.Values{"from": {"2025-03-02"}, "to": {"2025-03-01"}}, wantErr: true},
+		{name: "badLimit", values: url.Values{"limit": {"0"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseAuditQuery(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAuditQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, q)
+			}
+		})
+	}
+}
+
+func TestBuildAuditEntryView(t *testing.T) {
+	view := buildAuditEntryView(&AuditEntry{
+		Action: "login",
+		Before: []byte(`{"status":"open"}`),
+	})
+
+	if view.UserID != "-" || view.Target != "-" {
+		t.Errorf("view = %+v, want dashes for missing user and target", view)
+	}
+	if view.Before != "{\n  \"status\": \"open\"\n}" {
+		t.Errorf("Before = %q, want indented JSON", view.Before)
+	}
+	if view.After != "" {
+		t.Errorf("After = %q, want empty", view.After)
+	}
+}
@@ -1,14 +1,18 @@
 package operations
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"sort"
 
-	"github.com/appetiteclub/appetite/pkg/enums/station"
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
 )
 
 // Status codes (match Kitchen service status enum)
 const (
+	StatusStandby   = "standby"
 	StatusCreated   = "created"
 	StatusStarted   = "started"
 	StatusReady     = "ready"
@@ -40,12 +44,16 @@ func (h *Handler) KitchenKanban(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Pre-create all stations from enum (so they always show, even without tickets)
+	// Pre-create the venue's stations (so they always show, even without tickets)
 	stationMap := make(map[string]*StationView)
-	for _, s := range station.All {
+	for i, s := range h.stations.All() {
 		stationMap[s.Code()] = &StationView{
 			Station:     s.Code(),
 			StationName: s.Label(),
+			Colour:      s.Colour,
+			Printer:     s.Printer,
+			Capacity:    s.Capacity,
+			position:    i,
 			Columns:     make(map[string]*ColumnView),
 		}
 	}
@@ -62,6 +70,7 @@ func (h *Handler) KitchenKanban(w http.ResponseWriter, r *http.Request) {
 			st = &StationView{
 				Station:     stationCode,
 				StationName: stationCode,
+				position:    len(stationMap),
 				Columns:     make(map[string]*ColumnView),
 			}
 			stationMap[stationCode] = st
@@ -78,6 +87,9 @@ func (h *Handler) KitchenKanban(w http.ResponseWriter, r *http.Request) {
 		}
 
 		st.Columns[status].Tickets = append(st.Columns[status].Tickets, ticket)
+		if status == StatusStarted {
+			st.Working++
+		}
 	}
 
 	// Convert map to slice and sort stations
@@ -86,6 +98,7 @@ func (h *Handler) KitchenKanban(w http.ResponseWriter, r *http.Request) {
 		Code string
 		Name string
 	}{
+		{StatusStandby, "On Hold"},
 		{StatusCreated, "Received"},
 		{StatusStarted, "In Preparation"},
 		{StatusReady, "Ready for Delivery"},
@@ -114,8 +127,11 @@ func (h *Handler) KitchenKanban(w http.ResponseWriter, r *http.Request) {
 		stations = append(stations, station)
 	}
 
-	// Sort stations by name
+	// Keep the order the venue gave its stations
 	sort.Slice(stations, func(i, j int) bool {
+		if stations[i].position != stations[j].position {
+			return stations[i].position < stations[j].position
+		}
 		return stations[i].StationName < stations[j].StationName
 	})
 
@@ -126,6 +142,15 @@ func (h *Handler) KitchenKanban(w http.ResponseWriter, r *http.Request) {
 		"stations": stations,
 	}
 
+	// Menu items the line can offer as substitutes when asking the floor
+	if h.menuClient != nil {
+		if options, err := h.fetchMenuOptions(ctx); err != nil {
+			log.Info("cannot load menu items for substitutes", "error", err)
+		} else {
+			data["MenuItems"] = options
+		}
+	}
+
 	h.renderTemplate(w, "kitchen.html", "base.html", data)
 }
 
@@ -133,8 +158,20 @@ func (h *Handler) KitchenKanban(w http.ResponseWriter, r *http.Request) {
 type StationView struct {
 	Station     string
 	StationName string
+	Colour      string
+	Printer     string
+	Capacity    int // Tickets the station works on at once, 0 for no limit
+	Working     int // Tickets in preparation
 	Columns     map[string]*ColumnView
 	ColumnsList []*ColumnView // Ordered list for rendering
+
+	position int
+}
+
+// AtCapacity reports whether the station is working on as many tickets as
+// it can take.
+func (v *StationView) AtCapacity() bool {
+	return v.Capacity > 0 && v.Working >= v.Capacity
 }
 
 // ColumnView represents a Kanban column (status)
@@ -161,6 +198,95 @@ func getStatusName(status string) string {
 	}
 }
 
+// KitchenExpo shows the pass: open tickets grouped by order and course across
+// stations, so the expo sees which orders can go out and which are waiting
+// on a station.
+func (h *Handler) KitchenExpo(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.KitchenExpo")
+	defer finish()
+
+	log := h.log()
+
+	var orders []kitchenExpoOrderResource
+	if h.kitchenData == nil {
+		log.Info("Kitchen service not configured, showing empty expo")
+	} else {
+		list, err := h.kitchenData.ListExpoOrders(r.Context())
+		if err != nil {
+			log.Errorf("cannot fetch expo orders from kitchen: %v", err)
+		} else {
+			orders = list
+		}
+	}
+
+	data := map[string]interface{}{
+		"Title":    "Kitchen Expo",
+		"Template": "expo",
+		"User":     h.getUserFromSession(r),
+		"orders":   expoOrderViews(orders),
+	}
+
+	h.renderTemplate(w, "kitchen_expo.html", "base.html", data)
+}
+
+// ExpoOrderView is an order card on the expo page.
+type ExpoOrderView struct {
+	OrderID     string
+	ShortID     string
+	TableNumber string
+	Ready       int
+	Total       int
+	Lagging     bool
+	Complete    bool
+	Waiting     string
+	Courses     []kitchenExpoCourseResource
+}
+
+func expoOrderViews(orders []kitchenExpoOrderResource) []ExpoOrderView {
+	views := make([]ExpoOrderView, 0, len(orders))
+	for _, order := range orders {
+		views = append(views, ExpoOrderView{
+			OrderID:     order.OrderID,
+			ShortID:     shortOrderID(order.OrderID),
+			TableNumber: order.TableNumber,
+			Ready:       order.Ready,
+			Total:       order.Total,
+			Lagging:     order.Lagging,
+			Complete:    order.Total > 0 && order.Ready == order.Total,
+			Waiting:     relativeTimeSince(order.FirstAt),
+			Courses:     order.Courses,
+		})
+	}
+	return views
+}
+
+// ProxyKitchenBumpOrder proxies POST /api/kitchen/orders/:id/bump to Kitchen
+// service, delivering every ready ticket of the order at once.
+func (h *Handler) ProxyKitchenBumpOrder(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.ProxyKitchenBumpOrder")
+	defer finish()
+
+	if h.kitchenData == nil {
+		http.Error(w, "Kitchen service not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	orderID := chi.URLParam(r, "id")
+	result, err := h.kitchenData.BumpOrder(r.Context(), orderID)
+	h.auditChange(r, "kitchen-order.bump", orderID, nil, result, err)
+	if err != nil {
+		h.log().Errorf("failed to bump order: %v", err)
+		http.Error(w, "Failed to bump order", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"delivered": len(result.Delivered),
+		"failed":    len(result.Failed),
+	})
+}
+
 // ProxyKitchenTicketStatus proxies PATCH /api/kitchen/tickets/:id/status to Kitchen service
 func (h *Handler) ProxyKitchenTicketStatus(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.http.Start(w, r, "Handler.ProxyKitchenTicketStatus")
@@ -171,9 +297,33 @@ func (h *Handler) ProxyKitchenTicketStatus(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	ticketID := chi.URLParam(r, "id")
+	before, _ := h.kitchenData.GetTicket(r.Context(), ticketID)
+
 	// Forward the request to Kitchen service
 	err := h.kitchenData.UpdateTicketStatus(r.Context(), r)
+	var after *kitchenTicketResource
+	if err == nil {
+		after, _ = h.kitchenData.GetTicket(r.Context(), ticketID)
+	}
+	h.auditChange(r, "kitchen-ticket.status", ticketID, before, after, err)
 	if err != nil {
+		if isKitchenConflict(err) {
+			// Someone moved the ticket since the board loaded; hand back the
+			// ticket as it is now so the board can show it
+			current, _ := h.kitchenData.GetTicket(r.Context(), ticketID)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":  "This ticket was changed by someone else.",
+				"ticket": current,
+			})
+			return
+		}
+		if msg, ok := kitchenRejectionMessage(err); ok {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
 		h.log().Errorf("failed to update ticket status: %v", err)
 		http.Error(w, "Failed to update ticket status", http.StatusInternalServerError)
 		return
@@ -181,3 +331,171 @@ func (h *Handler) ProxyKitchenTicketStatus(w http.ResponseWriter, r *http.Reques
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// ProxyKitchenTicketTransitions proxies GET /api/kitchen/tickets/:id/transitions
+// to Kitchen service, so the board only offers the moves the ticket allows.
+func (h *Handler) ProxyKitchenTicketTransitions(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.ProxyKitchenTicketTransitions")
+	defer finish()
+
+	if h.kitchenData == nil {
+		http.Error(w, "Kitchen service not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	transitions, err := h.kitchenData.ListTicketTransitions(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.log().Errorf("failed to list ticket transitions: %v", err)
+		http.Error(w, "Failed to list ticket transitions", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transitions": transitions,
+	})
+}
+
+// ProxyKitchenRaiseDecision proxies POST /api/kitchen/tickets/:id/decision to
+// Kitchen service, so the line can ask the floor what to do with a ticket.
+func (h *Handler) ProxyKitchenRaiseDecision(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.ProxyKitchenRaiseDecision")
+	defer finish()
+
+	if h.kitchenData == nil {
+		http.Error(w, "Kitchen service not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, ok := body["raised_by"]; !ok {
+		body["raised_by"] = sessionCook(r)
+	}
+
+	ticketID := chi.URLParam(r, "id")
+	before, _ := h.kitchenData.GetTicket(r.Context(), ticketID)
+	after, err := h.kitchenData.RaiseDecision(r.Context(), ticketID, body)
+	h.auditChange(r, "kitchen-ticket.decision", ticketID, before, after, err)
+	if err != nil {
+		if isKitchenConflict(err) {
+			http.Error(w, "This ticket is already waiting for an answer.", http.StatusConflict)
+			return
+		}
+		if msg, ok := kitchenRejectionMessage(err); ok {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+		h.log().Errorf("failed to raise decision: %v", err)
+		http.Error(w, "Failed to ask the floor", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ticket": after,
+	})
+}
+
+// ProxyKitchenAnswerDecision proxies POST /api/kitchen/tickets/:id/decision/answer
+// to Kitchen service. Once a question went unanswered long enough to be
+// escalated, only a manager may answer it.
+func (h *Handler) ProxyKitchenAnswerDecision(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.ProxyKitchenAnswerDecision")
+	defer finish()
+
+	if h.kitchenData == nil {
+		http.Error(w, "Kitchen service not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		OptionID string `json:"option_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OptionID == "" {
+		http.Error(w, "Choose an answer", http.StatusBadRequest)
+		return
+	}
+
+	ticketID := chi.URLParam(r, "id")
+	before, err := h.kitchenData.GetTicket(r.Context(), ticketID)
+	if err != nil {
+		h.log().Errorf("failed to load ticket: %v", err)
+		http.Error(w, "Failed to load ticket", http.StatusBadGateway)
+		return
+	}
+	if status, msg := h.decisionAnswerPreflight(r, before); status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
+
+	after, err := h.kitchenData.AnswerDecision(r.Context(), ticketID, req.OptionID, sessionCook(r))
+	h.auditChange(r, "kitchen-ticket.answer", ticketID, before, after, err)
+	if err != nil {
+		if isKitchenConflict(err) {
+			http.Error(w, "Someone already answered this question.", http.StatusConflict)
+			return
+		}
+		if msg, ok := kitchenRejectionMessage(err); ok {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+		h.log().Errorf("failed to answer decision: %v", err)
+		http.Error(w, "Failed to answer the kitchen", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ticket": after,
+	})
+}
+
+// decisionAnswerPreflight checks the ticket still waits on a question the
+// user may answer. It returns status 0 when the answer can go ahead, or the
+// status and message to refuse it with.
+func (h *Handler) decisionAnswerPreflight(r *http.Request, ticket *kitchenTicketResource) (int, string) {
+	decision := ticket.PendingDecision()
+	if decision == nil {
+		return http.StatusConflict, "Someone already answered this question."
+	}
+	if decision.EscalatedAt != nil {
+		if status, _ := h.preflight(r, menuOverridePermission); status != 0 {
+			return http.StatusForbidden, "This question went to a manager. Ask one to answer it."
+		}
+	}
+	return 0, ""
+}
+
+// isKitchenConflict reports whether the kitchen refused a ticket update
+// because the ticket changed since the caller read it.
+func isKitchenConflict(err error) bool {
+	var httpErr *apt.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusConflict
+}
+
+// kitchenRejectionMessage returns the reason the kitchen refused a ticket
+// update, such as a move the ticket state machine does not allow.
+func kitchenRejectionMessage(err error) (string, bool) {
+	var httpErr *apt.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		return "", false
+	}
+	return httpErr.Message, true
+}
+
+// sessionCook returns the name the kitchen shows for the signed-in user.
+func sessionCook(r *http.Request) string {
+	session, ok := r.Context().Value("session").(*Session)
+	if !ok || session == nil {
+		return ""
+	}
+	if session.Name != "" {
+		return session.Name
+	}
+	return session.Username
+}
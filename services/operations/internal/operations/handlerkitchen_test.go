@@ -1,7 +1,14 @@
 package operations
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/appetiteclub/apt"
 )
 
 func TestGetStatusName(t *testing.T) {
@@ -112,3 +119,73 @@ func TestStatusConstants(t *testing.T) {
 		t.Errorf("StatusCancelled = %q, want %q", StatusCancelled, "cancelled")
 	}
 }
+
+func TestKitchenRejectionMessage(t *testing.T) {
+	rejected := fmt.Errorf("kitchen service request failed: %w", &apt.HTTPError{StatusCode: http.StatusBadRequest, Message: "invalid ticket transition: created to ready"})
+	got, ok := kitchenRejectionMessage(rejected)
+	if !ok || got != "invalid ticket transition: created to ready" {
+		t.Errorf("kitchenRejectionMessage() = %q, %v", got, ok)
+	}
+
+	if _, ok := kitchenRejectionMessage(&apt.HTTPError{StatusCode: http.StatusInternalServerError, Message: "boom"}); ok {
+		t.Error("kitchenRejectionMessage() accepted a server error")
+	}
+	if _, ok := kitchenRejectionMessage(errors.New("connection refused")); ok {
+		t.Error("kitchenRejectionMessage() accepted a transport error")
+	}
+}
+
+func TestIsKitchenConflict(t *testing.T) {
+	conflict := fmt.Errorf("kitchen service request failed: %w", &apt.HTTPError{StatusCode: http.StatusConflict, Message: "Ticket was changed by someone else"})
+	if !isKitchenConflict(conflict) {
+		t.Error("isKitchenConflict() missed a wrapped 409")
+	}
+	if isKitchenConflict(&apt.HTTPError{StatusCode: http.StatusBadRequest, Message: "invalid ticket transition"}) {
+		t.Error("isKitchenConflict() accepted a 400")
+	}
+	if isKitchenConflict(errors.New("connection refused")) {
+		t.Error("isKitchenConflict() accepted a transport error")
+	}
+}
+
+func TestExpoOrderViews(t *testing.T) {
+	orders := []kitchenExpoOrderResource{
+		{OrderID: "7a1b2c3d-0000-0000-0000-000000000001", TableNumber: "7", Ready: 2, Total: 2},
+		{OrderID: "8b2c3d4e-0000-0000-0000-000000000002", Ready: 1, Total: 3, Lagging: true},
+	}
+
+	views := expoOrderViews(orders)
+	if len(views) != 2 {
+		t.Fatalf("expoOrderViews() returned %d views, want 2", len(views))
+	}
+	if !views[0].Complete || views[0].Lagging {
+		t.Errorf("first order complete = %v, lagging = %v, want complete", views[0].Complete, views[0].Lagging)
+	}
+	if views[1].Complete || !views[1].Lagging || views[1].ShortID != "8B2C3D4E" {
+		t.Errorf("second order = %+v, want lagging 8B2C3D4E", views[1])
+	}
+}
+
+func TestSessionCook(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *Session
+		want    string
+	}{
+		{name: "noSession", want: ""},
+		{name: "name", session: &Session{Name: "Ana Lopez", Username: "ana"}, want: "Ana Lopez"},
+		{name: "username", session: &Session{Username: "ana"}, want: "ana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/api/kitchen/tickets/t-1/status", nil)
+			if tt.session != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "session", tt.session))
+			}
+			if got := sessionCook(req); got != tt.want {
+				t.Errorf("sessionCook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,18 +3,26 @@ package operations
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg/enums/station"
 	"github.com/appetiteclub/apt"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
+// menuOverridePermission lets a manager order items outside the menu's
+// visibility windows.
+const menuOverridePermission = "orders:manage"
+
 // Orders view state mirrors the tables view flash handling.
 type ordersPageState struct {
 	Error   string
@@ -75,8 +83,28 @@ type orderItemView struct {
 	Category           string
 	GroupName          string
 	Notes              string
+	Modifiers          []string
+	PortionName        string
+	Course             int
+	Held               bool
+	Late               bool
 	CreatedAt          string
 	RequiresProduction bool
+	Decision           *orderDecisionView // Question the kitchen waits on, if any
+}
+
+// orderDecisionView is a question the kitchen asked the floor about an item.
+type orderDecisionView struct {
+	TicketID  string
+	Question  string
+	RaisedBy  string
+	Escalated bool // Only a manager may answer now
+	Options   []orderDecisionOptionView
+}
+
+type orderDecisionOptionView struct {
+	ID    string
+	Label string
 }
 
 type orderSummaryView struct {
@@ -113,11 +141,13 @@ type orderEventView struct {
 
 // Lightweight DTOs for decoding service responses.
 type menuItemResource struct {
-	ID        string              `json:"id"`
-	ShortCode string              `json:"short_code"`
-	Name      map[string]string   `json:"name"`
-	Prices    []menuPriceResource `json:"prices"`
-	Tags      []string            `json:"tags"`
+	ID             string                      `json:"id"`
+	ShortCode      string                      `json:"short_code"`
+	Name           map[string]string           `json:"name"`
+	Prices         []menuPriceResource         `json:"prices"`
+	Tags           []string                    `json:"tags"`
+	ModifierGroups []menuModifierGroupResource `json:"modifier_groups"`
+	Portions       []menuPortionResource       `json:"portions"`
 }
 
 type menuPriceResource struct {
@@ -125,6 +155,30 @@ type menuPriceResource struct {
 	CurrencyCode string  `json:"currency_code"`
 }
 
+type menuModifierGroupResource struct {
+	ID            string                       `json:"id"`
+	Name          map[string]string            `json:"name"`
+	MinSelections int                          `json:"min_selections"`
+	MaxSelections int                          `json:"max_selections"`
+	Required      bool                         `json:"required"`
+	Options       []menuModifierOptionResource `json:"options"`
+}
+
+type menuModifierOptionResource struct {
+	ID          string              `json:"id"`
+	Name        map[string]string   `json:"name"`
+	PriceDeltas []menuPriceResource `json:"price_deltas"`
+	Active      bool                `json:"active"`
+}
+
+type menuPortionResource struct {
+	ID            string              `json:"id"`
+	Name          map[string]string   `json:"name"`
+	PriceOverride []menuPriceResource `json:"price_override"`
+	PrepTime      int                 `json:"prep_time"`
+	Active        bool                `json:"active"`
+}
+
 // Order creation modal payload.
 type orderFormModal struct {
 	Title         string
@@ -157,15 +211,54 @@ type orderItemFormModal struct {
 	DisplayPrice   string
 	DisplayRouting string
 	MenuQuery      string
+	// ManagerOverride orders the item even when the menu does not offer it now
+	ManagerOverride bool
+	// ModifierGroups of the selected item; SelectedModifiers keeps the picks
+	// when the form is shown again
+	ModifierGroups    []modifierGroupView
+	SelectedModifiers map[string]bool
+	// Portions of the selected item; SelectedPortion keeps the pick
+	Portions        []portionOptionView
+	SelectedPortion string
+	// Course the item is served in; empty sends it to the kitchen right away
+	Course string
 }
 
 type menuItemOption struct {
-	ID        string
-	Label     string
-	Price     float64
-	Currency  string
-	Routing   string
-	ShortCode string
+	ID             string
+	Name           string
+	Label          string
+	Price          float64
+	Currency       string
+	Routing        string
+	ShortCode      string
+	ModifierGroups []menuModifierGroupResource
+	Portions       []menuPortionResource
+}
+
+// modifierGroupView is a modifier group rendered in the order item form.
+// Single groups take exactly one pick and render as radio buttons.
+type modifierGroupView struct {
+	ID       string
+	Label    string
+	Hint     string
+	Required bool
+	Single   bool
+	Options  []modifierOptionView
+}
+
+type modifierOptionView struct {
+	ID       string
+	Label    string
+	Selected bool
+}
+
+// portionOptionView is a portion rendered in the order item form, labelled
+// with the price it sells for.
+type portionOptionView struct {
+	ID       string
+	Label    string
+	Selected bool
 }
 
 // Order group creation modal payload.
@@ -457,6 +550,17 @@ func (h *Handler) buildOrderCard(order orderResource, table *tableResource, item
 		groupLookups[group.ID] = &groupCopy
 	}
 
+	lateItems := map[string]bool{}
+	decisions := map[string]*orderDecisionView{}
+	for _, ticket := range tickets {
+		if ticket.LateAt != nil && ticket.FinishedAt == nil {
+			lateItems[ticket.OrderItemID] = true
+		}
+		if decision := ticket.PendingDecision(); decision != nil {
+			decisions[ticket.OrderItemID] = orderDecision(ticket.ID, decision)
+		}
+	}
+
 	itemViews := make([]orderItemView, 0, len(items))
 	for _, item := range items {
 		statusKey := strings.ToLower(item.Status)
@@ -485,8 +589,14 @@ func (h *Handler) buildOrderCard(order orderResource, table *tableResource, item
 			Category:           item.Category,
 			GroupName:          groupName,
 			Notes:              item.Notes,
+			Modifiers:          modifierLabels(item.Modifiers),
+			PortionName:        item.PortionName,
+			Course:             item.Course,
+			Held:               item.Held,
+			Late:               lateItems[item.ID],
 			CreatedAt:          relativeTimeSince(item.CreatedAt),
 			RequiresProduction: requiresProduction,
+			Decision:           decisions[item.ID],
 		}
 
 		if itemView.StatusLabel == "" {
@@ -581,6 +691,20 @@ func (h *Handler) buildOrderCard(order orderResource, table *tableResource, item
 	return card
 }
 
+func orderDecision(ticketID string, decision *kitchenDecisionResource) *orderDecisionView {
+	view := &orderDecisionView{
+		TicketID:  ticketID,
+		Question:  decision.Question,
+		RaisedBy:  decision.RaisedBy,
+		Escalated: decision.EscalatedAt != nil,
+		Options:   make([]orderDecisionOptionView, 0, len(decision.Options)),
+	}
+	for _, option := range decision.Options {
+		view.Options = append(view.Options, orderDecisionOptionView{ID: option.ID, Label: option.Label})
+	}
+	return view
+}
+
 func (h *Handler) buildOrderEvents(order orderResource, table *tableResource, items []orderItemResource, tickets []kitchenTicketResource, statusLabel string) []orderEventView {
 	type timelineEvent struct {
 		message  string
@@ -844,6 +968,7 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	order, err := h.orderData.CreateOrder(r.Context(), CreateOrderRequest{TableID: tableID})
 	if err != nil {
 		h.log().Error("order service create failed", "table_id", tableID, "error", err)
+		h.auditChange(r, "order.create", tableID, nil, CreateOrderRequest{TableID: tableID}, err)
 		if isHTMX {
 			http.Error(w, "Could not open the order right now.", http.StatusInternalServerError)
 		} else {
@@ -851,6 +976,7 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	h.auditChange(r, "order.create", order.ID, nil, order, nil)
 
 	if isHTMX {
 		items := []orderItemResource{}
@@ -983,17 +1109,28 @@ func (h *Handler) CreateOrderItem(w http.ResponseWriter, r *http.Request) {
 	notes := strings.TrimSpace(r.FormValue("notes"))
 	groupIDStr := strings.TrimSpace(r.FormValue("group_id"))
 	menuQuery := strings.TrimSpace(r.FormValue("menu_item_query"))
+	managerOverride := r.FormValue("manager_override") != ""
+	modifierIDs := parseModifierSelections(r.Form)
+	portionID := strings.TrimSpace(r.FormValue("portion_id"))
+	course := strings.TrimSpace(r.FormValue("course"))
 
 	form := orderItemFormModal{
-		Title:        fmt.Sprintf("Add Item to %s", shortOrderID(orderID)),
-		Action:       fmt.Sprintf("/orders/%s/items", orderID),
-		OrderID:      orderID,
-		MenuItems:    []menuItemOption{},
-		Quantity:     quantityStr,
-		Notes:        notes,
-		GroupID:      groupIDStr,
-		SelectedMenu: menuItemID,
-		MenuQuery:    menuQuery,
+		Title:           fmt.Sprintf("Add Item to %s", shortOrderID(orderID)),
+		Action:          fmt.Sprintf("/orders/%s/items", orderID),
+		OrderID:         orderID,
+		MenuItems:       []menuItemOption{},
+		Quantity:        quantityStr,
+		Notes:           notes,
+		GroupID:         groupIDStr,
+		SelectedMenu:    menuItemID,
+		MenuQuery:       menuQuery,
+		ManagerOverride: managerOverride,
+		SelectedPortion: portionID,
+		Course:          course,
+	}
+	form.SelectedModifiers = make(map[string]bool, len(modifierIDs))
+	for _, id := range modifierIDs {
+		form.SelectedModifiers[id] = true
 	}
 
 	if menuItemID == "" {
@@ -1007,6 +1144,13 @@ func (h *Handler) CreateOrderItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if managerOverride {
+		if status, _ := h.preflight(r, menuOverridePermission); status != 0 {
+			h.handleOrderItemFormError(w, form, "Only managers can order items the menu does not offer right now.")
+			return
+		}
+	}
+
 	menuItem, err := h.fetchMenuItem(r.Context(), menuItemID)
 	if err != nil {
 		h.log().Error("cannot load menu item", "menu_item_id", menuItemID, "error", err)
@@ -1014,8 +1158,8 @@ func (h *Handler) CreateOrderItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	price := pickMenuPrice(menuItem)
-	routing := deriveStation(menuItem)
+	price := pickMenuPrice(menuItem, portionID)
+	routing := deriveStation(h.stations, menuItem)
 	form.DisplayPrice = formatMoney(price)
 	form.DisplayRouting = routingLabel(routing)
 
@@ -1024,41 +1168,48 @@ func (h *Handler) CreateOrderItem(w http.ResponseWriter, r *http.Request) {
 		groupIDStr = defaultGroup
 	}
 
-	dishName := pickMenuName(menuItem)
-	requiresProduction := routing != "direct" && routing != ""
-
-	payload := map[string]interface{}{
-		"dish_name":          dishName,
-		"category":           routing,
-		"quantity":           quantity,
-		"price":              price,
-		"menu_item_id":       menuItemID,
-		"requires_production": requiresProduction,
-	}
+	payload := orderItemPayload(h.stations, menuItem, quantity, portionID)
+	payload["menu_item_id"] = menuItemID
 
 	if notes != "" {
 		payload["notes"] = notes
 	}
 
+	if managerOverride {
+		payload["manager_override"] = true
+	}
+
+	if course != "" {
+		payload["course"] = course
+	}
+
+	if len(modifierIDs) > 0 {
+		payload["modifier_option_ids"] = modifierIDs
+	}
+
 	if groupIDStr != "" {
 		if _, err := uuid.Parse(groupIDStr); err == nil {
 			payload["group_id"] = groupIDStr
 		}
 	}
 
-	// Get production station ID from dictionary if needed
-	if requiresProduction {
-		if stationID, err := h.getStationIDByName(r.Context(), routing); err == nil && stationID != "" {
-			payload["production_station"] = stationID
-		}
-	}
-
 	path := fmt.Sprintf("/orders/%s/items", orderID)
-	if _, err := h.orderClient.Request(r.Context(), "POST", path, payload); err != nil {
+	resp, err := h.orderClient.Request(r.Context(), "POST", path, payload)
+	if err != nil {
 		h.log().Error("order item creation failed", "order_id", orderID, "error", err)
+		h.auditChange(r, "order-item.create", orderID, nil, payload, err)
+		if message, ok := menuUnavailableMessage(err); ok {
+			h.handleOrderItemFormError(w, form, message)
+			return
+		}
+		if message, ok := modifierRejectionMessage(err); ok {
+			h.handleOrderItemFormError(w, form, message)
+			return
+		}
 		h.handleOrderItemFormError(w, form, "Could not add the item right now.")
 		return
 	}
+	h.auditChange(r, "order-item.create", auditTargetID(resp, orderID), nil, responseData(resp), nil)
 
 	if apt.IsHTMX(r) {
 		h.renderOrderModalFor(r.Context(), orderID, w, r)
@@ -1068,6 +1219,118 @@ func (h *Handler) CreateOrderItem(w http.ResponseWriter, r *http.Request) {
 	apt.RedirectOrHeader(w, r, "/orders?item_added=1")
 }
 
+// menuUnavailableMessage turns the order service rejection of an item the
+// menu does not offer right now into a message for the form.
+func menuUnavailableMessage(err error) (string, bool) {
+	var httpErr *apt.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusConflict {
+		return "", false
+	}
+	return fmt.Sprintf("%s. A manager can tick the override to order it anyway.", strings.TrimSuffix(httpErr.Message, ".")), true
+}
+
+// modifierRejectionMessage turns the order service rejection of picks that
+// break the item's modifier groups into a message for the form.
+func modifierRejectionMessage(err error) (string, bool) {
+	var httpErr *apt.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnprocessableEntity {
+		return "", false
+	}
+	return fmt.Sprintf("Check the options: %s.", strings.TrimSuffix(httpErr.Message, ".")), true
+}
+
+// modifierGroupPrefix prefixes the form field of each modifier group, so
+// radio buttons of different groups do not clash.
+const modifierGroupPrefix = "modifier_group_"
+
+// parseModifierSelections collects the picked option IDs of every modifier
+// group field, sorted so payloads are stable.
+func parseModifierSelections(form url.Values) []string {
+	ids := []string{}
+	for key, values := range form {
+		if !strings.HasPrefix(key, modifierGroupPrefix) {
+			continue
+		}
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				ids = append(ids, value)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// modifierGroupViews prepares the item's modifier groups for the form,
+// showing price deltas in the item currency and hiding inactive options.
+func modifierGroupViews(groups []menuModifierGroupResource, currency string, selected map[string]bool) []modifierGroupView {
+	views := make([]modifierGroupView, 0, len(groups))
+	for _, group := range groups {
+		minPicks := group.MinSelections
+		if group.Required && minPicks < 1 {
+			minPicks = 1
+		}
+
+		view := modifierGroupView{
+			ID:       group.ID,
+			Label:    localizedLabel(group.Name),
+			Required: minPicks > 0,
+			Single:   minPicks == 1 && group.MaxSelections == 1,
+		}
+		switch {
+		case view.Single:
+		case minPicks > 0 && group.MaxSelections > 0:
+			view.Hint = fmt.Sprintf("Pick %d to %d", minPicks, group.MaxSelections)
+		case minPicks > 0:
+			view.Hint = fmt.Sprintf("Pick at least %d", minPicks)
+		case group.MaxSelections > 0:
+			view.Hint = fmt.Sprintf("Pick up to %d", group.MaxSelections)
+		}
+
+		for _, option := range group.Options {
+			if !option.Active {
+				continue
+			}
+			label := localizedLabel(option.Name)
+			for _, delta := range option.PriceDeltas {
+				if delta.Amount != 0 && strings.EqualFold(delta.CurrencyCode, currency) {
+					sign := "+"
+					if delta.Amount < 0 {
+						sign = "-"
+					}
+					label = fmt.Sprintf("%s (%s%s)", label, sign, formatMoney(math.Abs(delta.Amount)))
+				}
+			}
+			view.Options = append(view.Options, modifierOptionView{
+				ID:       option.ID,
+				Label:    label,
+				Selected: selected[option.ID],
+			})
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// localizedLabel picks the English text, falling back to the first language
+// in alphabetical order.
+func localizedLabel(names map[string]string) string {
+	if name := names["en"]; name != "" {
+		return name
+	}
+	langs := make([]string, 0, len(names))
+	for lang := range names {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		if names[lang] != "" {
+			return names[lang]
+		}
+	}
+	return ""
+}
+
 func (h *Handler) renderOrderItemForm(w http.ResponseWriter, data orderItemFormModal) {
 	h.enrichOrderItemForm(context.Background(), &data)
 
@@ -1121,14 +1384,17 @@ func (h *Handler) fetchMenuOptions(ctx context.Context) ([]menuItemOption, error
 		if item.ShortCode != "" {
 			label = fmt.Sprintf("%s — %s (%s)", item.ShortCode, name, formatMoney(price))
 		}
-		routing := deriveStation(&item)
+		routing := deriveStation(h.stations, &item)
 		options = append(options, menuItemOption{
-			ID:        item.ID,
-			Label:     label,
-			Price:     price,
-			Currency:  currency,
-			Routing:   routing,
-			ShortCode: item.ShortCode,
+			ID:             item.ID,
+			Name:           name,
+			Label:          label,
+			Price:          price,
+			Currency:       currency,
+			Routing:        routing,
+			ShortCode:      item.ShortCode,
+			ModifierGroups: item.ModifierGroups,
+			Portions:       item.Portions,
 		})
 	}
 
@@ -1177,23 +1443,69 @@ func pickMenuName(item *menuItemResource) string {
 	return "Menu Item"
 }
 
-func pickMenuPrice(item *menuItemResource) float64 {
+// pickMenuPrice returns the unit price of the item in its first currency,
+// taken from the portion override when the picked portion has one.
+func pickMenuPrice(item *menuItemResource, portionID string) float64 {
 	if item == nil || len(item.Prices) == 0 {
 		return 0
 	}
-	return item.Prices[0].Amount
+	return portionPrice(item.Prices[0], item.Portions, portionID)
 }
 
-func deriveStation(item *menuItemResource) string {
+// portionPrice returns the portion override in the currency of the base
+// price, or the base price when the portion does not override it.
+func portionPrice(base menuPriceResource, portions []menuPortionResource, portionID string) float64 {
+	if portionID == "" {
+		return base.Amount
+	}
+	for _, portion := range portions {
+		if portion.ID != portionID {
+			continue
+		}
+		for _, price := range portion.PriceOverride {
+			if strings.EqualFold(price.CurrencyCode, base.CurrencyCode) {
+				return price.Amount
+			}
+		}
+	}
+	return base.Amount
+}
+
+// portionViews prepares the active portions of an item for the form, each
+// labelled with its price in the base price currency.
+func portionViews(portions []menuPortionResource, base menuPriceResource, selected string) []portionOptionView {
+	views := make([]portionOptionView, 0, len(portions))
+	for _, portion := range portions {
+		if !portion.Active {
+			continue
+		}
+		price := portionPrice(base, portions, portion.ID)
+		views = append(views, portionOptionView{
+			ID:       portion.ID,
+			Label:    fmt.Sprintf("%s (%s)", localizedLabel(portion.Name), formatMoney(price)),
+			Selected: portion.ID == selected,
+		})
+	}
+	return views
+}
+
+// deriveStation returns where a menu item is made: the station tagged on
+// the item when the venue has it, the fallback station when it does not,
+// and "direct" for items that skip production.
+func deriveStation(stations *station.Registry, item *menuItemResource) string {
 	if item == nil {
-		return "kitchen"
+		return stations.Resolve("")
 	}
 	for _, tag := range item.Tags {
 		if strings.HasPrefix(tag, "station:") {
-			return strings.TrimPrefix(tag, "station:")
+			code := strings.TrimPrefix(tag, "station:")
+			if strings.EqualFold(code, "direct") {
+				return "direct"
+			}
+			return stations.Resolve(code)
 		}
 	}
-	return "kitchen"
+	return stations.Resolve("")
 }
 
 func routingLabel(value string) string {
@@ -1207,25 +1519,28 @@ func routingLabel(value string) string {
 	}
 }
 
-func (h *Handler) getStationIDByName(ctx context.Context, stationName string) (string, error) {
-	stationName = strings.ToLower(strings.TrimSpace(stationName))
+// orderItemPayload builds the order service payload for quantity units of a
+// menu item, routed to the station tagged on the item. An empty portionID
+// orders the item at its base price.
+func orderItemPayload(stations *station.Registry, item *menuItemResource, quantity int, portionID string) map[string]interface{} {
+	routing := deriveStation(stations, item)
+	requiresProduction := routing != "direct" && routing != ""
 
-	// Known stations from enum
-	knownStations := map[string]bool{
-		"kitchen": true,
-		"dessert": true,
-		"bar":     true,
-		"coffee":  true,
-		"other":   true,
+	payload := map[string]interface{}{
+		"dish_name":           pickMenuName(item),
+		"category":            routing,
+		"quantity":            quantity,
+		"price":               pickMenuPrice(item, portionID),
+		"menu_item_id":        item.ID,
+		"requires_production": requiresProduction,
 	}
-
-	// If station is known, return it
-	if knownStations[stationName] {
-		return stationName, nil
+	if portionID != "" {
+		payload["portion_id"] = portionID
 	}
-
-	// Map unknown/invalid stations to "other"
-	return "other", nil
+	if requiresProduction {
+		payload["production_station"] = routing
+	}
+	return payload
 }
 
 func defaultMenuSelection(options []menuItemOption) string {
@@ -1286,8 +1601,11 @@ func (form *orderItemFormModal) populateOrderItemPreview() {
 	}
 	for _, opt := range form.MenuItems {
 		if opt.ID == form.SelectedMenu {
-			form.DisplayPrice = formatMoney(opt.Price)
+			base := menuPriceResource{Amount: opt.Price, CurrencyCode: opt.Currency}
+			form.DisplayPrice = formatMoney(portionPrice(base, opt.Portions, form.SelectedPortion))
 			form.DisplayRouting = routingLabel(opt.Routing)
+			form.ModifierGroups = modifierGroupViews(opt.ModifierGroups, opt.Currency, form.SelectedModifiers)
+			form.Portions = portionViews(opt.Portions, base, form.SelectedPortion)
 			return
 		}
 	}
@@ -1396,11 +1714,14 @@ func (h *Handler) CreateOrderGroup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	path := fmt.Sprintf("/orders/%s/groups", order.ID)
-	if _, err := h.orderClient.Request(r.Context(), "POST", path, body); err != nil {
+	resp, err := h.orderClient.Request(r.Context(), "POST", path, body)
+	if err != nil {
 		h.log().Error("order service group create failed", "order_id", order.ID, "error", err)
+		h.auditChange(r, "order-group.create", order.ID, nil, body, err)
 		h.handleOrderGroupFormError(w, form, "Could not create the group right now.")
 		return
 	}
+	h.auditChange(r, "order-group.create", auditTargetID(resp, order.ID), nil, responseData(resp), nil)
 
 	if apt.IsHTMX(r) {
 		h.renderOrderModalFor(r.Context(), order.ID, w, r)
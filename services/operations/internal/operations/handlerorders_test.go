@@ -1,8 +1,16 @@
 package operations
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/appetiteclub/appetite/pkg/enums/station"
+	"github.com/appetiteclub/apt"
 )
 
 func TestSummarizePrep(t *testing.T) {
@@ -335,6 +343,71 @@ func TestBuildOrderCardTotalsAndEvents(t *testing.T) {
 	}
 }
 
+func TestBuildOrderCardFlagsLateItems(t *testing.T) {
+	handler := &Handler{}
+	now := time.Now()
+	order := orderResource{ID: "order-1", Status: "pending", CreatedAt: now.Add(-time.Hour), UpdatedAt: now}
+	items := []orderItemResource{
+		{ID: "item-1", DishName: "Steak", Quantity: 1, Price: 20, Status: "preparing", CreatedAt: now.Add(-40 * time.Minute)},
+		{ID: "item-2", DishName: "Salad", Quantity: 1, Price: 8, Status: "preparing", CreatedAt: now.Add(-40 * time.Minute)},
+		{ID: "item-3", DishName: "Soup", Quantity: 1, Price: 6, Status: "ready", CreatedAt: now.Add(-40 * time.Minute)},
+	}
+	lateAt := now.Add(-5 * time.Minute)
+	tickets := []kitchenTicketResource{
+		{ID: "ticket-1", OrderItemID: "item-1", Status: "started", CreatedAt: now.Add(-40 * time.Minute), LateAt: &lateAt},
+		{ID: "ticket-2", OrderItemID: "item-2", Status: "started", CreatedAt: now.Add(-40 * time.Minute)},
+		{ID: "ticket-3", OrderItemID: "item-3", Status: "ready", CreatedAt: now.Add(-40 * time.Minute), LateAt: &lateAt, FinishedAt: &now},
+	}
+
+	card := handler.buildOrderCard(order, nil, items, nil, tickets)
+
+	want := map[string]bool{"item-1": true, "item-2": false, "item-3": false}
+	for _, item := range card.Items {
+		if item.Late != want[item.ID] {
+			t.Errorf("item %s Late = %v, want %v", item.ID, item.Late, want[item.ID])
+		}
+	}
+}
+
+func TestBuildOrderCardShowsKitchenDecisions(t *testing.T) {
+	handler := &Handler{}
+	now := time.Now()
+	order := orderResource{ID: "order-1", Status: "pending", CreatedAt: now.Add(-time.Hour), UpdatedAt: now}
+	items := []orderItemResource{
+		{ID: "item-1", DishName: "Salmon", Quantity: 1, Price: 24, Status: "preparing", CreatedAt: now},
+		{ID: "item-2", DishName: "Salad", Quantity: 1, Price: 8, Status: "preparing", CreatedAt: now},
+	}
+	tickets := []kitchenTicketResource{
+		{
+			ID: "ticket-1", OrderItemID: "item-1", Status: "started", CreatedAt: now,
+			DecisionRequired: true,
+			DecisionPayload:  []byte(`{"question":"Out of salmon","raised_by":"Ana","options":[{"id":"1","label":"Substitute with trout","outcome":"substitute"},{"id":"2","label":"Cancel the item","outcome":"cancel"}]}`),
+		},
+		{ID: "ticket-2", OrderItemID: "item-2", Status: "started", CreatedAt: now, DecisionPayload: []byte(`{"question":"Answered already"}`)},
+	}
+
+	card := handler.buildOrderCard(order, nil, items, nil, tickets)
+
+	for _, item := range card.Items {
+		switch item.ID {
+		case "item-1":
+			if item.Decision == nil {
+				t.Fatalf("item-1 Decision = nil, want pending question")
+			}
+			if item.Decision.TicketID != "ticket-1" || item.Decision.Question != "Out of salmon" || item.Decision.Escalated {
+				t.Errorf("item-1 Decision = %+v", item.Decision)
+			}
+			if len(item.Decision.Options) != 2 || item.Decision.Options[0].Label != "Substitute with trout" {
+				t.Errorf("item-1 Decision options = %+v", item.Decision.Options)
+			}
+		case "item-2":
+			if item.Decision != nil {
+				t.Errorf("item-2 Decision = %+v, want nil", item.Decision)
+			}
+		}
+	}
+}
+
 func TestOrderItemFormEnsureGroupSelection(t *testing.T) {
 	form := orderItemFormModal{
 		Groups: []orderGroupResource{
@@ -357,3 +430,197 @@ func TestOrderItemFormEnsureGroupSelection(t *testing.T) {
 		t.Fatalf("expected fallback to first group, got %s", form2.GroupID)
 	}
 }
+
+func TestMenuUnavailableMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "conflict",
+			err:    &apt.HTTPError{StatusCode: http.StatusConflict, Message: "menu item is not available: EGGS-001 is not offered at this time (UTC)"},
+			want:   "menu item is not available: EGGS-001 is not offered at this time (UTC). A manager can tick the override to order it anyway.",
+			wantOK: true,
+		},
+		{
+			name:   "wrappedConflict",
+			err:    fmt.Errorf("order service: %w", &apt.HTTPError{StatusCode: http.StatusConflict, Message: "EGGS-001 is inactive."}),
+			want:   "EGGS-001 is inactive. A manager can tick the override to order it anyway.",
+			wantOK: true,
+		},
+		{
+			name: "otherStatus",
+			err:  &apt.HTTPError{StatusCode: http.StatusBadRequest, Message: "table is closed"},
+		},
+		{
+			name: "plainError",
+			err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := menuUnavailableMessage(tt.err)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("menuUnavailableMessage() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestModifierRejectionMessage(t *testing.T) {
+	got, ok := modifierRejectionMessage(&apt.HTTPError{StatusCode: http.StatusUnprocessableEntity, Message: "pick at least 1 for Cooking point"})
+	if !ok || got != "Check the options: pick at least 1 for Cooking point." {
+		t.Errorf("modifierRejectionMessage() = (%q, %v)", got, ok)
+	}
+
+	if _, ok := modifierRejectionMessage(&apt.HTTPError{StatusCode: http.StatusConflict, Message: "inactive"}); ok {
+		t.Error("modifierRejectionMessage() should ignore other statuses")
+	}
+}
+
+func TestParseModifierSelections(t *testing.T) {
+	form := url.Values{
+		"modifier_group_g1": {"opt-b"},
+		"modifier_group_g2": {"opt-c", "opt-a", " "},
+		"notes":             {"no salt"},
+	}
+
+	got := parseModifierSelections(form)
+	want := []string{"opt-a", "opt-b", "opt-c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseModifierSelections() = %v, want %v", got, want)
+	}
+}
+
+func TestModifierGroupViews(t *testing.T) {
+	groups := []menuModifierGroupResource{
+		{
+			ID:            "cooking",
+			Name:          map[string]string{"es": "Punto", "en": "Cooking point"},
+			Required:      true,
+			MaxSelections: 1,
+			Options: []menuModifierOptionResource{
+				{ID: "rare", Name: map[string]string{"en": "Rare"}, Active: true},
+				{ID: "well", Name: map[string]string{"en": "Well done"}, Active: false},
+			},
+		},
+		{
+			ID:            "extras",
+			Name:          map[string]string{"es": "Extras"},
+			MaxSelections: 2,
+			Options: []menuModifierOptionResource{
+				{ID: "cheese", Name: map[string]string{"en": "Cheese"}, Active: true, PriceDeltas: []menuPriceResource{{Amount: 1.5, CurrencyCode: "USD"}, {Amount: 1.2, CurrencyCode: "EUR"}}},
+				{ID: "no-bun", Name: map[string]string{"en": "No bun"}, Active: true, PriceDeltas: []menuPriceResource{{Amount: -2, CurrencyCode: "USD"}}},
+			},
+		},
+	}
+
+	views := modifierGroupViews(groups, "USD", map[string]bool{"cheese": true})
+	if len(views) != 2 {
+		t.Fatalf("modifierGroupViews() len = %d, want 2", len(views))
+	}
+
+	cooking := views[0]
+	if cooking.Label != "Cooking point" || !cooking.Required || !cooking.Single || cooking.Hint != "" {
+		t.Errorf("cooking view = %+v", cooking)
+	}
+	if len(cooking.Options) != 1 || cooking.Options[0].ID != "rare" {
+		t.Errorf("cooking options = %+v, want only active rare", cooking.Options)
+	}
+
+	extras := views[1]
+	if extras.Label != "Extras" || extras.Required || extras.Single || extras.Hint != "Pick up to 2" {
+		t.Errorf("extras view = %+v", extras)
+	}
+	wantOptions := []modifierOptionView{
+		{ID: "cheese", Label: "Cheese (+$1.50)", Selected: true},
+		{ID: "no-bun", Label: "No bun (-$2.00)"},
+	}
+	if !reflect.DeepEqual(extras.Options, wantOptions) {
+		t.Errorf("extras options = %+v, want %+v", extras.Options, wantOptions)
+	}
+}
+
+func TestPickMenuPriceWithPortion(t *testing.T) {
+	item := &menuItemResource{
+		Prices: []menuPriceResource{{Amount: 14, CurrencyCode: "USD"}},
+		Portions: []menuPortionResource{
+			{ID: "half", Name: map[string]string{"en": "Half"}, Active: true, PriceOverride: []menuPriceResource{{Amount: 6.5, CurrencyCode: "EUR"}, {Amount: 8.5, CurrencyCode: "USD"}}},
+			{ID: "full", Name: map[string]string{"en": "Full"}, Active: true},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		portionID string
+		want      float64
+	}{
+		{name: "noPortion", portionID: "", want: 14},
+		{name: "portionOverride", portionID: "half", want: 8.5},
+		{name: "portionWithoutOverride", portionID: "full", want: 14},
+		{name: "unknownPortion", portionID: "family", want: 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickMenuPrice(item, tt.portionID); got != tt.want {
+				t.Errorf("pickMenuPrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	payload := orderItemPayload(nil, item, 1, "half")
+	if payload["portion_id"] != "half" || payload["price"] != 8.5 {
+		t.Errorf("orderItemPayload() = %v, want half portion at 8.5", payload)
+	}
+}
+
+func TestDeriveStationFromRegistry(t *testing.T) {
+	stations := station.NewRegistry(nil, 0, nil)
+	stations.Set([]station.Station{{Name: "kitchen"}, {Name: "grill"}, {Name: "other"}})
+
+	tests := []struct {
+		name string
+		tags []string
+		want string
+	}{
+		{name: "untagged", tags: nil, want: "kitchen"},
+		{name: "venueStation", tags: []string{"station:Grill"}, want: "grill"},
+		{name: "unknownStation", tags: []string{"station:sushi"}, want: "other"},
+		{name: "direct", tags: []string{"station:direct"}, want: "direct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &menuItemResource{Tags: tt.tags}
+			if got := deriveStation(stations, item); got != tt.want {
+				t.Errorf("deriveStation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	payload := orderItemPayload(stations, &menuItemResource{Tags: []string{"station:grill"}}, 1, "")
+	if payload["production_station"] != "grill" {
+		t.Errorf("production_station = %v, want grill", payload["production_station"])
+	}
+}
+
+func TestPortionViews(t *testing.T) {
+	portions := []menuPortionResource{
+		{ID: "half", Name: map[string]string{"en": "Half"}, Active: true, PriceOverride: []menuPriceResource{{Amount: 8.5, CurrencyCode: "USD"}}},
+		{ID: "family", Name: map[string]string{"en": "Family"}, Active: false},
+		{ID: "full", Name: map[string]string{"es": "Entera"}, Active: true},
+	}
+
+	got := portionViews(portions, menuPriceResource{Amount: 14, CurrencyCode: "USD"}, "full")
+	want := []portionOptionView{
+		{ID: "half", Label: "Half ($8.50)"},
+		{ID: "full", Label: "Entera ($14.00)", Selected: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("portionViews() = %+v, want %+v", got, want)
+	}
+}
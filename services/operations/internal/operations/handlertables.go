@@ -116,11 +116,14 @@ func (h *Handler) CreateTable(w http.ResponseWriter, r *http.Request) {
 		payload["status"] = status
 	}
 
-	if _, err := h.tableClient.Create(ctx, "tables", payload); err != nil {
+	resp, err := h.tableClient.Create(ctx, "tables", payload)
+	if err != nil {
 		log.Error("table service create failed", "error", err)
+		h.auditChange(r, "table.create", number, nil, payload, err)
 		h.handleFormError(w, r, formData, "Could not create the table right now. Please try again.")
 		return
 	}
+	h.auditChange(r, "table.create", auditTargetID(resp, number), nil, responseData(resp), nil)
 
 	apt.RedirectOrHeader(w, r, "/list-tables?created=1")
 }
@@ -187,11 +190,15 @@ func (h *Handler) UpdateTable(w http.ResponseWriter, r *http.Request) {
 
 	h.log().Info("attempting table update", "table_id", id, "payload", payload)
 
-	if _, err := h.tableClient.Update(ctx, "tables", id, payload); err != nil {
+	before, _ := h.fetchTable(ctx, id)
+	resp, err := h.tableClient.Update(ctx, "tables", id, payload)
+	if err != nil {
 		h.log().Error("table service update failed", "error", err, "table_id", id, "payload", payload)
+		h.auditChange(r, "table.update", id, before, payload, err)
 		h.handleFormError(w, r, form, "Could not update the table right now.")
 		return
 	}
+	h.auditChange(r, "table.update", id, before, responseData(resp), nil)
 
 	apt.RedirectOrHeader(w, r, "/list-tables?updated=1")
 }
@@ -211,7 +218,10 @@ func (h *Handler) DeleteTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.tableClient.Delete(r.Context(), "tables", id); err != nil {
+	before, _ := h.fetchTable(r.Context(), id)
+	err := h.tableClient.Delete(r.Context(), "tables", id)
+	h.auditChange(r, "table.delete", id, before, nil, err)
+	if err != nil {
 		h.log().Error("table service delete failed", "error", err, "table_id", id)
 		h.renderTablesPage(w, r, tablesPageState{Error: "Could not delete the table right now."})
 		return
@@ -237,7 +247,9 @@ func (h *Handler) ReleaseTable(w http.ResponseWriter, r *http.Request) {
 
 	// Call table service to release the table
 	path := fmt.Sprintf("/tables/%s/release", id)
-	_, err := h.tableClient.Request(r.Context(), "POST", path, nil)
+	before, _ := h.fetchTable(r.Context(), id)
+	resp, err := h.tableClient.Request(r.Context(), "POST", path, nil)
+	h.auditChange(r, "table.release", id, before, responseData(resp), err)
 	if err != nil {
 		h.log().Error("table service release failed", "error", err, "table_id", id)
 		h.renderTablesPage(w, r, tablesPageState{Error: "Could not release the table right now."})
@@ -411,6 +423,7 @@ var statusLabels = map[string]string{
 	"cleaning":       "Cleaning",
 	"clearing":       "Clearing",
 	"out_of_service": "Out of Service",
+	"merged":         "Merged",
 }
 
 func humanizeStatus(status string) string {
@@ -113,6 +113,21 @@ func (p *DeterministicParser) handleHelp(ctx context.Context, params []string) (
 						<td><code>sk</code></td>
 						<td>sk 47 | send kitchen 47</td>
 					</tr>
+					<tr>
+						<td><code>fire course</code></td>
+						<td><code>fc</code></td>
+						<td>fire 2 table 5 | fc main 47</td>
+					</tr>
+					<tr>
+						<td><code>list decisions</code></td>
+						<td><code>ld</code></td>
+						<td>decisions | ld table 5</td>
+					</tr>
+					<tr>
+						<td><code>answer decision</code></td>
+						<td><code>da</code></td>
+						<td>decide 3F2A 1 | da 3F2A 2</td>
+					</tr>
 					<tr>
 						<td><code>mark ready</code></td>
 						<td><code>mr</code></td>
@@ -292,7 +307,7 @@ func (p *DeterministicParser) handleHelp(ctx context.Context, params []string) (
 					<tr>
 						<td><code>set price</code></td>
 						<td><code>sp</code></td>
-						<td>sp | set price | fijar precio</td>
+						<td>sp | set price | set price BURG-001 15.99 USD</td>
 					</tr>
 					<tr>
 						<td><code>cancel</code></td>
@@ -304,7 +319,7 @@ func (p *DeterministicParser) handleHelp(ctx context.Context, params []string) (
 			<div style="background: #f0f9ff; padding: 1rem; border-radius: 0.5rem; border-left: 4px solid #0ea5e9; margin-bottom: 1.5rem;">
 				<p style="margin: 0 0 0.5rem 0;"><strong>📝 Interactive Workflows:</strong></p>
 				<p style="margin: 0; font-size: 0.9em;">
-					Commands like <code>new menu item</code>, <code>edit menu item</code>, and <code>set price</code> start multi-step processes.
+					Commands like <code>new menu item</code>, <code>edit menu item</code>, and <code>set price</code> (without parameters) start multi-step processes.
 					The system will guide you through each step. Use <code>cancel</code> anytime to abort.
 				</p>
 				<p style="margin: 0.5rem 0 0 0; font-size: 0.85em; color: #666;">
@@ -327,6 +342,11 @@ func (p *DeterministicParser) handleHelp(ctx context.Context, params []string) (
 						<td><code>u</code>, <code>un</code></td>
 						<td>u | undo | deshacer | cofnij</td>
 					</tr>
+					<tr>
+						<td><code>history</code></td>
+						<td><code>hist</code></td>
+						<td>history | history 20 | mi historial</td>
+					</tr>
 					<tr>
 						<td><code>help</code></td>
 						<td><code>h</code></td>
@@ -392,6 +412,16 @@ func (p *DeterministicParser) handleHelp(ctx context.Context, params []string) (
 						<td><code>enviar cocina 47</code></td>
 						<td><code>wyślij do kuchni 47</code></td>
 					</tr>
+					<tr>
+						<td><code>fire 2 table 5</code></td>
+						<td><code>marchar 2 mesa 5</code></td>
+						<td><code>wydaj kurs 2 stolik 5</code></td>
+					</tr>
+					<tr>
+						<td><code>decide 3F2A 1</code></td>
+						<td><code>responder cocina 3F2A 1</code></td>
+						<td><code>odpowiedz kuchni 3F2A 1</code></td>
+					</tr>
 					<tr>
 						<td><code>help</code></td>
 						<td><code>ayuda</code></td>
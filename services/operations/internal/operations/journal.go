@@ -0,0 +1,192 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultUndoWindow is how long a command stays undoable when
+	// journal.undo_window is not configured.
+	DefaultUndoWindow = 10 * time.Minute
+
+	defaultHistoryLimit = 10
+	maxHistoryLimit     = 50
+)
+
+// JournalCommand is a command as the registry runs it: canonical name plus
+// the params handed to its handler.
+type JournalCommand struct {
+	Command string   `json:"command"`
+	Params  []string `json:"params"`
+}
+
+// JournalEntry records one command a user ran through the chat.
+type JournalEntry struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Command   string          `json:"command"`
+	Params    []string        `json:"params"`
+	Success   bool            `json:"success"`
+	Message   string          `json:"message,omitempty"`
+	Inverse   *JournalCommand `json:"inverse,omitempty"`
+	UndoneAt  *time.Time      `json:"undone_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Reversible reports whether the entry can still be undone.
+func (e *JournalEntry) Reversible() bool {
+	return e.Success && e.Inverse != nil && e.UndoneAt == nil
+}
+
+// JournalStore persists journal entries.
+type JournalStore interface {
+	Append(ctx context.Context, entry *JournalEntry) error
+	// ListByUser returns the most recent entries of a user, newest first.
+	ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*JournalEntry, error)
+	MarkUndone(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// CommandJournal keeps the per-user command history that backs the history
+// and undo commands.
+type CommandJournal struct {
+	store  JournalStore
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewCommandJournal creates a journal over store. Commands older than window
+// can no longer be undone.
+func NewCommandJournal(store JournalStore, window time.Duration) *CommandJournal {
+	if store == nil {
+		store = NewMemoryJournalStore()
+	}
+	if window <= 0 {
+		window = DefaultUndoWindow
+	}
+	return &CommandJournal{
+		store:  store,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Window returns how long a command stays undoable.
+func (j *CommandJournal) Window() time.Duration {
+	return j.window
+}
+
+// Record appends a command run to the user's journal.
+func (j *CommandJournal) Record(ctx context.Context, entry *JournalEntry) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = j.now()
+	}
+	return j.store.Append(ctx, entry)
+}
+
+// History returns the user's latest commands, newest first.
+func (j *CommandJournal) History(ctx context.Context, userID uuid.UUID, limit int) ([]*JournalEntry, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	return j.store.ListByUser(ctx, userID, limit)
+}
+
+// LastUndoable returns the user's most recent reversible command that has not
+// been undone yet, or nil when there is none inside the undo window.
+// Non-reversible commands in between (queries, failed commands) are skipped.
+func (j *CommandJournal) LastUndoable(ctx context.Context, userID uuid.UUID) (*JournalEntry, error) {
+	entries, err := j.store.ListByUser(ctx, userID, maxHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := j.now().Add(-j.window)
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(cutoff) {
+			return nil, nil
+		}
+		if entry.Reversible() {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+// MarkUndone flags an entry so it cannot be undone twice.
+func (j *CommandJournal) MarkUndone(ctx context.Context, id uuid.UUID) error {
+	return j.store.MarkUndone(ctx, id, j.now())
+}
+
+// MemoryJournalStore keeps journal entries in memory. It is the default store
+// and loses the journal on restart.
+type MemoryJournalStore struct {
+	entries map[uuid.UUID][]*JournalEntry
+	mutex   sync.RWMutex
+}
+
+// NewMemoryJournalStore creates an empty in-memory journal store.
+func NewMemoryJournalStore() *MemoryJournalStore {
+	return &MemoryJournalStore{
+		entries: make(map[uuid.UUID][]*JournalEntry),
+	}
+}
+
+func (s *MemoryJournalStore) Append(ctx context.Context, entry *JournalEntry) error {
+	if entry == nil {
+		return fmt.Errorf("journal entry is required")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entryCopy := *entry
+	s.entries[entry.UserID] = append(s.entries[entry.UserID], &entryCopy)
+	return nil
+}
+
+func (s *MemoryJournalStore) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*JournalEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stored := s.entries[userID]
+	entries := make([]*JournalEntry, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		entryCopy := *stored[i]
+		entries = append(entries, &entryCopy)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+func (s *MemoryJournalStore) MarkUndone(ctx context.Context, id uuid.UUID, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, entries := range s.entries {
+		for _, entry := range entries {
+			if entry.ID == id {
+				undoneAt := at
+				entry.UndoneAt = &undoneAt
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("journal entry with id %s not found", id.String())
+}
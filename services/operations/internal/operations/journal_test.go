@@ -0,0 +1,304 @@
+package operations
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryJournalStoreListByUser(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryJournalStore()
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440700")
+	otherID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440701")
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, command := range []string{"list-orders", "add-item", "seat-party"} {
+		entry := &JournalEntry{ID: uuid.New(), UserID: userID, Command: command, CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := store.Append(ctx, entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if err := store.Append(ctx, &JournalEntry{ID: uuid.New(), UserID: otherID, Command: "help", CreatedAt: base}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	entries, err := store.ListByUser(ctx, userID, 2)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ListByUser() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Command != "seat-party" || entries[1].Command != "add-item" {
+		t.Errorf("ListByUser() = [%s %s], want newest first", entries[0].Command, entries[1].Command)
+	}
+
+	if err := store.MarkUndone(ctx, entries[0].ID, base); err != nil {
+		t.Fatalf("MarkUndone() error = %v", err)
+	}
+	if err := store.MarkUndone(ctx, uuid.New(), base); err == nil {
+		t.Error("MarkUndone() of an unknown entry should fail")
+	}
+
+	entries, _ = store.ListByUser(ctx, userID, 0)
+	if len(entries) != 3 {
+		t.Fatalf("ListByUser() without limit returned %d entries, want 3", len(entries))
+	}
+	if entries[0].UndoneAt == nil {
+		t.Error("MarkUndone() did not flag the entry")
+	}
+}
+
+func TestCommandJournalLastUndoable(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440702")
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	inverse := &JournalCommand{Command: "remove-item", Params: []string{"o-1", "i-1"}}
+
+	tests := []struct {
+		name    string
+		entries []*JournalEntry
+		want    string
+	}{
+		{
+			name:    "empty",
+			entries: nil,
+			want:    "",
+		},
+		{
+			name: "skipsQueriesAndFailures",
+			entries: []*JournalEntry{
+				{Command: "add-item", Success: true, Inverse: inverse, CreatedAt: now.Add(-3 * time.Minute)},
+				{Command: "seat-party", Success: false, CreatedAt: now.Add(-2 * time.Minute)},
+				{Command: "list-orders", Success: true, CreatedAt: now.Add(-time.Minute)},
+			},
+			want: "add-item",
+		},
+		{
+			name: "skipsUndone",
+			entries: []*JournalEntry{
+				{Command: "seat-party", Success: true, Inverse: inverse, CreatedAt: now.Add(-2 * time.Minute)},
+				{Command: "add-item", Success: true, Inverse: inverse, UndoneAt: &now, CreatedAt: now.Add(-time.Minute)},
+			},
+			want: "seat-party",
+		},
+		{
+			name: "outsideWindow",
+			entries: []*JournalEntry{
+				{Command: "add-item", Success: true, Inverse: inverse, CreatedAt: now.Add(-11 * time.Minute)},
+				{Command: "list-orders", Success: true, CreatedAt: now.Add(-time.Minute)},
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journal := NewCommandJournal(NewMemoryJournalStore(), 10*time.Minute)
+			journal.now = func() time.Time { return now }
+			for _, entry := range tt.entries {
+				entry.UserID = userID
+				if err := journal.Record(ctx, entry); err != nil {
+					t.Fatalf("Record() error = %v", err)
+				}
+			}
+
+			got, err := journal.LastUndoable(ctx, userID)
+			if err != nil {
+				t.Fatalf("LastUndoable() error = %v", err)
+			}
+			gotCommand := ""
+			if got != nil {
+				gotCommand = got.Command
+			}
+			if gotCommand != tt.want {
+				t.Errorf("LastUndoable() = %q, want %q", gotCommand, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessJournalsAndUndoes(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440703")
+	ctx := context.WithValue(context.Background(), contextKeyUserID, userID)
+	handler := &Handler{commandJournal: NewCommandJournal(nil, 0)}
+	parser := NewDeterministicParser(nil, nil, nil, handler)
+
+	var reverted []string
+	parser.registry.register("stamp", &CommandDefinition{
+		Canonical: "stamp",
+		Handler: func(ctx context.Context, params []string) (*CommandResponse, error) {
+			return &CommandResponse{Success: true, Effects: map[string]string{"stamp_id": "s-" + params[0]}}, nil
+		},
+		MinParams: 1,
+		MaxParams: 1,
+		Inverse: func(params []string, response *CommandResponse) *JournalCommand {
+			return &JournalCommand{Command: "unstamp", Params: []string{response.Effects["stamp_id"]}}
+		},
+	})
+	parser.registry.register("unstamp", &CommandDefinition{
+		Canonical: "unstamp",
+		Handler: func(ctx context.Context, params []string) (*CommandResponse, error) {
+			reverted = append(reverted, params...)
+			return &CommandResponse{HTML: "<p>unstamped</p>", Success: true}, nil
+		},
+		MinParams: 1,
+		MaxParams: 1,
+	})
+
+	if _, err := parser.Process(ctx, "stamp 7"); err != nil {
+		t.Fatalf("Process(stamp) error = %v", err)
+	}
+
+	response, err := parser.Process(ctx, "undo")
+	if err != nil {
+		t.Fatalf("Process(undo) error = %v", err)
+	}
+	if !response.Success {
+		t.Fatalf("undo failed: %s", response.Message)
+	}
+	if len(reverted) != 1 || reverted[0] != "s-7" {
+		t.Errorf("inverse ran with %v, want [s-7]", reverted)
+	}
+	if !strings.Contains(response.HTML, "unstamped") {
+		t.Error("undo response should include the inverse command output")
+	}
+
+	response, _ = parser.Process(ctx, "undo")
+	if response.Success {
+		t.Error("second undo should find nothing to undo")
+	}
+
+	entries, _ := handler.commandJournal.History(ctx, userID, 0)
+	if len(entries) != 3 {
+		t.Fatalf("History() returned %d entries, want 3", len(entries))
+	}
+	if entries[2].Command != "stamp" || entries[2].UndoneAt == nil {
+		t.Errorf("stamp entry = %+v, want it marked undone", entries[2])
+	}
+	if entries[0].Inverse != nil || entries[1].Inverse != nil {
+		t.Error("undo itself should not be undoable")
+	}
+}
+
+func TestJournalCommandsWithoutUser(t *testing.T) {
+	handler := &Handler{commandJournal: NewCommandJournal(nil, 0)}
+	parser := NewDeterministicParser(nil, nil, nil, handler)
+
+	for _, run := range []CommandHandler{parser.handleUndo, parser.handleHistory} {
+		response, err := run(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+		if response.Success {
+			t.Error("journal commands should require a signed-in user")
+		}
+	}
+}
+
+func TestInverseBuilders(t *testing.T) {
+	tests := []struct {
+		name    string
+		inverse InverseBuilder
+		effects map[string]string
+		want    *JournalCommand
+	}{
+		{
+			name:    "addItem",
+			inverse: inverseAddItem,
+			effects: map[string]string{"order_id": "o-1", "item_id": "i-1"},
+			want:    &JournalCommand{Command: "remove-item", Params: []string{"o-1", "i-1"}},
+		},
+		{
+			name:    "removeItem",
+			inverse: inverseRemoveItem,
+			effects: map[string]string{"order_id": "o-1", "menu_code": "BURG-001", "quantity": "2"},
+			want:    &JournalCommand{Command: "add-item", Params: []string{"o-1", "BURG-001", "2"}},
+		},
+		{
+			name:    "removeItemWithoutMenuCode",
+			inverse: inverseRemoveItem,
+			effects: map[string]string{"order_id": "o-1", "quantity": "2"},
+		},
+		{
+			name:    "seatParty",
+			inverse: inverseSeatParty,
+			effects: map[string]string{"table_id": "t-1"},
+			want:    &JournalCommand{Command: "release-table", Params: []string{"t-1"}},
+		},
+		{
+			name:    "releaseTable",
+			inverse: inverseReleaseTable,
+			effects: map[string]string{"table_id": "t-1", "guest_count": "4"},
+			want:    &JournalCommand{Command: "seat-party", Params: []string{"t-1", "4"}},
+		},
+		{
+			name:    "releaseTableNotSeated",
+			inverse: inverseReleaseTable,
+			effects: nil,
+		},
+		{
+			name:    "setPrice",
+			inverse: inverseSetPrice,
+			effects: map[string]string{"code": "BURG-001", "previous_price": "12.50", "currency": "USD"},
+			want:    &JournalCommand{Command: "set-price", Params: []string{"BURG-001", "12.50", "USD"}},
+		},
+		{
+			name:    "setPriceNewCurrency",
+			inverse: inverseSetPrice,
+			effects: map[string]string{"code": "BURG-001", "currency": "EUR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.inverse(nil, &CommandResponse{Success: true, Effects: tt.effects})
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("inverse = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Command != tt.want.Command || strings.Join(got.Params, " ") != strings.Join(tt.want.Params, " ") {
+				t.Errorf("inverse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePriceParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		tokens       []string
+		wantCode     string
+		wantAmount   float64
+		wantCurrency string
+		wantErr      bool
+	}{
+		{name: "splitCode", tokens: []string{"burg", "001", "15.99", "usd"}, wantCode: "BURG-001", wantAmount: 15.99, wantCurrency: "USD"},
+		{name: "noCurrency", tokens: []string{"burg", "001", "15"}, wantCode: "BURG-001", wantAmount: 15},
+		{name: "journalParams", tokens: []string{"BURG-001", "12.50", "EUR"}, wantCode: "BURG-001", wantAmount: 12.5, wantCurrency: "EUR"},
+		{name: "missingPrice", tokens: []string{"burg", "usd"}, wantErr: true},
+		{name: "negative", tokens: []string{"burg", "-3"}, wantErr: true},
+		{name: "badCurrency", tokens: []string{"burg", "3", "dollars"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, amount, currency, err := parsePriceParams(tt.tokens)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePriceParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if code != tt.wantCode || amount != tt.wantAmount || currency != tt.wantCurrency {
+				t.Errorf("parsePriceParams() = (%q, %v, %q), want (%q, %v, %q)", code, amount, currency, tt.wantCode, tt.wantAmount, tt.wantCurrency)
+			}
+		})
+	}
+}
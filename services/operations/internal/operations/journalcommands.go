@@ -0,0 +1,185 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Journal Commands
+//
+// Every command a signed-in user runs through the chat is recorded in the
+// command journal. Commands that declare an inverse in the registry can be
+// reverted with "undo" for a while; "history" lists the journal.
+
+// handleUndo replays the inverse of the user's last reversible command.
+func (p *DeterministicParser) handleUndo(ctx context.Context, params []string) (*CommandResponse, error) {
+	journal, userID, errResp := p.userJournal(ctx)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	entry, err := journal.LastUndoable(ctx, userID)
+	if err != nil {
+		return journalCommandError(fmt.Sprintf("Cannot read command history: %v", err), "Journal unavailable"), nil
+	}
+	if entry == nil {
+		return &CommandResponse{
+			HTML: fmt.Sprintf(`
+				<p>ℹ️ <strong>Nothing to undo</strong></p>
+				<p><em>Only add-item, remove-item, seat-party, release-table and set-price can be undone, up to %s after they ran.</em></p>
+			`, formatWindow(journal.Window())),
+			Success: false,
+			Message: "Nothing to undo",
+		}, nil
+	}
+
+	inverse, ok := p.registry.commands[entry.Inverse.Command]
+	if !ok || inverse.Handler == nil {
+		return journalCommandError(fmt.Sprintf("%s cannot be undone", entry.Command), "Undo failed"), nil
+	}
+
+	response, err := inverse.Handler(ctx, entry.Inverse.Params)
+	if err != nil {
+		return nil, err
+	}
+	if !response.Success {
+		response.HTML = fmt.Sprintf(`
+			<p>⚠️ <strong>Cannot undo <code>%s</code></strong></p>%s`, html.EscapeString(journalCommandLine(entry.Command, entry.Params)), response.HTML)
+		return response, nil
+	}
+
+	if err := journal.MarkUndone(ctx, entry.ID); err != nil && p.handler.logger != nil {
+		p.handler.logger.Error("cannot mark command undone", "entry_id", entry.ID.String(), "error", err)
+	}
+
+	return &CommandResponse{
+		HTML: fmt.Sprintf(`
+			<p>↩️ <strong>Undid <code>%s</code></strong></p>%s`, html.EscapeString(journalCommandLine(entry.Command, entry.Params)), response.HTML),
+		Success: true,
+		Message: fmt.Sprintf("Undid %s", entry.Command),
+	}, nil
+}
+
+// handleHistory lists the user's latest commands, newest first.
+func (p *DeterministicParser) handleHistory(ctx context.Context, params []string) (*CommandResponse, error) {
+	journal, userID, errResp := p.userJournal(ctx)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	limit := defaultHistoryLimit
+	if len(params) > 0 {
+		n, err := strconv.Atoi(params[0])
+		if err != nil || n <= 0 {
+			return journalCommandError(fmt.Sprintf("Invalid history size %q", params[0]), "Invalid history size"), nil
+		}
+		limit = min(n, maxHistoryLimit)
+	}
+
+	entries, err := journal.History(ctx, userID, limit)
+	if err != nil {
+		return journalCommandError(fmt.Sprintf("Cannot read command history: %v", err), "Journal unavailable"), nil
+	}
+
+	return &CommandResponse{
+		HTML:    renderJournal(entries, journal.now().Add(-journal.Window())),
+		Success: true,
+		Message: fmt.Sprintf("%d commands in history", len(entries)),
+	}, nil
+}
+
+// userJournal returns the journal and the signed-in user, or the error
+// response to show when either is missing.
+func (p *DeterministicParser) userJournal(ctx context.Context) (*CommandJournal, uuid.UUID, *CommandResponse) {
+	if p.handler == nil || p.handler.commandJournal == nil {
+		return nil, uuid.Nil, journalCommandError("Command history is not available", "Journal unavailable")
+	}
+	userID := getUserIDFromContext(ctx)
+	if userID == uuid.Nil {
+		return nil, uuid.Nil, journalCommandError("Sign in to use your command history", "Authentication required")
+	}
+	return p.handler.commandJournal, userID, nil
+}
+
+// renderJournal renders journal entries; entries created after undoableSince
+// that can still be reverted are flagged as undoable.
+func renderJournal(entries []*JournalEntry, undoableSince time.Time) string {
+	if len(entries) == 0 {
+		return `<p><strong>Command History:</strong></p><p><em>No commands yet</em></p>`
+	}
+
+	var rows strings.Builder
+	for _, entry := range entries {
+		result := "✅"
+		if !entry.Success {
+			result = "⚠️"
+		}
+		if entry.Message != "" {
+			result += " " + html.EscapeString(entry.Message)
+		}
+
+		undo := "-"
+		switch {
+		case entry.UndoneAt != nil:
+			undo = "Undone"
+		case entry.Reversible() && entry.CreatedAt.After(undoableSince):
+			undo = "Undoable"
+		}
+
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td>%s</td>
+					<td><code>%s</code></td>
+					<td>%s</td>
+					<td>%s</td>
+				</tr>`, relativeTimeSince(entry.CreatedAt), html.EscapeString(journalCommandLine(entry.Command, entry.Params)),
+			result, undo)
+	}
+
+	return fmt.Sprintf(`
+		<p><strong>Command History:</strong></p>
+		<table>
+			<thead>
+				<tr>
+					<th>When</th>
+					<th>Command</th>
+					<th>Result</th>
+					<th>Undo</th>
+				</tr>
+			</thead>
+			<tbody>%s
+			</tbody>
+		</table>
+		<p><em>Type <code>undo</code> to revert your last undoable command</em></p>
+	`, rows.String())
+}
+
+func journalCommandLine(command string, params []string) string {
+	return strings.TrimSpace(command + " " + strings.Join(params, " "))
+}
+
+// formatWindow renders the undo window in whole minutes when it is one.
+func formatWindow(window time.Duration) string {
+	if window >= time.Minute && window%time.Minute == 0 {
+		minutes := int(window / time.Minute)
+		if minutes == 1 {
+			return "1 minute"
+		}
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+	return window.String()
+}
+
+func journalCommandError(message, summary string) *CommandResponse {
+	return &CommandResponse{
+		HTML:    formatError(html.EscapeString(message)),
+		Success: false,
+		Message: summary,
+	}
+}
@@ -3,6 +3,7 @@ package operations
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/appetiteclub/appetite/pkg/event"
@@ -51,22 +52,22 @@ func (s *KitchenTicketSubscriber) Stop(ctx context.Context) error {
 }
 
 func (s *KitchenTicketSubscriber) handleEvent(ctx context.Context, msg []byte) error {
-	var baseEvent struct {
-		EventType string `json:"event_type"`
-	}
-
-	if err := json.Unmarshal(msg, &baseEvent); err != nil {
+	env, err := event.Open(msg)
+	if errors.Is(err, event.ErrNotEvent) {
 		s.logger.Error("failed to unmarshal event type", "error", err)
 		return nil
 	}
+	if err != nil {
+		return err
+	}
 
-	switch baseEvent.EventType {
+	switch env.Type {
 	case event.EventKitchenTicketCreated:
-		return s.handleTicketCreated(ctx, msg)
+		return s.handleTicketCreated(ctx, env.Payload)
 	case event.EventKitchenTicketStatusChange:
-		return s.handleTicketStatusChanged(ctx, msg)
+		return s.handleTicketStatusChanged(ctx, env.Payload)
 	default:
-		s.logger.Debug("ignoring unknown event type", "event_type", baseEvent.EventType)
+		s.logger.Debug("ignoring unknown event type", "event_type", env.Type)
 		return nil
 	}
 }
@@ -91,6 +92,10 @@ func (s *KitchenTicketSubscriber) handleTicketCreated(ctx context.Context, msg [
 		MenuItemName: evt.MenuItemName,
 		StationName:  evt.StationName,
 		TableNumber:  evt.TableNumber,
+		Modifiers:    evt.Modifiers,
+		PortionName:  evt.PortionName,
+		PrepTime:     evt.PrepTime,
+		Course:       evt.Course,
 		CreatedAt:    evt.OccurredAt,
 		UpdatedAt:    evt.OccurredAt,
 	}
@@ -126,6 +131,16 @@ func (s *KitchenTicketSubscriber) handleTicketStatusChanged(ctx context.Context,
 	// Update status and timestamps
 	ticket.Status = evt.NewStatus
 	ticket.Notes = evt.Notes
+	if len(evt.Modifiers) > 0 {
+		ticket.Modifiers = evt.Modifiers
+	}
+	if evt.PortionName != "" {
+		ticket.PortionName = evt.PortionName
+		ticket.PrepTime = evt.PrepTime
+	}
+	if evt.Course > 0 {
+		ticket.Course = evt.Course
+	}
 	ticket.UpdatedAt = evt.OccurredAt
 	ticket.StartedAt = evt.StartedAt
 	ticket.FinishedAt = evt.FinishedAt
@@ -0,0 +1,186 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Kitchen Decision Commands
+//
+// The kitchen can ask the floor about a ticket, e.g. "out of salmon,
+// substitute trout?". These handlers let staff see the open questions and
+// answer them from chat. Questions nobody answered in time go to a manager.
+
+// handleListDecisions lists the open kitchen questions: "decisions",
+// "decisions table 5".
+func (p *DeterministicParser) handleListDecisions(ctx context.Context, params []string) (*CommandResponse, error) {
+	kitchen := p.kitchenData()
+	if kitchen == nil {
+		return orderCommandError("Kitchen service is not configured.", "Kitchen unavailable"), nil
+	}
+
+	orderID := ""
+	if ref := orderRefFromTokens(params); ref != "" {
+		order, errResp := p.lookupOrder(ctx, ref)
+		if errResp != nil {
+			return errResp, nil
+		}
+		orderID = order.ID
+	}
+
+	pending, err := kitchen.ListDecisions(ctx, orderID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch kitchen questions: %s", serviceErrorMessage(err)), "Decision fetch failed"), nil
+	}
+
+	if len(pending) == 0 {
+		return &CommandResponse{
+			HTML:    `<p>✅ <strong>No open kitchen questions</strong></p>`,
+			Success: true,
+			Message: "No open kitchen questions",
+		}, nil
+	}
+
+	var rows strings.Builder
+	for _, entry := range pending {
+		who := ""
+		if entry.Decision.EscalatedAt != nil {
+			who = ` <em>(manager)</em>`
+		}
+
+		var options strings.Builder
+		for _, option := range entry.Decision.Options {
+			fmt.Fprintf(&options, `
+					<li><code>%s</code> %s</li>`, html.EscapeString(option.ID), html.EscapeString(option.Label))
+		}
+
+		fmt.Fprintf(&rows, `
+			<li><code>%s</code> %s, table %s%s<br>%s
+				<ul>%s
+				</ul>
+			</li>`,
+			shortOrderID(entry.Ticket.ID),
+			html.EscapeString(entry.Ticket.MenuItemName),
+			html.EscapeString(entry.Ticket.TableNumber),
+			who,
+			html.EscapeString(entry.Decision.Question),
+			options.String())
+	}
+
+	out := fmt.Sprintf(`
+		<p>❓ <strong>Kitchen questions</strong></p>
+		<ul>%s
+		</ul>
+		<p><em>Answer with <code>decide [ticket] [option]</code></em></p>
+	`, rows.String())
+
+	return &CommandResponse{
+		HTML:    out,
+		Success: true,
+		Message: fmt.Sprintf("%d open kitchen questions", len(pending)),
+	}, nil
+}
+
+// handleAnswerDecision answers a kitchen question: "decide 3f2a 1".
+func (p *DeterministicParser) handleAnswerDecision(ctx context.Context, params []string) (*CommandResponse, error) {
+	kitchen := p.kitchenData()
+	if kitchen == nil {
+		return orderCommandError("Kitchen service is not configured.", "Kitchen unavailable"), nil
+	}
+
+	pending, err := kitchen.ListDecisions(ctx, "")
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch kitchen questions: %s", serviceErrorMessage(err)), "Decision fetch failed"), nil
+	}
+
+	entry, err := matchDecisionRef(pending, params[0])
+	if err != nil {
+		return orderCommandError(err.Error(), "Question not found"), nil
+	}
+
+	optionID := params[1]
+	var label string
+	for _, option := range entry.Decision.Options {
+		if strings.EqualFold(option.ID, optionID) {
+			optionID, label = option.ID, option.Label
+			break
+		}
+	}
+	if label == "" {
+		return orderCommandError(fmt.Sprintf("Ticket %s has no option %s", shortOrderID(entry.Ticket.ID), optionID), "Invalid option"), nil
+	}
+
+	if entry.Decision.EscalatedAt != nil && !p.canManageOrders(ctx) {
+		return orderCommandError("This question went to a manager. Ask one to answer it.", "Manager required"), nil
+	}
+
+	answeredBy := ""
+	if userID := getUserIDFromContext(ctx); userID != uuid.Nil {
+		answeredBy = userID.String()
+	}
+
+	if _, err := kitchen.AnswerDecision(ctx, entry.Ticket.ID, optionID, answeredBy); err != nil {
+		if isKitchenConflict(err) {
+			return orderCommandError("Someone already answered this question.", "Decision already answered"), nil
+		}
+		return orderCommandError(fmt.Sprintf("Cannot answer ticket %s: %s", shortOrderID(entry.Ticket.ID), serviceErrorMessage(err)), "Decision failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>%s</strong></p>
+		<p><em>Told the kitchen about %s</em></p>
+	`, html.EscapeString(label), html.EscapeString(entry.Ticket.MenuItemName))
+
+	return &CommandResponse{
+		HTML:    out,
+		Success: true,
+		Message: fmt.Sprintf("Answered ticket %s", shortOrderID(entry.Ticket.ID)),
+		Effects: map[string]string{"ticket_id": entry.Ticket.ID, "option_id": optionID},
+	}, nil
+}
+
+// matchDecisionRef finds the open question whose ticket ID starts with ref,
+// the short ID shown by "decisions".
+func matchDecisionRef(pending []kitchenPendingDecisionResource, ref string) (*kitchenPendingDecisionResource, error) {
+	key := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(ref)), "#")
+	if key == "" {
+		return nil, fmt.Errorf("missing ticket reference")
+	}
+
+	var found *kitchenPendingDecisionResource
+	for i := range pending {
+		if !strings.HasPrefix(strings.ToLower(pending[i].Ticket.ID), key) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several open questions match %q, use more of the ticket ID", ref)
+		}
+		found = &pending[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no open kitchen question matches %q", ref)
+	}
+	return found, nil
+}
+
+func (p *DeterministicParser) kitchenData() *KitchenDataAccess {
+	if p.handler == nil {
+		return nil
+	}
+	return p.handler.kitchenData
+}
+
+// canManageOrders reports whether the chat user may answer questions that
+// went to a manager.
+func (p *DeterministicParser) canManageOrders(ctx context.Context) bool {
+	userID := getUserIDFromContext(ctx)
+	if userID == uuid.Nil || p.handler == nil || p.handler.authzHelper == nil {
+		return false
+	}
+	allowed, err := p.handler.authzHelper.CheckPermission(ctx, userID.String(), menuOverridePermission, "*")
+	return err == nil && allowed
+}
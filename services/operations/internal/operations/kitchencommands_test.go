@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMatchDecisionRef(t *testing.T) {
+	pending := []kitchenPendingDecisionResource{
+		{Ticket: kitchenTicketResource{ID: "550e8400-e29b-41d4-a716-446655440700"}},
+		{Ticket: kitchenTicketResource{ID: "550e9911-e29b-41d4-a716-446655440701"}},
+		{Ticket: kitchenTicketResource{ID: "7a1b2c3d-e29b-41d4-a716-446655440702"}},
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		wantID  string
+		wantErr bool
+	}{
+		{name: "shortID", ref: "7A1B2C3D", wantID: pending[2].Ticket.ID},
+		{name: "hashPrefix", ref: "#550e84", wantID: pending[0].Ticket.ID},
+		{name: "ambiguous", ref: "550e", wantErr: true},
+		{name: "noMatch", ref: "ffff0000", wantErr: true},
+		{name: "empty", ref: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchDecisionRef(pending, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchDecisionRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Ticket.ID != tt.wantID {
+				t.Errorf("matchDecisionRef() = %s, want %s", got.Ticket.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestHandleDecisionsNoKitchen(t *testing.T) {
+	p := &DeterministicParser{}
+
+	resp, err := p.handleListDecisions(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("handleListDecisions() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("handleListDecisions() should fail without a kitchen client")
+	}
+
+	resp, err = p.handleAnswerDecision(context.Background(), []string{"550e8400", "1"})
+	if err != nil {
+		t.Fatalf("handleAnswerDecision() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("handleAnswerDecision() should fail without a kitchen client")
+	}
+}
+
+func TestCanManageOrdersWithoutUser(t *testing.T) {
+	p := &DeterministicParser{handler: &Handler{}}
+
+	if p.canManageOrders(context.Background()) {
+		t.Error("canManageOrders() without a signed-in user should be false")
+	}
+}
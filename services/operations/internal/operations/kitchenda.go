@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg/event"
 	"github.com/appetiteclub/apt"
 	"github.com/go-chi/chi/v5"
 )
@@ -28,19 +29,44 @@ type kitchenTicketResource struct {
 	DecisionPayload  []byte     `json:"decision_payload"`
 
 	// Denormalized data for display
-	MenuItemName string `json:"menu_item_name"`
-	StationName  string `json:"station_name"`
-	TableNumber  string `json:"table_number"`
+	MenuItemName string   `json:"menu_item_name"`
+	StationName  string   `json:"station_name"`
+	TableNumber  string   `json:"table_number"`
+	Modifiers    []string `json:"modifiers"`
+	PortionName  string   `json:"portion_name"`
+	PrepTime     int      `json:"prep_time"` // Minutes
+	Course       int      `json:"course"`    // 0 when the item is not coursed
 
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	StartedAt   *time.Time `json:"started_at"`
 	FinishedAt  *time.Time `json:"finished_at"`
 	DeliveredAt *time.Time `json:"delivered_at"`
+	FiredAt     *time.Time `json:"fired_at"`
+	LateAt      *time.Time `json:"late_at"` // Set once the kitchen flags the ticket late
 
 	ModelVersion int `json:"model_version"`
 }
 
+// ExpectedReadyAt returns when the ticket should be done, counting the prep
+// time from when work started or else from when it was ordered or its course
+// fired. It returns nil when the prep time is unknown or the ticket is
+// already finished.
+func (t *kitchenTicketResource) ExpectedReadyAt() *time.Time {
+	if t.PrepTime <= 0 || t.FinishedAt != nil {
+		return nil
+	}
+	start := t.CreatedAt
+	if t.FiredAt != nil {
+		start = *t.FiredAt
+	}
+	if t.StartedAt != nil {
+		start = *t.StartedAt
+	}
+	readyAt := start.Add(time.Duration(t.PrepTime) * time.Minute)
+	return &readyAt
+}
+
 // KitchenDataAccess wraps the low-level kitchen API.
 type KitchenDataAccess struct {
 	client *apt.ServiceClient
@@ -112,6 +138,119 @@ func (da *KitchenDataAccess) GetTicket(ctx context.Context, id string) (*kitchen
 	return &ticket, nil
 }
 
+// kitchenTransitionResource is a status a ticket can move to next.
+type kitchenTransitionResource struct {
+	Status string `json:"status"`
+	Label  string `json:"label"`
+}
+
+// ListTicketTransitions returns the statuses the kitchen allows the ticket to
+// move to now.
+func (da *KitchenDataAccess) ListTicketTransitions(ctx context.Context, id string) ([]kitchenTransitionResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("kitchen client not configured")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("missing ticket ID")
+	}
+
+	path := fmt.Sprintf("/tickets/%s/transitions", id)
+	resp, err := da.client.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Transitions []kitchenTransitionResource `json:"transitions"`
+	}
+	if err := decodeSuccessResponse(resp, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Transitions, nil
+}
+
+// kitchenExpoStationResource is how far a station is with a course.
+type kitchenExpoStationResource struct {
+	Station     string `json:"station"`
+	StationName string `json:"station_name"`
+	Ready       int    `json:"ready"`
+	Total       int    `json:"total"`
+}
+
+// kitchenExpoCourseResource is one course of an order on the pass.
+type kitchenExpoCourseResource struct {
+	Course   int                          `json:"course"`
+	Held     bool                         `json:"held"`
+	Ready    int                          `json:"ready"`
+	Total    int                          `json:"total"`
+	Lagging  bool                         `json:"lagging"`
+	Stations []kitchenExpoStationResource `json:"stations"`
+	Tickets  []kitchenTicketResource      `json:"tickets"`
+}
+
+// kitchenExpoOrderResource mirrors an order in the kitchen expo view.
+type kitchenExpoOrderResource struct {
+	OrderID     string                      `json:"order_id"`
+	TableNumber string                      `json:"table_number"`
+	Ready       int                         `json:"ready"`
+	Total       int                         `json:"total"`
+	Lagging     bool                        `json:"lagging"`
+	FirstAt     time.Time                   `json:"first_at"`
+	Courses     []kitchenExpoCourseResource `json:"courses"`
+}
+
+// kitchenBumpResource is the kitchen answer to an order bump.
+type kitchenBumpResource struct {
+	OrderID   string                  `json:"order_id"`
+	Delivered []kitchenTicketResource `json:"delivered"`
+	Failed    []string                `json:"failed"`
+}
+
+// ListExpoOrders returns the open tickets grouped by order and course.
+func (da *KitchenDataAccess) ListExpoOrders(ctx context.Context) ([]kitchenExpoOrderResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("kitchen client not configured")
+	}
+
+	resp, err := da.client.Request(ctx, "GET", "/expo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Orders []kitchenExpoOrderResource `json:"orders"`
+	}
+	if err := decodeSuccessResponse(resp, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Orders, nil
+}
+
+// BumpOrder delivers every ready ticket of the order.
+func (da *KitchenDataAccess) BumpOrder(ctx context.Context, orderID string) (*kitchenBumpResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("kitchen client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/expo/orders/%s/bump", url.PathEscape(orderID))
+	resp, err := da.client.Request(ctx, "POST", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result kitchenBumpResource
+	if err := decodeSuccessResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 func (da *KitchenDataAccess) TransitionTicket(ctx context.Context, ticketID, action string) (*kitchenTicketResource, error) {
 	if da == nil || da.client == nil {
 		return nil, fmt.Errorf("kitchen client not configured")
@@ -158,6 +297,13 @@ func (da *KitchenDataAccess) UpdateTicketStatus(ctx context.Context, r *http.Req
 		return fmt.Errorf("invalid request body: %w", err)
 	}
 
+	// The kitchen records who started the ticket for its analytics
+	if _, ok := reqBody["cook"]; !ok {
+		if cook := sessionCook(r); cook != "" {
+			reqBody["cook"] = cook
+		}
+	}
+
 	// Forward to Kitchen service
 	path := fmt.Sprintf("/tickets/%s/status", ticketID)
 	_, err = da.client.Request(ctx, "PATCH", path, reqBody)
@@ -167,3 +313,164 @@ func (da *KitchenDataAccess) UpdateTicketStatus(ctx context.Context, r *http.Req
 
 	return nil
 }
+
+
+// kitchenDurationStatsResource summarises one phase of the tickets, in
+// seconds.
+type kitchenDurationStatsResource struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_seconds"`
+	P90   float64 `json:"p90_seconds"`
+	Max   float64 `json:"max_seconds"`
+}
+
+// kitchenAnalyticsRowResource holds the timings of one group of tickets.
+type kitchenAnalyticsRowResource struct {
+	Key     string                       `json:"key"`
+	Label   string                       `json:"label"`
+	Tickets int                          `json:"tickets"`
+	Queue   kitchenDurationStatsResource `json:"queue"`
+	Cook    kitchenDurationStatsResource `json:"cook"`
+	Pass    kitchenDurationStatsResource `json:"pass"`
+}
+
+// kitchenAnalyticsResource mirrors the kitchen ticket analytics report.
+type kitchenAnalyticsResource struct {
+	From    time.Time                     `json:"from"`
+	To      time.Time                     `json:"to"`
+	GroupBy string                        `json:"group_by"`
+	Overall kitchenAnalyticsRowResource   `json:"overall"`
+	Rows    []kitchenAnalyticsRowResource `json:"rows"`
+}
+
+// TicketAnalytics returns queue, cook and pass times over the tickets the
+// query selects (from, to, group_by, station and tz).
+func (da *KitchenDataAccess) TicketAnalytics(ctx context.Context, query url.Values) (*kitchenAnalyticsResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("kitchen client not configured")
+	}
+
+	path := "/analytics/tickets"
+	if encoded := query.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	resp, err := da.client.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var report kitchenAnalyticsResource
+	if err := decodeSuccessResponse(resp, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// kitchenDecisionResource mirrors a question the kitchen asked the floor
+// about a ticket.
+type kitchenDecisionResource struct {
+	Question    string                        `json:"question"`
+	Options     []event.KitchenDecisionOption `json:"options"`
+	RaisedBy    string                        `json:"raised_by"`
+	RaisedAt    time.Time                     `json:"raised_at"`
+	ExpiresAt   time.Time                     `json:"expires_at"`
+	EscalatedAt *time.Time                    `json:"escalated_at"` // Set once a manager has to answer
+	ChosenID    string                        `json:"chosen_id"`
+	AnsweredBy  string                        `json:"answered_by"`
+}
+
+// PendingDecision returns the question the ticket waits on, or nil when
+// nobody has to answer anything.
+func (t *kitchenTicketResource) PendingDecision() *kitchenDecisionResource {
+	if !t.DecisionRequired || len(t.DecisionPayload) == 0 {
+		return nil
+	}
+	var d kitchenDecisionResource
+	if err := json.Unmarshal(t.DecisionPayload, &d); err != nil {
+		return nil
+	}
+	return &d
+}
+
+// kitchenPendingDecisionResource is a ticket waiting for the floor.
+type kitchenPendingDecisionResource struct {
+	Ticket   kitchenTicketResource   `json:"ticket"`
+	Decision kitchenDecisionResource `json:"decision"`
+}
+
+// ListDecisions returns the tickets waiting for an answer from the floor,
+// oldest question first. An empty orderID lists them for every order.
+func (da *KitchenDataAccess) ListDecisions(ctx context.Context, orderID string) ([]kitchenPendingDecisionResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("kitchen client not configured")
+	}
+
+	path := "/decisions"
+	if orderID != "" {
+		path += "?order_id=" + url.QueryEscape(orderID)
+	}
+	resp, err := da.client.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Decisions []kitchenPendingDecisionResource `json:"decisions"`
+	}
+	if err := decodeSuccessResponse(resp, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload.Decisions, nil
+}
+
+// RaiseDecision asks the floor about a ticket. The body carries the question
+// and its options as the kitchen service expects them.
+func (da *KitchenDataAccess) RaiseDecision(ctx context.Context, ticketID string, body map[string]interface{}) (*kitchenTicketResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("kitchen client not configured")
+	}
+	if ticketID == "" {
+		return nil, fmt.Errorf("missing ticket ID")
+	}
+
+	path := fmt.Sprintf("/tickets/%s/decision", url.PathEscape(ticketID))
+	resp, err := da.client.Request(ctx, "POST", path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket kitchenTicketResource
+	if err := decodeSuccessResponse(resp, &ticket); err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
+
+// AnswerDecision picks one of the options the kitchen offered for a ticket.
+func (da *KitchenDataAccess) AnswerDecision(ctx context.Context, ticketID, optionID, answeredBy string) (*kitchenTicketResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("kitchen client not configured")
+	}
+	if ticketID == "" || optionID == "" {
+		return nil, fmt.Errorf("missing decision answer information")
+	}
+
+	path := fmt.Sprintf("/tickets/%s/decision/answer", url.PathEscape(ticketID))
+	resp, err := da.client.Request(ctx, "POST", path, map[string]interface{}{
+		"option_id":   optionID,
+		"answered_by": answeredBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket kitchenTicketResource
+	if err := decodeSuccessResponse(resp, &ticket); err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
@@ -67,6 +67,15 @@ func TestKitchenDataAccessGetTicketNilDA(t *testing.T) {
 	}
 }
 
+func TestKitchenDataAccessListTicketTransitionsNilClient(t *testing.T) {
+	da := &KitchenDataAccess{client: nil}
+
+	_, err := da.ListTicketTransitions(context.Background(), "ticket-1")
+	if err == nil {
+		t.Error("ListTicketTransitions() with nil client should return error")
+	}
+}
+
 func TestKitchenDataAccessTransitionTicketNilClient(t *testing.T) {
 	da := &KitchenDataAccess{client: nil}
 
@@ -214,3 +223,94 @@ func TestKitchenTicketResourceWithNilPointers(t *testing.T) {
 		t.Error("DeliveredAt should be nil")
 	}
 }
+
+func TestKitchenTicketResourceExpectedReadyAt(t *testing.T) {
+	created := time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)
+	started := created.Add(5 * time.Minute)
+
+	ticket := kitchenTicketResource{CreatedAt: created}
+	if got := ticket.ExpectedReadyAt(); got != nil {
+		t.Errorf("ExpectedReadyAt() without prep time = %v, want nil", got)
+	}
+
+	ticket.PrepTime = 12
+	if got := ticket.ExpectedReadyAt(); got == nil || !got.Equal(created.Add(12*time.Minute)) {
+		t.Errorf("ExpectedReadyAt() before start = %v, want %v", got, created.Add(12*time.Minute))
+	}
+
+	fired := created.Add(2 * time.Minute)
+	ticket.FiredAt = &fired
+	if got := ticket.ExpectedReadyAt(); got == nil || !got.Equal(fired.Add(12*time.Minute)) {
+		t.Errorf("ExpectedReadyAt() after firing = %v, want %v", got, fired.Add(12*time.Minute))
+	}
+
+	ticket.StartedAt = &started
+	if got := ticket.ExpectedReadyAt(); got == nil || !got.Equal(started.Add(12*time.Minute)) {
+		t.Errorf("ExpectedReadyAt() after start = %v, want %v", got, started.Add(12*time.Minute))
+	}
+
+	finished := started.Add(10 * time.Minute)
+	ticket.FinishedAt = &finished
+	if got := ticket.ExpectedReadyAt(); got != nil {
+		t.Errorf("ExpectedReadyAt() when finished = %v, want nil", got)
+	}
+}
+
+func TestKitchenDataAccessExpoNilClient(t *testing.T) {
+	da := &KitchenDataAccess{client: nil}
+	ctx := context.Background()
+
+	if _, err := da.ListExpoOrders(ctx); err == nil {
+		t.Error("ListExpoOrders() with nil client should return error")
+	}
+	if _, err := da.BumpOrder(ctx, "order-1"); err == nil {
+		t.Error("BumpOrder() with nil client should return error")
+	}
+}
+
+func TestKitchenDataAccessTicketAnalyticsNilClient(t *testing.T) {
+	da := &KitchenDataAccess{client: nil}
+
+	_, err := da.TicketAnalytics(context.Background(), nil)
+	if err == nil {
+		t.Error("TicketAnalytics() with nil client should return error")
+	}
+}
+
+func TestKitchenDataAccessDecisionsNilClient(t *testing.T) {
+	da := &KitchenDataAccess{client: nil}
+	ctx := context.Background()
+
+	if _, err := da.ListDecisions(ctx, ""); err == nil {
+		t.Error("ListDecisions() with nil client should return error")
+	}
+	if _, err := da.RaiseDecision(ctx, "ticket-1", map[string]interface{}{"question": "Out of salmon"}); err == nil {
+		t.Error("RaiseDecision() with nil client should return error")
+	}
+	if _, err := da.AnswerDecision(ctx, "ticket-1", "1", "user-1"); err == nil {
+		t.Error("AnswerDecision() with nil client should return error")
+	}
+}
+
+func TestKitchenTicketPendingDecision(t *testing.T) {
+	ticket := kitchenTicketResource{
+		DecisionRequired: true,
+		DecisionPayload:  []byte(`{"question":"Out of salmon","escalated_at":"2026-01-02T20:05:00Z","options":[{"id":"1","label":"Cancel the item","outcome":"cancel"}]}`),
+	}
+
+	decision := ticket.PendingDecision()
+	if decision == nil {
+		t.Fatal("PendingDecision() = nil, want decision")
+	}
+	if decision.Question != "Out of salmon" || decision.EscalatedAt == nil {
+		t.Errorf("PendingDecision() = %+v", decision)
+	}
+	if len(decision.Options) != 1 || decision.Options[0].Outcome != "cancel" {
+		t.Errorf("PendingDecision() options = %+v", decision.Options)
+	}
+
+	ticket.DecisionRequired = false
+	if got := ticket.PendingDecision(); got != nil {
+		t.Errorf("PendingDecision() after answer = %+v, want nil", got)
+	}
+}
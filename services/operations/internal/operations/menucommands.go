@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -380,19 +383,20 @@ func (p *DeterministicParser) handleSetPriceWorkflow(ctx context.Context, input
 		}, nil
 
 	case 3: // Update complete
-		currency := strings.ToUpper(strings.TrimSpace(input))
-		return &CommandResponse{
-			HTML: fmt.Sprintf(`
-				<div style="padding: 1rem; background: #ecfdf5; border-left: 4px solid #10b981;">
-					<p><strong>✅ Price Updated</strong></p>
-					<p><code>%s</code>: %s %s</p>
-				</div>
+		code, _ := data["code"].(string)
+		price, _ := data["price"].(string)
+		params := []string{code, price, strings.TrimSpace(input)}
+		response, err := p.handleSetPrice(ctx, params)
+		p.audit(ctx, p.registry.commands["set-price"], params, response, err)
+		if err == nil {
+			p.journal(ctx, p.registry.commands["set-price"], params, response)
+			response.HTML += `
 				<script>
 					sessionStorage.removeItem('ops_workflow');
 				</script>
-			`, data["code"], data["price"], currency),
-			Success: true,
-		}, nil
+			`
+		}
+		return response, err
 	}
 
 	return &CommandResponse{
@@ -692,8 +696,14 @@ func (p *DeterministicParser) handleEditMenuItemWorkflow(ctx context.Context, in
 	}
 }
 
-// handleSetPrice initiates price setting workflow
+// handleSetPrice sets the price of a menu item in one currency, e.g.
+// "set price BURG-001 15.99 USD". Without params it starts the interactive
+// workflow instead.
 func (p *DeterministicParser) handleSetPrice(ctx context.Context, params []string) (*CommandResponse, error) {
+	if len(params) > 0 {
+		return p.setMenuItemPrice(ctx, params)
+	}
+
 	return &CommandResponse{
 		HTML: `
 			<div style="padding: 1rem; background: #f0f9ff; border-left: 4px solid #0ea5e9;">
@@ -713,6 +723,137 @@ func (p *DeterministicParser) handleSetPrice(ctx context.Context, params []strin
 	}, nil
 }
 
+func (p *DeterministicParser) setMenuItemPrice(ctx context.Context, params []string) (*CommandResponse, error) {
+	code, amount, currency, err := parsePriceParams(params)
+	if err != nil {
+		return &CommandResponse{
+			HTML:    formatError(html.EscapeString(err.Error())),
+			Success: false,
+			Message: "Invalid price",
+		}, nil
+	}
+	if p.menuClient == nil {
+		return &CommandResponse{
+			HTML:    formatError("Menu service is not configured"),
+			Success: false,
+			Message: "Menu service unavailable",
+		}, nil
+	}
+
+	// The menu service replaces the whole item on update, so the price is
+	// changed on the item as fetched and everything else is sent back as is.
+	resp, err := p.menuClient.Request(ctx, "GET", "/menu/items/code/"+url.PathEscape(code), nil)
+	var item map[string]interface{}
+	if err == nil {
+		err = decodeSuccessResponse(resp, &item)
+	}
+	itemID, _ := item["id"].(string)
+	if err != nil || itemID == "" {
+		return &CommandResponse{
+			HTML:    formatError(fmt.Sprintf("Menu item %s not found", html.EscapeString(code))),
+			Success: false,
+			Message: "Menu item not found",
+		}, nil
+	}
+
+	prices, _ := item["prices"].([]interface{})
+	if currency == "" {
+		currency = defaultPriceCurrency(prices)
+	}
+
+	previous, hadPrevious := 0.0, false
+	for _, entry := range prices {
+		price, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if priceCurrency, _ := price["currency_code"].(string); strings.EqualFold(priceCurrency, currency) {
+			previous, _ = price["amount"].(float64)
+			hadPrevious = true
+			price["amount"] = amount
+		}
+	}
+	if !hadPrevious {
+		prices = append(prices, map[string]interface{}{"amount": amount, "currency_code": currency})
+	}
+	item["prices"] = prices
+
+	if _, err := p.menuClient.Request(ctx, "PUT", "/menu/items/"+url.PathEscape(itemID), item); err != nil {
+		return &CommandResponse{
+			HTML:    formatError(fmt.Sprintf("Cannot update price of %s: %s", html.EscapeString(code), html.EscapeString(serviceErrorMessage(err)))),
+			Success: false,
+			Message: "Price update failed",
+		}, nil
+	}
+
+	change := fmt.Sprintf("%.2f %s", amount, currency)
+	effects := map[string]string{"code": code, "currency": currency}
+	if hadPrevious {
+		change = fmt.Sprintf("%.2f → %.2f %s", previous, amount, currency)
+		effects["previous_price"] = strconv.FormatFloat(previous, 'f', 2, 64)
+	}
+
+	return &CommandResponse{
+		HTML: fmt.Sprintf(`
+			<div style="padding: 1rem; background: #ecfdf5; border-left: 4px solid #10b981;">
+				<p><strong>✅ Price Updated</strong></p>
+				<p><code>%s</code>: %s</p>
+			</div>
+		`, html.EscapeString(code), change),
+		Success: true,
+		Message: fmt.Sprintf("Price of %s set to %.2f %s", code, amount, currency),
+		Effects: effects,
+	}, nil
+}
+
+// inverseSetPrice restores the price a successful set-price replaced. A price
+// added in a new currency has nothing to restore.
+func inverseSetPrice(params []string, response *CommandResponse) *JournalCommand {
+	code, previous, currency := response.Effects["code"], response.Effects["previous_price"], response.Effects["currency"]
+	if code == "" || previous == "" || currency == "" {
+		return nil
+	}
+	return &JournalCommand{Command: "set-price", Params: []string{code, previous, currency}}
+}
+
+// parsePriceParams reads "[code tokens...] amount [currency]". The currency
+// is empty when not given.
+func parsePriceParams(tokens []string) (code string, amount float64, currency string, err error) {
+	last := len(tokens) - 1
+	if last >= 0 {
+		if _, convErr := strconv.ParseFloat(tokens[last], 64); convErr != nil {
+			currency = strings.ToUpper(tokens[last])
+			last--
+		}
+	}
+	if last < 1 {
+		return "", 0, "", fmt.Errorf("an item code and a price are required")
+	}
+
+	amount, err = strconv.ParseFloat(tokens[last], 64)
+	if err != nil || amount < 0 {
+		return "", 0, "", fmt.Errorf("price must be a non-negative number, got %q", tokens[last])
+	}
+	if currency != "" && len(currency) != 3 {
+		return "", 0, "", fmt.Errorf("currency must be a 3-letter code, got %q", currency)
+	}
+
+	return menuCodeFromTokens(tokens[:last]), amount, currency, nil
+}
+
+// defaultPriceCurrency is the currency of the item's first price, or USD for
+// an item without prices.
+func defaultPriceCurrency(prices []interface{}) string {
+	for _, entry := range prices {
+		if price, ok := entry.(map[string]interface{}); ok {
+			if currency, _ := price["currency_code"].(string); currency != "" {
+				return strings.ToUpper(currency)
+			}
+		}
+	}
+	return "USD"
+}
+
 // Helper functions
 
 func toJSON(data map[string]interface{}) string {
@@ -39,6 +39,10 @@ func (a *OrderItemAdapter) GetOrderItem(itemID string) (kitchenstream.OrderItemD
 		StatusClass:        formatOrderItemStatusClass(item.Status),
 		Category:           item.Category,
 		Notes:              item.Notes,
+		Modifiers:          modifierLabels(item.Modifiers),
+		PortionName:        item.PortionName,
+		Course:             item.Course,
+		Held:               item.Held,
 		CreatedAt:          item.CreatedAt.Format("3:04 PM"),
 		RequiresProduction: item.Category != "beverage" && item.Category != "dessert",
 	}, nil
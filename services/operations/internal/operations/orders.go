@@ -3,668 +3,770 @@ package operations
 import (
 	"context"
 	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/appetiteclub/appetite/pkg/enums/station"
 )
 
+// Orders are referenced in chat by the short ID shown in the UI (the first
+// segment of the order UUID) or by the table they belong to. Chat input is
+// lowercased and split on hyphens before it reaches a handler, so a full UUID
+// or a menu short code such as BURG-001 arrives as several tokens.
+
+const minOrderRefLength = 4
+
 // ORDER QUERIES
 
 func (p *DeterministicParser) handleListOrders(ctx context.Context, params []string) (*CommandResponse, error) {
-	// TODO: Call order service GET /orders
-	html := `
-		<p><strong>All Orders:</strong></p>
-		<table>
-			<thead>
-				<tr>
-					<th>Order #</th>
-					<th>Table</th>
-					<th>Items</th>
-					<th>Status</th>
-					<th>Total</th>
-					<th>Created</th>
-				</tr>
-			</thead>
-			<tbody>
-				<tr>
-					<td>101</td>
-					<td>Table 2</td>
-					<td>3</td>
-					<td><span style="color: #f59e0b">Preparing</span></td>
-					<td>$45.00</td>
-					<td>10 min ago</td>
-				</tr>
-				<tr>
-					<td>102</td>
-					<td>Table 3</td>
-					<td>5</td>
-					<td><span style="color: #3b82f6">Pending</span></td>
-					<td>$78.50</td>
-					<td>5 min ago</td>
-				</tr>
-				<tr>
-					<td>103</td>
-					<td>Table 5</td>
-					<td>2</td>
-					<td><span style="color: #10b981">Ready</span></td>
-					<td>$32.00</td>
-					<td>2 min ago</td>
-				</tr>
-			</tbody>
-		</table>
-		<p><em>Total: 3 orders</em></p>
-	`
+	orders, err := NewOrderDataAccess(p.orderClient).ListOrders(ctx)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch orders: %s", serviceErrorMessage(err)), "Order fetch failed"), nil
+	}
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    p.renderOrderList(ctx, "All Orders", orders),
 		Success: true,
 		Message: "Orders retrieved successfully",
 	}, nil
 }
 
 func (p *DeterministicParser) handleListActiveOrders(ctx context.Context, params []string) (*CommandResponse, error) {
-	// TODO: Call order service GET /orders/active
-	html := `
-		<p><strong>Active Orders:</strong></p>
-		<table>
-			<thead>
-				<tr>
-					<th>Order #</th>
-					<th>Table</th>
-					<th>Items</th>
-					<th>Status</th>
-					<th>Total</th>
-				</tr>
-			</thead>
-			<tbody>
-				<tr>
-					<td>101</td>
-					<td>Table 2</td>
-					<td>3</td>
-					<td><span style="color: #f59e0b">Preparing</span></td>
-					<td>$45.00</td>
-				</tr>
-				<tr>
-					<td>102</td>
-					<td>Table 3</td>
-					<td>5</td>
-					<td><span style="color: #3b82f6">Pending</span></td>
-					<td>$78.50</td>
-				</tr>
-			</tbody>
-		</table>
-		<p><em>2 active orders</em></p>
-	`
+	orders, err := NewOrderDataAccess(p.orderClient).ListActiveOrders(ctx)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch orders: %s", serviceErrorMessage(err)), "Order fetch failed"), nil
+	}
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    p.renderOrderList(ctx, "Active Orders", orders),
 		Success: true,
 		Message: "Active orders retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service GET /orders/:id
-
-	html := fmt.Sprintf(`
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	orders := NewOrderDataAccess(p.orderClient)
+	items, err := orders.ListOrderItems(ctx, order.ID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch items for order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Order fetch failed"), nil
+	}
+
+	var itemList strings.Builder
+	for _, item := range items {
+		label := fmt.Sprintf("%s × %d - %s", item.DishName, item.Quantity, formatMoney(item.Price*float64(item.Quantity)))
+		if item.Status == "cancelled" {
+			fmt.Fprintf(&itemList, "\n\t\t\t\t\t<li><s>%s</s></li>", html.EscapeString(label))
+			continue
+		}
+		fmt.Fprintf(&itemList, "\n\t\t\t\t\t<li>%s</li>", html.EscapeString(label))
+	}
+	if len(items) == 0 {
+		itemList.WriteString("\n\t\t\t\t\t<li><em>No items yet</em></li>")
+	}
+
+	var totals string
+	if bill, err := orders.GetBill(ctx, order.ID); err == nil {
+		totals = fmt.Sprintf(`
+			<li><strong>Subtotal:</strong> %s</li>
+			<li><strong>Service:</strong> %s</li>
+			<li><strong>Tax:</strong> %s</li>
+			<li><strong>Total:</strong> %s</li>
+			<li><strong>Paid:</strong> %s (balance %s)</li>`,
+			formatMoney(bill.Subtotal), formatMoney(bill.ServiceCharge), formatMoney(bill.Tax),
+			formatMoney(bill.AmountDue), formatMoney(bill.Paid), formatMoney(bill.Balance))
+	} else {
+		_, subtotal := summarizeOrderItems(items)
+		totals = fmt.Sprintf(`
+			<li><strong>Subtotal:</strong> %s</li>`, formatMoney(subtotal))
+	}
+
+	out := fmt.Sprintf(`
 		<p><strong>Order #%s Details:</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table 2</li>
-			<li><strong>Status:</strong> <span style="color: #f59e0b">Preparing</span></li>
-			<li><strong>Server:</strong> Maria</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 			<li><strong>Items:</strong>
-				<ul>
-					<li>Burger × 2 - $20.00</li>
-					<li>Fries × 2 - $10.00</li>
-					<li>Soda × 3 - $15.00</li>
+				<ul>%s
 				</ul>
-			</li>
-			<li><strong>Subtotal:</strong> $45.00</li>
-			<li><strong>Tax:</strong> $4.50</li>
-			<li><strong>Total:</strong> $49.50</li>
-			<li><strong>Created:</strong> 10 minutes ago</li>
+			</li>%s
+			<li><strong>Created:</strong> %s</li>
 		</ul>
-	`, orderID)
+	`, shortOrderID(order.ID), html.EscapeString(p.tableLabels(ctx)(order.TableID)), orderStatusBadge(order.Status),
+		itemList.String(), totals, relativeTimeSince(order.CreatedAt))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Order %s retrieved", orderID),
+		Message: fmt.Sprintf("Order %s retrieved", shortOrderID(order.ID)),
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetOrderItems(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service GET /orders/:id/items
-
-	html := fmt.Sprintf(`
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	items, err := NewOrderDataAccess(p.orderClient).ListOrderItems(ctx, order.ID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch items for order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Order items fetch failed"), nil
+	}
+
+	if len(items) == 0 {
+		return &CommandResponse{
+			HTML:    fmt.Sprintf(`<p>Order #%s has no items yet. Use <code>add item %s [code] [qty]</code>.</p>`, shortOrderID(order.ID), strings.ToLower(shortOrderID(order.ID))),
+			Success: true,
+			Message: "Order has no items",
+		}, nil
+	}
+
+	var rows strings.Builder
+	for _, item := range items {
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td><code>%s</code></td>
+					<td>%s</td>
+					<td>%d</td>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+				</tr>`, shortOrderID(item.ID), html.EscapeString(item.DishName), item.Quantity,
+			formatMoney(item.Price), orderStatusBadge(item.Status), html.EscapeString(item.Notes))
+	}
+
+	count, subtotal := summarizeOrderItems(items)
+	out := fmt.Sprintf(`
 		<p><strong>Items in Order #%s:</strong></p>
 		<table>
 			<thead>
 				<tr>
+					<th>Ref</th>
 					<th>Item</th>
-					<th>Code</th>
 					<th>Qty</th>
-					<th>Unit Price</th>
-					<th>Total</th>
+					<th>Price</th>
 					<th>Status</th>
+					<th>Notes</th>
 				</tr>
 			</thead>
-			<tbody>
-				<tr>
-					<td>Burger</td>
-					<td>BURG-001</td>
-					<td>2</td>
-					<td>$10.00</td>
-					<td>$20.00</td>
-					<td><span style="color: #f59e0b">Preparing</span></td>
-				</tr>
-				<tr>
-					<td>Fries</td>
-					<td>SIDE-002</td>
-					<td>2</td>
-					<td>$5.00</td>
-					<td>$10.00</td>
-					<td><span style="color: #10b981">Ready</span></td>
-				</tr>
-				<tr>
-					<td>Soda</td>
-					<td>DRINK-003</td>
-					<td>3</td>
-					<td>$5.00</td>
-					<td>$15.00</td>
-					<td><span style="color: #10b981">Ready</span></td>
-				</tr>
+			<tbody>%s
 			</tbody>
 		</table>
-		<p><em>3 items, Total: $45.00</em></p>
-	`, orderID)
+		<p><em>%d items, Subtotal: %s</em></p>
+	`, shortOrderID(order.ID), rows.String(), count, formatMoney(subtotal))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Order items retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetOrderStatus(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service GET /orders/:id/status
-
-	html := fmt.Sprintf(`
-		<p><strong>Order #%s Status:</strong></p>
-		<ul>
-			<li><strong>Current State:</strong> <span style="color: #f59e0b">Preparing</span></li>
-			<li><strong>Progress:</strong> 2/3 items ready</li>
-			<li><strong>Estimated Ready:</strong> 5 minutes</li>
-			<li><strong>Last Updated:</strong> 2 minutes ago</li>
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	items, err := NewOrderDataAccess(p.orderClient).ListOrderItems(ctx, order.ID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch items for order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Order status fetch failed"), nil
+	}
+
+	counts := map[string]int{}
+	for _, item := range items {
+		counts[item.Status]++
+	}
+	var breakdown strings.Builder
+	for _, status := range []string{"pending", "preparing", "ready", "delivered", "cancelled"} {
+		if counts[status] > 0 {
+			fmt.Fprintf(&breakdown, "\n\t\t\t<li>%s: %d</li>", orderStatusBadge(status), counts[status])
+		}
+	}
+
+	out := fmt.Sprintf(`
+		<p><strong>Order #%s Status:</strong> %s</p>
+		<ul>%s
 		</ul>
-	`, orderID)
+		<p><em>Last updated %s</em></p>
+	`, shortOrderID(order.ID), orderStatusBadge(order.Status), breakdown.String(), relativeTimeSince(order.UpdatedAt))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Order status retrieved",
+		Message: fmt.Sprintf("Order %s is %s", shortOrderID(order.ID), order.Status),
 	}, nil
 }
 
 // ORDER COMMANDS
 
 func (p *DeterministicParser) handleOpenOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call order service POST /orders with table_id
-	// Backend will generate conversational ID using atomic counter
-	// and return both conversational_id (e.g., "47") and internal UUID
-
-	// Mock: simulating backend response with conversational ID
-	conversationalID := "47" // TODO: Replace with actual backend response
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Order Opened Successfully</strong></p>
+	table, err := NewTableDataAccess(p.tableClient).FindTable(ctx, params[0])
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot open order: %s", serviceErrorMessage(err)), "Order open failed"), nil
+	}
+
+	order, err := NewOrderDataAccess(p.orderClient).CreateOrder(ctx, CreateOrderRequest{TableID: table.ID})
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot open order for table %s: %s", table.Number, serviceErrorMessage(err)), "Order open failed"), nil
+	}
+
+	ref := shortOrderID(order.ID)
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Order Opened</strong></p>
 		<ul>
-			<li><strong>Order ID:</strong> <code>%s</code></li>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> <span style="color: #3b82f6">Pending</span></li>
-			<li><strong>Created:</strong> Just now</li>
+			<li><strong>Order:</strong> #%s</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
-		<p><em>Use <code>add item %s [item] [qty]</code> to add items</em></p>
-	`, conversationalID, tableID, conversationalID)
+		<p><em>Add items with <code>add item %s [code] [qty]</code></em></p>
+	`, ref, html.EscapeString(table.Number), orderStatusBadge(order.Status), strings.ToLower(ref))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Order %s opened for table %s", conversationalID, tableID),
+		Message: fmt.Sprintf("Order %s opened for table %s", ref, table.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleCloseOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service POST /orders/:id/close
-
-	html := fmt.Sprintf(`
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	result, err := NewOrderDataAccess(p.orderClient).CloseOrder(ctx, order.ID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot close order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Order close failed"), nil
+	}
+
+	if confirm, _ := result["requires_confirmation"].(bool); confirm {
+		message, _ := result["message"].(string)
+		return &CommandResponse{
+			HTML: fmt.Sprintf(`
+				<p>⚠️ <strong>Order #%s Not Closed</strong></p>
+				<p>%s</p>
+				<p><em>Deliver or cancel the pending items first.</em></p>
+			`, shortOrderID(order.ID), html.EscapeString(message)),
+			Success: false,
+			Message: "Order has pending items",
+		}, nil
+	}
+
+	if p.handler != nil {
+		if tableID, _ := result["table_id"].(string); tableID != "" {
+			p.handler.updateTableStatus(ctx, p.handler.log(), tableID, result)
+		}
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Order #%s Closed</strong></p>
-		<ul>
-			<li><strong>Final Total:</strong> $49.50</li>
-			<li><strong>Payment Status:</strong> Pending</li>
-			<li><strong>Closed At:</strong> Just now</li>
-		</ul>
-		<p><em>Order ready for payment processing</em></p>
-	`, orderID)
+		<p><em>Table %s has been released</em></p>
+	`, shortOrderID(order.ID), html.EscapeString(p.tableLabels(ctx)(order.TableID)))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Order %s closed", orderID),
+		Message: fmt.Sprintf("Order %s closed", shortOrderID(order.ID)),
 	}, nil
 }
 
 func (p *DeterministicParser) handleCancelOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service POST /orders/:id/cancel
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
 
-	html := fmt.Sprintf(`
-		<p>⚠️ <strong>Order #%s Cancelled</strong></p>
-		<ul>
-			<li><strong>Status:</strong> <span style="color: #ef4444">Cancelled</span></li>
-			<li><strong>Cancelled At:</strong> Just now</li>
-		</ul>
-		<p><em>All items removed from kitchen queue</em></p>
-	`, orderID)
+	if _, err := NewOrderDataAccess(p.orderClient).UpdateOrderStatus(ctx, order.ID, "cancelled"); err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot cancel order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Order cancel failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Order #%s Cancelled</strong></p>
+	`, shortOrderID(order.ID))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Order %s cancelled", orderID),
+		Message: fmt.Sprintf("Order %s cancelled", shortOrderID(order.ID)),
 	}, nil
 }
 
 func (p *DeterministicParser) handleAddItem(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	itemCode := params[1]
-	quantity := params[2]
-	// TODO: Call order service POST /orders/:id/items
+	code, quantity, err := parseItemCodeAndQuantity(params[1:])
+	if err != nil {
+		return orderCommandError(err.Error(), "Invalid item"), nil
+	}
 
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Item Added to Order</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Item:</strong> %s</li>
-			<li><strong>Quantity:</strong> %s</li>
-			<li><strong>Status:</strong> Added to order</li>
-		</ul>
-		<p><em>Use <code>send to kitchen %s</code> to send to kitchen</em></p>
-	`, orderID, itemCode, quantity, orderID)
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
 
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Item added successfully",
-	}, nil
+	return p.addOrderItem(ctx, order, code, quantity, nil)
 }
 
 func (p *DeterministicParser) handleRemoveItem(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	itemCode := params[1]
-	// TODO: Call order service DELETE /orders/:id/items/:item_code
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Item Removed from Order</strong></p>
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	item, errResp := p.lookupOrderItem(ctx, order, menuCodeFromTokens(params[1:]))
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	if err := NewOrderDataAccess(p.orderClient).CancelOrderItem(ctx, item.ID); err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot remove %s: %s", item.DishName, serviceErrorMessage(err)), "Item removal failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Item Removed</strong></p>
 		<ul>
 			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Item:</strong> %s</li>
-			<li><strong>Status:</strong> Removed</li>
+			<li><strong>Item:</strong> %s × %d</li>
 		</ul>
-	`, orderID, itemCode)
+	`, shortOrderID(order.ID), html.EscapeString(item.DishName), item.Quantity)
 
-	return &CommandResponse{
-		HTML:    html,
+	response := &CommandResponse{
+		HTML:    out,
 		Success: true,
-		Message: "Item removed successfully",
-	}, nil
+		Message: fmt.Sprintf("%s removed from order %s", item.DishName, shortOrderID(order.ID)),
+	}
+	if item.MenuItemID != nil {
+		if menuItem, err := p.fetchMenuItem(ctx, *item.MenuItemID); err == nil && menuItem.ShortCode != "" {
+			response.Effects = map[string]string{
+				"order_id":  order.ID,
+				"menu_code": menuItem.ShortCode,
+				"quantity":  strconv.Itoa(item.Quantity),
+			}
+		}
+	}
+	return response, nil
 }
 
 func (p *DeterministicParser) handleUpdateItem(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	itemCode := params[1]
-	quantity := params[2]
-	// TODO: Call order service PATCH /orders/:id/items/:item_code
-
-	html := fmt.Sprintf(`
+	code, quantity, err := parseItemCodeAndQuantity(params[1:])
+	if err != nil {
+		return orderCommandError(err.Error(), "Invalid item"), nil
+	}
+
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	item, errResp := p.lookupOrderItem(ctx, order, code)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	updated, err := NewOrderDataAccess(p.orderClient).UpdateOrderItem(ctx, item.ID, UpdateOrderItemRequest{Quantity: &quantity})
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot update %s: %s", item.DishName, serviceErrorMessage(err)), "Item update failed"), nil
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Item Updated</strong></p>
 		<ul>
 			<li><strong>Order:</strong> #%s</li>
 			<li><strong>Item:</strong> %s</li>
-			<li><strong>New Quantity:</strong> %s</li>
-			<li><strong>Status:</strong> Updated</li>
+			<li><strong>Quantity:</strong> %d → %d</li>
+			<li><strong>Line Total:</strong> %s</li>
 		</ul>
-	`, orderID, itemCode, quantity)
+	`, shortOrderID(order.ID), html.EscapeString(item.DishName), item.Quantity, updated.Quantity,
+		formatMoney(updated.Price*float64(updated.Quantity)))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Item updated successfully",
+		Message: fmt.Sprintf("%s quantity set to %d", item.DishName, updated.Quantity),
 	}, nil
 }
 
+// handleSendToKitchen fires every course still on hold for the order.
 func (p *DeterministicParser) handleSendToKitchen(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service POST /orders/:id/send
+	return p.fireCourse(ctx, orderRefFromTokens(params), "")
+}
 
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Order Sent to Kitchen</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Status:</strong> <span style="color: #f59e0b">In Kitchen</span></li>
-			<li><strong>Estimated Time:</strong> 15-20 minutes</li>
+// handleFireCourse fires one course: "fire 2 table 5", "fire main 3f2a".
+func (p *DeterministicParser) handleFireCourse(ctx context.Context, params []string) (*CommandResponse, error) {
+	return p.fireCourse(ctx, orderRefFromTokens(params[1:]), params[0])
+}
+
+func (p *DeterministicParser) fireCourse(ctx context.Context, ref, course string) (*CommandResponse, error) {
+	order, errResp := p.lookupOrder(ctx, ref)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	result, err := NewOrderDataAccess(p.orderClient).FireCourse(ctx, order.ID, course)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot fire order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Course fire failed"), nil
+	}
+
+	what := "held courses"
+	if course != "" {
+		what = "course " + course
+	}
+	if len(result.Fired) == 0 {
+		return &CommandResponse{
+			HTML:    fmt.Sprintf(`<p>Order #%s has no %s on hold.</p>`, shortOrderID(order.ID), html.EscapeString(what)),
+			Success: true,
+			Message: "Nothing to fire",
+		}, nil
+	}
+
+	var rows strings.Builder
+	for _, item := range result.Fired {
+		fmt.Fprintf(&rows, `
+				<li>%d × %s <em>(course %d)</em></li>`, item.Quantity, html.EscapeString(item.DishName), item.Course)
+	}
+
+	out := fmt.Sprintf(`
+		<p>🔥 <strong>Order #%s: fired %s</strong></p>
+		<ul>%s
 		</ul>
-		<p><em>Kitchen has been notified</em></p>
-	`, orderID)
+		<p><em>Table %s</em></p>
+	`, shortOrderID(order.ID), html.EscapeString(what), rows.String(), html.EscapeString(p.tableLabels(ctx)(order.TableID)))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Order sent to kitchen",
+		Message: fmt.Sprintf("Fired %d items of order %s", len(result.Fired), shortOrderID(order.ID)),
 	}, nil
 }
 
 func (p *DeterministicParser) handleMarkReady(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service POST /orders/:id/ready
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
 
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Order Marked as Ready</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Status:</strong> <span style="color: #10b981">Ready for Delivery</span></li>
-			<li><strong>All items:</strong> Prepared</li>
-		</ul>
-		<p><em>Server can now deliver to table</em></p>
-	`, orderID)
+	if _, err := NewOrderDataAccess(p.orderClient).UpdateOrderStatus(ctx, order.ID, "ready"); err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot mark order %s ready: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Order update failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Order #%s Ready</strong></p>
+		<p><em>Table %s can be served</em></p>
+	`, shortOrderID(order.ID), html.EscapeString(p.tableLabels(ctx)(order.TableID)))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Order marked as ready",
+		Message: fmt.Sprintf("Order %s marked ready", shortOrderID(order.ID)),
 	}, nil
 }
 
 func (p *DeterministicParser) handleReopenOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service POST /orders/:id/reopen
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Order #%s Reopened</strong></p>
-		<ul>
-			<li><strong>Status:</strong> <span style="color: #3b82f6">Active</span></li>
-			<li><strong>Reopened At:</strong> Just now</li>
-		</ul>
-		<p><em>Order is now active and can be modified</em></p>
-	`, orderID)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: fmt.Sprintf("Order %s reopened", orderID),
-	}, nil
+	return orderCommandUnsupported("Reopening a closed order"), nil
 }
 
 func (p *DeterministicParser) handleAddNote(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	note := params[1]
-	// TODO: Call order service POST /orders/:id/note
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Note Added to Order</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Note:</strong> %s</li>
-			<li><strong>Added:</strong> Just now</li>
-		</ul>
-	`, orderID, note)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Note added successfully",
-	}, nil
+	return orderCommandUnsupported("Order-level notes"), nil
 }
 
 func (p *DeterministicParser) handleAssignOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	userID := params[1]
-	// TODO: Call order service POST /orders/:id/assign
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Order Assigned</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Assigned to:</strong> User %s</li>
-			<li><strong>Assigned At:</strong> Just now</li>
-		</ul>
-	`, orderID, userID)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Order assigned successfully",
-	}, nil
+	return orderCommandUnsupported("Assigning a waiter to an order"), nil
 }
 
 func (p *DeterministicParser) handleSplitOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	strategy := params[1]
-	// TODO: Call order service POST /orders/:id/split
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Order Split</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Strategy:</strong> %s</li>
-			<li><strong>New Orders:</strong> Creating sub-orders...</li>
-		</ul>
-		<p><em>Split operation completed</em></p>
-	`, orderID, strategy)
+	req, err := parseSplitStrategy(params[1])
+	if err != nil {
+		return &CommandResponse{
+			HTML:    formatError(fmt.Sprintf("%s. Use <code>group</code> or a number of guests, e.g. <code>split order 47 3</code>", html.EscapeString(err.Error()))),
+			Success: false,
+			Message: "Invalid split strategy",
+		}, nil
+	}
+
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	orderID := shortOrderID(order.ID)
+
+	split, err := NewOrderDataAccess(p.orderClient).SplitOrder(ctx, order.ID, req)
+	if err != nil {
+		return &CommandResponse{
+			HTML:    formatError(fmt.Sprintf("Failed to split order %s: %s", orderID, html.EscapeString(serviceErrorMessage(err)))),
+			Success: false,
+			Message: "Order split failed",
+		}, nil
+	}
+
+	var rows strings.Builder
+	for _, check := range split.Checks {
+		items := make([]string, 0, len(check.Lines))
+		for _, line := range check.Lines {
+			label := fmt.Sprintf("%s × %d", line.DishName, line.Quantity)
+			if line.Share > 0 {
+				label = fmt.Sprintf("%s (%.0f%%)", label, line.Share*100)
+			}
+			items = append(items, html.EscapeString(label))
+		}
+		if len(items) == 0 {
+			items = append(items, "Even share")
+		}
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td><strong>%s</strong></td>
+					<td>%s</td>
+					<td>$%.2f</td>
+					<td>$%.2f</td>
+					<td><strong>$%.2f</strong></td>
+				</tr>`, html.EscapeString(check.Name), strings.Join(items, "<br>"), check.Subtotal, check.ServiceCharge+check.Tax, check.AmountDue)
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Order #%s Split (%s)</strong></p>
+		<table>
+			<thead>
+				<tr>
+					<th>Check</th>
+					<th>Items</th>
+					<th>Subtotal</th>
+					<th>Charges</th>
+					<th>Due</th>
+				</tr>
+			</thead>
+			<tbody>%s
+			</tbody>
+		</table>
+		<p><em>%d checks, Total due: $%.2f</em></p>
+	`, html.EscapeString(orderID), html.EscapeString(split.Strategy), rows.String(), len(split.Checks), split.AmountDue)
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Order split successfully",
+		Message: fmt.Sprintf("Order %s split into %d checks", orderID, len(split.Checks)),
 	}, nil
 }
 
-func (p *DeterministicParser) handleMergeOrders(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID1 := params[0]
-	orderID2 := params[1]
-	// TODO: Call order service POST /orders/merge
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Orders Merged</strong></p>
-		<ul>
-			<li><strong>Source Orders:</strong> #%s, #%s</li>
-			<li><strong>New Order ID:</strong> 123</li>
-			<li><strong>Total Items:</strong> Combined</li>
-		</ul>
-		<p><em>All items merged into single order</em></p>
-	`, orderID1, orderID2)
+// parseSplitStrategy maps the chat strategy argument to a split request:
+// "group" splits by order group and a number splits evenly by that many guests.
+func parseSplitStrategy(strategy string) (SplitOrderRequest, error) {
+	strategy = strings.ToLower(strings.TrimSpace(strategy))
+	switch strategy {
+	case "group", "groups", "guest", "guests":
+		return SplitOrderRequest{Strategy: "group"}, nil
+	}
+
+	ways, err := strconv.Atoi(strings.TrimPrefix(strategy, "even"))
+	if err != nil || ways < 1 {
+		return SplitOrderRequest{}, fmt.Errorf("unknown split strategy %q", strategy)
+	}
+	return SplitOrderRequest{Strategy: "even", Ways: ways}, nil
+}
 
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Orders merged successfully",
-	}, nil
+func (p *DeterministicParser) handleMergeOrders(ctx context.Context, params []string) (*CommandResponse, error) {
+	return orderCommandUnsupported("Merging orders"), nil
 }
 
 func (p *DeterministicParser) handleCreateGroup(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	groupLabel := params[1]
-	// TODO: Call order service POST /orders/:id/groups
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	group, err := NewOrderDataAccess(p.orderClient).CreateOrderGroup(ctx, order.ID, params[1])
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot create group %s: %s", params[1], serviceErrorMessage(err)), "Group creation failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Group Created</strong></p>
 		<ul>
 			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Group Label:</strong> %s</li>
-			<li><strong>Status:</strong> Empty (ready for items)</li>
+			<li><strong>Group:</strong> %s</li>
 		</ul>
-		<p><em>Use <code>add item to group %s %s [item] [qty]</code> to add items</em></p>
-	`, orderID, groupLabel, orderID, groupLabel)
+		<p><em>Add items with <code>add item to group %s %s [code] [qty]</code></em></p>
+	`, shortOrderID(order.ID), html.EscapeString(group.Name), strings.ToLower(shortOrderID(order.ID)), html.EscapeString(group.Name))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Group created successfully",
+		Message: fmt.Sprintf("Group %s created", group.Name),
 	}, nil
 }
 
 func (p *DeterministicParser) handleAddItemToGroup(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	groupLabel := params[1]
-	itemCode := params[2]
-	quantity := params[3]
-	// TODO: Call order service POST /orders/:id/groups/:label/items
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Item Added to Group</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Group:</strong> %s</li>
-			<li><strong>Item:</strong> %s</li>
-			<li><strong>Quantity:</strong> %s</li>
-		</ul>
-	`, orderID, groupLabel, itemCode, quantity)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Item added to group successfully",
-	}, nil
+	code, quantity, err := parseItemCodeAndQuantity(params[2:])
+	if err != nil {
+		return orderCommandError(err.Error(), "Invalid item"), nil
+	}
+
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	group, errResp := p.lookupOrderGroup(ctx, order, params[1])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	return p.addOrderItem(ctx, order, code, quantity, group)
 }
 
 func (p *DeterministicParser) handleMoveItemToGroup(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	itemCode := params[1]
-	targetGroup := params[2]
-	// TODO: Call order service PATCH /orders/:id/items/:item_code/move
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Item Moved to Group</strong></p>
+	last := len(params) - 1
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	item, errResp := p.lookupOrderItem(ctx, order, menuCodeFromTokens(params[1:last]))
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	group, errResp := p.lookupOrderGroup(ctx, order, params[last])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	groupID := group.ID
+	if _, err := NewOrderDataAccess(p.orderClient).UpdateOrderItem(ctx, item.ID, UpdateOrderItemRequest{GroupID: &groupID}); err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot move %s: %s", item.DishName, serviceErrorMessage(err)), "Item move failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Item Moved</strong></p>
 		<ul>
 			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Item:</strong> %s</li>
-			<li><strong>Target Group:</strong> %s</li>
+			<li><strong>Item:</strong> %s × %d</li>
+			<li><strong>Group:</strong> %s</li>
 		</ul>
-	`, orderID, itemCode, targetGroup)
+	`, shortOrderID(order.ID), html.EscapeString(item.DishName), item.Quantity, html.EscapeString(group.Name))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Item moved successfully",
+		Message: fmt.Sprintf("%s moved to %s", item.DishName, group.Name),
 	}, nil
 }
 
 func (p *DeterministicParser) handleRemoveGroup(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	groupLabel := params[1]
-	// TODO: Call order service DELETE /orders/:id/groups/:label
-
-	html := fmt.Sprintf(`
-		<p>⚠️ <strong>Group Removed</strong></p>
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	group, errResp := p.lookupOrderGroup(ctx, order, params[1])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	if err := NewOrderDataAccess(p.orderClient).DeleteOrderGroup(ctx, order.ID, group.ID); err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot remove group %s: %s", group.Name, serviceErrorMessage(err)), "Group removal failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Group Removed</strong></p>
 		<ul>
 			<li><strong>Order:</strong> #%s</li>
 			<li><strong>Group:</strong> %s</li>
-			<li><strong>Items:</strong> Moved back to main order</li>
 		</ul>
-	`, orderID, groupLabel)
+		<p><em>Its items moved to the default group</em></p>
+	`, shortOrderID(order.ID), html.EscapeString(group.Name))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Group removed successfully",
+		Message: fmt.Sprintf("Group %s removed", group.Name),
 	}, nil
 }
 
 func (p *DeterministicParser) handleApplyDiscount(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	discount := params[1]
-	// TODO: Call order service POST /orders/:id/discount
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Discount Applied</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>Discount:</strong> %s</li>
-			<li><strong>New Total:</strong> Calculating...</li>
-		</ul>
-	`, orderID, discount)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Discount applied successfully",
-	}, nil
+	return orderCommandUnsupported("Discounts"), nil
 }
 
 func (p *DeterministicParser) handleTransferOrder(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	newTableID := params[1]
-	// TODO: Call order service PATCH /orders/:id/transfer
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Order Transferred</strong></p>
-		<ul>
-			<li><strong>Order:</strong> #%s</li>
-			<li><strong>New Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> Active</li>
-		</ul>
-		<p><em>Order moved successfully</em></p>
-	`, orderID, newTableID)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: fmt.Sprintf("Order transferred to table %s", newTableID),
-	}, nil
+	return orderCommandUnsupported("Moving an order to another table"), nil
 }
 
 // ADDITIONAL ORDER QUERIES
 
 func (p *DeterministicParser) handleGetOrdersByTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service GET /tables/:id/orders
+	table, err := NewTableDataAccess(p.tableClient).FindTable(ctx, params[0])
+	if err != nil {
+		return orderCommandError(serviceErrorMessage(err), "Table not found"), nil
+	}
 
-	html := fmt.Sprintf(`
-		<p><strong>Orders for Table %s:</strong></p>
-		<table>
-			<thead>
-				<tr>
-					<th>Order #</th>
-					<th>Status</th>
-					<th>Items</th>
-					<th>Total</th>
-					<th>Created</th>
-				</tr>
-			</thead>
-			<tbody>
-				<tr>
-					<td>101</td>
-					<td><span style="color: #f59e0b">Preparing</span></td>
-					<td>3</td>
-					<td>$45.00</td>
-					<td>10 min ago</td>
-				</tr>
-			</tbody>
-		</table>
-	`, tableID)
+	orders, err := NewOrderDataAccess(p.orderClient).ListOrdersByTable(ctx, table.ID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch orders for table %s: %s", table.Number, serviceErrorMessage(err)), "Order fetch failed"), nil
+	}
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    p.renderOrderList(ctx, fmt.Sprintf("Orders for Table %s", table.Number), orders),
 		Success: true,
 		Message: "Orders retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetGroups(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service GET /orders/:id/groups
-
-	html := fmt.Sprintf(`
+	order, errResp := p.lookupOrder(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	orders := NewOrderDataAccess(p.orderClient)
+	groups, err := orders.ListOrderGroups(ctx, order.ID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch groups for order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Group fetch failed"), nil
+	}
+	items, err := orders.ListOrderItems(ctx, order.ID)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Failed to fetch items for order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Group fetch failed"), nil
+	}
+
+	byGroup := map[string][]orderItemResource{}
+	for _, item := range items {
+		key := ""
+		if item.GroupID != nil {
+			key = *item.GroupID
+		}
+		byGroup[key] = append(byGroup[key], item)
+	}
+
+	var rows strings.Builder
+	writeRow := func(name string, groupItems []orderItemResource) {
+		count, subtotal := summarizeOrderItems(groupItems)
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td>%s</td>
+					<td>%d</td>
+					<td>%s</td>
+				</tr>`, html.EscapeString(name), count, formatMoney(subtotal))
+	}
+	for _, group := range groups {
+		name := group.Name
+		if group.IsDefault {
+			name += " (default)"
+		}
+		writeRow(name, byGroup[group.ID])
+		delete(byGroup, group.ID)
+	}
+	var ungrouped []orderItemResource
+	for _, groupItems := range byGroup {
+		ungrouped = append(ungrouped, groupItems...)
+	}
+	if len(ungrouped) > 0 {
+		writeRow("Unassigned", ungrouped)
+	}
+
+	out := fmt.Sprintf(`
 		<p><strong>Groups in Order #%s:</strong></p>
 		<table>
 			<thead>
@@ -674,118 +776,458 @@ func (p *DeterministicParser) handleGetGroups(ctx context.Context, params []stri
 					<th>Subtotal</th>
 				</tr>
 			</thead>
-			<tbody>
-				<tr>
-					<td>Person 1</td>
-					<td>2</td>
-					<td>$25.00</td>
-				</tr>
-				<tr>
-					<td>Person 2</td>
-					<td>1</td>
-					<td>$20.00</td>
-				</tr>
+			<tbody>%s
 			</tbody>
 		</table>
-		<p><em>2 groups total</em></p>
-	`, orderID)
+		<p><em>%d groups total</em></p>
+	`, shortOrderID(order.ID), rows.String(), len(groups))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Groups retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetOrderHistory(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service GET /orders/:id/history
+	return orderCommandUnsupported("Order history"), nil
+}
 
-	html := fmt.Sprintf(`
-		<p><strong>Order #%s History:</strong></p>
-		<ul>
-			<li><strong>Created:</strong> 2025-11-12 10:00 AM by Maria</li>
-			<li><strong>Item Added:</strong> Burger × 2 (10:05 AM)</li>
-			<li><strong>Sent to Kitchen:</strong> 10:10 AM</li>
-			<li><strong>Marked Ready:</strong> 10:25 AM</li>
-			<li><strong>Current Status:</strong> <span style="color: #10b981">Ready</span></li>
-		</ul>
-	`, orderID)
+func (p *DeterministicParser) handleGetWaiter(ctx context.Context, params []string) (*CommandResponse, error) {
+	return orderCommandUnsupported("Waiter assignment"), nil
+}
 
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Order history retrieved",
-	}, nil
+func (p *DeterministicParser) handleGetOrderNotes(ctx context.Context, params []string) (*CommandResponse, error) {
+	return orderCommandUnsupported("Order-level notes"), nil
 }
 
-func (p *DeterministicParser) handleGetWaiter(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service GET /orders/:id/waiter
+// ORDER HELPERS
 
-	html := fmt.Sprintf(`
-		<p><strong>Assigned Waiter for Order #%s:</strong></p>
+// stations returns the handler's station registry; nil, which means the
+// built-in stations, when the parser runs without a handler.
+func (p *DeterministicParser) stations() *station.Registry {
+	if p.handler == nil {
+		return nil
+	}
+	return p.handler.stations
+}
+
+// addOrderItem adds quantity units of the menu item with the given short code
+// to the order, in group when one is given and in the default group otherwise.
+func (p *DeterministicParser) addOrderItem(ctx context.Context, order *orderResource, code string, quantity int, group *orderGroupResource) (*CommandResponse, error) {
+	menuItem, err := p.fetchMenuItemByCode(ctx, code)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Menu item %s not found: %s", code, serviceErrorMessage(err)), "Menu item not found"), nil
+	}
+
+	orders := NewOrderDataAccess(p.orderClient)
+	payload := orderItemPayload(p.stations(), menuItem, quantity, "")
+	if group == nil {
+		if groups, err := orders.ListOrderGroups(ctx, order.ID); err == nil {
+			group = defaultOrderGroup(groups)
+		}
+	}
+	if group != nil {
+		payload["group_id"] = group.ID
+	}
+
+	item, err := orders.CreateOrderItem(ctx, order.ID, payload)
+	if err != nil {
+		return orderCommandError(fmt.Sprintf("Cannot add %s to order %s: %s", code, shortOrderID(order.ID), serviceErrorMessage(err)), "Item add failed"), nil
+	}
+
+	groupLine := ""
+	if group != nil {
+		groupLine = fmt.Sprintf("\n\t\t\t<li><strong>Group:</strong> %s</li>", html.EscapeString(group.Name))
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Item Added</strong></p>
 		<ul>
-			<li><strong>Name:</strong> Maria Rodriguez</li>
-			<li><strong>User ID:</strong> USR-123</li>
-			<li><strong>Assigned:</strong> 10:00 AM</li>
+			<li><strong>Order:</strong> #%s</li>
+			<li><strong>Item:</strong> %s (%s)</li>
+			<li><strong>Quantity:</strong> %d</li>
+			<li><strong>Line Total:</strong> %s</li>
+			<li><strong>Routing:</strong> %s</li>%s
 		</ul>
-	`, orderID)
+	`, shortOrderID(order.ID), html.EscapeString(item.DishName), html.EscapeString(menuItem.ShortCode), item.Quantity,
+		formatMoney(item.Price*float64(item.Quantity)), routingLabel(deriveStation(p.stations(), menuItem)), groupLine)
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Waiter info retrieved",
+		Message: fmt.Sprintf("Added %d × %s to order %s", item.Quantity, item.DishName, shortOrderID(order.ID)),
+		Effects: map[string]string{"order_id": order.ID, "item_id": item.ID},
 	}, nil
 }
 
-func (p *DeterministicParser) handleGetOrderNotes(ctx context.Context, params []string) (*CommandResponse, error) {
-	orderID := params[0]
-	// TODO: Call order service GET /orders/:id/notes
+// lookupOrder resolves an order reference, or returns the error response to
+// show when it cannot be resolved.
+func (p *DeterministicParser) lookupOrder(ctx context.Context, ref string) (*orderResource, *CommandResponse) {
+	orderData := NewOrderDataAccess(p.orderClient)
+
+	if prefix, ok := orderIDPrefix(ref); ok {
+		orders, err := orderData.FindOrdersByIDPrefix(ctx, prefix)
+		if err != nil {
+			return nil, orderCommandError(fmt.Sprintf("Failed to fetch orders: %s", serviceErrorMessage(err)), "Order lookup failed")
+		}
+		order, matched, err := matchOrderRef(orders, ref)
+		if err != nil {
+			return nil, orderCommandError(err.Error(), "Order not found")
+		}
+		if matched {
+			return order, nil
+		}
+	}
+
+	table, err := NewTableDataAccess(p.tableClient).FindTable(ctx, ref)
+	if err != nil {
+		return nil, orderCommandError(fmt.Sprintf("No order or table matches %q", ref), "Order not found")
+	}
+	orders, err := orderData.ListOrdersByTable(ctx, table.ID)
+	if err != nil {
+		return nil, orderCommandError(fmt.Sprintf("Failed to fetch orders of table %s: %s", table.Number, serviceErrorMessage(err)), "Order lookup failed")
+	}
+	if order := activeOrderForTable(orders, table.ID); order != nil {
+		return order, nil
+	}
+	return nil, orderCommandError(fmt.Sprintf("Table %s has no active order", table.Number), "Order not found")
+}
 
-	html := fmt.Sprintf(`
-		<p><strong>Notes for Order #%s:</strong></p>
-		<ul>
-			<li><strong>[10:05 AM]</strong> Customer allergic to peanuts</li>
-			<li><strong>[10:12 AM]</strong> Extra napkins requested</li>
-			<li><strong>[10:20 AM]</strong> Rush order - customer in a hurry</li>
-		</ul>
-		<p><em>3 notes total</em></p>
-	`, orderID)
+// lookupOrderItem finds the item of the order that a chat reference points
+// to: a menu short code, or the item ref shown by "order items". When several
+// items share a menu code, the most recent one still in play wins.
+func (p *DeterministicParser) lookupOrderItem(ctx context.Context, order *orderResource, ref string) (*orderItemResource, *CommandResponse) {
+	items, err := NewOrderDataAccess(p.orderClient).ListOrderItems(ctx, order.ID)
+	if err != nil {
+		return nil, orderCommandError(fmt.Sprintf("Failed to fetch items for order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Order item lookup failed")
+	}
+
+	menuItemID := ""
+	if menuItem, err := p.fetchMenuItemByCode(ctx, ref); err == nil {
+		menuItemID = menuItem.ID
+	}
+
+	if item := matchOrderItem(items, menuItemID, ref); item != nil {
+		return item, nil
+	}
+	return nil, orderCommandError(fmt.Sprintf("Order %s has no open item matching %s", shortOrderID(order.ID), ref), "Order item not found")
+}
 
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Order notes retrieved",
-	}, nil
+func (p *DeterministicParser) lookupOrderGroup(ctx context.Context, order *orderResource, ref string) (*orderGroupResource, *CommandResponse) {
+	groups, err := NewOrderDataAccess(p.orderClient).ListOrderGroups(ctx, order.ID)
+	if err != nil {
+		return nil, orderCommandError(fmt.Sprintf("Failed to fetch groups for order %s: %s", shortOrderID(order.ID), serviceErrorMessage(err)), "Group lookup failed")
+	}
+
+	key := compactRef(ref)
+	for i := range groups {
+		if compactRef(groups[i].Name) == key || (len(key) >= minOrderRefLength && strings.HasPrefix(strings.ToLower(groups[i].ID), key)) {
+			return &groups[i], nil
+		}
+	}
+	return nil, orderCommandError(fmt.Sprintf("Order %s has no group %q", shortOrderID(order.ID), ref), "Group not found")
+}
+
+func (p *DeterministicParser) fetchMenuItemByCode(ctx context.Context, code string) (*menuItemResource, error) {
+	if p.menuClient == nil {
+		return nil, fmt.Errorf("menu client not configured")
+	}
+
+	resp, err := p.menuClient.Request(ctx, "GET", "/menu/items/code/"+url.PathEscape(code), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var item menuItemResource
+	if err := decodeSuccessResponse(resp, &item); err != nil {
+		return nil, err
+	}
+	if item.ID == "" {
+		return nil, fmt.Errorf("menu item %s not found", code)
+	}
+
+	return &item, nil
+}
+
+func (p *DeterministicParser) fetchMenuItem(ctx context.Context, id string) (*menuItemResource, error) {
+	if p.menuClient == nil {
+		return nil, fmt.Errorf("menu client not configured")
+	}
+
+	resp, err := p.menuClient.Request(ctx, "GET", "/menu/items/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var item menuItemResource
+	if err := decodeSuccessResponse(resp, &item); err != nil {
+		return nil, err
+	}
+	if item.ID == "" {
+		return nil, fmt.Errorf("menu item %s not found", id)
+	}
+
+	return &item, nil
+}
+
+// tableLabels returns a lookup from table ID to table number. Unknown tables
+// fall back to a shortened ID so listings still render when the table service
+// is down.
+func (p *DeterministicParser) tableLabels(ctx context.Context) func(string) string {
+	numbers := map[string]string{}
+	if tables, err := NewTableDataAccess(p.tableClient).ListTables(ctx); err == nil {
+		for _, table := range tables {
+			numbers[table.ID] = table.Number
+		}
+	}
+	return func(id string) string {
+		if number, ok := numbers[id]; ok && number != "" {
+			return number
+		}
+		return truncateID(id)
+	}
+}
+
+func (p *DeterministicParser) renderOrderList(ctx context.Context, title string, orders []orderResource) string {
+	if len(orders) == 0 {
+		return fmt.Sprintf(`<p><strong>%s:</strong></p><p><em>No orders found</em></p>`, html.EscapeString(title))
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+
+	tableLabel := p.tableLabels(ctx)
+
+	var rows strings.Builder
+	for _, order := range orders {
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%d</td>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+				</tr>`, shortOrderID(order.ID), html.EscapeString(tableLabel(order.TableID)), order.ItemCount,
+			orderStatusBadge(order.Status), formatMoney(order.Subtotal), relativeTimeSince(order.CreatedAt))
+	}
+
+	return fmt.Sprintf(`
+		<p><strong>%s:</strong></p>
+		<table>
+			<thead>
+				<tr>
+					<th>Order #</th>
+					<th>Table</th>
+					<th>Items</th>
+					<th>Status</th>
+					<th>Total</th>
+					<th>Created</th>
+				</tr>
+			</thead>
+			<tbody>%s
+			</tbody>
+		</table>
+		<p><em>Total: %d orders</em></p>
+	`, html.EscapeString(title), rows.String(), len(orders))
+}
+
+// orderIDPrefix returns ref as the hex digits an order ID starts with, or
+// false when ref cannot be the start of an order ID.
+func orderIDPrefix(ref string) (string, bool) {
+	key := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ref), "#"))
+	digits := strings.ReplaceAll(key, "-", "")
+	if len(digits) < minOrderRefLength || len(digits) > 32 {
+		return "", false
+	}
+	for _, c := range digits {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", false
+		}
+	}
+	return key, true
+}
+
+// matchOrderRef finds the order whose ID starts with ref. matched is false
+// when ref is too short to be an order ID or no order starts with it, so the
+// caller can try it as a table reference instead.
+func matchOrderRef(orders []orderResource, ref string) (order *orderResource, matched bool, err error) {
+	key := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ref), "#"))
+	if len(key) < minOrderRefLength {
+		return nil, false, nil
+	}
+
+	var found []int
+	for i := range orders {
+		if strings.HasPrefix(strings.ToLower(orders[i].ID), key) {
+			found = append(found, i)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return &orders[found[0]], true, nil
+	default:
+		return nil, false, fmt.Errorf("order reference %q matches %d orders", ref, len(found))
+	}
+}
+
+// activeOrderForTable returns the most recent order of the table that is
+// neither closed nor cancelled.
+func activeOrderForTable(orders []orderResource, tableID string) *orderResource {
+	var latest *orderResource
+	for i := range orders {
+		order := &orders[i]
+		if order.TableID != tableID || !isActiveOrderStatus(order.Status) {
+			continue
+		}
+		if latest == nil || order.CreatedAt.After(latest.CreatedAt) {
+			latest = order
+		}
+	}
+	return latest
+}
+
+// matchOrderItem returns the most recent item that is still pending,
+// preparing or ready and was ordered from menuItemID, falling back to an item
+// whose ID starts with ref.
+func matchOrderItem(items []orderItemResource, menuItemID, ref string) *orderItemResource {
+	key := strings.ToLower(strings.TrimSpace(ref))
+	var byMenu, byRef *orderItemResource
+	for i := range items {
+		item := &items[i]
+		if item.Status == "cancelled" || item.Status == "delivered" {
+			continue
+		}
+		if menuItemID != "" && item.MenuItemID != nil && *item.MenuItemID == menuItemID {
+			if byMenu == nil || item.CreatedAt.After(byMenu.CreatedAt) {
+				byMenu = item
+			}
+		}
+		if len(key) >= minOrderRefLength && strings.HasPrefix(strings.ToLower(item.ID), key) {
+			byRef = item
+		}
+	}
+	if byMenu != nil {
+		return byMenu
+	}
+	return byRef
+}
+
+func defaultOrderGroup(groups []orderGroupResource) *orderGroupResource {
+	for i := range groups {
+		if groups[i].IsDefault {
+			return &groups[i]
+		}
+	}
+	if len(groups) > 0 {
+		return &groups[0]
+	}
+	return nil
+}
+
+// menuCodeFromTokens rebuilds a menu short code that the chat normalizer split
+// on its hyphens, e.g. ["burg", "001"] becomes "BURG-001".
+func menuCodeFromTokens(tokens []string) string {
+	return strings.ToUpper(strings.Join(tokens, "-"))
+}
+
+// parseItemCodeAndQuantity reads "[code tokens...] [qty]" from the tail of a
+// command.
+func parseItemCodeAndQuantity(tokens []string) (string, int, error) {
+	if len(tokens) < 2 {
+		return "", 0, fmt.Errorf("an item code and a quantity are required")
+	}
+	last := len(tokens) - 1
+	quantity, err := strconv.Atoi(tokens[last])
+	if err != nil || quantity <= 0 {
+		return "", 0, fmt.Errorf("quantity must be a positive number, got %q", tokens[last])
+	}
+	return menuCodeFromTokens(tokens[:last]), quantity, nil
 }
 
-// UTILITY COMMANDS
-
-func (p *DeterministicParser) handleUndo(ctx context.Context, params []string) (*CommandResponse, error) {
-	// TODO: Implement undo logic with command history stack
-	// TODO: Determine if last command is reversible
-	// TODO: Execute reverse operation
-	// TODO: Update command history
-
-	html := `
-		<p>⚠️ <strong>Undo Not Yet Implemented</strong></p>
-		<div style="background: #fef3c7; padding: 1rem; border-radius: 0.5rem; border-left: 4px solid #f59e0b; margin-top: 0.5rem;">
-			<p style="margin: 0;"><strong>Planned Functionality:</strong></p>
-			<ul style="margin: 0.5rem 0 0 1.5rem; padding: 0;">
-				<li>Maintains command history stack per session</li>
-				<li>Determines if last command is reversible</li>
-				<li>Confirms action before executing undo</li>
-				<li>Shows what will be undone</li>
-			</ul>
-			<p style="margin: 0.5rem 0 0 0; font-size: 0.9em;"><em>Example: After "add item 47 BURGER 2", undo would remove those items</em></p>
-		</div>
-		<p><em>This command will be implemented in a future phase</em></p>
-	`
+// inverseAddItem removes the exact item a successful add-item created.
+func inverseAddItem(params []string, response *CommandResponse) *JournalCommand {
+	orderID, itemID := response.Effects["order_id"], response.Effects["item_id"]
+	if orderID == "" || itemID == "" {
+		return nil
+	}
+	return &JournalCommand{Command: "remove-item", Params: []string{orderID, itemID}}
+}
 
+// inverseRemoveItem adds back what a successful remove-item cancelled.
+func inverseRemoveItem(params []string, response *CommandResponse) *JournalCommand {
+	orderID, code, quantity := response.Effects["order_id"], response.Effects["menu_code"], response.Effects["quantity"]
+	if orderID == "" || code == "" || quantity == "" {
+		return nil
+	}
+	return &JournalCommand{Command: "add-item", Params: []string{orderID, code, quantity}}
+}
+
+// summarizeOrderItems returns the number of billable items and their
+// subtotal; cancelled items are left out.
+func summarizeOrderItems(items []orderItemResource) (int, float64) {
+	count := 0
+	subtotal := 0.0
+	for _, item := range items {
+		if item.Status == "cancelled" {
+			continue
+		}
+		count += item.Quantity
+		subtotal += item.Price * float64(item.Quantity)
+	}
+	return count, subtotal
+}
+
+func isActiveOrderStatus(status string) bool {
+	return status != "closed" && status != "cancelled"
+}
+
+func orderStatusBadge(status string) string {
+	color := "#6b7280"
+	switch strings.ToLower(status) {
+	case "pending":
+		color = "#3b82f6"
+	case "preparing":
+		color = "#f59e0b"
+	case "ready":
+		color = "#10b981"
+	case "cancelled":
+		color = "#ef4444"
+	}
+	return fmt.Sprintf(`<span style="color: %s">%s</span>`, color, html.EscapeString(titleCase(status)))
+}
+
+// orderRefFromTokens reads an order or table reference, dropping the word
+// that names it: "table 5", "mesa 5" and "5" all give "5".
+func orderRefFromTokens(tokens []string) string {
+	if len(tokens) > 1 {
+		switch tokens[0] {
+		case "table", "mesa", "stolik", "order", "orden", "zamówienie":
+			tokens = tokens[1:]
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+func orderCommandError(message, summary string) *CommandResponse {
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    formatError(html.EscapeString(message)),
 		Success: false,
-		Message: "Undo command not yet implemented",
-	}, nil
+		Message: summary,
+	}
+}
+
+// orderCommandUnsupported answers commands the order service has no endpoint
+// for yet, instead of pretending they succeeded.
+func orderCommandUnsupported(feature string) *CommandResponse {
+	return &CommandResponse{
+		HTML:    formatError(fmt.Sprintf("%s is not supported by the order service yet.", html.EscapeString(feature))),
+		Success: false,
+		Message: "Command not supported",
+	}
 }
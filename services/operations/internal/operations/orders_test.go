@@ -0,0 +1,253 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseSplitStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    SplitOrderRequest
+		wantErr bool
+	}{
+		{name: "group", input: "group", want: SplitOrderRequest{Strategy: "group"}},
+		{name: "guestsAlias", input: "Guests", want: SplitOrderRequest{Strategy: "group"}},
+		{name: "evenByNumber", input: "3", want: SplitOrderRequest{Strategy: "even", Ways: 3}},
+		{name: "evenPrefixed", input: "even4", want: SplitOrderRequest{Strategy: "even", Ways: 4}},
+		{name: "zeroWays", input: "0", wantErr: true},
+		{name: "unknown", input: "seat", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSplitStrategy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSplitStrategy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSplitStrategy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleSplitOrderInvalidStrategy(t *testing.T) {
+	p := &DeterministicParser{}
+
+	resp, err := p.handleSplitOrder(context.Background(), []string{"47", "seat"})
+	if err != nil {
+		t.Fatalf("handleSplitOrder() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("handleSplitOrder() should fail for an unknown strategy")
+	}
+}
+
+func TestHandleSplitOrderNoClient(t *testing.T) {
+	p := &DeterministicParser{}
+
+	resp, err := p.handleSplitOrder(context.Background(), []string{"47", "2"})
+	if err != nil {
+		t.Fatalf("handleSplitOrder() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("handleSplitOrder() should fail without an order client")
+	}
+}
+
+func TestMatchOrderRef(t *testing.T) {
+	orders := []orderResource{
+		{ID: "550e8400-e29b-41d4-a716-446655440600"},
+		{ID: "550e9911-e29b-41d4-a716-446655440601"},
+		{ID: "7a1b2c3d-e29b-41d4-a716-446655440602"},
+	}
+
+	tests := []struct {
+		name        string
+		ref         string
+		wantID      string
+		wantMatched bool
+		wantErr     bool
+	}{
+		{name: "shortID", ref: "7a1b2c3d", wantID: orders[2].ID, wantMatched: true},
+		{name: "hashPrefix", ref: "#550e84", wantID: orders[0].ID, wantMatched: true},
+		{name: "ambiguous", ref: "550e", wantErr: true},
+		{name: "tooShortIsTableRef", ref: "7", wantMatched: false},
+		{name: "noMatch", ref: "ffff0000", wantMatched: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matched, err := matchOrderRef(orders, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchOrderRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if matched != tt.wantMatched {
+				t.Fatalf("matchOrderRef() matched = %v, want %v", matched, tt.wantMatched)
+			}
+			if matched && got.ID != tt.wantID {
+				t.Errorf("matchOrderRef() = %s, want %s", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestOrderIDPrefix(t *testing.T) {
+	tests := []struct {
+		ref    string
+		want   string
+		wantOK bool
+	}{
+		{ref: "7a1b2c3d", want: "7a1b2c3d", wantOK: true},
+		{ref: "#550E84", want: "550e84", wantOK: true},
+		{ref: "550e8400-e29b", want: "550e8400-e29b", wantOK: true},
+		{ref: "550", wantOK: false},
+		{ref: "a-b-c", wantOK: false},
+		{ref: "t12", wantOK: false},
+		{ref: "patio", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, ok := orderIDPrefix(tt.ref)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("orderIDPrefix(%q) = %q, %v, want %q, %v", tt.ref, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestActiveOrderForTable(t *testing.T) {
+	now := time.Now()
+	orders := []orderResource{
+		{ID: "old", TableID: "t-1", Status: "pending", CreatedAt: now.Add(-time.Hour)},
+		{ID: "new", TableID: "t-1", Status: "preparing", CreatedAt: now},
+		{ID: "closed", TableID: "t-1", Status: "closed", CreatedAt: now.Add(time.Minute)},
+		{ID: "other", TableID: "t-2", Status: "pending", CreatedAt: now},
+	}
+
+	if got := activeOrderForTable(orders, "t-1"); got == nil || got.ID != "new" {
+		t.Errorf("activeOrderForTable(t-1) = %v, want new", got)
+	}
+	if got := activeOrderForTable(orders, "t-3"); got != nil {
+		t.Errorf("activeOrderForTable(t-3) = %v, want nil", got)
+	}
+}
+
+func TestMatchOrderItem(t *testing.T) {
+	now := time.Now()
+	burger := "menu-burger"
+	items := []orderItemResource{
+		{ID: "aaaa1111-0000", MenuItemID: &burger, Status: "delivered", CreatedAt: now.Add(time.Minute)},
+		{ID: "bbbb2222-0000", MenuItemID: &burger, Status: "pending", CreatedAt: now.Add(-time.Minute)},
+		{ID: "cccc3333-0000", MenuItemID: &burger, Status: "ready", CreatedAt: now},
+		{ID: "dddd4444-0000", Status: "pending"},
+	}
+
+	if got := matchOrderItem(items, burger, "BURG-001"); got == nil || got.ID != "cccc3333-0000" {
+		t.Errorf("matchOrderItem(menu) = %v, want the latest open burger", got)
+	}
+	if got := matchOrderItem(items, "", "dddd4444"); got == nil || got.ID != "dddd4444-0000" {
+		t.Errorf("matchOrderItem(ref) = %v, want dddd4444-0000", got)
+	}
+	if got := matchOrderItem(items, "", "aaaa1111"); got != nil {
+		t.Errorf("matchOrderItem() = %v, delivered items should not match", got)
+	}
+}
+
+func TestParseItemCodeAndQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   []string
+		wantCode string
+		wantQty  int
+		wantErr  bool
+	}{
+		{name: "splitCode", tokens: []string{"burg", "001", "2"}, wantCode: "BURG-001", wantQty: 2},
+		{name: "compactCode", tokens: []string{"burg001", "1"}, wantCode: "BURG001", wantQty: 1},
+		{name: "zeroQuantity", tokens: []string{"burg", "001", "0"}, wantErr: true},
+		{name: "wordQuantity", tokens: []string{"burg", "001", "two"}, wantErr: true},
+		{name: "missingCode", tokens: []string{"2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, qty, err := parseItemCodeAndQuantity(tt.tokens)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseItemCodeAndQuantity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (code != tt.wantCode || qty != tt.wantQty) {
+				t.Errorf("parseItemCodeAndQuantity() = %s, %d, want %s, %d", code, qty, tt.wantCode, tt.wantQty)
+			}
+		})
+	}
+}
+
+func TestOrderRefFromTokens(t *testing.T) {
+	tests := []struct {
+		tokens []string
+		want   string
+	}{
+		{tokens: []string{"table", "5"}, want: "5"},
+		{tokens: []string{"mesa", "5"}, want: "5"},
+		{tokens: []string{"order", "7a1b2c3d"}, want: "7a1b2c3d"},
+		{tokens: []string{"7a1b2c3d"}, want: "7a1b2c3d"},
+		{tokens: []string{"table"}, want: "table"},
+	}
+
+	for _, tt := range tests {
+		if got := orderRefFromTokens(tt.tokens); got != tt.want {
+			t.Errorf("orderRefFromTokens(%v) = %q, want %q", tt.tokens, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeOrderItems(t *testing.T) {
+	items := []orderItemResource{
+		{Quantity: 2, Price: 4.5, Status: "pending"},
+		{Quantity: 1, Price: 10, Status: "delivered"},
+		{Quantity: 3, Price: 2, Status: "cancelled"},
+	}
+
+	count, subtotal := summarizeOrderItems(items)
+	if count != 3 || subtotal != 19 {
+		t.Errorf("summarizeOrderItems() = %d, %v, want 3, 19", count, subtotal)
+	}
+}
+
+func TestOrderCommandsWithoutClients(t *testing.T) {
+	p := &DeterministicParser{}
+	ctx := context.Background()
+
+	handlers := map[string]struct {
+		handler CommandHandler
+		params  []string
+	}{
+		"listOrders":  {handler: p.handleListOrders},
+		"getOrder":    {handler: p.handleGetOrder, params: []string{"7a1b2c3d"}},
+		"openOrder":   {handler: p.handleOpenOrder, params: []string{"window1"}},
+		"addItem":     {handler: p.handleAddItem, params: []string{"7a1b2c3d", "burg", "001", "2"}},
+		"removeItem":  {handler: p.handleRemoveItem, params: []string{"7a1b2c3d", "burg", "001"}},
+		"createGroup": {handler: p.handleCreateGroup, params: []string{"7a1b2c3d", "ana"}},
+		"byTable":     {handler: p.handleGetOrdersByTable, params: []string{"window1"}},
+		"merge":       {handler: p.handleMergeOrders, params: []string{"7a1b2c3d", "8b2c3d4e"}},
+		"transfer":    {handler: p.handleTransferOrder, params: []string{"7a1b2c3d", "window1"}},
+		"sendKitchen": {handler: p.handleSendToKitchen, params: []string{"7a1b2c3d"}},
+		"fireCourse":  {handler: p.handleFireCourse, params: []string{"2", "table", "window1"}},
+	}
+
+	for name, tt := range handlers {
+		t.Run(name, func(t *testing.T) {
+			resp, err := tt.handler(ctx, tt.params)
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if resp.Success {
+				t.Error("order commands should not report success without a backing service")
+			}
+		})
+	}
+}
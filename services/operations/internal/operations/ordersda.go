@@ -3,6 +3,7 @@ package operations
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/appetiteclub/apt"
@@ -15,21 +16,63 @@ type orderResource struct {
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// ItemCount and Subtotal are only set on listings asked for with
+	// summary=true.
+	ItemCount int     `json:"item_count"`
+	Subtotal  float64 `json:"subtotal"`
 }
 
 // orderItemResource represents a single item inside an order.
 type orderItemResource struct {
-	ID        string    `json:"id"`
-	OrderID   string    `json:"order_id"`
-	GroupID   *string   `json:"group_id"`
-	DishName  string    `json:"dish_name"`
-	Category  string    `json:"category"`
-	Quantity  int       `json:"quantity"`
-	Price     float64   `json:"price"`
-	Status    string    `json:"status"`
-	Notes     string    `json:"notes"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         string    `json:"id"`
+	OrderID    string    `json:"order_id"`
+	GroupID    *string   `json:"group_id"`
+	DishName   string    `json:"dish_name"`
+	Category   string    `json:"category"`
+	Quantity   int       `json:"quantity"`
+	Price      float64   `json:"price"`
+	Status     string    `json:"status"`
+	Notes      string    `json:"notes"`
+	MenuItemID *string   `json:"menu_item_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	Modifiers   []orderItemModifierResource `json:"modifiers"`
+	PortionName string                      `json:"portion_name"`
+
+	// Course is 0 for items that go to the kitchen right away; Held items
+	// wait for their course to be fired
+	Course int  `json:"course"`
+	Held   bool `json:"held"`
+}
+
+// firedCourseResource is the order service answer to a course fire.
+type firedCourseResource struct {
+	OrderID string              `json:"order_id"`
+	Course  int                 `json:"course"`
+	Fired   []orderItemResource `json:"fired"`
+}
+
+// orderItemModifierResource is a modifier option picked for an order item.
+type orderItemModifierResource struct {
+	GroupName  string  `json:"group_name"`
+	OptionName string  `json:"option_name"`
+	PriceDelta float64 `json:"price_delta"`
+}
+
+// modifierLabels renders picked modifiers as "Group: Option".
+func modifierLabels(modifiers []orderItemModifierResource) []string {
+	if len(modifiers) == 0 {
+		return nil
+	}
+	labels := make([]string, len(modifiers))
+	for i, m := range modifiers {
+		labels[i] = m.OptionName
+		if m.GroupName != "" {
+			labels[i] = m.GroupName + ": " + m.OptionName
+		}
+	}
+	return labels
 }
 
 type orderGroupResource struct {
@@ -39,11 +82,65 @@ type orderGroupResource struct {
 	IsDefault bool   `json:"is_default"`
 }
 
+// orderBillResource is the bill computed by the order service.
+type orderBillResource struct {
+	OrderID       string  `json:"order_id"`
+	Subtotal      float64 `json:"subtotal"`
+	ServiceCharge float64 `json:"service_charge"`
+	Tax           float64 `json:"tax"`
+	AmountDue     float64 `json:"amount_due"`
+	Tip           float64 `json:"tip"`
+	Total         float64 `json:"total"`
+	Paid          float64 `json:"paid"`
+	Balance       float64 `json:"balance"`
+	Settled       bool    `json:"settled"`
+}
+
+// orderCheckResource is one check produced by the order service split endpoint.
+type orderCheckResource struct {
+	Name          string  `json:"name"`
+	GroupID       *string `json:"group_id"`
+	Subtotal      float64 `json:"subtotal"`
+	ServiceCharge float64 `json:"service_charge"`
+	Tax           float64 `json:"tax"`
+	AmountDue     float64 `json:"amount_due"`
+	Lines         []struct {
+		DishName string  `json:"dish_name"`
+		Quantity int     `json:"quantity"`
+		Share    float64 `json:"share"`
+		Amount   float64 `json:"amount"`
+	} `json:"lines"`
+}
+
+type orderSplitResource struct {
+	OrderID   string               `json:"order_id"`
+	Strategy  string               `json:"strategy"`
+	Checks    []orderCheckResource `json:"checks"`
+	AmountDue float64              `json:"amount_due"`
+}
+
+// SplitOrderRequest selects how the order service splits a bill. Ways is only
+// used by the "even" strategy.
+type SplitOrderRequest struct {
+	Strategy string `json:"strategy"`
+	Ways     int    `json:"ways,omitempty"`
+}
+
 // CreateOrderRequest defines the payload supported by the order service.
 type CreateOrderRequest struct {
 	TableID string `json:"table_id"`
 }
 
+// UpdateOrderItemRequest carries the item fields the order service lets
+// clients change. Nil fields are left untouched; an empty GroupID ungroups
+// the item.
+type UpdateOrderItemRequest struct {
+	Quantity *int    `json:"quantity,omitempty"`
+	Status   *string `json:"status,omitempty"`
+	Notes    *string `json:"notes,omitempty"`
+	GroupID  *string `json:"group_id,omitempty"`
+}
+
 // OrderDataAccess centralizes decoding of order service responses.
 type OrderDataAccess struct {
 	client *apt.ServiceClient
@@ -53,12 +150,31 @@ func NewOrderDataAccess(client *apt.ServiceClient) *OrderDataAccess {
 	return &OrderDataAccess{client: client}
 }
 
+// ListOrders returns every order with its item count and subtotal.
 func (da *OrderDataAccess) ListOrders(ctx context.Context) ([]orderResource, error) {
+	return da.listOrders(ctx, "/orders?summary=true")
+}
+
+// ListActiveOrders returns the orders that are neither closed nor cancelled,
+// with their item count and subtotal.
+func (da *OrderDataAccess) ListActiveOrders(ctx context.Context) ([]orderResource, error) {
+	return da.listOrders(ctx, "/orders?open=true&summary=true")
+}
+
+// FindOrdersByIDPrefix returns the orders whose ID starts with prefix.
+func (da *OrderDataAccess) FindOrdersByIDPrefix(ctx context.Context, prefix string) ([]orderResource, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("missing order id prefix")
+	}
+	return da.listOrders(ctx, "/orders?id_prefix="+url.QueryEscape(prefix))
+}
+
+func (da *OrderDataAccess) listOrders(ctx context.Context, path string) ([]orderResource, error) {
 	if da == nil || da.client == nil {
 		return nil, fmt.Errorf("order client not configured")
 	}
 
-	resp, err := da.client.List(ctx, "orders")
+	resp, err := da.client.Request(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -172,3 +288,234 @@ func (da *OrderDataAccess) ListOrderGroups(ctx context.Context, orderID string)
 
 	return groups, nil
 }
+
+func (da *OrderDataAccess) SplitOrder(ctx context.Context, orderID string, payload SplitOrderRequest) (*orderSplitResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/orders/%s/split", orderID)
+	resp, err := da.client.Request(ctx, "POST", path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var split orderSplitResource
+	if err := decodeSuccessResponse(resp, &split); err != nil {
+		return nil, err
+	}
+
+	return &split, nil
+}
+
+func (da *OrderDataAccess) ListOrdersByTable(ctx context.Context, tableID string) ([]orderResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if tableID == "" {
+		return nil, fmt.Errorf("missing table id")
+	}
+
+	path := fmt.Sprintf("/orders?table_id=%s", url.QueryEscape(tableID))
+	resp, err := da.client.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []orderResource
+	if err := decodeSuccessResponse(resp, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (da *OrderDataAccess) UpdateOrderStatus(ctx context.Context, orderID, status string) (*orderResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/orders/%s", orderID)
+	resp, err := da.client.Request(ctx, "PUT", path, map[string]string{"status": status})
+	if err != nil {
+		return nil, err
+	}
+
+	var order orderResource
+	if err := decodeSuccessResponse(resp, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// CloseOrder asks the order service to close an order. The result carries
+// requires_confirmation when items are still pending.
+func (da *OrderDataAccess) CloseOrder(ctx context.Context, orderID string) (map[string]interface{}, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/orders/%s/close", orderID)
+	resp, err := da.client.Request(ctx, "POST", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{}
+	if err := decodeSuccessResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FireCourse sends the held items of course to the kitchen. An empty course
+// fires every held course of the order.
+func (da *OrderDataAccess) FireCourse(ctx context.Context, orderID, course string) (*firedCourseResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/orders/%s/fire", orderID)
+	if course != "" {
+		path = fmt.Sprintf("/orders/%s/courses/%s/fire", orderID, url.PathEscape(course))
+	}
+	resp, err := da.client.Request(ctx, "POST", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result firedCourseResource
+	if err := decodeSuccessResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (da *OrderDataAccess) GetBill(ctx context.Context, orderID string) (*orderBillResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/orders/%s/bill", orderID)
+	resp, err := da.client.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var bill orderBillResource
+	if err := decodeSuccessResponse(resp, &bill); err != nil {
+		return nil, err
+	}
+
+	return &bill, nil
+}
+
+func (da *OrderDataAccess) CreateOrderItem(ctx context.Context, orderID string, payload map[string]interface{}) (*orderItemResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/orders/%s/items", orderID)
+	resp, err := da.client.Request(ctx, "POST", path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var item orderItemResource
+	if err := decodeSuccessResponse(resp, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (da *OrderDataAccess) UpdateOrderItem(ctx context.Context, itemID string, payload UpdateOrderItemRequest) (*orderItemResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if itemID == "" {
+		return nil, fmt.Errorf("missing item id")
+	}
+
+	path := fmt.Sprintf("/order-items/%s", itemID)
+	resp, err := da.client.Request(ctx, "PUT", path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var item orderItemResource
+	if err := decodeSuccessResponse(resp, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (da *OrderDataAccess) CancelOrderItem(ctx context.Context, itemID string) error {
+	if da == nil || da.client == nil {
+		return fmt.Errorf("order client not configured")
+	}
+	if itemID == "" {
+		return fmt.Errorf("missing item id")
+	}
+
+	path := fmt.Sprintf("/items/%s/cancel", itemID)
+	_, err := da.client.Request(ctx, "PATCH", path, nil)
+	return err
+}
+
+func (da *OrderDataAccess) CreateOrderGroup(ctx context.Context, orderID, name string) (*orderGroupResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("order client not configured")
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("missing order id")
+	}
+
+	path := fmt.Sprintf("/orders/%s/groups", orderID)
+	resp, err := da.client.Request(ctx, "POST", path, map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	var group orderGroupResource
+	if err := decodeSuccessResponse(resp, &group); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// DeleteOrderGroup removes a group; the order service moves its items to the
+// default group.
+func (da *OrderDataAccess) DeleteOrderGroup(ctx context.Context, orderID, groupID string) error {
+	if da == nil || da.client == nil {
+		return fmt.Errorf("order client not configured")
+	}
+	if orderID == "" || groupID == "" {
+		return fmt.Errorf("missing order or group id")
+	}
+
+	return da.client.Delete(ctx, fmt.Sprintf("orders/%s/groups", orderID), groupID)
+}
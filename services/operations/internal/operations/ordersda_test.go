@@ -230,3 +230,42 @@ func TestCreateOrderRequestFields(t *testing.T) {
 		t.Errorf("TableID = %q, want %q", req.TableID, "table-5")
 	}
 }
+
+func TestOrderDataAccessSplitOrderNilClient(t *testing.T) {
+	da := &OrderDataAccess{client: nil}
+
+	_, err := da.SplitOrder(context.Background(), "order-1", SplitOrderRequest{Strategy: "group"})
+	if err == nil {
+		t.Error("SplitOrder() with nil client should return error")
+	}
+}
+
+func TestOrderDataAccessWriteMethodsNilClient(t *testing.T) {
+	da := &OrderDataAccess{client: nil}
+	ctx := context.Background()
+	quantity := 2
+
+	calls := map[string]func() error{
+		"ListOrdersByTable": func() error { _, err := da.ListOrdersByTable(ctx, "table-1"); return err },
+		"UpdateOrderStatus": func() error { _, err := da.UpdateOrderStatus(ctx, "order-1", "ready"); return err },
+		"CloseOrder":        func() error { _, err := da.CloseOrder(ctx, "order-1"); return err },
+		"GetBill":           func() error { _, err := da.GetBill(ctx, "order-1"); return err },
+		"FireCourse":        func() error { _, err := da.FireCourse(ctx, "order-1", "2"); return err },
+		"CreateOrderItem":   func() error { _, err := da.CreateOrderItem(ctx, "order-1", map[string]interface{}{}); return err },
+		"UpdateOrderItem": func() error {
+			_, err := da.UpdateOrderItem(ctx, "item-1", UpdateOrderItemRequest{Quantity: &quantity})
+			return err
+		},
+		"CancelOrderItem":  func() error { return da.CancelOrderItem(ctx, "item-1") },
+		"CreateOrderGroup": func() error { _, err := da.CreateOrderGroup(ctx, "order-1", "Ana"); return err },
+		"DeleteOrderGroup": func() error { return da.DeleteOrderGroup(ctx, "order-1", "group-1") },
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); err == nil {
+				t.Errorf("%s() with nil client should return error", name)
+			}
+		})
+	}
+}
@@ -16,11 +16,18 @@ type CommandDefinition struct {
 	Description string
 	MinParams   int
 	MaxParams   int
+	// Inverse builds the command that reverts a successful run, making the
+	// command undoable. Nil for commands that cannot be undone.
+	Inverse InverseBuilder
 }
 
 // CommandHandler processes a matched command
 type CommandHandler func(ctx context.Context, params []string) (*CommandResponse, error)
 
+// InverseBuilder returns the command that reverts a command run, or nil when
+// the run left nothing to revert.
+type InverseBuilder func(params []string, response *CommandResponse) *JournalCommand
+
 // CommandRegistry holds all available commands
 type CommandRegistry struct {
 	commands map[string]*CommandDefinition
@@ -220,8 +227,9 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"ai"},
 		Handler:     r.parser.handleAddItem,
 		Description: "Add an item to the order",
-		MinParams:   3, // order_id, item_code, quantity
-		MaxParams:   3,
+		MinParams:   3, // order_ref, item_code, quantity
+		MaxParams:   4, // item_code may arrive split on its hyphen (BURG-001)
+		Inverse:     inverseAddItem,
 	})
 
 	r.register("remove-item", &CommandDefinition{
@@ -230,8 +238,9 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"ri"},
 		Handler:     r.parser.handleRemoveItem,
 		Description: "Remove an item from the order",
-		MinParams:   2, // order_id, item_code
-		MaxParams:   2,
+		MinParams:   2, // order_ref, item_code
+		MaxParams:   3,
+		Inverse:     inverseRemoveItem,
 	})
 
 	r.register("update-item", &CommandDefinition{
@@ -240,8 +249,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"ui"},
 		Handler:     r.parser.handleUpdateItem,
 		Description: "Update quantity for an item",
-		MinParams:   3, // order_id, item_code, quantity
-		MaxParams:   3,
+		MinParams:   3, // order_ref, item_code, quantity
+		MaxParams:   4,
 	})
 
 	r.register("send-to-kitchen", &CommandDefinition{
@@ -249,9 +258,39 @@ func (r *CommandRegistry) registerAllCommands() {
 		Variations:  []string{"send to kitchen", "send kitchen", "enviar cocina", "wyślij do kuchni"},
 		ShortForms:  []string{"sk"},
 		Handler:     r.parser.handleSendToKitchen,
-		Description: "Send order to kitchen",
+		Description: "Fire every held course of an order",
 		MinParams:   1,
-		MaxParams:   1,
+		MaxParams:   2,
+	})
+
+	r.register("fire-course", &CommandDefinition{
+		Canonical:   "fire-course",
+		Variations:  []string{"fire course", "fire", "marchar", "wydaj kurs"},
+		ShortForms:  []string{"fc"},
+		Handler:     r.parser.handleFireCourse,
+		Description: "Fire a held course to the kitchen",
+		MinParams:   2,
+		MaxParams:   3,
+	})
+
+	r.register("list-decisions", &CommandDefinition{
+		Canonical:   "list-decisions",
+		Variations:  []string{"list decisions", "decisions", "kitchen questions", "decisiones", "decyzje"},
+		ShortForms:  []string{"ld"},
+		Handler:     r.parser.handleListDecisions,
+		Description: "List questions the kitchen asked the floor",
+		MinParams:   0,
+		MaxParams:   2,
+	})
+
+	r.register("answer-decision", &CommandDefinition{
+		Canonical:   "answer-decision",
+		Variations:  []string{"answer decision", "decide", "responder cocina", "odpowiedz kuchni"},
+		ShortForms:  []string{"da"},
+		Handler:     r.parser.handleAnswerDecision,
+		Description: "Answer a kitchen question with one of its options",
+		MinParams:   2,
+		MaxParams:   2,
 	})
 
 	r.register("mark-ready", &CommandDefinition{
@@ -299,8 +338,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		Variations:  []string{"split order", "dividir orden", "podziel zamówienie"},
 		ShortForms:  []string{"so"},
 		Handler:     r.parser.handleSplitOrder,
-		Description: "Split an order by strategy",
-		MinParams:   2, // order_id, strategy
+		Description: "Split an order bill by group or evenly by N guests",
+		MinParams:   2, // order_id, strategy (group or number of guests)
 		MaxParams:   2,
 	})
 
@@ -330,8 +369,9 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"aig"},
 		Handler:     r.parser.handleAddItemToGroup,
 		Description: "Add an item to a specific group",
-		MinParams:   4, // order_id, group_label, item_code, quantity
-		MaxParams:   4,
+		MinParams:   4, // order_ref, group_label, item_code, quantity
+		MaxParams:   5,
+		Inverse:     inverseAddItem,
 	})
 
 	r.register("move-item-to-group", &CommandDefinition{
@@ -340,8 +380,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"mig"},
 		Handler:     r.parser.handleMoveItemToGroup,
 		Description: "Move an item to another group",
-		MinParams:   3, // order_id, item_code, target_group
-		MaxParams:   3,
+		MinParams:   3, // order_ref, item_code, target_group
+		MaxParams:   4,
 	})
 
 	r.register("remove-group", &CommandDefinition{
@@ -486,6 +526,7 @@ func (r *CommandRegistry) registerAllCommands() {
 		Description: "Seat a party at the table",
 		MinParams:   2, // table_id, party_size
 		MaxParams:   2,
+		Inverse:     inverseSeatParty,
 	})
 
 	r.register("release-table", &CommandDefinition{
@@ -496,6 +537,7 @@ func (r *CommandRegistry) registerAllCommands() {
 		Description: "Release a table once it's free",
 		MinParams:   1,
 		MaxParams:   1,
+		Inverse:     inverseReleaseTable,
 	})
 
 	r.register("reserve-table", &CommandDefinition{
@@ -504,8 +546,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"rv"},
 		Handler:     r.parser.handleReserveTable,
 		Description: "Reserve a table for a customer",
-		MinParams:   1, // table_id (customer_name optional)
-		MaxParams:   2,
+		MinParams:   1, // table_ref (customer_name optional, may span two words)
+		MaxParams:   3,
 	})
 
 	r.register("cancel-reservation", &CommandDefinition{
@@ -524,8 +566,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"aw"},
 		Handler:     r.parser.handleAssignWaiter,
 		Description: "Assign waiter to a table",
-		MinParams:   2, // table_id, user_id
-		MaxParams:   2,
+		MinParams:   2, // table_ref, user_id or "me" (a UUID arrives split on its hyphens)
+		MaxParams:   6,
 	})
 
 	r.register("mark-table-clean", &CommandDefinition{
@@ -606,8 +648,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"ct"},
 		Handler:     r.parser.handleCreateTable,
 		Description: "Create a new table",
-		MinParams:   2, // table_id, capacity
-		MaxParams:   2,
+		MinParams:   2, // table_number, capacity (the number may arrive split on its hyphen)
+		MaxParams:   3,
 	})
 
 	r.register("delete-table", &CommandDefinition{
@@ -636,8 +678,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"rnt"},
 		Handler:     r.parser.handleRenameTable,
 		Description: "Rename a table",
-		MinParams:   2, // table_id, new_name
-		MaxParams:   2,
+		MinParams:   2, // table_ref, new_name (the name may arrive split on its hyphen)
+		MaxParams:   3,
 	})
 
 	r.register("set-table-location", &CommandDefinition{
@@ -646,8 +688,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"stl"},
 		Handler:     r.parser.handleSetTableLocation,
 		Description: "Set table location/zone",
-		MinParams:   2, // table_id, location
-		MaxParams:   2,
+		MinParams:   2, // table_ref, location (up to three words)
+		MaxParams:   4,
 	})
 
 	r.register("merge-tables", &CommandDefinition{
@@ -655,9 +697,10 @@ func (r *CommandRegistry) registerAllCommands() {
 		Variations:  []string{"merge tables", "combinar mesas", "połącz stoliki"},
 		ShortForms:  []string{"mt"},
 		Handler:     r.parser.handleMergeTables,
-		Description: "Merge multiple tables",
-		MinParams:   2, // table_id_1, table_id_2
+		Description: "Merge a table into a host table",
+		MinParams:   2, // host_table_ref, table_ref
 		MaxParams:   2,
+		Inverse:     inverseMergeTables,
 	})
 
 	r.register("unmerge-tables", &CommandDefinition{
@@ -676,8 +719,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"bt"},
 		Handler:     r.parser.handleBlockTable,
 		Description: "Block a table from use",
-		MinParams:   1, // table_id (reason optional)
-		MaxParams:   2,
+		MinParams:   1, // table_ref (reason optional, up to five words)
+		MaxParams:   6,
 	})
 
 	r.register("unblock-table", &CommandDefinition{
@@ -696,8 +739,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"tt"},
 		Handler:     r.parser.handleTransferTable,
 		Description: "Transfer table to another waiter",
-		MinParams:   2, // table_id, new_waiter_id
-		MaxParams:   2,
+		MinParams:   2, // table_ref, new_waiter_id or "me"
+		MaxParams:   6,
 	})
 
 	r.register("set-table-note", &CommandDefinition{
@@ -706,8 +749,8 @@ func (r *CommandRegistry) registerAllCommands() {
 		ShortForms:  []string{"stn"},
 		Handler:     r.parser.handleSetTableNote,
 		Description: "Add a note to a table",
-		MinParams:   2, // table_id, note
-		MaxParams:   2,
+		MinParams:   2, // table_ref, note (up to twenty words)
+		MaxParams:   21,
 	})
 
 	// MENU MANAGEMENT COMMANDS
@@ -747,9 +790,10 @@ func (r *CommandRegistry) registerAllCommands() {
 		Variations:  []string{"set price", "update price", "fijar precio", "actualizar precio"},
 		ShortForms:  []string{"sp"},
 		Handler:     r.parser.handleSetPrice,
-		Description: "Set item price (interactive)",
-		MinParams:   0,
-		MaxParams:   0,
+		Description: "Set item price (interactive without params)",
+		MinParams:   0, // item_code, price, currency; none starts the workflow
+		MaxParams:   5,
+		Inverse:     inverseSetPrice,
 	})
 
 	// UTILITY COMMANDS
@@ -764,6 +808,16 @@ func (r *CommandRegistry) registerAllCommands() {
 		MaxParams:   0,
 	})
 
+	r.register("history", &CommandDefinition{
+		Canonical:   "history",
+		Variations:  []string{"history", "command history", "mi historial", "moja historia"},
+		ShortForms:  []string{"hist"},
+		Handler:     r.parser.handleHistory,
+		Description: "Show your recent commands",
+		MinParams:   0,
+		MaxParams:   1, // limit
+	})
+
 	r.register("cancel", &CommandDefinition{
 		Canonical:   "cancel",
 		Variations:  []string{"cancel", "abort", "cancelar", "abortar"},
@@ -385,6 +385,16 @@ func TestCommandRegistryFindCommandMultipleParams(t *testing.T) {
 			input:      "transfer order order123 table5",
 			wantParams: []string{"order123", "table5"},
 		},
+		{
+			name:       "fireCourseAtTable",
+			input:      "fire 2 table 5",
+			wantParams: []string{"2", "table", "5"},
+		},
+		{
+			name:       "fireCourseByName",
+			input:      "fire course main order123",
+			wantParams: []string{"main", "order123"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -456,8 +466,8 @@ func TestCommandDefinitionFields(t *testing.T) {
 	if addItemCmd.MinParams != 3 {
 		t.Errorf("MinParams = %d, want 3", addItemCmd.MinParams)
 	}
-	if addItemCmd.MaxParams != 3 {
-		t.Errorf("MaxParams = %d, want 3", addItemCmd.MaxParams)
+	if addItemCmd.MaxParams != 4 {
+		t.Errorf("MaxParams = %d, want 4", addItemCmd.MaxParams)
 	}
 }
 
@@ -467,27 +477,31 @@ func TestFindCommandAllShortForms(t *testing.T) {
 	// Note: "sp" is ambiguous (seat-party and set-price both use it)
 	// Testing only unambiguous short forms
 	shortForms := map[string]string{
-		"h":   "help",
-		"lo":  "list-orders",
-		"lao": "list-active-orders",
-		"go":  "get-order",
-		"gi":  "get-order-items",
-		"gs":  "get-order-status",
-		"oo":  "open-order",
-		"co":  "close-order",
-		"xo":  "cancel-order",
-		"ai":  "add-item",
-		"ri":  "remove-item",
-		"ui":  "update-item",
-		"sk":  "send-to-kitchen",
-		"mr":  "mark-ready",
-		"lt":  "list-tables",
-		"lat": "list-available-tables",
-		"lot": "list-occupied-tables",
-		"gt":  "get-table",
-		"rt":  "release-table",
-		"rv":  "reserve-table",
-		"cr":  "cancel-reservation",
+		"h":    "help",
+		"lo":   "list-orders",
+		"lao":  "list-active-orders",
+		"go":   "get-order",
+		"gi":   "get-order-items",
+		"gs":   "get-order-status",
+		"oo":   "open-order",
+		"co":   "close-order",
+		"xo":   "cancel-order",
+		"ai":   "add-item",
+		"ri":   "remove-item",
+		"ui":   "update-item",
+		"sk":   "send-to-kitchen",
+		"fc":   "fire-course",
+		"ld":   "list-decisions",
+		"da":   "answer-decision",
+		"mr":   "mark-ready",
+		"lt":   "list-tables",
+		"lat":  "list-available-tables",
+		"lot":  "list-occupied-tables",
+		"gt":   "get-table",
+		"rt":   "release-table",
+		"rv":   "reserve-table",
+		"cr":   "cancel-reservation",
+		"hist": "history",
 	}
 
 	for short, canonical := range shortForms {
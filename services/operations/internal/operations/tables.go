@@ -3,264 +3,292 @@ package operations
 import (
 	"context"
 	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// Tables are referenced in chat by number (see matchTableRef). Waiters are
+// referenced by user ID, or "me" for the signed-in user; since chat input is
+// split on hyphens, a UUID arrives as several tokens and is joined back here.
+
 // TABLE QUERIES
 
 func (p *DeterministicParser) handleListTables(ctx context.Context, params []string) (*CommandResponse, error) {
-	// TODO: Call table service GET /tables
+	tables, err := NewTableDataAccess(p.tableClient).ListTables(ctx)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Failed to fetch tables: %s", serviceErrorMessage(err)), "Table fetch failed"), nil
+	}
 
-	html := `
-		<p><strong>All Tables:</strong></p>
-		<table>
-			<thead>
-				<tr>
-					<th>Table #</th>
-					<th>Capacity</th>
-					<th>Status</th>
-					<th>Party Size</th>
-					<th>Server</th>
-				</tr>
-			</thead>
-			<tbody>
-				<tr>
-					<td>Table 1</td>
-					<td>4</td>
-					<td><span style="color: #10b981">Available</span></td>
-					<td>-</td>
-					<td>-</td>
-				</tr>
-				<tr>
-					<td>Table 2</td>
-					<td>6</td>
-					<td><span style="color: #f59e0b">Occupied</span></td>
-					<td>5</td>
-					<td>Maria</td>
-				</tr>
-				<tr>
-					<td>Table 3</td>
-					<td>2</td>
-					<td><span style="color: #ef4444">Reserved</span></td>
-					<td>2</td>
-					<td>John</td>
-				</tr>
-			</tbody>
-		</table>
-		<p><em>Total: 3 tables (1 available, 1 occupied, 1 reserved)</em></p>
-	`
+	counts := map[string]int{}
+	for _, table := range tables {
+		counts[table.Status]++
+	}
+
+	summary := fmt.Sprintf("Total: %d tables (%d available, %d open, %d reserved)",
+		len(tables), counts["available"], counts["open"], counts["reserved"])
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    renderTableList("All Tables", tables, summary),
 		Success: true,
 		Message: "Tables retrieved successfully",
 	}, nil
 }
 
 func (p *DeterministicParser) handleListAvailableTables(ctx context.Context, params []string) (*CommandResponse, error) {
-	// TODO: Call table service GET /tables?status=available
+	tables, err := NewTableDataAccess(p.tableClient).ListTables(ctx)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Failed to fetch tables: %s", serviceErrorMessage(err)), "Table fetch failed"), nil
+	}
 
-	html := `
-		<p><strong>Available Tables:</strong></p>
-		<table>
-			<thead>
-				<tr>
-					<th>Table #</th>
-					<th>Capacity</th>
-					<th>Status</th>
-				</tr>
-			</thead>
-			<tbody>
-				<tr>
-					<td>Table 1</td>
-					<td>4</td>
-					<td><span style="color: #10b981">Available</span></td>
-				</tr>
-				<tr>
-					<td>Table 5</td>
-					<td>8</td>
-					<td><span style="color: #10b981">Available</span></td>
-				</tr>
-			</tbody>
-		</table>
-		<p><em>2 tables available</em></p>
-	`
+	available := filterTables(tables, func(table tableResource) bool {
+		return table.Status == "available"
+	})
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    renderTableList("Available Tables", available, fmt.Sprintf("%d tables available", len(available))),
 		Success: true,
 		Message: "Available tables retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleListOccupiedTables(ctx context.Context, params []string) (*CommandResponse, error) {
-	// TODO: Call table service GET /tables?status=occupied
+	tables, err := NewTableDataAccess(p.tableClient).ListTables(ctx)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Failed to fetch tables: %s", serviceErrorMessage(err)), "Table fetch failed"), nil
+	}
 
-	html := `
-		<p><strong>Occupied Tables:</strong></p>
-		<table>
-			<thead>
-				<tr>
-					<th>Table #</th>
-					<th>Capacity</th>
-					<th>Party Size</th>
-					<th>Server</th>
-					<th>Duration</th>
-				</tr>
-			</thead>
-			<tbody>
-				<tr>
-					<td>Table 2</td>
-					<td>6</td>
-					<td>5</td>
-					<td>Maria</td>
-					<td>45 min</td>
-				</tr>
-				<tr>
-					<td>Table 4</td>
-					<td>4</td>
-					<td>3</td>
-					<td>John</td>
-					<td>20 min</td>
-				</tr>
-			</tbody>
-		</table>
-		<p><em>2 tables occupied</em></p>
-	`
+	occupied := filterTables(tables, func(table tableResource) bool {
+		return table.Status == "open" || table.Status == "clearing"
+	})
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    renderTableList("Occupied Tables", occupied, fmt.Sprintf("%d tables occupied", len(occupied))),
 		Success: true,
 		Message: "Occupied tables retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service GET /tables/:id
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	activeOrder := "-"
+	if orders, err := NewOrderDataAccess(p.orderClient).ListOrdersByTable(ctx, table.ID); err == nil {
+		if order := activeOrderForTable(orders, table.ID); order != nil {
+			activeOrder = "#" + shortOrderID(order.ID)
+		}
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p><strong>Table %s Details:</strong></p>
 		<ul>
-			<li><strong>Capacity:</strong> 6 people</li>
-			<li><strong>Status:</strong> <span style="color: #f59e0b">Occupied</span></li>
-			<li><strong>Party Size:</strong> 5 people</li>
-			<li><strong>Server:</strong> Maria Rodriguez</li>
-			<li><strong>Seated At:</strong> 45 minutes ago</li>
-			<li><strong>Active Orders:</strong> 1 (ORD-101)</li>
-			<li><strong>Total Bill:</strong> $78.50</li>
+			<li><strong>Capacity:</strong> %s</li>
+			<li><strong>Location:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
+			<li><strong>Party Size:</strong> %d</li>
+			<li><strong>Server:</strong> %s</li>
+			<li><strong>Active Order:</strong> %s</li>
+			<li><strong>Total Bill:</strong> %s</li>
+			<li><strong>Updated:</strong> %s</li>
 		</ul>
-	`, tableID)
+	`, html.EscapeString(table.Number), tableCapacityLabel(table), html.EscapeString(orDash(table.Location)),
+		tableStatusLabel(table), table.GuestCount, tableServerLabel(table), activeOrder,
+		formatBill(table.CurrentBill), relativeTimeSince(table.UpdatedAt))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s details retrieved", tableID),
+		Message: fmt.Sprintf("Table %s details retrieved", table.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetTableStatus(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service GET /tables/:id/status
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
 
-	html := fmt.Sprintf(`
+	party := strconv.Itoa(table.GuestCount)
+	if table.Capacity > 0 {
+		party = fmt.Sprintf("%d/%d", table.GuestCount, table.Capacity)
+	}
+	assigned := "No"
+	if table.AssignedTo != nil && *table.AssignedTo != "" {
+		assigned = "Yes (" + html.EscapeString(truncateID(*table.AssignedTo)) + ")"
+	}
+
+	out := fmt.Sprintf(`
 		<p><strong>Table %s Status:</strong></p>
 		<ul>
-			<li><strong>Current State:</strong> <span style="color: #f59e0b">Occupied</span></li>
-			<li><strong>Clean Status:</strong> Clean</li>
-			<li><strong>Party Size:</strong> 5/6</li>
-			<li><strong>Duration:</strong> 45 minutes</li>
-			<li><strong>Server Assigned:</strong> Yes (Maria)</li>
+			<li><strong>Current State:</strong> %s</li>
+			<li><strong>Party Size:</strong> %s</li>
+			<li><strong>Since:</strong> %s</li>
+			<li><strong>Server Assigned:</strong> %s</li>
 		</ul>
-	`, tableID)
+	`, html.EscapeString(table.Number), tableStatusLabel(table), party, relativeTimeSince(table.UpdatedAt), assigned)
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Table status retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetTableOrders(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service GET /tables/:id/orders
+	return p.handleGetOrdersByTable(ctx, params)
+}
 
-	html := fmt.Sprintf(`
-		<p><strong>Orders for Table %s:</strong></p>
+func (p *DeterministicParser) handleGetTableHistory(ctx context.Context, params []string) (*CommandResponse, error) {
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	history, err := NewTableDataAccess(p.tableClient).GetHistory(ctx, table.ID)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Failed to fetch history of table %s: %s", table.Number, serviceErrorMessage(err)), "Table history failed"), nil
+	}
+
+	if len(history) == 0 {
+		return &CommandResponse{
+			HTML:    fmt.Sprintf(`<p><strong>Table %s History:</strong></p><p><em>No activity recorded</em></p>`, html.EscapeString(table.Number)),
+			Success: true,
+			Message: "Table history retrieved",
+		}, nil
+	}
+
+	var rows strings.Builder
+	for _, activity := range history {
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+				</tr>`, relativeTimeSince(activity.At), html.EscapeString(tableActivityLabel(activity.Reason)),
+			tableStatusBadge(activity.Status), html.EscapeString(orDash(activity.Detail)))
+	}
+
+	out := fmt.Sprintf(`
+		<p><strong>Table %s History:</strong></p>
 		<table>
 			<thead>
 				<tr>
-					<th>Order #</th>
+					<th>When</th>
+					<th>Activity</th>
 					<th>Status</th>
-					<th>Items</th>
-					<th>Total</th>
-					<th>Created</th>
+					<th>Detail</th>
 				</tr>
 			</thead>
-			<tbody>
-				<tr>
-					<td>ORD-101</td>
-					<td><span style="color: #f59e0b">Preparing</span></td>
-					<td>3</td>
-					<td>$45.00</td>
-					<td>10 min ago</td>
-				</tr>
+			<tbody>%s
 			</tbody>
 		</table>
-	`, tableID)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Table orders retrieved",
-	}, nil
-}
-
-func (p *DeterministicParser) handleGetTableHistory(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service GET /tables/:id/history
-
-	html := fmt.Sprintf(`
-		<p><strong>Table %s History (Today):</strong></p>
-		<ul>
-			<li><strong>9:00 AM - 10:30 AM:</strong> Party of 2, Server: John, Total: $35.00</li>
-			<li><strong>11:00 AM - 12:15 PM:</strong> Party of 4, Server: Maria, Total: $68.50</li>
-			<li><strong>1:00 PM - Current:</strong> Party of 5, Server: Maria, Total: $78.50</li>
-		</ul>
-		<p><em>3 seatings today, $182.00 total revenue</em></p>
-	`, tableID)
+	`, html.EscapeString(table.Number), rows.String())
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Table history retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetTableServer(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service GET /tables/:id/server
+	tables, err := NewTableDataAccess(p.tableClient).ListTables(ctx)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Failed to fetch tables: %s", serviceErrorMessage(err)), "Table fetch failed"), nil
+	}
+	table, err := matchTableRef(tables, params[0])
+	if err != nil {
+		return tableCommandError(err.Error(), "Table not found"), nil
+	}
+
+	if table.AssignedTo == nil || *table.AssignedTo == "" {
+		return &CommandResponse{
+			HTML:    fmt.Sprintf(`<p><strong>Table %s</strong> has no server assigned.</p>`, html.EscapeString(table.Number)),
+			Success: true,
+			Message: "Table server info retrieved",
+		}, nil
+	}
 
-	html := fmt.Sprintf(`
+	var served []string
+	for _, other := range tables {
+		if other.AssignedTo != nil && *other.AssignedTo == *table.AssignedTo {
+			served = append(served, other.Number)
+		}
+	}
+	sort.Strings(served)
+
+	out := fmt.Sprintf(`
 		<p><strong>Server for Table %s:</strong></p>
 		<ul>
-			<li><strong>Name:</strong> Maria Rodriguez</li>
-			<li><strong>User ID:</strong> USR-123</li>
-			<li><strong>Assigned:</strong> 45 minutes ago</li>
-			<li><strong>Current Tables:</strong> 3 (Table 2, Table 6, Table 8)</li>
+			<li><strong>User ID:</strong> %s</li>
+			<li><strong>Current Tables:</strong> %d (%s)</li>
 		</ul>
-	`, tableID)
+	`, html.EscapeString(table.Number), html.EscapeString(*table.AssignedTo), len(served), html.EscapeString(strings.Join(served, ", ")))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Table server info retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetReservations(ctx context.Context, params []string) (*CommandResponse, error) {
-	// TODO: Call table service GET /tables/reservations
+	tableData := NewTableDataAccess(p.tableClient)
+	reservations, err := tableData.ListReservations(ctx)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Failed to fetch reservations: %s", serviceErrorMessage(err)), "Reservation fetch failed"), nil
+	}
+
+	current := make([]reservationResource, 0, len(reservations))
+	for _, reservation := range reservations {
+		if reservation.Status == "confirmed" {
+			current = append(current, reservation)
+		}
+	}
+	if len(current) == 0 {
+		return &CommandResponse{
+			HTML:    `<p><strong>Current Reservations:</strong></p><p><em>No reservations</em></p>`,
+			Success: true,
+			Message: "Reservations retrieved",
+		}, nil
+	}
+
+	sort.Slice(current, func(i, j int) bool {
+		return current[i].ReservedFor.Before(current[j].ReservedFor)
+	})
+
+	numbers := map[string]string{}
+	if tables, err := tableData.ListTables(ctx); err == nil {
+		for _, table := range tables {
+			numbers[table.ID] = table.Number
+		}
+	}
 
-	html := `
+	var rows strings.Builder
+	for _, reservation := range current {
+		table := "-"
+		if reservation.TableID != nil {
+			table = orDash(numbers[*reservation.TableID])
+		}
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%d</td>
+					<td>%s</td>
+				</tr>`, html.EscapeString(table), html.EscapeString(reservation.ContactName),
+			reservation.GuestCount, reservation.ReservedFor.Local().Format("02 Jan 15:04"))
+	}
+
+	out := fmt.Sprintf(`
 		<p><strong>Current Reservations:</strong></p>
 		<table>
 			<thead>
@@ -269,52 +297,37 @@ func (p *DeterministicParser) handleGetReservations(ctx context.Context, params
 					<th>Customer</th>
 					<th>Party Size</th>
 					<th>Time</th>
-					<th>Status</th>
 				</tr>
 			</thead>
-			<tbody>
-				<tr>
-					<td>Table 3</td>
-					<td>Smith Family</td>
-					<td>2</td>
-					<td>6:00 PM</td>
-					<td><span style="color: #ef4444">Reserved</span></td>
-				</tr>
-				<tr>
-					<td>Table 7</td>
-					<td>Johnson Party</td>
-					<td>6</td>
-					<td>7:30 PM</td>
-					<td><span style="color: #ef4444">Reserved</span></td>
-				</tr>
+			<tbody>%s
 			</tbody>
 		</table>
-		<p><em>2 reservations</em></p>
-	`
+		<p><em>%d reservations</em></p>
+	`, rows.String(), len(current))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Reservations retrieved",
 	}, nil
 }
 
 func (p *DeterministicParser) handleGetTableCapacity(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service GET /tables/:id/capacity
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p><strong>Table %s Capacity:</strong></p>
 		<ul>
-			<li><strong>Standard Capacity:</strong> 6 people</li>
-			<li><strong>Maximum (with extra chairs):</strong> 8 people</li>
-			<li><strong>Minimum:</strong> 4 people</li>
-			<li><strong>Configuration:</strong> Square table</li>
+			<li><strong>Seats:</strong> %s</li>
+			<li><strong>Currently Seated:</strong> %d</li>
 		</ul>
-	`, tableID)
+	`, html.EscapeString(table.Number), tableCapacityLabel(table), table.GuestCount)
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Table capacity info retrieved",
 	}, nil
@@ -323,387 +336,832 @@ func (p *DeterministicParser) handleGetTableCapacity(ctx context.Context, params
 // TABLE COMMANDS
 
 func (p *DeterministicParser) handleSeatParty(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	partySize := params[1]
-	// TODO: Call table service POST /tables/:id/seat
+	partySize, err := strconv.Atoi(params[1])
+	if err != nil || partySize <= 0 {
+		return tableCommandError(fmt.Sprintf("Invalid party size %q", params[1]), "Invalid party size"), nil
+	}
+
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	if table.Status == "open" {
+		return tableCommandError(fmt.Sprintf("Table %s already has a party seated", table.Number), "Table occupied"), nil
+	}
+
+	tableData := NewTableDataAccess(p.tableClient)
+	assignedTo := table.AssignedTo
+	if assignedTo == nil {
+		if userID := getUserIDFromContext(ctx); userID != uuid.Nil {
+			id := userID.String()
+			assignedTo = &id
+		}
+	}
+
+	seated, err := tableData.OpenTable(ctx, table.ID, partySize, assignedTo)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot seat table %s: %s", table.Number, serviceErrorMessage(err)), "Seating failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	// A party arriving on a reserved table is the reservation showing up.
+	if reservations, err := tableData.ListReservations(ctx); err == nil {
+		if reservation := nextReservationFor(reservations, table.ID); reservation != nil {
+			_, _ = tableData.UpdateReservation(ctx, reservation.ID, map[string]interface{}{"status": "seated"})
+		}
+	}
+
+	var warning string
+	if seated.Capacity > 0 && partySize > seated.Capacity {
+		warning = fmt.Sprintf("\n\t\t<p>⚠️ <em>Party exceeds the table capacity of %d</em></p>", seated.Capacity)
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Party Seated Successfully</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Party Size:</strong> %s people</li>
-			<li><strong>Status:</strong> <span style="color: #f59e0b">Occupied</span></li>
-			<li><strong>Seated At:</strong> Just now</li>
-		</ul>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Party Size:</strong> %d people</li>
+			<li><strong>Status:</strong> %s</li>
+			<li><strong>Server:</strong> %s</li>
+		</ul>%s
 		<p><em>Use <code>open-order %s</code> to start an order</em></p>
-	`, tableID, partySize, tableID)
+	`, html.EscapeString(seated.Number), partySize, tableStatusLabel(seated), tableServerLabel(seated),
+		warning, html.EscapeString(seated.Number))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Party of %s seated at table %s", partySize, tableID),
+		Message: fmt.Sprintf("Party of %d seated at table %s", partySize, seated.Number),
+		Effects: map[string]string{"table_id": table.ID},
 	}, nil
 }
 
 func (p *DeterministicParser) handleReleaseTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service POST /tables/:id/release
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	tableData := NewTableDataAccess(p.tableClient)
+	var released *tableResource
+	var err error
+	switch table.Status {
+	case "available":
+		return tableCommandError(fmt.Sprintf("Table %s is already available", table.Number), "Table already available"), nil
+	case "out_of_service":
+		return tableCommandError(fmt.Sprintf("Table %s is blocked; use unblock-table instead", table.Number), "Table blocked"), nil
+	case "open":
+		released, err = tableData.CloseTable(ctx, table.ID)
+	case "clearing":
+		released, err = tableData.ReleaseTable(ctx, table.ID)
+	default:
+		released, err = tableData.UpdateTable(ctx, table.ID, map[string]interface{}{"status": "available"})
+	}
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot release table %s: %s", table.Number, serviceErrorMessage(err)), "Release failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	// Only closing a seated table can be reverted by seating the party again.
+	var effects map[string]string
+	if table.Status == "open" {
+		guests := table.GuestCount
+		if guests <= 0 {
+			guests = 1
+		}
+		effects = map[string]string{"table_id": table.ID, "guest_count": strconv.Itoa(guests)}
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Released Successfully</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> <span style="color: #10b981">Available</span></li>
-			<li><strong>Duration:</strong> 1h 15m</li>
-			<li><strong>Released At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
 		<p><em>Table ready for cleaning and next party</em></p>
-	`, tableID)
+	`, html.EscapeString(released.Number), tableStatusLabel(released))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s released", tableID),
+		Message: fmt.Sprintf("Table %s released", released.Number),
+		Effects: effects,
 	}, nil
 }
 
+// handleReserveTable holds a table from now on. Chat only captures a name, so
+// the reservation records where it came from in place of contact details.
 func (p *DeterministicParser) handleReserveTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	customerName := ""
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	if table.Status != "available" {
+		return tableCommandError(fmt.Sprintf("Table %s is %s", table.Number, strings.ToLower(humanizeStatus(table.Status))), "Table not available"), nil
+	}
+
+	customerName := "Guest"
 	if len(params) > 1 {
-		customerName = params[1]
+		customerName = titleWords(params[1:])
+	}
+	guests := table.Capacity
+	if guests <= 0 {
+		guests = 1
+	}
+
+	tableData := NewTableDataAccess(p.tableClient)
+	payload := map[string]interface{}{
+		"table_id":     table.ID,
+		"guest_count":  guests,
+		"reserved_for": time.Now().UTC(),
+		"contact_name": customerName,
+		"contact_info": "operations chat",
+	}
+	if _, err := tableData.CreateReservation(ctx, payload); err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot reserve table %s: %s", table.Number, serviceErrorMessage(err)), "Reservation failed"), nil
 	}
-	// TODO: Call table service POST /tables/:id/reserve
 
-	html := fmt.Sprintf(`
+	reserved, err := tableData.UpdateTable(ctx, table.ID, map[string]interface{}{"status": "reserved"})
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Reservation saved but table %s could not be marked reserved: %s", table.Number, serviceErrorMessage(err)), "Table update failed"), nil
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Reserved Successfully</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
+			<li><strong>Table:</strong> %s</li>
 			<li><strong>Customer:</strong> %s</li>
-			<li><strong>Status:</strong> <span style="color: #ef4444">Reserved</span></li>
-			<li><strong>Reserved At:</strong> Just now</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
-	`, tableID, customerName)
+	`, html.EscapeString(reserved.Number), html.EscapeString(customerName), tableStatusLabel(reserved))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s reserved", tableID),
+		Message: fmt.Sprintf("Table %s reserved", reserved.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleCancelReservation(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service POST /tables/:id/cancel-reservation
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	tableData := NewTableDataAccess(p.tableClient)
+	reservations, err := tableData.ListReservations(ctx)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Failed to fetch reservations: %s", serviceErrorMessage(err)), "Reservation fetch failed"), nil
+	}
+	reservation := nextReservationFor(reservations, table.ID)
+	if reservation == nil {
+		return tableCommandError(fmt.Sprintf("Table %s has no confirmed reservation", table.Number), "Reservation not found"), nil
+	}
+
+	if _, err := tableData.UpdateReservation(ctx, reservation.ID, map[string]interface{}{"status": "cancelled"}); err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot cancel reservation: %s", serviceErrorMessage(err)), "Cancellation failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	current := table
+	if table.Status == "reserved" {
+		if updated, err := tableData.UpdateTable(ctx, table.ID, map[string]interface{}{"status": "available"}); err == nil {
+			current = updated
+		}
+	}
+
+	out := fmt.Sprintf(`
 		<p>⚠️ <strong>Reservation Cancelled</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> <span style="color: #10b981">Available</span></li>
-			<li><strong>Cancelled At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Customer:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
-	`, tableID)
+	`, html.EscapeString(current.Number), html.EscapeString(reservation.ContactName), tableStatusLabel(current))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Reservation for table %s cancelled", tableID),
+		Message: fmt.Sprintf("Reservation for table %s cancelled", current.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleAssignWaiter(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	userID := params[1]
-	// TODO: Call table service POST /tables/:id/assign
-
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Waiter Assigned</strong></p>
-		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Waiter:</strong> User %s</li>
-			<li><strong>Assigned At:</strong> Just now</li>
-		</ul>
-	`, tableID, userID)
-
-	return &CommandResponse{
-		HTML:    html,
-		Success: true,
-		Message: "Waiter assigned successfully",
-	}, nil
+	return p.assignTableWaiter(ctx, params, "Waiter Assigned", "Waiter assigned successfully")
 }
 
 func (p *DeterministicParser) handleMarkTableClean(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service POST /tables/:id/clean
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	tableData := NewTableDataAccess(p.tableClient)
+	var cleaned *tableResource
+	var err error
+	switch table.Status {
+	case "cleaning":
+		cleaned, err = tableData.UpdateTable(ctx, table.ID, map[string]interface{}{"status": "available"})
+	case "clearing":
+		cleaned, err = tableData.ReleaseTable(ctx, table.ID)
+	default:
+		return tableCommandError(fmt.Sprintf("Table %s is %s, not waiting for cleaning", table.Number, strings.ToLower(humanizeStatus(table.Status))), "Table not dirty"), nil
+	}
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot update table %s: %s", table.Number, serviceErrorMessage(err)), "Table update failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Marked as Clean</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> Clean and ready</li>
-			<li><strong>Marked At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
 		<p><em>Table is ready for next party</em></p>
-	`, tableID)
+	`, html.EscapeString(cleaned.Number), tableStatusLabel(cleaned))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s marked as clean", tableID),
+		Message: fmt.Sprintf("Table %s marked as clean", cleaned.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleMarkTableDirty(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service POST /tables/:id/dirty
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	if table.Status == "open" {
+		return tableCommandError(fmt.Sprintf("Table %s still has a party seated; close its order first", table.Number), "Table occupied"), nil
+	}
+	if table.Status == "out_of_service" {
+		return tableCommandError(fmt.Sprintf("Table %s is blocked; use unblock-table first", table.Number), "Table blocked"), nil
+	}
+
+	dirty, err := NewTableDataAccess(p.tableClient).UpdateTable(ctx, table.ID, map[string]interface{}{"status": "cleaning"})
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot update table %s: %s", table.Number, serviceErrorMessage(err)), "Table update failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p>⚠️ <strong>Table Marked as Dirty</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> Needs cleaning</li>
-			<li><strong>Marked At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
-		<p><em>Housekeeping has been notified</em></p>
-	`, tableID)
+		<p><em>Use <code>clean table %s</code> once it is ready</em></p>
+	`, html.EscapeString(dirty.Number), tableStatusLabel(dirty), html.EscapeString(dirty.Number))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s marked as dirty", tableID),
+		Message: fmt.Sprintf("Table %s marked as dirty", dirty.Number),
 	}, nil
 }
 
 // ADDITIONAL TABLE COMMANDS FROM SPEC
 
 func (p *DeterministicParser) handleCreateTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	capacity := params[1]
-	// TODO: Call table service POST /tables
+	last := params[len(params)-1]
+	capacity, err := strconv.Atoi(last)
+	if err != nil || capacity <= 0 {
+		return tableCommandError(fmt.Sprintf("Invalid capacity %q", last), "Invalid capacity"), nil
+	}
+	number := tableNumberFromTokens(params[:len(params)-1])
 
-	html := fmt.Sprintf(`
+	table, err := NewTableDataAccess(p.tableClient).CreateTable(ctx, number, capacity)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot create table %s: %s", number, serviceErrorMessage(err)), "Table creation failed"), nil
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Created Successfully</strong></p>
 		<ul>
-			<li><strong>Table ID:</strong> Table %s</li>
-			<li><strong>Capacity:</strong> %s people</li>
-			<li><strong>Status:</strong> <span style="color: #10b981">Available</span></li>
-			<li><strong>Created At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Capacity:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
-	`, tableID, capacity)
+	`, html.EscapeString(table.Number), tableCapacityLabel(table), tableStatusLabel(table))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s created", tableID),
+		Message: fmt.Sprintf("Table %s created", table.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleDeleteTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service DELETE /tables/:id
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	if table.Status == "open" || table.Status == "clearing" {
+		return tableCommandError(fmt.Sprintf("Table %s is in use and cannot be deleted", table.Number), "Table in use"), nil
+	}
+
+	if err := NewTableDataAccess(p.tableClient).DeleteTable(ctx, table.ID); err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot delete table %s: %s", table.Number, serviceErrorMessage(err)), "Table deletion failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p>⚠️ <strong>Table Deleted</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> Removed from system</li>
+			<li><strong>Table:</strong> %s</li>
 		</ul>
-	`, tableID)
+	`, html.EscapeString(table.Number))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s deleted", tableID),
+		Message: fmt.Sprintf("Table %s deleted", table.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleUpdateTableCapacity(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	newCapacity := params[1]
-	// TODO: Call table service PATCH /tables/:id/capacity
+	capacity, err := strconv.Atoi(params[1])
+	if err != nil || capacity <= 0 {
+		return tableCommandError(fmt.Sprintf("Invalid capacity %q", params[1]), "Invalid capacity"), nil
+	}
 
-	html := fmt.Sprintf(`
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	updated, err := NewTableDataAccess(p.tableClient).SetCapacity(ctx, table.ID, capacity)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot update table %s: %s", table.Number, serviceErrorMessage(err)), "Table update failed"), nil
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Capacity Updated</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>New Capacity:</strong> %s people</li>
-			<li><strong>Updated At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Previous Capacity:</strong> %s</li>
+			<li><strong>New Capacity:</strong> %s</li>
 		</ul>
-	`, tableID, newCapacity)
+	`, html.EscapeString(updated.Number), tableCapacityLabel(table), tableCapacityLabel(updated))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Table capacity updated",
 	}, nil
 }
 
 func (p *DeterministicParser) handleRenameTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	newName := params[1]
-	// TODO: Call table service PATCH /tables/:id/name
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	number := tableNumberFromTokens(params[1:])
 
-	html := fmt.Sprintf(`
+	renamed, err := NewTableDataAccess(p.tableClient).UpdateTable(ctx, table.ID, map[string]interface{}{"number": number})
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot rename table %s: %s", table.Number, serviceErrorMessage(err)), "Table update failed"), nil
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Renamed</strong></p>
 		<ul>
-			<li><strong>Old ID:</strong> Table %s</li>
+			<li><strong>Old Name:</strong> %s</li>
 			<li><strong>New Name:</strong> %s</li>
-			<li><strong>Updated At:</strong> Just now</li>
 		</ul>
-	`, tableID, newName)
+	`, html.EscapeString(table.Number), html.EscapeString(renamed.Number))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Table renamed successfully",
 	}, nil
 }
 
 func (p *DeterministicParser) handleSetTableLocation(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	location := params[1]
-	// TODO: Call table service PATCH /tables/:id/location
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	location := titleWords(params[1:])
 
-	html := fmt.Sprintf(`
+	updated, err := NewTableDataAccess(p.tableClient).SetLocation(ctx, table.ID, location)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot update table %s: %s", table.Number, serviceErrorMessage(err)), "Table update failed"), nil
+	}
+
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Location Updated</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
+			<li><strong>Table:</strong> %s</li>
 			<li><strong>Location:</strong> %s</li>
-			<li><strong>Updated At:</strong> Just now</li>
 		</ul>
-	`, tableID, location)
+	`, html.EscapeString(updated.Number), html.EscapeString(orDash(updated.Location)))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
 		Message: "Table location updated",
 	}, nil
 }
 
+// handleMergeTables pushes the second table against the first, which seats
+// and bills the party.
 func (p *DeterministicParser) handleMergeTables(ctx context.Context, params []string) (*CommandResponse, error) {
-	table1 := params[0]
-	table2 := params[1]
-	// TODO: Call table service POST /tables/merge
+	host, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	table, errResp := p.lookupTable(ctx, params[1])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	merged, err := NewTableDataAccess(p.tableClient).MergeTable(ctx, table.ID, host.ID)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot merge table %s into %s: %s", table.Number, host.Number, serviceErrorMessage(err)), "Table merge failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Tables Merged</strong></p>
 		<ul>
-			<li><strong>Tables:</strong> Table %s + Table %s</li>
-			<li><strong>New Combined ID:</strong> Table %s-%s</li>
-			<li><strong>Combined Capacity:</strong> Calculating...</li>
+			<li><strong>Host Table:</strong> %s</li>
+			<li><strong>Merged Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
-	`, table1, table2, table1, table2)
+		<p><em>Seat and bill the party on table %s</em></p>
+	`, html.EscapeString(host.Number), html.EscapeString(merged.Number), tableStatusLabel(merged), html.EscapeString(host.Number))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Tables merged successfully",
+		Message: fmt.Sprintf("Table %s merged into %s", merged.Number, host.Number),
+		Effects: map[string]string{"table_id": merged.ID, "host_table_id": host.ID},
 	}, nil
 }
 
 func (p *DeterministicParser) handleUnmergeTables(ctx context.Context, params []string) (*CommandResponse, error) {
-	mergedTableID := params[0]
-	// TODO: Call table service POST /tables/:id/unmerge
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	unmerged, err := NewTableDataAccess(p.tableClient).UnmergeTable(ctx, table.ID)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot unmerge table %s: %s", table.Number, serviceErrorMessage(err)), "Table unmerge failed"), nil
+	}
 
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Tables Unmerged</strong></p>
+	out := fmt.Sprintf(`
+		<p>✅ <strong>Table Unmerged</strong></p>
 		<ul>
-			<li><strong>Merged Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> Restored to individual tables</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
-	`, mergedTableID)
+		<p><em>Table can now be seated on its own</em></p>
+	`, html.EscapeString(unmerged.Number), tableStatusLabel(unmerged))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Tables unmerged successfully",
+		Message: fmt.Sprintf("Table %s unmerged", unmerged.Number),
+		Effects: map[string]string{"table_id": unmerged.ID},
 	}, nil
 }
 
 func (p *DeterministicParser) handleBlockTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	reason := ""
-	if len(params) > 1 {
-		reason = params[1]
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
 	}
-	// TODO: Call table service POST /tables/:id/block
+	reason := strings.Join(params[1:], " ")
 
-	html := fmt.Sprintf(`
+	blocked, err := NewTableDataAccess(p.tableClient).BlockTable(ctx, table.ID, reason)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot block table %s: %s", table.Number, serviceErrorMessage(err)), "Table block failed"), nil
+	}
+
+	out := fmt.Sprintf(`
 		<p>⚠️ <strong>Table Blocked</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
+			<li><strong>Table:</strong> %s</li>
 			<li><strong>Reason:</strong> %s</li>
-			<li><strong>Status:</strong> <span style="color: #ef4444">Blocked</span></li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
 		<p><em>Table cannot be seated until unblocked</em></p>
-	`, tableID, reason)
+	`, html.EscapeString(blocked.Number), html.EscapeString(orDash(blocked.BlockReason)), tableStatusBadge(blocked.Status))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s blocked", tableID),
+		Message: fmt.Sprintf("Table %s blocked", blocked.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleUnblockTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	// TODO: Call table service POST /tables/:id/unblock
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	unblocked, err := NewTableDataAccess(p.tableClient).UnblockTable(ctx, table.ID)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot unblock table %s: %s", table.Number, serviceErrorMessage(err)), "Table unblock failed"), nil
+	}
 
-	html := fmt.Sprintf(`
+	out := fmt.Sprintf(`
 		<p>✅ <strong>Table Unblocked</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Status:</strong> <span style="color: #10b981">Available</span></li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Status:</strong> %s</li>
 		</ul>
 		<p><em>Table can now be seated</em></p>
-	`, tableID)
+	`, html.EscapeString(unblocked.Number), tableStatusLabel(unblocked))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s unblocked", tableID),
+		Message: fmt.Sprintf("Table %s unblocked", unblocked.Number),
 	}, nil
 }
 
 func (p *DeterministicParser) handleTransferTable(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	newWaiterID := params[1]
-	// TODO: Call table service POST /tables/:id/transfer
+	return p.assignTableWaiter(ctx, params, "Table Transferred", "Table transferred")
+}
+
+func (p *DeterministicParser) handleSetTableNote(ctx context.Context, params []string) (*CommandResponse, error) {
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
+	content := strings.Join(params[1:], " ")
 
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Table Transferred</strong></p>
+	createdBy := ""
+	if userID := getUserIDFromContext(ctx); userID != uuid.Nil {
+		createdBy = userID.String()
+	}
+
+	updated, err := NewTableDataAccess(p.tableClient).AddNote(ctx, table.ID, content, createdBy)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot add a note to table %s: %s", table.Number, serviceErrorMessage(err)), "Table note failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>📝 <strong>Table Note Added</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>New Waiter:</strong> User %s</li>
-			<li><strong>Transferred At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Note:</strong> %s</li>
 		</ul>
-	`, tableID, newWaiterID)
+	`, html.EscapeString(updated.Number), html.EscapeString(content))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: fmt.Sprintf("Table %s transferred", tableID),
+		Message: fmt.Sprintf("Note added to table %s", updated.Number),
 	}, nil
 }
 
-func (p *DeterministicParser) handleSetTableNote(ctx context.Context, params []string) (*CommandResponse, error) {
-	tableID := params[0]
-	note := params[1]
-	// TODO: Call table service POST /tables/:id/note
+// assignTableWaiter backs both assign-waiter and transfer-table; they differ
+// only in how the result is worded.
+func (p *DeterministicParser) assignTableWaiter(ctx context.Context, params []string, title, message string) (*CommandResponse, error) {
+	waiterID, err := parseWaiterRef(ctx, params[1:])
+	if err != nil {
+		return tableCommandError(err.Error(), "Invalid waiter"), nil
+	}
+
+	table, errResp := p.lookupTable(ctx, params[0])
+	if errResp != nil {
+		return errResp, nil
+	}
 
-	html := fmt.Sprintf(`
-		<p>✅ <strong>Note Added to Table</strong></p>
+	updated, err := NewTableDataAccess(p.tableClient).AssignWaiter(ctx, table.ID, &waiterID)
+	if err != nil {
+		return tableCommandError(fmt.Sprintf("Cannot assign waiter to table %s: %s", table.Number, serviceErrorMessage(err)), "Waiter assignment failed"), nil
+	}
+
+	out := fmt.Sprintf(`
+		<p>✅ <strong>%s</strong></p>
 		<ul>
-			<li><strong>Table:</strong> Table %s</li>
-			<li><strong>Note:</strong> %s</li>
-			<li><strong>Added At:</strong> Just now</li>
+			<li><strong>Table:</strong> %s</li>
+			<li><strong>Previous Waiter:</strong> %s</li>
+			<li><strong>Waiter:</strong> %s</li>
 		</ul>
-	`, tableID, note)
+	`, title, html.EscapeString(updated.Number), tableServerLabel(table), tableServerLabel(updated))
 
 	return &CommandResponse{
-		HTML:    html,
+		HTML:    out,
 		Success: true,
-		Message: "Note added to table",
+		Message: message,
 	}, nil
 }
+
+// ----- Helpers -----
+
+func (p *DeterministicParser) lookupTable(ctx context.Context, ref string) (*tableResource, *CommandResponse) {
+	table, err := NewTableDataAccess(p.tableClient).FindTable(ctx, ref)
+	if err != nil {
+		return nil, tableCommandError(serviceErrorMessage(err), "Table not found")
+	}
+	return table, nil
+}
+
+func renderTableList(title string, tables []tableResource, summary string) string {
+	if len(tables) == 0 {
+		return fmt.Sprintf(`<p><strong>%s:</strong></p><p><em>No tables found</em></p>`, html.EscapeString(title))
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Number < tables[j].Number
+	})
+
+	var rows strings.Builder
+	for _, table := range tables {
+		fmt.Fprintf(&rows, `
+				<tr>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+					<td>%s</td>
+				</tr>`, html.EscapeString(table.Number), tableCapacityLabel(&table), html.EscapeString(orDash(table.Location)),
+			tableStatusBadge(table.Status), partySizeLabel(table.GuestCount), tableServerLabel(&table))
+	}
+
+	return fmt.Sprintf(`
+		<p><strong>%s:</strong></p>
+		<table>
+			<thead>
+				<tr>
+					<th>Table #</th>
+					<th>Capacity</th>
+					<th>Location</th>
+					<th>Status</th>
+					<th>Party Size</th>
+					<th>Server</th>
+				</tr>
+			</thead>
+			<tbody>%s
+			</tbody>
+		</table>
+		<p><em>%s</em></p>
+	`, html.EscapeString(title), rows.String(), html.EscapeString(summary))
+}
+
+func filterTables(tables []tableResource, keep func(tableResource) bool) []tableResource {
+	filtered := make([]tableResource, 0, len(tables))
+	for _, table := range tables {
+		if keep(table) {
+			filtered = append(filtered, table)
+		}
+	}
+	return filtered
+}
+
+// nextReservationFor returns the earliest confirmed reservation for a table.
+func nextReservationFor(reservations []reservationResource, tableID string) *reservationResource {
+	var next *reservationResource
+	for i := range reservations {
+		reservation := &reservations[i]
+		if reservation.Status != "confirmed" || reservation.TableID == nil || *reservation.TableID != tableID {
+			continue
+		}
+		if next == nil || reservation.ReservedFor.Before(next.ReservedFor) {
+			next = reservation
+		}
+	}
+	return next
+}
+
+// parseWaiterRef resolves the waiter tokens of a command to a user ID. "me"
+// stands for the signed-in user.
+func parseWaiterRef(ctx context.Context, tokens []string) (string, error) {
+	if len(tokens) == 1 && tokens[0] == "me" {
+		userID := getUserIDFromContext(ctx)
+		if userID == uuid.Nil {
+			return "", fmt.Errorf("log in to assign yourself")
+		}
+		return userID.String(), nil
+	}
+
+	ref := strings.Join(tokens, "")
+	userID, err := uuid.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid waiter %q: use a user ID or \"me\"", strings.Join(tokens, "-"))
+	}
+	return userID.String(), nil
+}
+
+// tableNumberFromTokens rebuilds a table number that chat split on its
+// hyphens, so "patio 9" becomes "Patio-9".
+func tableNumberFromTokens(tokens []string) string {
+	parts := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			parts = append(parts, strings.ToUpper(token[:1])+token[1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func titleWords(tokens []string) string {
+	words := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			words = append(words, strings.ToUpper(token[:1])+token[1:])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func tableStatusBadge(status string) string {
+	color := "#6b7280"
+	switch status {
+	case "available":
+		color = "#10b981"
+	case "open":
+		color = "#f59e0b"
+	case "reserved", "out_of_service":
+		color = "#ef4444"
+	case "clearing", "cleaning":
+		color = "#3b82f6"
+	}
+	return fmt.Sprintf(`<span style="color: %s">%s</span>`, color, html.EscapeString(humanizeStatus(status)))
+}
+
+func tableStatusLabel(table *tableResource) string {
+	label := tableStatusBadge(table.Status)
+	if table.BlockReason != "" {
+		label += " (" + html.EscapeString(table.BlockReason) + ")"
+	}
+	return label
+}
+
+func tableCapacityLabel(table *tableResource) string {
+	if table.Capacity <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d people", table.Capacity)
+}
+
+func tableServerLabel(table *tableResource) string {
+	if table.AssignedTo == nil || *table.AssignedTo == "" {
+		return "-"
+	}
+	return html.EscapeString(truncateID(*table.AssignedTo))
+}
+
+func partySizeLabel(guests int) string {
+	if guests <= 0 {
+		return "-"
+	}
+	return strconv.Itoa(guests)
+}
+
+func orDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
+
+func tableCommandError(message, summary string) *CommandResponse {
+	return &CommandResponse{
+		HTML:    formatError(html.EscapeString(message)),
+		Success: false,
+		Message: summary,
+	}
+}
+
+// tableActivityLabel turns a history reason such as "table.note_added" into
+// "Note added".
+func tableActivityLabel(reason string) string {
+	label := strings.ReplaceAll(strings.TrimPrefix(reason, "table."), "_", " ")
+	if label == "" {
+		return "-"
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// inverseMergeTables unmerges the table a successful merge-tables merged.
+func inverseMergeTables(params []string, response *CommandResponse) *JournalCommand {
+	tableID := response.Effects["table_id"]
+	if tableID == "" {
+		return nil
+	}
+	return &JournalCommand{Command: "unmerge-tables", Params: []string{tableID}}
+}
+
+// inverseSeatParty releases the table a successful seat-party opened.
+func inverseSeatParty(params []string, response *CommandResponse) *JournalCommand {
+	tableID := response.Effects["table_id"]
+	if tableID == "" {
+		return nil
+	}
+	return &JournalCommand{Command: "release-table", Params: []string{tableID}}
+}
+
+// inverseReleaseTable seats the party again when release-table closed a
+// seated table. Releasing a table that was clearing or reserved is final.
+func inverseReleaseTable(params []string, response *CommandResponse) *JournalCommand {
+	tableID, guests := response.Effects["table_id"], response.Effects["guest_count"]
+	if tableID == "" || guests == "" {
+		return nil
+	}
+	return &JournalCommand{Command: "seat-party", Params: []string{tableID, guests}}
+}
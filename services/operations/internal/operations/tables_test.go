@@ -0,0 +1,152 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNextReservationFor(t *testing.T) {
+	tableID := "550e8400-e29b-41d4-a716-446655440601"
+	otherID := "550e8400-e29b-41d4-a716-446655440602"
+	now := time.Now()
+
+	reservations := []reservationResource{
+		{ID: "r-1", TableID: &tableID, Status: "confirmed", ReservedFor: now.Add(2 * time.Hour)},
+		{ID: "r-2", TableID: &tableID, Status: "confirmed", ReservedFor: now.Add(time.Hour)},
+		{ID: "r-3", TableID: &tableID, Status: "cancelled", ReservedFor: now},
+		{ID: "r-4", TableID: &otherID, Status: "confirmed", ReservedFor: now},
+		{ID: "r-5", Status: "confirmed", ReservedFor: now},
+	}
+
+	got := nextReservationFor(reservations, tableID)
+	if got == nil || got.ID != "r-2" {
+		t.Fatalf("nextReservationFor() = %v, want r-2", got)
+	}
+
+	if got := nextReservationFor(reservations, "550e8400-e29b-41d4-a716-446655440603"); got != nil {
+		t.Errorf("nextReservationFor() = %v, want nil", got)
+	}
+}
+
+func TestParseWaiterRef(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440604")
+	signedIn := context.WithValue(context.Background(), contextKeyUserID, userID)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		tokens  []string
+		want    string
+		wantErr bool
+	}{
+		{name: "me", ctx: signedIn, tokens: []string{"me"}, want: userID.String()},
+		{name: "meSignedOut", ctx: context.Background(), tokens: []string{"me"}, wantErr: true},
+		{name: "splitUUID", ctx: context.Background(), tokens: []string{"550e8400", "e29b", "41d4", "a716", "446655440604"}, want: userID.String()},
+		{name: "compactUUID", ctx: context.Background(), tokens: []string{"550e8400e29b41d4a716446655440604"}, want: userID.String()},
+		{name: "name", ctx: context.Background(), tokens: []string{"maria"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWaiterRef(tt.ctx, tt.tokens)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWaiterRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseWaiterRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableNumberFromTokens(t *testing.T) {
+	tests := []struct {
+		tokens []string
+		want   string
+	}{
+		{tokens: []string{"patio", "9"}, want: "Patio-9"},
+		{tokens: []string{"12"}, want: "12"},
+		{tokens: []string{"bar", " ", "3"}, want: "Bar-3"},
+	}
+
+	for _, tt := range tests {
+		if got := tableNumberFromTokens(tt.tokens); got != tt.want {
+			t.Errorf("tableNumberFromTokens(%v) = %q, want %q", tt.tokens, got, tt.want)
+		}
+	}
+}
+
+func TestTableStatusLabel(t *testing.T) {
+	blocked := &tableResource{Status: "out_of_service", BlockReason: "wobbly <leg>"}
+	got := tableStatusLabel(blocked)
+	want := `<span style="color: #ef4444">Out of Service</span> (wobbly &lt;leg&gt;)`
+	if got != want {
+		t.Errorf("tableStatusLabel() = %q, want %q", got, want)
+	}
+}
+
+func TestTableActivityLabel(t *testing.T) {
+	tests := map[string]string{
+		"table.note_added": "Note added",
+		"table.merged":     "Merged",
+		"":                 "-",
+	}
+
+	for reason, want := range tests {
+		if got := tableActivityLabel(reason); got != want {
+			t.Errorf("tableActivityLabel(%q) = %q, want %q", reason, got, want)
+		}
+	}
+}
+
+func TestInverseMergeTables(t *testing.T) {
+	got := inverseMergeTables([]string{"window1", "center2"}, &CommandResponse{Success: true, Effects: map[string]string{"table_id": "table-2"}})
+	if got == nil || got.Command != "unmerge-tables" || len(got.Params) != 1 || got.Params[0] != "table-2" {
+		t.Errorf("inverseMergeTables() = %+v, want unmerge-tables table-2", got)
+	}
+	if got := inverseMergeTables(nil, &CommandResponse{}); got != nil {
+		t.Errorf("inverseMergeTables() without effects = %+v, want nil", got)
+	}
+}
+
+func TestTableCommandsWithoutClients(t *testing.T) {
+	p := &DeterministicParser{}
+	ctx := context.Background()
+
+	handlers := map[string]struct {
+		handler CommandHandler
+		params  []string
+	}{
+		"listTables":     {handler: p.handleListTables},
+		"getTable":       {handler: p.handleGetTable, params: []string{"window1"}},
+		"reservations":   {handler: p.handleGetReservations},
+		"seatParty":      {handler: p.handleSeatParty, params: []string{"window1", "4"}},
+		"seatPartyBad":   {handler: p.handleSeatParty, params: []string{"window1", "four"}},
+		"releaseTable":   {handler: p.handleReleaseTable, params: []string{"window1"}},
+		"reserveTable":   {handler: p.handleReserveTable, params: []string{"window1", "smith"}},
+		"assignWaiter":   {handler: p.handleAssignWaiter, params: []string{"window1", "maria"}},
+		"createTable":    {handler: p.handleCreateTable, params: []string{"patio", "9", "4"}},
+		"createTableBad": {handler: p.handleCreateTable, params: []string{"patio", "9", "x"}},
+		"blockTable":     {handler: p.handleBlockTable, params: []string{"window1", "broken", "leg"}},
+		"setLocation":    {handler: p.handleSetTableLocation, params: []string{"window1", "terrace"}},
+		"merge":          {handler: p.handleMergeTables, params: []string{"window1", "center2"}},
+		"unmerge":        {handler: p.handleUnmergeTables, params: []string{"center2"}},
+		"setNote":        {handler: p.handleSetTableNote, params: []string{"window1", "birthday", "cake"}},
+		"history":        {handler: p.handleGetTableHistory, params: []string{"window1"}},
+	}
+
+	for name, tt := range handlers {
+		t.Run(name, func(t *testing.T) {
+			resp, err := tt.handler(ctx, tt.params)
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if resp.Success {
+				t.Error("table commands should not report success without a backing service")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package operations
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/appetiteclub/apt"
@@ -14,16 +15,41 @@ type tableResource struct {
 	Number      string             `json:"number"`
 	Status      string             `json:"status"`
 	GuestCount  int                `json:"guest_count"`
+	Capacity    int                `json:"capacity"`
+	Location    string             `json:"location"`
+	BlockReason string             `json:"block_reason"`
 	AssignedTo  *string            `json:"assigned_to"`
+	MergedInto  *string            `json:"merged_into"`
 	CreatedAt   time.Time          `json:"created_at"`
 	UpdatedAt   time.Time          `json:"updated_at"`
 	CurrentBill *tableBillResource `json:"current_bill"`
 }
 
+// tableActivityResource is an entry of a table history.
+type tableActivityResource struct {
+	At             time.Time `json:"at"`
+	Reason         string    `json:"reason"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status"`
+	Detail         string    `json:"detail"`
+}
+
 type tableBillResource struct {
 	Total float64 `json:"total"`
 }
 
+// reservationResource mirrors a reservation held by the table service.
+type reservationResource struct {
+	ID          string    `json:"id"`
+	TableID     *string   `json:"table_id"`
+	GuestCount  int       `json:"guest_count"`
+	ReservedFor time.Time `json:"reserved_for"`
+	ContactName string    `json:"contact_name"`
+	ContactInfo string    `json:"contact_info"`
+	Status      string    `json:"status"`
+	Notes       string    `json:"notes"`
+}
+
 // orderGroupResource represents table-level billing groups used by the order UI.
 
 // TableDataAccess centralizes decoding of table service responses.
@@ -70,3 +96,263 @@ func (da *TableDataAccess) GetTable(ctx context.Context, id string) (*tableResou
 
 	return &table, nil
 }
+
+func (da *TableDataAccess) CreateTable(ctx context.Context, number string, capacity int) (*tableResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("table client not configured")
+	}
+
+	payload := map[string]interface{}{
+		"number":   number,
+		"capacity": capacity,
+	}
+	resp, err := da.client.Create(ctx, "tables", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var table tableResource
+	if err := decodeSuccessResponse(resp, &table); err != nil {
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+// UpdateTable patches the table; only the fields present in payload change.
+func (da *TableDataAccess) UpdateTable(ctx context.Context, id string, payload map[string]interface{}) (*tableResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("table client not configured")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("missing table id")
+	}
+
+	resp, err := da.client.Update(ctx, "tables", id, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var table tableResource
+	if err := decodeSuccessResponse(resp, &table); err != nil {
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+func (da *TableDataAccess) DeleteTable(ctx context.Context, id string) error {
+	if da == nil || da.client == nil {
+		return fmt.Errorf("table client not configured")
+	}
+	if id == "" {
+		return fmt.Errorf("missing table id")
+	}
+
+	return da.client.Delete(ctx, "tables", id)
+}
+
+// OpenTable seats a party. The table service replaces the assigned waiter on
+// open, so callers pass the current one to keep it.
+func (da *TableDataAccess) OpenTable(ctx context.Context, id string, guestCount int, assignedTo *string) (*tableResource, error) {
+	payload := map[string]interface{}{"guest_count": guestCount}
+	if assignedTo != nil {
+		payload["assigned_to"] = *assignedTo
+	}
+	return da.tableAction(ctx, "POST", id, "open", payload)
+}
+
+func (da *TableDataAccess) CloseTable(ctx context.Context, id string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "close", nil)
+}
+
+func (da *TableDataAccess) SetClearing(ctx context.Context, id string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "clearing", nil)
+}
+
+func (da *TableDataAccess) ReleaseTable(ctx context.Context, id string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "release", nil)
+}
+
+// AssignWaiter sets the waiter serving the table; a nil waiterID unassigns it.
+func (da *TableDataAccess) AssignWaiter(ctx context.Context, id string, waiterID *string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "assign", map[string]interface{}{"assigned_to": waiterID})
+}
+
+func (da *TableDataAccess) BlockTable(ctx context.Context, id, reason string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "block", map[string]string{"reason": reason})
+}
+
+func (da *TableDataAccess) UnblockTable(ctx context.Context, id string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "unblock", nil)
+}
+
+func (da *TableDataAccess) SetCapacity(ctx context.Context, id string, capacity int) (*tableResource, error) {
+	return da.tableAction(ctx, "PUT", id, "capacity", map[string]int{"capacity": capacity})
+}
+
+func (da *TableDataAccess) SetLocation(ctx context.Context, id, location string) (*tableResource, error) {
+	return da.tableAction(ctx, "PUT", id, "location", map[string]string{"location": location})
+}
+
+// MergeTable pushes the table against host, which seats its guests.
+func (da *TableDataAccess) MergeTable(ctx context.Context, id, hostID string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "merge", map[string]string{"into": hostID})
+}
+
+func (da *TableDataAccess) UnmergeTable(ctx context.Context, id string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "unmerge", nil)
+}
+
+func (da *TableDataAccess) AddNote(ctx context.Context, id, content, createdBy string) (*tableResource, error) {
+	return da.tableAction(ctx, "POST", id, "notes", map[string]string{"content": content, "created_by": createdBy})
+}
+
+// GetHistory returns the recent activity of the table, newest first.
+func (da *TableDataAccess) GetHistory(ctx context.Context, id string) ([]tableActivityResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("table client not configured")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("missing table id")
+	}
+
+	resp, err := da.client.Request(ctx, "GET", fmt.Sprintf("/tables/%s/history", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []tableActivityResource
+	if err := decodeSuccessResponse(resp, &history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (da *TableDataAccess) tableAction(ctx context.Context, method, id, action string, body interface{}) (*tableResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("table client not configured")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("missing table id")
+	}
+
+	path := fmt.Sprintf("/tables/%s/%s", id, action)
+	resp, err := da.client.Request(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var table tableResource
+	if err := decodeSuccessResponse(resp, &table); err != nil {
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+func (da *TableDataAccess) ListReservations(ctx context.Context) ([]reservationResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("table client not configured")
+	}
+
+	resp, err := da.client.List(ctx, "reservations")
+	if err != nil {
+		return nil, err
+	}
+
+	var reservations []reservationResource
+	if err := decodeSuccessResponse(resp, &reservations); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+func (da *TableDataAccess) CreateReservation(ctx context.Context, payload map[string]interface{}) (*reservationResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("table client not configured")
+	}
+
+	resp, err := da.client.Create(ctx, "reservations", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation reservationResource
+	if err := decodeSuccessResponse(resp, &reservation); err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+func (da *TableDataAccess) UpdateReservation(ctx context.Context, id string, payload map[string]interface{}) (*reservationResource, error) {
+	if da == nil || da.client == nil {
+		return nil, fmt.Errorf("table client not configured")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("missing reservation id")
+	}
+
+	resp, err := da.client.Update(ctx, "reservations", id, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation reservationResource
+	if err := decodeSuccessResponse(resp, &reservation); err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// FindTable resolves a table typed in chat. See matchTableRef.
+func (da *TableDataAccess) FindTable(ctx context.Context, ref string) (*tableResource, error) {
+	tables, err := da.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return matchTableRef(tables, ref)
+}
+
+// matchTableRef finds a table by number, ignoring case and separators, so
+// "window1" matches "Window-1". A bare suffix such as "1" is accepted when
+// exactly one table ends with it.
+func matchTableRef(tables []tableResource, ref string) (*tableResource, error) {
+	key := compactRef(ref)
+	if key == "" {
+		return nil, fmt.Errorf("missing table reference")
+	}
+
+	var suffixMatches []int
+	for i := range tables {
+		number := compactRef(tables[i].Number)
+		if number == key || strings.EqualFold(tables[i].ID, ref) {
+			return &tables[i], nil
+		}
+		parts := strings.FieldsFunc(strings.ToLower(tables[i].Number), isRefSeparator)
+		if len(parts) > 1 && parts[len(parts)-1] == key {
+			suffixMatches = append(suffixMatches, i)
+		}
+	}
+
+	switch len(suffixMatches) {
+	case 0:
+		return nil, fmt.Errorf("table %q not found", ref)
+	case 1:
+		return &tables[suffixMatches[0]], nil
+	default:
+		return nil, fmt.Errorf("table %q is ambiguous", ref)
+	}
+}
+
+func compactRef(value string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(strings.TrimSpace(value)), isRefSeparator), "")
+}
+
+func isRefSeparator(r rune) bool {
+	return r == '-' || r == '_' || r == ' ' || r == '#'
+}
@@ -47,3 +47,72 @@ func TestTableDataAccessGetTableNilDA(t *testing.T) {
 		t.Error("GetTable() with nil DA should return error")
 	}
 }
+
+func TestMatchTableRef(t *testing.T) {
+	tables := []tableResource{
+		{ID: "t-1", Number: "Window-1"},
+		{ID: "t-2", Number: "Center-2"},
+		{ID: "t-3", Number: "Patio-3"},
+		{ID: "t-4", Number: "Bar-3"},
+		{ID: "t-5", Number: "12"},
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		wantID  string
+		wantErr bool
+	}{
+		{name: "compactNumber", ref: "window1", wantID: "t-1"},
+		{name: "caseInsensitive", ref: "CENTER-2", wantID: "t-2"},
+		{name: "plainNumber", ref: "12", wantID: "t-5"},
+		{name: "uniqueSuffix", ref: "2", wantID: "t-2"},
+		{name: "ambiguousSuffix", ref: "3", wantErr: true},
+		{name: "unknown", ref: "terrace9", wantErr: true},
+		{name: "empty", ref: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchTableRef(tables, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchTableRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.ID != tt.wantID {
+				t.Errorf("matchTableRef() = %s, want %s", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestTableDataAccessWriteMethodsNilClient(t *testing.T) {
+	da := &TableDataAccess{client: nil}
+	ctx := context.Background()
+	waiter := "550e8400-e29b-41d4-a716-446655440600"
+
+	calls := map[string]func() error{
+		"CreateTable":       func() error { _, err := da.CreateTable(ctx, "Patio-9", 4); return err },
+		"UpdateTable":       func() error { _, err := da.UpdateTable(ctx, "table-1", map[string]interface{}{}); return err },
+		"DeleteTable":       func() error { return da.DeleteTable(ctx, "table-1") },
+		"OpenTable":         func() error { _, err := da.OpenTable(ctx, "table-1", 2, nil); return err },
+		"CloseTable":        func() error { _, err := da.CloseTable(ctx, "table-1"); return err },
+		"SetClearing":       func() error { _, err := da.SetClearing(ctx, "table-1"); return err },
+		"ReleaseTable":      func() error { _, err := da.ReleaseTable(ctx, "table-1"); return err },
+		"AssignWaiter":      func() error { _, err := da.AssignWaiter(ctx, "table-1", &waiter); return err },
+		"BlockTable":        func() error { _, err := da.BlockTable(ctx, "table-1", "broken leg"); return err },
+		"UnblockTable":      func() error { _, err := da.UnblockTable(ctx, "table-1"); return err },
+		"SetCapacity":       func() error { _, err := da.SetCapacity(ctx, "table-1", 6); return err },
+		"SetLocation":       func() error { _, err := da.SetLocation(ctx, "table-1", "Patio"); return err },
+		"ListReservations":  func() error { _, err := da.ListReservations(ctx); return err },
+		"CreateReservation": func() error { _, err := da.CreateReservation(ctx, map[string]interface{}{}); return err },
+		"UpdateReservation": func() error { _, err := da.UpdateReservation(ctx, "res-1", map[string]interface{}{}); return err },
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); err == nil {
+				t.Errorf("%s() with nil client should return error", name)
+			}
+		})
+	}
+}
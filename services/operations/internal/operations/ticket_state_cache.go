@@ -110,20 +110,17 @@ func (c *TicketStateCache) warmFromHTTP(ctx context.Context) error {
 // applyEventLocked processes a single event and updates the cache.
 // Must be called with c.mu locked.
 func (c *TicketStateCache) applyEventLocked(ctx context.Context, data []byte) {
-	var baseEvent struct {
-		EventType string `json:"event_type"`
-	}
-
-	if err := json.Unmarshal(data, &baseEvent); err != nil {
+	env, err := event.Open(data)
+	if err != nil {
 		c.logger.Error("failed to unmarshal event type", "error", err)
 		return
 	}
 
-	switch baseEvent.EventType {
+	switch env.Type {
 	case event.EventKitchenTicketCreated:
-		c.handleTicketCreatedLocked(data)
+		c.handleTicketCreatedLocked(env.Payload)
 	case event.EventKitchenTicketStatusChange:
-		c.handleTicketStatusChangedLocked(data)
+		c.handleTicketStatusChangedLocked(env.Payload)
 	default:
 		// Silently ignore unknown event types (forward compatibility)
 		return
@@ -150,6 +147,10 @@ func (c *TicketStateCache) handleTicketCreatedLocked(data []byte) {
 		MenuItemName: evt.MenuItemName,
 		StationName:  evt.StationName,
 		TableNumber:  evt.TableNumber,
+		Modifiers:    evt.Modifiers,
+		PortionName:  evt.PortionName,
+		PrepTime:     evt.PrepTime,
+		Course:       evt.Course,
 		CreatedAt:    evt.OccurredAt,
 		UpdatedAt:    evt.OccurredAt,
 	}
@@ -183,6 +184,16 @@ func (c *TicketStateCache) handleTicketStatusChangedLocked(data []byte) {
 	// Update status and timestamps
 	ticket.Status = evt.NewStatus
 	ticket.Notes = evt.Notes
+	if len(evt.Modifiers) > 0 {
+		ticket.Modifiers = evt.Modifiers
+	}
+	if evt.PortionName != "" {
+		ticket.PortionName = evt.PortionName
+		ticket.PrepTime = evt.PrepTime
+	}
+	if evt.Course > 0 {
+		ticket.Course = evt.Course
+	}
 	ticket.UpdatedAt = evt.OccurredAt
 	ticket.StartedAt = evt.StartedAt
 	ticket.FinishedAt = evt.FinishedAt
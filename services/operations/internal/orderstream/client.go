@@ -6,12 +6,17 @@ import (
 	"sync"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg"
 	proto "github.com/appetiteclub/appetite/services/operations/internal/orderstream/proto"
 	"github.com/appetiteclub/apt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// snapshotEventType marks the current items Order sends when the stream
+// connects, as opposed to changes.
+const snapshotEventType = "order.item.snapshot"
+
 // Client manages connection to Order gRPC stream and broadcasts to SSE subscribers
 type Client struct {
 	addr   string
@@ -19,10 +24,16 @@ type Client struct {
 
 	mu          sync.RWMutex
 	subscribers map[string]chan *proto.OrderItemEvent
+	replay      *pkg.ReplayBuffer[*proto.OrderItemEvent]
 	conn        *grpc.ClientConn
 	stream      proto.OrderEventStream_StreamOrderItemEventsClient
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// Epoch and sequence of the last event received from Order, to resume
+	// from after a reconnect. Only touched by the connection goroutine.
+	upstreamInstance string
+	upstreamSequence uint64
 }
 
 // NewClient creates a new Order stream client
@@ -32,6 +43,7 @@ func NewClient(addr string, logger apt.Logger) *Client {
 		addr:        addr,
 		logger:      logger,
 		subscribers: make(map[string]chan *proto.OrderItemEvent),
+		replay:      pkg.NewReplayBuffer[*proto.OrderItemEvent](pkg.DefaultReplaySize),
 		ctx:         ctx,
 		cancel:      cancel,
 	}
@@ -76,10 +88,13 @@ func (c *Client) connectWithRetry() {
 		c.conn = conn
 		client := proto.NewOrderEventStreamClient(conn)
 
-		// Subscribe to all events (no filters)
+		// Subscribe to all events (no filters), resuming after the last
+		// event we got so a dropped connection does not lose any
 		req := &proto.SubscribeOrderItemEventsRequest{
-			TableId: "",
-			OrderId: "",
+			TableId:       "",
+			OrderId:       "",
+			AfterSequence: c.upstreamSequence,
+			Instance:      c.upstreamInstance,
 		}
 
 		stream, err := client.StreamOrderItemEvents(c.ctx, req)
@@ -118,15 +133,30 @@ func (c *Client) receiveEvents() {
 			return
 		}
 
+		c.upstreamInstance = evt.Instance
+		c.upstreamSequence = evt.Sequence
+
+		// Pages load the current items themselves, the snapshot Order sends
+		// on (re)connect would only make every browser refetch them
+		if evt.EventType == snapshotEventType {
+			continue
+		}
+
 		// Broadcast to all SSE subscribers
 		c.broadcastToSubscribers(evt)
 	}
 }
 
-// broadcastToSubscribers sends event to all SSE subscribers
+// broadcastToSubscribers sends event to all SSE subscribers. Events are
+// renumbered in this client's own sequence and epoch, which SSE clients
+// resume from: it keeps increasing when Order restarts and starts its
+// numbering over.
 func (c *Client) broadcastToSubscribers(evt *proto.OrderItemEvent) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evt.Sequence = c.replay.Append(evt)
+	evt.Instance = c.replay.Epoch()
 
 	for subscriberID, ch := range c.subscribers {
 		select {
@@ -139,8 +169,12 @@ func (c *Client) broadcastToSubscribers(evt *proto.OrderItemEvent) {
 	}
 }
 
-// Subscribe adds a new SSE subscriber and returns event channel
-func (c *Client) Subscribe(subscriberID string) <-chan *proto.OrderItemEvent {
+// Subscribe adds a new SSE subscriber and returns its event channel. missed
+// holds the buffered events after the given sequence of instance, oldest
+// first, for a subscriber resuming after a dropped connection; ok is false
+// when they are no longer buffered or instance is from before a restart.
+// last is the sequence of the newest event sent before the subscriber joined.
+func (c *Client) Subscribe(subscriberID string, instance string, after uint64) (events <-chan *proto.OrderItemEvent, missed []*proto.OrderItemEvent, last uint64, ok bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -149,7 +183,14 @@ func (c *Client) Subscribe(subscriberID string) <-chan *proto.OrderItemEvent {
 
 	c.logger.Info("new SSE subscriber for Order events", "subscriber_id", subscriberID, "total_subscribers", len(c.subscribers))
 
-	return ch
+	missed, ok = c.replay.Since(instance, after)
+	return ch, missed, c.replay.Last(), ok
+}
+
+// Instance returns the epoch of the sequences this client hands out. It
+// changes when operations restarts.
+func (c *Client) Instance() string {
+	return c.replay.Epoch()
 }
 
 // Unsubscribe removes an SSE subscriber
@@ -22,13 +22,25 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// Request to subscribe to order item events
+// Request to subscribe to order item events. Filters combine: an event is
+// sent when it matches all of the ones set.
 type SubscribeOrderItemEventsRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Optional filter by table_id
 	TableId string `protobuf:"bytes,1,opt,name=table_id,json=tableId,proto3" json:"table_id,omitempty"`
 	// Optional filter by order_id
-	OrderId       string `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	OrderId string `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// Resume after this sequence: the server replays the events sent since.
+	// 0, or a sequence the server no longer holds, starts over with the
+	// current items in scope.
+	AfterSequence uint64 `protobuf:"varint,3,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	// Optional filter by the waiter assigned to the item's table
+	WaiterId string `protobuf:"bytes,4,opt,name=waiter_id,json=waiterId,proto3" json:"waiter_id,omitempty"`
+	// Optional filter by item status, e.g. "ready" and "delivered"
+	Statuses []string `protobuf:"bytes,5,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	// Epoch the after_sequence was given in, from the events' instance. When it
+	// is not the server's, the server restarted and starts over.
+	Instance      string `protobuf:"bytes,6,opt,name=instance,proto3" json:"instance,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -77,10 +89,39 @@ func (x *SubscribeOrderItemEventsRequest) GetOrderId() string {
 	return ""
 }
 
+func (x *SubscribeOrderItemEventsRequest) GetAfterSequence() uint64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+func (x *SubscribeOrderItemEventsRequest) GetWaiterId() string {
+	if x != nil {
+		return x.WaiterId
+	}
+	return ""
+}
+
+func (x *SubscribeOrderItemEventsRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *SubscribeOrderItemEventsRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // Order item event streamed to clients
 type OrderItemEvent struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Event type: "order.item.status_changed", "order.item.delivered", etc.
+	// Items sent on subscribe, before any change, are "order.item.snapshot".
 	EventType string `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
 	// When the event occurred
 	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
@@ -100,7 +141,14 @@ type OrderItemEvent struct {
 	RequiresProduction bool    `protobuf:"varint,13,opt,name=requires_production,json=requiresProduction,proto3" json:"requires_production,omitempty"`
 	Notes              string  `protobuf:"bytes,14,opt,name=notes,proto3" json:"notes,omitempty"`
 	// Timestamps
-	DeliveredAt   *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	DeliveredAt *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	// Position of the event in the stream, increasing by one per event. The
+	// current items sent on subscribe carry the latest sequence so far.
+	Sequence uint64 `protobuf:"varint,16,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Table the item's order is for
+	TableId string `protobuf:"bytes,17,opt,name=table_id,json=tableId,proto3" json:"table_id,omitempty"`
+	// Epoch of the stream's sequences, new each time the server starts
+	Instance      string `protobuf:"bytes,18,opt,name=instance,proto3" json:"instance,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -240,14 +288,39 @@ func (x *OrderItemEvent) GetDeliveredAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *OrderItemEvent) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *OrderItemEvent) GetTableId() string {
+	if x != nil {
+		return x.TableId
+	}
+	return ""
+}
+
+func (x *OrderItemEvent) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 var File_internal_orderstream_proto_events_proto protoreflect.FileDescriptor
 
 const file_internal_orderstream_proto_events_proto_rawDesc = "" +
 	"\n" +
-	"'internal/orderstream/proto/events.proto\x12\x11appetite.order.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"W\n" +
+	"'internal/orderstream/proto/events.proto\x12\x11appetite.order.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd3\x01\n" +
 	"\x1fSubscribeOrderItemEventsRequest\x12\x19\n" +
 	"\btable_id\x18\x01 \x01(\tR\atableId\x12\x19\n" +
-	"\border_id\x18\x02 \x01(\tR\aorderId\"\xa9\x04\n" +
+	"\border_id\x18\x02 \x01(\tR\aorderId\x12%\n" +
+	"\x0eafter_sequence\x18\x03 \x01(\x04R\rafterSequence\x12\x1b\n" +
+	"\twaiter_id\x18\x04 \x01(\tR\bwaiterId\x12\x1a\n" +
+	"\bstatuses\x18\x05 \x03(\tR\bstatuses\x12\x1a\n" +
+	"\binstance\x18\x06 \x01(\tR\binstance\"\xfc\x04\n" +
 	"\x0eOrderItemEvent\x12\x1d\n" +
 	"\n" +
 	"event_type\x18\x01 \x01(\tR\teventType\x12;\n" +
@@ -268,7 +341,10 @@ const file_internal_orderstream_proto_events_proto_rawDesc = "" +
 	"\x05price\x18\f \x01(\x01R\x05price\x12/\n" +
 	"\x13requires_production\x18\r \x01(\bR\x12requiresProduction\x12\x14\n" +
 	"\x05notes\x18\x0e \x01(\tR\x05notes\x12=\n" +
-	"\fdelivered_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt2\x84\x01\n" +
+	"\fdelivered_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt\x12\x1a\n" +
+	"\bsequence\x18\x10 \x01(\x04R\bsequence\x12\x19\n" +
+	"\btable_id\x18\x11 \x01(\tR\atableId\x12\x1a\n" +
+	"\binstance\x18\x12 \x01(\tR\binstance2\x84\x01\n" +
 	"\x10OrderEventStream\x12p\n" +
 	"\x15StreamOrderItemEvents\x122.appetite.order.v1.SubscribeOrderItemEventsRequest\x1a!.appetite.order.v1.OrderItemEvent0\x01BYZWgithub.com/appetiteclub/appetite/services/operations/internal/orderstream/proto;orderpbb\x06proto3"
 
@@ -13,6 +13,7 @@ import (
 	aqmtemplate "github.com/appetiteclub/apt/template"
 
 	"github.com/appetiteclub/appetite/services/operations/internal/kitchenstream"
+	"github.com/appetiteclub/appetite/services/operations/internal/mongo"
 	"github.com/appetiteclub/appetite/services/operations/internal/operations"
 	"github.com/appetiteclub/appetite/services/operations/internal/orderstream"
 )
@@ -93,7 +94,22 @@ func main() {
 		DisableCORS: false, // Enable CORS for operations service
 	})
 
-	lifecycles := []interface{}{tmplMgr, kitchenStreamClient, orderStreamClient}
+	lifecycles := []interface{}{tmplMgr, handler.Stations(), kitchenStreamClient, orderStreamClient}
+
+	// Persist the chat command journal when configured, so history and undo
+	// survive restarts
+	if journalStore, _ := config.GetString("journal.store"); journalStore == "mongo" {
+		journalRepo := mongo.NewJournalStore(config, logger)
+		handler.SetJournalStore(journalRepo)
+		lifecycles = append(lifecycles, journalRepo)
+	}
+
+	// Persist the audit trail when configured, so managers can query it
+	if auditStore, _ := config.GetString("audit.store"); auditStore == "mongo" {
+		auditRepo := mongo.NewAuditStore(config, logger)
+		handler.SetAuditStore(auditRepo)
+		lifecycles = append(lifecycles, auditRepo)
+	}
 
 	options := []apt.Option{
 		apt.WithConfig(config),
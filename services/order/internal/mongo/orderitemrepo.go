@@ -60,6 +60,25 @@ func (r *OrderItemRepo) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*
 	return result, nil
 }
 
+func (r *OrderItemRepo) ListByOrders(ctx context.Context, orderIDs []uuid.UUID) ([]*order.OrderItem, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"order_id": bson.M{"$in": orderIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list order items by orders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []*order.OrderItem
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("cannot decode order items: %w", err)
+	}
+
+	return result, nil
+}
+
 func (r *OrderItemRepo) ListByGroup(ctx context.Context, groupID uuid.UUID) ([]*order.OrderItem, error) {
 	cursor, err := r.collection.Find(ctx, bson.M{"group_id": groupID})
 	if err != nil {
@@ -3,6 +3,7 @@ package mongo
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
@@ -75,6 +76,48 @@ func (r *OrderRepo) ListByStatus(ctx context.Context, status string) ([]*order.O
 	return result, nil
 }
 
+func (r *OrderRepo) ListOpen(ctx context.Context) ([]*order.Order, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"status": bson.M{"$nin": order.ClosedStatuses}})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list open orders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []*order.Order
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("cannot decode orders: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListByIDPrefix reads the IDs starting with prefix as a range, from the
+// prefix padded with zeros to the prefix padded with f, so the _id index
+// serves it.
+func (r *OrderRepo) ListByIDPrefix(ctx context.Context, prefix string) ([]*order.Order, error) {
+	low, err := uuid.Parse(prefix + strings.Repeat("0", 32-len(prefix)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id prefix %q: %w", prefix, err)
+	}
+	high, err := uuid.Parse(prefix + strings.Repeat("f", 32-len(prefix)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id prefix %q: %w", prefix, err)
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$gte": low, "$lte": high}})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list orders by id prefix: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []*order.Order
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("cannot decode orders: %w", err)
+	}
+
+	return result, nil
+}
+
 func (r *OrderRepo) List(ctx context.Context) ([]*order.Order, error) {
 	cursor, err := r.collection.Find(ctx, bson.M{})
 	if err != nil {
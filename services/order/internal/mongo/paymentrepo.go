@@ -0,0 +1,65 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/appetiteclub/appetite/services/order/internal/order"
+)
+
+type PaymentRepo struct {
+	collection *mongo.Collection
+}
+
+func NewPaymentRepo(db *mongo.Database) *PaymentRepo {
+	return &PaymentRepo{collection: db.Collection("payments")}
+}
+
+// EnsureIndexes creates the unique index that keeps one payment per
+// sequence on an order. Payments without a sequence are left out of it.
+func (r *PaymentRepo) EnsureIndexes(ctx context.Context) error {
+	model := mongo.IndexModel{
+		Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "sequence", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"sequence": bson.M{"$gt": 0}}),
+	}
+	if _, err := r.collection.Indexes().CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("cannot create payment indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentRepo) Create(ctx context.Context, payment *order.Payment) error {
+	if payment == nil {
+		return fmt.Errorf("payment is nil")
+	}
+	_, err := r.collection.InsertOne(ctx, payment)
+	if mongo.IsDuplicateKeyError(err) {
+		return order.ErrPaymentConflict
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create payment: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentRepo) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*order.Payment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"order_id": orderID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list payments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var payments []*order.Payment
+	if err := cursor.All(ctx, &payments); err != nil {
+		return nil, fmt.Errorf("cannot decode payments: %w", err)
+	}
+	return payments, nil
+}
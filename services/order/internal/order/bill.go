@@ -0,0 +1,240 @@
+package order
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
+)
+
+// Bill is computed on demand from the order items and the payments recorded
+// so far. It is never persisted.
+type Bill struct {
+	OrderID        uuid.UUID    `json:"order_id"`
+	TableID        uuid.UUID    `json:"table_id"`
+	Lines          []BillLine   `json:"lines"`
+	Subtotal       float64      `json:"subtotal"`
+	ServiceCharges []BillCharge `json:"service_charges,omitempty"`
+	ServiceCharge  float64      `json:"service_charge"`
+	Taxes          []BillCharge `json:"taxes,omitempty"`
+	Tax            float64      `json:"tax"`
+	AmountDue      float64      `json:"amount_due"`
+	Tip            float64      `json:"tip"`
+	Total          float64      `json:"total"`
+	Paid           float64      `json:"paid"`
+	Balance        float64      `json:"balance"`
+	Settled        bool         `json:"settled"`
+	Payments       []*Payment   `json:"payments"`
+}
+
+func (b *Bill) GetID() uuid.UUID {
+	return b.OrderID
+}
+
+func (b *Bill) ResourceType() string {
+	return "bill"
+}
+
+type BillLine struct {
+	OrderItemID uuid.UUID `json:"order_item_id"`
+	DishName    string    `json:"dish_name"`
+	Category    string    `json:"category"`
+	Quantity    int       `json:"quantity"`
+	UnitPrice   float64   `json:"unit_price"`
+	Share       float64   `json:"share,omitempty"`
+	Amount      float64   `json:"amount"`
+}
+
+type BillCharge struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+// TaxRule applies Rate to the lines whose category is listed in Categories.
+// An empty Categories list applies the rule to every line.
+type TaxRule struct {
+	Name       string
+	Rate       float64
+	Categories []string
+}
+
+func (t TaxRule) appliesTo(category string) bool {
+	if len(t.Categories) == 0 {
+		return true
+	}
+	for _, c := range t.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceChargeRule applies Rate to the bill subtotal. Service charges are
+// not taxed.
+type ServiceChargeRule struct {
+	Name string
+	Rate float64
+}
+
+type BillingPolicy struct {
+	TaxRules       []TaxRule
+	ServiceCharges []ServiceChargeRule
+}
+
+// NewBillingPolicy reads the billing section of the service configuration.
+// Rates are fractions (0.21 means 21%); a zero or missing rate disables the
+// corresponding rule.
+func NewBillingPolicy(config *apt.Config) BillingPolicy {
+	var policy BillingPolicy
+	if config == nil {
+		return policy
+	}
+
+	if rate := configRate(config, "billing.tax.rate"); rate > 0 {
+		policy.TaxRules = append(policy.TaxRules, TaxRule{
+			Name:       config.GetStringOrDef("billing.tax.name", "Tax"),
+			Rate:       rate,
+			Categories: splitList(config.GetStringOrDef("billing.tax.categories", "")),
+		})
+	}
+
+	if rate := configRate(config, "billing.service_charge.rate"); rate > 0 {
+		policy.ServiceCharges = append(policy.ServiceCharges, ServiceChargeRule{
+			Name: config.GetStringOrDef("billing.service_charge.name", "Service"),
+			Rate: rate,
+		})
+	}
+
+	return policy
+}
+
+// Compute builds the bill for an order. Cancelled items are excluded.
+func (p BillingPolicy) Compute(order *Order, items []*OrderItem, payments []*Payment) *Bill {
+	bill := &Bill{
+		Lines:    []BillLine{},
+		Payments: payments,
+	}
+	if order != nil {
+		bill.OrderID = order.ID
+		bill.TableID = order.TableID
+	}
+	if bill.Payments == nil {
+		bill.Payments = []*Payment{}
+	}
+
+	for _, item := range items {
+		if !billable(item) {
+			continue
+		}
+		line := billLineFor(item, 1, 0, true)
+		bill.Lines = append(bill.Lines, line)
+		bill.Subtotal += line.Amount
+	}
+	bill.Subtotal = roundMoney(bill.Subtotal)
+
+	bill.ServiceCharge, bill.ServiceCharges = p.serviceCharges(bill.Subtotal)
+	bill.Tax, bill.Taxes = p.taxes(bill.Lines)
+
+	for _, payment := range payments {
+		bill.Paid += payment.Amount
+		bill.Tip += payment.Tip
+	}
+
+	bill.Paid = roundMoney(bill.Paid)
+	bill.Tip = roundMoney(bill.Tip)
+	bill.AmountDue = roundMoney(bill.Subtotal + bill.ServiceCharge + bill.Tax)
+	bill.Total = roundMoney(bill.AmountDue + bill.Tip)
+	bill.Balance = roundMoney(bill.AmountDue - bill.Paid)
+	if bill.Balance < 0 {
+		bill.Balance = 0
+	}
+	bill.Settled = bill.Balance == 0
+
+	return bill
+}
+
+// billable reports whether item is charged on the bill. Cancelled items are
+// not; pending items are until the order is closed, which cancels them.
+func billable(item *OrderItem) bool {
+	return item != nil && item.Status != "cancelled"
+}
+
+// afterClose returns items as closing the order leaves them, with the
+// pending ones cancelled. The items passed in are not changed.
+func afterClose(items []*OrderItem) []*OrderItem {
+	closed := make([]*OrderItem, 0, len(items))
+	for _, item := range items {
+		if item != nil && item.Status == "pending" {
+			cancelled := *item
+			cancelled.Cancel()
+			item = &cancelled
+		}
+		closed = append(closed, item)
+	}
+	return closed
+}
+
+// serviceCharges applies every service charge rule to subtotal.
+func (p BillingPolicy) serviceCharges(subtotal float64) (float64, []BillCharge) {
+	var total float64
+	var charges []BillCharge
+	for _, rule := range p.ServiceCharges {
+		charge := BillCharge{Name: rule.Name, Rate: rule.Rate, Amount: roundMoney(subtotal * rule.Rate)}
+		charges = append(charges, charge)
+		total += charge.Amount
+	}
+	return roundMoney(total), charges
+}
+
+// taxes applies every tax rule to the lines in its categories.
+func (p BillingPolicy) taxes(lines []BillLine) (float64, []BillCharge) {
+	var total float64
+	var charges []BillCharge
+	for _, rule := range p.TaxRules {
+		var base float64
+		for _, line := range lines {
+			if rule.appliesTo(line.Category) {
+				base += line.Amount
+			}
+		}
+		charge := BillCharge{Name: rule.Name, Rate: rule.Rate, Amount: roundMoney(base * rule.Rate)}
+		charges = append(charges, charge)
+		total += charge.Amount
+	}
+	return roundMoney(total), charges
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func configRate(config *apt.Config, key string) float64 {
+	raw, _ := config.GetString(key)
+	return parseRate(raw)
+}
+
+func parseRate(raw string) float64 {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+	rate, err := strconv.ParseFloat(raw, 64)
+	if err != nil || rate < 0 {
+		return 0
+	}
+	return rate
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,403 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/outbox"
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Bill and payment handlers
+
+// maxPaymentAttempts is how many times a payment is checked against a fresh
+// bill when other payments keep landing on the order meanwhile.
+const maxPaymentAttempts = 3
+
+// errAmountExceedsBalance is returned by recordPayment when the payment is
+// for more than the bill still owes.
+var errAmountExceedsBalance = errors.New("amount exceeds outstanding balance")
+
+func (h *Handler) GetBill(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.GetBill")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	order, err := h.orderRepo.Get(ctx, id)
+	if err != nil {
+		log.Error("cannot load order for bill", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order")
+		return
+	}
+	if order == nil {
+		log.Debug("order not found for bill", "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	items, err := h.orderItemRepo.ListByOrder(ctx, id)
+	if err != nil {
+		log.Error("cannot list order items", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order items")
+		return
+	}
+
+	bill, err := h.computeBill(ctx, order, items)
+	if err != nil {
+		log.Error("cannot compute bill", "error", err, "order_id", id.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not compute bill")
+		return
+	}
+
+	apt.RespondSuccess(w, bill, apt.RESTfulLinksFor(bill)...)
+}
+
+func (h *Handler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.CreatePayment")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	orderIDStr := chi.URLParam(r, "orderID")
+	orderID, err := uuid.Parse(orderIDStr)
+	if err != nil {
+		log.Debug("invalid order ID", "order_id", orderIDStr)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if h.paymentRepo == nil {
+		apt.RespondError(w, http.StatusServiceUnavailable, "Payments are not available")
+		return
+	}
+
+	order, err := h.orderRepo.Get(ctx, orderID)
+	if err != nil {
+		log.Error("cannot load order for payment", "error", err, "order_id", orderID.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order")
+		return
+	}
+	if order == nil {
+		log.Debug("order not found for payment", "order_id", orderID.String())
+		apt.RespondError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	if order.Status == "closed" || order.Status == "cancelled" {
+		apt.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Order is %s", order.Status))
+		return
+	}
+
+	req, ok := h.decodePaymentCreatePayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	tender := strings.ToLower(strings.TrimSpace(req.Tender))
+	if !IsValidTender(tender) {
+		apt.RespondError(w, http.StatusBadRequest, "tender must be one of cash, card, other")
+		return
+	}
+	if req.Amount < 0 || req.Tip < 0 {
+		apt.RespondError(w, http.StatusBadRequest, "amount and tip cannot be negative")
+		return
+	}
+	if req.Amount == 0 && req.Tip == 0 {
+		apt.RespondError(w, http.StatusBadRequest, "amount or tip is required")
+		return
+	}
+
+	payment := NewPayment(orderID)
+	payment.Tender = tender
+	payment.Amount = roundMoney(req.Amount)
+	payment.Tip = roundMoney(req.Tip)
+	payment.Reference = strings.TrimSpace(req.Reference)
+	payment.BeforeCreate()
+
+	// Two payments taken at once are both checked against the same bill;
+	// the store keeps only the first, and the other is checked again
+	// against the balance it left
+	var bill *Bill
+	for attempt := 1; ; attempt++ {
+		bill, err = h.recordPayment(ctx, order, payment)
+		if !errors.Is(err, ErrPaymentConflict) || attempt == maxPaymentAttempts {
+			break
+		}
+		log.Info("order was paid meanwhile, checking payment again", "order_id", orderID.String(), "attempt", attempt)
+	}
+	switch {
+	case errors.Is(err, errAmountExceedsBalance):
+		apt.RespondError(w, http.StatusBadRequest, fmt.Sprintf("amount exceeds outstanding balance of %.2f", bill.Balance))
+		return
+	case errors.Is(err, ErrPaymentConflict):
+		apt.RespondError(w, http.StatusConflict, "Order is being paid by someone else, try again")
+		return
+	case err != nil:
+		log.Error("cannot create payment", "error", err, "order_id", orderID.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not record payment")
+		return
+	}
+
+	log.Info("payment recorded", "order_id", orderID.String(), "tender", tender, "amount", payment.Amount, "tip", payment.Tip, "balance", bill.Balance)
+
+	links := apt.RESTfulLinksFor(payment)
+	w.WriteHeader(http.StatusCreated)
+	apt.RespondSuccess(w, payment, links...)
+}
+
+func (h *Handler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListPayments")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	orderIDStr := chi.URLParam(r, "orderID")
+	orderID, err := uuid.Parse(orderIDStr)
+	if err != nil {
+		log.Debug("invalid order ID", "order_id", orderIDStr)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	payments := []*Payment{}
+	if h.paymentRepo != nil {
+		payments, err = h.paymentRepo.ListByOrder(ctx, orderID)
+		if err != nil {
+			log.Error("cannot list payments", "error", err, "order_id", orderID.String())
+			apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve payments")
+			return
+		}
+	}
+
+	apt.RespondCollection(w, payments, "payment")
+}
+
+// recordPayment checks payment against the order's bill as it is now and
+// saves it as the next payment of the order. It returns the bill with the
+// payment, or ErrPaymentConflict when another payment took that place first.
+func (h *Handler) recordPayment(ctx context.Context, order *Order, payment *Payment) (*Bill, error) {
+	items, err := h.orderItemRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list order items: %w", err)
+	}
+
+	bill, err := h.computeBill(ctx, order, items)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute bill: %w", err)
+	}
+	if payment.Amount > bill.Balance {
+		return bill, errAmountExceedsBalance
+	}
+	payment.Sequence = len(bill.Payments) + 1
+
+	// The payment that settles the bill is saved with the event saying so
+	wasSettled := bill.Settled
+	bill = h.billing.Compute(order, items, append(bill.Payments, payment))
+	err = outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.paymentRepo.Create(ctx, payment); err != nil {
+			return err
+		}
+		if bill.Settled && !wasSettled {
+			return h.publishBillSettled(ctx, bill)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+// computeBill applies the billing policy to items and the payments recorded
+// for the order.
+func (h *Handler) computeBill(ctx context.Context, order *Order, items []*OrderItem) (*Bill, error) {
+	var payments []*Payment
+	if h.paymentRepo != nil {
+		var err error
+		payments, err = h.paymentRepo.ListByOrder(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.billing.Compute(order, items, payments), nil
+}
+
+func (h *Handler) publishBillSettled(ctx context.Context, bill *Bill) error {
+	if h.publisher == nil {
+		return nil
+	}
+	evt := pkg.OrderBillSettledEvent{
+		EventID:       event.NewID(),
+		EventType:     pkg.EventOrderBillSettled,
+		TableID:       bill.TableID.String(),
+		OrderID:       bill.OrderID.String(),
+		Subtotal:      bill.Subtotal,
+		ServiceCharge: bill.ServiceCharge,
+		Tax:           bill.Tax,
+		Tip:           bill.Tip,
+		Total:         bill.Total,
+		Paid:          bill.Paid,
+		OccurredAt:    time.Now().UTC(),
+	}
+	payload, err := event.Marshal(ctx, eventSource, evt)
+	if err != nil {
+		return fmt.Errorf("cannot marshal bill settled event: %w", err)
+	}
+	if err := h.publisher.Publish(ctx, pkg.OrderTableTopic, payload); err != nil {
+		return fmt.Errorf("cannot publish bill settled event: %w", err)
+	}
+	return nil
+}
+
+type PaymentCreateRequest struct {
+	Tender    string  `json:"tender"`
+	Amount    float64 `json:"amount"`
+	Tip       float64 `json:"tip"`
+	Reference string  `json:"reference,omitempty"`
+}
+
+func (h *Handler) decodePaymentCreatePayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (PaymentCreateRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("failed to read request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Failed to read request body")
+		return PaymentCreateRequest{}, false
+	}
+
+	var req PaymentCreateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("failed to decode request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON in request body")
+		return PaymentCreateRequest{}, false
+	}
+
+	return req, true
+}
+
+// SplitOrder divides the order bill into checks. The strategy is "group" (one
+// check per order group), "items" (explicit item shares) or "even" (N equal
+// checks).
+func (h *Handler) SplitOrder(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.SplitOrder")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	orderIDStr := chi.URLParam(r, "orderID")
+	orderID, err := uuid.Parse(orderIDStr)
+	if err != nil {
+		log.Debug("invalid order ID", "order_id", orderIDStr)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	order, err := h.orderRepo.Get(ctx, orderID)
+	if err != nil || order == nil {
+		log.Debug("order not found for split", "order_id", orderID.String())
+		apt.RespondError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	req, ok := h.decodeSplitPayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	items, err := h.orderItemRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		log.Error("cannot list order items", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order items")
+		return
+	}
+
+	strategy := strings.ToLower(strings.TrimSpace(req.Strategy))
+	var checks []*Check
+	switch strategy {
+	case SplitByGroup:
+		groups, err := h.orderGroupRepo.ListByOrder(ctx, orderID)
+		if err != nil {
+			log.Error("cannot list order groups", "error", err, "order_id", orderID.String())
+			apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order groups")
+			return
+		}
+		checks = h.billing.SplitByGroups(items, groups)
+	case SplitByItems:
+		if len(req.Checks) == 0 {
+			apt.RespondError(w, http.StatusBadRequest, "checks are required for an item split")
+			return
+		}
+		checks, err = h.billing.SplitByItems(items, req.Checks)
+	case SplitEvenly:
+		checks, err = h.billing.SplitEvenly(order, items, req.Ways)
+	default:
+		apt.RespondError(w, http.StatusBadRequest, "strategy must be one of group, items, even")
+		return
+	}
+	if err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var amountDue float64
+	for _, check := range checks {
+		amountDue += check.AmountDue
+	}
+
+	response := map[string]interface{}{
+		"order_id":   orderID,
+		"strategy":   strategy,
+		"checks":     checks,
+		"amount_due": roundMoney(amountDue),
+	}
+	apt.RespondSuccess(w, response)
+}
+
+type SplitRequest struct {
+	Strategy string            `json:"strategy"`
+	Ways     int               `json:"ways,omitempty"`
+	Checks   []CheckAssignment `json:"checks,omitempty"`
+}
+
+func (h *Handler) decodeSplitPayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (SplitRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("failed to read request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Failed to read request body")
+		return SplitRequest{}, false
+	}
+
+	var req SplitRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("failed to decode request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON in request body")
+		return SplitRequest{}, false
+	}
+
+	return req, true
+}
@@ -0,0 +1,181 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
+)
+
+func TestNewBillingPolicy(t *testing.T) {
+	for name, config := range map[string]*apt.Config{"nilConfig": nil, "emptyConfig": apt.NewConfig()} {
+		t.Run(name, func(t *testing.T) {
+			policy := NewBillingPolicy(config)
+			if len(policy.TaxRules) != 0 || len(policy.ServiceCharges) != 0 {
+				t.Errorf("NewBillingPolicy() = %+v, want no rules", policy)
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want float64
+	}{
+		{raw: "", want: 0},
+		{raw: "0.21", want: 0.21},
+		{raw: " 0.1 ", want: 0.1},
+		{raw: "abc", want: 0},
+		{raw: "-1", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRate(tt.raw); got != tt.want {
+			t.Errorf("parseRate(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := splitList("drinks, desserts ,,")
+	if len(got) != 2 || got[0] != "drinks" || got[1] != "desserts" {
+		t.Errorf("splitList() = %v, want [drinks desserts]", got)
+	}
+	if got := splitList(""); len(got) != 0 {
+		t.Errorf("splitList(\"\") = %v, want empty", got)
+	}
+}
+
+func TestBillingPolicyCompute(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440300")
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440301")
+	order := &Order{ID: orderID, TableID: tableID, Status: "pending"}
+
+	items := []*OrderItem{
+		{ID: uuid.New(), OrderID: orderID, DishName: "Steak", Category: "mains", Quantity: 2, Price: 15.50, Status: "delivered"},
+		{ID: uuid.New(), OrderID: orderID, DishName: "Wine", Category: "drinks", Quantity: 1, Price: 8.00, Status: "ready"},
+		{ID: uuid.New(), OrderID: orderID, DishName: "Soup", Category: "starters", Quantity: 1, Price: 6.00, Status: "cancelled"},
+	}
+
+	tests := []struct {
+		name          string
+		policy        BillingPolicy
+		payments      []*Payment
+		wantSubtotal  float64
+		wantService   float64
+		wantTax       float64
+		wantAmountDue float64
+		wantTotal     float64
+		wantBalance   float64
+		wantSettled   bool
+	}{
+		{
+			name:          "noRulesNoPayments",
+			wantSubtotal:  39.00,
+			wantAmountDue: 39.00,
+			wantTotal:     39.00,
+			wantBalance:   39.00,
+		},
+		{
+			name:          "taxOnCategory",
+			policy:        BillingPolicy{TaxRules: []TaxRule{{Name: "Alcohol", Rate: 0.10, Categories: []string{"Drinks"}}}},
+			wantSubtotal:  39.00,
+			wantTax:       0.80,
+			wantAmountDue: 39.80,
+			wantTotal:     39.80,
+			wantBalance:   39.80,
+		},
+		{
+			name: "serviceChargeAndTax",
+			policy: BillingPolicy{
+				TaxRules:       []TaxRule{{Name: "VAT", Rate: 0.21}},
+				ServiceCharges: []ServiceChargeRule{{Name: "Service", Rate: 0.10}},
+			},
+			wantSubtotal:  39.00,
+			wantService:   3.90,
+			wantTax:       8.19,
+			wantAmountDue: 51.09,
+			wantTotal:     51.09,
+			wantBalance:   51.09,
+		},
+		{
+			name: "partialPaymentWithTip",
+			payments: []*Payment{
+				{OrderID: orderID, Tender: TenderCard, Amount: 20, Tip: 3},
+			},
+			wantSubtotal:  39.00,
+			wantAmountDue: 39.00,
+			wantTotal:     42.00,
+			wantBalance:   19.00,
+		},
+		{
+			name: "settled",
+			payments: []*Payment{
+				{OrderID: orderID, Tender: TenderCard, Amount: 20},
+				{OrderID: orderID, Tender: TenderCash, Amount: 19, Tip: 2},
+			},
+			wantSubtotal:  39.00,
+			wantAmountDue: 39.00,
+			wantTotal:     41.00,
+			wantBalance:   0,
+			wantSettled:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bill := tt.policy.Compute(order, items, tt.payments)
+
+			if bill.OrderID != orderID || bill.TableID != tableID {
+				t.Errorf("bill ids = %s/%s, want %s/%s", bill.OrderID, bill.TableID, orderID, tableID)
+			}
+			if len(bill.Lines) != 2 {
+				t.Errorf("Lines = %d, want 2 (cancelled excluded)", len(bill.Lines))
+			}
+			if bill.Subtotal != tt.wantSubtotal {
+				t.Errorf("Subtotal = %v, want %v", bill.Subtotal, tt.wantSubtotal)
+			}
+			if bill.ServiceCharge != tt.wantService {
+				t.Errorf("ServiceCharge = %v, want %v", bill.ServiceCharge, tt.wantService)
+			}
+			if bill.Tax != tt.wantTax {
+				t.Errorf("Tax = %v, want %v", bill.Tax, tt.wantTax)
+			}
+			if bill.AmountDue != tt.wantAmountDue {
+				t.Errorf("AmountDue = %v, want %v", bill.AmountDue, tt.wantAmountDue)
+			}
+			if bill.Total != tt.wantTotal {
+				t.Errorf("Total = %v, want %v", bill.Total, tt.wantTotal)
+			}
+			if bill.Balance != tt.wantBalance {
+				t.Errorf("Balance = %v, want %v", bill.Balance, tt.wantBalance)
+			}
+			if bill.Settled != tt.wantSettled {
+				t.Errorf("Settled = %v, want %v", bill.Settled, tt.wantSettled)
+			}
+		})
+	}
+}
+
+func TestBillingPolicyComputeEmptyOrder(t *testing.T) {
+	bill := BillingPolicy{}.Compute(&Order{ID: uuid.New()}, nil, nil)
+
+	if !bill.Settled {
+		t.Error("empty bill should be settled")
+	}
+	if bill.Lines == nil || bill.Payments == nil {
+		t.Error("Lines and Payments should be empty slices, not nil")
+	}
+}
+
+func TestIsValidTender(t *testing.T) {
+	for _, tender := range []string{TenderCash, TenderCard, TenderOther} {
+		if !IsValidTender(tender) {
+			t.Errorf("IsValidTender(%q) = false, want true", tender)
+		}
+	}
+	if IsValidTender("voucher") {
+		t.Error("IsValidTender(voucher) = true, want false")
+	}
+}
@@ -0,0 +1,420 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestHandlerGetBill(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440310")
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440311")
+
+	orderRepo := NewMockOrderRepo()
+	itemRepo := NewMockOrderItemRepo()
+	paymentRepo := NewMockPaymentRepo()
+
+	orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+	itemRepo.items[uuid.New()] = &OrderItem{OrderID: orderID, Quantity: 2, Price: 10, Status: "delivered"}
+	itemRepo.items[uuid.New()] = &OrderItem{OrderID: orderID, Quantity: 1, Price: 5, Status: "cancelled"}
+	paymentRepo.payments = append(paymentRepo.payments, &Payment{OrderID: orderID, Tender: TenderCash, Amount: 5, Tip: 1})
+
+	h := NewHandler(HandlerDeps{
+		Repos: Repos{OrderRepo: orderRepo, OrderItemRepo: itemRepo, PaymentRepo: paymentRepo},
+	}, apt.NewConfig(), nil)
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+	}{
+		{name: "validOrder", id: orderID.String(), expectedStatus: http.StatusOK},
+		{name: "orderNotFound", id: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalidID", id: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders/"+tt.id+"/bill", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.GetBill(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("GetBill() status = %d, want %d, body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data Bill `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("cannot decode response: %v", err)
+			}
+			if resp.Data.Subtotal != 20 || resp.Data.Paid != 5 || resp.Data.Tip != 1 || resp.Data.Balance != 15 {
+				t.Errorf("GetBill() = %+v, want subtotal 20, paid 5, tip 1, balance 15", resp.Data)
+			}
+		})
+	}
+}
+
+func TestHandlerCreatePayment(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440320")
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440321")
+
+	tests := []struct {
+		name           string
+		orderID        string
+		orderStatus    string
+		body           interface{}
+		getErr         error
+		createErr      error
+		expectedStatus int
+		expectSettled  bool
+	}{
+		{
+			name:           "partialCardPayment",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "card", Amount: 10},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "settlingCashPaymentWithTip",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "Cash", Amount: 25, Tip: 3},
+			expectedStatus: http.StatusCreated,
+			expectSettled:  true,
+		},
+		{
+			name:           "amountExceedsBalance",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "card", Amount: 30},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalidTender",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "voucher", Amount: 10},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negativeAmount",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "card", Amount: -1},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "zeroAmountAndTip",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "card"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "closedOrder",
+			orderID:        orderID.String(),
+			orderStatus:    "closed",
+			body:           PaymentCreateRequest{Tender: "card", Amount: 10},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "orderNotFound",
+			orderID:        uuid.New().String(),
+			body:           PaymentCreateRequest{Tender: "card", Amount: 10},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "orderRepoError",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "card", Amount: 10},
+			getErr:         fmt.Errorf("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalidOrderID",
+			orderID:        "not-a-uuid",
+			body:           PaymentCreateRequest{Tender: "card", Amount: 10},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalidJSON",
+			orderID:        orderID.String(),
+			body:           "not json",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repoError",
+			orderID:        orderID.String(),
+			body:           PaymentCreateRequest{Tender: "card", Amount: 10},
+			createErr:      fmt.Errorf("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepo := NewMockOrderRepo()
+			itemRepo := NewMockOrderItemRepo()
+			paymentRepo := NewMockPaymentRepo()
+
+			status := tt.orderStatus
+			if status == "" {
+				status = "pending"
+			}
+			orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: status}
+			itemRepo.items[uuid.New()] = &OrderItem{OrderID: orderID, Quantity: 1, Price: 25, Status: "delivered"}
+			if tt.getErr != nil {
+				orderRepo.GetFunc = func(ctx context.Context, id uuid.UUID) (*Order, error) {
+					return nil, tt.getErr
+				}
+			}
+			if tt.createErr != nil {
+				paymentRepo.CreateFunc = func(ctx context.Context, payment *Payment) error {
+					return tt.createErr
+				}
+			}
+
+			var published []pkg.OrderBillSettledEvent
+			publisher := NewMockPublisher()
+			publisher.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
+				var event pkg.OrderBillSettledEvent
+				if err := unmarshalEvent(msg, &event); err == nil && topic == pkg.OrderTableTopic {
+					published = append(published, event)
+				}
+				return nil
+			}
+
+			h := NewHandler(HandlerDeps{
+				Repos:     Repos{OrderRepo: orderRepo, OrderItemRepo: itemRepo, PaymentRepo: paymentRepo},
+				Publisher: publisher,
+			}, apt.NewConfig(), nil)
+
+			var body []byte
+			if s, ok := tt.body.(string); ok {
+				body = []byte(s)
+			} else {
+				body, _ = json.Marshal(tt.body)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/"+tt.orderID+"/payments", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("orderID", tt.orderID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.CreatePayment(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("CreatePayment() status = %d, want %d, body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+
+			if tt.expectSettled {
+				if len(published) != 1 {
+					t.Fatalf("published %d bill settled events, want 1", len(published))
+				}
+				event := published[0]
+				if event.EventType != pkg.EventOrderBillSettled || event.TableID != tableID.String() || event.Tip != 3 || event.Total != 28 {
+					t.Errorf("bill settled event = %+v", event)
+				}
+			} else if len(published) != 0 {
+				t.Errorf("published %d bill settled events, want 0", len(published))
+			}
+		})
+	}
+}
+
+func TestHandlerGetBillRepoError(t *testing.T) {
+	orderRepo := NewMockOrderRepo()
+	orderRepo.GetFunc = func(ctx context.Context, id uuid.UUID) (*Order, error) {
+		return nil, fmt.Errorf("db error")
+	}
+	h := NewHandler(HandlerDeps{
+		Repos: Repos{OrderRepo: orderRepo, OrderItemRepo: NewMockOrderItemRepo(), PaymentRepo: NewMockPaymentRepo()},
+	}, apt.NewConfig(), nil)
+
+	id := uuid.New().String()
+	req := httptest.NewRequest(http.MethodGet, "/orders/"+id+"/bill", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	w := httptest.NewRecorder()
+	h.GetBill(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("GetBill() status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestHandlerCreatePaymentRechecksConcurrentPayment(t *testing.T) {
+	orderID := uuid.New()
+	orderRepo := NewMockOrderRepo()
+	itemRepo := NewMockOrderItemRepo()
+	paymentRepo := NewMockPaymentRepo()
+
+	orderRepo.orders[orderID] = &Order{ID: orderID, TableID: uuid.New(), Status: "pending"}
+	itemRepo.items[uuid.New()] = &OrderItem{OrderID: orderID, Quantity: 1, Price: 25, Status: "delivered"}
+
+	// Another till takes 20 after this request read the bill
+	other := &Payment{ID: uuid.New(), OrderID: orderID, Tender: TenderCash, Amount: 20, Sequence: 1}
+	reads := 0
+	paymentRepo.ListByOrderFunc = func(ctx context.Context, id uuid.UUID) ([]*Payment, error) {
+		reads++
+		if reads == 1 {
+			paymentRepo.payments = append(paymentRepo.payments, other)
+			return nil, nil
+		}
+		return []*Payment{other}, nil
+	}
+
+	h := NewHandler(HandlerDeps{
+		Repos:     Repos{OrderRepo: orderRepo, OrderItemRepo: itemRepo, PaymentRepo: paymentRepo},
+		Publisher: NewMockPublisher(),
+	}, apt.NewConfig(), nil)
+
+	body, _ := json.Marshal(PaymentCreateRequest{Tender: "card", Amount: 10})
+	req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID.String()+"/payments", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orderID", orderID.String())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	w := httptest.NewRecorder()
+	h.CreatePayment(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("CreatePayment() status = %d, want %d, body: %s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+	if len(paymentRepo.payments) != 1 {
+		t.Errorf("recorded %d payments, want only the other till's", len(paymentRepo.payments))
+	}
+}
+
+func TestHandlerListPayments(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440330")
+
+	tests := []struct {
+		name           string
+		orderID        string
+		listErr        error
+		expectedStatus int
+	}{
+		{name: "validOrder", orderID: orderID.String(), expectedStatus: http.StatusOK},
+		{name: "invalidOrderID", orderID: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "repoError", orderID: orderID.String(), listErr: fmt.Errorf("db error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := NewMockPaymentRepo()
+			paymentRepo.payments = append(paymentRepo.payments, &Payment{ID: uuid.New(), OrderID: orderID, Tender: TenderCard, Amount: 10})
+			if tt.listErr != nil {
+				paymentRepo.ListByOrderFunc = func(ctx context.Context, id uuid.UUID) ([]*Payment, error) {
+					return nil, tt.listErr
+				}
+			}
+
+			h := NewHandler(HandlerDeps{Repos: Repos{PaymentRepo: paymentRepo}}, apt.NewConfig(), nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/"+tt.orderID+"/payments", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("orderID", tt.orderID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.ListPayments(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("ListPayments() status = %d, want %d, body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerCloseOrderOutstandingBalance(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440340")
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440341")
+
+	tests := []struct {
+		name           string
+		payments       []*Payment
+		expectedStatus int
+		expectSettled  bool
+	}{
+		{name: "unpaid", expectedStatus: http.StatusConflict},
+		{name: "partiallyPaid", payments: []*Payment{{OrderID: orderID, Tender: TenderCard, Amount: 10}}, expectedStatus: http.StatusConflict},
+		// Settled only once the pending item is dropped, so closing settles it
+		{name: "fullyPaid", payments: []*Payment{{OrderID: orderID, Tender: TenderCard, Amount: 12}}, expectedStatus: http.StatusOK, expectSettled: true},
+		// Settled by the payment, which published the event already
+		{name: "paidWithPendingItem", payments: []*Payment{{OrderID: orderID, Tender: TenderCard, Amount: 19}}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepo := NewMockOrderRepo()
+			itemRepo := NewMockOrderItemRepo()
+			paymentRepo := NewMockPaymentRepo()
+			paymentRepo.payments = tt.payments
+
+			orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+			itemRepo.items[uuid.New()] = &OrderItem{OrderID: orderID, Quantity: 1, Price: 12, Status: "delivered"}
+			// Pending items are cancelled on a forced close and must not be billed
+			itemRepo.items[uuid.New()] = &OrderItem{OrderID: orderID, Quantity: 1, Price: 7, Status: "pending"}
+
+			var published []pkg.OrderBillSettledEvent
+			publisher := NewMockPublisher()
+			publisher.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
+				var event pkg.OrderBillSettledEvent
+				if err := unmarshalEvent(msg, &event); err == nil && topic == pkg.OrderTableTopic {
+					published = append(published, event)
+				}
+				return nil
+			}
+
+			h := NewHandler(HandlerDeps{
+				Repos:     Repos{OrderRepo: orderRepo, OrderItemRepo: itemRepo, PaymentRepo: paymentRepo},
+				Publisher: publisher,
+			}, apt.NewConfig(), nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID.String()+"/close?force=true", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", orderID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.CloseOrder(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("CloseOrder() status = %d, want %d, body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusConflict && orderRepo.orders[orderID].Status == "closed" {
+				t.Error("order should remain open while the bill has a balance")
+			}
+
+			if tt.expectSettled {
+				if len(published) != 1 {
+					t.Fatalf("published %d bill settled events, want 1", len(published))
+				}
+				if event := published[0]; event.OrderID != orderID.String() || event.Total != 12 || event.Paid != 12 {
+					t.Errorf("bill settled event = %+v", event)
+				}
+			} else if len(published) != 0 {
+				t.Errorf("published %d bill settled events, want 0", len(published))
+			}
+		})
+	}
+}
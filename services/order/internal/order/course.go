@@ -0,0 +1,238 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/outbox"
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+	"github.com/google/uuid"
+)
+
+// Course is the position of an item in the meal. Items without a course (0)
+// go to the kitchen as soon as they are ordered.
+type Course int
+
+// MaxCourse is the highest course an item can be assigned to.
+const MaxCourse Course = 9
+
+// Named courses, for the usual starter, main and dessert service.
+const (
+	CourseStarter Course = 1
+	CourseMain    Course = 2
+	CourseDessert Course = 3
+)
+
+var courseNames = map[string]Course{
+	"starter": CourseStarter,
+	"main":    CourseMain,
+	"dessert": CourseDessert,
+}
+
+// ParseCourse reads a course by name (starter, main, dessert) or number.
+// An empty string means no course.
+func ParseCourse(s string) (Course, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	if c, ok := courseNames[s]; ok {
+		return c, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("unknown course %q: use starter, main, dessert or a number", s)
+	}
+	c := Course(n)
+	if c < 0 || c > MaxCourse {
+		return 0, fmt.Errorf("course must be between 0 and %d", MaxCourse)
+	}
+	return c, nil
+}
+
+// UnmarshalJSON accepts a course as a number or as a name.
+func (c *Course) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		if Course(n) < 0 || Course(n) > MaxCourse {
+			return fmt.Errorf("course must be between 0 and %d", MaxCourse)
+		}
+		*c = Course(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("course must be a number or a name")
+	}
+	parsed, err := ParseCourse(s)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// Label names the course for screens: "Starter", "Main", "Dessert" or
+// "Course 4".
+func (c Course) Label() string {
+	for name, course := range courseNames {
+		if course == c {
+			return strings.ToUpper(name[:1]) + name[1:]
+		}
+	}
+	return fmt.Sprintf("Course %d", c)
+}
+
+// courseOpen reports whether the item still keeps later courses on hold.
+func courseOpen(item *OrderItem) bool {
+	return item.Course > 0 && !item.Held && item.Status != "delivered" && item.Status != "cancelled"
+}
+
+// firedCourse returns the highest course already sent to the kitchen.
+func firedCourse(items []*OrderItem) Course {
+	var fired Course
+	for _, item := range items {
+		if !item.Held && item.Status != "cancelled" && item.Course > fired {
+			fired = item.Course
+		}
+	}
+	return fired
+}
+
+// holdsCourse reports whether a new item of course must wait: it does when
+// its course was not fired yet and an earlier course is still being served.
+func holdsCourse(items []*OrderItem, course Course) bool {
+	if course == 0 || course <= firedCourse(items) {
+		return false
+	}
+	for _, item := range items {
+		if courseOpen(item) && item.Course < course {
+			return true
+		}
+	}
+	return false
+}
+
+// nextHeldCourse returns the earliest course on hold, or 0 when none is.
+func nextHeldCourse(items []*OrderItem) Course {
+	var next Course
+	for _, item := range items {
+		if item.Held && item.Status != "cancelled" && (next == 0 || item.Course < next) {
+			next = item.Course
+		}
+	}
+	return next
+}
+
+// CourseFirer sends held courses to the kitchen, on demand or once the
+// course before them has been served.
+type CourseFirer struct {
+	items     OrderItemRepo
+	publisher events.Publisher
+	stream    *OrderEventStreamServer
+	logger    apt.Logger
+}
+
+func NewCourseFirer(items OrderItemRepo, publisher events.Publisher, stream *OrderEventStreamServer, logger apt.Logger) *CourseFirer {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &CourseFirer{
+		items:     items,
+		publisher: publisher,
+		stream:    stream,
+		logger:    logger,
+	}
+}
+
+// Fire releases the held items of course to the kitchen. Course 0 releases
+// every held course of the order. It returns the items it fired.
+func (f *CourseFirer) Fire(ctx context.Context, orderID uuid.UUID, course Course) ([]*OrderItem, error) {
+	items, err := f.items.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list order items: %w", err)
+	}
+
+	now := time.Now()
+	fired := []*OrderItem{}
+	for _, item := range items {
+		if !item.Held || item.Status == "cancelled" || (course != 0 && item.Course != course) {
+			continue
+		}
+		item.Fire(now)
+		// The kitchen releases the ticket on the fired event, so it is
+		// saved with the item
+		err := outbox.WithTransaction(ctx, f.publisher, func(ctx context.Context) error {
+			if err := f.items.Save(ctx, item); err != nil {
+				return err
+			}
+			return f.publishFired(ctx, item)
+		})
+		if err != nil {
+			return fired, fmt.Errorf("cannot fire order item %s: %w", item.ID, err)
+		}
+		if f.stream != nil {
+			f.stream.BroadcastOrderItemEvent(item, event.EventOrderItemFired, item.Status)
+		}
+		fired = append(fired, item)
+	}
+
+	sort.Slice(fired, func(i, j int) bool { return fired[i].Course < fired[j].Course })
+	return fired, nil
+}
+
+// FireNextIfDue fires the earliest held course once every earlier course has
+// been delivered or cancelled.
+func (f *CourseFirer) FireNextIfDue(ctx context.Context, orderID uuid.UUID) ([]*OrderItem, error) {
+	items, err := f.items.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list order items: %w", err)
+	}
+
+	next := nextHeldCourse(items)
+	if next == 0 {
+		return nil, nil
+	}
+	for _, item := range items {
+		if courseOpen(item) && item.Course < next {
+			return nil, nil
+		}
+	}
+
+	f.logger.Info("previous course served, firing next", "order_id", orderID.String(), "course", int(next))
+	return f.Fire(ctx, orderID, next)
+}
+
+func (f *CourseFirer) publishFired(ctx context.Context, item *OrderItem) error {
+	if f.publisher == nil || !item.RequiresProduction {
+		return nil
+	}
+
+	evt := event.OrderItemEvent{
+		EventID:            event.NewID(),
+		EventType:          event.EventOrderItemFired,
+		OccurredAt:         time.Now().UTC(),
+		OrderID:            item.OrderID.String(),
+		OrderItemID:        item.ID.String(),
+		Quantity:           item.Quantity,
+		RequiresProduction: item.RequiresProduction,
+		Course:             int(item.Course),
+	}
+
+	payload, err := event.Marshal(ctx, eventSource, evt)
+	if err != nil {
+		return fmt.Errorf("cannot marshal order item fired event: %w", err)
+	}
+	if err := f.publisher.Publish(ctx, event.OrderItemsTopic, payload); err != nil {
+		return fmt.Errorf("cannot publish order item fired event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestParseCourse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Course
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "starter", want: CourseStarter},
+		{input: " Main ", want: CourseMain},
+		{input: "DESSERT", want: CourseDessert},
+		{input: "4", want: 4},
+		{input: "10", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "soup", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCourse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCourse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCourse(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCourseUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    Course
+		wantErr bool
+	}{
+		{name: "number", body: `{"course": 2}`, want: CourseMain},
+		{name: "name", body: `{"course": "dessert"}`, want: CourseDessert},
+		{name: "numberString", body: `{"course": "3"}`, want: 3},
+		{name: "outOfRange", body: `{"course": 12}`, wantErr: true},
+		{name: "unknownName", body: `{"course": "soup"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req OrderItemCreateRequest
+			err := json.Unmarshal([]byte(tt.body), &req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && req.Course != tt.want {
+				t.Errorf("Course = %d, want %d", req.Course, tt.want)
+			}
+		})
+	}
+}
+
+func TestHoldsCourse(t *testing.T) {
+	tests := []struct {
+		name   string
+		items  []*OrderItem
+		course Course
+		want   bool
+	}{
+		{
+			name:   "noCourse",
+			items:  []*OrderItem{{Course: CourseStarter, Status: "pending"}},
+			course: 0,
+			want:   false,
+		},
+		{
+			name:   "firstCourse",
+			items:  nil,
+			course: CourseMain,
+			want:   false,
+		},
+		{
+			name:   "starterStillOpen",
+			items:  []*OrderItem{{Course: CourseStarter, Status: "preparing"}},
+			course: CourseMain,
+			want:   true,
+		},
+		{
+			name:   "starterServed",
+			items:  []*OrderItem{{Course: CourseStarter, Status: "delivered"}},
+			course: CourseMain,
+			want:   false,
+		},
+		{
+			name: "courseAlreadyFired",
+			items: []*OrderItem{
+				{Course: CourseStarter, Status: "preparing"},
+				{Course: CourseMain, Status: "pending"},
+			},
+			course: CourseMain,
+			want:   false,
+		},
+		{
+			name: "joinsHeldCourse",
+			items: []*OrderItem{
+				{Course: CourseStarter, Status: "preparing"},
+				{Course: CourseMain, Status: "pending", Held: true},
+			},
+			course: CourseMain,
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := holdsCourse(tt.items, tt.course); got != tt.want {
+				t.Errorf("holdsCourse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCourseFirerFireNextIfDue(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440729")
+	starterID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440730")
+	mainID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440731")
+	dessertID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440732")
+
+	tests := []struct {
+		name          string
+		starterStatus string
+		wantFired     []uuid.UUID
+	}{
+		{name: "starterOpen", starterStatus: "ready", wantFired: nil},
+		{name: "starterDelivered", starterStatus: "delivered", wantFired: []uuid.UUID{mainID}},
+		{name: "starterCancelled", starterStatus: "cancelled", wantFired: []uuid.UUID{mainID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockOrderItemRepo()
+			repo.items[starterID] = &OrderItem{ID: starterID, OrderID: orderID, Course: CourseStarter, Status: tt.starterStatus, RequiresProduction: true}
+			repo.items[mainID] = &OrderItem{ID: mainID, OrderID: orderID, Course: CourseMain, Status: "pending", Held: true, RequiresProduction: true}
+			repo.items[dessertID] = &OrderItem{ID: dessertID, OrderID: orderID, Course: CourseDessert, Status: "pending", Held: true, RequiresProduction: true}
+
+			var published []event.OrderItemEvent
+			pub := NewMockPublisher()
+			pub.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
+				var evt event.OrderItemEvent
+				if err := unmarshalEvent(msg, &evt); err != nil {
+					t.Fatalf("cannot decode published event: %v", err)
+				}
+				published = append(published, evt)
+				return nil
+			}
+
+			firer := NewCourseFirer(repo, pub, nil, nil)
+			fired, err := firer.FireNextIfDue(context.Background(), orderID)
+			if err != nil {
+				t.Fatalf("FireNextIfDue() error = %v", err)
+			}
+
+			if len(fired) != len(tt.wantFired) {
+				t.Fatalf("FireNextIfDue() fired %d items, want %d", len(fired), len(tt.wantFired))
+			}
+			for i, item := range fired {
+				if item.ID != tt.wantFired[i] {
+					t.Errorf("fired[%d] = %s, want %s", i, item.ID, tt.wantFired[i])
+				}
+				if item.Held || item.FiredAt == nil {
+					t.Errorf("fired item %s still held", item.ID)
+				}
+			}
+			if len(published) != len(tt.wantFired) {
+				t.Fatalf("published %d events, want %d", len(published), len(tt.wantFired))
+			}
+			for _, evt := range published {
+				if evt.EventType != event.EventOrderItemFired || evt.Course != int(CourseMain) {
+					t.Errorf("published %s for course %d, want %s for course %d", evt.EventType, evt.Course, event.EventOrderItemFired, CourseMain)
+				}
+			}
+			if !repo.items[dessertID].Held {
+				t.Error("dessert should stay held until the main course is served")
+			}
+		})
+	}
+}
+
+func TestHandlerFireCourse(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440733")
+	mainID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440734")
+	dessertID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440735")
+
+	tests := []struct {
+		name           string
+		orderID        string
+		course         string
+		expectedStatus int
+		expectedFired  int
+	}{
+		{name: "fireByName", orderID: orderID.String(), course: "main", expectedStatus: http.StatusOK, expectedFired: 1},
+		{name: "fireByNumber", orderID: orderID.String(), course: "3", expectedStatus: http.StatusOK, expectedFired: 1},
+		{name: "fireAll", orderID: orderID.String(), expectedStatus: http.StatusOK, expectedFired: 2},
+		{name: "nothingHeld", orderID: orderID.String(), course: "starter", expectedStatus: http.StatusOK, expectedFired: 0},
+		{name: "unknownCourse", orderID: orderID.String(), course: "soup", expectedStatus: http.StatusBadRequest},
+		{name: "unknownOrder", orderID: uuid.New().String(), course: "main", expectedStatus: http.StatusNotFound},
+		{name: "invalidOrderID", orderID: "not-a-uuid", course: "main", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepo := NewMockOrderRepo()
+			orderRepo.orders[orderID] = &Order{ID: orderID, Status: "pending"}
+			itemRepo := NewMockOrderItemRepo()
+			itemRepo.items[mainID] = &OrderItem{ID: mainID, OrderID: orderID, Course: CourseMain, Status: "pending", Held: true}
+			itemRepo.items[dessertID] = &OrderItem{ID: dessertID, OrderID: orderID, Course: CourseDessert, Status: "pending", Held: true}
+
+			deps := HandlerDeps{
+				Repos: Repos{
+					OrderRepo:     orderRepo,
+					OrderItemRepo: itemRepo,
+				},
+			}
+			h := NewHandler(deps, apt.NewConfig(), nil)
+
+			r := chi.NewRouter()
+			r.Post("/orders/{id}/fire", h.FireCourse)
+			r.Post("/orders/{id}/courses/{course}/fire", h.FireCourse)
+
+			path := "/orders/" + tt.orderID + "/fire"
+			if tt.course != "" {
+				path = "/orders/" + tt.orderID + "/courses/" + tt.course + "/fire"
+			}
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("FireCourse() status = %d, want %d: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data struct {
+					Fired []OrderItem `json:"fired"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("cannot decode response: %v", err)
+			}
+			if len(resp.Data.Fired) != tt.expectedFired {
+				t.Errorf("fired %d items, want %d", len(resp.Data.Fired), tt.expectedFired)
+			}
+		})
+	}
+}
+
+func TestHandlerCreateOrderItemHoldsLaterCourse(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440736")
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440737")
+	starterID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440738")
+	station := "kitchen"
+
+	tests := []struct {
+		name          string
+		starterStatus string
+		body          string
+		wantHeld      bool
+		wantEvent     bool
+	}{
+		{name: "starterOpen", starterStatus: "preparing", body: `"main"`, wantHeld: true, wantEvent: true},
+		{name: "starterServed", starterStatus: "delivered", body: `"main"`, wantHeld: false, wantEvent: true},
+		{name: "sameCourse", starterStatus: "preparing", body: `1`, wantHeld: false, wantEvent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepo := NewMockOrderRepo()
+			itemRepo := NewMockOrderItemRepo()
+			cache := NewTableStateCache(nil, nil)
+
+			orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+			itemRepo.items[starterID] = &OrderItem{ID: starterID, OrderID: orderID, Course: CourseStarter, Status: tt.starterStatus, RequiresProduction: true}
+			cache.Set(tableID, "open")
+
+			var created *event.OrderItemEvent
+			pub := NewMockPublisher()
+			pub.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
+				var evt event.OrderItemEvent
+				if err := unmarshalEvent(msg, &evt); err == nil && evt.EventType == event.EventOrderItemCreated {
+					created = &evt
+				}
+				return nil
+			}
+
+			deps := HandlerDeps{
+				Repos: Repos{
+					OrderRepo:     orderRepo,
+					OrderItemRepo: itemRepo,
+				},
+				TableStatesCache: cache,
+				Publisher:        pub,
+			}
+			h := NewHandler(deps, apt.NewConfig(), nil)
+
+			body := `{"dish_name": "Steak", "category": "main", "quantity": 1, "price": 20, "requires_production": true, ` +
+				`"production_station": "` + station + `", "course": ` + tt.body + `}`
+			req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID.String()+"/items", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("orderID", orderID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.CreateOrderItem(w, req)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("CreateOrderItem() status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+			}
+			if created == nil {
+				t.Fatal("CreateOrderItem() did not publish a created event")
+			}
+			if created.Held != tt.wantHeld {
+				t.Errorf("created event Held = %v, want %v", created.Held, tt.wantHeld)
+			}
+		})
+	}
+}
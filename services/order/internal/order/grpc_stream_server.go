@@ -1,24 +1,36 @@
 package order
 
 import (
+	"context"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/appetiteclub/appetite/pkg"
 	proto "github.com/appetiteclub/appetite/services/order/internal/order/proto"
 	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// EventOrderItemSnapshot marks the items a subscriber gets when it joins, so
+// it can tell the current state apart from changes.
+const EventOrderItemSnapshot = "order.item.snapshot"
+
 // OrderEventStreamServer implements the gRPC OrderEventStream service
 type OrderEventStreamServer struct {
 	proto.UnimplementedOrderEventStreamServer
+	orderRepo     OrderRepo
 	orderItemRepo OrderItemRepo
+	tables        *TableStateCache
 	logger        apt.Logger
 
-	// Manage active stream subscribers
+	// Manage active stream subscribers and the recent events they can
+	// resume from
 	mu          sync.RWMutex
 	subscribers map[string]chan *proto.OrderItemEvent
+	replay      *pkg.ReplayBuffer[*proto.OrderItemEvent]
 }
 
 // RegisterGRPCService registers this service with the gRPC server (apt.GRPCServiceRegistrar interface)
@@ -26,12 +38,17 @@ func (s *OrderEventStreamServer) RegisterGRPCService(server *grpc.Server) {
 	proto.RegisterOrderEventStreamServer(server, s)
 }
 
-// NewOrderEventStreamServer creates a new gRPC streaming server for order items
-func NewOrderEventStreamServer(orderItemRepo OrderItemRepo, logger apt.Logger) *OrderEventStreamServer {
+// NewOrderEventStreamServer creates a new gRPC streaming server for order
+// items. Orders tell which table an item is for and the table cache which
+// waiter serves it, for the subscriber filters.
+func NewOrderEventStreamServer(orderRepo OrderRepo, orderItemRepo OrderItemRepo, tables *TableStateCache, logger apt.Logger) *OrderEventStreamServer {
 	return &OrderEventStreamServer{
+		orderRepo:     orderRepo,
 		orderItemRepo: orderItemRepo,
+		tables:        tables,
 		logger:        logger,
 		subscribers:   make(map[string]chan *proto.OrderItemEvent),
+		replay:        pkg.NewReplayBuffer[*proto.OrderItemEvent](pkg.DefaultReplaySize),
 	}
 }
 
@@ -39,14 +56,23 @@ func NewOrderEventStreamServer(orderItemRepo OrderItemRepo, logger apt.Logger) *
 func (s *OrderEventStreamServer) StreamOrderItemEvents(req *proto.SubscribeOrderItemEventsRequest, stream proto.OrderEventStream_StreamOrderItemEventsServer) error {
 	ctx := stream.Context()
 	subscriberID := generateSubscriberID()
+	filter := newOrderItemFilter(req)
 
-	s.logger.Info("new order item events subscriber", "subscriber_id", subscriberID, "table_filter", req.TableId, "order_filter", req.OrderId)
+	s.logger.Info("new order item events subscriber", "subscriber_id", subscriberID, "table_filter", req.TableId, "order_filter", req.OrderId, "waiter_filter", req.WaiterId, "status_filter", req.Statuses, "after_sequence", req.AfterSequence)
 
 	// Create channel for this subscriber
 	eventChan := make(chan *proto.OrderItemEvent, 100)
 
+	// Register and read what the subscriber missed under one lock, so no
+	// event falls between the replay and the live feed
+	var missed []*proto.OrderItemEvent
+	resumed := false
 	s.mu.Lock()
 	s.subscribers[subscriberID] = eventChan
+	if req.AfterSequence > 0 {
+		missed, resumed = s.replay.Since(req.Instance, req.AfterSequence)
+	}
+	current := s.replay.Last()
 	s.mu.Unlock()
 
 	// Cleanup on disconnect
@@ -58,10 +84,19 @@ func (s *OrderEventStreamServer) StreamOrderItemEvents(req *proto.SubscribeOrder
 		s.logger.Info("order item events subscriber disconnected", "subscriber_id", subscriberID)
 	}()
 
-	// Send initial state - get all order items based on filters
-	// For now, we'll skip initial state to simplify
-	// In production, you might want to load recent order items from the DB
-	// based on req.TableId or req.OrderId filters
+	if resumed {
+		for _, evt := range missed {
+			if !s.matches(filter, evt) {
+				continue
+			}
+			if err := stream.Send(evt); err != nil {
+				s.logger.Errorf("failed to replay event: %v", err)
+				return err
+			}
+		}
+	} else if err := s.sendCurrentItems(ctx, stream, filter, current); err != nil {
+		return err
+	}
 
 	// Stream real-time updates
 	for {
@@ -69,7 +104,9 @@ func (s *OrderEventStreamServer) StreamOrderItemEvents(req *proto.SubscribeOrder
 		case <-ctx.Done():
 			return ctx.Err()
 		case evt := <-eventChan:
-			// TODO: Apply filters if needed (table_id, order_id)
+			if !s.matches(filter, evt) {
+				continue
+			}
 			if err := stream.Send(evt); err != nil {
 				s.logger.Errorf("failed to send event: %v", err)
 				return err
@@ -78,6 +115,142 @@ func (s *OrderEventStreamServer) StreamOrderItemEvents(req *proto.SubscribeOrder
 	}
 }
 
+// sendCurrentItems sends the items of the open orders in the subscriber's
+// scope as snapshot events. They carry the latest sequence so far, so a
+// subscriber resuming from any of them gets what happened after.
+func (s *OrderEventStreamServer) sendCurrentItems(ctx context.Context, stream proto.OrderEventStream_StreamOrderItemEventsServer, filter orderItemFilter, sequence uint64) error {
+	if s.orderRepo == nil || s.orderItemRepo == nil {
+		return nil
+	}
+
+	orders, err := s.ordersInScope(ctx, filter)
+	if err != nil {
+		s.logger.Errorf("failed to load orders for snapshot: %v", err)
+		return nil
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ID
+	}
+	items, err := s.orderItemRepo.ListByOrders(ctx, ids)
+	if err != nil {
+		s.logger.Errorf("failed to load items for snapshot: %v", err)
+		return nil
+	}
+	itemsByOrder := make(map[uuid.UUID][]*OrderItem, len(orders))
+	for _, item := range items {
+		itemsByOrder[item.OrderID] = append(itemsByOrder[item.OrderID], item)
+	}
+
+	for _, order := range orders {
+		for _, item := range itemsByOrder[order.ID] {
+			evt := s.itemEvent(item, order.TableID, EventOrderItemSnapshot, "")
+			evt.Sequence = sequence
+			evt.Instance = s.replay.Epoch()
+			if !s.matches(filter, evt) {
+				continue
+			}
+			if err := stream.Send(evt); err != nil {
+				s.logger.Errorf("failed to send initial item: %v", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ordersInScope loads the open orders that can hold the items the filter
+// asks for, querying no more of them than it has to.
+func (s *OrderEventStreamServer) ordersInScope(ctx context.Context, filter orderItemFilter) ([]*Order, error) {
+	var orders []*Order
+	switch {
+	case filter.orderID != "":
+		id, err := uuid.Parse(filter.orderID)
+		if err != nil {
+			return nil, nil
+		}
+		order, err := s.orderRepo.Get(ctx, id)
+		if err != nil || order == nil {
+			return nil, err
+		}
+		orders = []*Order{order}
+	case filter.tableID != "":
+		id, err := uuid.Parse(filter.tableID)
+		if err != nil {
+			return nil, nil
+		}
+		if orders, err = s.orderRepo.ListByTable(ctx, id); err != nil {
+			return nil, err
+		}
+	default:
+		return s.orderRepo.ListOpen(ctx)
+	}
+
+	open := orders[:0]
+	for _, order := range orders {
+		if order != nil && order.IsOpen() {
+			open = append(open, order)
+		}
+	}
+	return open, nil
+}
+
+// orderItemFilter is the scope a subscriber asked for. Empty fields match
+// everything.
+type orderItemFilter struct {
+	tableID  string
+	orderID  string
+	waiterID string
+	statuses map[string]bool
+}
+
+func newOrderItemFilter(req *proto.SubscribeOrderItemEventsRequest) orderItemFilter {
+	filter := orderItemFilter{
+		tableID:  strings.TrimSpace(req.TableId),
+		orderID:  strings.TrimSpace(req.OrderId),
+		waiterID: strings.TrimSpace(req.WaiterId),
+	}
+	for _, status := range req.Statuses {
+		if status = strings.ToLower(strings.TrimSpace(status)); status != "" {
+			if filter.statuses == nil {
+				filter.statuses = make(map[string]bool)
+			}
+			filter.statuses[status] = true
+		}
+	}
+	return filter
+}
+
+// matches reports whether evt falls in the filter's scope. The waiter is
+// checked against the table as it is now, so a handheld follows its tables
+// when they are reassigned.
+func (s *OrderEventStreamServer) matches(filter orderItemFilter, evt *proto.OrderItemEvent) bool {
+	if filter.orderID != "" && !strings.EqualFold(evt.OrderId, filter.orderID) {
+		return false
+	}
+	if filter.tableID != "" && !strings.EqualFold(evt.TableId, filter.tableID) {
+		return false
+	}
+	if filter.statuses != nil && !filter.statuses[strings.ToLower(evt.NewStatus)] {
+		return false
+	}
+	if filter.waiterID != "" {
+		tableID, err := uuid.Parse(evt.TableId)
+		if err != nil || s.tables == nil {
+			return false
+		}
+		details, ok := s.tables.Details(tableID)
+		if !ok || !strings.EqualFold(details.AssignedTo, filter.waiterID) {
+			return false
+		}
+	}
+	return true
+}
+
 // BroadcastOrderItemEvent sends an event to all connected subscribers
 // This should be called when an OrderItem status changes
 func (s *OrderEventStreamServer) BroadcastOrderItemEvent(item *OrderItem, eventType string, previousStatus string) {
@@ -89,6 +262,26 @@ func (s *OrderEventStreamServer) BroadcastOrderItemEvent(item *OrderItem, eventT
 		"total_subscribers", len(s.subscribers),
 	)
 
+	protoEvt := s.itemEvent(item, s.tableOf(item.OrderID), eventType, previousStatus)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	protoEvt.Sequence = s.replay.Append(protoEvt)
+	protoEvt.Instance = s.replay.Epoch()
+
+	for subscriberID, ch := range s.subscribers {
+		select {
+		case ch <- protoEvt:
+			// Event sent successfully
+		default:
+			// Channel full, subscriber too slow - skip this event
+			s.logger.Info("subscriber channel full, dropping event", "subscriber_id", subscriberID)
+		}
+	}
+}
+
+func (s *OrderEventStreamServer) itemEvent(item *OrderItem, tableID uuid.UUID, eventType string, previousStatus string) *proto.OrderItemEvent {
 	protoEvt := &proto.OrderItemEvent{
 		EventType:          eventType,
 		OccurredAt:         timestamppb.New(time.Now()),
@@ -112,18 +305,29 @@ func (s *OrderEventStreamServer) BroadcastOrderItemEvent(item *OrderItem, eventT
 		protoEvt.DeliveredAt = timestamppb.New(*item.DeliveredAt)
 	}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	for subscriberID, ch := range s.subscribers {
-		select {
-		case ch <- protoEvt:
-			// Event sent successfully
-		default:
-			// Channel full, subscriber too slow - skip this event
-			s.logger.Info("subscriber channel full, dropping event", "subscriber_id", subscriberID)
+	if tableID != uuid.Nil {
+		protoEvt.TableId = tableID.String()
+		if s.tables != nil {
+			if details, ok := s.tables.Details(tableID); ok {
+				protoEvt.TableNumber = details.Number
+			}
 		}
 	}
+
+	return protoEvt
+}
+
+// tableOf returns the table of an order, or uuid.Nil when it cannot be
+// loaded.
+func (s *OrderEventStreamServer) tableOf(orderID uuid.UUID) uuid.UUID {
+	if s.orderRepo == nil {
+		return uuid.Nil
+	}
+	order, err := s.orderRepo.Get(context.Background(), orderID)
+	if err != nil || order == nil {
+		return uuid.Nil
+	}
+	return order.TableID
 }
 
 // Helper to generate unique subscriber IDs
@@ -0,0 +1,51 @@
+package order
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestOrdersInScopeSkipsClosedOrders(t *testing.T) {
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440070")
+	openID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440071")
+	closedID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440072")
+	otherID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440073")
+
+	repo := NewMockOrderRepo()
+	repo.orders[openID] = &Order{ID: openID, TableID: tableID, Status: "pending"}
+	repo.orders[closedID] = &Order{ID: closedID, TableID: tableID, Status: "closed"}
+	repo.orders[otherID] = &Order{ID: otherID, TableID: uuid.New(), Status: "cancelled"}
+
+	s := NewOrderEventStreamServer(repo, NewMockOrderItemRepo(), nil, nil)
+
+	tests := []struct {
+		name   string
+		filter orderItemFilter
+		want   []uuid.UUID
+	}{
+		{name: "all", filter: orderItemFilter{}, want: []uuid.UUID{openID}},
+		{name: "table", filter: orderItemFilter{tableID: tableID.String()}, want: []uuid.UUID{openID}},
+		{name: "openOrder", filter: orderItemFilter{orderID: openID.String()}, want: []uuid.UUID{openID}},
+		{name: "closedOrder", filter: orderItemFilter{orderID: closedID.String()}},
+		{name: "invalidOrder", filter: orderItemFilter{orderID: "nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, err := s.ordersInScope(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("ordersInScope() error = %v", err)
+			}
+			if len(orders) != len(tt.want) {
+				t.Fatalf("ordersInScope() returned %d orders, want %d", len(orders), len(tt.want))
+			}
+			for i, order := range orders {
+				if order.ID != tt.want[i] {
+					t.Errorf("ordersInScope()[%d] = %s, want %s", i, order.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
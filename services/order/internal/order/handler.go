@@ -3,6 +3,7 @@ package order
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/appetiteclub/appetite/pkg"
 	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/outbox"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
 	"github.com/appetiteclub/apt/telemetry"
@@ -20,6 +22,9 @@ import (
 
 const MaxBodyBytes = 1 << 20
 
+// eventSource names the order service on the events it publishes.
+const eventSource = "order-service"
+
 type Handler struct {
 	logger         apt.Logger
 	config         *apt.Config
@@ -27,11 +32,15 @@ type Handler struct {
 	orderRepo      OrderRepo
 	orderItemRepo  OrderItemRepo
 	orderGroupRepo OrderGroupRepo
+	paymentRepo    PaymentRepo
+	billing        BillingPolicy
 	tableClient    *apt.ServiceClient
 	tableStates    *TableStateCache
+	menuClient     *apt.ServiceClient
 	kitchenClient  *apt.ServiceClient
 	publisher      events.Publisher
 	streamServer   *OrderEventStreamServer
+	courses        *CourseFirer
 }
 
 type HandlerDeps struct {
@@ -46,6 +55,7 @@ type Repos struct {
 	OrderRepo      OrderRepo
 	OrderItemRepo  OrderItemRepo
 	OrderGroupRepo OrderGroupRepo
+	PaymentRepo    PaymentRepo
 }
 
 func NewHandler(hd HandlerDeps, config *apt.Config, logger apt.Logger) *Handler {
@@ -57,6 +67,12 @@ func NewHandler(hd HandlerDeps, config *apt.Config, logger apt.Logger) *Handler
 	tableURL, _ := config.GetString("services.table.url")
 	tableClient := apt.NewServiceClient(tableURL)
 
+	// Menu service client for checking item visibility; skipped when unset
+	var menuClient *apt.ServiceClient
+	if menuURL, _ := config.GetString("services.menu.url"); menuURL != "" {
+		menuClient = apt.NewServiceClient(menuURL)
+	}
+
 	return &Handler{
 		config:         config,
 		logger:         logger,
@@ -64,11 +80,15 @@ func NewHandler(hd HandlerDeps, config *apt.Config, logger apt.Logger) *Handler
 		orderRepo:      hd.Repos.OrderRepo,
 		orderItemRepo:  hd.Repos.OrderItemRepo,
 		orderGroupRepo: hd.Repos.OrderGroupRepo,
+		paymentRepo:    hd.Repos.PaymentRepo,
+		billing:        NewBillingPolicy(config),
 		tableClient:    tableClient,
 		tableStates:    hd.TableStatesCache,
+		menuClient:     menuClient,
 		kitchenClient:  hd.KitchenClient,
 		publisher:      hd.Publisher,
 		streamServer:   hd.OrderStreamServer,
+		courses:        NewCourseFirer(hd.Repos.OrderItemRepo, hd.Publisher, hd.OrderStreamServer, logger),
 	}
 }
 
@@ -80,6 +100,9 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Put("/{id}", h.UpdateOrderStatus)
 		r.Delete("/{id}", h.DeleteOrder)
 		r.Post("/{id}/close", h.CloseOrder)
+		r.Post("/{id}/fire", h.FireCourse)
+		r.Post("/{id}/courses/{course}/fire", h.FireCourse)
+		r.Get("/{id}/bill", h.GetBill)
 
 		r.Route("/{orderID}/items", func(r chi.Router) {
 			r.Post("/", h.CreateOrderItem)
@@ -89,7 +112,15 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Route("/{orderID}/groups", func(r chi.Router) {
 			r.Post("/", h.CreateOrderGroup)
 			r.Get("/", h.ListOrderGroups)
+			r.Delete("/{groupID}", h.DeleteOrderGroup)
+		})
+
+		r.Route("/{orderID}/payments", func(r chi.Router) {
+			r.Post("/", h.CreatePayment)
+			r.Get("/", h.ListPayments)
 		})
+
+		r.Post("/{orderID}/split", h.SplitOrder)
 	})
 
 	r.Route("/order-items", func(r chi.Router) {
@@ -190,9 +221,12 @@ func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	log := h.log(r)
 	ctx := r.Context()
 
-	// Support filtering by table_id and status via query params
-	tableIDStr := r.URL.Query().Get("table_id")
-	status := r.URL.Query().Get("status")
+	// Support filtering by table_id, id_prefix, open and status via query
+	// params; summary=true adds the item count and subtotal of each order
+	query := r.URL.Query()
+	tableIDStr := query.Get("table_id")
+	idPrefix := query.Get("id_prefix")
+	status := query.Get("status")
 
 	var orders []*Order
 	var err error
@@ -205,6 +239,16 @@ func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		orders, err = h.orderRepo.ListByTable(ctx, tableID)
+	} else if idPrefix != "" {
+		prefix, parseErr := ParseIDPrefix(idPrefix)
+		if parseErr != nil {
+			log.Debug("invalid id_prefix parameter", "id_prefix", idPrefix)
+			apt.RespondError(w, http.StatusBadRequest, parseErr.Error())
+			return
+		}
+		orders, err = h.orderRepo.ListByIDPrefix(ctx, prefix)
+	} else if query.Get("open") == "true" {
+		orders, err = h.orderRepo.ListOpen(ctx)
 	} else if status != "" {
 		orders, err = h.orderRepo.ListByStatus(ctx, status)
 	} else {
@@ -217,7 +261,23 @@ func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apt.RespondCollection(w, orders, "order")
+	if query.Get("summary") != "true" {
+		apt.RespondCollection(w, orders, "order")
+		return
+	}
+
+	ids := make([]uuid.UUID, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	items, err := h.orderItemRepo.ListByOrders(ctx, ids)
+	if err != nil {
+		log.Error("error retrieving order items", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order items")
+		return
+	}
+
+	apt.RespondCollection(w, Summarize(orders, items), "order")
 }
 
 func (h *Handler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
@@ -328,6 +388,63 @@ func (h *Handler) CreateOrderItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var availability *MenuItemAvailability
+	if req.MenuItemID != nil {
+		availability, err = h.fetchMenuItemAvailability(ctx, *req.MenuItemID)
+		switch {
+		case req.ManagerOverride:
+			log.Info("menu item visibility overridden by manager", "order_id", orderID.String(), "menu_item_id", req.MenuItemID.String(), "error", err)
+		case err != nil:
+			log.Info("cannot check menu item availability", "menu_item_id", req.MenuItemID.String(), "error", err)
+			apt.RespondError(w, http.StatusServiceUnavailable, "Could not check menu item availability")
+			return
+		case availability != nil && availability.Err() != nil:
+			log.Info("menu item cannot be ordered", "menu_item_id", req.MenuItemID.String(), "error", availability.Err())
+			apt.RespondError(w, http.StatusConflict, availability.Err().Error())
+			return
+		}
+	}
+
+	var modifiers []OrderItemModifier
+	if len(req.ModifierOptionIDs) > 0 || (availability != nil && availability.RequiresModifiers) {
+		if req.MenuItemID == nil {
+			apt.RespondError(w, http.StatusBadRequest, "Modifiers need a menu item")
+			return
+		}
+		modifiers, err = h.resolveMenuItemModifiers(ctx, *req.MenuItemID, req.ModifierOptionIDs)
+		if err != nil {
+			var httpErr *apt.HTTPError
+			if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnprocessableEntity {
+				log.Info("invalid modifiers for menu item", "menu_item_id", req.MenuItemID.String(), "error", err)
+				apt.RespondError(w, http.StatusUnprocessableEntity, httpErr.Message)
+				return
+			}
+			log.Info("cannot resolve menu item modifiers", "menu_item_id", req.MenuItemID.String(), "error", err)
+			apt.RespondError(w, http.StatusServiceUnavailable, "Could not check menu item modifiers")
+			return
+		}
+	}
+
+	var portion *MenuItemPortion
+	if req.PortionID != nil {
+		if req.MenuItemID == nil {
+			apt.RespondError(w, http.StatusBadRequest, "Portions need a menu item")
+			return
+		}
+		portion, err = h.fetchMenuItemPortion(ctx, *req.MenuItemID, *req.PortionID)
+		if err != nil {
+			var httpErr *apt.HTTPError
+			if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusUnprocessableEntity) {
+				log.Info("invalid portion for menu item", "menu_item_id", req.MenuItemID.String(), "portion_id", req.PortionID.String(), "error", err)
+				apt.RespondError(w, http.StatusUnprocessableEntity, httpErr.Message)
+				return
+			}
+			log.Info("cannot resolve menu item portion", "menu_item_id", req.MenuItemID.String(), "error", err)
+			apt.RespondError(w, http.StatusServiceUnavailable, "Could not check menu item portion")
+			return
+		}
+	}
+
 	item := NewOrderItem()
 	item.OrderID = orderID
 	item.GroupID = req.GroupID
@@ -339,26 +456,52 @@ func (h *Handler) CreateOrderItem(w http.ResponseWriter, r *http.Request) {
 	item.MenuItemID = req.MenuItemID
 	item.ProductionStation = req.ProductionStation
 	item.RequiresProduction = req.RequiresProduction
+	if availability != nil && availability.MenuID != nil {
+		item.MenuID = availability.MenuID
+		item.MenuVersion = availability.MenuVersion
+	}
+	if portion != nil {
+		item.ApplyPortion(*portion)
+	}
+	item.ApplyModifiers(modifiers)
 
 	// Direct service items (no production required) start as ready for immediate delivery
 	// NOTE: Future enhancement may involve stock service integration for availability checks
-	if !req.RequiresProduction {
+	if !item.RequiresProduction {
 		item.Status = "ready"
 	}
 
+	// Later courses wait in the kitchen until the one before is served
+	item.Course = req.Course
+	if item.Course > 0 && item.RequiresProduction {
+		siblings, err := h.orderItemRepo.ListByOrder(ctx, orderID)
+		if err != nil {
+			log.Error("cannot list order items for coursing", "error", err, "order_id", orderID.String())
+			apt.RespondError(w, http.StatusInternalServerError, "Could not create order item")
+			return
+		}
+		item.Held = holdsCourse(siblings, item.Course)
+	}
+
 	item.BeforeCreate()
 
-	if err := h.orderItemRepo.Create(ctx, item); err != nil {
+	// The item and its kitchen event are saved together, so the kitchen
+	// gets the ticket even when NATS is down while the item is created
+	err = outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.orderItemRepo.Create(ctx, item); err != nil {
+			return err
+		}
+		if item.RequiresProduction {
+			return h.publishOrderItemCreated(ctx, item, parentOrder)
+		}
+		return nil
+	})
+	if err != nil {
 		log.Error("cannot create order item", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not create order item")
 		return
 	}
 
-	// Publish event to NATS if item requires production
-	if item.RequiresProduction && h.publisher != nil {
-		h.publishOrderItemCreated(ctx, item, parentOrder)
-	}
-
 	links := apt.RESTfulLinksFor(item)
 	w.WriteHeader(http.StatusCreated)
 	apt.RespondSuccess(w, item, links...)
@@ -448,6 +591,14 @@ func (h *Handler) UpdateOrderItem(w http.ResponseWriter, r *http.Request) {
 	if req.Notes != nil {
 		item.Notes = *req.Notes
 	}
+	if req.GroupID != nil {
+		groupID, ok := h.resolveItemGroup(ctx, item.OrderID, *req.GroupID)
+		if !ok {
+			apt.RespondError(w, http.StatusBadRequest, "Group does not belong to the order")
+			return
+		}
+		item.GroupID = groupID
+	}
 	if req.Status != nil {
 		switch *req.Status {
 		case "preparing":
@@ -567,6 +718,108 @@ func (h *Handler) ListOrderGroups(w http.ResponseWriter, r *http.Request) {
 	apt.RespondCollection(w, groups, "order_group")
 }
 
+// DeleteOrderGroup removes a group from an order. Its items move to the
+// default group, or stay ungrouped when the order has none.
+func (h *Handler) DeleteOrderGroup(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.DeleteOrderGroup")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	orderIDStr := chi.URLParam(r, "orderID")
+	orderID, err := uuid.Parse(orderIDStr)
+	if err != nil {
+		log.Debug("invalid order ID", "order_id", orderIDStr)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := uuid.Parse(groupIDStr)
+	if err != nil {
+		log.Debug("invalid group ID", "group_id", groupIDStr)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	groups, err := h.orderGroupRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		log.Error("cannot list order groups", "error", err, "order_id", orderID.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order groups")
+		return
+	}
+
+	var group, fallback *OrderGroup
+	for _, g := range groups {
+		if g.ID == groupID {
+			group = g
+		} else if g.IsDefault {
+			fallback = g
+		}
+	}
+	if group == nil {
+		apt.RespondError(w, http.StatusNotFound, "Order group not found")
+		return
+	}
+	if group.IsDefault {
+		apt.RespondError(w, http.StatusBadRequest, "The default group cannot be removed")
+		return
+	}
+
+	items, err := h.orderItemRepo.ListByGroup(ctx, groupID)
+	if err != nil {
+		log.Error("cannot list group items", "error", err, "group_id", groupID.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not retrieve order items")
+		return
+	}
+
+	for _, item := range items {
+		item.GroupID = nil
+		if fallback != nil {
+			id := fallback.ID
+			item.GroupID = &id
+		}
+		item.BeforeUpdate()
+		if err := h.orderItemRepo.Save(ctx, item); err != nil {
+			log.Error("cannot regroup order item", "error", err, "item_id", item.ID.String())
+			apt.RespondError(w, http.StatusInternalServerError, "Could not update order items")
+			return
+		}
+	}
+
+	if err := h.orderGroupRepo.Delete(ctx, groupID); err != nil {
+		log.Error("cannot delete order group", "error", err, "group_id", groupID.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not delete order group")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolveItemGroup validates a group reference from an item update. An empty
+// reference clears the group.
+func (h *Handler) resolveItemGroup(ctx context.Context, orderID uuid.UUID, ref string) (*uuid.UUID, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, true
+	}
+	groupID, err := uuid.Parse(ref)
+	if err != nil {
+		return nil, false
+	}
+	groups, err := h.orderGroupRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, false
+	}
+	for _, group := range groups {
+		if group.ID == groupID {
+			return &groupID, true
+		}
+	}
+	return nil, false
+}
+
 // Helper methods
 func (h *Handler) parseIDParam(w http.ResponseWriter, r *http.Request, log apt.Logger) (uuid.UUID, bool) {
 	idStr := chi.URLParam(r, "id")
@@ -597,21 +850,26 @@ type OrderUpdateRequest struct {
 }
 
 type OrderItemCreateRequest struct {
-	GroupID            *uuid.UUID `json:"group_id,omitempty"`
-	DishName           string     `json:"dish_name"`
-	Category           string     `json:"category"`
-	Quantity           int        `json:"quantity"`
-	Price              float64    `json:"price"`
-	Notes              string     `json:"notes,omitempty"`
-	MenuItemID         *uuid.UUID `json:"menu_item_id,omitempty"`
-	ProductionStation  *string    `json:"production_station,omitempty"`
-	RequiresProduction bool       `json:"requires_production"`
+	GroupID            *uuid.UUID  `json:"group_id,omitempty"`
+	DishName           string      `json:"dish_name"`
+	Category           string      `json:"category"`
+	Quantity           int         `json:"quantity"`
+	Price              float64     `json:"price"`
+	Notes              string      `json:"notes,omitempty"`
+	MenuItemID         *uuid.UUID  `json:"menu_item_id,omitempty"`
+	ProductionStation  *string     `json:"production_station,omitempty"`
+	RequiresProduction bool        `json:"requires_production"`
+	ManagerOverride    bool        `json:"manager_override,omitempty"`    // Skip menu visibility rules
+	ModifierOptionIDs  []uuid.UUID `json:"modifier_option_ids,omitempty"` // Resolved against the menu item
+	PortionID          *uuid.UUID  `json:"portion_id,omitempty"`          // Priced by the menu item portion
+	Course             Course      `json:"course,omitempty"`              // Number or name: starter, main, dessert
 }
 
 type OrderItemUpdateRequest struct {
 	Quantity *int    `json:"quantity,omitempty"`
 	Status   *string `json:"status,omitempty"`
 	Notes    *string `json:"notes,omitempty"`
+	GroupID  *string `json:"group_id,omitempty"`
 }
 
 type OrderGroupCreateRequest struct {
@@ -745,11 +1003,127 @@ func (h *Handler) ensureTableAllowsOrdering(ctx context.Context, tableID uuid.UU
 	}
 }
 
+// ErrMenuItemUnavailable is returned when the menu does not offer an item at
+// the time it is ordered.
+var ErrMenuItemUnavailable = errors.New("menu item is not available")
+
+// MenuItemAvailability is the menu service answer for a single item. MenuID
+// and MenuVersion name the published menu version it is ordered from.
+type MenuItemAvailability struct {
+	ShortCode   string     `json:"short_code"`
+	Active      bool       `json:"active"`
+	Visible     bool       `json:"visible"`
+	Available   bool       `json:"available"`
+	Timezone    string     `json:"timezone"`
+	MenuID      *uuid.UUID `json:"menu_id,omitempty"`
+	MenuVersion int        `json:"menu_version,omitempty"`
+	// RequiresModifiers is set when some modifier group needs a pick
+	RequiresModifiers bool `json:"requires_modifiers"`
+}
+
+// Err returns why the item cannot be ordered, or nil when it can.
+func (a MenuItemAvailability) Err() error {
+	if a.Available {
+		return nil
+	}
+	name := a.ShortCode
+	if name == "" {
+		name = "menu item"
+	}
+	if !a.Active {
+		return fmt.Errorf("%w: %s is inactive", ErrMenuItemUnavailable, name)
+	}
+	return fmt.Errorf("%w: %s is not offered at this time (%s)", ErrMenuItemUnavailable, name, a.Timezone)
+}
+
+// fetchMenuItemAvailability asks the menu service whether the item can be
+// ordered now. It returns nil when no menu service is configured.
+func (h *Handler) fetchMenuItemAvailability(ctx context.Context, menuItemID uuid.UUID) (*MenuItemAvailability, error) {
+	if h.menuClient == nil {
+		return nil, nil
+	}
+
+	path := fmt.Sprintf("/menu/items/%s/availability", menuItemID.String())
+	resp, err := h.menuClient.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var availability MenuItemAvailability
+	if err := decodeSuccessResponse(resp, &availability); err != nil {
+		return nil, err
+	}
+
+	return &availability, nil
+}
+
+// resolveMenuItemModifiers asks the menu service to check the picked options
+// against the item's modifier groups. The menu answers 422 when they break
+// the group rules.
+func (h *Handler) resolveMenuItemModifiers(ctx context.Context, menuItemID uuid.UUID, optionIDs []uuid.UUID) ([]OrderItemModifier, error) {
+	if h.menuClient == nil {
+		return nil, fmt.Errorf("menu service not configured")
+	}
+	if optionIDs == nil {
+		optionIDs = []uuid.UUID{}
+	}
+
+	path := fmt.Sprintf("/menu/items/%s/modifiers/resolve", menuItemID.String())
+	resp, err := h.menuClient.Request(ctx, "POST", path, map[string]interface{}{
+		"option_ids": optionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resolution struct {
+		Modifiers []OrderItemModifier `json:"modifiers"`
+	}
+	if err := decodeSuccessResponse(resp, &resolution); err != nil {
+		return nil, err
+	}
+
+	return resolution.Modifiers, nil
+}
+
+// MenuItemPortion is a portion as the menu service resolves it, priced in the
+// item currency.
+type MenuItemPortion struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Price        float64   `json:"price"`
+	CurrencyCode string    `json:"currency_code"`
+	PrepTime     int       `json:"prep_time"`
+}
+
+// fetchMenuItemPortion asks the menu service for the portion name, prep time
+// and unit price. The menu answers 404 for unknown portions and 422 for
+// portions that cannot be sold.
+func (h *Handler) fetchMenuItemPortion(ctx context.Context, menuItemID, portionID uuid.UUID) (*MenuItemPortion, error) {
+	if h.menuClient == nil {
+		return nil, fmt.Errorf("menu service not configured")
+	}
+
+	path := fmt.Sprintf("/menu/items/%s/portions/%s", menuItemID.String(), portionID.String())
+	resp, err := h.menuClient.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var portion MenuItemPortion
+	if err := decodeSuccessResponse(resp, &portion); err != nil {
+		return nil, err
+	}
+
+	return &portion, nil
+}
+
 func (h *Handler) publishOrderTableRejection(ctx context.Context, tableID uuid.UUID, orderID *uuid.UUID, action, reason, status string) {
 	if h.publisher == nil {
 		return
 	}
-	event := pkg.OrderTableRejectionEvent{
+	evt := pkg.OrderTableRejectionEvent{
+		EventID:    event.NewID(),
 		EventType:  pkg.EventOrderTableRejected,
 		TableID:    tableID.String(),
 		Action:     action,
@@ -758,9 +1132,9 @@ func (h *Handler) publishOrderTableRejection(ctx context.Context, tableID uuid.U
 		OccurredAt: time.Now().UTC(),
 	}
 	if orderID != nil {
-		event.OrderID = orderID.String()
+		evt.OrderID = orderID.String()
 	}
-	payload, err := json.Marshal(event)
+	payload, err := event.Marshal(ctx, eventSource, evt)
 	if err != nil {
 		h.logger.Error("cannot marshal order table rejection", "error", err, "table_id", tableID.String())
 		return
@@ -809,9 +1183,9 @@ func decodeSuccessResponse(resp *apt.SuccessResponse, target interface{}) error
 	return nil
 }
 
-func (h *Handler) publishOrderItemCreated(ctx context.Context, item *OrderItem, parentOrder *Order) {
+func (h *Handler) publishOrderItemCreated(ctx context.Context, item *OrderItem, parentOrder *Order) error {
 	if h.publisher == nil {
-		return
+		return nil
 	}
 
 	// Get table number for enrichment
@@ -831,6 +1205,7 @@ func (h *Handler) publishOrderItemCreated(ctx context.Context, item *OrderItem,
 	}
 
 	evt := event.OrderItemEvent{
+		EventID:            event.NewID(),
 		EventType:          event.EventOrderItemCreated,
 		OccurredAt:         time.Now().UTC(),
 		OrderID:            item.OrderID.String(),
@@ -852,17 +1227,41 @@ func (h *Handler) publishOrderItemCreated(ctx context.Context, item *OrderItem,
 	if parentOrder != nil {
 		evt.TableID = parentOrder.TableID.String()
 	}
+	evt.Modifiers = eventModifiers(item.Modifiers)
+	if item.PortionID != nil {
+		evt.PortionID = item.PortionID.String()
+		evt.PortionName = item.PortionName
+		evt.PrepTimeMinutes = item.PrepTime
+	}
+	evt.Course = int(item.Course)
+	evt.Held = item.Held
 
-	payload, err := json.Marshal(evt)
+	payload, err := event.Marshal(ctx, eventSource, evt)
 	if err != nil {
-		h.logger.Error("cannot marshal order item created event", "error", err)
-		return
+		return fmt.Errorf("cannot marshal order item created event: %w", err)
 	}
 	if err := h.publisher.Publish(ctx, event.OrderItemsTopic, payload); err != nil {
-		h.logger.Error("cannot publish order item created event", "error", err)
-	} else {
-		h.logger.Info("published order item created event", "order_item_id", item.ID.String())
+		return fmt.Errorf("cannot publish order item created event: %w", err)
+	}
+	h.logger.Info("published order item created event", "order_item_id", item.ID.String())
+	return nil
+}
+
+// eventModifiers converts the picked modifiers for the order item events.
+func eventModifiers(modifiers []OrderItemModifier) []event.OrderItemModifier {
+	if len(modifiers) == 0 {
+		return nil
+	}
+	result := make([]event.OrderItemModifier, len(modifiers))
+	for i, m := range modifiers {
+		result[i] = event.OrderItemModifier{
+			GroupID:    m.GroupID.String(),
+			GroupName:  m.GroupName,
+			OptionID:   m.OptionID.String(),
+			OptionName: m.OptionName,
+		}
 	}
+	return result
 }
 
 // MarkItemDelivered marks an order item as delivered
@@ -889,7 +1288,18 @@ func (h *Handler) MarkItemDelivered(w http.ResponseWriter, r *http.Request) {
 	previousStatus := item.Status
 	item.MarkAsDelivered()
 
-	if err := h.orderItemRepo.Save(ctx, item); err != nil {
+	// The kitchen moves the ticket on the status change, so it is saved
+	// with the item
+	err = outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.orderItemRepo.Save(ctx, item); err != nil {
+			return err
+		}
+		if item.RequiresProduction {
+			return h.publishOrderItemStatus(ctx, event.EventOrderItemStatusChanged, item, previousStatus)
+		}
+		return nil
+	})
+	if err != nil {
 		log.Error("cannot update order item", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not mark item as delivered")
 		return
@@ -897,21 +1307,24 @@ func (h *Handler) MarkItemDelivered(w http.ResponseWriter, r *http.Request) {
 
 	// Broadcast the status change to gRPC stream subscribers (operations service, etc.)
 	if h.streamServer != nil {
-		h.streamServer.BroadcastOrderItemEvent(item, "order.item.status_changed", previousStatus)
-	}
-
-	// Publish NATS event for kitchen service to update ticket status
-	if item.RequiresProduction {
-		h.publishOrderItemStatusChange(ctx, item, previousStatus)
+		h.streamServer.BroadcastOrderItemEvent(item, event.EventOrderItemStatusChanged, previousStatus)
 	}
 
 	log.Info("order item marked as delivered", "item_id", itemID)
+	h.fireNextCourse(ctx, item.OrderID, log)
 	apt.Respond(w, http.StatusOK, item, nil)
 }
 
-func (h *Handler) publishOrderItemStatusChange(ctx context.Context, item *OrderItem, previousStatus string) {
+// publishOrderItemStatus tells the kitchen an item moved to its current
+// status, with eventType saying how.
+func (h *Handler) publishOrderItemStatus(ctx context.Context, eventType string, item *OrderItem, previousStatus string) error {
+	if h.publisher == nil {
+		return nil
+	}
+
 	evt := event.OrderItemEvent{
-		EventType:          "order.item.status_changed",
+		EventID:            event.NewID(),
+		EventType:          eventType,
 		OccurredAt:         time.Now().UTC(),
 		OrderID:            item.OrderID.String(),
 		OrderItemID:        item.ID.String(),
@@ -920,17 +1333,15 @@ func (h *Handler) publishOrderItemStatusChange(ctx context.Context, item *OrderI
 		RequiresProduction: item.RequiresProduction,
 	}
 
-	payload, err := json.Marshal(evt)
+	payload, err := event.Marshal(ctx, eventSource, evt)
 	if err != nil {
-		h.logger.Error("cannot marshal order item status change event", "error", err)
-		return
+		return fmt.Errorf("cannot marshal %s event: %w", eventType, err)
 	}
-
 	if err := h.publisher.Publish(ctx, event.OrderItemsTopic, payload); err != nil {
-		h.logger.Error("cannot publish order item status change event", "error", err)
-	} else {
-		h.logger.Info("published order item status change event", "order_item_id", item.ID.String(), "status", item.Status)
+		return fmt.Errorf("cannot publish %s event: %w", eventType, err)
 	}
+	h.logger.Info("published order item status event", "event_type", eventType, "order_item_id", item.ID.String(), "status", item.Status)
+	return nil
 }
 
 // CancelItem cancels an order item
@@ -954,18 +1365,81 @@ func (h *Handler) CancelItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	previousStatus := item.Status
 	item.Cancel()
 
-	if err := h.orderItemRepo.Save(ctx, item); err != nil {
+	// The cancellation reaches the kitchen with the item, so the line does
+	// not keep cooking it
+	err = outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.orderItemRepo.Save(ctx, item); err != nil {
+			return err
+		}
+		if item.RequiresProduction {
+			return h.publishOrderItemStatus(ctx, event.EventOrderItemCancelled, item, previousStatus)
+		}
+		return nil
+	})
+	if err != nil {
 		log.Error("cannot cancel order item", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not cancel item")
 		return
 	}
 
 	log.Info("order item cancelled", "item_id", itemID)
+	h.fireNextCourse(ctx, item.OrderID, log)
 	apt.Respond(w, http.StatusOK, item, nil)
 }
 
+// FireCourse sends the held items of a course to the kitchen via
+// POST /orders/{id}/courses/{course}/fire. POST /orders/{id}/fire sends every
+// held course at once.
+func (h *Handler) FireCourse(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.FireCourse")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apt.RespondError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	course, err := ParseCourse(chi.URLParam(r, "course"))
+	if err != nil {
+		apt.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	parentOrder, err := h.orderRepo.Get(ctx, orderID)
+	if err != nil || parentOrder == nil {
+		apt.RespondError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	fired, err := h.courses.Fire(ctx, orderID, course)
+	if err != nil {
+		log.Error("cannot fire course", "error", err, "order_id", orderID.String(), "course", int(course))
+		apt.RespondError(w, http.StatusInternalServerError, "Could not fire course")
+		return
+	}
+
+	log.Info("course fired", "order_id", orderID.String(), "course", int(course), "items", len(fired))
+	apt.Respond(w, http.StatusOK, map[string]interface{}{
+		"order_id": orderID,
+		"course":   course,
+		"fired":    fired,
+	}, nil)
+}
+
+// fireNextCourse fires the next held course once the one before is served.
+func (h *Handler) fireNextCourse(ctx context.Context, orderID uuid.UUID, log apt.Logger) {
+	if _, err := h.courses.FireNextIfDue(ctx, orderID); err != nil {
+		log.Error("cannot fire next course", "error", err, "order_id", orderID.String())
+	}
+}
+
 // updateKitchenTicketStatus updates the kitchen ticket status via Kitchen service
 // This is called when the waiter manually marks an item as delivered
 func (h *Handler) updateKitchenTicketStatus(ctx context.Context, orderItemID uuid.UUID, statusID string, log apt.Logger) {
@@ -1082,6 +1556,20 @@ func (h *Handler) CloseOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Payments were taken against the bill with the pending items on it;
+	// closing cancels them, so the order closes on the bill without them
+	current, err := h.computeBill(ctx, order, items)
+	if err != nil {
+		log.Error("cannot compute bill", "error", err, "order_id", id.String())
+		apt.RespondError(w, http.StatusInternalServerError, "Could not compute bill")
+		return
+	}
+	bill := h.billing.Compute(order, afterClose(items), current.Payments)
+	if !bill.Settled {
+		apt.RespondError(w, http.StatusConflict, fmt.Sprintf("Order has an outstanding balance of %.2f", bill.Balance))
+		return
+	}
+
 	// Auto-cancel pending items
 	for _, item := range pendingItems {
 		item.Cancel()
@@ -1110,9 +1598,20 @@ func (h *Handler) CloseOrder(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Close the order
+	// Close the order. The bill is settled here when dropping the pending
+	// items clears its balance; otherwise the payment that settled it
+	// already said so
 	order.Close()
-	if err := h.orderRepo.Save(ctx, order); err != nil {
+	err = outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.orderRepo.Save(ctx, order); err != nil {
+			return err
+		}
+		if !current.Settled {
+			return h.publishBillSettled(ctx, bill)
+		}
+		return nil
+	})
+	if err != nil {
 		log.Error("cannot close order", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not close order")
 		return
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/appetiteclub/appetite/pkg/event"
 	"github.com/appetiteclub/apt"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -269,6 +272,42 @@ func TestHandlerListOrders(t *testing.T) {
 			setupRepo:      func(repo *MockOrderRepo) {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:        "filterByIDPrefix",
+			queryParams: "?id_prefix=550E8400-e29b",
+			setupRepo: func(repo *MockOrderRepo) {
+				repo.orders[orderID] = &Order{
+					ID:      orderID,
+					TableID: tableID,
+					Status:  "pending",
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "shortIDPrefix",
+			queryParams:    "?id_prefix=550",
+			setupRepo:      func(repo *MockOrderRepo) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalidIDPrefix",
+			queryParams:    "?id_prefix=table5",
+			setupRepo:      func(repo *MockOrderRepo) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "openOnly",
+			queryParams: "?open=true",
+			setupRepo: func(repo *MockOrderRepo) {
+				repo.orders[orderID] = &Order{
+					ID:      orderID,
+					TableID: tableID,
+					Status:  "pending",
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -294,6 +333,55 @@ func TestHandlerListOrders(t *testing.T) {
 	}
 }
 
+func TestHandlerListOrdersSummary(t *testing.T) {
+	openID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440055")
+	closedID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440056")
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440053")
+
+	orderRepo := NewMockOrderRepo()
+	orderRepo.orders[openID] = &Order{ID: openID, TableID: tableID, Status: "pending"}
+	orderRepo.orders[closedID] = &Order{ID: closedID, TableID: tableID, Status: "closed"}
+
+	itemRepo := NewMockOrderItemRepo()
+	for _, item := range []*OrderItem{
+		{ID: uuid.New(), OrderID: openID, Quantity: 2, Price: 4.5, Status: "pending"},
+		{ID: uuid.New(), OrderID: openID, Quantity: 1, Price: 3, Status: "cancelled"},
+		{ID: uuid.New(), OrderID: closedID, Quantity: 1, Price: 10, Status: "delivered"},
+	} {
+		itemRepo.items[item.ID] = item
+	}
+
+	deps := HandlerDeps{
+		Repos: Repos{
+			OrderRepo:     orderRepo,
+			OrderItemRepo: itemRepo,
+		},
+	}
+	h := NewHandler(deps, apt.NewConfig(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders?open=true&summary=true", nil)
+	w := httptest.NewRecorder()
+	h.ListOrders(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("ListOrders() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Data []OrderSummary `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(response.Data) != 1 {
+		t.Fatalf("got %d orders, want the open one only", len(response.Data))
+	}
+	got := response.Data[0]
+	if got.ID != openID || got.ItemCount != 2 || got.Subtotal != 9 {
+		t.Errorf("summary = {ID: %s, ItemCount: %d, Subtotal: %v}, want {%s, 2, 9}", got.ID, got.ItemCount, got.Subtotal, openID)
+	}
+}
+
 func TestHandlerDeleteOrder(t *testing.T) {
 	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440054")
 
@@ -510,6 +598,8 @@ func TestHandlerUpdateOrderStatus(t *testing.T) {
 func TestHandlerCreateOrderItem(t *testing.T) {
 	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440060")
 	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440061")
+	menuItemID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440721")
+	portionID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440725")
 
 	tests := []struct {
 		name           string
@@ -581,6 +671,72 @@ func TestHandlerCreateOrderItem(t *testing.T) {
 			setupCache:     func(cache *TableStateCache) {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:    "modifiersWithoutMenuItem",
+			orderID: orderID.String(),
+			body: OrderItemCreateRequest{
+				DishName:          "Burger",
+				Quantity:          1,
+				ModifierOptionIDs: []uuid.UUID{uuid.MustParse("550e8400-e29b-41d4-a716-446655440720")},
+			},
+			setupRepos: func(orderRepo *MockOrderRepo, itemRepo *MockOrderItemRepo) {
+				orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+			},
+			setupCache: func(cache *TableStateCache) {
+				cache.Set(tableID, "open")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "modifiersWithoutMenuService",
+			orderID: orderID.String(),
+			body: OrderItemCreateRequest{
+				DishName:          "Burger",
+				Quantity:          1,
+				MenuItemID:        &menuItemID,
+				ModifierOptionIDs: []uuid.UUID{uuid.MustParse("550e8400-e29b-41d4-a716-446655440720")},
+			},
+			setupRepos: func(orderRepo *MockOrderRepo, itemRepo *MockOrderItemRepo) {
+				orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+			},
+			setupCache: func(cache *TableStateCache) {
+				cache.Set(tableID, "open")
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:    "portionWithoutMenuItem",
+			orderID: orderID.String(),
+			body: OrderItemCreateRequest{
+				DishName:  "Burger",
+				Quantity:  1,
+				PortionID: &portionID,
+			},
+			setupRepos: func(orderRepo *MockOrderRepo, itemRepo *MockOrderItemRepo) {
+				orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+			},
+			setupCache: func(cache *TableStateCache) {
+				cache.Set(tableID, "open")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "portionWithoutMenuService",
+			orderID: orderID.String(),
+			body: OrderItemCreateRequest{
+				DishName:   "Burger",
+				Quantity:   1,
+				MenuItemID: &menuItemID,
+				PortionID:  &portionID,
+			},
+			setupRepos: func(orderRepo *MockOrderRepo, itemRepo *MockOrderItemRepo) {
+				orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+			},
+			setupCache: func(cache *TableStateCache) {
+				cache.Set(tableID, "open")
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
 	}
 
 	for _, tt := range tests {
@@ -2268,6 +2424,77 @@ func TestHandlerCreateOrderItemWithPublisher(t *testing.T) {
 	}
 }
 
+func TestHandlerCreateOrderItemSavesEventWithItem(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440122")
+	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440123")
+
+	tests := []struct {
+		name       string
+		publishErr error
+		wantStatus int
+		wantEvents int
+	}{
+		{name: "item and event saved together", wantStatus: http.StatusCreated, wantEvents: 1},
+		{name: "event cannot be stored", publishErr: errors.New("outbox unavailable"), wantStatus: http.StatusInternalServerError, wantEvents: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepo := NewMockOrderRepo()
+			orderRepo.orders[orderID] = &Order{ID: orderID, TableID: tableID, Status: "pending"}
+
+			createdInTransaction := false
+			itemRepo := NewMockOrderItemRepo()
+			itemRepo.CreateFunc = func(ctx context.Context, item *OrderItem) error {
+				createdInTransaction = ctx.Value(mockTransactionKey{}) != nil
+				return nil
+			}
+
+			cache := NewTableStateCache(nil, nil)
+			cache.Set(tableID, "open")
+
+			publisher := &MockTransactionalPublisher{}
+			publisher.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
+				return tt.publishErr
+			}
+
+			deps := HandlerDeps{
+				Repos: Repos{
+					OrderRepo:     orderRepo,
+					OrderItemRepo: itemRepo,
+				},
+				Publisher:        publisher,
+				TableStatesCache: cache,
+			}
+			h := NewHandler(deps, apt.NewConfig(), nil)
+
+			body, _ := json.Marshal(OrderItemCreateRequest{
+				DishName:           "Test Dish",
+				Quantity:           1,
+				RequiresProduction: true,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID.String()+"/items", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("orderID", orderID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.CreateOrderItem(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("CreateOrderItem() status = %d, want %d, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if publisher.Transactions != 1 || !createdInTransaction {
+				t.Errorf("CreateOrderItem() should create the item in a transaction, ran %d", publisher.Transactions)
+			}
+			if len(publisher.Published) != tt.wantEvents {
+				t.Errorf("CreateOrderItem() kept %d events, want %d", len(publisher.Published), tt.wantEvents)
+			}
+		})
+	}
+}
+
 func TestHandlerCreateOrderItemTableRejection(t *testing.T) {
 	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440122")
 	tableID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440123")
@@ -3021,6 +3248,48 @@ func TestHandlerCancelItemSuccess(t *testing.T) {
 	}
 }
 
+func TestHandlerCancelItemTellsKitchen(t *testing.T) {
+	itemID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440164")
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440165")
+
+	itemRepo := NewMockOrderItemRepo()
+	itemRepo.items[itemID] = &OrderItem{ID: itemID, OrderID: orderID, Status: "preparing", RequiresProduction: true}
+
+	var eventType string
+	publisher := &MockTransactionalPublisher{}
+	publisher.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
+		env, err := event.Open(msg)
+		if err != nil {
+			return err
+		}
+		eventType = env.Type
+		return nil
+	}
+
+	deps := HandlerDeps{
+		Repos: Repos{
+			OrderItemRepo: itemRepo,
+		},
+		Publisher: publisher,
+	}
+	h := NewHandler(deps, apt.NewConfig(), nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/order-items/"+itemID.String()+"/cancel", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", itemID.String())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	w := httptest.NewRecorder()
+	h.CancelItem(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("CancelItem() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if len(publisher.Published) != 1 || eventType != event.EventOrderItemCancelled {
+		t.Errorf("CancelItem() published %v (%q), want one %s event", publisher.Published, eventType, event.EventOrderItemCancelled)
+	}
+}
+
 func TestHandlerCancelItemInvalidID(t *testing.T) {
 	itemRepo := NewMockOrderItemRepo()
 
@@ -3376,6 +3645,39 @@ func TestHandlerPublishOrderItemCreatedWithMenuItemID(t *testing.T) {
 	}
 }
 
+func TestHandlerPublishOrderItemCreatedWithPortion(t *testing.T) {
+	publisher := NewMockPublisher()
+	var published event.OrderItemEvent
+	publisher.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
+		return unmarshalEvent(msg, &published)
+	}
+
+	h := NewHandler(HandlerDeps{Publisher: publisher}, apt.NewConfig(), nil)
+
+	portionID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440726")
+	item := &OrderItem{
+		ID:          uuid.MustParse("550e8400-e29b-41d4-a716-446655440727"),
+		OrderID:     uuid.MustParse("550e8400-e29b-41d4-a716-446655440728"),
+		DishName:    "Paella",
+		Quantity:    1,
+		PortionID:   &portionID,
+		PortionName: "Half",
+		PrepTime:    20,
+	}
+
+	h.publishOrderItemCreated(context.Background(), item, &Order{ID: item.OrderID})
+
+	if published.PortionID != portionID.String() {
+		t.Errorf("PortionID = %q, want %q", published.PortionID, portionID.String())
+	}
+	if published.PortionName != "Half" {
+		t.Errorf("PortionName = %q, want %q", published.PortionName, "Half")
+	}
+	if published.PrepTimeMinutes != 20 {
+		t.Errorf("PrepTimeMinutes = %d, want 20", published.PrepTimeMinutes)
+	}
+}
+
 func TestHandlerPublishOrderItemCreatedPublishError(t *testing.T) {
 	publisher := NewMockPublisher()
 	publisher.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
@@ -3403,7 +3705,7 @@ func TestHandlerPublishOrderItemCreatedPublishError(t *testing.T) {
 	h.publishOrderItemCreated(context.Background(), item, parentOrder)
 }
 
-func TestHandlerPublishOrderItemStatusChangePublishError(t *testing.T) {
+func TestHandlerPublishOrderItemStatusPublishError(t *testing.T) {
 	publisher := NewMockPublisher()
 	publisher.PublishFunc = func(ctx context.Context, topic string, msg []byte) error {
 		return fmt.Errorf("publish error")
@@ -3421,8 +3723,10 @@ func TestHandlerPublishOrderItemStatusChangePublishError(t *testing.T) {
 		RequiresProduction: true,
 	}
 
-	// Should not panic on publish error
-	h.publishOrderItemStatusChange(context.Background(), item, "pending")
+	err := h.publishOrderItemStatus(context.Background(), event.EventOrderItemStatusChanged, item, "pending")
+	if err == nil {
+		t.Error("publishOrderItemStatus() should return the publish error")
+	}
 }
 
 func TestHandlerCloseOrderListItemsError(t *testing.T) {
@@ -3469,7 +3773,7 @@ func TestHandlerMarkItemDeliveredWithGRPCBroadcast(t *testing.T) {
 	}
 	itemRepo.items[itemID] = item
 
-	streamServer := NewOrderEventStreamServer(nil, apt.NewNoopLogger())
+	streamServer := NewOrderEventStreamServer(nil, nil, nil, apt.NewNoopLogger())
 
 	deps := HandlerDeps{
 		Repos: Repos{
@@ -3775,3 +4079,165 @@ func TestHandlerEnsureTableAllowsOrderingEmptyStatus(t *testing.T) {
 	}
 }
 
+
+func TestHandlerUpdateOrderItemGroup(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440500")
+	groupID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440501")
+	otherGroupID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440502")
+	itemID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440503")
+
+	tests := []struct {
+		name           string
+		groupID        string
+		expectedStatus int
+		wantGroup      *uuid.UUID
+	}{
+		{name: "moveToGroup", groupID: groupID.String(), expectedStatus: http.StatusOK, wantGroup: &groupID},
+		{name: "clearGroup", groupID: "", expectedStatus: http.StatusOK},
+		{name: "groupOfAnotherOrder", groupID: otherGroupID.String(), expectedStatus: http.StatusBadRequest},
+		{name: "invalidGroupID", groupID: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			itemRepo := NewMockOrderItemRepo()
+			groupRepo := NewMockOrderGroupRepo()
+			groupRepo.groups[groupID] = &OrderGroup{ID: groupID, OrderID: orderID, Name: "Ana"}
+			groupRepo.groups[otherGroupID] = &OrderGroup{ID: otherGroupID, OrderID: uuid.New(), Name: "Ben"}
+			itemRepo.items[itemID] = &OrderItem{ID: itemID, OrderID: orderID, GroupID: &otherGroupID, Status: "pending"}
+
+			h := NewHandler(HandlerDeps{
+				Repos: Repos{OrderItemRepo: itemRepo, OrderGroupRepo: groupRepo},
+			}, apt.NewConfig(), nil)
+
+			body, _ := json.Marshal(OrderItemUpdateRequest{GroupID: &tt.groupID})
+			req := httptest.NewRequest(http.MethodPut, "/order-items/"+itemID.String(), bytes.NewReader(body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", itemID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.UpdateOrderItem(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("UpdateOrderItem() status = %d, want %d", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			got := itemRepo.items[itemID].GroupID
+			if (got == nil) != (tt.wantGroup == nil) || (got != nil && *got != *tt.wantGroup) {
+				t.Errorf("GroupID = %v, want %v", got, tt.wantGroup)
+			}
+		})
+	}
+}
+
+func TestHandlerDeleteOrderGroup(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440510")
+	defaultID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440511")
+	groupID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440512")
+	itemID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440513")
+
+	tests := []struct {
+		name           string
+		orderID        string
+		groupID        string
+		expectedStatus int
+	}{
+		{name: "removeGroup", orderID: orderID.String(), groupID: groupID.String(), expectedStatus: http.StatusNoContent},
+		{name: "defaultGroup", orderID: orderID.String(), groupID: defaultID.String(), expectedStatus: http.StatusBadRequest},
+		{name: "groupNotFound", orderID: orderID.String(), groupID: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		{name: "invalidGroupID", orderID: orderID.String(), groupID: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "invalidOrderID", orderID: "not-a-uuid", groupID: groupID.String(), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			itemRepo := NewMockOrderItemRepo()
+			groupRepo := NewMockOrderGroupRepo()
+			groupRepo.groups[defaultID] = &OrderGroup{ID: defaultID, OrderID: orderID, Name: "Table", IsDefault: true}
+			groupRepo.groups[groupID] = &OrderGroup{ID: groupID, OrderID: orderID, Name: "Ana"}
+			itemRepo.items[itemID] = &OrderItem{ID: itemID, OrderID: orderID, GroupID: &groupID, Status: "pending"}
+
+			h := NewHandler(HandlerDeps{
+				Repos: Repos{OrderItemRepo: itemRepo, OrderGroupRepo: groupRepo},
+			}, apt.NewConfig(), nil)
+
+			req := httptest.NewRequest(http.MethodDelete, "/orders/"+tt.orderID+"/groups/"+tt.groupID, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("orderID", tt.orderID)
+			rctx.URLParams.Add("groupID", tt.groupID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.DeleteOrderGroup(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("DeleteOrderGroup() status = %d, want %d, body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusNoContent {
+				return
+			}
+			if _, ok := groupRepo.groups[groupID]; ok {
+				t.Error("group should be deleted")
+			}
+			if got := itemRepo.items[itemID].GroupID; got == nil || *got != defaultID {
+				t.Errorf("item GroupID = %v, want the default group %s", got, defaultID)
+			}
+		})
+	}
+}
+
+func TestMenuItemAvailabilityErr(t *testing.T) {
+	tests := []struct {
+		name         string
+		availability MenuItemAvailability
+		wantErr      bool
+		wantContains string
+	}{
+		{name: "available", availability: MenuItemAvailability{ShortCode: "EGGS-001", Active: true, Visible: true, Available: true}},
+		{name: "inactive", availability: MenuItemAvailability{ShortCode: "EGGS-001", Visible: true}, wantErr: true, wantContains: "inactive"},
+		{name: "outsideWindow", availability: MenuItemAvailability{ShortCode: "EGGS-001", Active: true, Timezone: "Europe/Madrid"}, wantErr: true, wantContains: "not offered at this time (Europe/Madrid)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.availability.Err()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Err() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, ErrMenuItemUnavailable) {
+				t.Errorf("Err() = %v, want it to wrap ErrMenuItemUnavailable", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantContains) {
+				t.Errorf("Err() = %q, want it to contain %q", err.Error(), tt.wantContains)
+			}
+		})
+	}
+}
+
+func TestEventModifiers(t *testing.T) {
+	groupID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440722")
+	optionID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440723")
+
+	if got := eventModifiers(nil); got != nil {
+		t.Errorf("eventModifiers(nil) = %v, want nil", got)
+	}
+
+	got := eventModifiers([]OrderItemModifier{
+		{GroupID: groupID, GroupName: "Cooking point", OptionID: optionID, OptionName: "Medium rare", PriceDelta: 0},
+	})
+	if len(got) != 1 {
+		t.Fatalf("eventModifiers() len = %d, want 1", len(got))
+	}
+	if got[0].GroupID != groupID.String() || got[0].OptionID != optionID.String() {
+		t.Errorf("eventModifiers() ids = %q/%q, want %q/%q", got[0].GroupID, got[0].OptionID, groupID, optionID)
+	}
+	if got[0].Label() != "Cooking point: Medium rare" {
+		t.Errorf("Label() = %q, want %q", got[0].Label(), "Cooking point: Medium rare")
+	}
+}
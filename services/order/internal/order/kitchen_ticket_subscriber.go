@@ -3,9 +3,12 @@ package order
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/idempotency"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
 	"github.com/google/uuid"
@@ -15,6 +18,8 @@ type KitchenTicketSubscriber struct {
 	subscriber    events.Subscriber
 	orderItemRepo OrderItemRepo
 	streamServer  *OrderEventStreamServer
+	courses       *CourseFirer
+	processed     idempotency.Store
 	logger        apt.Logger
 }
 
@@ -34,30 +39,47 @@ func (s *KitchenTicketSubscriber) SetStreamServer(streamServer *OrderEventStream
 	s.streamServer = streamServer
 }
 
+// SetCourseFirer sets the firer that sends the next course once the kitchen
+// reports the previous one served
+func (s *KitchenTicketSubscriber) SetCourseFirer(courses *CourseFirer) {
+	s.courses = courses
+}
+
+// SetIdempotencyStore sets the store of processed event IDs, so a redelivered
+// kitchen event is not applied twice
+func (s *KitchenTicketSubscriber) SetIdempotencyStore(store idempotency.Store) {
+	s.processed = store
+}
+
 func (s *KitchenTicketSubscriber) Start(ctx context.Context) error {
 	s.log().Info("starting kitchen ticket subscriber", "topic", event.KitchenTicketsTopic)
 	if s.subscriber == nil {
 		return fmt.Errorf("kitchen ticket subscriber not configured")
 	}
-	return s.subscriber.Subscribe(ctx, event.KitchenTicketsTopic, s.handleEvent)
+	return s.subscriber.Subscribe(ctx, event.KitchenTicketsTopic, idempotency.Handler(s.processed, s.log(), s.handleEvent))
 }
 
 func (s *KitchenTicketSubscriber) handleEvent(ctx context.Context, msg []byte) error {
-	// Parse base metadata to determine event type
-	var metadata event.KitchenTicketEventMetadata
-	if err := json.Unmarshal(msg, &metadata); err != nil {
+	env, err := event.Open(msg)
+	if errors.Is(err, event.ErrNotEvent) {
 		s.log().Info("invalid kitchen ticket event", "error", err)
 		return nil
 	}
+	if err != nil {
+		return err
+	}
+	ctx = event.WithCause(ctx, env)
 
-	switch metadata.EventType {
+	switch env.Type {
 	case event.EventKitchenTicketStatusChange:
-		return s.handleStatusChange(ctx, msg)
+		return s.handleStatusChange(ctx, env.Payload)
+	case event.EventKitchenDecisionResolved:
+		return s.handleDecisionResolved(ctx, env.Payload)
 	case event.EventKitchenTicketCreated:
 		// We don't need to handle ticket creation - it was triggered by OrderItem creation
 		return nil
 	default:
-		s.log().Debug("unknown kitchen ticket event type", "event_type", metadata.EventType)
+		s.log().Debug("unknown kitchen ticket event type", "event_type", env.Type)
 		return nil
 	}
 }
@@ -127,14 +149,95 @@ func (s *KitchenTicketSubscriber) handleStatusChange(ctx context.Context, msg []
 
 	// Broadcast the status change to gRPC stream subscribers
 	if s.streamServer != nil {
-		s.streamServer.BroadcastOrderItemEvent(orderItem, "order.item.status_changed", oldStatus)
+		s.streamServer.BroadcastOrderItemEvent(orderItem, event.EventOrderItemStatusChanged, oldStatus)
 	} else {
 		s.logger.Info("streamServer is nil, cannot broadcast event", "order_item_id", orderItemID)
 	}
 
+	if s.courses != nil && (newStatus == "delivered" || newStatus == "cancelled") {
+		if _, err := s.courses.FireNextIfDue(ctx, orderItem.OrderID); err != nil {
+			s.logger.Info("cannot fire next course", "order_id", orderItem.OrderID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// handleDecisionResolved applies the floor's answer to a kitchen question to
+// the order item: a substitute changes the dish and, when it costs something
+// else, the price; the chosen option's note is added to the item notes. A
+// cancel arrives as its own status change.
+func (s *KitchenTicketSubscriber) handleDecisionResolved(ctx context.Context, msg []byte) error {
+	var evt event.KitchenTicketDecisionEvent
+	if err := json.Unmarshal(msg, &evt); err != nil {
+		s.log().Info("invalid decision event", "error", err)
+		return nil
+	}
+	if evt.Chosen == nil || evt.Chosen.Outcome == event.DecisionOutcomeCancel {
+		return nil
+	}
+
+	orderItemID, err := uuid.Parse(evt.OrderItemID)
+	if err != nil {
+		s.logger.Info("invalid order_item_id in event", "order_item_id", evt.OrderItemID)
+		return nil
+	}
+
+	orderItem, err := s.orderItemRepo.Get(ctx, orderItemID)
+	if err != nil {
+		s.logger.Info("cannot find order item for ticket", "order_item_id", orderItemID, "error", err)
+		return nil
+	}
+
+	chosen := evt.Chosen
+	if chosen.Outcome == event.DecisionOutcomeSubstitute {
+		menuItemID, err := uuid.Parse(chosen.MenuItemID)
+		if err != nil {
+			s.logger.Info("invalid substitute in decision", "menu_item_id", chosen.MenuItemID)
+			return nil
+		}
+		orderItem.Notes = appendItemNote(orderItem.Notes, "Instead of "+orderItem.DishName)
+		orderItem.MenuItemID = &menuItemID
+		if chosen.MenuItemName != "" {
+			orderItem.DishName = chosen.MenuItemName
+		}
+		if chosen.Price != nil {
+			orderItem.Price = *chosen.Price
+		}
+	}
+	orderItem.Notes = appendItemNote(orderItem.Notes, chosen.Note)
+	orderItem.BeforeUpdate()
+
+	if err := s.orderItemRepo.Save(ctx, orderItem); err != nil {
+		s.logger.Info("failed to apply kitchen decision", "order_item_id", orderItemID, "error", err)
+		return err
+	}
+
+	s.logger.Info("kitchen decision applied to order item",
+		"order_item_id", orderItemID,
+		"outcome", chosen.Outcome,
+		"answered_by", evt.AnsweredBy,
+		"ticket_id", evt.TicketID,
+	)
+
+	if s.streamServer != nil {
+		s.streamServer.BroadcastOrderItemEvent(orderItem, event.EventOrderItemUpdated, orderItem.Status)
+	}
 	return nil
 }
 
+func appendItemNote(notes, note string) string {
+	note = strings.TrimSpace(note)
+	switch {
+	case note == "":
+		return notes
+	case notes == "":
+		return note
+	default:
+		return notes + "; " + note
+	}
+}
+
 // mapKitchenStatusToOrderStatus maps kitchen ticket status codes to order item status strings
 func (s *KitchenTicketSubscriber) mapKitchenStatusToOrderStatus(kitchenStatus string) string {
 	// Kitchen status codes from kitchen service:
@@ -3,8 +3,10 @@ package order
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/appetiteclub/appetite/pkg/event"
 	"github.com/appetiteclub/apt/events"
 	"github.com/google/uuid"
 )
@@ -25,6 +27,38 @@ func (m *MockPublisher) Publish(ctx context.Context, topic string, msg []byte) e
 	return nil
 }
 
+// MockTransactionalPublisher is a mock publisher that saves a change and its
+// events together, like the outbox. Changes made through the context passed
+// to the transaction are only kept when it commits.
+type MockTransactionalPublisher struct {
+	MockPublisher
+	Transactions int
+	Published    []string
+}
+
+type mockTransactionKey struct{}
+
+func (m *MockTransactionalPublisher) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Transactions++
+	pending := len(m.Published)
+	if err := fn(context.WithValue(ctx, mockTransactionKey{}, true)); err != nil {
+		m.Published = m.Published[:pending]
+		return err
+	}
+	return nil
+}
+
+func (m *MockTransactionalPublisher) Publish(ctx context.Context, topic string, msg []byte) error {
+	if ctx.Value(mockTransactionKey{}) == nil {
+		return fmt.Errorf("%s published outside the transaction", topic)
+	}
+	if err := m.MockPublisher.Publish(ctx, topic, msg); err != nil {
+		return err
+	}
+	m.Published = append(m.Published, topic)
+	return nil
+}
+
 // MockSubscriber is a mock implementation of events.Subscriber for testing
 type MockSubscriber struct {
 	SubscribeFunc func(ctx context.Context, topic string, handler events.HandlerFunc) error
@@ -73,11 +107,8 @@ func (m *MockOrderRepo) Get(ctx context.Context, id uuid.UUID) (*Order, error) {
 	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	order, ok := m.orders[id]
-	if !ok {
-		return nil, fmt.Errorf("order not found")
-	}
-	return order, nil
+	// Like the Mongo repo, a missing order is not an error
+	return m.orders[id], nil
 }
 
 func (m *MockOrderRepo) List(ctx context.Context) ([]*Order, error) {
@@ -114,6 +145,30 @@ func (m *MockOrderRepo) ListByStatus(ctx context.Context, status string) ([]*Ord
 	return result, nil
 }
 
+func (m *MockOrderRepo) ListOpen(ctx context.Context) ([]*Order, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*Order
+	for _, o := range m.orders {
+		if o.IsOpen() {
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockOrderRepo) ListByIDPrefix(ctx context.Context, prefix string) ([]*Order, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*Order
+	for _, o := range m.orders {
+		if strings.HasPrefix(strings.ReplaceAll(o.ID.String(), "-", ""), prefix) {
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
 func (m *MockOrderRepo) Save(ctx context.Context, order *Order) error {
 	if m.SaveFunc != nil {
 		return m.SaveFunc(ctx, order)
@@ -185,6 +240,21 @@ func (m *MockOrderItemRepo) ListByOrder(ctx context.Context, orderID uuid.UUID)
 	return result, nil
 }
 
+func (m *MockOrderItemRepo) ListByOrders(ctx context.Context, orderIDs []uuid.UUID) ([]*OrderItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*OrderItem
+	for _, item := range m.items {
+		for _, id := range orderIDs {
+			if item.OrderID == id {
+				result = append(result, item)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
 func (m *MockOrderItemRepo) ListByGroup(ctx context.Context, groupID uuid.UUID) ([]*OrderItem, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -262,3 +332,54 @@ func (m *MockOrderGroupRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	delete(m.groups, id)
 	return nil
 }
+
+// MockPaymentRepo is a mock implementation of PaymentRepo for testing
+type MockPaymentRepo struct {
+	mu       sync.RWMutex
+	payments []*Payment
+	CreateFunc      func(ctx context.Context, payment *Payment) error
+	ListByOrderFunc func(ctx context.Context, orderID uuid.UUID) ([]*Payment, error)
+}
+
+func NewMockPaymentRepo() *MockPaymentRepo {
+	return &MockPaymentRepo{}
+}
+
+func (m *MockPaymentRepo) Create(ctx context.Context, payment *Payment) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, payment)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.payments {
+		if payment.Sequence > 0 && p.OrderID == payment.OrderID && p.Sequence == payment.Sequence {
+			return ErrPaymentConflict
+		}
+	}
+	m.payments = append(m.payments, payment)
+	return nil
+}
+
+func (m *MockPaymentRepo) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*Payment, error) {
+	if m.ListByOrderFunc != nil {
+		return m.ListByOrderFunc(ctx, orderID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*Payment
+	for _, p := range m.payments {
+		if p.OrderID == orderID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// unmarshalEvent decodes the payload of a published event envelope into v.
+func unmarshalEvent(msg []byte, v any) error {
+	env, err := event.Open(msg)
+	if err != nil {
+		return err
+	}
+	return env.Unmarshal(v)
+}
@@ -1,12 +1,20 @@
 package order
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/appetiteclub/apt"
 	"github.com/google/uuid"
 )
 
+// ClosedStatuses are the statuses an order ends in.
+var ClosedStatuses = []string{"closed", "cancelled"}
+
+// MinIDPrefixLength is the shortest order ID prefix that can be looked up.
+const MinIDPrefixLength = 4
+
 type Order struct {
 	ID        uuid.UUID `json:"id" bson:"_id"`
 	TableID   uuid.UUID `json:"table_id" bson:"table_id"`
@@ -76,3 +84,60 @@ func (o *Order) Close() {
 	o.Status = "closed"
 	o.UpdatedAt = time.Now()
 }
+
+// IsOpen reports whether the order is neither closed nor cancelled.
+func (o *Order) IsOpen() bool {
+	for _, status := range ClosedStatuses {
+		if o.Status == status {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseIDPrefix normalizes the start of an order ID, as shown in the UI, to
+// the lowercase hex digits ListByIDPrefix takes.
+func ParseIDPrefix(raw string) (string, error) {
+	prefix := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(raw), "-", ""))
+	if len(prefix) < MinIDPrefixLength || len(prefix) > 32 {
+		return "", fmt.Errorf("id prefix must have %d to 32 hex digits", MinIDPrefixLength)
+	}
+	for _, c := range prefix {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", fmt.Errorf("id prefix %q is not hex", raw)
+		}
+	}
+	return prefix, nil
+}
+
+// OrderSummary is an order with the count and subtotal of its billable
+// items, so listings need not fetch the items of every order.
+type OrderSummary struct {
+	*Order
+	ItemCount int     `json:"item_count"`
+	Subtotal  float64 `json:"subtotal"`
+}
+
+// Summarize pairs orders with the totals of their items, which may belong
+// to any of them.
+func Summarize(orders []*Order, items []*OrderItem) []OrderSummary {
+	byOrder := make(map[uuid.UUID]*OrderSummary, len(orders))
+	summaries := make([]OrderSummary, len(orders))
+	for i, o := range orders {
+		summaries[i] = OrderSummary{Order: o}
+		byOrder[o.ID] = &summaries[i]
+	}
+	for _, item := range items {
+		if !billable(item) {
+			continue
+		}
+		if summary, ok := byOrder[item.OrderID]; ok {
+			summary.ItemCount += item.Quantity
+			summary.Subtotal += item.Price * float64(item.Quantity)
+		}
+	}
+	for i := range summaries {
+		summaries[i].Subtotal = roundMoney(summaries[i].Subtotal)
+	}
+	return summaries
+}
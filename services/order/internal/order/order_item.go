@@ -24,12 +24,40 @@ type OrderItem struct {
 	ProductionStation  *string    `json:"production_station,omitempty" bson:"production_station,omitempty"`
 	RequiresProduction bool       `json:"requires_production" bson:"requires_production"`
 
+	// Published menu version the item was ordered from, to replay its price
+	MenuID      *uuid.UUID `json:"menu_id,omitempty" bson:"menu_id,omitempty"`
+	MenuVersion int        `json:"menu_version,omitempty" bson:"menu_version,omitempty"`
+
+	// Modifier options picked for the item, as the menu resolved them
+	Modifiers []OrderItemModifier `json:"modifiers,omitempty" bson:"modifiers,omitempty"`
+
+	// Portion picked for the item and the prep time the kitchen plans for
+	PortionID   *uuid.UUID `json:"portion_id,omitempty" bson:"portion_id,omitempty"`
+	PortionName string     `json:"portion_name,omitempty" bson:"portion_name,omitempty"`
+	PrepTime    int        `json:"prep_time,omitempty" bson:"prep_time,omitempty"` // Minutes
+
+	// Course the item is served in; held items wait for the course to fire
+	Course  Course     `json:"course,omitempty" bson:"course,omitempty"`
+	Held    bool       `json:"held,omitempty" bson:"held,omitempty"`
+	FiredAt *time.Time `json:"fired_at,omitempty" bson:"fired_at,omitempty"`
+
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	CreatedBy string    `json:"created_by" bson:"created_by"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 	UpdatedBy string    `json:"updated_by" bson:"updated_by"`
 }
 
+// OrderItemModifier is a modifier option picked for an order item. Names and
+// price delta are copied from the menu so the order keeps what was ordered.
+type OrderItemModifier struct {
+	GroupID           uuid.UUID `json:"group_id" bson:"group_id"`
+	GroupName         string    `json:"group_name" bson:"group_name"`
+	OptionID          uuid.UUID `json:"option_id" bson:"option_id"`
+	OptionName        string    `json:"option_name" bson:"option_name"`
+	PriceDelta        float64   `json:"price_delta" bson:"price_delta"`
+	ProductionStation string    `json:"production_station,omitempty" bson:"production_station,omitempty"`
+}
+
 func (oi *OrderItem) GetID() uuid.UUID {
 	return oi.ID
 }
@@ -55,6 +83,33 @@ func (oi *OrderItem) EnsureID() {
 	}
 }
 
+// ApplyPortion stores the picked portion and takes its unit price. Apply it
+// before the modifiers so their deltas add on top of the portion price.
+func (oi *OrderItem) ApplyPortion(portion MenuItemPortion) {
+	id := portion.ID
+	oi.PortionID = &id
+	oi.PortionName = portion.Name
+	oi.PrepTime = portion.PrepTime
+	oi.Price = portion.Price
+}
+
+// ApplyModifiers stores the picked modifiers, adds their price deltas to the
+// unit price and routes the item to the first station a modifier asks for.
+func (oi *OrderItem) ApplyModifiers(modifiers []OrderItemModifier) {
+	oi.Modifiers = modifiers
+	for _, m := range modifiers {
+		oi.Price += m.PriceDelta
+	}
+	for _, m := range modifiers {
+		if m.ProductionStation != "" {
+			station := m.ProductionStation
+			oi.ProductionStation = &station
+			oi.RequiresProduction = true
+			return
+		}
+	}
+}
+
 func (oi *OrderItem) BeforeCreate() {
 	oi.EnsureID()
 	oi.CreatedAt = time.Now()
@@ -65,6 +120,13 @@ func (oi *OrderItem) BeforeUpdate() {
 	oi.UpdatedAt = time.Now()
 }
 
+// Fire releases a held item to the kitchen.
+func (oi *OrderItem) Fire(now time.Time) {
+	oi.Held = false
+	oi.FiredAt = &now
+	oi.UpdatedAt = now
+}
+
 func (oi *OrderItem) MarkAsPreparing() {
 	oi.Status = "preparing"
 	oi.UpdatedAt = time.Now()
@@ -285,3 +285,87 @@ func TestOrderItemWithOptionalFields(t *testing.T) {
 		t.Errorf("Price = %f, want %f", item.Price, 29.99)
 	}
 }
+
+func TestOrderItemApplyModifiers(t *testing.T) {
+	kitchen := "kitchen"
+
+	tests := []struct {
+		name           string
+		item           *OrderItem
+		modifiers      []OrderItemModifier
+		wantPrice      float64
+		wantStation    string
+		wantProduction bool
+	}{
+		{
+			name:           "noModifiersKeepsItem",
+			item:           &OrderItem{Price: 12.5, ProductionStation: &kitchen, RequiresProduction: true},
+			wantPrice:      12.5,
+			wantStation:    "kitchen",
+			wantProduction: true,
+		},
+		{
+			name: "addsPriceDeltas",
+			item: &OrderItem{Price: 12.5, ProductionStation: &kitchen, RequiresProduction: true},
+			modifiers: []OrderItemModifier{
+				{GroupName: "Extras", OptionName: "Cheese", PriceDelta: 1.5},
+				{GroupName: "Extras", OptionName: "No onions", PriceDelta: 0},
+				{GroupName: "Size", OptionName: "Kids", PriceDelta: -2},
+			},
+			wantPrice:      12,
+			wantStation:    "kitchen",
+			wantProduction: true,
+		},
+		{
+			name: "routesToFirstModifierStation",
+			item: &OrderItem{Price: 4},
+			modifiers: []OrderItemModifier{
+				{GroupName: "Side", OptionName: "Fries", ProductionStation: "fryer"},
+				{GroupName: "Drink", OptionName: "Cola", ProductionStation: "bar"},
+			},
+			wantPrice:      4,
+			wantStation:    "fryer",
+			wantProduction: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.item.ApplyModifiers(tt.modifiers)
+
+			if len(tt.item.Modifiers) != len(tt.modifiers) {
+				t.Errorf("Modifiers len = %d, want %d", len(tt.item.Modifiers), len(tt.modifiers))
+			}
+			if tt.item.Price != tt.wantPrice {
+				t.Errorf("Price = %v, want %v", tt.item.Price, tt.wantPrice)
+			}
+			if tt.item.ProductionStation == nil || *tt.item.ProductionStation != tt.wantStation {
+				t.Errorf("ProductionStation = %v, want %q", tt.item.ProductionStation, tt.wantStation)
+			}
+			if tt.item.RequiresProduction != tt.wantProduction {
+				t.Errorf("RequiresProduction = %v, want %v", tt.item.RequiresProduction, tt.wantProduction)
+			}
+		})
+	}
+}
+
+func TestOrderItemApplyPortion(t *testing.T) {
+	portionID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440724")
+	item := &OrderItem{Price: 14}
+
+	item.ApplyPortion(MenuItemPortion{ID: portionID, Name: "Half", Price: 8.5, CurrencyCode: "USD", PrepTime: 10})
+	item.ApplyModifiers([]OrderItemModifier{{GroupName: "Extras", OptionName: "Cheese", PriceDelta: 1.5}})
+
+	if item.PortionID == nil || *item.PortionID != portionID {
+		t.Errorf("PortionID = %v, want %v", item.PortionID, portionID)
+	}
+	if item.PortionName != "Half" {
+		t.Errorf("PortionName = %q, want %q", item.PortionName, "Half")
+	}
+	if item.PrepTime != 10 {
+		t.Errorf("PrepTime = %d, want 10", item.PrepTime)
+	}
+	if item.Price != 10 {
+		t.Errorf("Price = %v, want 10", item.Price)
+	}
+}
@@ -0,0 +1,71 @@
+package order
+
+import (
+	"time"
+
+	"github.com/appetiteclub/apt"
+	"github.com/google/uuid"
+)
+
+const (
+	TenderCash  = "cash"
+	TenderCard  = "card"
+	TenderOther = "other"
+)
+
+// Payment records money taken against an order. Amount settles the bill,
+// Tip is collected on top of it and never reduces the outstanding balance.
+type Payment struct {
+	ID        uuid.UUID `json:"id" bson:"_id"`
+	OrderID   uuid.UUID `json:"order_id" bson:"order_id"`
+	Tender    string    `json:"tender" bson:"tender"`
+	Amount    float64   `json:"amount" bson:"amount"`
+	Tip       float64   `json:"tip" bson:"tip"`
+	Reference string    `json:"reference,omitempty" bson:"reference,omitempty"`
+	// Sequence numbers the payments of an order from 1, as the bill they were
+	// checked against had that many minus one. Payments from before it was
+	// added have 0.
+	Sequence  int       `json:"sequence" bson:"sequence,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	CreatedBy string    `json:"created_by" bson:"created_by"`
+}
+
+func (p *Payment) GetID() uuid.UUID {
+	return p.ID
+}
+
+func (p *Payment) ResourceType() string {
+	return "payment"
+}
+
+func (p *Payment) SetID(id uuid.UUID) {
+	p.ID = id
+}
+
+func NewPayment(orderID uuid.UUID) *Payment {
+	return &Payment{
+		ID:      apt.GenerateNewID(),
+		OrderID: orderID,
+	}
+}
+
+func (p *Payment) EnsureID() {
+	if p.ID == uuid.Nil {
+		p.ID = apt.GenerateNewID()
+	}
+}
+
+func (p *Payment) BeforeCreate() {
+	p.EnsureID()
+	p.CreatedAt = time.Now()
+}
+
+// IsValidTender reports whether tender is one of the accepted payment methods.
+func IsValidTender(tender string) bool {
+	switch tender {
+	case TenderCash, TenderCard, TenderOther:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +13,11 @@ type OrderRepo interface {
 	List(ctx context.Context) ([]*Order, error)
 	ListByTable(ctx context.Context, tableID uuid.UUID) ([]*Order, error)
 	ListByStatus(ctx context.Context, status string) ([]*Order, error)
+	// ListOpen returns the orders that are neither closed nor cancelled.
+	ListOpen(ctx context.Context) ([]*Order, error)
+	// ListByIDPrefix returns the orders whose ID starts with prefix, given
+	// as lowercase hex digits without hyphens.
+	ListByIDPrefix(ctx context.Context, prefix string) ([]*Order, error)
 	Save(ctx context.Context, order *Order) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -21,6 +27,7 @@ type OrderItemRepo interface {
 	Get(ctx context.Context, id uuid.UUID) (*OrderItem, error)
 	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*OrderItem, error)
 	ListByGroup(ctx context.Context, groupID uuid.UUID) ([]*OrderItem, error)
+	ListByOrders(ctx context.Context, orderIDs []uuid.UUID) ([]*OrderItem, error)
 	Save(ctx context.Context, item *OrderItem) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -30,3 +37,15 @@ type OrderGroupRepo interface {
 	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*OrderGroup, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
+
+// ErrPaymentConflict is returned by PaymentRepo.Create when the order
+// already has a payment at payment.Sequence: another one was taken against
+// the same bill.
+var ErrPaymentConflict = errors.New("another payment was recorded on the order")
+
+type PaymentRepo interface {
+	// Create saves the payment unless the order already has one at
+	// payment.Sequence, in which case it returns ErrPaymentConflict.
+	Create(ctx context.Context, payment *Payment) error
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*Payment, error)
+}
@@ -0,0 +1,264 @@
+package order
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// Split strategies accepted by the split endpoint.
+const (
+	SplitByGroup  = "group"
+	SplitByItems  = "items"
+	SplitEvenly   = "even"
+	unassignedTab = "Unassigned"
+)
+
+// Check is one share of an order bill. Checks are computed on demand and are
+// never persisted; payments are still recorded against the order.
+type Check struct {
+	Name          string     `json:"name"`
+	GroupID       *uuid.UUID `json:"group_id,omitempty"`
+	Lines         []BillLine `json:"lines"`
+	Subtotal      float64    `json:"subtotal"`
+	ServiceCharge float64    `json:"service_charge"`
+	Tax           float64    `json:"tax"`
+	AmountDue     float64    `json:"amount_due"`
+}
+
+// CheckAssignment puts a set of item shares on a named check.
+type CheckAssignment struct {
+	Name  string       `json:"name"`
+	Items []CheckShare `json:"items"`
+}
+
+// CheckShare assigns a fraction of an order item to a check. A zero Share
+// means the whole item.
+type CheckShare struct {
+	OrderItemID uuid.UUID `json:"order_item_id"`
+	Share       float64   `json:"share,omitempty"`
+}
+
+// SplitByGroups returns one check per order group. Items without a group, or
+// with a group that no longer exists, land on an extra "Unassigned" check.
+func (p BillingPolicy) SplitByGroups(items []*OrderItem, groups []*OrderGroup) []*Check {
+	checks := make([]*Check, 0, len(groups)+1)
+	byGroup := make(map[uuid.UUID]*Check, len(groups))
+	for _, group := range groups {
+		id := group.ID
+		check := &Check{Name: group.Name, GroupID: &id, Lines: []BillLine{}}
+		byGroup[id] = check
+		checks = append(checks, check)
+	}
+
+	var unassigned *Check
+	for _, item := range items {
+		if !billable(item) {
+			continue
+		}
+		var check *Check
+		if item.GroupID != nil {
+			check = byGroup[*item.GroupID]
+		}
+		if check == nil {
+			if unassigned == nil {
+				unassigned = &Check{Name: unassignedTab, Lines: []BillLine{}}
+			}
+			check = unassigned
+		}
+		check.Lines = append(check.Lines, billLineFor(item, 1, 0, true))
+	}
+	if unassigned != nil {
+		checks = append(checks, unassigned)
+	}
+
+	p.priceChecks(checks)
+	return checks
+}
+
+// SplitByItems builds checks from explicit item assignments. An item may be
+// shared across several checks as long as its shares add up to at most one;
+// whatever is left unassigned lands on an extra "Unassigned" check.
+func (p BillingPolicy) SplitByItems(items []*OrderItem, assignments []CheckAssignment) ([]*Check, error) {
+	billableItems := make(map[uuid.UUID]*OrderItem, len(items))
+	for _, item := range items {
+		if billable(item) {
+			billableItems[item.ID] = item
+		}
+	}
+
+	allocated := make(map[uuid.UUID]float64, len(items))
+	billed := make(map[uuid.UUID]float64, len(items))
+	checks := make([]*Check, 0, len(assignments)+1)
+
+	for i, assignment := range assignments {
+		name := assignment.Name
+		if name == "" {
+			name = fmt.Sprintf("Check %d", i+1)
+		}
+		check := &Check{Name: name, Lines: []BillLine{}}
+
+		for _, share := range assignment.Items {
+			item, ok := billableItems[share.OrderItemID]
+			if !ok {
+				return nil, fmt.Errorf("order item %s is not billable on this order", share.OrderItemID)
+			}
+			fraction := share.Share
+			if fraction == 0 {
+				fraction = 1
+			}
+			if fraction < 0 || fraction > 1 {
+				return nil, fmt.Errorf("share for order item %s must be between 0 and 1", share.OrderItemID)
+			}
+			if allocated[item.ID]+fraction > 1+shareTolerance {
+				return nil, fmt.Errorf("order item %s is assigned beyond its full amount", share.OrderItemID)
+			}
+
+			allocated[item.ID] += fraction
+			completes := allocated[item.ID] >= 1-shareTolerance
+			line := billLineFor(item, fraction, billed[item.ID], completes)
+			billed[item.ID] += line.Amount
+			check.Lines = append(check.Lines, line)
+		}
+
+		checks = append(checks, check)
+	}
+
+	var unassigned *Check
+	for _, item := range items {
+		if _, ok := billableItems[item.ID]; !ok {
+			continue
+		}
+		remaining := 1 - allocated[item.ID]
+		if remaining <= shareTolerance {
+			continue
+		}
+		if unassigned == nil {
+			unassigned = &Check{Name: unassignedTab, Lines: []BillLine{}}
+		}
+		unassigned.Lines = append(unassigned.Lines, billLineFor(item, remaining, billed[item.ID], true))
+	}
+	if unassigned != nil {
+		checks = append(checks, unassigned)
+	}
+
+	p.priceChecks(checks)
+	return checks, nil
+}
+
+// MaxSplitWays bounds an even split, well above any party a table seats.
+const MaxSplitWays = 100
+
+// SplitEvenly divides the whole bill into ways equal checks. Leftover cents
+// are spread across the checks so they add up to the bill exactly.
+func (p BillingPolicy) SplitEvenly(order *Order, items []*OrderItem, ways int) ([]*Check, error) {
+	if ways < 1 {
+		return nil, fmt.Errorf("ways must be at least 1")
+	}
+	if ways > MaxSplitWays {
+		return nil, fmt.Errorf("ways must be at most %d", MaxSplitWays)
+	}
+
+	bill := p.Compute(order, items, nil)
+	subtotals, next := divideMoney(bill.Subtotal, ways, 0)
+	services, next := divideMoney(bill.ServiceCharge, ways, next)
+	taxes, _ := divideMoney(bill.Tax, ways, next)
+
+	checks := make([]*Check, ways)
+	for i := range checks {
+		checks[i] = &Check{
+			Name:          fmt.Sprintf("Guest %d", i+1),
+			Lines:         []BillLine{},
+			Subtotal:      subtotals[i],
+			ServiceCharge: services[i],
+			Tax:           taxes[i],
+			AmountDue:     roundMoney(subtotals[i] + services[i] + taxes[i]),
+		}
+	}
+	return checks, nil
+}
+
+const shareTolerance = 1e-9
+
+// billLineFor prices a fraction of an item. The share that completes the item
+// absorbs the rounding left by earlier shares so the item is billed exactly.
+func billLineFor(item *OrderItem, fraction, alreadyBilled float64, completes bool) BillLine {
+	full := roundMoney(item.Price * float64(item.Quantity))
+	amount := roundMoney(full * fraction)
+	if completes {
+		amount = roundMoney(full - alreadyBilled)
+	}
+	line := BillLine{
+		OrderItemID: item.ID,
+		DishName:    item.DishName,
+		Category:    item.Category,
+		Quantity:    item.Quantity,
+		UnitPrice:   item.Price,
+		Amount:      amount,
+	}
+	if fraction < 1-shareTolerance {
+		line.Share = fraction
+	}
+	return line
+}
+
+// priceChecks applies service charges and taxes to every check. Charges are
+// rounded per check, so the cents lost or gained against the whole bill are
+// settled on the last check.
+func (p BillingPolicy) priceChecks(checks []*Check) {
+	if len(checks) == 0 {
+		return
+	}
+
+	var all []BillLine
+	var service, tax float64
+	for _, check := range checks {
+		p.priceCheck(check)
+		all = append(all, check.Lines...)
+		service += check.ServiceCharge
+		tax += check.Tax
+	}
+
+	var subtotal float64
+	for _, line := range all {
+		subtotal += line.Amount
+	}
+	billService, _ := p.serviceCharges(roundMoney(subtotal))
+	billTax, _ := p.taxes(all)
+
+	last := checks[len(checks)-1]
+	last.ServiceCharge = roundMoney(last.ServiceCharge + billService - service)
+	last.Tax = roundMoney(last.Tax + billTax - tax)
+	last.AmountDue = roundMoney(last.Subtotal + last.ServiceCharge + last.Tax)
+}
+
+// priceCheck applies service charges and taxes to the lines of a check.
+func (p BillingPolicy) priceCheck(check *Check) {
+	var subtotal float64
+	for _, line := range check.Lines {
+		subtotal += line.Amount
+	}
+	check.Subtotal = roundMoney(subtotal)
+	check.ServiceCharge, _ = p.serviceCharges(check.Subtotal)
+	check.Tax, _ = p.taxes(check.Lines)
+	check.AmountDue = roundMoney(check.Subtotal + check.ServiceCharge + check.Tax)
+}
+
+// divideMoney splits amount into n parts that add up to amount exactly. The
+// leftover cents go to consecutive parts starting at offset; the returned
+// offset lets the next component continue where this one stopped.
+func divideMoney(amount float64, n, offset int) ([]float64, int) {
+	cents := int64(math.Round(amount * 100))
+	base := cents / int64(n)
+	rest := int(cents % int64(n))
+	parts := make([]float64, n)
+	for i := range parts {
+		parts[i] = float64(base) / 100
+	}
+	for i := 0; i < rest; i++ {
+		idx := (offset + i) % n
+		parts[idx] = float64(base+1) / 100
+	}
+	return parts, (offset + rest) % n
+}
@@ -0,0 +1,304 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/appetiteclub/apt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func sumChecks(checks []*Check) float64 {
+	var total float64
+	for _, check := range checks {
+		total += check.AmountDue
+	}
+	return roundMoney(total)
+}
+
+func TestBillingPolicySplitByGroups(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440400")
+	groupA := uuid.MustParse("550e8400-e29b-41d4-a716-446655440401")
+	groupB := uuid.MustParse("550e8400-e29b-41d4-a716-446655440402")
+	missing := uuid.MustParse("550e8400-e29b-41d4-a716-446655440403")
+
+	groups := []*OrderGroup{
+		{ID: groupA, OrderID: orderID, Name: "Ana"},
+		{ID: groupB, OrderID: orderID, Name: "Ben"},
+	}
+	items := []*OrderItem{
+		{ID: uuid.New(), GroupID: &groupA, Quantity: 1, Price: 12, Status: "delivered"},
+		{ID: uuid.New(), GroupID: &groupB, Quantity: 2, Price: 4.5, Status: "delivered"},
+		{ID: uuid.New(), GroupID: &groupB, Quantity: 1, Price: 9, Status: "cancelled"},
+		{ID: uuid.New(), Quantity: 1, Price: 3, Status: "ready"},
+		{ID: uuid.New(), GroupID: &missing, Quantity: 1, Price: 2, Status: "ready"},
+	}
+
+	checks := BillingPolicy{}.SplitByGroups(items, groups)
+
+	if len(checks) != 3 {
+		t.Fatalf("SplitByGroups() = %d checks, want 3", len(checks))
+	}
+	want := []struct {
+		name     string
+		subtotal float64
+		lines    int
+	}{
+		{name: "Ana", subtotal: 12, lines: 1},
+		{name: "Ben", subtotal: 9, lines: 1},
+		{name: "Unassigned", subtotal: 5, lines: 2},
+	}
+	for i, w := range want {
+		if checks[i].Name != w.name || checks[i].Subtotal != w.subtotal || len(checks[i].Lines) != w.lines {
+			t.Errorf("check %d = %s/%v/%d lines, want %s/%v/%d lines", i, checks[i].Name, checks[i].Subtotal, len(checks[i].Lines), w.name, w.subtotal, w.lines)
+		}
+	}
+}
+
+func TestBillingPolicySplitByItems(t *testing.T) {
+	pizza := &OrderItem{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440410"), DishName: "Pizza", Quantity: 1, Price: 10, Status: "delivered"}
+	wine := &OrderItem{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440411"), DishName: "Wine", Quantity: 1, Price: 7, Status: "delivered"}
+	soup := &OrderItem{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440412"), DishName: "Soup", Quantity: 1, Price: 5, Status: "cancelled"}
+	items := []*OrderItem{pizza, wine, soup}
+	policy := BillingPolicy{TaxRules: []TaxRule{{Name: "VAT", Rate: 0.1}}}
+
+	tests := []struct {
+		name        string
+		assignments []CheckAssignment
+		wantErr     bool
+		wantChecks  int
+		wantDue     []float64
+	}{
+		{
+			name: "pizzaSharedThreeWays",
+			assignments: []CheckAssignment{
+				{Name: "A", Items: []CheckShare{{OrderItemID: pizza.ID, Share: 1.0 / 3}, {OrderItemID: wine.ID}}},
+				{Name: "B", Items: []CheckShare{{OrderItemID: pizza.ID, Share: 1.0 / 3}}},
+				{Name: "C", Items: []CheckShare{{OrderItemID: pizza.ID, Share: 1.0 / 3}}},
+			},
+			wantChecks: 3,
+			wantDue:    []float64{11.36, 3.66, 3.68},
+		},
+		{
+			name: "remainderUnassigned",
+			assignments: []CheckAssignment{
+				{Items: []CheckShare{{OrderItemID: pizza.ID, Share: 0.5}}},
+			},
+			wantChecks: 2,
+			wantDue:    []float64{5.5, 13.2},
+		},
+		{
+			name: "overAllocated",
+			assignments: []CheckAssignment{
+				{Items: []CheckShare{{OrderItemID: pizza.ID}}},
+				{Items: []CheckShare{{OrderItemID: pizza.ID, Share: 0.5}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "cancelledItem",
+			assignments: []CheckAssignment{{Items: []CheckShare{{OrderItemID: soup.ID}}}},
+			wantErr:     true,
+		},
+		{
+			name:        "invalidShare",
+			assignments: []CheckAssignment{{Items: []CheckShare{{OrderItemID: wine.ID, Share: 1.5}}}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := policy.SplitByItems(items, tt.assignments)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitByItems() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(checks) != tt.wantChecks {
+				t.Fatalf("SplitByItems() = %d checks, want %d", len(checks), tt.wantChecks)
+			}
+			if got := sumChecks(checks); got != 18.7 {
+				t.Errorf("checks add up to %v, want the bill total 18.7", got)
+			}
+			for i, due := range tt.wantDue {
+				if checks[i].AmountDue != due {
+					t.Errorf("check %d AmountDue = %v, want %v", i, checks[i].AmountDue, due)
+				}
+			}
+			if checks[0].Name == "" {
+				t.Error("checks should always be named")
+			}
+		})
+	}
+}
+
+func TestBillingPolicySplitEvenly(t *testing.T) {
+	order := &Order{ID: uuid.New()}
+	items := []*OrderItem{
+		{ID: uuid.New(), Quantity: 1, Price: 10, Status: "delivered"},
+	}
+	policy := BillingPolicy{ServiceCharges: []ServiceChargeRule{{Name: "Service", Rate: 0.1}}}
+
+	checks, err := policy.SplitEvenly(order, items, 3)
+	if err != nil {
+		t.Fatalf("SplitEvenly() error = %v", err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("SplitEvenly() = %d checks, want 3", len(checks))
+	}
+	if checks[0].AmountDue != 3.67 || checks[2].AmountDue != 3.66 {
+		t.Errorf("SplitEvenly() dues = %v, %v, %v", checks[0].AmountDue, checks[1].AmountDue, checks[2].AmountDue)
+	}
+	if got := sumChecks(checks); got != 11 {
+		t.Errorf("checks add up to %v, want 11", got)
+	}
+
+	if _, err := policy.SplitEvenly(order, items, 0); err == nil {
+		t.Error("SplitEvenly(0) should fail")
+	}
+	if _, err := policy.SplitEvenly(order, items, MaxSplitWays+1); err == nil {
+		t.Errorf("SplitEvenly(%d) should fail", MaxSplitWays+1)
+	}
+}
+
+func TestHandlerSplitOrder(t *testing.T) {
+	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440420")
+	groupID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440421")
+	itemID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440422")
+
+	tests := []struct {
+		name           string
+		orderID        string
+		body           interface{}
+		expectedStatus int
+		wantChecks     int
+	}{
+		{
+			name:           "byGroup",
+			orderID:        orderID.String(),
+			body:           SplitRequest{Strategy: "group"},
+			expectedStatus: http.StatusOK,
+			wantChecks:     1,
+		},
+		{
+			name:           "evenly",
+			orderID:        orderID.String(),
+			body:           SplitRequest{Strategy: "even", Ways: 4},
+			expectedStatus: http.StatusOK,
+			wantChecks:     4,
+		},
+		{
+			name:    "byItems",
+			orderID: orderID.String(),
+			body: SplitRequest{Strategy: "items", Checks: []CheckAssignment{
+				{Name: "A", Items: []CheckShare{{OrderItemID: itemID, Share: 0.5}}},
+				{Name: "B", Items: []CheckShare{{OrderItemID: itemID, Share: 0.5}}},
+			}},
+			expectedStatus: http.StatusOK,
+			wantChecks:     2,
+		},
+		{
+			name:           "byItemsWithoutChecks",
+			orderID:        orderID.String(),
+			body:           SplitRequest{Strategy: "items"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "evenlyZeroWays",
+			orderID:        orderID.String(),
+			body:           SplitRequest{Strategy: "even"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "evenlyTooManyWays",
+			orderID:        orderID.String(),
+			body:           SplitRequest{Strategy: "even", Ways: 1_000_000_000},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknownStrategy",
+			orderID:        orderID.String(),
+			body:           SplitRequest{Strategy: "seat"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "orderNotFound",
+			orderID:        uuid.New().String(),
+			body:           SplitRequest{Strategy: "group"},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalidOrderID",
+			orderID:        "not-a-uuid",
+			body:           SplitRequest{Strategy: "group"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalidJSON",
+			orderID:        orderID.String(),
+			body:           "not json",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepo := NewMockOrderRepo()
+			itemRepo := NewMockOrderItemRepo()
+			groupRepo := NewMockOrderGroupRepo()
+
+			orderRepo.orders[orderID] = &Order{ID: orderID, Status: "pending"}
+			groupRepo.groups[groupID] = &OrderGroup{ID: groupID, OrderID: orderID, Name: "Ana"}
+			itemRepo.items[itemID] = &OrderItem{ID: itemID, OrderID: orderID, GroupID: &groupID, Quantity: 2, Price: 8, Status: "delivered"}
+
+			h := NewHandler(HandlerDeps{
+				Repos: Repos{OrderRepo: orderRepo, OrderItemRepo: itemRepo, OrderGroupRepo: groupRepo},
+			}, apt.NewConfig(), nil)
+
+			var body []byte
+			if s, ok := tt.body.(string); ok {
+				body = []byte(s)
+			} else {
+				body, _ = json.Marshal(tt.body)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/"+tt.orderID+"/split", bytes.NewReader(body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("orderID", tt.orderID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.SplitOrder(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("SplitOrder() status = %d, want %d, body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data struct {
+					Checks    []*Check `json:"checks"`
+					AmountDue float64  `json:"amount_due"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("cannot decode response: %v", err)
+			}
+			if len(resp.Data.Checks) != tt.wantChecks {
+				t.Errorf("SplitOrder() = %d checks, want %d", len(resp.Data.Checks), tt.wantChecks)
+			}
+			if resp.Data.AmountDue != 16 {
+				t.Errorf("SplitOrder() amount_due = %v, want 16", resp.Data.AmountDue)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/appetiteclub/appetite/pkg"
 	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/idempotency"
 	proto "github.com/appetiteclub/appetite/services/order/internal/order/proto"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
@@ -243,6 +244,48 @@ func TestTableStatusSubscriberHandleEvent(t *testing.T) {
 	}
 }
 
+func TestTableStatusSubscriberSkipsRedeliveredEvent(t *testing.T) {
+	tableID := uuid.New()
+
+	var handler events.HandlerFunc
+	subscriber := &MockSubscriber{
+		SubscribeFunc: func(ctx context.Context, topic string, h events.HandlerFunc) error {
+			handler = h
+			return nil
+		},
+	}
+	cache := NewTableStateCache(nil, nil)
+	sub := NewTableStatusSubscriber(subscriber, cache, nil)
+	sub.SetIdempotencyStore(idempotency.NewMemoryStore(time.Hour))
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	occupied, _ := json.Marshal(pkg.TableStatusEvent{
+		EventID:    event.NewID(),
+		TableID:    tableID.String(),
+		Status:     "occupied",
+		OccurredAt: time.Now(),
+	})
+	available, _ := json.Marshal(pkg.TableStatusEvent{
+		EventID:    event.NewID(),
+		TableID:    tableID.String(),
+		Status:     "available",
+		OccurredAt: time.Now(),
+	})
+
+	// The occupied event is redelivered after the table was freed
+	for _, msg := range [][]byte{occupied, available, occupied} {
+		if err := handler(context.Background(), msg); err != nil {
+			t.Fatalf("handler() error = %v", err)
+		}
+	}
+
+	if status, _ := cache.Get(tableID); status != "available" {
+		t.Errorf("cached status = %q, want %q", status, "available")
+	}
+}
+
 func TestKitchenTicketSubscriberHandleEvent(t *testing.T) {
 	orderItemID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440091")
 	orderID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440092")
@@ -454,6 +497,78 @@ func TestKitchenTicketSubscriberHandleEvent(t *testing.T) {
 	}
 }
 
+func TestKitchenTicketSubscriberHandleDecisionResolved(t *testing.T) {
+	troutID := uuid.New()
+	price := 21.5
+
+	tests := []struct {
+		name      string
+		chosen    *event.KitchenDecisionOption
+		wantDish  string
+		wantPrice float64
+		wantNotes string
+		wantMenu  *uuid.UUID
+	}{
+		{
+			name:      "substitute",
+			chosen:    &event.KitchenDecisionOption{ID: "1", Outcome: event.DecisionOutcomeSubstitute, MenuItemID: troutID.String(), MenuItemName: "Trout", Price: &price},
+			wantDish:  "Trout",
+			wantPrice: 21.5,
+			wantNotes: "No ice; Instead of Salmon",
+			wantMenu:  &troutID,
+		},
+		{
+			name:      "proceed",
+			chosen:    &event.KitchenDecisionOption{ID: "3", Outcome: event.DecisionOutcomeProceed, Note: "Half portion"},
+			wantDish:  "Salmon",
+			wantPrice: 24,
+			wantNotes: "No ice; Half portion",
+		},
+		{
+			name:      "cancelLeftToStatusChange",
+			chosen:    &event.KitchenDecisionOption{ID: "2", Outcome: event.DecisionOutcomeCancel},
+			wantDish:  "Salmon",
+			wantPrice: 24,
+			wantNotes: "No ice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderItemID := uuid.New()
+			repo := NewMockOrderItemRepo()
+			repo.items[orderItemID] = &OrderItem{ID: orderItemID, OrderID: uuid.New(), DishName: "Salmon", Price: 24, Notes: "No ice", Status: "pending"}
+
+			sub := NewKitchenTicketSubscriber(nil, repo, nil)
+			msg, _ := json.Marshal(event.KitchenTicketDecisionEvent{
+				KitchenTicketEventMetadata: event.KitchenTicketEventMetadata{
+					EventType:   event.EventKitchenDecisionResolved,
+					TicketID:    uuid.New().String(),
+					OrderItemID: orderItemID.String(),
+				},
+				Question:   "Out of salmon, what now?",
+				Chosen:     tt.chosen,
+				AnsweredBy: "ana",
+			})
+
+			if err := sub.handleEvent(context.Background(), msg); err != nil {
+				t.Fatalf("handleEvent() unexpected error: %v", err)
+			}
+
+			item, _ := repo.Get(context.Background(), orderItemID)
+			if item.DishName != tt.wantDish || item.Price != tt.wantPrice || item.Notes != tt.wantNotes {
+				t.Errorf("item = %s/%.2f/%q, want %s/%.2f/%q", item.DishName, item.Price, item.Notes, tt.wantDish, tt.wantPrice, tt.wantNotes)
+			}
+			if tt.wantMenu != nil && (item.MenuItemID == nil || *item.MenuItemID != *tt.wantMenu) {
+				t.Errorf("item menu item = %v, want %v", item.MenuItemID, *tt.wantMenu)
+			}
+			if item.Status != "pending" {
+				t.Errorf("item status = %q, want it unchanged", item.Status)
+			}
+		})
+	}
+}
+
 func TestNewOrderEventStreamServer(t *testing.T) {
 	tests := []struct {
 		name string
@@ -465,7 +580,7 @@ func TestNewOrderEventStreamServer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := NewOrderEventStreamServer(nil, nil)
+			server := NewOrderEventStreamServer(nil, nil, nil, nil)
 
 			if server == nil {
 				t.Fatal("NewOrderEventStreamServer() returned nil")
@@ -536,7 +651,7 @@ func TestOrderEventStreamServerBroadcastOrderItemEvent(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := NewMockOrderItemRepo()
 			logger := apt.NewNoopLogger()
-			server := NewOrderEventStreamServer(repo, logger)
+			server := NewOrderEventStreamServer(nil, repo, nil, logger)
 
 			// BroadcastOrderItemEvent should not panic even with no subscribers
 			server.BroadcastOrderItemEvent(tt.item, tt.eventType, tt.previousStatus)
@@ -668,7 +783,7 @@ func TestKitchenTicketSubscriberHandleStatusChangeRepoSaveError(t *testing.T) {
 
 func TestOrderEventStreamServerBroadcastToSubscribers(t *testing.T) {
 	orderItemRepo := NewMockOrderItemRepo()
-	server := NewOrderEventStreamServer(orderItemRepo, apt.NewNoopLogger())
+	server := NewOrderEventStreamServer(nil, orderItemRepo, nil, apt.NewNoopLogger())
 
 	// Add a subscriber with a buffer
 	testChan := make(chan *proto.OrderItemEvent, 10)
@@ -706,7 +821,7 @@ func TestOrderEventStreamServerBroadcastToSubscribers(t *testing.T) {
 
 func TestOrderEventStreamServerBroadcastChannelFull(t *testing.T) {
 	orderItemRepo := NewMockOrderItemRepo()
-	server := NewOrderEventStreamServer(orderItemRepo, apt.NewNoopLogger())
+	server := NewOrderEventStreamServer(nil, orderItemRepo, nil, apt.NewNoopLogger())
 
 	// Add a subscriber with a buffer of 1, already full
 	fullChan := make(chan *proto.OrderItemEvent, 1)
@@ -759,3 +874,145 @@ func TestTableStatusSubscriberHandleEventCacheUpdate(t *testing.T) {
 		t.Errorf("handleEvent() cached status = %q, want %q", status, "reserved")
 	}
 }
+
+// recordingOrderStream collects what StreamOrderItemEvents sends and ends the
+// stream once it got the expected number of events.
+type recordingOrderStream struct {
+	proto.OrderEventStream_StreamOrderItemEventsServer
+	ctx    context.Context
+	cancel context.CancelFunc
+	want   int
+	sent   []*proto.OrderItemEvent
+}
+
+func (s *recordingOrderStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recordingOrderStream) Send(evt *proto.OrderItemEvent) error {
+	s.sent = append(s.sent, evt)
+	if len(s.sent) >= s.want {
+		s.cancel()
+	}
+	return nil
+}
+
+func TestOrderEventStreamServerResumesFromSequence(t *testing.T) {
+	server := NewOrderEventStreamServer(nil, NewMockOrderItemRepo(), nil, apt.NewNoopLogger())
+
+	for _, status := range []string{"pending", "preparing", "ready"} {
+		item := &OrderItem{ID: uuid.New(), OrderID: uuid.New(), DishName: "Soup", Status: status, Quantity: 1}
+		server.BroadcastOrderItemEvent(item, "order.item.status_changed", "")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	stream := &recordingOrderStream{ctx: ctx, cancel: cancel, want: 2}
+	server.StreamOrderItemEvents(&proto.SubscribeOrderItemEventsRequest{AfterSequence: 1, Instance: server.replay.Epoch()}, stream)
+
+	if len(stream.sent) != 2 {
+		t.Fatalf("resumed stream sent %d events, want 2", len(stream.sent))
+	}
+	for i, want := range []string{"preparing", "ready"} {
+		if stream.sent[i].NewStatus != want || stream.sent[i].Sequence != uint64(i+2) {
+			t.Errorf("replayed event %d = %s at %d, want %s at %d", i, stream.sent[i].NewStatus, stream.sent[i].Sequence, want, i+2)
+		}
+	}
+}
+
+func TestTableStatusSubscriberStoresTableDetails(t *testing.T) {
+	tableID := uuid.New()
+	cache := NewTableStateCache(nil, nil)
+	sub := NewTableStatusSubscriber(nil, cache, nil)
+
+	msg, _ := json.Marshal(pkg.TableStatusEvent{
+		TableID:    tableID.String(),
+		Number:     "12",
+		Status:     "occupied",
+		AssignedTo: "waiter-1",
+		OccurredAt: time.Now(),
+	})
+	if err := sub.handleEvent(context.Background(), msg); err != nil {
+		t.Fatalf("handleEvent() unexpected error: %v", err)
+	}
+
+	details, ok := cache.Details(tableID)
+	if !ok || details.Number != "12" || details.AssignedTo != "waiter-1" {
+		t.Errorf("Details() = %+v, %v, want number 12 assigned to waiter-1", details, ok)
+	}
+}
+
+func TestOrderEventStreamServerFilters(t *testing.T) {
+	ctx := context.Background()
+	orders := NewMockOrderRepo()
+	items := NewMockOrderItemRepo()
+	tables := NewTableStateCache(nil, nil)
+
+	mine, other := uuid.New(), uuid.New()
+	tables.SetDetails(mine, TableDetails{Number: "4", AssignedTo: "waiter-1"})
+	tables.SetDetails(other, TableDetails{Number: "9", AssignedTo: "waiter-2"})
+
+	order := &Order{ID: uuid.New(), TableID: mine, Status: "open"}
+	otherOrder := &Order{ID: uuid.New(), TableID: other, Status: "open"}
+	orders.Create(ctx, order)
+	orders.Create(ctx, otherOrder)
+
+	server := NewOrderEventStreamServer(orders, items, tables, apt.NewNoopLogger())
+
+	tests := []struct {
+		name   string
+		req    *proto.SubscribeOrderItemEventsRequest
+		item   *OrderItem
+		wanted bool
+	}{
+		{"orderMatches", &proto.SubscribeOrderItemEventsRequest{OrderId: order.ID.String()}, &OrderItem{OrderID: order.ID, Status: "pending"}, true},
+		{"orderDiffers", &proto.SubscribeOrderItemEventsRequest{OrderId: order.ID.String()}, &OrderItem{OrderID: otherOrder.ID, Status: "pending"}, false},
+		{"tableMatches", &proto.SubscribeOrderItemEventsRequest{TableId: mine.String()}, &OrderItem{OrderID: order.ID, Status: "pending"}, true},
+		{"tableDiffers", &proto.SubscribeOrderItemEventsRequest{TableId: mine.String()}, &OrderItem{OrderID: otherOrder.ID, Status: "pending"}, false},
+		{"waiterMatches", &proto.SubscribeOrderItemEventsRequest{WaiterId: "waiter-1"}, &OrderItem{OrderID: order.ID, Status: "pending"}, true},
+		{"waiterDiffers", &proto.SubscribeOrderItemEventsRequest{WaiterId: "waiter-1"}, &OrderItem{OrderID: otherOrder.ID, Status: "pending"}, false},
+		{"statusMatches", &proto.SubscribeOrderItemEventsRequest{Statuses: []string{"Ready", "delivered"}}, &OrderItem{OrderID: order.ID, Status: "ready"}, true},
+		{"statusDiffers", &proto.SubscribeOrderItemEventsRequest{Statuses: []string{"ready"}}, &OrderItem{OrderID: order.ID, Status: "pending"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.item.ID = uuid.New()
+			evt := server.itemEvent(tt.item, server.tableOf(tt.item.OrderID), "order.item.created", "")
+			if got := server.matches(newOrderItemFilter(tt.req), evt); got != tt.wanted {
+				t.Errorf("matches() = %v, want %v", got, tt.wanted)
+			}
+		})
+	}
+}
+
+func TestOrderEventStreamServerSendsSnapshotInScope(t *testing.T) {
+	orders := NewMockOrderRepo()
+	items := NewMockOrderItemRepo()
+	tables := NewTableStateCache(nil, nil)
+
+	tableID := uuid.New()
+	tables.SetDetails(tableID, TableDetails{Number: "7"})
+
+	order := &Order{ID: uuid.New(), TableID: tableID, Status: "open"}
+	closed := &Order{ID: uuid.New(), TableID: tableID, Status: "closed"}
+	orders.Create(context.Background(), order)
+	orders.Create(context.Background(), closed)
+	items.Create(context.Background(), &OrderItem{ID: uuid.New(), OrderID: order.ID, DishName: "Soup", Status: "pending"})
+	items.Create(context.Background(), &OrderItem{ID: uuid.New(), OrderID: order.ID, DishName: "Steak", Status: "ready"})
+	items.Create(context.Background(), &OrderItem{ID: uuid.New(), OrderID: closed.ID, DishName: "Cake", Status: "ready"})
+
+	server := NewOrderEventStreamServer(orders, items, tables, apt.NewNoopLogger())
+	server.BroadcastOrderItemEvent(&OrderItem{ID: uuid.New(), OrderID: order.ID, Status: "pending"}, "order.item.created", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	stream := &recordingOrderStream{ctx: ctx, cancel: cancel, want: 1}
+	server.StreamOrderItemEvents(&proto.SubscribeOrderItemEventsRequest{TableId: tableID.String(), Statuses: []string{"ready"}}, stream)
+
+	if len(stream.sent) != 1 {
+		t.Fatalf("snapshot sent %d items, want 1", len(stream.sent))
+	}
+	evt := stream.sent[0]
+	if evt.EventType != EventOrderItemSnapshot || evt.DishName != "Steak" || evt.TableNumber != "7" || evt.Sequence != 1 {
+		t.Errorf("snapshot item = %s %s table %s at %d, want %s Steak table 7 at 1", evt.EventType, evt.DishName, evt.TableNumber, evt.Sequence, EventOrderItemSnapshot)
+	}
+}
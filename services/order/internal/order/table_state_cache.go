@@ -11,10 +11,18 @@ import (
 )
 
 type TableStateCache struct {
-	mu     sync.RWMutex
-	state  map[uuid.UUID]string
-	client *apt.ServiceClient
-	logger apt.Logger
+	mu      sync.RWMutex
+	state   map[uuid.UUID]string
+	details map[uuid.UUID]TableDetails
+	client  *apt.ServiceClient
+	logger  apt.Logger
+}
+
+// TableDetails is what the order service knows about a table besides its
+// status: the number guests see and the waiter serving it.
+type TableDetails struct {
+	Number     string
+	AssignedTo string // Waiter ID, empty when nobody is assigned
 }
 
 func NewTableStateCache(client *apt.ServiceClient, logger apt.Logger) *TableStateCache {
@@ -22,9 +30,10 @@ func NewTableStateCache(client *apt.ServiceClient, logger apt.Logger) *TableStat
 		logger = apt.NewNoopLogger()
 	}
 	return &TableStateCache{
-		state:  make(map[uuid.UUID]string),
-		client: client,
-		logger: logger,
+		state:   make(map[uuid.UUID]string),
+		details: make(map[uuid.UUID]TableDetails),
+		client:  client,
+		logger:  logger,
 	}
 }
 
@@ -66,6 +75,7 @@ func (c *TableStateCache) Refresh(ctx context.Context, id uuid.UUID) (string, er
 		return "", fmt.Errorf("invalid table id %s", dto.ID)
 	}
 	c.Set(idValue, dto.Status)
+	c.SetDetails(idValue, dto.details())
 	return dto.Status, nil
 }
 
@@ -82,6 +92,20 @@ func (c *TableStateCache) Set(id uuid.UUID, status string) {
 	c.state[id] = status
 }
 
+// Details returns the number and waiter last seen for a table.
+func (c *TableStateCache) Details(id uuid.UUID) (TableDetails, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	details, ok := c.details[id]
+	return details, ok
+}
+
+func (c *TableStateCache) SetDetails(id uuid.UUID, details TableDetails) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.details[id] = details
+}
+
 func (c *TableStateCache) ingestCollection(data interface{}) error {
 	var records []tableStateDTO
 	if err := rehydrate(data, &records); err != nil {
@@ -94,13 +118,24 @@ func (c *TableStateCache) ingestCollection(data interface{}) error {
 			continue
 		}
 		c.Set(id, record.Status)
+		c.SetDetails(id, record.details())
 	}
 	return nil
 }
 
 type tableStateDTO struct {
-	ID     string `json:"id"`
-	Status string `json:"status"`
+	ID         string  `json:"id"`
+	Status     string  `json:"status"`
+	Number     string  `json:"number"`
+	AssignedTo *string `json:"assigned_to"`
+}
+
+func (d tableStateDTO) details() TableDetails {
+	details := TableDetails{Number: d.Number}
+	if d.AssignedTo != nil {
+		details.AssignedTo = *d.AssignedTo
+	}
+	return details
 }
 
 func rehydrate(data interface{}, out interface{}) error {
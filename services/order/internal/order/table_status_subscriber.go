@@ -2,10 +2,12 @@ package order
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/idempotency"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
 	"github.com/google/uuid"
@@ -14,6 +16,7 @@ import (
 type TableStatusSubscriber struct {
 	subscriber events.Subscriber
 	cache      *TableStateCache
+	processed  idempotency.Store
 	logger     apt.Logger
 }
 
@@ -28,6 +31,12 @@ func NewTableStatusSubscriber(sub events.Subscriber, cache *TableStateCache, log
 	}
 }
 
+// SetIdempotencyStore sets the store of processed event IDs, so a redelivered
+// table event does not overwrite a newer status
+func (s *TableStatusSubscriber) SetIdempotencyStore(store idempotency.Store) {
+	s.processed = store
+}
+
 func (s *TableStatusSubscriber) Start(ctx context.Context) error {
 	s.logger.Info("starting table status subscriber", "topic", pkg.TableStatusTopic)
 	if s.cache != nil {
@@ -38,23 +47,37 @@ func (s *TableStatusSubscriber) Start(ctx context.Context) error {
 	if s.subscriber == nil {
 		return fmt.Errorf("table status subscriber not configured")
 	}
-	return s.subscriber.Subscribe(ctx, pkg.TableStatusTopic, s.handleEvent)
+	return s.subscriber.Subscribe(ctx, pkg.TableStatusTopic, idempotency.Handler(s.processed, s.logger, s.handleEvent))
 }
 
 func (s *TableStatusSubscriber) handleEvent(ctx context.Context, msg []byte) error {
-	var event pkg.TableStatusEvent
-	if err := json.Unmarshal(msg, &event); err != nil {
+	env, err := event.Open(msg)
+	if errors.Is(err, event.ErrNotEvent) {
 		s.logger.Info("invalid table status event", "error", err)
 		return nil
 	}
+	if err != nil {
+		return err
+	}
 
-	id, err := uuid.Parse(event.TableID)
+	var evt pkg.TableStatusEvent
+	if err := env.Unmarshal(&evt); err != nil {
+		s.logger.Info("invalid table status event", "error", err)
+		return nil
+	}
+
+	id, err := uuid.Parse(evt.TableID)
 	if err != nil {
-		s.logger.Info("invalid table id in event", "table_id", event.TableID)
+		s.logger.Info("invalid table id in event", "table_id", evt.TableID)
 		return nil
 	}
 
-	s.cache.Set(id, event.Status)
-	s.logger.Debug("table status updated", "table_id", id.String(), "status", event.Status)
+	s.cache.Set(id, evt.Status)
+	// Older table services leave the number out and say nothing about the
+	// waiter, so only trust the details when it is there
+	if evt.Number != "" {
+		s.cache.SetDetails(id, TableDetails{Number: evt.Number, AssignedTo: evt.AssignedTo})
+	}
+	s.logger.Debug("table status updated", "table_id", id.String(), "status", evt.Status)
 	return nil
 }
@@ -9,6 +9,8 @@ import (
 	"syscall"
 
 	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/idempotency"
+	"github.com/appetiteclub/appetite/pkg/outbox"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/middleware"
 
@@ -57,11 +59,16 @@ func main() {
 	orderRepo := mongo.NewOrderRepo(db)
 	orderItemRepo := mongo.NewOrderItemRepo(db)
 	orderGroupRepo := mongo.NewOrderGroupRepo(db)
+	paymentRepo := mongo.NewPaymentRepo(db)
+	if err := paymentRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("%s(%s) cannot prepare payments: %v", appName, appVersion, err)
+	}
 
 	repos := order.Repos{
 		OrderRepo:      orderRepo,
 		OrderItemRepo:  orderItemRepo,
 		OrderGroupRepo: orderGroupRepo,
+		PaymentRepo:    paymentRepo,
 	}
 
 	natsURL := config.GetStringOrDef("nats.url", "nats://localhost:4222")
@@ -71,15 +78,46 @@ func main() {
 		log.Fatalf("%s(%s) cannot connect to NATS publisher: %v", appName, appVersion, err)
 	}
 
-	sub, err := pkg.NewNATSSubscriber(natsURL)
+	// Events are stored with the changes that raise them and relayed to
+	// NATS from there, so a NATS outage delays them instead of losing them
+	relayConfig, err := outbox.LoadRelayConfig(config)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup outbox: %v", appName, appVersion, err)
+	}
+	eventOutbox := outbox.New(baseRepo.GetDatabase, logger)
+	relay := outbox.NewRelay(eventOutbox, pub, relayConfig, logger)
+
+	deliveryPolicy, err := pkg.LoadDeliveryPolicy(config, appName)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup NATS delivery: %v", appName, appVersion, err)
+	}
+
+	// kitchen.tickets and tables.status are read with durable consumers, so
+	// the events published while the service is down, and their retries,
+	// are handled once it is back
+	sub, err := pkg.NewNATSConsumer(ctx, pkg.NATSConsumerConfig{
+		URL:      natsURL,
+		Consumer: appName,
+		Delivery: deliveryPolicy,
+		Logger:   logger,
+	})
 	if err != nil {
 		log.Fatalf("%s(%s) cannot connect to NATS subscriber: %v", appName, appVersion, err)
 	}
 
+	// Redelivered and republished events are skipped by their event ID
+	processedTTL, err := idempotency.LoadTTL(config)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup idempotency store: %v", appName, appVersion, err)
+	}
+	tableStatusProcessed := idempotency.NewMongoStore(baseRepo.GetDatabase, appName+".table_status", processedTTL, logger)
+	kitchenProcessed := idempotency.NewMongoStore(baseRepo.GetDatabase, appName+".kitchen_tickets", processedTTL, logger)
+
 	tableURL, _ := config.GetString("services.table.url")
 	tableClient := apt.NewServiceClient(tableURL)
 	tableStateCache := order.NewTableStateCache(tableClient, logger)
 	tableStatusSub := order.NewTableStatusSubscriber(sub, tableStateCache, logger)
+	tableStatusSub.SetIdempotencyStore(tableStatusProcessed)
 
 	// Kitchen service client for updating tickets when order items change
 	kitchenURL := config.GetStringOrDef("services.kitchen.url", "")
@@ -89,11 +127,13 @@ func main() {
 	kitchenClient := apt.NewServiceClient(kitchenURL)
 
 	// Initialize gRPC streaming server for real-time order item events
-	orderEvents := order.NewOrderEventStreamServer(orderItemRepo, logger)
+	orderEvents := order.NewOrderEventStreamServer(orderRepo, orderItemRepo, tableStateCache, logger)
 
 	// Subscribe to kitchen ticket events to sync OrderItem status
 	kitchenSub := order.NewKitchenTicketSubscriber(sub, orderItemRepo, logger)
 	kitchenSub.SetStreamServer(orderEvents)
+	kitchenSub.SetCourseFirer(order.NewCourseFirer(orderItemRepo, eventOutbox, orderEvents, logger))
+	kitchenSub.SetIdempotencyStore(kitchenProcessed)
 
 	publisherLifecycle := apt.LifecycleHooks{
 		OnStop: func(context.Context) error {
@@ -111,7 +151,7 @@ func main() {
 		Repos:             repos,
 		TableStatesCache:  tableStateCache,
 		KitchenClient:     kitchenClient,
-		Publisher:         pub,
+		Publisher:         eventOutbox,
 		OrderStreamServer: orderEvents,
 	}
 
@@ -143,8 +183,11 @@ func main() {
 	// Build lifecycle hooks
 	lifecycles := []interface{}{
 		apt.LifecycleHooks{OnStop: baseRepo.Stop},
+		tableStatusProcessed,
+		kitchenProcessed,
 		tableStatusSub,
 		kitchenSub,
+		relay,
 		publisherLifecycle,
 		subLifecycle,
 	}
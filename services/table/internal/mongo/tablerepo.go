@@ -24,17 +24,23 @@ type TableRepo struct {
 
 // tableDocument represents the MongoDB document structure.
 type tableDocument struct {
-	ID          string     `bson:"_id"`
-	Number      string     `bson:"number"`
-	Status      string     `bson:"status"`
-	GuestCount  int        `bson:"guest_count"`
-	AssignedTo  *string    `bson:"assigned_to,omitempty"`
-	Notes       []bson.M   `bson:"notes,omitempty"`
-	CurrentBill *bson.M    `bson:"current_bill,omitempty"`
-	CreatedAt   time.Time  `bson:"created_at"`
-	CreatedBy   string     `bson:"created_by"`
-	UpdatedAt   time.Time  `bson:"updated_at"`
-	UpdatedBy   string     `bson:"updated_by"`
+	ID          string    `bson:"_id"`
+	Number      string    `bson:"number"`
+	Status      string    `bson:"status"`
+	GuestCount  int       `bson:"guest_count"`
+	Capacity    int       `bson:"capacity"`
+	Location    string    `bson:"location"`
+	BlockReason string    `bson:"block_reason"`
+	AssignedTo  *string   `bson:"assigned_to,omitempty"`
+	Notes       []bson.M  `bson:"notes,omitempty"`
+	MergedInto  *string   `bson:"merged_into,omitempty"`
+	CurrentBill *bson.M   `bson:"current_bill,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	CreatedBy   string    `bson:"created_by"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+	UpdatedBy   string    `bson:"updated_by"`
+
+	History []tables.Activity `bson:"history,omitempty"`
 }
 
 func NewTableRepo(config *apt.Config, logger apt.Logger) *TableRepo {
@@ -105,14 +111,18 @@ func (r *TableRepo) GetDatabase() *mongo.Database {
 // toDocument converts a Table entity to MongoDB document.
 func (r *TableRepo) toDocument(table *tables.Table) *tableDocument {
 	doc := &tableDocument{
-		ID:         table.ID.String(),
-		Number:     table.Number,
-		Status:     table.Status,
-		GuestCount: table.GuestCount,
-		CreatedAt:  table.CreatedAt,
-		CreatedBy:  table.CreatedBy,
-		UpdatedAt:  table.UpdatedAt,
-		UpdatedBy:  table.UpdatedBy,
+		ID:          table.ID.String(),
+		Number:      table.Number,
+		Status:      table.Status,
+		GuestCount:  table.GuestCount,
+		Capacity:    table.Capacity,
+		Location:    table.Location,
+		BlockReason: table.BlockReason,
+		CreatedAt:   table.CreatedAt,
+		CreatedBy:   table.CreatedBy,
+		UpdatedAt:   table.UpdatedAt,
+		UpdatedBy:   table.UpdatedBy,
+		History:     table.History,
 	}
 
 	if table.AssignedTo != nil {
@@ -120,6 +130,11 @@ func (r *TableRepo) toDocument(table *tables.Table) *tableDocument {
 		doc.AssignedTo = &assignedToStr
 	}
 
+	if table.MergedInto != nil {
+		mergedIntoStr := table.MergedInto.String()
+		doc.MergedInto = &mergedIntoStr
+	}
+
 	if table.Notes != nil && len(table.Notes) > 0 {
 		doc.Notes = make([]bson.M, len(table.Notes))
 		for i, note := range table.Notes {
@@ -134,10 +149,13 @@ func (r *TableRepo) toDocument(table *tables.Table) *tableDocument {
 
 	if table.CurrentBill != nil {
 		billDoc := bson.M{
-			"subtotal": table.CurrentBill.Subtotal,
-			"tax":      table.CurrentBill.Tax,
-			"tip":      table.CurrentBill.Tip,
-			"total":    table.CurrentBill.Total,
+			"order_id":       table.CurrentBill.OrderID,
+			"subtotal":       table.CurrentBill.Subtotal,
+			"service_charge": table.CurrentBill.ServiceCharge,
+			"tax":            table.CurrentBill.Tax,
+			"tip":            table.CurrentBill.Tip,
+			"total":          table.CurrentBill.Total,
+			"paid":           table.CurrentBill.Paid,
 		}
 		doc.CurrentBill = &billDoc
 	}
@@ -153,14 +171,18 @@ func (r *TableRepo) fromDocument(doc *tableDocument) (*tables.Table, error) {
 	}
 
 	table := &tables.Table{
-		ID:         id,
-		Number:     doc.Number,
-		Status:     doc.Status,
-		GuestCount: doc.GuestCount,
-		CreatedAt:  doc.CreatedAt,
-		CreatedBy:  doc.CreatedBy,
-		UpdatedAt:  doc.UpdatedAt,
-		UpdatedBy:  doc.UpdatedBy,
+		ID:          id,
+		Number:      doc.Number,
+		Status:      doc.Status,
+		GuestCount:  doc.GuestCount,
+		Capacity:    doc.Capacity,
+		Location:    doc.Location,
+		BlockReason: doc.BlockReason,
+		CreatedAt:   doc.CreatedAt,
+		CreatedBy:   doc.CreatedBy,
+		UpdatedAt:   doc.UpdatedAt,
+		UpdatedBy:   doc.UpdatedBy,
+		History:     doc.History,
 	}
 
 	if doc.AssignedTo != nil && *doc.AssignedTo != "" {
@@ -170,6 +192,13 @@ func (r *TableRepo) fromDocument(doc *tableDocument) (*tables.Table, error) {
 		}
 	}
 
+	if doc.MergedInto != nil && *doc.MergedInto != "" {
+		mergedInto, err := uuid.Parse(*doc.MergedInto)
+		if err == nil {
+			table.MergedInto = &mergedInto
+		}
+	}
+
 	if doc.Notes != nil && len(doc.Notes) > 0 {
 		table.Notes = make([]tables.Note, 0, len(doc.Notes))
 		for _, noteDoc := range doc.Notes {
@@ -196,9 +225,15 @@ func (r *TableRepo) fromDocument(doc *tableDocument) (*tables.Table, error) {
 	if doc.CurrentBill != nil {
 		billDoc := *doc.CurrentBill
 		bill := &tables.Bill{}
+		if orderID, ok := billDoc["order_id"].(string); ok {
+			bill.OrderID = orderID
+		}
 		if subtotal, ok := billDoc["subtotal"].(float64); ok {
 			bill.Subtotal = subtotal
 		}
+		if serviceCharge, ok := billDoc["service_charge"].(float64); ok {
+			bill.ServiceCharge = serviceCharge
+		}
 		if tax, ok := billDoc["tax"].(float64); ok {
 			bill.Tax = tax
 		}
@@ -208,6 +243,9 @@ func (r *TableRepo) fromDocument(doc *tableDocument) (*tables.Table, error) {
 		if total, ok := billDoc["total"].(float64); ok {
 			bill.Total = total
 		}
+		if paid, ok := billDoc["paid"].(float64); ok {
+			bill.Paid = paid
+		}
 		table.CurrentBill = bill
 	}
 
@@ -0,0 +1,88 @@
+package tables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/apt"
+	"github.com/appetiteclub/apt/events"
+	"github.com/google/uuid"
+)
+
+// BillSubscriber keeps Table.CurrentBill in sync with the bills settled in the
+// order service.
+type BillSubscriber struct {
+	subscriber events.Subscriber
+	tableRepo  TableRepo
+	logger     apt.Logger
+}
+
+func NewBillSubscriber(sub events.Subscriber, tableRepo TableRepo, logger apt.Logger) *BillSubscriber {
+	if logger == nil {
+		logger = apt.NewNoopLogger()
+	}
+	return &BillSubscriber{
+		subscriber: sub,
+		tableRepo:  tableRepo,
+		logger:     logger,
+	}
+}
+
+func (s *BillSubscriber) Start(ctx context.Context) error {
+	s.logger.Info("starting bill subscriber", "topic", pkg.OrderTableTopic)
+	if s.subscriber == nil {
+		return fmt.Errorf("bill subscriber not configured")
+	}
+	return s.subscriber.Subscribe(ctx, pkg.OrderTableTopic, s.handleEvent)
+}
+
+func (s *BillSubscriber) handleEvent(ctx context.Context, msg []byte) error {
+	env, err := event.Open(msg)
+	if errors.Is(err, event.ErrNotEvent) {
+		s.logger.Info("invalid order table event", "error", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The topic also carries order table rejections
+	if env.Type != pkg.EventOrderBillSettled {
+		return nil
+	}
+
+	var evt pkg.OrderBillSettledEvent
+	if err := env.Unmarshal(&evt); err != nil {
+		s.logger.Info("invalid order table event", "error", err)
+		return nil
+	}
+
+	id, err := uuid.Parse(evt.TableID)
+	if err != nil {
+		s.logger.Info("invalid table id in bill event", "table_id", evt.TableID)
+		return nil
+	}
+
+	table, err := s.tableRepo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("cannot load table for bill: %w", err)
+	}
+	if table == nil {
+		s.logger.Info("table not found for bill event", "table_id", id.String())
+		return nil
+	}
+
+	table.UpdateBill(evt.Subtotal, evt.ServiceCharge, evt.Tax, evt.Tip)
+	table.CurrentBill.OrderID = evt.OrderID
+	table.CurrentBill.Paid = evt.Paid
+
+	if err := s.tableRepo.Save(ctx, table); err != nil {
+		return fmt.Errorf("cannot save table bill: %w", err)
+	}
+
+	s.logger.Debug("table bill updated", "table_id", id.String(), "order_id", evt.OrderID, "total", evt.Total)
+	return nil
+}
@@ -3,12 +3,15 @@ package tables
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/event"
+	"github.com/appetiteclub/appetite/pkg/outbox"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/events"
 	"github.com/appetiteclub/apt/telemetry"
@@ -78,6 +81,15 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Post("/{id}/close", h.CloseTable)
 		r.Post("/{id}/clearing", h.SetTableClearing)
 		r.Post("/{id}/release", h.ReleaseTable)
+		r.Post("/{id}/assign", h.AssignWaiter)
+		r.Post("/{id}/block", h.BlockTable)
+		r.Post("/{id}/unblock", h.UnblockTable)
+		r.Put("/{id}/capacity", h.SetTableCapacity)
+		r.Put("/{id}/location", h.SetTableLocation)
+		r.Post("/{id}/merge", h.MergeTable)
+		r.Post("/{id}/unmerge", h.UnmergeTable)
+		r.Post("/{id}/notes", h.AddTableNote)
+		r.Get("/{id}/history", h.GetTableHistory)
 
 		r.Route("/{tableID}/groups", func(r chi.Router) {
 			r.Post("/", h.CreateGroup)
@@ -144,16 +156,22 @@ func (h *Handler) CreateTable(w http.ResponseWriter, r *http.Request) {
 	table.Number = req.Number
 	table.GuestCount = req.GuestCount
 	table.AssignedTo = req.AssignedTo
+	table.Capacity = req.Capacity
+	table.Location = strings.TrimSpace(req.Location)
 	table.BeforeCreate()
 
-	if err := h.tableRepo.Create(ctx, table); err != nil {
+	err := outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.tableRepo.Create(ctx, table); err != nil {
+			return err
+		}
+		return h.publishTableStatusChanged(ctx, table, "", "table.created")
+	})
+	if err != nil {
 		log.Error("cannot create table", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not create table")
 		return
 	}
 
-	h.publishTableStatusChanged(ctx, table, "", "table.created")
-
 	links := apt.RESTfulLinksFor(table)
 	w.WriteHeader(http.StatusCreated)
 	apt.RespondSuccess(w, table, links...)
@@ -256,6 +274,9 @@ func (h *Handler) UpdateTable(w http.ResponseWriter, r *http.Request) {
 			statusChanged = true
 		}
 		table.Status = req.Status
+		if !table.IsMerged() {
+			table.MergedInto = nil
+		}
 	}
 	if req.GuestCount > 0 {
 		table.GuestCount = req.GuestCount
@@ -266,16 +287,17 @@ func (h *Handler) UpdateTable(w http.ResponseWriter, r *http.Request) {
 
 	table.BeforeUpdate()
 
-	if err := h.tableRepo.Save(ctx, table); err != nil {
+	if statusChanged {
+		err = h.saveTable(ctx, table, previousStatus, "table.updated")
+	} else {
+		err = h.tableRepo.Save(ctx, table)
+	}
+	if err != nil {
 		log.Error("cannot update table", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not update table")
 		return
 	}
 
-	if statusChanged {
-		h.publishTableStatusChanged(ctx, table, previousStatus, "table.updated")
-	}
-
 	links := apt.RESTfulLinksFor(table)
 	apt.RespondSuccess(w, table, links...)
 }
@@ -325,17 +347,25 @@ func (h *Handler) OpenTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if table.IsBlocked() {
+		apt.RespondError(w, http.StatusConflict, "Table is blocked")
+		return
+	}
+
+	if table.IsMerged() {
+		apt.RespondError(w, http.StatusConflict, "Table is merged into another table")
+		return
+	}
+
 	previousStatus := table.Status
 	table.Open(req.GuestCount, req.AssignedTo)
 
-	if err := h.tableRepo.Save(ctx, table); err != nil {
+	if err := h.saveTable(ctx, table, previousStatus, "table.opened"); err != nil {
 		log.Error("cannot open table", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not open table")
 		return
 	}
 
-	h.publishTableStatusChanged(ctx, table, previousStatus, "table.opened")
-
 	links := apt.RESTfulLinksFor(table)
 	apt.RespondSuccess(w, table, links...)
 }
@@ -362,14 +392,12 @@ func (h *Handler) CloseTable(w http.ResponseWriter, r *http.Request) {
 	previousStatus := table.Status
 	table.Close()
 
-	if err := h.tableRepo.Save(ctx, table); err != nil {
+	if err := h.saveTable(ctx, table, previousStatus, "table.closed"); err != nil {
 		log.Error("cannot close table", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not close table")
 		return
 	}
 
-	h.publishTableStatusChanged(ctx, table, previousStatus, "table.closed")
-
 	links := apt.RESTfulLinksFor(table)
 	apt.RespondSuccess(w, table, links...)
 }
@@ -396,14 +424,12 @@ func (h *Handler) SetTableClearing(w http.ResponseWriter, r *http.Request) {
 	previousStatus := table.Status
 	table.SetClearing()
 
-	if err := h.tableRepo.Save(ctx, table); err != nil {
+	if err := h.saveTable(ctx, table, previousStatus, "table.clearing"); err != nil {
 		log.Error("cannot set table to clearing", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not update table")
 		return
 	}
 
-	h.publishTableStatusChanged(ctx, table, previousStatus, "table.clearing")
-
 	links := apt.RESTfulLinksFor(table)
 	apt.RespondSuccess(w, table, links...)
 }
@@ -430,24 +456,409 @@ func (h *Handler) ReleaseTable(w http.ResponseWriter, r *http.Request) {
 	previousStatus := table.Status
 	table.Release()
 
-	if err := h.tableRepo.Save(ctx, table); err != nil {
+	if err := h.saveTable(ctx, table, previousStatus, "table.released"); err != nil {
 		log.Error("cannot release table", "error", err)
 		apt.RespondError(w, http.StatusInternalServerError, "Could not release table")
 		return
 	}
 
-	h.publishTableStatusChanged(ctx, table, previousStatus, "table.released")
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+func (h *Handler) AssignWaiter(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.AssignWaiter")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeTableAssignPayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	table.AssignWaiter(req.AssignedTo)
+
+	if err := h.saveTable(ctx, table, table.Status, "table.assigned"); err != nil {
+		log.Error("cannot assign waiter", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not assign waiter")
+		return
+	}
 
 	links := apt.RESTfulLinksFor(table)
 	apt.RespondSuccess(w, table, links...)
 }
 
-func (h *Handler) publishTableStatusChanged(ctx context.Context, table *Table, previousStatus, reason string) {
-	if h.publisher == nil || table == nil {
+func (h *Handler) BlockTable(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.BlockTable")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeTableBlockPayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	if table.InUse() {
+		apt.RespondError(w, http.StatusConflict, "Table is in use")
+		return
+	}
+
+	previousStatus := table.Status
+	table.Block(strings.TrimSpace(req.Reason))
+
+	if err := h.saveTable(ctx, table, previousStatus, "table.blocked"); err != nil {
+		log.Error("cannot block table", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not block table")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+func (h *Handler) UnblockTable(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.UnblockTable")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	if !table.IsBlocked() {
+		apt.RespondError(w, http.StatusConflict, "Table is not blocked")
+		return
+	}
+
+	previousStatus := table.Status
+	table.Unblock()
+
+	if err := h.saveTable(ctx, table, previousStatus, "table.unblocked"); err != nil {
+		log.Error("cannot unblock table", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not unblock table")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+func (h *Handler) SetTableCapacity(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.SetTableCapacity")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeTableCapacityPayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	if req.Capacity <= 0 {
+		apt.RespondError(w, http.StatusBadRequest, "capacity must be greater than 0")
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	table.Capacity = req.Capacity
+	table.BeforeUpdate()
+
+	if err := h.tableRepo.Save(ctx, table); err != nil {
+		log.Error("cannot update table capacity", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not update table")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+func (h *Handler) SetTableLocation(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.SetTableLocation")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeTableLocationPayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	table.Location = strings.TrimSpace(req.Location)
+	table.BeforeUpdate()
+
+	if err := h.tableRepo.Save(ctx, table); err != nil {
+		log.Error("cannot update table location", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not update table")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+// MergeTable pushes an available table against the one named in the
+// request, which seats and bills its guests until the table is unmerged.
+func (h *Handler) MergeTable(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.MergeTable")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeTableMergePayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	if req.Into == uuid.Nil {
+		apt.RespondError(w, http.StatusBadRequest, "into is required")
+		return
+	}
+
+	if req.Into == id {
+		apt.RespondError(w, http.StatusBadRequest, "Cannot merge a table into itself")
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	host, err := h.tableRepo.Get(ctx, req.Into)
+	if err != nil || host == nil {
+		log.Error("host table not found", "error", err, "id", req.Into.String())
+		apt.RespondError(w, http.StatusNotFound, "Host table not found")
+		return
+	}
+
+	if table.Status != "available" {
+		apt.RespondError(w, http.StatusConflict, "Only an available table can be merged")
+		return
+	}
+
+	if host.IsBlocked() || host.IsMerged() {
+		apt.RespondError(w, http.StatusConflict, "Host table cannot take merged tables")
+		return
+	}
+
+	previousStatus := table.Status
+	table.MergeInto(host.ID)
+
+	if err := h.saveTableWithDetail(ctx, table, previousStatus, "table.merged", "merged into "+host.Number); err != nil {
+		log.Error("cannot merge table", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not merge table")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+func (h *Handler) UnmergeTable(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.UnmergeTable")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	if !table.IsMerged() {
+		apt.RespondError(w, http.StatusConflict, "Table is not merged")
+		return
+	}
+
+	previousStatus := table.Status
+	table.Unmerge()
+
+	if err := h.saveTable(ctx, table, previousStatus, "table.unmerged"); err != nil {
+		log.Error("cannot unmerge table", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not unmerge table")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+func (h *Handler) AddTableNote(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.AddTableNote")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeTableNotePayload(w, r, log)
+	if !ok {
+		return
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		apt.RespondError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
 		return
 	}
 
-	event := pkg.TableStatusEvent{
+	table.AddNote(content, strings.TrimSpace(req.CreatedBy))
+	table.Record("table.note_added", table.Status, content)
+	table.BeforeUpdate()
+
+	if err := h.tableRepo.Save(ctx, table); err != nil {
+		log.Error("cannot add table note", "error", err)
+		apt.RespondError(w, http.StatusInternalServerError, "Could not add note")
+		return
+	}
+
+	links := apt.RESTfulLinksFor(table)
+	apt.RespondSuccess(w, table, links...)
+}
+
+// GetTableHistory returns the recent activity of a table, newest first.
+func (h *Handler) GetTableHistory(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.GetTableHistory")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableRepo.Get(ctx, id)
+	if err != nil || table == nil {
+		log.Error("table not found", "error", err, "id", id.String())
+		apt.RespondError(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	history := make([]Activity, len(table.History))
+	for i, activity := range table.History {
+		history[len(history)-1-i] = activity
+	}
+
+	apt.RespondCollection(w, history, "activity")
+}
+
+// saveTable stores the table together with its status event, so the other
+// services hear about every status the table service saved. The change is
+// recorded in the table history under reason.
+func (h *Handler) saveTable(ctx context.Context, table *Table, previousStatus, reason string) error {
+	return h.saveTableWithDetail(ctx, table, previousStatus, reason, "")
+}
+
+func (h *Handler) saveTableWithDetail(ctx context.Context, table *Table, previousStatus, reason, detail string) error {
+	table.Record(reason, previousStatus, detail)
+	return outbox.WithTransaction(ctx, h.publisher, func(ctx context.Context) error {
+		if err := h.tableRepo.Save(ctx, table); err != nil {
+			return err
+		}
+		return h.publishTableStatusChanged(ctx, table, previousStatus, reason)
+	})
+}
+
+func (h *Handler) publishTableStatusChanged(ctx context.Context, table *Table, previousStatus, reason string) error {
+	if h.publisher == nil || table == nil {
+		return nil
+	}
+
+	evt := pkg.TableStatusEvent{
+		EventID:        event.NewID(),
 		EventType:      pkg.EventTableStatusChanged,
 		TableID:        table.ID.String(),
 		Status:         table.Status,
@@ -455,17 +866,21 @@ func (h *Handler) publishTableStatusChanged(ctx context.Context, table *Table, p
 		Reason:         reason,
 		Source:         tableEventSource,
 		OccurredAt:     time.Now().UTC(),
+		Number:         table.Number,
+	}
+	if table.AssignedTo != nil {
+		evt.AssignedTo = table.AssignedTo.String()
 	}
 
-	payload, err := json.Marshal(event)
+	payload, err := event.Marshal(ctx, tableEventSource, evt)
 	if err != nil {
-		h.logger.Error("cannot marshal table status event", "error", err, "table_id", table.ID.String())
-		return
+		return fmt.Errorf("cannot marshal table status event: %w", err)
 	}
 
 	if err := h.publisher.Publish(ctx, pkg.TableStatusTopic, payload); err != nil {
-		h.logger.Error("cannot publish table status event", "error", err, "table_id", table.ID.String())
+		return fmt.Errorf("cannot publish table status event: %w", err)
 	}
+	return nil
 }
 
 // Group Handlers
@@ -1076,6 +1491,161 @@ func (h *Handler) decodeTableOpenPayload(w http.ResponseWriter, r *http.Request,
 	return req, true
 }
 
+func (h *Handler) decodeTableAssignPayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (TableAssignRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return TableAssignRequest{}, false
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		apt.RespondError(w, http.StatusBadRequest, "Request body is empty")
+		return TableAssignRequest{}, false
+	}
+
+	var req TableAssignRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return TableAssignRequest{}, false
+	}
+
+	return req, true
+}
+
+func (h *Handler) decodeTableBlockPayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (TableBlockRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return TableBlockRequest{}, false
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return TableBlockRequest{}, true
+	}
+
+	var req TableBlockRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return TableBlockRequest{}, false
+	}
+
+	return req, true
+}
+
+func (h *Handler) decodeTableCapacityPayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (TableCapacityRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return TableCapacityRequest{}, false
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		apt.RespondError(w, http.StatusBadRequest, "Request body is empty")
+		return TableCapacityRequest{}, false
+	}
+
+	var req TableCapacityRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return TableCapacityRequest{}, false
+	}
+
+	return req, true
+}
+
+func (h *Handler) decodeTableLocationPayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (TableLocationRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return TableLocationRequest{}, false
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		apt.RespondError(w, http.StatusBadRequest, "Request body is empty")
+		return TableLocationRequest{}, false
+	}
+
+	var req TableLocationRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return TableLocationRequest{}, false
+	}
+
+	return req, true
+}
+
+func (h *Handler) decodeTableMergePayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (TableMergeRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return TableMergeRequest{}, false
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		apt.RespondError(w, http.StatusBadRequest, "Request body is empty")
+		return TableMergeRequest{}, false
+	}
+
+	var req TableMergeRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return TableMergeRequest{}, false
+	}
+
+	return req, true
+}
+
+func (h *Handler) decodeTableNotePayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (TableNoteRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return TableNoteRequest{}, false
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		apt.RespondError(w, http.StatusBadRequest, "Request body is empty")
+		return TableNoteRequest{}, false
+	}
+
+	var req TableNoteRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		apt.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return TableNoteRequest{}, false
+	}
+
+	return req, true
+}
+
 func (h *Handler) decodeGroupCreatePayload(w http.ResponseWriter, r *http.Request, log apt.Logger) (GroupCreateRequest, bool) {
 	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
 	defer r.Body.Close()
@@ -10,6 +10,8 @@ type TableCreateRequest struct {
 	Number     string     `json:"number"`
 	GuestCount int        `json:"guest_count,omitempty"`
 	AssignedTo *uuid.UUID `json:"assigned_to,omitempty"`
+	Capacity   int        `json:"capacity,omitempty"`
+	Location   string     `json:"location,omitempty"`
 }
 
 type TableUpdateRequest struct {
@@ -24,6 +26,32 @@ type TableOpenRequest struct {
 	AssignedTo *uuid.UUID `json:"assigned_to,omitempty"`
 }
 
+type TableAssignRequest struct {
+	AssignedTo *uuid.UUID `json:"assigned_to"`
+}
+
+type TableBlockRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// TableMergeRequest names the table the merged table is pushed against.
+type TableMergeRequest struct {
+	Into uuid.UUID `json:"into"`
+}
+
+type TableNoteRequest struct {
+	Content   string `json:"content"`
+	CreatedBy string `json:"created_by,omitempty"`
+}
+
+type TableCapacityRequest struct {
+	Capacity int `json:"capacity"`
+}
+
+type TableLocationRequest struct {
+	Location string `json:"location"`
+}
+
 type GroupCreateRequest struct {
 	TableID uuid.UUID `json:"table_id"`
 	Name    string    `json:"name"`
@@ -13,15 +13,23 @@ type Table struct {
 	Status      string     `json:"status" bson:"status"`
 	GuestCount  int        `json:"guest_count" bson:"guest_count"`
 	AssignedTo  *uuid.UUID `json:"assigned_to,omitempty" bson:"assigned_to,omitempty"`
+	Capacity    int        `json:"capacity,omitempty" bson:"capacity,omitempty"`
+	Location    string     `json:"location,omitempty" bson:"location,omitempty"`
+	BlockReason string     `json:"block_reason,omitempty" bson:"block_reason,omitempty"`
 	Notes       []Note     `json:"notes,omitempty" bson:"notes,omitempty"`
-	// NOTE: CurrentBill denotes a denormalized view of charges for the table, but
-	// no code populates it today. We should evaluate whether the field is worth
-	// keeping or if billing should live exclusively in the order service.
-	CurrentBill *Bill      `json:"current_bill,omitempty" bson:"current_bill,omitempty"`
-	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
-	CreatedBy   string     `json:"created_by" bson:"created_by"`
-	UpdatedAt   time.Time  `json:"updated_at" bson:"updated_at"`
-	UpdatedBy   string     `json:"updated_by" bson:"updated_by"`
+	// MergedInto is the table a merged table was pushed against; its guests
+	// are seated and billed there until it is unmerged.
+	MergedInto *uuid.UUID `json:"merged_into,omitempty" bson:"merged_into,omitempty"`
+	// History is the recent activity of the table, oldest first, capped at
+	// MaxHistory entries. It is served on its own by GET /tables/{id}/history.
+	History []Activity `json:"-" bson:"history,omitempty"`
+	// CurrentBill is a denormalized view of the order service bill. It is filled
+	// from order.bill.settled events; the order service stays authoritative.
+	CurrentBill *Bill     `json:"current_bill,omitempty" bson:"current_bill,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	CreatedBy   string    `json:"created_by" bson:"created_by"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+	UpdatedBy   string    `json:"updated_by" bson:"updated_by"`
 }
 
 type Note struct {
@@ -31,11 +39,26 @@ type Note struct {
 	CreatedBy string    `json:"created_by" bson:"created_by"`
 }
 
+// MaxHistory is how many activity entries a table keeps.
+const MaxHistory = 50
+
+// Activity is an entry of the table history.
+type Activity struct {
+	At             time.Time `json:"at" bson:"at"`
+	Reason         string    `json:"reason" bson:"reason"`
+	Status         string    `json:"status" bson:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty" bson:"previous_status,omitempty"`
+	Detail         string    `json:"detail,omitempty" bson:"detail,omitempty"`
+}
+
 type Bill struct {
-	Subtotal float64 `json:"subtotal" bson:"subtotal"`
-	Tax      float64 `json:"tax" bson:"tax"`
-	Tip      float64 `json:"tip" bson:"tip"`
-	Total    float64 `json:"total" bson:"total"`
+	OrderID       string  `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	Subtotal      float64 `json:"subtotal" bson:"subtotal"`
+	ServiceCharge float64 `json:"service_charge" bson:"service_charge"`
+	Tax           float64 `json:"tax" bson:"tax"`
+	Tip           float64 `json:"tip" bson:"tip"`
+	Total         float64 `json:"total" bson:"total"`
+	Paid          float64 `json:"paid" bson:"paid"`
 }
 
 func (t *Table) GetID() uuid.UUID {
@@ -87,6 +110,21 @@ func (t *Table) AddNote(content, createdBy string) {
 	t.Notes = append(t.Notes, note)
 }
 
+// Record appends an entry to the history, dropping the oldest ones past
+// MaxHistory.
+func (t *Table) Record(reason, previousStatus, detail string) {
+	t.History = append(t.History, Activity{
+		At:             time.Now().UTC(),
+		Reason:         reason,
+		Status:         t.Status,
+		PreviousStatus: previousStatus,
+		Detail:         detail,
+	})
+	if extra := len(t.History) - MaxHistory; extra > 0 {
+		t.History = append([]Activity(nil), t.History[extra:]...)
+	}
+}
+
 func (t *Table) Open(guestCount int, waiterID *uuid.UUID) {
 	t.Status = "open"
 	t.GuestCount = guestCount
@@ -117,12 +155,61 @@ func (t *Table) Release() {
 	t.UpdatedAt = time.Now()
 }
 
-func (t *Table) UpdateBill(subtotal, tax, tip float64) {
+// AssignWaiter sets the waiter serving the table; nil unassigns it.
+func (t *Table) AssignWaiter(waiterID *uuid.UUID) {
+	t.AssignedTo = waiterID
+	t.UpdatedAt = time.Now()
+}
+
+// Block takes the table out of service so it cannot be seated.
+func (t *Table) Block(reason string) {
+	t.Status = "out_of_service"
+	t.BlockReason = reason
+	t.UpdatedAt = time.Now()
+}
+
+// Unblock puts a blocked table back into service.
+func (t *Table) Unblock() {
+	t.Status = "available"
+	t.BlockReason = ""
+	t.UpdatedAt = time.Now()
+}
+
+// MergeInto pushes the table against host, which seats and bills its
+// guests.
+func (t *Table) MergeInto(host uuid.UUID) {
+	t.Status = "merged"
+	t.MergedInto = &host
+	t.UpdatedAt = time.Now()
+}
+
+// Unmerge puts a merged table back on its own, free to be seated.
+func (t *Table) Unmerge() {
+	t.Status = "available"
+	t.MergedInto = nil
+	t.UpdatedAt = time.Now()
+}
+
+func (t *Table) IsMerged() bool {
+	return t.Status == "merged"
+}
+
+func (t *Table) IsBlocked() bool {
+	return t.Status == "out_of_service"
+}
+
+// InUse reports whether guests are seated or their order is still clearing.
+func (t *Table) InUse() bool {
+	return t.Status == "open" || t.Status == "clearing"
+}
+
+func (t *Table) UpdateBill(subtotal, serviceCharge, tax, tip float64) {
 	t.CurrentBill = &Bill{
-		Subtotal: subtotal,
-		Tax:      tax,
-		Tip:      tip,
-		Total:    subtotal + tax + tip,
+		Subtotal:      subtotal,
+		ServiceCharge: serviceCharge,
+		Tax:           tax,
+		Tip:           tip,
+		Total:         subtotal + serviceCharge + tax + tip,
 	}
 	t.UpdatedAt = time.Now()
 }
@@ -14,6 +14,10 @@ func ValidateTableCreate(ctx context.Context, req TableCreateRequest) []string {
 		errors = append(errors, "number is required")
 	}
 
+	if req.Capacity < 0 {
+		errors = append(errors, "capacity cannot be negative")
+	}
+
 	return errors
 }
 
@@ -10,6 +10,7 @@ import (
 	"syscall"
 
 	"github.com/appetiteclub/appetite/pkg"
+	"github.com/appetiteclub/appetite/pkg/outbox"
 	"github.com/appetiteclub/apt"
 	"github.com/appetiteclub/apt/middleware"
 
@@ -80,6 +81,39 @@ func main() {
 	}
 	lifecycle = append(lifecycle, publisherLifecycle)
 
+	// Table events are stored with the tables and relayed to NATS from
+	// there, so a NATS outage delays them instead of losing them
+	relayConfig, err := outbox.LoadRelayConfig(config)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup outbox: %v", appName, appVersion, err)
+	}
+	eventOutbox := outbox.New(tableRepo.GetDatabase, logger)
+	lifecycle = append(lifecycle, outbox.NewRelay(eventOutbox, publisher, relayConfig, logger))
+
+	deliveryPolicy, err := pkg.LoadDeliveryPolicy(config, appName)
+	if err != nil {
+		log.Fatalf("%s(%s) cannot setup NATS delivery: %v", appName, appVersion, err)
+	}
+
+	// orders.tables is read with a durable consumer, so the bills settled
+	// while the service is down, and their retries, are handled once it is back
+	subscriber, err := pkg.NewNATSConsumer(ctx, pkg.NATSConsumerConfig{
+		URL:      natsURL,
+		Consumer: appName,
+		Delivery: deliveryPolicy,
+		Logger:   logger,
+	})
+	if err != nil {
+		log.Fatalf("%s(%s) cannot connect to NATS subscriber: %v", appName, appVersion, err)
+	}
+
+	subscriberLifecycle := apt.LifecycleHooks{
+		OnStop: func(context.Context) error {
+			return subscriber.Close()
+		},
+	}
+	lifecycle = append(lifecycle, subscriberLifecycle)
+
 	repos := tables.Repos{
 		TableRepo:       tableRepo,
 		GroupRepo:       groupRepo,
@@ -88,9 +122,12 @@ func main() {
 		ReservationRepo: reservationRepo,
 	}
 
+	// Keep the table bill view in sync with settled order bills
+	lifecycle = append(lifecycle, tables.NewBillSubscriber(subscriber, tableRepo, logger))
+
 	hd := tables.HandlerDeps{
 		Repos:     repos,
-		Publisher: publisher,
+		Publisher: eventOutbox,
 	}
 
 	handler := tables.NewHandler(